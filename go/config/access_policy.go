@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// ErrSecretAccessDenied matches any *SecretAccessDeniedError via errors.Is.
+var ErrSecretAccessDenied = errors.New("config secret access denied")
+
+// SecretAccessDeniedError is returned from GetSecretConfig when the
+// WithSecretAccessPolicy policy refuses the read. Err is the policy's
+// error.
+type SecretAccessDeniedError struct {
+	Key    string
+	Caller SecretCaller
+	Err    error
+}
+
+func (e *SecretAccessDeniedError) Error() string {
+	who := e.Caller.Package
+	if who == "" {
+		who = "the config manager"
+	}
+	return fmt.Sprintf("[Smooai Config] %s may not read secret %s: %v", who, e.Key, e.Err)
+}
+
+// Is supports errors.Is(err, ErrSecretAccessDenied).
+func (e *SecretAccessDeniedError) Is(target error) bool { return target == ErrSecretAccessDenied }
+
+func (e *SecretAccessDeniedError) Unwrap() error { return e.Err }
+
+// SecretCaller identifies the code reading a secret: the first function on
+// the stack outside this package, so reads through Get, ConfigView and
+// GetCredential name the application code behind them. It is zero when the
+// manager reads a secret itself, for example to refresh an OpenSQL pool.
+type SecretCaller struct {
+	// Package is the import path, such as "github.com/acme/billing".
+	Package string
+	// Function is the qualified name, such as
+	// "github.com/acme/billing.(*Client).Charge".
+	Function string
+	File     string
+	Line     int
+}
+
+// SecretAccessPolicy decides whether caller may read the secret key. A
+// non-nil error denies the read.
+type SecretAccessPolicy func(key string, caller SecretCaller) error
+
+// WithSecretAccessPolicy calls policy on every GetSecretConfig, cache hits
+// included, before the value is read, so an application can restrict which
+// of its packages read which secrets and log every attempt:
+//
+//	config.WithSecretAccessPolicy(func(key string, caller config.SecretCaller) error {
+//	    auditLog.Info("secret read", "key", key, "package", caller.Package)
+//	    if strings.HasPrefix(key, "STRIPE_") && caller.Package != "github.com/acme/billing" {
+//	        return errors.New("only billing reads payment secrets")
+//	    }
+//	    return nil
+//	})
+//
+// Explain, Snapshot and Export with includeSecrets call policy too, for
+// each key they return that isn't known to be public or a feature flag. A
+// denied read fails with a *SecretAccessDeniedError. policy runs on the
+// reading goroutine without the manager's lock held, so it may read the
+// manager's other tiers.
+func WithSecretAccessPolicy(policy SecretAccessPolicy) ConfigManagerOption {
+	return func(m *ConfigManager) { m.secretPolicy = policy }
+}
+
+// checkSecretAccess runs the secret access policy for a read of key.
+func (m *ConfigManager) checkSecretAccess(key string) error {
+	return m.checkSecretAccessAll([]string{key})
+}
+
+// checkSecretAccessAll runs the secret access policy for a read of each of
+// keys, in order, failing on the first it denies.
+func (m *ConfigManager) checkSecretAccessAll(keys []string) error {
+	if m.secretPolicy == nil || len(keys) == 0 {
+		return nil
+	}
+	caller := secretCaller()
+	slices.Sort(keys)
+	for _, key := range keys {
+		if err := m.secretPolicy(key, caller); err != nil {
+			return &SecretAccessDeniedError{Key: key, Caller: caller, Err: err}
+		}
+	}
+	return nil
+}
+
+// configPackage is this package's import path.
+var configPackage = reflect.TypeFor[ConfigManager]().PkgPath()
+
+// secretCaller finds the first frame outside this package.
+func secretCaller() SecretCaller {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if pkg := funcPackage(frame.Function); pkg != "" && pkg != configPackage && pkg != "runtime" {
+			return SecretCaller{Package: pkg, Function: frame.Function, File: frame.File, Line: frame.Line}
+		}
+		if !more {
+			return SecretCaller{}
+		}
+	}
+}
+
+// funcPackage returns the import path of a function name as reported by
+// runtime, such as "github.com/acme/billing" for
+// "github.com/acme/billing.(*Client).Charge".
+func funcPackage(name string) string {
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i] // type arguments may hold other paths
+	}
+	dir := ""
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		dir, name = name[:i+1], name[i+1:]
+	}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+	return dir + name
+}
@@ -0,0 +1,95 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+const thisPackage = "github.com/SmooAI/config/go/config_test"
+
+func TestWithSecretAccessPolicy(t *testing.T) {
+	var seen []config.SecretCaller
+	mgr := config.NewStaticConfigManager(
+		map[string]any{"API_URL": "https://static"},
+		map[string]any{"DB_PASSWORD": "hunter2", "STRIPE_KEY": "sk_live"},
+		nil,
+		config.WithSecretAccessPolicy(func(key string, caller config.SecretCaller) error {
+			seen = append(seen, caller)
+			if key == "STRIPE_KEY" {
+				return errors.New("only billing reads payment secrets")
+			}
+			return nil
+		}),
+	)
+
+	v, err := mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+	require.Len(t, seen, 1)
+	assert.Equal(t, thisPackage, seen[0].Package)
+	assert.Equal(t, thisPackage+".TestWithSecretAccessPolicy", seen[0].Function)
+	assert.Contains(t, seen[0].File, "access_policy_test.go")
+
+	_, err = mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Len(t, seen, 2, "cache hits are checked too")
+
+	_, err = mgr.GetSecretConfig("STRIPE_KEY")
+	assert.ErrorIs(t, err, config.ErrSecretAccessDenied)
+	var denied *config.SecretAccessDeniedError
+	require.ErrorAs(t, err, &denied)
+	assert.Equal(t, "STRIPE_KEY", denied.Key)
+	assert.Equal(t, thisPackage, denied.Caller.Package)
+	assert.ErrorContains(t, err, "only billing reads payment secrets")
+
+	_, err = config.Get[string](mgr, "STRIPE_KEY")
+	assert.ErrorIs(t, err, config.ErrSecretAccessDenied)
+	assert.Equal(t, thisPackage, seen[len(seen)-1].Package, "reads through Get name the code calling Get")
+	_, err = mgr.WithOverrides(map[string]any{"API_URL": "x"}).GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, thisPackage, seen[len(seen)-1].Package)
+
+	n := len(seen)
+	_, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Len(t, seen, n, "other tiers are not checked")
+}
+
+func TestWithSecretAccessPolicy_CoversBulkReads(t *testing.T) {
+	var checked []string
+	mgr := config.NewStaticConfigManager(
+		map[string]any{"API_URL": "https://static"},
+		map[string]any{"DB_PASSWORD": "hunter2", "STRIPE_KEY": "sk_live"},
+		nil,
+		config.WithSecretAccessPolicy(func(key string, caller config.SecretCaller) error {
+			checked = append(checked, key)
+			if key == "STRIPE_KEY" {
+				return errors.New("only billing reads payment secrets")
+			}
+			return nil
+		}),
+	)
+
+	e, err := mgr.Explain("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", e.Value)
+	_, err = mgr.Explain("STRIPE_KEY")
+	assert.ErrorIs(t, err, config.ErrSecretAccessDenied)
+	_, err = mgr.Snapshot()
+	assert.ErrorIs(t, err, config.ErrSecretAccessDenied)
+	_, err = mgr.Export(config.ExportJSON, true)
+	assert.ErrorIs(t, err, config.ErrSecretAccessDenied)
+
+	checked = nil
+	_, err = mgr.Explain("API_URL")
+	require.NoError(t, err)
+	out, err := mgr.Export(config.ExportJSON, false)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "hunter2")
+	assert.Empty(t, checked, "public keys and redacted exports need no check")
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// accessMetricName is the counter exported by WriteAccessMetrics.
+const accessMetricName = "smooai_config_key_reads_total"
+
+// Defaults for WithCMAccessStats: an hour of one-minute windows.
+const (
+	defaultAccessWindow  = time.Minute
+	defaultAccessWindows = 60
+)
+
+// WithCMAccessStats counts every Get* read per key, in windows of the given
+// length keeping the most recent retain windows, so platform owners can see
+// which keys are hot and which are cold and size caches and TTLs from real
+// traffic. Zero values default to 1m windows, 60 of them. Read the counts
+// with AccessStats (JSON-ready) or WriteAccessMetrics (Prometheus).
+func WithCMAccessStats(window time.Duration, retain int) ConfigManagerOption {
+	return func(m *ConfigManager) {
+		if window <= 0 {
+			window = defaultAccessWindow
+		}
+		if retain <= 0 {
+			retain = defaultAccessWindows
+		}
+		m.accessStats = &accessStats{
+			window:  window,
+			buckets: make([]accessBucket, retain),
+			totals:  map[string]int64{},
+			last:    map[string]time.Time{},
+			since:   time.Now(),
+			now:     time.Now,
+		}
+	}
+}
+
+// AccessReport is a snapshot of per-key read counts.
+type AccessReport struct {
+	// WindowSeconds is the length of each window.
+	WindowSeconds float64 `json:"windowSeconds"`
+	// Since is when counting started.
+	Since time.Time `json:"since"`
+	// Windows holds the retained windows with reads, oldest first.
+	Windows []AccessWindow `json:"windows"`
+	// Keys lists every key read since Since, and every loaded key never
+	// read, hottest first (by Recent, then Total).
+	Keys []KeyAccess `json:"keys"`
+}
+
+// AccessWindow is the read count per key within one window.
+type AccessWindow struct {
+	Start  time.Time        `json:"start"`
+	Counts map[string]int64 `json:"counts"`
+}
+
+// KeyAccess summarizes the reads of one key.
+type KeyAccess struct {
+	Key string `json:"key"`
+	// Total counts reads since the report's Since.
+	Total int64 `json:"total"`
+	// Recent counts reads within the retained windows.
+	Recent int64 `json:"recent"`
+	// LastRead is the most recent read; zero if never read.
+	LastRead time.Time `json:"lastRead"`
+}
+
+// accessStats is a ring of fixed-length windows of per-key read counts.
+type accessStats struct {
+	mu      sync.Mutex
+	window  time.Duration
+	buckets []accessBucket
+	totals  map[string]int64
+	last    map[string]time.Time
+	since   time.Time
+	now     func() time.Time
+}
+
+type accessBucket struct {
+	start  time.Time
+	counts map[string]int64
+}
+
+func (s *accessStats) record(key string) {
+	now := s.now()
+	start := now.Truncate(s.window)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := &s.buckets[int(start.UnixNano()/int64(s.window))%len(s.buckets)]
+	if !b.start.Equal(start) || b.counts == nil {
+		*b = accessBucket{start: start, counts: map[string]int64{}}
+	}
+	b.counts[key]++
+	s.totals[key]++
+	s.last[key] = now
+}
+
+// report snapshots the counts, adding loaded keys that were never read.
+func (s *accessStats) report(loaded []string) AccessReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	oldest := s.now().Truncate(s.window).Add(-time.Duration(len(s.buckets)-1) * s.window)
+
+	report := AccessReport{WindowSeconds: s.window.Seconds(), Since: s.since}
+	recent := map[string]int64{}
+	for _, b := range s.buckets {
+		if b.counts == nil || b.start.Before(oldest) {
+			continue
+		}
+		counts := make(map[string]int64, len(b.counts))
+		for key, n := range b.counts {
+			counts[key] = n
+			recent[key] += n
+		}
+		report.Windows = append(report.Windows, AccessWindow{Start: b.start, Counts: counts})
+	}
+	sort.Slice(report.Windows, func(i, j int) bool { return report.Windows[i].Start.Before(report.Windows[j].Start) })
+
+	for key, total := range s.totals {
+		report.Keys = append(report.Keys, KeyAccess{Key: key, Total: total, Recent: recent[key], LastRead: s.last[key]})
+	}
+	for _, key := range loaded {
+		if _, ok := s.totals[key]; !ok {
+			report.Keys = append(report.Keys, KeyAccess{Key: key})
+		}
+	}
+	sort.Slice(report.Keys, func(i, j int) bool {
+		a, b := report.Keys[i], report.Keys[j]
+		if a.Recent != b.Recent {
+			return a.Recent > b.Recent
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Key < b.Key
+	})
+	return report
+}
+
+// AccessStats returns the per-key read counts collected under
+// WithCMAccessStats, or an empty report without it. It never triggers a
+// load; once loaded, keys never read are listed with zero counts.
+func (m *ConfigManager) AccessStats() AccessReport {
+	if m.accessStats == nil {
+		return AccessReport{}
+	}
+	m.mu.Lock()
+	loaded := make([]string, 0, len(m.config))
+	for key := range m.config {
+		loaded = append(loaded, key)
+	}
+	m.mu.Unlock()
+	return m.accessStats.report(loaded)
+}
+
+// AccessStatsHandler serves AccessStats as JSON, for dumping the heatmap
+// from an admin endpoint.
+func (m *ConfigManager) AccessStatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.AccessStats())
+	})
+}
+
+// WriteAccessMetrics writes the total reads per key as a Prometheus counter
+// in the text exposition format:
+//
+//	smooai_config_key_reads_total{key="API_URL"} 1520
+//
+// It writes nothing without WithCMAccessStats.
+func (m *ConfigManager) WriteAccessMetrics(w io.Writer) error {
+	if m.accessStats == nil {
+		return nil
+	}
+	m.accessStats.mu.Lock()
+	keys := make([]string, 0, len(m.accessStats.totals))
+	totals := make(map[string]int64, len(m.accessStats.totals))
+	for key, n := range m.accessStats.totals {
+		keys = append(keys, key)
+		totals[key] = n
+	}
+	m.accessStats.mu.Unlock()
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# HELP %s Reads of a config key through the Get* methods.\n", accessMetricName)
+	fmt.Fprintf(&buf, "# TYPE %s counter\n", accessMetricName)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "%s{key=\"%s\"} %d\n", accessMetricName, escapeLabelValue(key), totals[key])
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
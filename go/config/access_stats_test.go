@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessStats_WindowsAndRanking(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"HOT": 1, "WARM": 2, "COLD": 3}})
+	mgr := NewConfigManager(
+		WithCMAccessStats(time.Minute, 3),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}),
+	)
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	mgr.accessStats.now = func() time.Time { return clock }
+
+	for range 3 {
+		_, err := mgr.GetPublicConfig("HOT")
+		require.NoError(t, err)
+	}
+	_, _ = mgr.GetPublicConfig("WARM")
+	clock = clock.Add(time.Minute)
+	_, _ = mgr.GetPublicConfig("HOT")
+
+	report := mgr.AccessStats()
+	assert.Equal(t, 60.0, report.WindowSeconds)
+	require.Len(t, report.Windows, 2)
+	assert.Equal(t, map[string]int64{"HOT": 3, "WARM": 1}, report.Windows[0].Counts)
+	assert.Equal(t, map[string]int64{"HOT": 1}, report.Windows[1].Counts)
+
+	var keys []string
+	for _, k := range report.Keys {
+		keys = append(keys, k.Key)
+	}
+	assert.Equal(t, "HOT", keys[0])
+	assert.Equal(t, "WARM", keys[1])
+	assert.Contains(t, keys, "COLD", "loaded keys never read are listed as cold")
+	assert.Equal(t, KeyAccess{Key: "HOT", Total: 4, Recent: 4, LastRead: clock}, report.Keys[0])
+
+	// Windows older than the retained three drop out of Recent, not Total.
+	clock = clock.Add(3 * time.Minute)
+	_, _ = mgr.GetPublicConfig("WARM")
+	report = mgr.AccessStats()
+	require.Len(t, report.Windows, 1)
+	assert.Equal(t, KeyAccess{Key: "WARM", Total: 2, Recent: 1, LastRead: clock}, report.Keys[0])
+	assert.Equal(t, int64(4), report.Keys[1].Total)
+	assert.Zero(t, report.Keys[1].Recent)
+}
+
+func TestAccessStats_Exports(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_URL": "x", "MAX_RETRIES": 3}})
+	mgr := NewConfigManager(
+		WithCMAccessStats(0, 0),
+		WithCMMetricKeys("MAX_RETRIES"),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}),
+	)
+	_, _ = mgr.GetPublicConfig("API_URL")
+	_, _ = mgr.GetPublicConfig("API_URL")
+
+	var buf bytes.Buffer
+	require.NoError(t, mgr.WriteAccessMetrics(&buf))
+	assert.Contains(t, buf.String(), "# TYPE smooai_config_key_reads_total counter\n")
+	assert.Contains(t, buf.String(), `smooai_config_key_reads_total{key="API_URL"} 2`)
+
+	rec := httptest.NewRecorder()
+	mgr.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `smooai_config_value{key="MAX_RETRIES"} 3`)
+	assert.Contains(t, rec.Body.String(), `smooai_config_key_reads_total{key="API_URL"} 2`)
+
+	rec = httptest.NewRecorder()
+	mgr.AccessStatsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/access", nil))
+	var report AccessReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "API_URL", report.Keys[0].Key)
+	assert.Equal(t, int64(2), report.Keys[0].Total)
+}
+
+func TestAccessStats_OffByDefault(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_URL": "x"}})
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}))
+	_, _ = mgr.GetPublicConfig("API_URL")
+	assert.Empty(t, mgr.AccessStats().Keys)
+	var buf bytes.Buffer
+	require.NoError(t, mgr.WriteAccessMetrics(&buf))
+	assert.False(t, strings.Contains(buf.String(), "smooai_config_key_reads_total"))
+}
@@ -0,0 +1,158 @@
+//go:build !js && !wasip1
+
+package config
+
+// Local agent discovery. On busy hosts a smooai-config-agent can serve the
+// config API to every process from one shared cache. ConfigClient looks for
+// one when it is created and, when found, sends its API requests there
+// instead of to the public API. Whenever the agent can't be reached the
+// request goes to the API directly, so a missing or restarting agent only
+// costs the caching, never availability.
+//
+// Agent contract: the agent serves the config API over plain HTTP on its
+// address. Requests keep their method, path, query, body and Authorization
+// header; X-Smooai-Config-Upstream carries the API base URL the client
+// would otherwise have called. OAuth token requests are not routed through
+// the agent.
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// agentSocketPath is the well-known socket an agent listens on when
+// SMOOAI_CONFIG_AGENT_ADDR is unset.
+var agentSocketPath = "/var/run/smooai-config/agent.sock"
+
+// agentRetryAfter is how long requests skip an agent that couldn't be
+// reached before trying it again.
+var agentRetryAfter = 30 * time.Second
+
+// agentUpstreamHeader names the API base URL a request was meant for.
+const agentUpstreamHeader = "X-Smooai-Config-Upstream"
+
+// WithAgentAddr routes API requests through the smooai-config-agent at addr:
+// a unix socket path ("/run/agent.sock" or "unix:///run/agent.sock") or a
+// TCP "host:port". Defaults to $SMOOAI_CONFIG_AGENT_ADDR, or the socket at
+// /var/run/smooai-config/agent.sock when it exists. Pass "off" to always
+// call the API directly.
+func WithAgentAddr(addr string) ConfigClientOption {
+	return func(c *ConfigClient) {
+		c.agentAddr = addr
+	}
+}
+
+// resolveAgentAddr picks the agent address: the configured one, the env
+// var, or the well-known socket if it exists. Empty means no agent.
+func resolveAgentAddr(configured string) string {
+	addr := configured
+	if addr == "" {
+		addr = os.Getenv("SMOOAI_CONFIG_AGENT_ADDR")
+	}
+	if addr == "" {
+		if info, err := os.Stat(agentSocketPath); err == nil && info.Mode()&os.ModeSocket != 0 {
+			addr = agentSocketPath
+		}
+	}
+	if strings.EqualFold(addr, "off") {
+		return ""
+	}
+	return addr
+}
+
+// agentDialer dials addr as a unix socket or a TCP address.
+func agentDialer(addr string) func(ctx context.Context, _, _ string) (net.Conn, error) {
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(addr, "unix://"):
+		network, addr = "unix", strings.TrimPrefix(addr, "unix://")
+	case strings.HasPrefix(addr, "unix:"):
+		network, addr = "unix", strings.TrimPrefix(addr, "unix:")
+	case strings.HasPrefix(addr, "/") || strings.HasPrefix(addr, "."):
+		network = "unix"
+	}
+	var d net.Dialer
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return d.DialContext(ctx, network, addr)
+	}
+}
+
+// agentTransport sends requests under base to the agent, falling back to
+// direct when the agent can't be reached. Other requests go direct.
+type agentTransport struct {
+	base     *url.URL
+	upstream string
+	agent    *http.Transport
+	direct   http.RoundTripper
+	// downUntil (unix nanos) skips the agent after a failed dial.
+	downUntil atomic.Int64
+}
+
+func newAgentTransport(baseURL, addr string, direct http.RoundTripper) (*agentTransport, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if direct == nil {
+		direct = http.DefaultTransport
+	}
+	return &agentTransport{
+		base:     base,
+		upstream: baseURL,
+		agent:    &http.Transport{DialContext: agentDialer(addr), MaxIdleConnsPerHost: 16, IdleConnTimeout: 90 * time.Second},
+		direct:   direct,
+	}, nil
+}
+
+func (t *agentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.routes(req.URL) || time.Now().UnixNano() < t.downUntil.Load() {
+		return t.direct.RoundTrip(req)
+	}
+
+	agentReq := req.Clone(req.Context())
+	agentReq.URL.Scheme = "http"
+	agentReq.Header.Set(agentUpstreamHeader, t.upstream)
+	resp, err := t.agent.RoundTrip(agentReq)
+	if err == nil {
+		return resp, nil
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "dial" {
+		return nil, err
+	}
+
+	// Nothing was sent, so the request can go direct, given a fresh copy of
+	// the body the failed attempt closed.
+	t.downUntil.Store(time.Now().Add(agentRetryAfter).UnixNano())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, err
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return nil, bodyErr
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+	return t.direct.RoundTrip(req)
+}
+
+// routes reports whether u is an API request under the client's base URL.
+func (t *agentTransport) routes(u *url.URL) bool {
+	return u.Scheme == t.base.Scheme && u.Host == t.base.Host && strings.HasPrefix(u.Path, t.base.Path)
+}
+
+func (t *agentTransport) CloseIdleConnections() {
+	t.agent.CloseIdleConnections()
+	if closer, ok := t.direct.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,25 @@
+//go:build js || wasip1
+
+package config
+
+import (
+	"errors"
+	"net/http"
+)
+
+// WASM hosts have no sockets to reach a smooai-config-agent through, so
+// API requests always go to the API directly.
+
+// WithAgentAddr is accepted for portability and ignored: js/wasm and wasip1
+// builds never route through a local agent.
+func WithAgentAddr(addr string) ConfigClientOption {
+	return func(c *ConfigClient) {
+		c.agentAddr = addr
+	}
+}
+
+func resolveAgentAddr(string) string { return "" }
+
+func newAgentTransport(string, string, http.RoundTripper) (http.RoundTripper, error) {
+	return nil, errors.New("smooai-config agent is not supported on this platform")
+}
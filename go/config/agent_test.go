@@ -0,0 +1,126 @@
+//go:build !js && !wasip1
+
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// agentServer serves the values endpoint on a unix socket, recording each
+// request's upstream header.
+func agentServer(t *testing.T, upstreams chan string) string {
+	t.Helper()
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer "+mockJWT, r.Header.Get("Authorization"))
+		upstreams <- r.Header.Get(agentUpstreamHeader)
+		json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"API_URL": "from-agent"}})
+	}))
+	server.Listener = ln
+	server.Start()
+	t.Cleanup(server.Close)
+	return sock
+}
+
+// directServer issues tokens and serves values, counting value requests.
+func directServer(t *testing.T, valueRequests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": mockJWT, "expires_in": 3600})
+			return
+		}
+		valueRequests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"API_URL": "direct", "BODY": string(body)}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestConfigClient_RoutesThroughAgent(t *testing.T) {
+	var direct atomic.Int32
+	server := directServer(t, &direct)
+	upstreams := make(chan string, 4)
+	sock := agentServer(t, upstreams)
+
+	for _, addr := range []string{sock, "unix://" + sock} {
+		client := NewConfigClient(server.URL, "cid", "secret", testOrgID, WithAuthURL(server.URL), WithAgentAddr(addr))
+		values, err := client.GetAllValues("production")
+		require.NoError(t, err)
+		assert.Equal(t, "from-agent", values["API_URL"])
+		assert.Equal(t, server.URL, <-upstreams)
+		client.Close()
+	}
+	assert.Zero(t, direct.Load(), "no value request reached the API")
+}
+
+func TestConfigClient_AgentFallback(t *testing.T) {
+	var direct atomic.Int32
+	server := directServer(t, &direct)
+	missing := filepath.Join(t.TempDir(), "agent.sock")
+
+	client := NewConfigClient(server.URL, "cid", "secret", testOrgID, WithAuthURL(server.URL), WithAgentAddr(missing))
+	defer client.Close()
+	values, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	assert.Equal(t, "direct", values["API_URL"])
+	assert.Equal(t, int32(1), direct.Load())
+}
+
+func TestAgentTransport_FallbackResendsBody(t *testing.T) {
+	var direct atomic.Int32
+	server := directServer(t, &direct)
+	transport, err := newAgentTransport(server.URL, filepath.Join(t.TempDir(), "agent.sock"), nil)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/organizations/x/config/values", strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var got valuesResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	assert.Equal(t, "payload", got.Values["BODY"])
+	assert.Positive(t, transport.downUntil.Load(), "the agent is skipped for a while")
+}
+
+func TestResolveAgentAddr(t *testing.T) {
+	orig := agentSocketPath
+	t.Cleanup(func() { agentSocketPath = orig })
+	agentSocketPath = filepath.Join(t.TempDir(), "agent.sock")
+
+	t.Setenv("SMOOAI_CONFIG_AGENT_ADDR", "")
+	assert.Equal(t, "", resolveAgentAddr(""), "no socket, no agent")
+
+	ln, err := net.Listen("unix", agentSocketPath)
+	require.NoError(t, err)
+	defer ln.Close()
+	assert.Equal(t, agentSocketPath, resolveAgentAddr(""))
+	assert.Equal(t, "", resolveAgentAddr("off"))
+
+	t.Setenv("SMOOAI_CONFIG_AGENT_ADDR", "127.0.0.1:7000")
+	assert.Equal(t, "127.0.0.1:7000", resolveAgentAddr(""))
+	assert.Equal(t, "/run/other.sock", resolveAgentAddr("/run/other.sock"))
+
+	// A regular file at the well-known path is not an agent.
+	t.Setenv("SMOOAI_CONFIG_AGENT_ADDR", "")
+	ln.Close()
+	os.Remove(agentSocketPath)
+	require.NoError(t, os.WriteFile(agentSocketPath, nil, 0o644))
+	assert.Equal(t, "", resolveAgentAddr(""))
+}
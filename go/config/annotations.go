@@ -0,0 +1,116 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AnnotationWebhook posts an annotation to an operational timeline whenever
+// the manager's effective config changes. The JSON payload works unchanged
+// with Grafana's annotations API (POST /api/annotations) and Slack incoming
+// webhooks:
+//
+//	{"time": 1718000000000, "tags": ["smooai-config", "production"],
+//	 "text": "Config changed in production: API_URL=\"https://...\", DB_PASSWORD=[redacted]",
+//	 "keys": ["API_URL", "DB_PASSWORD"]}
+//
+// Only values of keys known to be public or feature flags (per
+// WithCMKeyTiers, the schema or the API's tier metadata) are shown; every
+// other value, secret or of unknown tier, is redacted.
+type AnnotationWebhook struct {
+	// URL receives the POST.
+	URL string
+	// Headers are added to every request, e.g. {"Authorization": "Bearer <grafana token>"}.
+	Headers map[string]string
+	// Tags are appended to the default "smooai-config" and environment tags.
+	Tags []string
+	// HTTPClient sends the request. Defaults to http.DefaultClient; each
+	// request times out after 10s regardless.
+	HTTPClient *http.Client
+}
+
+type annotationPayload struct {
+	Time int64    `json:"time"`
+	Tags []string `json:"tags"`
+	Text string   `json:"text"`
+	Keys []string `json:"keys"`
+}
+
+// WithChangeAnnotations posts to hook whenever a reload, pushed change
+// (Subscribe) or override changes the effective config. Delivery is
+// best-effort: failures are logged and never affect config reads.
+func WithChangeAnnotations(hook AnnotationWebhook) ConfigManagerOption {
+	return func(m *ConfigManager) {
+		m.changeListeners = append(m.changeListeners, func(evt ConfigChangeEvent) {
+			if err := hook.post(m.annotationPayload(evt, hook.Tags)); err != nil {
+				m.log().Warn("failed to post config change annotation", "error", err)
+			}
+		})
+	}
+}
+
+// annotationPayload renders evt with every value not known to be public or
+// a feature flag redacted, as SafeDump does.
+func (m *ConfigManager) annotationPayload(evt ConfigChangeEvent, tags []string) annotationPayload {
+	m.mu.Lock()
+	parts := make([]string, len(evt.Keys))
+	for i, key := range evt.Keys {
+		v, ok := evt.Values[key]
+		switch {
+		case !ok:
+			parts[i] = key + " removed"
+		case !m.revealableLocked(key):
+			parts[i] = key + "=" + redactedValue
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				data = []byte(fmt.Sprint(v))
+			}
+			parts[i] = key + "=" + string(data)
+		}
+	}
+	m.mu.Unlock()
+
+	return annotationPayload{
+		Time: time.Now().UnixMilli(),
+		Tags: append([]string{"smooai-config", evt.Environment}, tags...),
+		Text: fmt.Sprintf("Config changed in %s: %s", evt.Environment, strings.Join(parts, ", ")),
+		Keys: evt.Keys,
+	}
+}
+
+func (h AnnotationWebhook) post(payload annotationPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := h.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newHTTPError("change annotation", resp)
+	}
+	return nil
+}
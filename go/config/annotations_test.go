@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// annotationSink records annotation POSTs.
+func annotationSink(t *testing.T) (*httptest.Server, chan annotationPayload) {
+	t.Helper()
+	got := make(chan annotationPayload, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer grafana", r.Header.Get("Authorization"))
+		var p annotationPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		got <- p
+	}))
+	t.Cleanup(server.Close)
+	return server, got
+}
+
+func receiveAnnotation(t *testing.T, got chan annotationPayload) annotationPayload {
+	t.Helper()
+	select {
+	case p := <-got:
+		return p
+	case <-time.After(2 * time.Second):
+		t.Fatal("no annotation posted")
+		return annotationPayload{}
+	}
+}
+
+func TestChangedKeys(t *testing.T) {
+	before := map[string]any{"A": 1.0, "B": map[string]any{"x": 1.0}, "GONE": true}
+	after := map[string]any{"A": 1.0, "B": map[string]any{"x": 2.0}, "NEW": "v"}
+	assert.Equal(t, []string{"B", "GONE", "NEW"}, changedKeys(before, after))
+	assert.Equal(t, []string{"B"}, changedKeys(before, after, "A", "B"))
+	assert.Empty(t, changedKeys(before, before))
+}
+
+func TestConfigManager_AnnotatesReloadChanges(t *testing.T) {
+	var mu sync.Mutex
+	values := map[string]any{"API_URL": "https://v1", "DB_PASSWORD": "old", "SIGNING_SALT": "old", "STABLE": "same"}
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"values": values})
+	}))
+	defer remote.Close()
+	sink, got := annotationSink(t)
+
+	mgr := newStalenessManager(t, remote.URL,
+		WithCMKeyTiers(map[string]ConfigTier{"API_URL": TierPublic, "DB_PASSWORD": TierSecret}),
+		WithChangeAnnotations(AnnotationWebhook{
+			URL:     sink.URL,
+			Headers: map[string]string{"Authorization": "Bearer grafana"},
+			Tags:    []string{"deploy"},
+		}),
+	)
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+
+	mu.Lock()
+	values = map[string]any{"API_URL": "https://v2", "DB_PASSWORD": "new", "SIGNING_SALT": "new", "STABLE": "same"}
+	mu.Unlock()
+	mgr.Invalidate()
+	_, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+
+	p := receiveAnnotation(t, got)
+	assert.Equal(t, []string{"API_URL", "DB_PASSWORD", "SIGNING_SALT"}, p.Keys)
+	assert.Equal(t, []string{"smooai-config", "production", "deploy"}, p.Tags)
+	// SIGNING_SALT has no known tier, so it is redacted like a secret.
+	assert.Equal(t, `Config changed in production: API_URL="https://v2", DB_PASSWORD=[redacted], SIGNING_SALT=[redacted]`, p.Text)
+	assert.NotContains(t, p.Text, "new")
+	assert.NotZero(t, p.Time)
+
+	// An unchanged reload posts nothing.
+	mgr.Invalidate()
+	_, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	select {
+	case p := <-got:
+		t.Fatalf("unexpected annotation: %+v", p)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestConfigManager_AnnotatesPushedChanges(t *testing.T) {
+	server, frames := sseServer(t, map[string]any{"A": "old"}, nil)
+	sink, got := annotationSink(t)
+
+	mgr := NewConfigManager(
+		WithAPIKey("secret"),
+		WithBaseURL(server.URL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMKeyTiers(map[string]ConfigTier{"A": TierPublic}),
+		WithChangeAnnotations(AnnotationWebhook{URL: sink.URL, Headers: map[string]string{"Authorization": "Bearer grafana"}}),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_AUTH_URL": server.URL,
+			"SMOOAI_ENV_CONFIG_DIR":  makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+		}),
+	)
+	sub, err := mgr.Subscribe(context.Background())
+	require.NoError(t, err)
+	defer sub.Close()
+
+	frames <- `{"keys":["A"],"values":{"A":"new"}}`
+	receiveEvent(t, sub)
+
+	p := receiveAnnotation(t, got)
+	assert.Equal(t, []string{"A"}, p.Keys)
+	assert.Equal(t, `Config changed in production: A="new"`, p.Text)
+}
@@ -0,0 +1,60 @@
+package config
+
+// Soft-deleted (archived) remote keys. The API can keep a key around after
+// it is archived, marking it in the values metadata:
+//
+//	{"values": {...}, "metadata": {"OLD_KEY": {"archived": true, "archivedAt": "RFC3339"}}}
+//
+// ConfigManager drops the remote value of an archived key, so it reads as
+// absent unless a file or env var still sets it, and warns the first time
+// code reads it so the read can be removed before the key is purged.
+
+import (
+	"time"
+)
+
+// ArchivedKeys returns the keys the API marked archived on the last remote
+// fetch, each with when it was archived (zero if the API didn't say). It
+// never triggers a load.
+func (m *ConfigManager) ArchivedKeys() map[string]time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	archived := make(map[string]time.Time, len(m.archivedKeys))
+	for k, v := range m.archivedKeys {
+		archived[k] = v
+	}
+	return archived
+}
+
+// warnArchivedLocked warns, once per key, about a read of an archived key.
+// Must be called under m.mu.
+func (m *ConfigManager) warnArchivedLocked(key string) {
+	archivedAt, ok := m.archivedKeys[key]
+	if !ok || m.archivedWarned[key] {
+		return
+	}
+	if m.archivedWarned == nil {
+		m.archivedWarned = make(map[string]bool)
+	}
+	m.archivedWarned[key] = true
+	attrs := []any{"key", key}
+	if !archivedAt.IsZero() {
+		attrs = append(attrs, "archived_at", archivedAt.Format(time.DateOnly))
+	}
+	m.log().Warn("read of an archived key, which is no longer served remotely; remove it before the key is purged", attrs...)
+}
+
+// withoutKeys returns values minus drop, copying only when something is
+// dropped.
+func withoutKeys(values map[string]any, drop map[string]time.Time) map[string]any {
+	if len(drop) == 0 {
+		return values
+	}
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		if _, ok := drop[k]; !ok {
+			out[k] = v
+		}
+	}
+	return out
+}
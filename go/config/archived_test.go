@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var archivedAt = time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+// archivedValuesResponse serves OLD_URL and LEGACY_FLAG as archived.
+func archivedValuesResponse(w http.ResponseWriter) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"values": map[string]any{"API_URL": "https://api", "OLD_URL": "https://old", "LEGACY_FLAG": true},
+		"metadata": map[string]any{
+			"OLD_URL":     map[string]any{"archived": true, "archivedAt": archivedAt.Format(time.RFC3339)},
+			"LEGACY_FLAG": map[string]any{"archived": true},
+			"API_URL":     map[string]any{"maxAge": 60},
+		},
+	})
+}
+
+func TestConfigClient_ArchivedKeys(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) { archivedValuesResponse(w) })
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	assert.Empty(t, client.ArchivedKeys("production"), "nothing before a fetch")
+	values, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	assert.Contains(t, values, "OLD_URL", "the client returns what the server sent")
+	assert.Equal(t, map[string]time.Time{"OLD_URL": archivedAt, "LEGACY_FLAG": {}}, client.ArchivedKeys("production"))
+	assert.Empty(t, client.ArchivedKeys("staging"))
+}
+
+func TestConfigManager_ArchivedKeysReadAsAbsent(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": mockJWT, "expires_in": 3600, "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/organizations/", func(w http.ResponseWriter, r *http.Request) { archivedValuesResponse(w) })
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	configDir := makeCMConfigDir(t, map[string]any{
+		"default.json": map[string]any{"LEGACY_FLAG": false},
+	})
+	mgr := NewConfigManager(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_AUTH_URL": server.URL,
+			"SMOOAI_ENV_CONFIG_DIR":  configDir,
+			"SMOOAI_CONFIG_ENV":      "test",
+		}),
+	)
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api", v)
+
+	v, err = mgr.GetPublicConfig("OLD_URL")
+	require.NoError(t, err)
+	assert.Nil(t, v, "an archived remote value is dropped")
+
+	v, err = mgr.GetFeatureFlag("LEGACY_FLAG")
+	require.NoError(t, err)
+	assert.Equal(t, false, v, "a file value for an archived key still applies")
+
+	assert.Equal(t, map[string]time.Time{"OLD_URL": archivedAt, "LEGACY_FLAG": {}}, mgr.ArchivedKeys())
+	mgr.mu.Lock()
+	assert.Equal(t, map[string]bool{"OLD_URL": true, "LEGACY_FLAG": true}, mgr.archivedWarned, "each read key warned once")
+	mgr.mu.Unlock()
+}
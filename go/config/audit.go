@@ -0,0 +1,97 @@
+package config
+
+// Audit trail — who changed which value and when, so deployment tooling can
+// annotate incidents with recent config changes.
+//
+// Server contract:
+//
+//	GET {BaseURL}/organizations/{orgId}/config/values/{key}/history?environment=...[&pageToken=...]
+//	GET {BaseURL}/organizations/{orgId}/config/changes?since=RFC3339[&pageToken=...]
+//	{"changes": [{"key": "...", "environment": "...", "version": 7, "tier": "public",
+//	              "oldValue": ..., "newValue": ..., "changedBy": "...",
+//	              "changedAt": "RFC3339"}],
+//	 "nextPageToken": "..."}
+//
+// Entries are newest first. Secret-tier entries carry no values. An empty or
+// missing nextPageToken marks the last page.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxAuditPages bounds GetHistory and GetChangesSince like maxListKeysPages.
+const maxAuditPages = 1000
+
+// ValueChange is one write to a config value. Version is the value's
+// version after the change. OldValue and NewValue are nil for secret-tier
+// keys, and NewValue is nil when the change deleted the key.
+type ValueChange struct {
+	Key         string     `json:"key"`
+	Environment string     `json:"environment"`
+	Version     int64      `json:"version"`
+	Tier        ConfigTier `json:"tier,omitempty"`
+	OldValue    any        `json:"oldValue,omitempty"`
+	NewValue    any        `json:"newValue,omitempty"`
+	ChangedBy   string     `json:"changedBy,omitempty"`
+	ChangedAt   time.Time  `json:"changedAt"`
+}
+
+type changesPage struct {
+	Changes       []ValueChange `json:"changes"`
+	NextPageToken string        `json:"nextPageToken"`
+}
+
+// GetHistory returns every change to key in environment, newest first,
+// following pagination to the end. Pass an empty environment to use the
+// client's default.
+func (c *ConfigClient) GetHistory(key, environment string) ([]ValueChange, error) {
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("/config/values/%s/history", url.PathEscape(key))
+	return c.listChanges("history", path, url.Values{"environment": {env}})
+}
+
+// GetChangesSince returns every change made at or after since, in any
+// environment, newest first.
+func (c *ConfigClient) GetChangesSince(since time.Time) ([]ValueChange, error) {
+	return c.listChanges("changes", "/config/changes", url.Values{"since": {since.UTC().Format(time.RFC3339)}})
+}
+
+// listChanges reads every page of an audit endpoint below the org URL.
+func (c *ConfigClient) listChanges(op, path string, q url.Values) ([]ValueChange, error) {
+	var changes []ValueChange
+	for i := 0; i < maxAuditPages; i++ {
+		u := fmt.Sprintf("%s%s?%s", c.orgURL(), path, q.Encode())
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("config %s: %w", op, err)
+		}
+		resp, err := c.doRequestWithRetry(req)
+		if err != nil {
+			return nil, fmt.Errorf("config %s: %w", op, err)
+		}
+		var page changesPage
+		if resp.StatusCode != http.StatusOK {
+			err = newHTTPError(op, resp)
+		} else if derr := json.NewDecoder(resp.Body).Decode(&page); derr != nil {
+			err = fmt.Errorf("config %s decode: %w", op, derr)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, page.Changes...)
+		if page.NextPageToken == "" {
+			return changes, nil
+		}
+		q.Set("pageToken", page.NextPageToken)
+	}
+	return nil, fmt.Errorf("config %s: more than %d pages", op, maxAuditPages)
+}
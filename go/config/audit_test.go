@@ -0,0 +1,89 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHistory(t *testing.T) {
+	var tokens []string
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/organizations/"+testOrgID+"/config/values/API%20URL/history", r.URL.EscapedPath())
+		assert.Equal(t, "production", r.URL.Query().Get("environment"))
+		token := r.URL.Query().Get("pageToken")
+		tokens = append(tokens, token)
+		switch token {
+		case "":
+			json.NewEncoder(w).Encode(map[string]any{
+				"changes": []map[string]any{{
+					"key": "API URL", "environment": "production", "version": 2, "tier": "public",
+					"oldValue": "https://old", "newValue": "https://new",
+					"changedBy": "alice", "changedAt": "2026-10-01T10:00:00Z",
+				}},
+				"nextPageToken": "p2",
+			})
+		case "p2":
+			json.NewEncoder(w).Encode(map[string]any{
+				"changes": []map[string]any{{"key": "API URL", "environment": "production", "version": 1, "newValue": "https://old"}},
+			})
+		}
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	changes, err := client.GetHistory("API URL", "production")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "p2"}, tokens)
+	require.Len(t, changes, 2)
+	assert.Equal(t, ValueChange{
+		Key: "API URL", Environment: "production", Version: 2, Tier: TierPublic,
+		OldValue: "https://old", NewValue: "https://new",
+		ChangedBy: "alice", ChangedAt: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+	}, changes[0])
+	assert.Nil(t, changes[1].OldValue)
+}
+
+func TestGetChangesSince(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/organizations/"+testOrgID+"/config/changes", r.URL.Path)
+		assert.Equal(t, "2026-10-16T07:00:00Z", r.URL.Query().Get("since"))
+		assert.Empty(t, r.URL.Query().Get("environment"), "changes span every environment")
+		json.NewEncoder(w).Encode(map[string]any{
+			"changes": []map[string]any{
+				{"key": "DB_PASSWORD", "environment": "staging", "version": 4, "tier": "secret", "changedBy": "ci"},
+			},
+		})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	since := time.Date(2026, 10, 16, 9, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	changes, err := client.GetChangesSince(since)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "staging", changes[0].Environment)
+	assert.Equal(t, TierSecret, changes[0].Tier)
+	assert.Nil(t, changes[0].NewValue)
+}
+
+func TestGetChangesSince_HTTPError(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.GetChangesSince(time.Now())
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,264 @@
+package config
+
+// Shared plumbing for the AWS integrations (AWSSecretsManager,
+// WithCMSSMParameters): options, and SigV4-signed calls to AWS JSON-protocol
+// APIs, so they need no AWS SDK.
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSOption configures the AWS integrations.
+type AWSOption func(*awsClient)
+
+// WithAWSCredentials sets the credentials instead of the default chain.
+func WithAWSCredentials(c AWSCredentialsProvider) AWSOption {
+	return func(a *awsClient) { a.credentials = c }
+}
+
+// WithAWSEndpoint sends requests to url instead of the regional endpoint,
+// e.g. LocalStack's http://localhost:4566.
+func WithAWSEndpoint(url string) AWSOption {
+	return func(a *awsClient) { a.endpoint = strings.TrimRight(url, "/") }
+}
+
+// WithAWSRegion sets the region for services whose resource names don't
+// carry one. The default is AWS_REGION, then AWS_DEFAULT_REGION.
+func WithAWSRegion(region string) AWSOption {
+	return func(a *awsClient) { a.region = region }
+}
+
+// WithAWSEnv reads AWS_* variables (credentials, region, endpoints) through
+// lookup instead of the process environment.
+func WithAWSEnv(lookup EnvFunc) AWSOption {
+	return func(a *awsClient) { a.lookupEnv = lookup }
+}
+
+// WithAWSHTTPClient sets the HTTP client for AWS and credential requests.
+func WithAWSHTTPClient(c *http.Client) AWSOption {
+	return func(a *awsClient) { a.client = c }
+}
+
+type awsClient struct {
+	credentials AWSCredentialsProvider
+	client      *http.Client
+	lookupEnv   EnvFunc
+	endpoint    string
+	region      string
+	now         func() time.Time
+}
+
+func newAWSClient(opts []AWSOption) *awsClient {
+	a := &awsClient{client: http.DefaultClient, lookupEnv: os.LookupEnv, now: time.Now}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.credentials == nil {
+		a.credentials = DefaultAWSCredentials(a.lookupEnv, a.client)
+	}
+	return a
+}
+
+// awsService names an AWS JSON-protocol API.
+type awsService struct {
+	// name is the signing name and endpoint host prefix.
+	name string
+	// target prefixes the action in X-Amz-Target.
+	target string
+	// endpointEnv is the service's AWS_ENDPOINT_URL_* override.
+	endpointEnv string
+}
+
+// defaultRegion returns WithAWSRegion, then AWS_REGION, then
+// AWS_DEFAULT_REGION.
+func (a *awsClient) defaultRegion() string {
+	if a.region != "" {
+		return a.region
+	}
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if v, ok := a.lookupEnv(name); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// endpointFor returns WithAWSEndpoint, the service's AWS_ENDPOINT_URL_*
+// variable, AWS_ENDPOINT_URL, then the regional public endpoint.
+func (a *awsClient) endpointFor(svc awsService, partition, region string) string {
+	if a.endpoint != "" {
+		return a.endpoint
+	}
+	for _, name := range []string{svc.endpointEnv, "AWS_ENDPOINT_URL"} {
+		if v, ok := a.lookupEnv(name); ok && v != "" {
+			return strings.TrimRight(v, "/")
+		}
+	}
+	return "https://" + svc.name + "." + region + "." + awsDNSSuffix(partition)
+}
+
+// call invokes action on svc with a signed request and decodes the JSON
+// response into out.
+func (a *awsClient) call(ctx context.Context, svc awsService, partition, region, action string, in, out any) error {
+	creds, err := a.credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpointFor(svc, partition, region)+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", svc.target+"."+action)
+	signAWSRequest(req, body, creds, region, svc.name, a.now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return awsJSONError(svc.name, resp.StatusCode, data)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s: decoding response: %w", svc.name, err)
+	}
+	return nil
+}
+
+// awsPartition returns the partition a region belongs to.
+func awsPartition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	}
+	return "aws"
+}
+
+func awsDNSSuffix(partition string) string {
+	if partition == "aws-cn" {
+		return "amazonaws.com.cn"
+	}
+	return "amazonaws.com"
+}
+
+// awsJSONError turns a JSON-protocol error body, e.g.
+// {"__type":"ResourceNotFoundException","message":"..."}, into an error.
+func awsJSONError(service string, status int, body []byte) error {
+	var e struct {
+		Type    string `json:"__type"`
+		Message string `json:"message"`
+		Msg     string `json:"Message"`
+	}
+	_ = json.Unmarshal(body, &e)
+	code := e.Type
+	if i := strings.LastIndexByte(code, '#'); i >= 0 {
+		code = code[i+1:]
+	}
+	if code == "" {
+		return fmt.Errorf("%s: HTTP %d: %s", service, status, strings.TrimSpace(string(body)))
+	}
+	return fmt.Errorf("%s: %s: %s (HTTP %d)", service, code, coalesceStr(e.Message, e.Msg), status)
+}
+
+// signAWSRequest adds Signature Version 4 headers to req, signing the host
+// and every header already set. body is the request payload.
+func signAWSRequest(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, awsURIEncode(key)+"="+awsURIEncode(v))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes everything but the unreserved characters, as
+// SigV4 requires.
+func awsURIEncode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AWSCredentials are the keys SigV4 signs requests with.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expires is when temporary credentials stop working; zero for
+	// long-lived keys.
+	Expires time.Time
+}
+
+// AWSCredentialsProvider supplies AWS credentials.
+type AWSCredentialsProvider interface {
+	Retrieve(ctx context.Context) (AWSCredentials, error)
+}
+
+// StaticAWSCredentials returns fixed credentials.
+func StaticAWSCredentials(accessKeyID, secretAccessKey, sessionToken string) AWSCredentialsProvider {
+	return staticAWSCredentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, SessionToken: sessionToken}
+}
+
+type staticAWSCredentials AWSCredentials
+
+func (c staticAWSCredentials) Retrieve(context.Context) (AWSCredentials, error) {
+	return AWSCredentials(c), nil
+}
+
+// awsCredentialsRefreshWindow is how long before expiry temporary
+// credentials are fetched again.
+const awsCredentialsRefreshWindow = 5 * time.Minute
+
+var errNoAWSCredentials = errors.New("no AWS credentials found (checked AWS_ACCESS_KEY_ID, web identity, container credentials and EC2 instance metadata)")
+
+// DefaultAWSCredentials returns the standard AWS credential chain, reading
+// variables through lookupEnv (nil for the process environment):
+//
+//  1. AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN
+//  2. Web identity (EKS IRSA): AWS_WEB_IDENTITY_TOKEN_FILE and AWS_ROLE_ARN,
+//     exchanged through STS AssumeRoleWithWebIdentity
+//  3. Container credentials (ECS task roles, EKS Pod Identity):
+//     AWS_CONTAINER_CREDENTIALS_RELATIVE_URI or _FULL_URI. As in the AWS
+//     SDKs, a _FULL_URI must be https, loopback, or one of the ECS and EKS
+//     agent addresses (169.254.170.2, 169.254.170.23, fd00:ec2::23), so a
+//     tampered variable can't send the authorization token elsewhere.
+//  4. EC2 instance metadata (IMDSv2), unless AWS_EC2_METADATA_DISABLED=true
+//
+// Shared config and credentials files (~/.aws/config, ~/.aws/credentials,
+// AWS_PROFILE) are not read; export the keys or pass
+// StaticAWSCredentials instead. Temporary credentials are cached until five
+// minutes before they expire.
+func DefaultAWSCredentials(lookupEnv EnvFunc, client *http.Client) AWSCredentialsProvider {
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &awsCredentialChain{lookupEnv: lookupEnv, client: client, now: time.Now}
+}
+
+type awsCredentialChain struct {
+	lookupEnv EnvFunc
+	client    *http.Client
+	now       func() time.Time
+
+	mu     sync.Mutex
+	cached AWSCredentials
+}
+
+func (c *awsCredentialChain) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	if id, secret := c.env("AWS_ACCESS_KEY_ID"), c.env("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return AWSCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: c.env("AWS_SESSION_TOKEN")}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached.AccessKeyID != "" && c.now().Before(c.cached.Expires.Add(-awsCredentialsRefreshWindow)) {
+		return c.cached, nil
+	}
+	var (
+		creds AWSCredentials
+		err   error
+	)
+	switch {
+	case c.env("AWS_WEB_IDENTITY_TOKEN_FILE") != "" && c.env("AWS_ROLE_ARN") != "":
+		creds, err = c.webIdentity(ctx)
+	case c.env("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "" || c.env("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "":
+		creds, err = c.container(ctx)
+	case !strings.EqualFold(c.env("AWS_EC2_METADATA_DISABLED"), "true"):
+		creds, err = c.instanceMetadata(ctx)
+		if err != nil {
+			err = fmt.Errorf("%w: instance metadata: %v", errNoAWSCredentials, err)
+		}
+	default:
+		err = errNoAWSCredentials
+	}
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	c.cached = creds
+	return creds, nil
+}
+
+func (c *awsCredentialChain) env(name string) string {
+	v, _ := c.lookupEnv(name)
+	return v
+}
+
+// webIdentity exchanges the projected service account token for role
+// credentials. AssumeRoleWithWebIdentity is called unsigned.
+func (c *awsCredentialChain) webIdentity(ctx context.Context) (AWSCredentials, error) {
+	token, err := os.ReadFile(c.env("AWS_WEB_IDENTITY_TOKEN_FILE"))
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("web identity: %w", err)
+	}
+	endpoint := c.env("AWS_ENDPOINT_URL_STS")
+	if endpoint == "" {
+		endpoint = "https://sts.amazonaws.com"
+		if region := coalesceStr(c.env("AWS_REGION"), c.env("AWS_DEFAULT_REGION")); region != "" {
+			endpoint = "https://sts." + region + ".amazonaws.com"
+		}
+	}
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {c.env("AWS_ROLE_ARN")},
+		"RoleSessionName":  {coalesceStr(c.env("AWS_ROLE_SESSION_NAME"), "smooai-config")},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	data, err := c.do(req)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("web identity: %w", err)
+	}
+	var out struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(data, &out); err != nil {
+		return AWSCredentials{}, fmt.Errorf("web identity: decoding response: %w", err)
+	}
+	cr := out.Credentials
+	return AWSCredentials{AccessKeyID: cr.AccessKeyID, SecretAccessKey: cr.SecretAccessKey, SessionToken: cr.SessionToken, Expires: cr.Expiration}, nil
+}
+
+// container fetches task role credentials from the ECS agent or the EKS Pod
+// Identity agent.
+func (c *awsCredentialChain) container(ctx context.Context) (AWSCredentials, error) {
+	endpoint := c.env("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if rel := c.env("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); rel != "" {
+		endpoint = "http://169.254.170.2" + rel
+	} else if err := checkContainerCredentialsURI(endpoint); err != nil {
+		return AWSCredentials{}, fmt.Errorf("container credentials: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	token := c.env("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if file := c.env("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return AWSCredentials{}, fmt.Errorf("container credentials: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	data, err := c.do(req)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("container credentials: %w", err)
+	}
+	return decodeAWSRoleCredentials(data, "container credentials")
+}
+
+// containerAgentHosts are the ECS and EKS Pod Identity agent addresses.
+var containerAgentHosts = []string{"169.254.170.2", "169.254.170.23", "fd00:ec2::23"}
+
+// checkContainerCredentialsURI rejects an AWS_CONTAINER_CREDENTIALS_FULL_URI
+// that isn't https, loopback or a container agent address.
+func checkContainerCredentialsURI(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("AWS_CONTAINER_CREDENTIALS_FULL_URI: %w", err)
+	}
+	if u.Scheme == "https" {
+		return nil
+	}
+	if u.Scheme == "http" {
+		host := u.Hostname()
+		if host == "localhost" {
+			return nil
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			if ip.IsLoopback() {
+				return nil
+			}
+			for _, agent := range containerAgentHosts {
+				if ip.Equal(net.ParseIP(agent)) {
+					return nil
+				}
+			}
+		}
+	}
+	return fmt.Errorf("AWS_CONTAINER_CREDENTIALS_FULL_URI %q must use https or a loopback or container agent host", raw)
+}
+
+// instanceMetadata fetches the instance profile's credentials over IMDSv2.
+func (c *awsCredentialChain) instanceMetadata(ctx context.Context) (AWSCredentials, error) {
+	endpoint := strings.TrimRight(coalesceStr(c.env("AWS_EC2_METADATA_SERVICE_ENDPOINT"), "http://169.254.169.254"), "/")
+	// Off EC2 the metadata address usually hangs rather than refusing;
+	// don't let it eat the whole resolution timeout.
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	token, err := c.do(req)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	get := func(path string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-aws-ec2-metadata-token", string(token))
+		return c.do(req)
+	}
+	roles, err := get("/latest/meta-data/iam/security-credentials/")
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	role, _, _ := strings.Cut(strings.TrimSpace(string(roles)), "\n")
+	if role == "" {
+		return AWSCredentials{}, errors.New("no instance profile attached")
+	}
+	data, err := get("/latest/meta-data/iam/security-credentials/" + role)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	return decodeAWSRoleCredentials(data, "instance metadata")
+}
+
+func (c *awsCredentialChain) do(req *http.Request) ([]byte, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// decodeAWSRoleCredentials decodes the JSON shared by the container and
+// instance metadata endpoints.
+func decodeAWSRoleCredentials(data []byte, source string) (AWSCredentials, error) {
+	var out struct {
+		AccessKeyID     string    `json:"AccessKeyId"`
+		SecretAccessKey string    `json:"SecretAccessKey"`
+		Token           string    `json:"Token"`
+		Expiration      time.Time `json:"Expiration"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return AWSCredentials{}, fmt.Errorf("%s: decoding response: %w", source, err)
+	}
+	if out.AccessKeyID == "" {
+		return AWSCredentials{}, fmt.Errorf("%s: response has no AccessKeyId", source)
+	}
+	return AWSCredentials{AccessKeyID: out.AccessKeyID, SecretAccessKey: out.SecretAccessKey, SessionToken: out.Token, Expires: out.Expiration}, nil
+}
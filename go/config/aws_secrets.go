@@ -0,0 +1,72 @@
+package config
+
+// AWS Secrets Manager provider for smooai-secret:// references. It calls
+// GetSecretValue over the JSON protocol with a SigV4-signed request, so it
+// needs no AWS SDK. Credentials come from the default chain (see
+// DefaultAWSCredentials), which covers IAM roles on ECS, EKS and EC2.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+var secretsManagerService = awsService{name: "secretsmanager", target: "secretsmanager", endpointEnv: "AWS_ENDPOINT_URL_SECRETS_MANAGER"}
+
+// AWSSecretsManager resolves references that are Secrets Manager ARNs:
+//
+//	smooai-secret://arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf
+//
+// The region comes from the ARN. The endpoint is, in order, WithAWSEndpoint,
+// AWS_ENDPOINT_URL_SECRETS_MANAGER, AWS_ENDPOINT_URL, then the regional
+// public endpoint.
+type AWSSecretsManager struct {
+	aws *awsClient
+}
+
+// NewAWSSecretsManager creates a Secrets Manager provider.
+func NewAWSSecretsManager(opts ...AWSOption) *AWSSecretsManager {
+	return &AWSSecretsManager{aws: newAWSClient(opts)}
+}
+
+// Handles reports whether ref is a Secrets Manager ARN.
+func (p *AWSSecretsManager) Handles(ref string) bool {
+	_, ok := parseSecretARN(ref)
+	return ok
+}
+
+// Resolve fetches the current version of the secret: SecretString, or the
+// decoded SecretBinary.
+func (p *AWSSecretsManager) Resolve(ctx context.Context, ref string) (string, error) {
+	arn, ok := parseSecretARN(ref)
+	if !ok {
+		return "", fmt.Errorf("not a Secrets Manager ARN: %s", ref)
+	}
+	var out struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"` // base64 in JSON
+	}
+	err := p.aws.call(ctx, secretsManagerService, arn.partition, arn.region, "GetSecretValue", map[string]string{"SecretId": ref}, &out)
+	if err != nil {
+		return "", err
+	}
+	if out.SecretString != nil {
+		return *out.SecretString, nil
+	}
+	return string(out.SecretBinary), nil
+}
+
+// secretARN is the part of arn:partition:secretsmanager:region:account:secret:name
+// the provider needs.
+type secretARN struct {
+	partition string
+	region    string
+}
+
+func parseSecretARN(ref string) (secretARN, bool) {
+	parts := strings.SplitN(ref, ":", 7)
+	if len(parts) != 7 || parts[0] != "arn" || parts[2] != "secretsmanager" || parts[3] == "" || parts[5] != "secret" || parts[6] == "" {
+		return secretARN{}, false
+	}
+	return secretARN{partition: parts[1], region: parts[3]}, true
+}
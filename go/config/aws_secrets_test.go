@@ -0,0 +1,223 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecretARN = "arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf"
+
+// envLookup adapts a map to an EnvFunc.
+func envLookup(env map[string]string) EnvFunc {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func TestSignAWSRequest_GetVanilla(t *testing.T) {
+	// "get-vanilla" from the AWS SigV4 test suite.
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signAWSRequest(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestParseSecretARN(t *testing.T) {
+	arn, ok := parseSecretARN(testSecretARN)
+	require.True(t, ok)
+	assert.Equal(t, secretARN{partition: "aws", region: "us-east-1"}, arn)
+
+	for _, ref := range []string{
+		"prod/db",
+		"arn:aws:ssm:us-east-1:123456789012:parameter/db",
+		"arn:aws:secretsmanager:us-east-1:123456789012:secret:",
+	} {
+		_, ok := parseSecretARN(ref)
+		assert.False(t, ok, ref)
+	}
+}
+
+func TestAWSSecretsManager_Resolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-east-1/secretsmanager/aws4_request")
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch body["SecretId"] {
+		case testSecretARN:
+			json.NewEncoder(w).Encode(map[string]any{"ARN": testSecretARN, "SecretString": `{"password":"hunter2"}`})
+		case strings.Replace(testSecretARN, "db", "cert", 1):
+			json.NewEncoder(w).Encode(map[string]any{"SecretBinary": []byte("binary")})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`)
+		}
+	}))
+	defer server.Close()
+
+	p := NewAWSSecretsManager(
+		WithAWSCredentials(StaticAWSCredentials("AKID", "secret", "session")),
+		WithAWSEnv(envLookup(map[string]string{"AWS_ENDPOINT_URL_SECRETS_MANAGER": server.URL})),
+	)
+	assert.True(t, p.Handles(testSecretARN))
+
+	payload, err := p.Resolve(context.Background(), testSecretARN)
+	require.NoError(t, err)
+	assert.Equal(t, `{"password":"hunter2"}`, payload)
+
+	payload, err = p.Resolve(context.Background(), strings.Replace(testSecretARN, "db", "cert", 1))
+	require.NoError(t, err)
+	assert.Equal(t, "binary", payload)
+
+	_, err = p.Resolve(context.Background(), strings.Replace(testSecretARN, "db", "gone", 1))
+	assert.EqualError(t, err, "secretsmanager: ResourceNotFoundException: Secrets Manager can't find the specified secret. (HTTP 400)")
+}
+
+func TestAWSSecretsManager_DefaultEndpoint(t *testing.T) {
+	p := NewAWSSecretsManager(WithAWSEnv(envLookup(nil)))
+	assert.Equal(t, "https://secretsmanager.us-east-1.amazonaws.com", p.aws.endpointFor(secretsManagerService, "aws", "us-east-1"))
+	assert.Equal(t, "https://secretsmanager.cn-north-1.amazonaws.com.cn", p.aws.endpointFor(secretsManagerService, "aws-cn", "cn-north-1"))
+}
+
+func TestDefaultAWSCredentials_Sources(t *testing.T) {
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	roleJSON := func(id string) string {
+		data, _ := json.Marshal(map[string]any{"AccessKeyId": id, "SecretAccessKey": "s", "Token": "t", "Expiration": expires})
+		return string(data)
+	}
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("jwt\n"), 0o600))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /sts/", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "AssumeRoleWithWebIdentity", r.Form.Get("Action"))
+		assert.Equal(t, "jwt", r.Form.Get("WebIdentityToken"))
+		assert.Equal(t, "arn:aws:iam::123456789012:role/app", r.Form.Get("RoleArn"))
+		io.WriteString(w, `<AssumeRoleWithWebIdentityResponse><AssumeRoleWithWebIdentityResult><Credentials>`+
+			`<AccessKeyId>WEB</AccessKeyId><SecretAccessKey>s</SecretAccessKey><SessionToken>t</SessionToken>`+
+			`<Expiration>`+expires.Format(time.RFC3339)+`</Expiration></Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`)
+	})
+	mux.HandleFunc("GET /ecs/creds", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "task-token", r.Header.Get("Authorization"))
+		io.WriteString(w, roleJSON("ECS"))
+	})
+	mux.HandleFunc("PUT /latest/api/token", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "imds-token")
+	})
+	mux.HandleFunc("GET /latest/meta-data/iam/security-credentials/{role...}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "imds-token", r.Header.Get("X-aws-ec2-metadata-token"))
+		if r.PathValue("role") == "" {
+			io.WriteString(w, "app-role\n")
+			return
+		}
+		assert.Equal(t, "app-role", r.PathValue("role"))
+		io.WriteString(w, roleJSON("EC2"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want AWSCredentials
+	}{
+		{"env", map[string]string{"AWS_ACCESS_KEY_ID": "ENV", "AWS_SECRET_ACCESS_KEY": "s", "AWS_SESSION_TOKEN": "t"},
+			AWSCredentials{AccessKeyID: "ENV", SecretAccessKey: "s", SessionToken: "t"}},
+		{"web identity", map[string]string{
+			"AWS_WEB_IDENTITY_TOKEN_FILE": tokenFile, "AWS_ROLE_ARN": "arn:aws:iam::123456789012:role/app", "AWS_ENDPOINT_URL_STS": server.URL + "/sts",
+		}, AWSCredentials{AccessKeyID: "WEB", SecretAccessKey: "s", SessionToken: "t", Expires: expires}},
+		{"container", map[string]string{
+			"AWS_CONTAINER_CREDENTIALS_FULL_URI": server.URL + "/ecs/creds", "AWS_CONTAINER_AUTHORIZATION_TOKEN": "task-token",
+		}, AWSCredentials{AccessKeyID: "ECS", SecretAccessKey: "s", SessionToken: "t", Expires: expires}},
+		{"instance metadata", map[string]string{"AWS_EC2_METADATA_SERVICE_ENDPOINT": server.URL},
+			AWSCredentials{AccessKeyID: "EC2", SecretAccessKey: "s", SessionToken: "t", Expires: expires}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			creds, err := DefaultAWSCredentials(envLookup(tc.env), nil).Retrieve(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.want.AccessKeyID, creds.AccessKeyID)
+			assert.Equal(t, tc.want.SessionToken, creds.SessionToken)
+			assert.True(t, tc.want.Expires.Equal(creds.Expires))
+		})
+	}
+
+	_, err := DefaultAWSCredentials(envLookup(map[string]string{"AWS_EC2_METADATA_DISABLED": "true"}), nil).Retrieve(context.Background())
+	assert.ErrorIs(t, err, errNoAWSCredentials)
+}
+
+func TestCheckContainerCredentialsURI(t *testing.T) {
+	for _, uri := range []string{
+		"https://creds.example.com/role",
+		"http://127.0.0.1:8080/creds",
+		"http://localhost/creds",
+		"http://[::1]/creds",
+		"http://169.254.170.2/v2/credentials",
+		"http://169.254.170.23/v1/credentials",
+		"http://[fd00:ec2::23]/v1/credentials",
+	} {
+		assert.NoError(t, checkContainerCredentialsURI(uri), uri)
+	}
+	for _, uri := range []string{
+		"http://creds.example.com/role",
+		"http://10.0.0.5/creds",
+		"http://169.254.169.254/latest",
+		"file:///etc/passwd",
+	} {
+		assert.Error(t, checkContainerCredentialsURI(uri), uri)
+	}
+
+	// The chain refuses before sending the authorization token anywhere.
+	chain := DefaultAWSCredentials(envLookup(map[string]string{
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI": "http://creds.example.com/role",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN":  "task-token",
+	}), nil)
+	_, err := chain.Retrieve(context.Background())
+	assert.ErrorContains(t, err, "must use https")
+}
+
+func TestDefaultAWSCredentials_CachesUntilExpiry(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(map[string]any{
+			"AccessKeyId": "ECS", "SecretAccessKey": "s", "Token": "t", "Expiration": time.Date(2026, 1, 1, 13, 0, 0, 0, time.UTC),
+		})
+	}))
+	defer server.Close()
+
+	chain := DefaultAWSCredentials(envLookup(map[string]string{"AWS_CONTAINER_CREDENTIALS_FULL_URI": server.URL}), nil).(*awsCredentialChain)
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	chain.now = func() time.Time { return clock }
+
+	for range 2 {
+		_, err := chain.Retrieve(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls)
+
+	clock = clock.Add(56 * time.Minute) // inside the refresh window
+	_, err := chain.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
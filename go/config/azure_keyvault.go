@@ -0,0 +1,264 @@
+package config
+
+// Azure Key Vault provider for smooai-secret:// references. It calls the
+// Key Vault REST API directly, so it needs no Azure SDK. Tokens come from
+// the default chain (see DefaultAzureTokenSource), which covers service
+// principals, AKS workload identity and managed identities.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const azureKeyVaultAPIVersion = "7.4"
+
+// azureVaultSuffixes are the Key Vault DNS suffixes of the Azure clouds.
+var azureVaultSuffixes = []string{"vault.azure.net", "vault.azure.cn", "vault.usgovcloudapi.net", "vault.microsoftazure.de"}
+
+// AzureOption configures AzureKeyVault.
+type AzureOption func(*AzureKeyVault)
+
+// WithAzureTokenSource sets the token source instead of the default chain.
+func WithAzureTokenSource(s CloudTokenSource) AzureOption {
+	return func(p *AzureKeyVault) { p.tokens = s }
+}
+
+// WithAzureEndpoint sends requests to url instead of the vault named in the
+// reference, e.g. a local emulator. Tokens are still requested for the
+// vault's cloud.
+func WithAzureEndpoint(url string) AzureOption {
+	return func(p *AzureKeyVault) { p.endpoint = strings.TrimRight(url, "/") }
+}
+
+// WithAzureEnv reads AZURE_* and IDENTITY_* variables through lookup
+// instead of the process environment.
+func WithAzureEnv(lookup EnvFunc) AzureOption {
+	return func(p *AzureKeyVault) { p.lookupEnv = lookup }
+}
+
+// WithAzureHTTPClient sets the HTTP client for Key Vault and token
+// requests.
+func WithAzureHTTPClient(c *http.Client) AzureOption {
+	return func(p *AzureKeyVault) { p.client = c }
+}
+
+// AzureKeyVault resolves references that are Key Vault secret URLs, with or
+// without a version (the current one by default):
+//
+//	smooai-secret://https://my-vault.vault.azure.net/secrets/db-password
+//	smooai-secret://https://my-vault.vault.azure.net/secrets/db-password/4f6c...
+type AzureKeyVault struct {
+	tokens    CloudTokenSource
+	client    *http.Client
+	lookupEnv EnvFunc
+	endpoint  string
+}
+
+// NewAzureKeyVault creates a Key Vault provider.
+func NewAzureKeyVault(opts ...AzureOption) *AzureKeyVault {
+	p := &AzureKeyVault{client: http.DefaultClient, lookupEnv: os.LookupEnv}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.tokens == nil {
+		p.tokens = DefaultAzureTokenSource(p.lookupEnv, p.client)
+	}
+	return p
+}
+
+// Handles reports whether ref is a Key Vault secret URL.
+func (p *AzureKeyVault) Handles(ref string) bool {
+	_, ok := parseAzureSecretURL(ref)
+	return ok
+}
+
+// Resolve fetches the secret's value.
+func (p *AzureKeyVault) Resolve(ctx context.Context, ref string) (string, error) {
+	secret, ok := parseAzureSecretURL(ref)
+	if !ok {
+		return "", fmt.Errorf("not a Key Vault secret URL: %s", ref)
+	}
+	token, err := p.tokens.AccessToken(ctx, secret.resource)
+	if err != nil {
+		return "", err
+	}
+	base := coalesceStr(p.endpoint, secret.vault)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+secret.path+"?api-version="+azureKeyVaultAPIVersion, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", cloudJSONError("keyvault", resp.StatusCode, data)
+	}
+	var out struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("keyvault: decoding response: %w", err)
+	}
+	return out.Value, nil
+}
+
+// azureSecretURL is the part of a Key Vault secret URL the provider needs.
+type azureSecretURL struct {
+	// vault is https://<name>.<suffix>.
+	vault string
+	// path is /secrets/<name>[/<version>].
+	path string
+	// resource is the token audience, https://<suffix>.
+	resource string
+}
+
+func parseAzureSecretURL(ref string) (azureSecretURL, bool) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "https" || u.RawQuery != "" || u.User != nil || u.Port() != "" {
+		return azureSecretURL{}, false
+	}
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if (len(parts) != 2 && len(parts) != 3) || parts[0] != "secrets" || parts[1] == "" || (len(parts) == 3 && parts[2] == "") {
+		return azureSecretURL{}, false
+	}
+	for _, suffix := range azureVaultSuffixes {
+		name, ok := strings.CutSuffix(u.Host, "."+suffix)
+		if ok && name != "" && !strings.Contains(name, ".") {
+			return azureSecretURL{vault: "https://" + u.Host, path: u.Path, resource: "https://" + suffix}, true
+		}
+	}
+	return azureSecretURL{}, false
+}
+
+var errNoAzureCredentials = errors.New("no Azure credentials found (checked AZURE_CLIENT_SECRET, workload identity, App Service managed identity and instance metadata)")
+
+// DefaultAzureTokenSource returns the standard Azure credential chain,
+// reading variables through lookupEnv (nil for the process environment):
+//
+//  1. A service principal secret: AZURE_TENANT_ID, AZURE_CLIENT_ID and
+//     AZURE_CLIENT_SECRET
+//  2. Workload identity (AKS): AZURE_FEDERATED_TOKEN_FILE, AZURE_TENANT_ID
+//     and AZURE_CLIENT_ID
+//  3. App Service and Container Apps managed identity: IDENTITY_ENDPOINT
+//     and IDENTITY_HEADER
+//  4. VM managed identity through instance metadata (IMDS)
+//
+// AZURE_CLIENT_ID picks a user-assigned managed identity. Tokens are cached
+// per resource until five minutes before they expire.
+func DefaultAzureTokenSource(lookupEnv EnvFunc, client *http.Client) CloudTokenSource {
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &azureTokenChain{lookupEnv: lookupEnv, client: client, cache: cloudTokenCache{now: time.Now}}
+}
+
+type azureTokenChain struct {
+	lookupEnv EnvFunc
+	client    *http.Client
+	cache     cloudTokenCache
+}
+
+func (c *azureTokenChain) AccessToken(ctx context.Context, resource string) (string, error) {
+	return c.cache.get(ctx, resource, func(ctx context.Context) (cloudToken, error) {
+		return c.fetch(ctx, resource)
+	})
+}
+
+func (c *azureTokenChain) env(name string) string {
+	v, _ := c.lookupEnv(name)
+	return v
+}
+
+func (c *azureTokenChain) fetch(ctx context.Context, resource string) (cloudToken, error) {
+	tenant, clientID := c.env("AZURE_TENANT_ID"), c.env("AZURE_CLIENT_ID")
+	switch {
+	case tenant != "" && clientID != "" && c.env("AZURE_CLIENT_SECRET") != "":
+		return c.entraToken(ctx, resource, url.Values{"client_secret": {c.env("AZURE_CLIENT_SECRET")}})
+	case tenant != "" && clientID != "" && c.env("AZURE_FEDERATED_TOKEN_FILE") != "":
+		assertion, err := os.ReadFile(c.env("AZURE_FEDERATED_TOKEN_FILE"))
+		if err != nil {
+			return cloudToken{}, fmt.Errorf("workload identity: %w", err)
+		}
+		return c.entraToken(ctx, resource, url.Values{
+			"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+			"client_assertion":      {strings.TrimSpace(string(assertion))},
+		})
+	case c.env("IDENTITY_ENDPOINT") != "" && c.env("IDENTITY_HEADER") != "":
+		return c.managedIdentity(ctx, c.env("IDENTITY_ENDPOINT"), "2019-08-01", resource, "X-IDENTITY-HEADER", c.env("IDENTITY_HEADER"))
+	default:
+		host := strings.TrimRight(coalesceStr(c.env("AZURE_POD_IDENTITY_AUTHORITY_HOST"), "http://169.254.169.254"), "/")
+		// Off Azure the metadata address usually hangs rather than
+		// refusing; don't let it eat the whole resolution timeout.
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		t, err := c.managedIdentity(ctx, host+"/metadata/identity/oauth2/token", "2018-02-01", resource, "Metadata", "true")
+		if err != nil {
+			return cloudToken{}, fmt.Errorf("%w: instance metadata: %v", errNoAzureCredentials, err)
+		}
+		return t, nil
+	}
+}
+
+// entraToken runs the client credentials flow against Microsoft Entra ID,
+// authenticating with the secret or assertion in auth.
+func (c *azureTokenChain) entraToken(ctx context.Context, resource string, auth url.Values) (cloudToken, error) {
+	authority := strings.TrimRight(coalesceStr(c.env("AZURE_AUTHORITY_HOST"), "https://login.microsoftonline.com"), "/")
+	form := url.Values{
+		"grant_type": {"client_credentials"},
+		"client_id":  {c.env("AZURE_CLIENT_ID")},
+		"scope":      {resource + "/.default"},
+	}
+	for k, v := range auth {
+		form[k] = v
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		authority+"/"+url.PathEscape(c.env("AZURE_TENANT_ID"))+"/oauth2/v2.0/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return cloudToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	now := time.Now()
+	data, err := doCloudRequest(c.client, req)
+	if err != nil {
+		return cloudToken{}, fmt.Errorf("entra id token: %w", err)
+	}
+	return decodeOAuthToken(data, now, "entra id token")
+}
+
+// managedIdentity requests a token from a managed identity endpoint, which
+// authenticates the caller by the header it sets.
+func (c *azureTokenChain) managedIdentity(ctx context.Context, endpoint, apiVersion, resource, header, value string) (cloudToken, error) {
+	query := url.Values{"api-version": {apiVersion}, "resource": {resource}}
+	if id := c.env("AZURE_CLIENT_ID"); id != "" {
+		query.Set("client_id", id)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return cloudToken{}, err
+	}
+	req.Header.Set(header, value)
+	now := time.Now()
+	data, err := doCloudRequest(c.client, req)
+	if err != nil {
+		return cloudToken{}, fmt.Errorf("managed identity: %w", err)
+	}
+	return decodeOAuthToken(data, now, "managed identity")
+}
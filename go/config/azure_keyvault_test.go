@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resourceTokens hands out a token naming the resource it was asked for.
+type resourceTokens struct{}
+
+func (resourceTokens) AccessToken(_ context.Context, resource string) (string, error) {
+	return "token-for-" + resource, nil
+}
+
+func TestParseAzureSecretURL(t *testing.T) {
+	secret, ok := parseAzureSecretURL("https://my-vault.vault.azure.net/secrets/db")
+	require.True(t, ok)
+	assert.Equal(t, azureSecretURL{vault: "https://my-vault.vault.azure.net", path: "/secrets/db", resource: "https://vault.azure.net"}, secret)
+	secret, ok = parseAzureSecretURL("https://cn-vault.vault.azure.cn/secrets/db/0123abcd")
+	require.True(t, ok)
+	assert.Equal(t, "https://vault.azure.cn", secret.resource)
+
+	for _, ref := range []string{
+		"http://my-vault.vault.azure.net/secrets/db",
+		"https://my-vault.vault.azure.net/keys/db",
+		"https://my-vault.vault.azure.net/secrets/",
+		"https://vault.azure.net/secrets/db",
+		"https://my-vault.example.com/secrets/db",
+		"https://my-vault.vault.azure.net/secrets/db/v1/extra",
+		"projects/p/secrets/db",
+	} {
+		_, ok := parseAzureSecretURL(ref)
+		assert.False(t, ok, ref)
+	}
+}
+
+func TestAzureKeyVault_Resolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-for-https://vault.azure.net", r.Header.Get("Authorization"))
+		assert.Equal(t, azureKeyVaultAPIVersion, r.URL.Query().Get("api-version"))
+		switch r.URL.Path {
+		case "/secrets/db":
+			io.WriteString(w, `{"value":"{\"password\":\"hunter2\"}","id":"https://my-vault.vault.azure.net/secrets/db/1"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":{"code":"SecretNotFound","message":"A secret with (name/id) gone was not found in this key vault."}}`)
+		}
+	}))
+	defer server.Close()
+
+	p := NewAzureKeyVault(WithAzureEndpoint(server.URL), WithAzureTokenSource(resourceTokens{}))
+	assert.True(t, p.Handles("https://my-vault.vault.azure.net/secrets/db"))
+	assert.False(t, p.Handles(testSecretARN))
+
+	payload, err := p.Resolve(context.Background(), "https://my-vault.vault.azure.net/secrets/db")
+	require.NoError(t, err)
+	assert.Equal(t, `{"password":"hunter2"}`, payload)
+
+	_, err = p.Resolve(context.Background(), "https://my-vault.vault.azure.net/secrets/gone")
+	assert.EqualError(t, err, "keyvault: SecretNotFound: A secret with (name/id) gone was not found in this key vault. (HTTP 404)")
+}
+
+func TestDefaultAzureTokenSource_Sources(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("federated\n"), 0o600))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		assert.Equal(t, "https://vault.azure.net/.default", r.Form.Get("scope"))
+		if r.Form.Get("client_secret") == "s" {
+			io.WriteString(w, `{"access_token":"SP","expires_in":3599}`)
+			return
+		}
+		assert.Equal(t, "federated", r.Form.Get("client_assertion"))
+		io.WriteString(w, `{"access_token":"WI","expires_in":3599}`)
+	})
+	mux.HandleFunc("GET /msi/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret-header", r.Header.Get("X-IDENTITY-HEADER"))
+		assert.Equal(t, "https://vault.azure.net", r.URL.Query().Get("resource"))
+		io.WriteString(w, `{"access_token":"APP","expires_on":"4102444800"}`)
+	})
+	mux.HandleFunc("GET /metadata/identity/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.Header.Get("Metadata"))
+		assert.Equal(t, "user-assigned", r.URL.Query().Get("client_id"))
+		io.WriteString(w, `{"access_token":"VM","expires_in":"3599"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"client secret", map[string]string{
+			"AZURE_TENANT_ID": "tenant", "AZURE_CLIENT_ID": "id", "AZURE_CLIENT_SECRET": "s", "AZURE_AUTHORITY_HOST": server.URL,
+		}, "SP"},
+		{"workload identity", map[string]string{
+			"AZURE_TENANT_ID": "tenant", "AZURE_CLIENT_ID": "id", "AZURE_FEDERATED_TOKEN_FILE": tokenFile, "AZURE_AUTHORITY_HOST": server.URL,
+		}, "WI"},
+		{"app service", map[string]string{"IDENTITY_ENDPOINT": server.URL + "/msi/token", "IDENTITY_HEADER": "secret-header"}, "APP"},
+		{"instance metadata", map[string]string{"AZURE_POD_IDENTITY_AUTHORITY_HOST": server.URL, "AZURE_CLIENT_ID": "user-assigned"}, "VM"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := DefaultAzureTokenSource(envLookup(tc.env), nil).AccessToken(context.Background(), "https://vault.azure.net")
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, token)
+		})
+	}
+}
+
+func TestBuiltinRemoteProvider_FollowsCloudProvider(t *testing.T) {
+	for cloud, env := range map[string]map[string]string{
+		"aws":   {"AWS_REGION": "us-east-1"},
+		"gcp":   {"GOOGLE_CLOUD_REGION": "us-central1"},
+		"azure": {"SMOOAI_CONFIG_CLOUD_PROVIDER": "azure"},
+		"none":  {},
+	} {
+		mgr := NewConfigManager(WithCMEnvOverride(env))
+		builtin := mgr.secrets.providers[len(mgr.secrets.providers)-1]
+		switch cloud {
+		case "gcp":
+			assert.IsType(t, &GCPSecretManager{}, builtin, cloud)
+		case "azure":
+			assert.IsType(t, &AzureKeyVault{}, builtin, cloud)
+		default:
+			assert.IsType(t, &AWSSecretsManager{}, builtin, cloud)
+		}
+	}
+}
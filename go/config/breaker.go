@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultBreakerCooldown is how long an open circuit fails fast when
+// WithCircuitBreaker is given no cooldown.
+const defaultBreakerCooldown = 30 * time.Second
+
+// ErrCircuitOpen matches the *CircuitOpenError a ConfigClient returns
+// instead of calling the API while its circuit breaker is open.
+var ErrCircuitOpen = errors.New("config circuit open")
+
+// CircuitOpenError is returned without a request being sent while the
+// circuit breaker (WithCircuitBreaker) is open.
+type CircuitOpenError struct {
+	// RetryIn is how long until the circuit lets a trial request through.
+	RetryIn time.Duration
+	// Cause is the failure that opened the circuit.
+	Cause error
+}
+
+// Error implements the error interface.
+func (e *CircuitOpenError) Error() string {
+	msg := fmt.Sprintf("config API circuit open, retrying in %s", e.RetryIn.Round(time.Second))
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// Is matches ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool { return target == ErrCircuitOpen }
+
+// Unwrap returns the failure that opened the circuit.
+func (e *CircuitOpenError) Unwrap() error { return e.Cause }
+
+// WithCircuitBreaker stops the client calling the API after threshold
+// consecutive failures (transport errors and 5xx responses): for cooldown,
+// every call fails fast with a *CircuitOpenError instead of waiting out a
+// connect timeout. Then a single trial request goes through; it closes the
+// circuit if it succeeds and opens it for another cooldown if it fails.
+// Requests the caller cancels don't count. A threshold below 1 turns the
+// breaker off; a cooldown of zero or less uses 30s.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ConfigClientOption {
+	return func(c *ConfigClient) { c.breaker = newBreaker(threshold, cooldown) }
+}
+
+// WithCMCircuitBreaker puts a circuit breaker (see WithCircuitBreaker)
+// around the manager's config API calls. The breaker is kept across
+// refreshes, so once the API is down each refresh fails fast until the
+// cooldown ends, and the manager serves its last good values as it does for
+// any failed fetch (see WithMaxStaleness).
+func WithCMCircuitBreaker(threshold int, cooldown time.Duration) ConfigManagerOption {
+	return func(m *ConfigManager) { m.breaker = newBreaker(threshold, cooldown) }
+}
+
+// withBreaker shares b with a client, so the manager's breaker outlives the
+// client it creates for each load.
+func withBreaker(b *breaker) ConfigClientOption {
+	return func(c *ConfigClient) { c.breaker = b }
+}
+
+// breaker is a consecutive-failure circuit breaker. A nil *breaker lets
+// every request through.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	// openUntil is when the open circuit lets a trial request through.
+	openUntil deadline
+	// trial is set while the trial request is in flight.
+	trial   bool
+	lastErr error
+}
+
+// newBreaker returns nil for a threshold below 1.
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	if threshold < 1 {
+		return nil
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow returns a *CircuitOpenError if a request must not be sent. Once the
+// cooldown has ended, it lets one trial request through at a time.
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if !b.openUntil.passed() || b.trial {
+		return &CircuitOpenError{RetryIn: max(time.Duration(b.openUntil)-monoNow(), 0), Cause: b.lastErr}
+	}
+	b.trial = true
+	return nil
+}
+
+// release ends a trial that sent no request.
+func (b *breaker) release() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.trial = false
+	b.mu.Unlock()
+}
+
+// record counts the outcome of sending req. It returns the failure that
+// opened the circuit when this one did, and nil otherwise.
+func (b *breaker) record(req *http.Request, resp *http.Response, err error) (opened error) {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		// The caller gave up; that says nothing about the API.
+		return nil
+	case err != nil:
+		b.lastErr = err
+	case resp.StatusCode >= 500:
+		b.lastErr = &HTTPError{Op: req.Method + " " + req.URL.Path, StatusCode: resp.StatusCode}
+	default:
+		b.failures, b.lastErr = 0, nil
+		return nil
+	}
+	b.failures++
+	if b.failures < b.threshold {
+		return nil
+	}
+	b.openUntil = expireAfter(b.cooldown)
+	return b.lastErr
+}
+
+// send sends req with client, counting the outcome against the breaker.
+func (c *ConfigClient) send(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if cause := c.breaker.record(req, resp, err); cause != nil {
+		c.log().Warn("config API circuit opened", "cooldown", c.breaker.cooldown, "error", cause)
+	}
+	if err == nil {
+		if err = c.decodeResponse(resp); err != nil {
+			return nil, err
+		}
+	}
+	return resp, err
+}
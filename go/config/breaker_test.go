@@ -0,0 +1,147 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRemote serves values, or 503 while failing is set, and counts the
+// value requests it receives.
+func countingRemote(t *testing.T, values map[string]any) (*httptest.Server, *atomic.Bool, *atomic.Int32) {
+	t.Helper()
+	var failing atomic.Bool
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"values": values})
+	}))
+	t.Cleanup(server.Close)
+	return server, &failing, &calls
+}
+
+func TestConfigClient_CircuitBreaker(t *testing.T) {
+	advance := fakeMonoClock(t)
+	server, failing, calls := countingRemote(t, map[string]any{"K": "v"})
+	failing.Store(true)
+	client := newUnitClient(t, server.URL, WithCircuitBreaker(2, time.Minute))
+
+	for range 2 {
+		_, err := client.GetAllValues("production")
+		require.ErrorIs(t, err, ErrServerError)
+	}
+	assert.EqualValues(t, 2, calls.Load())
+
+	_, err := client.GetAllValues("production")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.ErrorIs(t, err, ErrServerError, "the open error wraps the failure that opened it")
+	var open *CircuitOpenError
+	require.ErrorAs(t, err, &open)
+	assert.Equal(t, time.Minute, open.RetryIn)
+	assert.EqualValues(t, 2, calls.Load(), "an open circuit sends no request")
+
+	t.Run("a failed trial reopens the circuit", func(t *testing.T) {
+		advance(time.Minute)
+		_, err := client.GetAllValues("production")
+		require.ErrorIs(t, err, ErrServerError)
+		assert.EqualValues(t, 3, calls.Load())
+
+		_, err = client.GetAllValues("production")
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		assert.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("a successful trial closes the circuit", func(t *testing.T) {
+		failing.Store(false)
+		advance(time.Minute)
+		values, err := client.GetAllValues("production")
+		require.NoError(t, err)
+		assert.Equal(t, "v", values["K"])
+
+		client.InvalidateCache()
+		_, err = client.GetAllValues("production")
+		require.NoError(t, err)
+		assert.EqualValues(t, 5, calls.Load())
+	})
+}
+
+func TestBreaker(t *testing.T) {
+	advance := fakeMonoClock(t)
+	req := httptest.NewRequest(http.MethodGet, "/values", nil)
+	down := errors.New("connection refused")
+
+	t.Run("a success resets the count", func(t *testing.T) {
+		b := newBreaker(2, time.Second)
+		b.record(req, nil, down)
+		b.record(req, &http.Response{StatusCode: http.StatusOK}, nil)
+		b.record(req, nil, down)
+		assert.NoError(t, b.allow())
+	})
+
+	t.Run("4xx responses don't count", func(t *testing.T) {
+		b := newBreaker(1, time.Second)
+		b.record(req, &http.Response{StatusCode: http.StatusNotFound}, nil)
+		assert.NoError(t, b.allow())
+	})
+
+	t.Run("requests the caller canceled don't count", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		b := newBreaker(1, time.Second)
+		b.record(req.WithContext(ctx), nil, context.Canceled)
+		assert.NoError(t, b.allow())
+	})
+
+	t.Run("one trial at a time", func(t *testing.T) {
+		b := newBreaker(1, time.Second)
+		assert.ErrorIs(t, b.record(req, nil, down), down, "reports the failure that opened it")
+		advance(time.Second)
+		require.NoError(t, b.allow())
+		assert.ErrorIs(t, b.allow(), ErrCircuitOpen, "a second caller waits for the trial")
+		b.release()
+		assert.NoError(t, b.allow())
+	})
+
+	t.Run("threshold below 1 turns it off", func(t *testing.T) {
+		b := newBreaker(0, time.Second)
+		assert.Nil(t, b)
+		b.record(req, nil, down)
+		assert.NoError(t, b.allow())
+	})
+}
+
+func TestConfigManager_CircuitBreakerAcrossRefreshes(t *testing.T) {
+	fakeMonoClock(t)
+	server, failing, calls := countingRemote(t, map[string]any{"API_URL": "https://remote"})
+	mgr := newStalenessManager(t, server.URL, WithMaxStaleness(time.Hour), WithCMCircuitBreaker(1, time.Minute))
+
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	failing.Store(true)
+	before := calls.Load()
+
+	for range 3 {
+		mgr.Invalidate()
+		v, err := mgr.GetPublicConfig("API_URL")
+		require.NoError(t, err)
+		assert.Equal(t, "https://remote", v, "last good value is served")
+	}
+	assert.Equal(t, before+1, calls.Load(), "refreshes after the first failure fail fast")
+	assert.ErrorIs(t, mgr.Health().LastError, ErrCircuitOpen)
+}
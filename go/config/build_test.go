@@ -90,7 +90,7 @@ func TestBuildBundle_DefaultClassifyAllPublic(t *testing.T) {
 		BaseURL:     srv.URL,
 		AuthURL:     srv.URL,
 		APIKey:      "test-key",
-		OrgID:       "org-1",
+		OrgID:       testOrgID,
 		Environment: "production",
 	})
 	require.NoError(t, err)
@@ -126,7 +126,7 @@ func TestBuildBundle_Classifier_PartitionsAndSkips(t *testing.T) {
 		BaseURL:  srv.URL,
 		AuthURL:  srv.URL,
 		APIKey:   "test-key",
-		OrgID:    "org-1",
+		OrgID:    testOrgID,
 		Classify: classify,
 	})
 	require.NoError(t, err)
@@ -151,7 +151,7 @@ func TestBuildBundle_BlobLayoutMatchesTsPython(t *testing.T) {
 		BaseURL: srv.URL,
 		AuthURL: srv.URL,
 		APIKey:  "test-key",
-		OrgID:   "org-1",
+		OrgID:   testOrgID,
 	})
 	require.NoError(t, err)
 
@@ -172,7 +172,7 @@ func TestBuildBundle_RemoteFetchError(t *testing.T) {
 		BaseURL: srv.URL,
 		AuthURL: srv.URL,
 		APIKey:  "test-key",
-		OrgID:   "org-1",
+		OrgID:   testOrgID,
 	})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "config build bundle fetch")
@@ -189,7 +189,7 @@ func TestBuildBundle_CancelledContext(t *testing.T) {
 		BaseURL: srv.URL,
 		AuthURL: srv.URL,
 		APIKey:  "test-key",
-		OrgID:   "org-1",
+		OrgID:   testOrgID,
 	})
 	require.Error(t, err)
 	assert.ErrorIs(t, err, context.Canceled)
@@ -0,0 +1,162 @@
+package config
+
+// Built-in keys are injected into every config from the process environment,
+// alongside whatever the files, remote API and env vars provide:
+//
+//	ENV             SMOOAI_CONFIG_ENV, default "development"
+//	IS_LOCAL        IS_LOCAL, coerced to bool
+//	REGION          see GetCloudRegionFromEnv
+//	CLOUD_PROVIDER  see GetCloudRegionFromEnv
+//	RING            see GetDeploymentRingFromEnv
+//	RUNTIME         see GetRuntimeFromEnv
+//	AZ              see GetAvailabilityZoneFromEnv
+//
+// By default they overwrite user keys with the same names. A BuiltinPolicy
+// relaxes that per key.
+
+// Built-in key names.
+const (
+	BuiltinEnv           = "ENV"
+	BuiltinIsLocal       = "IS_LOCAL"
+	BuiltinRegion        = "REGION"
+	BuiltinCloudProvider = "CLOUD_PROVIDER"
+	BuiltinRing          = "RING"
+	BuiltinRuntime       = "RUNTIME"
+	BuiltinAZ            = "AZ"
+)
+
+// BuiltinKeys lists every built-in key, in injection order.
+var BuiltinKeys = []string{
+	BuiltinEnv, BuiltinIsLocal, BuiltinRegion, BuiltinCloudProvider,
+	BuiltinRing, BuiltinRuntime, BuiltinAZ,
+}
+
+// BuiltinMode controls how one built-in key is injected.
+type BuiltinMode string
+
+const (
+	// BuiltinOverwrite (default) sets the key above every source, replacing
+	// a user value with the same name.
+	BuiltinOverwrite BuiltinMode = "overwrite"
+	// BuiltinFallback sets the key below every source: files, remote values
+	// and env vars with the same name win.
+	BuiltinFallback BuiltinMode = "fallback"
+	// BuiltinSuppress never injects the key; only user sources can set it.
+	BuiltinSuppress BuiltinMode = "suppress"
+)
+
+// BuiltinPolicy chooses a BuiltinMode per built-in key. The zero value
+// overwrites every built-in, which is the historical behavior.
+type BuiltinPolicy struct {
+	// Default applies to keys without an entry in Keys. Empty means
+	// BuiltinOverwrite.
+	Default BuiltinMode
+	// Keys sets the mode for individual built-ins, e.g. {"REGION": BuiltinFallback}.
+	Keys map[string]BuiltinMode
+}
+
+// With returns a copy of p with key set to mode, so policies compose:
+//
+//	BuiltinPolicy{Default: BuiltinFallback}.With(BuiltinEnv, BuiltinOverwrite)
+func (p BuiltinPolicy) With(key string, mode BuiltinMode) BuiltinPolicy {
+	keys := make(map[string]BuiltinMode, len(p.Keys)+1)
+	for k, v := range p.Keys {
+		keys[k] = v
+	}
+	keys[key] = mode
+	return BuiltinPolicy{Default: p.Default, Keys: keys}
+}
+
+// Mode returns the mode for key.
+func (p BuiltinPolicy) Mode(key string) BuiltinMode {
+	if mode, ok := p.Keys[key]; ok && mode != "" {
+		return mode
+	}
+	if p.Default != "" {
+		return p.Default
+	}
+	return BuiltinOverwrite
+}
+
+// withoutFallback returns p with every fallback mode turned into suppress,
+// for callers that place fallback built-ins themselves.
+func (p BuiltinPolicy) withoutFallback() BuiltinPolicy {
+	out := BuiltinPolicy{Default: p.Default, Keys: make(map[string]BuiltinMode, len(BuiltinKeys))}
+	for _, key := range BuiltinKeys {
+		mode := p.Mode(key)
+		if mode == BuiltinFallback {
+			mode = BuiltinSuppress
+		}
+		out.Keys[key] = mode
+	}
+	return out
+}
+
+// GetRuntimeFromEnv detects the compute runtime from a provided env map.
+//
+// Detection order:
+//  1. SMOOAI_CONFIG_RUNTIME (custom override)
+//  2. AWS_LAMBDA_FUNCTION_NAME → "lambda"
+//  3. ECS_CONTAINER_METADATA_URI(_V4) → "ecs"
+//  4. K_SERVICE → "cloudrun"
+//  5. FUNCTIONS_WORKER_RUNTIME → "azure-functions"
+//  6. KUBERNETES_SERVICE_HOST → "kubernetes"
+//  7. Default: "unknown"
+func GetRuntimeFromEnv(env map[string]string) string {
+	switch {
+	case env["SMOOAI_CONFIG_RUNTIME"] != "":
+		return env["SMOOAI_CONFIG_RUNTIME"]
+	case env["AWS_LAMBDA_FUNCTION_NAME"] != "":
+		return "lambda"
+	case env["ECS_CONTAINER_METADATA_URI_V4"] != "" || env["ECS_CONTAINER_METADATA_URI"] != "":
+		return "ecs"
+	case env["K_SERVICE"] != "":
+		return "cloudrun"
+	case env["FUNCTIONS_WORKER_RUNTIME"] != "":
+		return "azure-functions"
+	case env["KUBERNETES_SERVICE_HOST"] != "":
+		return "kubernetes"
+	}
+	return "unknown"
+}
+
+// GetAvailabilityZoneFromEnv reads the availability zone from a provided env
+// map: SMOOAI_CONFIG_AZ, then AVAILABILITY_ZONE, defaulting to "unknown".
+// No provider exposes the zone as a standard env var, so deployments that
+// care set one of these.
+func GetAvailabilityZoneFromEnv(env map[string]string) string {
+	return coalesceStr(env["SMOOAI_CONFIG_AZ"], env["AVAILABILITY_ZONE"], "unknown")
+}
+
+// builtinValuesFromEnv computes every built-in key from env.
+func builtinValuesFromEnv(env map[string]string) map[string]any {
+	envName := GetConfigEnvironmentFromEnv(env)
+	cloudRegion := GetCloudRegionFromEnv(env)
+	return map[string]any{
+		BuiltinEnv:           envName,
+		BuiltinIsLocal:       CoerceBoolean(env["IS_LOCAL"]),
+		BuiltinRegion:        cloudRegion.Region,
+		BuiltinCloudProvider: cloudRegion.Provider,
+		BuiltinRing:          GetDeploymentRingFromEnv(env),
+		BuiltinRuntime:       GetRuntimeFromEnv(env),
+		BuiltinAZ:            GetAvailabilityZoneFromEnv(env),
+	}
+}
+
+// applyBuiltins injects builtins into layer according to policy. The file
+// layer is the lowest source, so fallback built-ins go there (when the files
+// don't set the key); overwrite built-ins go into every layer that carries
+// them so no source above can replace them.
+func applyBuiltins(layer, builtins map[string]any, policy BuiltinPolicy, lowest bool) {
+	for _, key := range BuiltinKeys {
+		switch policy.Mode(key) {
+		case BuiltinSuppress:
+		case BuiltinFallback:
+			if _, ok := layer[key]; lowest && !ok {
+				layer[key] = builtins[key]
+			}
+		default:
+			layer[key] = builtins[key]
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRuntimeFromEnv(t *testing.T) {
+	cases := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{}, "unknown"},
+		{map[string]string{"AWS_LAMBDA_FUNCTION_NAME": "fn"}, "lambda"},
+		{map[string]string{"ECS_CONTAINER_METADATA_URI_V4": "http://169.254.170.2/v4"}, "ecs"},
+		{map[string]string{"K_SERVICE": "svc"}, "cloudrun"},
+		{map[string]string{"FUNCTIONS_WORKER_RUNTIME": "node"}, "azure-functions"},
+		{map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1"}, "kubernetes"},
+		{map[string]string{"SMOOAI_CONFIG_RUNTIME": "fly", "KUBERNETES_SERVICE_HOST": "10.0.0.1"}, "fly"},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, GetRuntimeFromEnv(tc.env), "%v", tc.env)
+	}
+}
+
+func TestGetAvailabilityZoneFromEnv(t *testing.T) {
+	assert.Equal(t, "unknown", GetAvailabilityZoneFromEnv(map[string]string{}))
+	assert.Equal(t, "us-east-1a", GetAvailabilityZoneFromEnv(map[string]string{"AVAILABILITY_ZONE": "us-east-1a"}))
+	assert.Equal(t, "use1-az2", GetAvailabilityZoneFromEnv(map[string]string{
+		"SMOOAI_CONFIG_AZ":  "use1-az2",
+		"AVAILABILITY_ZONE": "us-east-1a",
+	}))
+}
+
+func TestBuiltinPolicy_Mode(t *testing.T) {
+	var zero BuiltinPolicy
+	assert.Equal(t, BuiltinOverwrite, zero.Mode(BuiltinRegion))
+
+	p := BuiltinPolicy{Default: BuiltinFallback}.With(BuiltinEnv, BuiltinOverwrite)
+	assert.Equal(t, BuiltinOverwrite, p.Mode(BuiltinEnv))
+	assert.Equal(t, BuiltinFallback, p.Mode(BuiltinRegion))
+
+	q := p.With(BuiltinEnv, BuiltinSuppress)
+	assert.Equal(t, BuiltinSuppress, q.Mode(BuiltinEnv))
+	assert.Equal(t, BuiltinOverwrite, p.Mode(BuiltinEnv), "With must not mutate the receiver")
+}
+
+func TestFindAndProcessFileConfig_BuiltinPolicy(t *testing.T) {
+	env := map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{
+			"default.json": map[string]any{"REGION": "eu-custom", "RUNTIME": "mine"},
+		}),
+		"AWS_REGION":               "us-east-1",
+		"AWS_LAMBDA_FUNCTION_NAME": "fn",
+	}
+
+	result, err := findAndProcessFileConfigWithPolicy(env, NullSet, BuiltinPolicy{}, configSource{})
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", result["REGION"])
+	assert.Equal(t, "lambda", result["RUNTIME"])
+	assert.Equal(t, "unknown", result["AZ"])
+
+	result, err = findAndProcessFileConfigWithPolicy(env, NullSet, BuiltinPolicy{Default: BuiltinFallback}, configSource{})
+	require.NoError(t, err)
+	assert.Equal(t, "eu-custom", result["REGION"])
+	assert.Equal(t, "mine", result["RUNTIME"])
+	assert.Equal(t, "aws", result["CLOUD_PROVIDER"], "fallback fills keys the files leave unset")
+
+	result, err = findAndProcessFileConfigWithPolicy(env, NullSet, BuiltinPolicy{Default: BuiltinSuppress}, configSource{})
+	require.NoError(t, err)
+	assert.Equal(t, "eu-custom", result["REGION"])
+	assert.NotContains(t, result, "ENV")
+	assert.NotContains(t, result, "CLOUD_PROVIDER")
+}
+
+func TestConfigManager_BuiltinsFallbackLetsRemoteWin(t *testing.T) {
+	mock := newMockCMServer("test-key", "test-org", map[string]any{"REGION": "remote-region"})
+	defer mock.close()
+
+	newMgr := func(policy BuiltinPolicy) *ConfigManager {
+		return NewConfigManager(
+			WithAPIKey("test-key"),
+			WithBaseURL(mock.server.URL),
+			WithOrgID(testOrgID),
+			WithConfigEnvironment("production"),
+			WithCMBuiltins(policy),
+			WithCMEnvOverride(mock.envOverride(map[string]string{
+				"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+				"AWS_REGION":            "us-east-1",
+			})),
+		)
+	}
+
+	v, err := newMgr(BuiltinPolicy{}).GetPublicConfig("REGION")
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", v, "built-ins overwrite by default")
+
+	mgr := newMgr(BuiltinPolicy{}.With(BuiltinRegion, BuiltinFallback))
+	v, err = mgr.GetPublicConfig("REGION")
+	require.NoError(t, err)
+	assert.Equal(t, "remote-region", v)
+	v, err = mgr.GetPublicConfig("CLOUD_PROVIDER")
+	require.NoError(t, err)
+	assert.Equal(t, "aws", v)
+}
+
+func TestConfigManager_BuiltinsFallbackWithoutConfigDir(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMBuiltins(BuiltinPolicy{Default: BuiltinFallback}),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": "/nonexistent",
+			"SMOOAI_CONFIG_ENV":     "staging",
+		}),
+	)
+	v, err := mgr.GetPublicConfig("ENV")
+	require.NoError(t, err)
+	assert.Equal(t, "staging", v)
+}
+
+func TestLocalConfigManager_BuiltinsFallbackLetsEnvVarWin(t *testing.T) {
+	env := map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+		"SMOOAI_CONFIG_RUNTIME": "lambda",
+		"AZ":                    "from-env",
+	}
+	mgr := NewLocalConfigManager(
+		WithSchemaKeys(map[string]bool{"AZ": true}),
+		WithBuiltins(BuiltinPolicy{Default: BuiltinFallback}),
+		WithEnvOverride(env),
+	)
+
+	v, err := mgr.GetPublicConfig("AZ")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", v)
+	v, err = mgr.GetPublicConfig("RUNTIME")
+	require.NoError(t, err)
+	assert.Equal(t, "lambda", v)
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+// changeListener is called, on its own goroutine, with the keys whose
+// effective value changed. Values holds the new value of every changed key
+// that is still set; removed keys are listed in Keys only.
+type changeListener func(evt ConfigChangeEvent)
+
+// changedKeys returns the sorted keys whose values differ between before and
+// after. keys limits the comparison; empty compares every key in either map.
+func changedKeys(before, after map[string]any, keys ...string) []string {
+	if len(keys) == 0 {
+		seen := make(map[string]bool, len(after))
+		for key := range before {
+			seen[key] = true
+		}
+		for key := range after {
+			seen[key] = true
+		}
+		for key := range seen {
+			keys = append(keys, key)
+		}
+	}
+	var changed []string
+	for _, key := range keys {
+		b, inBefore := before[key]
+		a, inAfter := after[key]
+		if inBefore != inAfter || !reflect.DeepEqual(a, b) {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// addChangeListener registers fn after construction, for helpers such as
+// OpenSQL that attach to an existing manager.
+func (m *ConfigManager) addChangeListener(fn changeListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changeListeners = append(m.changeListeners, fn)
+}
+
+// notifyChangeLocked records pending restarts for keys and hands them (with
+// their values from config) to every change listener. Must be called under
+// m.mu; listeners run after it is released, so they may call back into the
+// manager.
+func (m *ConfigManager) notifyChangeLocked(keys []string, config map[string]any) {
+	if len(keys) == 0 {
+		return
+	}
+	m.markRestartLocked(keys)
+	if len(m.changeListeners) == 0 && len(m.watchers) == 0 {
+		return
+	}
+	evt := ConfigChangeEvent{Environment: m.configEnvironment(), Keys: keys, Values: make(map[string]any, len(keys))}
+	for _, key := range keys {
+		if v, ok := config[key]; ok {
+			evt.Values[key] = v
+		}
+	}
+	for _, listener := range m.changeListeners {
+		m.components.goCallback(func() { listener(evt) })
+	}
+	for _, w := range m.watchers {
+		w.notify(evt, m.components.goCallback)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"math"
 	"net/http"
 	"net/url"
@@ -36,38 +38,139 @@ import (
 //	                               deprecated alias)
 //	SMOOAI_CONFIG_ORG_ID         — Organization ID
 //	SMOOAI_CONFIG_ENV            — Default environment name
+//	SMOOAI_CONFIG_RING           — Deployment ring (RING / DEPLOYMENT_STAGE
+//	                               also accepted)
+//	SMOOAI_CONFIG_WATCH_TRANSPORT — Subscribe transport: sse, longpoll or
+//	                               websocket (default: SSE with long-poll
+//	                               fallback)
+//	SMOOAI_CONFIG_AGENT_ADDR     — Local agent socket path or host:port
+//	                               ("off" disables agent discovery)
 type ConfigClient struct {
 	baseURL            string
 	orgID              string
 	defaultEnvironment string
 	cacheTTL           time.Duration
 	client             *http.Client
-	tokenProvider      *TokenProvider
+	// streamClient is client without the request timeout, for Subscribe's
+	// long-lived event stream.
+	streamClient  *http.Client
+	tokenProvider *TokenProvider
+	// transport and requestTimeout (WithTransport, WithRequestTimeout) are
+	// applied to a copy of client during NewConfigClient.
+	transport      http.RoundTripper
+	requestTimeout time.Duration
+	// ring is the deployment ring sent as ?ring= on value fetches so the
+	// server can return ring-specific values. Empty omits the param.
+	ring string
 	// authURLOverride is set via WithAuthURL and consumed during
 	// NewConfigClient to build the TokenProvider.
 	authURLOverride string
-	cache           map[string]cacheEntry
+	cache           map[CacheKey]cacheEntry
 	mu              sync.RWMutex
+	// flights coalesces concurrent cache-miss fetches for the same
+	// (environment, key) — and concurrent GetAllValues for the same
+	// environment — into one HTTP request.
+	flights flightGroup
+	// snapshots holds the last GetAllValues response per environment along
+	// with its ETag, so refreshes can send If-None-Match and reuse the
+	// values on 304 Not Modified. Guarded by mu.
+	snapshots map[string]valuesSnapshot
+	// maxAges holds server cache hints per cache key. A hinted key
+	// expires after its maxAge instead of cacheTTL. Guarded by mu.
+	maxAges map[CacheKey]time.Duration
+	// fingerprintInstanceID enables report mode (WithFingerprintReporting).
+	fingerprintInstanceID string
+	// definition (WithClientDefinition) validates SetValues writes before
+	// they are sent.
+	definition *ConfigDefinition
+	// watchTransport (WithWatchTransport) is how Subscribe receives
+	// changes.
+	watchTransport WatchTransport
+	// agentAddr (WithAgentAddr) is consumed during NewConfigClient to route
+	// API requests through a local agent.
+	agentAddr string
+	// logger (WithLogger) receives request debug logs; nil means
+	// slog.Default.
+	logger *slog.Logger
+	// breaker (WithCircuitBreaker) fails requests fast while the API is
+	// down; nil sends every request.
+	breaker *breaker
+	// limiter (WithRateLimit, WithRateLimiter) caps the requests sent; nil
+	// doesn't limit.
+	limiter *RateLimiter
+	// keyPrefixes and namespace (WithClientKeyPrefix, WithClientNamespace)
+	// scope value fetches to a subset of the org's keys.
+	keyPrefixes []string
+	namespace   string
+	// sharedHTTP is set when client came from WithHTTPClient, whose
+	// connections Close must not drop.
+	sharedHTTP bool
+	// decoders (WithResponseDecoder) decode compressed responses by
+	// Content-Encoding; nil leaves compression to the transport.
+	decoders map[string]ResponseDecoder
+}
+
+// valuesSnapshot is the last full values map fetched for an environment.
+type valuesSnapshot struct {
+	etag   string
+	values map[string]any
+	tiers  map[string]ConfigTier
+	// maxAges are the per-key cache hints sent with the values.
+	maxAges map[string]time.Duration
+	// archived are the keys the server marked archived, with when.
+	archived map[string]time.Time
 }
 
 type cacheEntry struct {
 	value     any
-	expiresAt time.Time // zero means no expiry
+	expiresAt deadline // zero means no expiry
 }
 
 type valueResponse struct {
 	Value any `json:"value"`
+	// Namespace optionally names the namespace the key belongs to.
+	Namespace string `json:"namespace,omitempty"`
+	// MaxAge optionally overrides the client's cache TTL for this key, in
+	// seconds. Zero means do not cache.
+	MaxAge *float64 `json:"maxAge,omitempty"`
 }
 
 type valuesResponse struct {
 	Values map[string]any `json:"values"`
+	// Tiers optionally maps each key to its schema tier so SDKs can
+	// enforce tier boundaries (see ConfigManager.GetPublicConfig).
+	Tiers map[string]ConfigTier `json:"tiers,omitempty"`
+	// Metadata optionally carries per-key cache hints and lifecycle state,
+	// e.g. {"KILL_SWITCH": {"maxAge": 5}, "OLD_KEY": {"archived": true}}.
+	Metadata map[string]valueMetadata `json:"metadata,omitempty"`
+}
+
+type valueMetadata struct {
+	MaxAge *float64 `json:"maxAge,omitempty"`
+	// Archived marks a soft-deleted key, kept server-side until it is
+	// purged; ArchivedAt optionally says when it was archived.
+	Archived   bool       `json:"archived,omitempty"`
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+	// Namespace optionally names the namespace the key belongs to.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// maxAgeDuration converts a maxAge hint in seconds. Missing or negative
+// hints report ok == false.
+func maxAgeDuration(seconds *float64) (time.Duration, bool) {
+	if seconds == nil || *seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(*seconds * float64(time.Second)), true
 }
 
 // ConfigClientOption configures a ConfigClient.
 type ConfigClientOption func(*ConfigClient)
 
 // WithCacheTTL sets the cache time-to-live duration.
-// Zero (default) means cache never expires.
+// Zero (default) means cache never expires. Keys the server sends a maxAge
+// cache hint for expire after that instead, so the platform can keep hot
+// keys such as kill switches fresh without a short client-wide TTL.
 func WithCacheTTL(ttl time.Duration) ConfigClientOption {
 	return func(c *ConfigClient) {
 		c.cacheTTL = ttl
@@ -91,10 +194,44 @@ func WithTokenProvider(tp *TokenProvider) ConfigClientOption {
 	}
 }
 
-// WithHTTPClient injects a custom *http.Client. Mainly for tests.
+// WithRing sets the deployment ring sent on value fetches. Defaults to
+// $SMOOAI_CONFIG_RING (or $RING, or $DEPLOYMENT_STAGE). Pass an empty string
+// to disable ring-specific values.
+func WithRing(ring string) ConfigClientOption {
+	return func(c *ConfigClient) {
+		c.ring = ring
+	}
+}
+
+// WithHTTPClient injects a custom *http.Client, used for both API and OAuth
+// token requests. Defaults to http.DefaultClient, or in js/wasm builds a
+// client using FetchTransport. The client is not modified: WithTransport and
+// WithRequestTimeout apply to a copy. Since other clients may share it,
+// Close leaves its idle connections open.
 func WithHTTPClient(httpClient *http.Client) ConfigClientOption {
 	return func(c *ConfigClient) {
 		c.client = httpClient
+		c.sharedHTTP = true
+	}
+}
+
+// WithTransport sets the http.RoundTripper for API and OAuth token requests —
+// for proxies, corporate TLS roots, or instrumented (e.g. OpenTelemetry)
+// transports. Authentication headers are added per request, so the
+// transport sees them like any other header.
+func WithTransport(transport http.RoundTripper) ConfigClientOption {
+	return func(c *ConfigClient) {
+		c.transport = transport
+	}
+}
+
+// WithRequestTimeout bounds each API and OAuth token request, including
+// reading the response body. Zero (default) means no timeout beyond the
+// caller's context. Subscribe's event stream is exempt, since it stays open
+// by design.
+func WithRequestTimeout(timeout time.Duration) ConfigClientOption {
+	return func(c *ConfigClient) {
+		c.requestTimeout = timeout
 	}
 }
 
@@ -123,23 +260,51 @@ func NewConfigClient(baseURL, clientID, clientSecret, orgID string, opts ...Conf
 		orgID = os.Getenv("SMOOAI_CONFIG_ORG_ID")
 	}
 
-	defaultEnv := os.Getenv("SMOOAI_CONFIG_ENV")
-	if defaultEnv == "" {
-		defaultEnv = "development"
-	}
+	defaultEnv := GetConfigEnvironment()
 
 	c := &ConfigClient{
 		baseURL:            strings.TrimRight(baseURL, "/"),
-		orgID:              orgID,
-		defaultEnvironment: defaultEnv,
-		client:             http.DefaultClient,
-		cache:              make(map[string]cacheEntry),
+		orgID:              normalizeOrgID(orgID),
+		defaultEnvironment: normalizeEnvironment(defaultEnv),
+		ring:               GetDeploymentRing(),
+		watchTransport:     WatchTransport(strings.ToLower(os.Getenv("SMOOAI_CONFIG_WATCH_TRANSPORT"))),
+		client:             defaultHTTPClient,
+		cache:              make(map[CacheKey]cacheEntry),
+		snapshots:          make(map[string]valuesSnapshot),
+		maxAges:            make(map[CacheKey]time.Duration),
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	if c.transport != nil || c.requestTimeout > 0 {
+		hc := *c.client
+		if c.transport != nil {
+			hc.Transport = c.transport
+		}
+		if c.requestTimeout > 0 {
+			hc.Timeout = c.requestTimeout
+		}
+		c.client = &hc
+	}
+	// Token requests always go direct; API requests may go through a
+	// local agent.
+	tokenClient := c.client
+	if addr := resolveAgentAddr(c.agentAddr); addr != "" && c.baseURL != "" {
+		if agent, err := newAgentTransport(c.baseURL, addr, c.client.Transport); err == nil {
+			hc := *c.client
+			hc.Transport = agent
+			c.client = &hc
+		}
+	}
+	c.streamClient = c.client
+	if c.client.Timeout > 0 {
+		stream := *c.client
+		stream.Timeout = 0
+		c.streamClient = &stream
+	}
+
 	// Resolve OAuth issuer URL: explicit override > env var > legacy env > default.
 	authURL := c.authURLOverride
 	if authURL == "" {
@@ -154,7 +319,7 @@ func NewConfigClient(baseURL, clientID, clientSecret, orgID string, opts ...Conf
 
 	if c.tokenProvider == nil && clientID != "" && clientSecret != "" {
 		// Errors here only happen on empty inputs — guarded above.
-		tp, _ := NewTokenProvider(authURL, clientID, clientSecret, WithTokenProviderHTTPClient(c.client))
+		tp, _ := NewTokenProvider(authURL, clientID, clientSecret, WithTokenProviderHTTPClient(tokenClient))
 		c.tokenProvider = tp
 	}
 
@@ -169,17 +334,71 @@ func NewConfigClientFromEnv(opts ...ConfigClientOption) *ConfigClient {
 }
 
 func (c *ConfigClient) resolveEnv(environment string) string {
-	if environment != "" {
-		return environment
+	if env := normalizeEnvironment(environment); env != "" {
+		return env
 	}
 	return c.defaultEnvironment
 }
 
-func (c *ConfigClient) computeExpiresAt() time.Time {
+// requestEnv resolves environment like resolveEnv and validates it and the
+// org ID, so a malformed one fails with an *InvalidInputError instead of an
+// opaque 404 from the server.
+func (c *ConfigClient) requestEnv(environment string) (string, error) {
+	if err := ValidateOrgID(c.orgID); err != nil {
+		return "", err
+	}
+	env := c.resolveEnv(environment)
+	if err := ValidateEnvironment(env); err != nil {
+		return "", err
+	}
+	return env, nil
+}
+
+// orgURL returns the base URL of the org's API routes.
+func (c *ConfigClient) orgURL() string {
+	return c.baseURL + "/organizations/" + url.PathEscape(c.orgID)
+}
+
+// valuesQuery builds the query string for value fetches: the environment
+// plus the deployment ring when one is configured.
+func (c *ConfigClient) valuesQuery(env string) string {
+	q := "environment=" + url.QueryEscape(env)
+	if c.ring != "" {
+		q += "&ring=" + url.QueryEscape(c.ring)
+	}
+	if c.namespace != "" {
+		q += "&namespace=" + url.QueryEscape(c.namespace)
+	}
+	for _, p := range c.keyPrefixes {
+		q += "&prefix=" + url.QueryEscape(p)
+	}
+	return q
+}
+
+func (c *ConfigClient) computeExpiresAt() deadline {
 	if c.cacheTTL > 0 {
-		return time.Now().Add(c.cacheTTL)
+		return expireAfter(c.cacheTTL)
+	}
+	return 0
+}
+
+// expiresAtLocked is computeExpiresAt for one cache key, honoring the
+// server's maxAge hint when it sent one. c.mu must be held.
+func (c *ConfigClient) expiresAtLocked(cacheKey CacheKey) deadline {
+	if maxAge, ok := c.maxAges[cacheKey]; ok {
+		return expireAfter(maxAge)
+	}
+	return c.computeExpiresAt()
+}
+
+// setMaxAgeLocked records (or, for a nil hint, clears) the maxAge hint for
+// cacheKey. c.mu must be held.
+func (c *ConfigClient) setMaxAgeLocked(cacheKey CacheKey, seconds *float64) {
+	if maxAge, ok := maxAgeDuration(seconds); ok {
+		c.maxAges[cacheKey] = maxAge
+	} else {
+		delete(c.maxAges, cacheKey)
 	}
-	return time.Time{}
 }
 
 // authHeader returns "Bearer <jwt>" by minting/refreshing via the
@@ -200,19 +419,33 @@ func (c *ConfigClient) authHeader(ctx context.Context) (string, error) {
 // invalidating the cached token on a 401 to handle server-side rotation
 // or revocation.
 func (c *ConfigClient) doRequestWithRetry(req *http.Request) (*http.Response, error) {
+	return c.doRequestWithRetryUsing(c.client, req)
+}
+
+// doRequestWithRetryUsing is doRequestWithRetry over a specific http.Client.
+func (c *ConfigClient) doRequestWithRetryUsing(client *http.Client, req *http.Request) (*http.Response, error) {
+	if err := c.admit(req.Context()); err != nil {
+		return nil, err
+	}
 	authHeader, err := c.authHeader(req.Context())
 	if err != nil {
+		c.breaker.release()
 		return nil, err
 	}
 	req.Header.Set("Authorization", authHeader)
+	if ae := c.acceptEncoding(); ae != "" && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", ae)
+	}
 
 	// http.Client.Do consumes the request body. For retry safety we
 	// snapshot the body when the caller provided GetBody (set by
 	// http.NewRequestWithContext for bytes.Reader / strings.Reader).
-	resp, err := c.client.Do(req)
+	resp, err := c.send(client, req)
 	if err != nil {
+		c.log().Debug("config API request failed", "method", req.Method, "path", req.URL.Path, "error", err)
 		return nil, err
 	}
+	c.log().Debug("config API request", "method", req.Method, "path", req.URL.Path, "status", resp.StatusCode)
 	if resp.StatusCode != http.StatusUnauthorized {
 		return resp, nil
 	}
@@ -220,6 +453,7 @@ func (c *ConfigClient) doRequestWithRetry(req *http.Request) (*http.Response, er
 	// 401 — drain + close, invalidate, retry once with a freshly minted token.
 	_, _ = io.Copy(io.Discard, resp.Body)
 	_ = resp.Body.Close()
+	c.log().Info("config API rejected the token, retrying with a new one", "path", req.URL.Path)
 
 	if c.tokenProvider != nil {
 		c.tokenProvider.Invalidate()
@@ -234,24 +468,49 @@ func (c *ConfigClient) doRequestWithRetry(req *http.Request) (*http.Response, er
 		req.Body = body
 	}
 
+	// The retry is a request of its own to the limiter and the breaker.
+	if err := c.admit(req.Context()); err != nil {
+		return nil, err
+	}
 	authHeader, err = c.authHeader(req.Context())
 	if err != nil {
+		c.breaker.release()
 		return nil, err
 	}
 	req.Header.Set("Authorization", authHeader)
-	return c.client.Do(req)
+	return c.send(client, req)
+}
+
+// admit takes a breaker slot and a rate limiter token for one request. A
+// caller that then sends nothing must call c.breaker.release.
+func (c *ConfigClient) admit(ctx context.Context) error {
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+	if err := c.limiter.wait(ctx); err != nil {
+		c.breaker.release()
+		return err
+	}
+	return nil
 }
 
 // GetValue retrieves a single config value for the given key and environment.
 // Pass empty string for environment to use the default.
-// Results are cached locally after the first fetch.
+// Results are cached locally after the first fetch. Concurrent cache misses
+// for the same key and environment share a single HTTP request.
 func (c *ConfigClient) GetValue(key, environment string) (any, error) {
-	env := c.resolveEnv(environment)
-	cacheKey := env + ":" + key
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
+	}
+	if !c.inScope(key, "") {
+		return nil, nil
+	}
+	cacheKey := c.valueCacheKey(env, key)
 
 	c.mu.RLock()
 	if entry, ok := c.cache[cacheKey]; ok {
-		if entry.expiresAt.IsZero() || time.Now().Before(entry.expiresAt) {
+		if !entry.expiresAt.passed() {
 			c.mu.RUnlock()
 			return entry.value, nil
 		}
@@ -259,8 +518,15 @@ func (c *ConfigClient) GetValue(key, environment string) (any, error) {
 	}
 	c.mu.RUnlock()
 
-	u := fmt.Sprintf("%s/organizations/%s/config/values/%s?environment=%s",
-		c.baseURL, c.orgID, url.PathEscape(key), url.QueryEscape(env))
+	value, err, _ := c.flights.Do("value:"+cacheKey.flightKey(), func() (any, error) {
+		return c.fetchValue(key, env)
+	})
+	return value, err
+}
+
+func (c *ConfigClient) fetchValue(key, env string) (any, error) {
+	u := fmt.Sprintf("%s/config/values/%s?%s",
+		c.orgURL(), url.PathEscape(key), c.valuesQuery(env))
 
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u, nil)
 	if err != nil {
@@ -274,17 +540,21 @@ func (c *ConfigClient) GetValue(key, environment string) (any, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("config get value: HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, newHTTPError("get value", resp)
 	}
 
 	var result valueResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("config get value decode: %w", err)
 	}
+	if !c.inScope(key, result.Namespace) {
+		return nil, nil
+	}
 
+	cacheKey := c.valueCacheKey(env, key)
 	c.mu.Lock()
-	c.cache[cacheKey] = cacheEntry{value: result.Value, expiresAt: c.computeExpiresAt()}
+	c.setMaxAgeLocked(cacheKey, result.MaxAge)
+	c.setCacheLocked(cacheKey, result.Value)
 	c.mu.Unlock()
 
 	return result.Value, nil
@@ -292,42 +562,144 @@ func (c *ConfigClient) GetValue(key, environment string) (any, error) {
 
 // GetAllValues retrieves all config values for the given environment.
 // Pass empty string for environment to use the default.
-// All values are cached locally after the fetch.
+// All values are cached locally after the fetch. When the server sent an
+// ETag on the previous fetch, the refresh carries If-None-Match and a 304
+// Not Modified re-stamps the cached values instead of re-downloading them,
+// keeping frequent polling cheap. Concurrent calls for the
+// same environment share a single HTTP request. Each call returns its own
+// copy of the map, so adding or deleting keys never affects the cache or
+// another caller.
 func (c *ConfigClient) GetAllValues(environment string) (map[string]any, error) {
-	env := c.resolveEnv(environment)
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err, _ := c.flights.Do("values:"+env, func() (any, error) {
+		return c.fetchAllValues(env)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return maps.Clone(values.(map[string]any)), nil
+}
 
-	u := fmt.Sprintf("%s/organizations/%s/config/values?environment=%s",
-		c.baseURL, c.orgID, url.QueryEscape(env))
+func (c *ConfigClient) fetchAllValues(env string) (map[string]any, error) {
+	u := fmt.Sprintf("%s/config/values?%s",
+		c.orgURL(), c.valuesQuery(env))
 
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u, nil)
 	if err != nil {
 		return nil, fmt.Errorf("config get all values: %w", err)
 	}
 
+	c.mu.RLock()
+	prev, hasPrev := c.snapshots[env]
+	c.mu.RUnlock()
+	if hasPrev && prev.etag != "" {
+		req.Header.Set("If-None-Match", prev.etag)
+	}
+
 	resp, err := c.doRequestWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("config get all values: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && hasPrev {
+		c.storeValues(env, prev)
+		c.reportSnapshotAsync(env, prev.values)
+		return prev.values, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("config get all values: HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, newHTTPError("get all values", resp)
 	}
 
-	var result valuesResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	result, err := decodeValuesResponse(resp.Body)
+	if err != nil {
 		return nil, fmt.Errorf("config get all values decode: %w", err)
 	}
+	c.dropOutOfScope(&result)
+
+	snap := valuesSnapshot{etag: resp.Header.Get("ETag"), values: result.Values, tiers: result.Tiers}
+	for key, meta := range result.Metadata {
+		if maxAge, ok := maxAgeDuration(meta.MaxAge); ok {
+			if snap.maxAges == nil {
+				snap.maxAges = make(map[string]time.Duration)
+			}
+			snap.maxAges[key] = maxAge
+		}
+		if meta.Archived {
+			if snap.archived == nil {
+				snap.archived = make(map[string]time.Time)
+			}
+			var at time.Time
+			if meta.ArchivedAt != nil {
+				at = *meta.ArchivedAt
+			}
+			snap.archived[key] = at
+		}
+	}
+	c.storeValues(env, snap)
+	c.reportSnapshotAsync(env, result.Values)
+	return result.Values, nil
+}
 
+// storeValues records a full-values snapshot for env and (re-)stamps every
+// value into the per-key cache with a fresh expiry.
+func (c *ConfigClient) storeValues(env string, snap valuesSnapshot) {
 	c.mu.Lock()
-	expiresAt := c.computeExpiresAt()
-	for key, value := range result.Values {
-		c.cache[env+":"+key] = cacheEntry{value: value, expiresAt: expiresAt}
+	defer c.mu.Unlock()
+	c.snapshots[env] = snap
+	for key, value := range snap.values {
+		cacheKey := c.valueCacheKey(env, key)
+		if maxAge, ok := snap.maxAges[key]; ok {
+			c.maxAges[cacheKey] = maxAge
+		} else {
+			delete(c.maxAges, cacheKey)
+		}
+		c.setCacheLocked(cacheKey, value)
 	}
-	c.mu.Unlock()
+}
 
-	return result.Values, nil
+// KeyTiers returns the per-key tier metadata the server sent with the last
+// GetAllValues for environment, or nil when it sent none (or nothing has
+// been fetched). Pass an empty environment to use the client's default.
+func (c *ConfigClient) KeyTiers(environment string) map[string]ConfigTier {
+	env := c.resolveEnv(environment)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	snap, ok := c.snapshots[env]
+	if !ok || snap.tiers == nil {
+		return nil
+	}
+	tiers := make(map[string]ConfigTier, len(snap.tiers))
+	for k, v := range snap.tiers {
+		tiers[k] = v
+	}
+	return tiers
+}
+
+// ArchivedKeys returns the keys the server marked archived (soft-deleted)
+// in the last GetAllValues for environment, each with when it was archived
+// (zero if the server didn't say), or nil when there are none. The values
+// of archived keys are still returned by GetAllValues and GetValue;
+// ConfigManager treats them as absent. Pass an empty environment to use the
+// client's default.
+func (c *ConfigClient) ArchivedKeys(environment string) map[string]time.Time {
+	env := c.resolveEnv(environment)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	snap, ok := c.snapshots[env]
+	if !ok || len(snap.archived) == 0 {
+		return nil
+	}
+	archived := make(map[string]time.Time, len(snap.archived))
+	for k, v := range snap.archived {
+		archived[k] = v
+	}
+	return archived
 }
 
 // SeedCache pre-populates a single cache entry without a network request.
@@ -335,10 +707,7 @@ func (c *ConfigClient) GetAllValues(environment string) (map[string]any, error)
 // ConfigClient.seedCache — used by container mode to record an env-tier
 // override so a subsequent GetCachedValue (sync read) sees it.
 func (c *ConfigClient) SeedCache(key string, value any, environment string) {
-	env := c.resolveEnv(environment)
-	c.mu.Lock()
-	c.cache[env+":"+key] = cacheEntry{value: value, expiresAt: c.computeExpiresAt()}
-	c.mu.Unlock()
+	c.SeedCacheKey(c.valueCacheKey(environment, key), value)
 }
 
 // GetCachedValue synchronously reads a value from the local cache without a
@@ -346,41 +715,33 @@ func (c *ConfigClient) SeedCache(key string, value any, environment string) {
 // expired. Mirrors the TS ConfigClient.getCachedValue. Pass an empty
 // environment to use the client's default.
 func (c *ConfigClient) GetCachedValue(key, environment string) (any, bool) {
-	env := c.resolveEnv(environment)
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	entry, ok := c.cache[env+":"+key]
-	if !ok {
-		return nil, false
-	}
-	if !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
-		return nil, false
-	}
-	return entry.value, true
+	return c.CachedValue(c.valueCacheKey(environment, key))
 }
 
 // InvalidateCache clears all locally cached values.
 func (c *ConfigClient) InvalidateCache() {
 	c.mu.Lock()
-	c.cache = make(map[string]cacheEntry)
+	c.cache = make(map[CacheKey]cacheEntry)
+	c.snapshots = make(map[string]valuesSnapshot)
 	c.mu.Unlock()
 }
 
-// InvalidateCacheForEnvironment clears cached values for a specific environment.
+// InvalidateCacheForEnvironment clears cached values for a specific
+// environment. See InvalidateCacheMatching to invalidate by tier, namespace
+// or key.
 func (c *ConfigClient) InvalidateCacheForEnvironment(environment string) {
-	prefix := environment + ":"
-	c.mu.Lock()
-	for key := range c.cache {
-		if strings.HasPrefix(key, prefix) {
-			delete(c.cache, key)
-		}
+	if environment == "" {
+		return // the zero filter would match every environment
 	}
-	c.mu.Unlock()
+	c.InvalidateCacheMatching(CacheFilter{Environment: environment})
 }
 
-// Close releases resources held by the client.
+// Close releases resources held by the client. It closes the idle
+// connections of the HTTP client unless that came from WithHTTPClient.
 func (c *ConfigClient) Close() {
-	c.client.CloseIdleConnections()
+	if !c.sharedHTTP {
+		c.client.CloseIdleConnections()
+	}
 }
 
 // EvaluateFeatureFlagResponse is the wire contract for the segment-aware
@@ -482,7 +843,9 @@ func (e *FeatureFlagEvaluationError) Is(target error) bool {
 //     (e.g. {userId, tenantId, plan, country}). Unreferenced keys are ignored
 //     by the server. Keep values JSON-serializable — the server hashes
 //     `bucketBy` values by their string representation, so numbers and
-//     booleans bucket stably across client rebuilds. A nil map is sent as {}.
+//     booleans bucket stably across client rebuilds. Attributes set on ctx
+//     with WithEvalContext are included; keys passed here win. A nil map
+//     with no ctx attributes is sent as {}.
 //   - environment: environment name. Pass an empty string to use the client's
 //     default environment.
 //
@@ -498,12 +861,13 @@ func (c *ConfigClient) EvaluateFeatureFlag(
 	evalContext map[string]any,
 	environment string,
 ) (*EvaluateFeatureFlagResponse, error) {
-	env := c.resolveEnv(environment)
-
-	if evalContext == nil {
-		evalContext = map[string]any{}
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
 	}
 
+	evalContext = resolveEvalContext(ctx, evalContext)
+
 	body, err := json.Marshal(struct {
 		Environment string         `json:"environment"`
 		Context     map[string]any `json:"context"`
@@ -515,8 +879,8 @@ func (c *ConfigClient) EvaluateFeatureFlag(
 		return nil, fmt.Errorf("config evaluate feature flag %q: marshal body: %w", key, err)
 	}
 
-	u := fmt.Sprintf("%s/organizations/%s/config/feature-flags/%s/evaluate",
-		c.baseURL, c.orgID, url.PathEscape(key))
+	u := fmt.Sprintf("%s/config/feature-flags/%s/evaluate",
+		c.orgURL(), url.PathEscape(key))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
 	if err != nil {
@@ -654,12 +1018,13 @@ func (c *ConfigClient) EvaluateLimit(
 	evalContext map[string]any,
 	environment string,
 ) (*EvaluateLimitResponse, error) {
-	env := c.resolveEnv(environment)
-
-	if evalContext == nil {
-		evalContext = map[string]any{}
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
 	}
 
+	evalContext = resolveEvalContext(ctx, evalContext)
+
 	body, err := json.Marshal(struct {
 		Environment string         `json:"environment"`
 		Context     map[string]any `json:"context"`
@@ -671,8 +1036,8 @@ func (c *ConfigClient) EvaluateLimit(
 		return nil, fmt.Errorf("config evaluate limit %q: marshal body: %w", key, err)
 	}
 
-	u := fmt.Sprintf("%s/organizations/%s/config/limits/%s/evaluate",
-		c.baseURL, c.orgID, url.PathEscape(key))
+	u := fmt.Sprintf("%s/config/limits/%s/evaluate",
+		c.orgURL(), url.PathEscape(key))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
 	if err != nil {
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CacheKey identifies one value in the ConfigClient cache by the query that
+// fetched it. Tier and Namespace scope the query; they are empty for the
+// environment-wide fetches (GetValue, GetAllValues) and are there so tier-
+// and namespace-scoped fetches cache separately without sharing entries.
+type CacheKey struct {
+	Environment string
+	Tier        ConfigTier
+	Namespace   string
+	Key         string
+}
+
+// valueCacheKey is the CacheKey of an environment-wide fetch, scoped to the
+// client's namespace (WithClientNamespace).
+func (c *ConfigClient) valueCacheKey(env, key string) CacheKey {
+	return CacheKey{Environment: env, Namespace: c.namespace, Key: key}
+}
+
+// flightKey encodes k for the flight group. Quoting keeps distinct keys
+// distinct whatever characters they contain.
+func (k CacheKey) flightKey() string {
+	return strings.Join([]string{
+		strconv.Quote(k.Environment), strconv.Quote(string(k.Tier)), strconv.Quote(k.Namespace), strconv.Quote(k.Key),
+	}, ",")
+}
+
+// CacheFilter selects cache entries by any combination of dimensions. An
+// empty field matches every value of that dimension; the zero filter
+// matches everything.
+type CacheFilter struct {
+	Environment string
+	Tier        ConfigTier
+	Namespace   string
+	Key         string
+}
+
+// Matches reports whether k falls within f.
+func (f CacheFilter) Matches(k CacheKey) bool {
+	return (f.Environment == "" || f.Environment == k.Environment) &&
+		(f.Tier == "" || f.Tier == k.Tier) &&
+		(f.Namespace == "" || f.Namespace == k.Namespace) &&
+		(f.Key == "" || f.Key == k.Key)
+}
+
+// CachedValue reads the entry for k without a network request. It returns
+// (nil, false) when there is none or it has expired. An empty
+// k.Environment means the client's default environment.
+func (c *ConfigClient) CachedValue(k CacheKey) (any, bool) {
+	k.Environment = c.resolveEnv(k.Environment)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.cache[k]
+	if !ok || entry.expiresAt.passed() {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// SeedCacheKey stores value under k without a network request. An empty
+// k.Environment means the client's default environment.
+func (c *ConfigClient) SeedCacheKey(k CacheKey, value any) {
+	k.Environment = c.resolveEnv(k.Environment)
+	c.mu.Lock()
+	c.setCacheLocked(k, value)
+	c.mu.Unlock()
+}
+
+// CachedKeys lists the unexpired cache entries matching f, in no
+// particular order.
+func (c *ConfigClient) CachedKeys(f CacheFilter) []CacheKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var keys []CacheKey
+	for k, entry := range c.cache {
+		if f.Matches(k) && !entry.expiresAt.passed() {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// InvalidateCacheMatching drops the cache entries matching f, so the next
+// read refetches them. A filter on the environment alone also drops the
+// environment's GetAllValues snapshot, so the next fetch downloads the
+// values again rather than revalidating them with an ETag.
+func (c *ConfigClient) InvalidateCacheMatching(f CacheFilter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.cache {
+		if f.Matches(k) {
+			delete(c.cache, k)
+			delete(c.maxAges, k)
+		}
+	}
+	if f.Tier != "" || f.Namespace != "" || f.Key != "" {
+		return
+	}
+	for env := range c.snapshots {
+		if f.Environment == "" || f.Environment == env {
+			delete(c.snapshots, env)
+		}
+	}
+}
+
+// setCacheLocked stores value under k with its expiry. c.mu must be held.
+func (c *ConfigClient) setCacheLocked(k CacheKey, value any) {
+	c.cache[k] = cacheEntry{value: value, expiresAt: c.expiresAtLocked(k)}
+}
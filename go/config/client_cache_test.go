@@ -0,0 +1,74 @@
+package config
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientCache_KeysDoNotCollide(t *testing.T) {
+	client := NewConfigClient("https://api.example.com", "cid", "sec", testOrgID)
+	defer client.Close()
+
+	// "a:b" + "c" and "a" + "b:c" were the same string key.
+	client.SeedCacheKey(CacheKey{Environment: "a:b", Key: "c"}, 1)
+	client.SeedCacheKey(CacheKey{Environment: "a", Key: "b:c"}, 2)
+	client.SeedCacheKey(CacheKey{Environment: "a", Tier: TierSecret, Key: "b:c"}, 3)
+
+	v, _ := client.CachedValue(CacheKey{Environment: "a:b", Key: "c"})
+	assert.Equal(t, 1, v)
+	v, _ = client.CachedValue(CacheKey{Environment: "a", Key: "b:c"})
+	assert.Equal(t, 2, v)
+	v, _ = client.CachedValue(CacheKey{Environment: "a", Tier: TierSecret, Key: "b:c"})
+	assert.Equal(t, 3, v)
+	assert.NotEqual(t, CacheKey{Environment: "a:b", Key: "c"}.flightKey(), CacheKey{Environment: "a", Key: "b:c"}.flightKey())
+}
+
+func TestClientCache_InvalidateByDimension(t *testing.T) {
+	client := NewConfigClient("https://api.example.com", "cid", "sec", testOrgID)
+	defer client.Close()
+	client.defaultEnvironment = "prod"
+
+	keys := []CacheKey{
+		{Environment: "prod", Key: "API_URL"},
+		{Environment: "prod", Tier: TierSecret, Key: "DB_PASSWORD"},
+		{Environment: "prod", Tier: TierSecret, Namespace: "billing", Key: "STRIPE_KEY"},
+		{Environment: "staging", Namespace: "billing", Key: "API_URL"},
+	}
+	reset := func() {
+		client.InvalidateCache()
+		for _, k := range keys {
+			client.SeedCacheKey(k, "v")
+		}
+	}
+	remaining := func() []string {
+		var out []string
+		for _, k := range client.CachedKeys(CacheFilter{}) {
+			out = append(out, k.Environment+"/"+string(k.Tier)+"/"+k.Namespace+"/"+k.Key)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	reset()
+	client.InvalidateCacheMatching(CacheFilter{Tier: TierSecret})
+	assert.Equal(t, []string{"prod///API_URL", "staging//billing/API_URL"}, remaining())
+
+	reset()
+	client.InvalidateCacheMatching(CacheFilter{Namespace: "billing"})
+	assert.Equal(t, []string{"prod///API_URL", "prod/secret//DB_PASSWORD"}, remaining())
+
+	reset()
+	client.InvalidateCacheMatching(CacheFilter{Key: "API_URL"})
+	assert.Len(t, remaining(), 2)
+
+	reset()
+	client.InvalidateCacheForEnvironment("prod")
+	assert.Equal(t, []string{"staging//billing/API_URL"}, remaining())
+
+	reset()
+	assert.Len(t, client.CachedKeys(CacheFilter{Environment: "prod", Tier: TierSecret}), 2)
+	_, ok := client.GetCachedValue("API_URL", "")
+	assert.True(t, ok, "the string accessors are the unscoped keys of the default environment")
+}
@@ -305,18 +305,18 @@ func TestIntegration_GetValue_ErrorOn401(t *testing.T) {
 
 	_, err := client.GetValue("API_URL", "production")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "401")
+	assert.ErrorIs(t, err, ErrUnauthorized)
 }
 
 func TestIntegration_GetValue_ErrorOn403(t *testing.T) {
 	m := newMockConfigServer()
 	defer m.close()
-	client := NewConfigClient(m.server.URL, testClientID, testAPIKey, "wrong-org-id", WithTokenProvider(newStubTokenProvider()))
+	client := NewConfigClient(m.server.URL, testClientID, testAPIKey, "00000000-0000-4000-8000-000000000000", WithTokenProvider(newStubTokenProvider()))
 	defer client.Close()
 
 	_, err := client.GetValue("API_URL", "production")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "403")
+	assert.ErrorIs(t, err, ErrForbidden)
 }
 
 func TestIntegration_GetValue_ErrorOn404(t *testing.T) {
@@ -327,7 +327,7 @@ func TestIntegration_GetValue_ErrorOn404(t *testing.T) {
 
 	_, err := client.GetValue("NONEXISTENT_KEY", "production")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "404")
+	assert.ErrorIs(t, err, ErrNotFound)
 }
 
 func TestIntegration_GetValue_ErrorOn500(t *testing.T) {
@@ -341,7 +341,7 @@ func TestIntegration_GetValue_ErrorOn500(t *testing.T) {
 	defer client.Close()
 	_, err := client.GetValue("API_URL", "production")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "500")
+	assert.ErrorIs(t, err, ErrServerError)
 }
 
 // ---------------------------------------------------------------------------
@@ -405,7 +405,7 @@ func TestIntegration_GetAllValues_ErrorOn401(t *testing.T) {
 
 	_, err := client.GetAllValues("production")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "401")
+	assert.ErrorIs(t, err, ErrUnauthorized)
 }
 
 // ---------------------------------------------------------------------------
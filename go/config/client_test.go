@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -49,7 +51,7 @@ func newFixedTokenProvider(t *testing.T, token string) *TokenProvider {
 func newUnitClient(t *testing.T, baseURL string, opts ...ConfigClientOption) *ConfigClient {
 	t.Helper()
 	all := append([]ConfigClientOption{WithTokenProvider(newFixedTokenProvider(t, unitTestJWT))}, opts...)
-	return NewConfigClient(baseURL, "test-client-id", "test-secret", "org-id", all...)
+	return NewConfigClient(baseURL, "test-client-id", "test-secret", testOrgID, all...)
 }
 
 func newTestServer(handler http.HandlerFunc) *httptest.Server {
@@ -78,7 +80,7 @@ func TestNewConfigClient_StoresOrgID(t *testing.T) {
 }
 
 func TestNewConfigClient_InitializesEmptyCache(t *testing.T) {
-	client := NewConfigClient("https://api.example.com", "cid", "sec", "org")
+	client := NewConfigClient("https://api.example.com", "cid", "sec", testOrgID)
 	defer client.Close()
 
 	assert.Empty(t, client.cache)
@@ -184,16 +186,16 @@ func TestGetAllValues_PopulatesCache(t *testing.T) {
 	_, err := client.GetAllValues("prod")
 	require.NoError(t, err)
 
-	assert.Equal(t, "val1", client.cache["prod:KEY1"].value)
-	assert.Equal(t, "val2", client.cache["prod:KEY2"].value)
+	assert.Equal(t, "val1", client.cache[client.valueCacheKey("prod", "KEY1")].value)
+	assert.Equal(t, "val2", client.cache[client.valueCacheKey("prod", "KEY2")].value)
 }
 
 func TestInvalidateCache_ClearsAll(t *testing.T) {
-	client := NewConfigClient("https://example.com", "cid", "sec", "org")
+	client := NewConfigClient("https://example.com", "cid", "sec", testOrgID)
 	defer client.Close()
 
-	client.cache["prod:KEY"] = cacheEntry{value: "value"}
-	client.cache["staging:KEY"] = cacheEntry{value: "value2"}
+	client.cache[client.valueCacheKey("prod", "KEY")] = cacheEntry{value: "value"}
+	client.cache[client.valueCacheKey("staging", "KEY")] = cacheEntry{value: "value2"}
 	assert.Len(t, client.cache, 2)
 
 	client.InvalidateCache()
@@ -201,7 +203,7 @@ func TestInvalidateCache_ClearsAll(t *testing.T) {
 }
 
 func TestInvalidateCache_EmptyIsNoop(t *testing.T) {
-	client := NewConfigClient("https://example.com", "cid", "sec", "org")
+	client := NewConfigClient("https://example.com", "cid", "sec", testOrgID)
 	defer client.Close()
 
 	client.InvalidateCache()
@@ -220,7 +222,7 @@ func TestGetValue_ErrorOnServerError(t *testing.T) {
 
 	_, err := client.GetValue("KEY", "prod")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "500")
+	assert.ErrorIs(t, err, ErrServerError)
 }
 
 func TestGetValue_ErrorOnUnauthorized(t *testing.T) {
@@ -237,7 +239,7 @@ func TestGetValue_ErrorOnUnauthorized(t *testing.T) {
 
 	_, err := client.GetValue("KEY", "prod")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "401")
+	assert.ErrorIs(t, err, ErrUnauthorized)
 }
 
 func TestGetValue_ErrorOnNotFound(t *testing.T) {
@@ -252,7 +254,7 @@ func TestGetValue_ErrorOnNotFound(t *testing.T) {
 
 	_, err := client.GetValue("nonexistent", "prod")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "404")
+	assert.ErrorIs(t, err, ErrNotFound)
 }
 
 func TestGetValue_SetsAuthorizationHeader(t *testing.T) {
@@ -316,8 +318,9 @@ func TestNewConfigClientFromEnv(t *testing.T) {
 func TestNewConfigClient_DefaultEnvironment(t *testing.T) {
 	// Without SMOOAI_CONFIG_ENV set, default should be "development"
 	t.Setenv("SMOOAI_CONFIG_ENV", "")
+	t.Setenv("SMOOAI_CONFIG_ENV_INFERENCE", "false")
 
-	client := NewConfigClient("https://example.com", "cid", "sec", "org")
+	client := NewConfigClient("https://example.com", "cid", "sec", testOrgID)
 	defer client.Close()
 
 	assert.Equal(t, "development", client.defaultEnvironment)
@@ -358,3 +361,197 @@ func TestGetAllValues_UsesDefaultEnvironment(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "val", vals["KEY"])
 }
+
+func TestGetAllValues_SendsIfNoneMatchAndReusesValuesOn304(t *testing.T) {
+	var ifNoneMatch []string
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"A": "1"}})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	first, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"A": "1"}, first)
+	// The caller's map is its own: changing it doesn't reach the snapshot
+	// a 304 reuses.
+	first["B"] = "mine"
+	delete(first, "A")
+
+	second, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"A": "1"}, second)
+
+	assert.Equal(t, []string{"", `"v1"`}, ifNoneMatch)
+	v, ok := client.GetCachedValue("A", "production")
+	assert.True(t, ok)
+	assert.Equal(t, "1", v)
+}
+
+func TestGetAllValues_NoIfNoneMatchWithoutETag(t *testing.T) {
+	var ifNoneMatch []string
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"A": "1"}})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	_, err = client.GetAllValues("production")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", ""}, ifNoneMatch)
+}
+
+func TestGetAllValues_InvalidateDropsETag(t *testing.T) {
+	var ifNoneMatch []string
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"A": "1"}})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	client.InvalidateCacheForEnvironment("production")
+	_, err = client.GetAllValues("production")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", ""}, ifNoneMatch)
+}
+
+type countingTransport struct {
+	calls atomic.Int32
+	auth  atomic.Value
+}
+
+func (ct *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ct.calls.Add(1)
+	ct.auth.Store(req.Header.Get("Authorization"))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithTransport_RoutesRequests(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"value": "v"})
+	})
+	defer server.Close()
+
+	rt := &countingTransport{}
+	client := newUnitClient(t, server.URL, WithTransport(rt))
+	defer client.Close()
+
+	_, err := client.GetValue("KEY", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), rt.calls.Load())
+	assert.Equal(t, "Bearer "+unitTestJWT, rt.auth.Load())
+	assert.Nil(t, http.DefaultClient.Transport, "http.DefaultClient must not be modified")
+}
+
+func TestWithRequestTimeout_BoundsRequests(t *testing.T) {
+	release := make(chan struct{})
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer server.Close()
+	defer close(release)
+
+	custom := &http.Client{}
+	client := newUnitClient(t, server.URL, WithHTTPClient(custom), WithRequestTimeout(50*time.Millisecond))
+	defer client.Close()
+
+	start := time.Now()
+	_, err := client.GetValue("KEY", "prod")
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Zero(t, custom.Timeout, "the injected client must not be modified")
+}
+
+func TestGetValue_HonorsServerMaxAge(t *testing.T) {
+	var calls atomic.Int32
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"value": true, "maxAge": 0})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL, WithCacheTTL(time.Hour))
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetValue("KILL_SWITCH", "prod")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), calls.Load(), "maxAge 0 must bypass the client-wide TTL")
+}
+
+func TestGetAllValues_HonorsPerKeyMaxAge(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"values":   map[string]any{"KILL_SWITCH": false, "API_URL": "https://api"},
+			"metadata": map[string]any{"KILL_SWITCH": map[string]any{"maxAge": 0}},
+		})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL, WithCacheTTL(time.Hour))
+	defer client.Close()
+
+	_, err := client.GetAllValues("prod")
+	require.NoError(t, err)
+
+	_, ok := client.GetCachedValue("KILL_SWITCH", "prod")
+	assert.False(t, ok, "hinted key expires per its maxAge")
+	v, ok := client.GetCachedValue("API_URL", "prod")
+	assert.True(t, ok, "unhinted key keeps the client TTL")
+	assert.Equal(t, "https://api", v)
+
+	// Writes through the client keep honoring the hint.
+	client.SeedCache("KILL_SWITCH", true, "prod")
+	_, ok = client.GetCachedValue("KILL_SWITCH", "prod")
+	assert.False(t, ok)
+}
+
+func TestGetAllValues_MaxAgeSurvivesNotModified(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(map[string]any{
+			"values":   map[string]any{"KILL_SWITCH": false},
+			"metadata": map[string]any{"KILL_SWITCH": map[string]any{"maxAge": 0}},
+		})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetAllValues("prod")
+		require.NoError(t, err)
+	}
+	_, ok := client.GetCachedValue("KILL_SWITCH", "prod")
+	assert.False(t, ok)
+}
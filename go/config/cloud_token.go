@@ -0,0 +1,141 @@
+package config
+
+// OAuth access tokens for the GCP and Azure secret providers. Both clouds
+// hand workloads short-lived bearer tokens (metadata servers, managed
+// identities, service account keys); the default chains live next to each
+// provider and share the caching here.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CloudTokenSource supplies OAuth bearer tokens for a cloud secret store.
+type CloudTokenSource interface {
+	// AccessToken returns a token valid for resource: the Key Vault
+	// resource URI on Azure, ignored on GCP.
+	AccessToken(ctx context.Context, resource string) (string, error)
+}
+
+// StaticCloudToken returns a fixed token, e.g. from `gcloud auth
+// print-access-token` or `az account get-access-token` in local tooling.
+func StaticCloudToken(token string) CloudTokenSource {
+	return staticCloudToken(token)
+}
+
+type staticCloudToken string
+
+func (t staticCloudToken) AccessToken(context.Context, string) (string, error) {
+	return string(t), nil
+}
+
+// cloudTokenRefreshWindow is how long before expiry a token is fetched
+// again.
+const cloudTokenRefreshWindow = 5 * time.Minute
+
+// cloudTokenCache caches tokens per resource until shortly before they
+// expire.
+type cloudTokenCache struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]cloudToken
+}
+
+type cloudToken struct {
+	token   string
+	expires time.Time
+}
+
+// get returns the cached token for resource, or fetches a new one.
+func (c *cloudTokenCache) get(ctx context.Context, resource string, fetch func(context.Context) (cloudToken, error)) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.tokens[resource]; ok && c.now().Before(t.expires.Add(-cloudTokenRefreshWindow)) {
+		return t.token, nil
+	}
+	t, err := fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	if c.tokens == nil {
+		c.tokens = make(map[string]cloudToken)
+	}
+	c.tokens[resource] = t
+	return t.token, nil
+}
+
+// oauthTokenResponse is the token response shared by Google, Entra ID and
+// the managed identity endpoints. The lifetime fields are numbers on some
+// endpoints and strings on others.
+type oauthTokenResponse struct {
+	AccessToken string      `json:"access_token"`
+	ExpiresIn   json.Number `json:"expires_in"`
+	ExpiresOn   json.Number `json:"expires_on"`
+}
+
+// decodeOAuthToken decodes a token response received at now.
+func decodeOAuthToken(data []byte, now time.Time, source string) (cloudToken, error) {
+	var out oauthTokenResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return cloudToken{}, fmt.Errorf("%s: decoding response: %w", source, err)
+	}
+	if out.AccessToken == "" {
+		return cloudToken{}, fmt.Errorf("%s: response has no access_token", source)
+	}
+	// Prefer the relative lifetime: expires_on is a wall clock instant, so
+	// host clock skew shifts it. Either way the expiry is taken relative to
+	// now, keeping now's monotonic reading for the refresh check.
+	t := cloudToken{token: out.AccessToken}
+	if in, err := strconv.ParseInt(out.ExpiresIn.String(), 10, 64); err == nil {
+		t.expires = now.Add(time.Duration(in) * time.Second)
+	} else if on, err := strconv.ParseInt(out.ExpiresOn.String(), 10, 64); err == nil {
+		t.expires = now.Add(time.Unix(on, 0).Sub(now))
+	}
+	return t, nil
+}
+
+// doCloudRequest sends req and returns the body of a 200 response.
+func doCloudRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// cloudJSONError turns a Google or Azure error body,
+// {"error": {"code": ..., "message": ..., "status": ...}}, into an error.
+func cloudJSONError(service string, status int, body []byte) error {
+	var e struct {
+		Error struct {
+			Code    json.RawMessage `json:"code"`
+			Message string          `json:"message"`
+			Status  string          `json:"status"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &e)
+	code := e.Error.Status
+	if code == "" {
+		_ = json.Unmarshal(e.Error.Code, &code)
+	}
+	if code == "" {
+		return fmt.Errorf("%s: HTTP %d: %s", service, status, strings.TrimSpace(string(body)))
+	}
+	return fmt.Errorf("%s: %s: %s (HTTP %d)", service, code, e.Error.Message, status)
+}
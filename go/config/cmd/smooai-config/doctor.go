@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+// doctorMain runs the doctor command: it prints config.ConfigManager.Doctor's
+// report and exits 1 when something in it is broken.
+func doctorMain(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	environment := fs.String("environment", "", "config environment (default: detected)")
+	timeout := fs.Duration("timeout", 10*time.Second, "give up on the remote API after this long")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "doctor: unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return 2
+	}
+
+	var opts []config.ConfigManagerOption
+	if *environment != "" {
+		opts = append(opts, config.WithConfigEnvironment(*environment))
+	}
+	mgr := config.NewConfigManager(opts...)
+	defer mgr.Close(context.Background())
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	report := mgr.Doctor(ctx)
+	fmt.Fprint(stdout, report)
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
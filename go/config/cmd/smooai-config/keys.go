@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	config "github.com/SmooAI/config/go/config"
+	"github.com/SmooAI/config/go/config/keyusage"
+)
+
+// keysMain runs the keys command: it checks the config keys read in the Go
+// packages under each directory (default ".") against a schema and exits 1
+// when one isn't declared or is read from the wrong tier.
+func keysMain(_ context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("keys", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaPath := fs.String("schema", "", "schema bundle file (default: fetch the published schema from the API)")
+	environment := fs.String("environment", "", "environment whose published schema to fetch (default: detected)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	dirs := fs.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	def, err := loadKeysSchema(*schemaPath, *environment)
+	if err != nil {
+		fmt.Fprintf(stderr, "keys: %v\n", err)
+		return 2
+	}
+	code := 0
+	for _, dir := range dirs {
+		diags, err := keyusage.CheckDir(def, dir)
+		if err != nil {
+			fmt.Fprintf(stderr, "keys: %v\n", err)
+			return 2
+		}
+		for _, d := range diags {
+			fmt.Fprintln(stdout, d)
+			code = 1
+		}
+	}
+	return code
+}
+
+// loadKeysSchema reads the schema bundle at path, or fetches the schema
+// published for environment when path is empty.
+func loadKeysSchema(path, environment string) (*config.ConfigDefinition, error) {
+	if path == "" {
+		client := config.NewConfigClientFromEnv()
+		defer client.Close()
+		return client.GetSchema(environment)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return config.ReadSchemaBundle(f)
+}
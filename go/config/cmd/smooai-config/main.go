@@ -0,0 +1,43 @@
+// Command smooai-config is the Go SDK's command line:
+//
+//	smooai-config render [flags]
+//	smooai-config doctor [flags]
+//	smooai-config keys [flags] [dir ...]
+//
+// render writes merged config to files for init containers; see package
+// render for its exit codes. doctor reports where config comes from and
+// exits 1 when a source is broken. keys checks the config keys the Go code
+// under each dir reads against the schema and exits 1 when one is undeclared
+// or read from the wrong tier. Run any of them with -h for its flags.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/SmooAI/config/go/config/render"
+)
+
+func main() {
+	commands := map[string]func(context.Context, []string, io.Writer, io.Writer) int{
+		"render": render.Main,
+		"doctor": doctorMain,
+		"keys":   keysMain,
+	}
+	var run func(context.Context, []string, io.Writer, io.Writer) int
+	if len(os.Args) >= 2 {
+		run = commands[os.Args[1]]
+	}
+	if run == nil {
+		fmt.Fprintln(os.Stderr, "usage: smooai-config render|doctor|keys [flags]")
+		os.Exit(render.ExitUsage)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[2:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
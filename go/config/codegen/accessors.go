@@ -0,0 +1,371 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+// AccessorsOptions configures GenerateAccessors.
+type AccessorsOptions struct {
+	// Package is the package clause of the generated file. Defaults to
+	// "typedconfig".
+	Package string
+}
+
+// accessorTiers pairs each tier with its generated accessor struct, the
+// Config field holding it, and the Getter method it reads through.
+var accessorTiers = []struct {
+	tier     config.ConfigTier
+	typeName string
+	field    string
+	getter   string
+}{
+	{config.TierPublic, "PublicConfig", "Public", "GetPublicConfig"},
+	{config.TierSecret, "SecretConfig", "Secret", "GetSecretConfig"},
+	{config.TierFeatureFlag, "FeatureFlagConfig", "FeatureFlags", "GetFeatureFlag"},
+}
+
+// GenerateAccessors is the reverse of DefineConfigTyped: it emits a Go file
+// with one accessor struct per tier and a typed getter method per declared
+// key, so renaming, removing or retyping a key in the schema breaks the build
+// at every call site:
+//
+//	cfg := typedconfig.New(manager)
+//	url, err := cfg.Public.APIURL()          // string
+//	retries, err := cfg.Public.MaxRetries()  // int
+//	on, err := cfg.FeatureFlags.EnableNewUI() // bool
+//
+// Property types map to Go types as string → string, integer → int,
+// number → float64, boolean → bool, array → slice, and object → a generated
+// struct (or map[string]T when the object only declares
+// additionalProperties). A type of ["T", "null"] becomes *T; anything without
+// a single type becomes any. Getters decode the stored value via a JSON round
+// trip; a key no source sets returns the zero value.
+//
+// Returns an error when two keys or generated types map to the same Go
+// identifier.
+func GenerateAccessors(def *config.ConfigDefinition, opts AccessorsOptions) ([]byte, error) {
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "typedconfig"
+	}
+
+	g := &accessorGen{
+		seen:     map[string]bool{"Getter": true, "Config": true, "New": true, "decodeValue": true},
+		structs:  map[string]string{},
+		refTypes: map[string]string{},
+		building: map[string]bool{},
+	}
+	for _, at := range accessorTiers {
+		g.seen[at.typeName] = true
+	}
+
+	var body bytes.Buffer
+	for _, at := range accessorTiers {
+		schema := tierSchema(def, at.tier)
+		fmt.Fprintf(&body, "// %s reads the %s tier.\n", at.typeName, at.tier)
+		fmt.Fprintf(&body, "type %s struct{ g Getter }\n\n", at.typeName)
+
+		methods := map[string]string{}
+		for _, key := range def.Keys(at.tier) {
+			prop, _ := schemaProperties(schema, schema)[key].(map[string]any)
+			name := GoIdentifier(key)
+			if prev, dup := methods[name]; dup {
+				return nil, fmt.Errorf("codegen: keys %q and %q both map to method %s.%s", prev, key, at.typeName, name)
+			}
+			methods[name] = key
+			typ, err := g.goType(schema, prop, at.field+name)
+			if err != nil {
+				return nil, err
+			}
+			writeDescription(&body, fmt.Sprintf("%s returns the %s value of %q.", name, at.tier, key), prop)
+			fmt.Fprintf(&body, "func (c %s) %s() (%s, error) {\n", at.typeName, name, typ)
+			fmt.Fprintf(&body, "\tvar v %s\n", typ)
+			fmt.Fprintf(&body, "\terr := decodeValue(c.g.%s, %q, &v)\n", at.getter, key)
+			body.WriteString("\treturn v, err\n}\n\n")
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by smooai-config codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "// Package %s holds typed config accessors generated from the config schema.\n", pkg)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	buf.WriteString("import (\n\t\"encoding/json\"\n\t\"fmt\"\n)\n\n")
+
+	buf.WriteString("// Getter is the read surface the accessors call. *config.ConfigManager\n")
+	buf.WriteString("// and *config.LocalConfigManager satisfy it.\n")
+	buf.WriteString("type Getter interface {\n")
+	for _, at := range accessorTiers {
+		fmt.Fprintf(&buf, "\t%s(key string) (any, error)\n", at.getter)
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("// Config groups the accessors for every tier.\n")
+	buf.WriteString("type Config struct {\n")
+	for _, at := range accessorTiers {
+		fmt.Fprintf(&buf, "\t%s %s\n", at.field, at.typeName)
+	}
+	buf.WriteString("}\n\n")
+	buf.WriteString("// New returns accessors that read through g.\n")
+	buf.WriteString("func New(g Getter) Config {\n\treturn Config{\n")
+	for _, at := range accessorTiers {
+		fmt.Fprintf(&buf, "\t\t%s: %s{g},\n", at.field, at.typeName)
+	}
+	buf.WriteString("\t}\n}\n\n")
+
+	buf.Write(body.Bytes())
+	for _, name := range g.order {
+		buf.WriteString(g.structs[name])
+	}
+	buf.WriteString(decodeValueSource)
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: format generated accessors: %w", err)
+	}
+	return out, nil
+}
+
+// decodeValueSource is emitted verbatim into every accessor file.
+const decodeValueSource = `// decodeValue reads key through get and decodes it into out. A key no
+// source sets leaves out at its zero value.
+func decodeValue(get func(string) (any, error), key string, out any) error {
+	raw, err := get(key)
+	if err != nil || raw == nil {
+		return err
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("config key %q: %w", key, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("config key %q: %w", key, err)
+	}
+	return nil
+}
+`
+
+// accessorGen collects the struct types generated for object properties.
+type accessorGen struct {
+	seen    map[string]bool
+	structs map[string]string
+	order   []string
+	// refTypes maps a $ref to the struct generated for it, so shared
+	// definitions get one type and recursive ones terminate.
+	refTypes map[string]string
+	building map[string]bool
+}
+
+// goType returns the Go type for prop, generating a struct named name for
+// objects with declared properties. root resolves $refs.
+func (g *accessorGen) goType(root, prop map[string]any, name string) (string, error) {
+	ref, _ := prop["$ref"].(string)
+	if typ, ok := g.refTypes[ref]; ok && ref != "" {
+		if g.building[typ] {
+			return "*" + typ, nil
+		}
+		return typ, nil
+	}
+	prop = resolveRef(root, prop)
+	var types []string
+	nullable := false
+	for _, t := range schemaTypes(prop["type"]) {
+		if t == "null" {
+			nullable = true
+			continue
+		}
+		types = append(types, t)
+	}
+	if len(types) != 1 {
+		return "any", nil
+	}
+
+	var typ string
+	switch types[0] {
+	case "string":
+		typ = "string"
+	case "integer":
+		typ = "int"
+	case "number":
+		typ = "float64"
+	case "boolean":
+		typ = "bool"
+	case "array":
+		items, _ := prop["items"].(map[string]any)
+		elem, err := g.goType(root, items, name+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case "object":
+		props := schemaProperties(root, prop)
+		if len(props) == 0 {
+			additional, _ := prop["additionalProperties"].(map[string]any)
+			elem, err := g.goType(root, additional, name+"Value")
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + elem, nil
+		}
+		if ref != "" {
+			g.refTypes[ref] = name
+		}
+		if err := g.generateStruct(root, props, name); err != nil {
+			return "", err
+		}
+		typ = name
+	default:
+		return "any", nil
+	}
+	if nullable {
+		typ = "*" + typ
+	}
+	return typ, nil
+}
+
+// generateStruct emits a struct type for an object schema's properties.
+func (g *accessorGen) generateStruct(root, props map[string]any, name string) error {
+	if g.seen[name] {
+		return fmt.Errorf("codegen: generated type %s is already declared", name)
+	}
+	g.seen[name] = true
+	g.building[name] = true
+	defer delete(g.building, name)
+
+	fields := make([]string, 0, len(props))
+	for field := range props {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %s is generated from an object schema.\n", name)
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	names := map[string]string{}
+	for _, field := range fields {
+		prop, _ := props[field].(map[string]any)
+		fieldName := GoIdentifier(field)
+		if prev, dup := names[fieldName]; dup {
+			return fmt.Errorf("codegen: properties %q and %q of %s both map to field %s", prev, field, name, fieldName)
+		}
+		names[fieldName] = field
+		typ, err := g.goType(root, prop, name+fieldName)
+		if err != nil {
+			return err
+		}
+		if desc, ok := resolveRef(root, prop)["description"].(string); ok && desc != "" {
+			writeComment(&b, "\t", desc)
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:%q`\n", fieldName, typ, field)
+	}
+	b.WriteString("}\n\n")
+
+	g.structs[name] = b.String()
+	g.order = append(g.order, name)
+	return nil
+}
+
+// tierSchema returns def's schema for tier.
+func tierSchema(def *config.ConfigDefinition, tier config.ConfigTier) map[string]any {
+	switch tier {
+	case config.TierPublic:
+		return def.PublicSchema
+	case config.TierSecret:
+		return def.SecretSchema
+	case config.TierFeatureFlag:
+		return def.FeatureFlagSchema
+	}
+	return nil
+}
+
+// schemaProperties returns the resolved "properties" object of schema.
+func schemaProperties(root, schema map[string]any) map[string]any {
+	props, _ := resolveRef(root, schema)["properties"].(map[string]any)
+	return props
+}
+
+// resolveRef follows local "#/$defs/..." and "#/definitions/..." references
+// against root. Unresolvable references return the schema unchanged.
+func resolveRef(root, schema map[string]any) map[string]any {
+	for range 16 {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		var target map[string]any
+		for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+			if name, found := strings.CutPrefix(ref, prefix); found {
+				defs, _ := root[strings.Trim(prefix[1:], "/")].(map[string]any)
+				target, _ = defs[name].(map[string]any)
+			}
+		}
+		if target == nil {
+			return schema
+		}
+		schema = target
+	}
+	return schema
+}
+
+// schemaTypes normalizes a "type" keyword to a list.
+func schemaTypes(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// writeDescription writes summary, followed by the schema's description when
+// it has one, as a doc comment.
+func writeDescription(b *bytes.Buffer, summary string, prop map[string]any) {
+	writeComment(b, "", summary)
+	if desc, ok := prop["description"].(string); ok && desc != "" {
+		b.WriteString("//\n")
+		writeComment(b, "", desc)
+	}
+}
+
+func writeComment(b *bytes.Buffer, indent, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(b, "%s// %s\n", indent, strings.TrimRight(line, " \t"))
+	}
+}
+
+// DefinitionFromJSON parses schema JSON for GenerateAccessors and
+// GenerateKeys, so a go:generate step can work from a checked-in schema file
+// instead of Go structs. It accepts either a marshaled ConfigDefinition
+// ("public_schema", "secret_schema", "feature_flag_schema") or the combined
+// JSON Schema with "public", "secret" and "feature_flags" properties.
+func DefinitionFromJSON(data []byte) (*config.ConfigDefinition, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("codegen: parse schema JSON: %w", err)
+	}
+	tier := func(m map[string]any, key string) map[string]any {
+		schema, _ := m[key].(map[string]any)
+		return schema
+	}
+	if _, ok := raw["public_schema"]; ok || raw["secret_schema"] != nil || raw["feature_flag_schema"] != nil {
+		return config.DefineConfig(tier(raw, "public_schema"), tier(raw, "secret_schema"), tier(raw, "feature_flag_schema")), nil
+	}
+	props, ok := raw["properties"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("codegen: schema JSON has neither tier schemas nor tier properties")
+	}
+	return config.DefineConfig(tier(props, "public"), tier(props, "secret"), tier(props, "feature_flags")), nil
+}
@@ -0,0 +1,122 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+type accessorsDatabase struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type accessorsNode struct {
+	Name     string           `json:"name"`
+	Children []*accessorsNode `json:"children"`
+}
+
+type accessorsPublic struct {
+	APIURL     string            `json:"API_URL" jsonschema:"description=Base URL of the API"`
+	MaxRetries int               `json:"maxRetries"`
+	Ratio      float64           `json:"ratio"`
+	Tags       []string          `json:"tags"`
+	Limits     map[string]int    `json:"limits"`
+	Primary    accessorsDatabase `json:"primary"`
+	Replica    accessorsDatabase `json:"replica"`
+	Tree       accessorsNode     `json:"tree"`
+}
+
+type accessorsFlags struct {
+	EnableNewUI bool `json:"enableNewUi"`
+}
+
+func TestGenerateAccessors_TypedGetters(t *testing.T) {
+	def, err := config.DefineConfigTyped(&accessorsPublic{}, nil, &accessorsFlags{})
+	require.NoError(t, err)
+
+	src, err := GenerateAccessors(def, AccessorsOptions{})
+	require.NoError(t, err)
+	out := string(src)
+
+	assert.Contains(t, out, "package typedconfig")
+	assert.Contains(t, out, "func (c PublicConfig) APIURL() (string, error)")
+	assert.Contains(t, out, "// Base URL of the API")
+	assert.Contains(t, out, "func (c PublicConfig) MaxRetries() (int, error)")
+	assert.Contains(t, out, "func (c PublicConfig) Ratio() (float64, error)")
+	assert.Contains(t, out, "func (c PublicConfig) Tags() ([]string, error)")
+	assert.Contains(t, out, "func (c PublicConfig) Limits() (map[string]int, error)")
+	assert.Contains(t, out, "func (c PublicConfig) Primary() (PublicPrimary, error)")
+	// Both keys reference the same $defs entry, so they share one type.
+	assert.Contains(t, out, "func (c PublicConfig) Replica() (PublicPrimary, error)")
+	assert.Contains(t, out, "Children []*PublicTree `json:\"children\"`")
+	assert.Contains(t, out, "func (c FeatureFlagConfig) EnableNewUI() (bool, error)")
+	assert.Contains(t, out, `decodeValue(c.g.GetFeatureFlag, "enableNewUi", &v)`)
+}
+
+func TestGenerateAccessors_CompilesAndCatchesSchemaChanges(t *testing.T) {
+	src, err := GenerateAccessors(keysDefinition(), AccessorsOptions{Package: "keys"})
+	require.NoError(t, err)
+
+	assert.NoError(t, typeCheck(t, src, `func ok(g Getter) (string, int, bool) {
+	cfg := New(g)
+	url, _ := cfg.Public.APIURL()
+	retries, _ := cfg.Public.MaxRetries()
+	on, _ := cfg.FeatureFlags.EnableNewUI()
+	_, _ = cfg.Secret.DBPassword()
+	return url, retries, on
+}
+`))
+	// A retyped key is a compile error at the call site.
+	assert.Error(t, typeCheck(t, src, "func bad(g Getter) string { v, _ := New(g).Public.MaxRetries(); return v }\n"))
+	// So is a removed or misspelled one.
+	assert.Error(t, typeCheck(t, src, "func bad(g Getter) { New(g).Public.DBPassword() }\n"))
+}
+
+func TestGenerateAccessors_NullableAndUntyped(t *testing.T) {
+	def := config.DefineConfig(map[string]any{"type": "object", "properties": map[string]any{
+		"timeout":  map[string]any{"type": []any{"integer", "null"}},
+		"anyThing": map[string]any{},
+		"choice":   map[string]any{"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "integer"}}},
+	}}, nil, nil)
+
+	src, err := GenerateAccessors(def, AccessorsOptions{})
+	require.NoError(t, err)
+	out := string(src)
+	assert.Contains(t, out, "Timeout() (*int, error)")
+	assert.Contains(t, out, "AnyThing() (any, error)")
+	assert.Contains(t, out, "Choice() (any, error)")
+}
+
+func TestGenerateAccessors_TypeCollision(t *testing.T) {
+	def := config.DefineConfig(map[string]any{"type": "object", "properties": map[string]any{
+		"config": map[string]any{"type": "object", "properties": map[string]any{"a": map[string]any{"type": "string"}}},
+	}}, nil, nil)
+
+	_, err := GenerateAccessors(def, AccessorsOptions{})
+	assert.ErrorContains(t, err, "PublicConfig")
+}
+
+func TestDefinitionFromJSON(t *testing.T) {
+	fromDefinition, err := DefinitionFromJSON([]byte(`{
+		"public_schema": {"type": "object", "properties": {"API_URL": {"type": "string"}}},
+		"feature_flag_schema": {"type": "object", "properties": {"newUi": {"type": "boolean"}}}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"API_URL"}, fromDefinition.Keys(config.TierPublic))
+	assert.Equal(t, []string{"newUi"}, fromDefinition.Keys(config.TierFeatureFlag))
+
+	fromJSONSchema, err := DefinitionFromJSON([]byte(`{"type": "object", "properties": {
+		"secret": {"type": "object", "properties": {"dbPassword": {"type": "string"}}}
+	}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dbPassword"}, fromJSONSchema.Keys(config.TierSecret))
+
+	_, err = DefinitionFromJSON([]byte(`{"type": "string"}`))
+	assert.Error(t, err)
+	_, err = DefinitionFromJSON([]byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,144 @@
+// Package codegen generates Go source from a ConfigDefinition so services get
+// compile-time checked access to their config schema.
+//
+// The generators return gofmt'd source; write it wherever your go:generate
+// step wants it. Nothing here touches the network or the process environment.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+// KeysOptions configures GenerateKeys.
+type KeysOptions struct {
+	// Package is the package clause of the generated file. Defaults to "keys".
+	Package string
+}
+
+// commonInitialisms are upper-cased whole when they appear as a word, per
+// the Go naming conventions (APIURL, not ApiUrl).
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "AWS": true, "CPU": true, "CSS": true, "DB": true,
+	"DNS": true, "EOF": true, "GCP": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "JWT": true, "LHS": true, "OS": true, "QPS": true,
+	"RAM": true, "RHS": true, "RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true,
+	"SSL": true, "TCP": true, "TLS": true, "TTL": true, "UI": true, "UID": true, "URI": true,
+	"URL": true, "UTF8": true, "UUID": true, "VM": true, "XML": true,
+}
+
+// GoIdentifier converts a config key (camelCase, snake_case, or
+// UPPER_SNAKE_CASE) into an exported Go identifier, upper-casing common
+// initialisms: "API_URL" → "APIURL", "maxRetries" → "MaxRetries",
+// "db_host" → "DBHost". Keys starting with a digit get a "Key" prefix.
+func GoIdentifier(key string) string {
+	// CamelToUpperSnake drops existing separators rather than splitting on
+	// them, so split snake/kebab segments first and camel-split each one.
+	var words []string
+	for _, segment := range strings.FieldsFunc(key, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		words = append(words, strings.Split(config.CamelToUpperSnake(segment), "_")...)
+	}
+	var b strings.Builder
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		if commonInitialisms[word] {
+			b.WriteString(word)
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	id := b.String()
+	if id == "" || !unicode.IsLetter([]rune(id)[0]) {
+		id = "Key" + id
+	}
+	return id
+}
+
+// keyTiers pairs each tier with the generated constant type name.
+var keyTiers = []struct {
+	tier     config.ConfigTier
+	typeName string
+	getter   string
+	wrapper  string
+}{
+	{config.TierPublic, "PublicKey", "GetPublicConfig", "Public"},
+	{config.TierSecret, "SecretKey", "GetSecretConfig", "Secret"},
+	{config.TierFeatureFlag, "FeatureFlagKey", "GetFeatureFlag", "FeatureFlag"},
+}
+
+// GenerateKeys emits a Go file with one typed string constant per declared
+// key — PublicKey, SecretKey, and FeatureFlagKey — plus tier-specific getter
+// wrappers that only accept the matching constant type:
+//
+//	const APIURL PublicKey = "API_URL"
+//
+//	func Public(g Getter, key PublicKey) (any, error)
+//
+// Passing a SecretKey to Public is a compile error, so wrong-tier access is
+// caught at build time. Getter is satisfied by *config.ConfigManager and
+// *config.LocalConfigManager.
+//
+// Returns an error when two keys map to the same Go identifier.
+func GenerateKeys(def *config.ConfigDefinition, opts KeysOptions) ([]byte, error) {
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "keys"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by smooai-config codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "// Package %s holds typed config key constants generated from the config schema.\n", pkg)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+
+	buf.WriteString("// Getter is the read surface the tier wrappers call. *config.ConfigManager\n")
+	buf.WriteString("// and *config.LocalConfigManager satisfy it.\n")
+	buf.WriteString("type Getter interface {\n")
+	for _, kt := range keyTiers {
+		fmt.Fprintf(&buf, "\t%s(key string) (any, error)\n", kt.getter)
+	}
+	buf.WriteString("}\n\n")
+
+	// Seed with the generated declarations so a key can't shadow them.
+	seen := map[string]string{"Getter": "(generated)"}
+	for _, kt := range keyTiers {
+		seen[kt.typeName] = "(generated)"
+		seen[kt.wrapper] = "(generated)"
+	}
+	for _, kt := range keyTiers {
+		fmt.Fprintf(&buf, "// %s is a key declared in the %s tier.\n", kt.typeName, kt.tier)
+		fmt.Fprintf(&buf, "type %s string\n\n", kt.typeName)
+
+		keys := def.Keys(kt.tier)
+		if len(keys) > 0 {
+			buf.WriteString("const (\n")
+			for _, key := range keys {
+				id := GoIdentifier(key)
+				if prev, dup := seen[id]; dup {
+					return nil, fmt.Errorf("codegen: keys %q and %q both map to identifier %s", prev, key, id)
+				}
+				seen[id] = key
+				fmt.Fprintf(&buf, "\t%s %s = %q\n", id, kt.typeName, key)
+			}
+			buf.WriteString(")\n\n")
+		}
+
+		fmt.Fprintf(&buf, "// %s reads a %s-tier key through g.\n", kt.wrapper, kt.tier)
+		fmt.Fprintf(&buf, "func %s(g Getter, key %s) (any, error) {\n", kt.wrapper, kt.typeName)
+		fmt.Fprintf(&buf, "\treturn g.%s(string(key))\n}\n\n", kt.getter)
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: format generated keys: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,94 @@
+package codegen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+func keysDefinition() *config.ConfigDefinition {
+	return config.DefineConfig(
+		map[string]any{"type": "object", "properties": map[string]any{
+			"API_URL":    map[string]any{"type": "string"},
+			"maxRetries": map[string]any{"type": "integer"},
+		}},
+		map[string]any{"type": "object", "properties": map[string]any{
+			"db_password": map[string]any{"type": "string"},
+		}},
+		map[string]any{"type": "object", "properties": map[string]any{
+			"enableNewUi": map[string]any{"type": "boolean"},
+		}},
+	)
+}
+
+func TestGoIdentifier(t *testing.T) {
+	cases := map[string]string{
+		"API_URL":     "APIURL",
+		"maxRetries":  "MaxRetries",
+		"db_host":     "DBHost",
+		"enableNewUi": "EnableNewUI",
+		"jwt-secret":  "JWTSecret",
+		"2fa_enabled": "Key2faEnabled",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, GoIdentifier(in), in)
+	}
+}
+
+func TestGenerateKeys_EmitsTypedConstants(t *testing.T) {
+	src, err := GenerateKeys(keysDefinition(), KeysOptions{})
+	require.NoError(t, err)
+	out := string(src)
+
+	assert.Contains(t, out, "package keys")
+	assert.Contains(t, out, `APIURL     PublicKey = "API_URL"`)
+	assert.Contains(t, out, `MaxRetries PublicKey = "maxRetries"`)
+	assert.Contains(t, out, `DBPassword SecretKey = "db_password"`)
+	assert.Contains(t, out, `EnableNewUI FeatureFlagKey = "enableNewUi"`)
+	assert.Contains(t, out, "func Secret(g Getter, key SecretKey) (any, error)")
+}
+
+// typeCheck compiles the generated file plus extra source in the same
+// package and returns the type-checker error, if any.
+func typeCheck(t *testing.T, generated []byte, extra string) error {
+	t.Helper()
+	fset := token.NewFileSet()
+	gen, err := parser.ParseFile(fset, "keys.go", generated, 0)
+	require.NoError(t, err)
+	use, err := parser.ParseFile(fset, "use.go", "package keys\n\n"+extra, 0)
+	require.NoError(t, err)
+	_, err = (&types.Config{Importer: importer.Default()}).Check("keys", fset, []*ast.File{gen, use}, nil)
+	return err
+}
+
+func TestGenerateKeys_WrongTierIsCompileError(t *testing.T) {
+	src, err := GenerateKeys(keysDefinition(), KeysOptions{})
+	require.NoError(t, err)
+
+	assert.NoError(t, typeCheck(t, src, "func ok(g Getter) { Public(g, APIURL); Secret(g, DBPassword) }\n"))
+	assert.Error(t, typeCheck(t, src, "func bad(g Getter) { Public(g, DBPassword) }\n"))
+}
+
+func TestGenerateKeys_CustomPackage(t *testing.T) {
+	src, err := GenerateKeys(keysDefinition(), KeysOptions{Package: "cfgkeys"})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "package cfgkeys")
+}
+
+func TestGenerateKeys_IdentifierCollision(t *testing.T) {
+	def := config.DefineConfig(
+		map[string]any{"type": "object", "properties": map[string]any{"apiUrl": map[string]any{}}},
+		map[string]any{"type": "object", "properties": map[string]any{"API_URL": map[string]any{}}},
+		nil,
+	)
+	_, err := GenerateKeys(def, KeysOptions{})
+	assert.ErrorContains(t, err, "APIURL")
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ResponseDecoder wraps a response body compressed with one
+// Content-Encoding in a reader of the plain body.
+type ResponseDecoder func(r io.Reader) (io.ReadCloser, error)
+
+// WithResponseDecoder lets the client accept responses compressed with
+// encoding, decoded by dec. The standard library has no zstd, so plug one
+// in:
+//
+//	config.WithResponseDecoder("zstd", func(r io.Reader) (io.ReadCloser, error) {
+//	    d, err := zstd.NewReader(r)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return d.IOReadCloser(), nil
+//	})
+//
+// Once a decoder is added the client sends its own Accept-Encoding, listing
+// gzip and every added encoding, and decodes responses itself. Without one
+// it leaves compression to the transport, which for net/http means gzip.
+// Don't add decoders under js/wasm: fetch decodes responses already.
+func WithResponseDecoder(encoding string, dec ResponseDecoder) ConfigClientOption {
+	return func(c *ConfigClient) {
+		if c.decoders == nil {
+			c.decoders = map[string]ResponseDecoder{"gzip": gunzip}
+		}
+		c.decoders[strings.ToLower(encoding)] = dec
+	}
+}
+
+// WithCMResponseDecoder is WithResponseDecoder for the manager's clients.
+func WithCMResponseDecoder(encoding string, dec ResponseDecoder) ConfigManagerOption {
+	return func(m *ConfigManager) {
+		if m.decoders == nil {
+			m.decoders = make(map[string]ResponseDecoder)
+		}
+		m.decoders[strings.ToLower(encoding)] = dec
+	}
+}
+
+func gunzip(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+
+// acceptEncoding is the Accept-Encoding the client sends, or "" to leave it
+// to the transport.
+func (c *ConfigClient) acceptEncoding() string {
+	if c.decoders == nil {
+		return ""
+	}
+	return strings.Join(slices.Sorted(maps.Keys(c.decoders)), ", ")
+}
+
+// decodeResponse replaces a compressed resp.Body with its plain form.
+func (c *ConfigClient) decodeResponse(resp *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if c.decoders == nil || encoding == "" || encoding == "identity" {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	dec, ok := c.decoders[encoding]
+	if !ok {
+		resp.Body.Close()
+		return fmt.Errorf("config API response has unsupported Content-Encoding %q", encoding)
+	}
+	body, err := dec(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return fmt.Errorf("config API response: %s: %w", encoding, err)
+	}
+	resp.Body = &decodedBody{ReadCloser: body, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// decodedBody closes both the decoder and the compressed body under it.
+type decodedBody struct {
+	io.ReadCloser
+	raw io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if rerr := b.raw.Close(); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// decodeValuesResponse reads a GetAllValues response from r one value at a
+// time, so decoding a multi-megabyte config set never holds a second copy
+// of the whole document, as json.Decoder.Decode would.
+func decodeValuesResponse(r io.Reader) (valuesResponse, error) {
+	var result valuesResponse
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return result, err
+	}
+	rest := map[string]json.RawMessage{}
+	for dec.More() {
+		field, err := dec.Token()
+		if err != nil {
+			return result, err
+		}
+		name, _ := field.(string)
+		if name != "values" {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return result, err
+			}
+			rest[name] = raw
+			continue
+		}
+		if result.Values, err = decodeValuesObject(dec); err != nil {
+			return result, err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return result, err
+	}
+	if len(rest) == 0 {
+		return result, nil
+	}
+	// The other fields are small; decode them the usual way.
+	data, err := json.Marshal(rest)
+	if err != nil {
+		return result, err
+	}
+	return result, json.Unmarshal(data, &result)
+}
+
+// decodeValuesObject decodes the "values" object, or null, entry by entry.
+func decodeValuesObject(dec *json.Decoder) (map[string]any, error) {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
+		return nil, err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("values: want an object, got %v", tok)
+	}
+	values := make(map[string]any)
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		values[key.(string)] = v
+	}
+	return values, expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("want %v, got %v", want, tok)
+	}
+	return nil
+}
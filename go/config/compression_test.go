@@ -0,0 +1,116 @@
+package config
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// base64Decoder decodes the made-up "x-base64" Content-Encoding.
+func base64Decoder(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(base64.NewDecoder(base64.StdEncoding, r)), nil
+}
+
+// compressingServer answers every request with body, compressed with the
+// first encoding the client accepts that it knows.
+func compressingServer(t *testing.T, body any) (*httptest.Server, *[]string) {
+	t.Helper()
+	var accepted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		ae := r.Header.Get("Accept-Encoding")
+		accepted = append(accepted, ae)
+		switch {
+		case strings.Contains(ae, "x-base64"):
+			w.Header().Set("Content-Encoding", "x-base64")
+			enc := base64.NewEncoder(base64.StdEncoding, w)
+			json.NewEncoder(enc).Encode(body)
+			enc.Close()
+		case strings.Contains(ae, "gzip"):
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			json.NewEncoder(gz).Encode(body)
+			gz.Close()
+		default:
+			json.NewEncoder(w).Encode(body)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &accepted
+}
+
+func TestWithResponseDecoder(t *testing.T) {
+	values := map[string]any{"API_URL": "https://compressed", "LIMITS": map[string]any{"rps": 10.0}}
+	server, accepted := compressingServer(t, map[string]any{"values": values})
+
+	client := newUnitClient(t, server.URL, WithResponseDecoder("X-Base64", base64Decoder))
+	defer client.Close()
+	got, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	assert.Equal(t, values, got)
+	assert.Equal(t, []string{"gzip, x-base64"}, *accepted, "gzip is always accepted alongside")
+
+	t.Run("gzip is built in", func(t *testing.T) {
+		client := newUnitClient(t, server.URL, WithResponseDecoder("x-other", base64Decoder), WithTransport(&http.Transport{DisableCompression: true}))
+		defer client.Close()
+		got, err := client.GetAllValues("production")
+		require.NoError(t, err)
+		assert.Equal(t, values, got)
+	})
+
+	t.Run("an unknown encoding fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "br")
+			w.Write([]byte("???"))
+		}))
+		defer server.Close()
+		client := newUnitClient(t, server.URL, WithResponseDecoder("x-base64", base64Decoder))
+		defer client.Close()
+		_, err := client.GetAllValues("production")
+		assert.ErrorContains(t, err, `unsupported Content-Encoding "br"`)
+	})
+}
+
+func TestWithCMResponseDecoder(t *testing.T) {
+	server, accepted := compressingServer(t, map[string]any{"values": map[string]any{"API_URL": "https://compressed"}})
+	mgr := newStalenessManager(t, server.URL, WithCMResponseDecoder("x-base64", base64Decoder))
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://compressed", v)
+	assert.Equal(t, []string{"gzip, x-base64"}, *accepted)
+}
+
+func TestDecodeValuesResponse(t *testing.T) {
+	result, err := decodeValuesResponse(strings.NewReader(`{
+		"tiers": {"DB_PASSWORD": "secret"},
+		"values": {"API_URL": "https://a", "DB_PASSWORD": "x", "NESTED": {"a": [1, 2]}, "OFF": null},
+		"metadata": {"API_URL": {"maxAge": 5}},
+		"unknown": [1, 2, 3]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"API_URL": "https://a", "DB_PASSWORD": "x", "NESTED": map[string]any{"a": []any{1.0, 2.0}}, "OFF": nil}, result.Values)
+	assert.Equal(t, map[string]ConfigTier{"DB_PASSWORD": TierSecret}, result.Tiers)
+	require.NotNil(t, result.Metadata["API_URL"].MaxAge)
+	assert.Equal(t, 5.0, *result.Metadata["API_URL"].MaxAge)
+
+	result, err = decodeValuesResponse(strings.NewReader(`{"values": null}`))
+	require.NoError(t, err)
+	assert.Nil(t, result.Values)
+
+	for _, bad := range []string{``, `[]`, `{"values": [1]}`, `{"values": {"A": }}`, `{"values": {}`} {
+		_, err := decodeValuesResponse(strings.NewReader(bad))
+		assert.Error(t, err, bad)
+	}
+}
@@ -1,9 +1,17 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"sync"
+	"testing/fstest"
 	"time"
 )
 
@@ -33,6 +41,24 @@ func (e *UndefinedKeyError) Error() string {
 	)
 }
 
+// ErrKeyNotFound matches any *KeyNotFoundError via errors.Is.
+var ErrKeyNotFound = errors.New("config key not found")
+
+// KeyNotFoundError is returned from Get* under WithStrictMode when no config
+// source sets the key.
+type KeyNotFoundError struct {
+	Key         string
+	Environment string
+}
+
+// Error implements the error interface.
+func (e *KeyNotFoundError) Error() string {
+	return fmt.Sprintf("[Smooai Config] config key %q is not set in environment %q", e.Key, e.Environment)
+}
+
+// Is supports errors.Is(err, ErrKeyNotFound).
+func (e *KeyNotFoundError) Is(target error) bool { return target == ErrKeyNotFound }
+
 // ConfigManager is a unified config manager that merges three sources in this
 // precedence (highest to lowest):
 //
@@ -40,13 +66,33 @@ func (e *UndefinedKeyError) Error() string {
 //  2. Remote API — authoritative values from server
 //  3. File config — base defaults from JSON files
 //
-// Thread-safe via sync.Mutex. Lazy initialization loads all sources on first access.
+// Thread-safe via sync.Mutex. Lazy initialization loads all sources on first access;
+// because it runs under the mutex, concurrent first reads coalesce into a single
+// remote fetch rather than one per goroutine.
 // Per-key caches with configurable TTL for each tier (public, secret, feature_flag).
 type ConfigManager struct {
 	mu          sync.Mutex
 	initialized bool
 	config      map[string]any // single merged config
 
+	// fileConfig / ssmConfig / kvConfig / remoteConfig / envConfig are the
+	// layers config was merged from, kept so live updates (Subscribe,
+	// SetOverride) can re-merge a single key without a reload.
+	fileConfig   map[string]any
+	ssmConfig    map[string]any
+	kvConfig     map[string]any
+	remoteConfig map[string]any
+	envConfig    map[string]any
+
+	// overrides is the in-memory break-glass layer (SetOverride). It sits
+	// above env vars and survives Invalidate and refreshes.
+	overrides map[string]configOverride
+	// overrideSeq numbers SetOverride calls, so a ttl timer only clears
+	// the override it was set for.
+	overrideSeq uint64
+	// overrideTimersStopped is set by Close: ttls no longer expire.
+	overrideTimersStopped bool
+
 	// Per-tier caches
 	publicCache map[string]localCacheEntry
 	secretCache map[string]localCacheEntry
@@ -54,20 +100,130 @@ type ConfigManager struct {
 
 	// Local config params
 	schemaKeys  map[string]bool
-	envPrefix   string
+	envNames    envNames
 	schemaTypes map[string]string
 	cacheTTL    time.Duration
 	envOverride map[string]string // for testing
+	envFunc     EnvFunc
+	// envNestedSep (WithCMEnvNestedSeparator) splits env var names into
+	// paths of nested keys.
+	envNestedSep string
+
+	// dotEnvFiles (WithCMDotEnv) are read once, after every option has
+	// been applied, into dotEnv, which sits below the real env vars.
+	dotEnvFiles []string
+	dotEnv      map[string]string
+
+	// configFiles (WithCMConfigFS) is where the file layer is read from.
+	// fileWatch (WithCMFileWatch) starts watcher, which reloads the
+	// config when a file in the config directory changes.
+	configFiles configSource
+	fileWatch   bool
+	watcher     *fileWatcher
+
+	// components tracks the background work Close stops: the file watch,
+	// live subscriptions and change callbacks.
+	components components
+
+	// envDefinition (WithCMDefinition) is merged into schemaKeys and
+	// schemaTypes once every option has been applied.
+	envDefinition *ConfigDefinition
 
 	// Remote API params
-	apiKey      string
-	baseURL     string
-	orgID       string
-	environment string
+	apiKey         string
+	baseURL        string
+	orgID          string
+	environment    string
+	ring           string
+	watchTransport WatchTransport
 
 	// Deferred config values
 	deferred map[string]DeferredValue
 
+	// interpolate (WithCMInterpolation) expands ${...} placeholders once
+	// everything else is merged; interpErrs are the keys whose placeholders
+	// didn't resolve, and reads of them fail with the error.
+	interpolate bool
+	interpErrs  map[string]error
+
+	// secrets resolves smooai-secret:// values through remoteProviders
+	// (WithCMRemoteProvider) and the built-in provider for the detected cloud.
+	remoteProviders []RemoteProvider
+	secretRefTTL    time.Duration
+	secrets         *secretResolver
+	// secretRefErrs are the keys whose references didn't resolve; reads
+	// of them fail with the error (see secretRefErrLocked).
+	secretRefErrs map[string]error
+
+	// ssm is the SSM Parameter Store layer (WithCMSSMParameters).
+	ssm *ssmSource
+
+	// kv is the KV store layer (WithCMKVStore); kvWatch (WithCMKVWatch)
+	// reloads the config when a key under its prefix changes.
+	kv      *kvSource
+	kvWatch bool
+
+	// sources are the custom layers (WithSource), merged among the
+	// built-in ones by priority.
+	sources []*sourceLayer
+
+	// logger (WithCMLogger) receives warnings and merge debug logs; nil
+	// means slog.Default.
+	logger *slog.Logger
+
+	// nullPolicy decides how explicit nulls merge across files and layers
+	// (WithCMNullPolicy). Empty means NullSet.
+	nullPolicy NullPolicy
+
+	// builtins decides whether ENV, REGION and the other built-in keys
+	// overwrite, fall back to, or are absent from user values
+	// (WithCMBuiltins). The zero value overwrites.
+	builtins BuiltinPolicy
+
+	// changeListeners are notified of keys whose effective value changed
+	// on a reload, pushed change or override; previousConfig holds the
+	// merged config across a reset so the reload can be diffed.
+	changeListeners []changeListener
+	previousConfig  map[string]any
+
+	// watchers are the Watch registrations, notified with the change
+	// listeners but removable.
+	watchers []*watcher
+
+	// restartKeys (WithRestartRequiredKeys) are only read at startup;
+	// restartPending holds those that changed since, and restartHandler
+	// (WithRestartHandler) is told about each change.
+	restartKeys    map[string]bool
+	restartPending map[string]bool
+	restartHandler func(keys []string)
+
+	// requirements (WithCMRequirements) are what WaitReady checks.
+	requirements []requiredKey
+
+	// metricKeys are exported by WriteMetrics (WithCMMetricKeys).
+	metricKeys []string
+
+	// accessStats counts reads per key (WithCMAccessStats); nil when off.
+	accessStats *accessStats
+
+	// Disk cache of the last good remote fetch (WithDiskCache), optionally
+	// encrypted with diskCacheKey.
+	diskCachePath string
+	diskCacheKey  []byte
+
+	// decryptionKey opens encrypted secret-tier values (WithDecryptionKey).
+	decryptionKey DecryptionKey
+
+	// decoders (WithCMResponseDecoder) are added to every client.
+	decoders map[string]ResponseDecoder
+
+	// secretPolicy approves every secret read (WithSecretAccessPolicy).
+	secretPolicy SecretAccessPolicy
+
+	// protectSecrets caches and returns string secrets as SecretStrings
+	// (WithSecretMemoryProtection).
+	protectSecrets bool
+
 	// schemaPath surfaces in UndefinedKeyError messages so callers know where
 	// to declare the missing key. Defaults to ".smooai-config/config.ts" when
 	// the message is rendered.
@@ -79,12 +235,73 @@ type ConfigManager struct {
 	// an env-var filter, not a strict allow-list.
 	strictSchemaKeys bool
 
+	// strictMode, when true, makes Get* return *KeyNotFoundError for keys
+	// no source sets, instead of (nil, nil).
+	strictMode bool
+
+	// keyTiers (WithCMKeyTiers) and remoteTiers (sent by the API with the
+	// values) map keys to their schema tier; Get* refuses to read a key
+	// through the wrong tier. The schema wins when both are present.
+	keyTiers    map[string]ConfigTier
+	remoteTiers map[string]ConfigTier
+
+	// archivedKeys are the keys the API marked archived on the last
+	// fetch; their remote values are dropped. archivedWarned records the
+	// ones a read has already warned about.
+	archivedKeys   map[string]time.Time
+	archivedWarned map[string]bool
+
+	// definition (WithCMDefinition) is validated against config on every
+	// load and live update; schemaErr holds the violations, if any.
+	// definitionTiers are its key tiers.
+	definition      *ConfigDefinition
+	definitionTiers map[string]ConfigTier
+	schemaErr       error
+
+	// remoteSchema (WithCMRemoteSchema) fetches the published schema on
+	// load; remoteDefinition holds it once fetched, and
+	// remoteDefinitionTiers its key tiers. schemaBundle is true while
+	// remoteDefinition is a bundle (WithCMSchemaBundle) a fetch may replace.
+	remoteSchema          bool
+	remoteDefinition      *ConfigDefinition
+	remoteDefinitionTiers map[string]ConfigTier
+	schemaBundle          bool
+
+	// Staleness budget (WithMaxStaleness). lastRemote is the last good
+	// remote fetch, served when a later fetch fails; staleSince marks when
+	// the manager started serving non-fresh remote data (zero = fresh).
+	maxStaleness     time.Duration
+	failStaleSecrets bool
+	lastRemote       map[string]any
+	staleSince       time.Time
+	lastRemoteErr    error
+	lastSync         time.Time
+	// loadErrs are the layers the last load failed to read, for Init.
+	loadErrs map[string]error
+	// lazyRemote (WithLazyRemoteKeys) fetches remote keys as they are
+	// read; lazyKeys are the keys read so far.
+	lazyRemote bool
+	lazyKeys   map[string]bool
+	// keyPrefixes and namespace (WithKeyPrefixFilter, WithNamespace) scope
+	// the remote keys kept.
+	keyPrefixes []string
+	namespace   string
+	// httpClient (WithCMHTTPClient) is shared by the client of every load.
+	httpClient *http.Client
+	// breaker (WithCMCircuitBreaker) is shared by the client of every load.
+	breaker *breaker
+	// limiter (WithCMRateLimiter) caps the requests of every load's client.
+	limiter *RateLimiter
+
 	// bakedConfig is an optional pre-decrypted map of remote values,
 	// seeded by NewRuntimeConfigManager from an AES-256-GCM blob. When
 	// set, initialize() uses it in place of the live remote fetch —
 	// env-var overrides still apply on top, file config still layers
 	// underneath, and deferred resolution still runs.
 	bakedConfig map[string]any
+
+	// snapshot (LoadSnapshot) replaces every source when set.
+	snapshot *Snapshot
 }
 
 // ConfigManagerOption is a functional option for ConfigManager.
@@ -93,14 +310,34 @@ type ConfigManagerOption func(*ConfigManager)
 // NewConfigManager creates a new unified config manager with functional options.
 func NewConfigManager(opts ...ConfigManagerOption) *ConfigManager {
 	m := &ConfigManager{
-		publicCache: make(map[string]localCacheEntry),
-		secretCache: make(map[string]localCacheEntry),
-		ffCache:     make(map[string]localCacheEntry),
-		cacheTTL:    defaultLocalCacheTTL,
+		publicCache:  make(map[string]localCacheEntry),
+		secretCache:  make(map[string]localCacheEntry),
+		ffCache:      make(map[string]localCacheEntry),
+		cacheTTL:     defaultLocalCacheTTL,
+		envNestedSep: defaultEnvNestedSeparator,
 	}
 	for _, opt := range opts {
 		opt(m)
 	}
+	if m.envDefinition != nil {
+		m.schemaKeys, m.schemaTypes = mergeEnvSchema(m.envDefinition, m.schemaKeys, m.schemaTypes)
+	}
+	if len(m.dotEnvFiles) > 0 {
+		m.dotEnv = loadDotEnvFiles(m.dotEnvFiles, m.log())
+	}
+	providers := append(slices.Clone(m.remoteProviders), builtinRemoteProvider(GetCloudRegionFromEnv(m.envMap()).Provider, m.lookupEnvVal))
+	m.secrets = newSecretResolver(providers, m.secretRefTTL, m.log())
+	if m.fileWatch {
+		m.watcher = watchConfigDir(m.configFiles, m.envMap(), m.log(), m.reloadConfigFiles)
+		if m.watcher != nil {
+			_, _ = m.components.register("file watch", m.watcher.Stop)
+		}
+	}
+	if m.kv != nil && m.kvWatch {
+		w := startKVWatch(m.kv.store, m.kv.prefix, m.log(), m.reloadKV)
+		_, _ = m.components.register("kv watch", w.Stop)
+	}
+	m.startSourceWatches()
 	return m
 }
 
@@ -124,29 +361,86 @@ func WithConfigEnvironment(env string) ConfigManagerOption {
 	return func(m *ConfigManager) { m.environment = env }
 }
 
+// WithCMHTTPClient sends the manager's API and token requests with hc.
+// Managers given the same client share its connection pool, which a
+// process serving many orgs wants (see ConfigHub).
+func WithCMHTTPClient(hc *http.Client) ConfigManagerOption {
+	return func(m *ConfigManager) { m.httpClient = hc }
+}
+
+// WithCMRing sets the deployment ring for remote config fetching. Defaults
+// to the ring detected from SMOOAI_CONFIG_RING / RING / DEPLOYMENT_STAGE.
+func WithCMRing(ring string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.ring = ring }
+}
+
+// WithCMWatchTransport sets how Subscribe receives changes. Defaults to
+// SMOOAI_CONFIG_WATCH_TRANSPORT; see WithWatchTransport.
+func WithCMWatchTransport(transport WatchTransport) ConfigManagerOption {
+	return func(m *ConfigManager) { m.watchTransport = transport }
+}
+
 // WithCMSchemaKeys sets schema keys for env config filtering.
 func WithCMSchemaKeys(keys map[string]bool) ConfigManagerOption {
 	return func(m *ConfigManager) { m.schemaKeys = keys }
 }
 
-// WithCMEnvPrefix sets env var prefix for stripping.
-func WithCMEnvPrefix(prefix string) ConfigManagerOption {
-	return func(m *ConfigManager) { m.envPrefix = prefix }
+// WithCMEnvPrefix sets the env var prefixes to strip, so NEXT_PUBLIC_API_URL
+// sets API_URL. When several names set one key, the first prefix listed
+// wins and the bare name comes last.
+func WithCMEnvPrefix(prefixes ...string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.envNames.prefixes = newEnvNames(prefixes...).prefixes }
 }
 
-// WithCMSchemaTypes sets schema type hints for coercion.
+// WithCMSchemaTypes sets schema type hints for coercion: "string",
+// "number", "boolean", "json"/"object", or the list types "string[]",
+// "number[]" and "boolean[]".
 func WithCMSchemaTypes(types map[string]string) ConfigManagerOption {
 	return func(m *ConfigManager) { m.schemaTypes = types }
 }
 
+// WithCMDefinition configures the manager from the definition compiled
+// into the service:
+//
+//   - env vars: the schema keys and type hints come from def (see
+//     ConfigDefinition.EnvSchema), so env vars are picked up and coerced
+//     without hand-maintained maps. Keys and types passed to
+//     WithCMSchemaKeys / WithCMSchemaTypes are kept, and their types win.
+//   - tiers: each key's tier comes from def (ConfigDefinition.KeyTiers), so
+//     Get* refuses keys read through the wrong tier. WithCMKeyTiers entries
+//     win.
+//   - validation: the merged config is validated against def on every load
+//     and live update (see SchemaViolationError).
+//
+// A definition fetched with WithCMRemoteSchema or read with
+// WithCMSchemaBundle is used the same way, but only where WithCMDefinition
+// isn't set.
+func WithCMDefinition(def *ConfigDefinition) ConfigManagerOption {
+	return func(m *ConfigManager) {
+		m.envDefinition = def
+		m.definition = def
+		m.definitionTiers = definitionTiers(def)
+	}
+}
+
 // WithCMCacheTTL sets the cache TTL.
 func WithCMCacheTTL(ttl time.Duration) ConfigManagerOption {
 	return func(m *ConfigManager) { m.cacheTTL = ttl }
 }
 
-// WithCMEnvOverride overrides environment variables (for testing).
+// WithCMEnvOverride overrides environment variables (for testing). The map
+// is copied, so changing it afterwards has no effect on the manager.
 func WithCMEnvOverride(env map[string]string) ConfigManagerOption {
-	return func(m *ConfigManager) { m.envOverride = env }
+	return func(m *ConfigManager) { m.envOverride = cloneEnv(env) }
+}
+
+// WithCMEnvFunc sources environment variables from fn instead of the process
+// environment, taking precedence over WithCMEnvOverride. fn is called on
+// every load, so it can serve values that change at runtime; it must be safe
+// for concurrent use. It is asked for the variables the SDK reads and for
+// each schema key (see WithCMSchemaKeys), with and without the env prefix.
+func WithCMEnvFunc(fn EnvFunc) ConfigManagerOption {
+	return func(m *ConfigManager) { m.envFunc = fn }
 }
 
 // WithSchemaPath sets the schema file path referenced in UndefinedKeyError.
@@ -162,6 +456,34 @@ func WithStrictSchemaKeys(strict bool) ConfigManagerOption {
 	return func(m *ConfigManager) { m.strictSchemaKeys = strict }
 }
 
+// WithStrictMode makes Get* return a *KeyNotFoundError (matching
+// ErrKeyNotFound) for a key that no config source sets, rather than
+// (nil, nil). A key explicitly set to null still reads as nil. Use Has to
+// check for a key without an error.
+//
+// Strict mode also refuses config file values that LintConfigFiles flags:
+// literal values of secret keys, and smooai-secret:// references on public
+// and feature flag keys. They are dropped with a warning, so the key reads
+// from the remote and env layers only.
+func WithStrictMode() ConfigManagerOption {
+	return func(m *ConfigManager) { m.strictMode = true }
+}
+
+// WithCMNullPolicy sets how an explicit JSON null in a higher-precedence
+// source (a later config file, the remote API, a JSON env var) merges over a
+// lower one: NullSet (default, matches the TypeScript SDK), NullDelete, or
+// NullKeepLower.
+func WithCMNullPolicy(policy NullPolicy) ConfigManagerOption {
+	return func(m *ConfigManager) { m.nullPolicy = policy }
+}
+
+// WithCMBuiltins sets how the built-in keys (ENV, IS_LOCAL, REGION,
+// CLOUD_PROVIDER, RING, RUNTIME, AZ) interact with user keys of the same name.
+// By default they overwrite them; see BuiltinPolicy.
+func WithCMBuiltins(policy BuiltinPolicy) ConfigManagerOption {
+	return func(m *ConfigManager) { m.builtins = policy }
+}
+
 // WithDeferred registers a deferred (computed) config value.
 // The function receives the full merged config map (pre-resolution snapshot)
 // and returns the computed value.
@@ -174,39 +496,144 @@ func WithDeferred(key string, fn DeferredValue) ConfigManagerOption {
 	}
 }
 
-// getEnvVal looks up a key from the env override map, falling back to os.Getenv.
+// getEnvVal looks up a key from the env override map, falling back to
+// os.Getenv, and then to the .env files.
 func (m *ConfigManager) getEnvVal(key string) string {
+	v, _ := m.lookupEnvVal(key)
+	return v
+}
+
+// lookupEnvVal is getEnvVal, also reporting whether the key is set.
+func (m *ConfigManager) lookupEnvVal(key string) (string, bool) {
+	var (
+		v  string
+		ok bool
+	)
+	switch {
+	case m.envFunc != nil:
+		v, ok = m.envFunc(key)
+	case m.envOverride != nil:
+		v, ok = m.envOverride[key]
+	default:
+		v, ok = os.LookupEnv(key)
+	}
+	if !ok {
+		v, ok = m.dotEnv[key]
+	}
+	return v, ok
+}
+
+// envMap returns the env vars the loaders read: a snapshot of the env func,
+// the env override map, or the process environment, over the .env files.
+func (m *ConfigManager) envMap() map[string]string {
+	if m.envFunc != nil {
+		return overlayDotEnv(m.dotEnv, envFromFunc(m.envFunc, m.schemaKeys, m.envNames))
+	}
 	if m.envOverride != nil {
-		return m.envOverride[key]
+		return overlayDotEnv(m.dotEnv, m.envOverride)
+	}
+	return overlayDotEnv(m.dotEnv, osEnvMap())
+}
+
+// WithCMDotEnv loads .env files into the env var layer, below real env vars:
+// a variable set in the environment wins over the same one in a file, and
+// later files win over earlier ones, so pass the shared file first
+// (".env", ".env.local"). File values get the same prefix stripping and
+// schema coercion as env vars. Missing files are skipped. The files are read
+// once, when the manager is created.
+func WithCMDotEnv(paths ...string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.dotEnvFiles = append(m.dotEnvFiles, paths...) }
+}
+
+// WithCMConfigFS reads the config files (default.json, {env}.json, ...)
+// from the root directory of fsys instead of a .smooai-config directory on
+// disk, typically an embed.FS so distroless images and Lambdas carry their
+// config in the binary:
+//
+//	//go:embed .smooai-config/*.json
+//	var configFiles embed.FS
+//
+//	mgr := config.NewConfigManager(config.WithCMConfigFS(configFiles, ".smooai-config"))
+//
+// SMOOAI_ENV_CONFIG_DIR and the directory search are not consulted.
+func WithCMConfigFS(fsys fs.FS, root string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.configFiles = configSource{fsys: fsys, root: root} }
+}
+
+// WithCMConfigFiles reads the config files from memory, keyed by file name
+// ("default.json", "production.json", ...), instead of from disk. It suits
+// hosts with no filesystem, such as js/wasm in a browser, and tests:
+//
+//	mgr := config.NewConfigManager(config.WithCMConfigFiles(map[string]string{
+//		"default.json":    `{"API_URL": "https://api.example.com"}`,
+//		"production.json": `{"MAX_RETRIES": 5}`,
+//	}))
+//
+// The files are copied, and merge exactly as they would from a directory.
+func WithCMConfigFiles(files map[string]string) ConfigManagerOption {
+	fsys := make(fstest.MapFS, len(files))
+	for name, data := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(data)}
 	}
-	return os.Getenv(key)
+	return WithCMConfigFS(fsys, ".")
 }
 
 func (m *ConfigManager) initialize() error {
 	if m.initialized {
-		return nil
+		return m.schemaErr
+	}
+	if m.snapshot != nil {
+		return m.initializeFromSnapshotLocked()
 	}
 
 	// Resolve the env map for file/env config functions
-	var env map[string]string
-	if m.envOverride != nil {
-		env = m.envOverride
-	} else {
-		env = osEnvMap()
-	}
+	env := m.envMap()
+	m.loadErrs = nil
 
 	// 1. Load file config (graceful — file config is optional)
-	fileConfig, err := findAndProcessFileConfigWithEnv(env)
+	fileConfig, err := findAndProcessFileConfigWithPolicy(env, m.nullPolicy, m.builtins, m.configFiles)
 	if err != nil {
+		if _, _, _, dirErr := m.configFiles.resolve(env); dirErr == nil {
+			// A config directory that exists but doesn't load is a
+			// failure; having none is not.
+			m.recordLoadErrLocked("file", err)
+		}
 		fileConfig = make(map[string]any)
+		applyBuiltins(fileConfig, builtinValuesFromEnv(env), m.builtins, true)
+	}
+
+	var (
+		client    *ConfigClient
+		configEnv string
+		remoteOK  bool
+	)
+	if m.bakedConfig == nil {
+		if client, configEnv, remoteOK = m.newRemoteClient(env); remoteOK {
+			defer client.Close()
+			m.loadRemoteSchemaLocked(client, configEnv)
+		}
 	}
 
 	// 2. Load env config
-	schemaKeys := m.schemaKeys
+	schemaKeys, schemaTypes := m.envSchemaLocked()
 	if schemaKeys == nil {
 		schemaKeys = make(map[string]bool)
 	}
-	envConfig := findAndProcessEnvConfigWithEnv(schemaKeys, m.envPrefix, m.schemaTypes, env)
+	if m.envFunc != nil && m.remoteDefinition != nil {
+		// The func was only asked for the configured keys; ask again now
+		// the remote schema has added its own.
+		env = overlayDotEnv(m.dotEnv, envFromFunc(m.envFunc, schemaKeys, m.envNames))
+	}
+	envConfig := findAndProcessEnvConfigWithBuiltins(schemaKeys, m.envNames, schemaTypes, env, m.builtins)
+
+	// 2b. Load the SSM Parameter Store layer, if configured.
+	ssmConfig := m.loadSSMLocked(schemaTypes)
+
+	// 2c. Load the KV store layer, if configured.
+	kvConfig := m.loadKVLocked(schemaTypes)
+
+	// 2d. Load the WithSource layers, if any.
+	m.loadSourcesLocked()
 
 	// 3. Resolve the "remote" tier — either from a baked blob (when
 	// NewRuntimeConfigManager pre-seeded m.bakedConfig) or via a live
@@ -215,78 +642,208 @@ func (m *ConfigManager) initialize() error {
 
 	if m.bakedConfig != nil {
 		remoteConfig = m.bakedConfig
-	} else {
-		apiKey := m.apiKey
-		baseURL := m.baseURL
-		orgID := m.orgID
-
-		// Check env vars as fallback for API credentials
-		if apiKey == "" {
-			apiKey = m.getEnvVal("SMOOAI_CONFIG_API_KEY")
-		}
-		if baseURL == "" {
-			baseURL = m.getEnvVal("SMOOAI_CONFIG_API_URL")
-		}
-		if orgID == "" {
-			orgID = m.getEnvVal("SMOOAI_CONFIG_ORG_ID")
-		}
-
-		// SMOODEV-975: ConfigClient now requires (clientID, clientSecret).
-		// The ConfigManager-facing API still calls this "apiKey" for
-		// backwards-compat; treat it as the OAuth client secret and pull
-		// the client ID from env (or fall back to apiKey itself so single-
-		// secret configs continue to work in dev).
-		clientID := m.getEnvVal("SMOOAI_CONFIG_CLIENT_ID")
-		if clientID == "" {
-			clientID = apiKey
-		}
-
-		if apiKey != "" && baseURL != "" && orgID != "" {
-			// Resolve environment
-			configEnv := m.environment
-			if configEnv == "" {
-				configEnv = m.getEnvVal("SMOOAI_CONFIG_ENV")
+	} else if remoteOK {
+		values, err := m.fetchRemoteLocked(client, configEnv)
+		if err != nil {
+			m.log().Warn("failed to fetch remote config", "error", err)
+			m.recordLoadErrLocked("remote", err)
+			// Serve the last good remote values (if any) and start the
+			// staleness clock; see WithMaxStaleness.
+			if m.staleSince.IsZero() {
+				m.staleSince = time.Now()
 			}
-			if configEnv == "" {
-				configEnv = "development"
+			m.lastRemoteErr = err
+			if m.lastRemote == nil {
+				// Nothing fetched in this process yet: fall back to the
+				// disk cache, stale since it was written.
+				if cached := m.loadDiskCacheLocked(configEnv); cached != nil {
+					m.lastRemote = cached.Values
+					m.remoteTiers = cached.Tiers
+					if cached.FetchedAt.Before(m.staleSince) {
+						m.staleSince = cached.FetchedAt
+					}
+				}
 			}
-
-			// SMOODEV-975: Honor the OAuth issuer URL from envOverride so
-			// tests can point the TokenProvider at their mock server.
-			clientOpts := []ConfigClientOption{}
-			if authURL := m.getEnvVal("SMOOAI_CONFIG_AUTH_URL"); authURL != "" {
-				clientOpts = append(clientOpts, WithAuthURL(authURL))
-			} else if authURL := m.getEnvVal("SMOOAI_AUTH_URL"); authURL != "" {
-				clientOpts = append(clientOpts, WithAuthURL(authURL))
-			}
-			client := NewConfigClient(baseURL, clientID, apiKey, orgID, clientOpts...)
-			defer client.Close()
-
-			values, err := client.GetAllValues(configEnv)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "[Smooai Config] Warning: Failed to fetch remote config: %v\n", err)
-			} else {
-				remoteConfig = values
+			if m.lastRemote != nil {
+				remoteConfig = m.lastRemote
 			}
+		} else {
+			m.archivedKeys = client.ArchivedKeys(configEnv)
+			values = withoutKeys(values, m.archivedKeys)
+			remoteConfig = values
+			m.lastRemote = values
+			m.remoteTiers = client.KeyTiers(configEnv)
+			m.staleSince = time.Time{}
+			m.lastRemoteErr = nil
+			m.lastSync = time.Now()
+			m.saveDiskCacheLocked(configEnv, values, m.remoteTiers)
 		}
 	}
 
-	// 4. Merge: file < remote < env
-	merged := MergeReplaceArrays(make(map[string]any), fileConfig).(map[string]any)
-	merged = MergeReplaceArrays(merged, remoteConfig).(map[string]any)
-	merged = MergeReplaceArrays(merged, envConfig).(map[string]any)
+	// Tiers are known now the remote metadata is in; refuse file values
+	// that expose a secret.
+	if m.strictMode {
+		m.dropMisplacedLocked(fileConfig)
+	}
+
+	// 4. Merge: file < SSM < KV < remote < env, with WithSource layers
+	// placed among them by priority.
+	layers := m.layersLocked(fileConfig, ssmConfig, kvConfig, remoteConfig, envConfig)
+	m.applyNestedEnvLocked(env, envConfig, layers)
+	merged := make(map[string]any)
+	for _, layer := range layers {
+		merged = MergeWithNullPolicy(merged, layer.values, m.nullPolicy).(map[string]any)
+	}
+	m.applyOverridesLocked(merged)
+
+	// 5. Replace smooai-secret:// references with the secrets they name.
+	// A reference that can't be resolved fails reads of its own key only.
+	refErrs := m.secrets.resolveAll(merged)
+	if len(refErrs) > 0 {
+		errs := make([]error, 0, len(refErrs))
+		for _, key := range slices.Sorted(maps.Keys(refErrs)) {
+			errs = append(errs, refErrs[key])
+		}
+		err := errors.Join(errs...)
+		m.log().Warn("failed to resolve secret references", "error", err)
+		m.recordLoadErrLocked("secrets", err)
+	}
 
-	// 5. Resolve deferred/computed values
+	// 6. Resolve deferred/computed values. Overrides are re-applied so they
+	// also win over computed keys.
 	if len(m.deferred) > 0 {
 		ResolveDeferred(merged, m.deferred)
+		m.applyOverridesLocked(merged)
 	}
 
+	// 7. Expand ${...} placeholders last, so they see every layer. A
+	// placeholder that can't be resolved fails reads of its own key only.
+	var interpErrs map[string]error
+	if m.interpolate {
+		resolved, failed := interpolate(merged, m.lookupEnvVal)
+		maps.Copy(merged, resolved)
+		for key := range failed {
+			delete(merged, key)
+		}
+		if len(failed) > 0 {
+			err := joinInterpolationErrors(failed)
+			m.log().Warn("failed to interpolate config values", "error", err)
+			m.recordLoadErrLocked("interpolation", err)
+			interpErrs = failed
+		}
+	}
+
+	if m.previousConfig != nil {
+		m.notifyChangeLocked(changedKeys(m.previousConfig, merged), merged)
+		m.previousConfig = nil
+	}
 	m.config = merged
+	m.fileConfig = fileConfig
+	m.ssmConfig = ssmConfig
+	m.kvConfig = kvConfig
+	m.remoteConfig = make(map[string]any, len(remoteConfig))
+	for k, v := range remoteConfig {
+		m.remoteConfig[k] = v
+	}
+	m.envConfig = envConfig
+	m.secretRefErrs = refErrs
+	m.interpErrs = interpErrs
 	m.initialized = true
-	return nil
+	m.logMergeLocked(merged, layers)
+	m.validateLocked()
+	return m.schemaErr
 }
 
-func (m *ConfigManager) getFromTier(key string, cache map[string]localCacheEntry) (any, error) {
+// configEnvironment resolves the environment name: WithConfigEnvironment,
+// then GetConfigEnvironmentFromEnv.
+func (m *ConfigManager) configEnvironment() string {
+	if m.environment != "" {
+		return m.environment
+	}
+	return GetConfigEnvironmentFromEnv(m.envMap())
+}
+
+// newRemoteClient builds a ConfigClient from the manager's remote API params,
+// falling back to SMOOAI_CONFIG_* env vars (resolved through env). ok is false
+// when the credentials are incomplete and the remote tier should be skipped.
+func (m *ConfigManager) newRemoteClient(env map[string]string) (client *ConfigClient, configEnv string, ok bool) {
+	apiKey := m.apiKey
+	baseURL := m.baseURL
+	orgID := m.orgID
+
+	// Check env vars as fallback for API credentials
+	if apiKey == "" {
+		apiKey = m.getEnvVal("SMOOAI_CONFIG_API_KEY")
+	}
+	if baseURL == "" {
+		baseURL = m.getEnvVal("SMOOAI_CONFIG_API_URL")
+	}
+	if orgID == "" {
+		orgID = m.getEnvVal("SMOOAI_CONFIG_ORG_ID")
+	}
+
+	// SMOODEV-975: ConfigClient now requires (clientID, clientSecret).
+	// The ConfigManager-facing API still calls this "apiKey" for
+	// backwards-compat; treat it as the OAuth client secret and pull
+	// the client ID from env (or fall back to apiKey itself so single-
+	// secret configs continue to work in dev).
+	clientID := m.getEnvVal("SMOOAI_CONFIG_CLIENT_ID")
+	if clientID == "" {
+		clientID = apiKey
+	}
+
+	if apiKey == "" || baseURL == "" || orgID == "" {
+		return nil, "", false
+	}
+
+	configEnv = m.configEnvironment()
+
+	// SMOODEV-975: Honor the OAuth issuer URL from envOverride so
+	// tests can point the TokenProvider at their mock server.
+	clientOpts := []ConfigClientOption{}
+	if authURL := m.getEnvVal("SMOOAI_CONFIG_AUTH_URL"); authURL != "" {
+		clientOpts = append(clientOpts, WithAuthURL(authURL))
+	} else if authURL := m.getEnvVal("SMOOAI_AUTH_URL"); authURL != "" {
+		clientOpts = append(clientOpts, WithAuthURL(authURL))
+	}
+	ring := m.ring
+	if ring == "" {
+		ring = GetDeploymentRingFromEnv(env)
+	}
+	clientOpts = append(clientOpts, WithRing(ring))
+	transport := m.watchTransport
+	if transport == WatchAuto {
+		transport = WatchTransport(strings.ToLower(m.getEnvVal("SMOOAI_CONFIG_WATCH_TRANSPORT")))
+	}
+	clientOpts = append(clientOpts, WithWatchTransport(transport))
+	if m.logger != nil {
+		clientOpts = append(clientOpts, WithLogger(m.logger))
+	}
+	if addr := m.getEnvVal("SMOOAI_CONFIG_AGENT_ADDR"); addr != "" {
+		clientOpts = append(clientOpts, WithAgentAddr(addr))
+	}
+	if m.breaker != nil {
+		clientOpts = append(clientOpts, withBreaker(m.breaker))
+	}
+	if m.limiter != nil {
+		clientOpts = append(clientOpts, WithRateLimiter(m.limiter))
+	}
+	if m.httpClient != nil {
+		clientOpts = append(clientOpts, WithHTTPClient(m.httpClient))
+	}
+	if len(m.keyPrefixes) > 0 {
+		clientOpts = append(clientOpts, WithClientKeyPrefix(m.keyPrefixes...))
+	}
+	if m.namespace != "" {
+		clientOpts = append(clientOpts, WithClientNamespace(m.namespace))
+	}
+	for encoding, dec := range m.decoders {
+		clientOpts = append(clientOpts, WithResponseDecoder(encoding, dec))
+	}
+	return NewConfigClient(baseURL, clientID, apiKey, orgID, clientOpts...), configEnv, true
+}
+
+func (m *ConfigManager) getFromTier(key string, tier ConfigTier, cache map[string]localCacheEntry) (any, error) {
 	// SMOODEV-847 — guard against empty keys. Matches the assertKeyDefined
 	// behavior in the TypeScript SDK; surfaces a clear error instead of
 	// silently returning nil from the merged config map.
@@ -301,52 +858,116 @@ func (m *ConfigManager) getFromTier(key string, cache map[string]localCacheEntry
 	if m.strictSchemaKeys && m.schemaKeys != nil && !m.schemaKeys[key] {
 		return nil, &UndefinedKeyError{Key: key, SchemaPath: m.schemaPath}
 	}
+	if m.accessStats != nil {
+		m.accessStats.record(key)
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Check cache
-	if entry, ok := cache[key]; ok {
-		if time.Now().Before(entry.expiresAt) {
-			return entry.value, nil
+	// Enforce the staleness budget for secrets before any cache hit.
+	if tier == TierSecret && m.failStaleSecrets {
+		if staleness := m.stalenessLocked(); m.maxStaleness > 0 && staleness > m.maxStaleness {
+			return nil, &StaleConfigError{Key: key, Staleness: staleness, Budget: m.maxStaleness, Cause: m.lastRemoteErr}
+		}
+	}
+
+	// Check cache (a schema violation from a live update trumps it)
+	if entry, ok := cache[key]; ok && m.schemaErr == nil {
+		if !entry.expiresAt.passed() {
+			return handOut(entry.value), nil
 		}
-		delete(cache, key)
+		dropCacheEntry(cache, key)
 	}
 
 	// Initialize if needed
 	if err := m.initialize(); err != nil {
 		return nil, err
 	}
+	if err := m.fetchLazyKeyLocked(key); err != nil {
+		return nil, err
+	}
+	m.warnArchivedLocked(key)
+
+	if err := m.checkTierLocked(key, tier); err != nil {
+		return nil, err
+	}
+	if err := m.secretRefErrLocked(key); err != nil {
+		return nil, err
+	}
+	if err := m.interpErrs[key]; err != nil {
+		return nil, err
+	}
 
 	// Lookup in merged config
-	value := m.config[key]
+	value, ok := m.config[key]
+	if !ok && m.strictMode {
+		return nil, &KeyNotFoundError{Key: key, Environment: m.configEnvironment()}
+	}
+	if tier == TierSecret {
+		var err error
+		if value, err = m.decryptSecretLocked(key, value); err != nil {
+			return nil, err
+		}
+	}
 
 	// Cache the result
-	cache[key] = localCacheEntry{value: value, expiresAt: time.Now().Add(m.cacheTTL)}
-	return value, nil
+	if tier == TierSecret {
+		value = m.protectSecret(value)
+	}
+	dropCacheEntry(cache, key)
+	cache[key] = localCacheEntry{value: value, expiresAt: expireAfter(m.cacheTTL)}
+	return handOut(value), nil
 }
 
 // GetPublicConfig retrieves a public config value.
 func (m *ConfigManager) GetPublicConfig(key string) (any, error) {
-	return m.getFromTier(key, m.publicCache)
+	return m.getFromTier(key, TierPublic, m.publicCache)
 }
 
-// GetSecretConfig retrieves a secret config value.
+// GetSecretConfig retrieves a secret config value, once the
+// WithSecretAccessPolicy policy allows it.
 func (m *ConfigManager) GetSecretConfig(key string) (any, error) {
-	return m.getFromTier(key, m.secretCache)
+	if err := m.checkSecretAccess(key); err != nil {
+		return nil, err
+	}
+	return m.getFromTier(key, TierSecret, m.secretCache)
 }
 
 // GetFeatureFlag retrieves a feature flag value.
 func (m *ConfigManager) GetFeatureFlag(key string) (any, error) {
-	return m.getFromTier(key, m.ffCache)
+	return m.getFromTier(key, TierFeatureFlag, m.ffCache)
+}
+
+// Has reports whether any config source sets key, loading the config on
+// first use like the Get* methods. A key explicitly set to null counts as
+// set.
+func (m *ConfigManager) Has(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.initialize(); err != nil {
+		return false, err
+	}
+	_, ok := m.config[key]
+	return ok, nil
 }
 
 // Invalidate clears all caches and forces re-initialization on next access.
 func (m *ConfigManager) Invalidate() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.resetLocked()
+}
+
+// resetLocked drops all merged state and caches. Must be called under m.mu.
+func (m *ConfigManager) resetLocked() {
+	if m.config != nil {
+		// Kept so the next initialize can report what changed.
+		m.previousConfig = m.config
+	}
 	m.initialized = false
 	m.config = nil
-	m.publicCache = make(map[string]localCacheEntry)
-	m.secretCache = make(map[string]localCacheEntry)
-	m.ffCache = make(map[string]localCacheEntry)
+	// Cleared in place: the getters pick their cache map before locking.
+	clear(m.publicCache)
+	scrubCache(m.secretCache)
+	clear(m.ffCache)
 }
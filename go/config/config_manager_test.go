@@ -203,7 +203,7 @@ func TestConfigManager_RemoteEnrichment(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"REMOTE_KEY":    "remote-value",
 		"REMOTE_NUMBER": float64(42),
 	})
@@ -212,7 +212,7 @@ func TestConfigManager_RemoteEnrichment(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
@@ -248,7 +248,7 @@ func TestConfigManager_MergePrecedence_EnvWins(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"API_URL": "http://remote-value",
 	})
 	defer mock.close()
@@ -256,7 +256,7 @@ func TestConfigManager_MergePrecedence_EnvWins(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMSchemaKeys(map[string]bool{"API_URL": true}),
 		WithCMEnvOverride(map[string]string{
@@ -279,7 +279,7 @@ func TestConfigManager_MergePrecedence_RemoteOverFile(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"API_URL": "http://remote-value",
 	})
 	defer mock.close()
@@ -287,7 +287,7 @@ func TestConfigManager_MergePrecedence_RemoteOverFile(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
@@ -309,7 +309,7 @@ func TestConfigManager_MergePrecedence_FileIsBase(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"REMOTE_ONLY_KEY": "remote-only-value",
 	})
 	defer mock.close()
@@ -317,7 +317,7 @@ func TestConfigManager_MergePrecedence_FileIsBase(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
@@ -352,7 +352,7 @@ func TestConfigManager_NestedObjectMerge(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"DATABASE": map[string]any{
 			"host": "remote-db.example.com",
 			"ssl":  true,
@@ -363,7 +363,7 @@ func TestConfigManager_NestedObjectMerge(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
@@ -405,7 +405,7 @@ func TestConfigManager_GracefulDegradation_Server500(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": server.URL,
@@ -430,7 +430,7 @@ func TestConfigManager_GracefulDegradation_UnreachableServer(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL("http://localhost:1"), // Unreachable port
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_ENV_CONFIG_DIR": configDir,
@@ -494,7 +494,7 @@ func TestConfigManager_CacheBehavior_SecondCallCached(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"REMOTE_KEY": "remote-value",
 	})
 	defer mock.close()
@@ -502,7 +502,7 @@ func TestConfigManager_CacheBehavior_SecondCallCached(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
@@ -531,7 +531,7 @@ func TestConfigManager_CacheBehavior_InvalidateClears(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"REMOTE_KEY": "remote-value",
 	})
 	defer mock.close()
@@ -539,7 +539,7 @@ func TestConfigManager_CacheBehavior_InvalidateClears(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
@@ -573,7 +573,7 @@ func TestConfigManager_CacheBehavior_TTLExpiry(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"REMOTE_KEY": "remote-value",
 	})
 	defer mock.close()
@@ -581,7 +581,7 @@ func TestConfigManager_CacheBehavior_TTLExpiry(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMCacheTTL(time.Millisecond), // Very short TTL
 		WithCMEnvOverride(map[string]string{
@@ -620,7 +620,7 @@ func TestConfigManager_APICredsFromEnv(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("env-api-key", "env-org-id", map[string]any{
+	mock := newMockCMServer("env-api-key", testOrgID, map[string]any{
 		"REMOTE_KEY": "from-env-creds",
 	})
 	defer mock.close()
@@ -632,7 +632,7 @@ func TestConfigManager_APICredsFromEnv(t *testing.T) {
 			"SMOOAI_CONFIG_API_KEY":  "env-api-key",
 			"SMOOAI_CONFIG_API_URL":  mock.server.URL,
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
-			"SMOOAI_CONFIG_ORG_ID":   "env-org-id",
+			"SMOOAI_CONFIG_ORG_ID":   testOrgID,
 		}),
 	)
 
@@ -653,7 +653,7 @@ func TestConfigManager_APICredsFromConstructor(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("constructor-key", "constructor-org", map[string]any{
+	mock := newMockCMServer("constructor-key", testOrgID, map[string]any{
 		"REMOTE_KEY": "from-constructor",
 	})
 	defer mock.close()
@@ -661,7 +661,7 @@ func TestConfigManager_APICredsFromConstructor(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("constructor-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("constructor-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
@@ -692,7 +692,7 @@ func TestConfigManager_ThreadSafety_ConcurrentReads(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"REMOTE_KEY": "remote-value",
 	})
 	defer mock.close()
@@ -700,7 +700,7 @@ func TestConfigManager_ThreadSafety_ConcurrentReads(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
@@ -734,7 +734,7 @@ func TestConfigManager_ThreadSafety_ConcurrentInvalidateAndRead(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"REMOTE_KEY": "remote-value",
 	})
 	defer mock.close()
@@ -742,7 +742,7 @@ func TestConfigManager_ThreadSafety_ConcurrentInvalidateAndRead(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
@@ -831,7 +831,7 @@ func TestConfigManager_FullIntegration(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("test-key", "test-org", map[string]any{
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{
 		"API_URL":       "https://api.remote.com",
 		"REMOTE_SECRET": "secret-from-remote",
 		"ENABLE_NEW_UI": true,
@@ -841,7 +841,7 @@ func TestConfigManager_FullIntegration(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("test-key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("test-org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMSchemaKeys(map[string]bool{"API_URL": true, "MAX_RETRIES": true}),
 		WithCMSchemaTypes(map[string]string{"MAX_RETRIES": "number"}),
@@ -920,7 +920,7 @@ func TestConfigManager_EnvironmentResolution_Explicit(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("key"),
 		WithBaseURL(server.URL),
-		WithOrgID("org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("explicit-env"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": server.URL,
@@ -953,7 +953,7 @@ func TestConfigManager_EnvironmentResolution_EnvVar(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("key"),
 		WithBaseURL(server.URL),
-		WithOrgID("org"),
+		WithOrgID(testOrgID),
 		// No WithConfigEnvironment — should fall back to env var
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": server.URL,
@@ -986,7 +986,7 @@ func TestConfigManager_EnvironmentResolution_Default(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("key"),
 		WithBaseURL(server.URL),
-		WithOrgID("org"),
+		WithOrgID(testOrgID),
 		// No WithConfigEnvironment, no SMOOAI_CONFIG_ENV — should default to "development"
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": server.URL,
@@ -1033,7 +1033,7 @@ func TestConfigManager_InvalidationRefetches(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("key"),
 		WithBaseURL(server.URL),
-		WithOrgID("org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("production"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": server.URL,
@@ -1289,7 +1289,7 @@ func TestConfigManager_DeferredWithRemote(t *testing.T) {
 		},
 	})
 
-	mock := newMockCMServer("key", "org", map[string]any{
+	mock := newMockCMServer("key", testOrgID, map[string]any{
 		"HOST": "remote-host",
 		"PORT": float64(8080),
 	})
@@ -1298,7 +1298,7 @@ func TestConfigManager_DeferredWithRemote(t *testing.T) {
 	mgr := NewConfigManager(
 		WithAPIKey("key"),
 		WithBaseURL(mock.server.URL),
-		WithOrgID("org"),
+		WithOrgID(testOrgID),
 		WithConfigEnvironment("test"),
 		WithCMEnvOverride(map[string]string{
 			"SMOOAI_CONFIG_AUTH_URL": mock.server.URL,
@@ -1463,3 +1463,89 @@ func stringContains(s, frag string) bool {
 	}
 	return false
 }
+
+func TestConfigManager_NullPolicy(t *testing.T) {
+	files := map[string]any{
+		"default.json":    map[string]any{"A": "default", "DB": map[string]any{"host": "h", "password": "p"}},
+		"production.json": map[string]any{"A": nil, "DB": map[string]any{"password": nil}},
+	}
+	cases := map[NullPolicy]struct {
+		a     any
+		hasA  bool
+		dbHas bool
+	}{
+		NullSet:       {a: nil, hasA: true, dbHas: true},
+		NullDelete:    {a: nil, hasA: false, dbHas: false},
+		NullKeepLower: {a: "default", hasA: true, dbHas: true},
+	}
+	for policy, want := range cases {
+		t.Run(string(policy), func(t *testing.T) {
+			mgr := NewConfigManager(
+				WithCMNullPolicy(policy),
+				WithCMEnvOverride(map[string]string{
+					"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, files),
+					"SMOOAI_CONFIG_ENV":     "production",
+				}),
+			)
+			v, err := mgr.GetPublicConfig("A")
+			require.NoError(t, err)
+			assert.Equal(t, want.a, v)
+
+			mgr.mu.Lock()
+			_, hasA := mgr.config["A"]
+			_, dbHas := mgr.config["DB"].(map[string]any)["password"]
+			mgr.mu.Unlock()
+			assert.Equal(t, want.hasA, hasA)
+			assert.Equal(t, want.dbHas, dbHas)
+		})
+	}
+}
+
+func TestConfigManager_StrictModeMissingKey(t *testing.T) {
+	env := map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{
+			"default.json": map[string]any{"API_URL": "https://api", "OPTIONAL": nil},
+		}),
+		"SMOOAI_CONFIG_ENV": "production",
+	}
+
+	lenient := NewConfigManager(WithCMEnvOverride(env))
+	v, err := lenient.GetPublicConfig("MISSING")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	strict := NewConfigManager(WithCMEnvOverride(env), WithStrictMode())
+	v, err = strict.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api", v)
+
+	v, err = strict.GetPublicConfig("OPTIONAL")
+	require.NoError(t, err, "explicit null is set")
+	assert.Nil(t, v)
+
+	_, err = strict.GetFeatureFlag("MISSING")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	var notFound *KeyNotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "MISSING", notFound.Key)
+	assert.Equal(t, "production", notFound.Environment)
+
+	// Misses are not cached: a later override makes the key readable.
+	strict.SetOverride("MISSING", "now", 0)
+	v, err = strict.GetFeatureFlag("MISSING")
+	require.NoError(t, err)
+	assert.Equal(t, "now", v)
+}
+
+func TestConfigManager_Has(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{
+			"default.json": map[string]any{"API_URL": "https://api", "OPTIONAL": nil},
+		}),
+	}))
+	for key, want := range map[string]bool{"API_URL": true, "OPTIONAL": true, "MISSING": false} {
+		has, err := mgr.Has(key)
+		require.NoError(t, err)
+		assert.Equal(t, want, has, key)
+	}
+}
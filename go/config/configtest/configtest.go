@@ -0,0 +1,91 @@
+// Package configtest provides test helpers for services that consume
+// @smooai/config. It depends only on the standard library testing package
+// and the config package, so it is safe to import from any _test.go file.
+package configtest
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+// fallbackEnvironment is used when a config dir only carries default.json —
+// the same default the file loader applies when SMOOAI_CONFIG_ENV is unset.
+const fallbackEnvironment = "development"
+
+// Environments lists the environment names that have a top-level
+// {env}.json file in configDir, sorted. default.json and local.json are
+// layers rather than environments and are skipped, as are provider / region
+// / ring overlays ({env}.aws.json, {env}.ring.canary.json, ...). A dir with
+// only default.json yields ["development"].
+func Environments(configDir string) ([]string, error) {
+	entries, err := os.ReadDir(configDir)
+	if err != nil {
+		return nil, err
+	}
+	var envs []string
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), ".json")
+		if name == "default" || name == "local" || strings.Contains(name, ".") {
+			continue
+		}
+		envs = append(envs, name)
+	}
+	if len(envs) == 0 {
+		envs = []string{fallbackEnvironment}
+	}
+	sort.Strings(envs)
+	return envs, nil
+}
+
+// ForEachEnvironment runs fn as a subtest once per environment found in each
+// of dirs (see Environments). Each subtest gets a fresh *config.ConfigManager
+// reading only from that dir and that environment — the process environment
+// is not consulted, so no remote fetch happens unless opts configure one.
+//
+// Subtests are named "<dir base>/<env>". opts are applied after the
+// dir/environment wiring, so callers can add schema keys, deferred values,
+// or their own WithCMEnvOverride entries via EnvOverride.
+//
+//	func TestAllEnvironments(t *testing.T) {
+//	    configtest.ForEachEnvironment(t, []string{"../.smooai-config"}, func(t *testing.T, mgr *config.ConfigManager) {
+//	        v, err := mgr.GetPublicConfig("API_URL")
+//	        require.NoError(t, err)
+//	        require.NotEmpty(t, v)
+//	    })
+//	}
+func ForEachEnvironment(t *testing.T, dirs []string, fn func(t *testing.T, mgr *config.ConfigManager), opts ...config.ConfigManagerOption) {
+	t.Helper()
+	for _, dir := range dirs {
+		envs, err := Environments(dir)
+		if err != nil {
+			t.Fatalf("configtest: list environments in %s: %v", dir, err)
+		}
+		for _, envName := range envs {
+			t.Run(filepath.Base(dir)+"/"+envName, func(t *testing.T) {
+				mgr := NewManagerForEnvironment(dir, envName, opts...)
+				fn(t, mgr)
+			})
+		}
+	}
+}
+
+// NewManagerForEnvironment builds a ConfigManager that reads file config from
+// configDir for envName with an otherwise empty environment.
+func NewManagerForEnvironment(configDir, envName string, opts ...config.ConfigManagerOption) *config.ConfigManager {
+	base := []config.ConfigManagerOption{
+		config.WithConfigEnvironment(envName),
+		config.WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": configDir,
+			"SMOOAI_CONFIG_ENV":     envName,
+		}),
+	}
+	return config.NewConfigManager(append(base, opts...)...)
+}
@@ -0,0 +1,83 @@
+package configtest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+func writeJSON(t *testing.T, dir, filename string, data any) {
+	t.Helper()
+	b, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, filename), b, 0o644))
+}
+
+func makeConfigDir(t *testing.T, files map[string]any) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), ".smooai-config")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	for name, data := range files {
+		writeJSON(t, dir, name, data)
+	}
+	return dir
+}
+
+func TestEnvironments_SkipsLayersAndOverlays(t *testing.T) {
+	dir := makeConfigDir(t, map[string]any{
+		"default.json":                map[string]any{},
+		"local.json":                  map[string]any{},
+		"production.json":             map[string]any{},
+		"production.aws.json":         map[string]any{},
+		"production.ring.canary.json": map[string]any{},
+		"development.json":            map[string]any{},
+		"staging.json":                map[string]any{},
+	})
+	envs, err := Environments(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"development", "production", "staging"}, envs)
+}
+
+func TestEnvironments_DefaultOnlyFallsBackToDevelopment(t *testing.T) {
+	dir := makeConfigDir(t, map[string]any{"default.json": map[string]any{}})
+	envs, err := Environments(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"development"}, envs)
+}
+
+func TestEnvironments_MissingDir(t *testing.T) {
+	_, err := Environments(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestForEachEnvironment_RunsPerEnvironment(t *testing.T) {
+	dir := makeConfigDir(t, map[string]any{
+		"default.json":     map[string]any{"API_URL": "http://localhost"},
+		"production.json":  map[string]any{"API_URL": "https://api.example.com"},
+		"development.json": map[string]any{},
+	})
+
+	var mu sync.Mutex
+	seen := map[string]any{}
+	ForEachEnvironment(t, []string{dir}, func(t *testing.T, mgr *config.ConfigManager) {
+		envName, err := mgr.GetPublicConfig("ENV")
+		require.NoError(t, err)
+		apiURL, err := mgr.GetPublicConfig("API_URL")
+		require.NoError(t, err)
+		mu.Lock()
+		seen[envName.(string)] = apiURL
+		mu.Unlock()
+	})
+
+	assert.Equal(t, map[string]any{
+		"development": "http://localhost",
+		"production":  "https://api.example.com",
+	}, seen)
+}
@@ -0,0 +1,283 @@
+package configtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+// MockOrgID is the organization ID a MockServer serves.
+const MockOrgID = ScriptedOrgID
+
+// mockToken is the access token the mock issues and requires.
+const mockToken = "configtest-token"
+
+// fakeBaseURL is the base URL of a NewFakeClient; nothing listens there.
+const fakeBaseURL = "http://configtest.invalid"
+
+// Request is one request a MockServer received.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+}
+
+// MockServer is a fake config API that follows the real contract: OAuth
+// tokens, bulk and per-key value reads with tiers and ETags, transactional
+// batch writes with If-Match, and raw feature flag evaluation. Build one
+// with NewMockServer and point a client or manager at it:
+//
+//	srv := configtest.NewMockServer(t, map[string]any{"API_URL": "https://api.example.com"})
+//	srv.SetTiers(map[string]config.ConfigTier{"API_URL": config.TierPublic})
+//	mgr := config.NewConfigManager(srv.ManagerOptions("production", nil)...)
+//
+// Values set with SetValues("") are served to every environment that has
+// none of its own. Requests without the token the mock issued get 401.
+type MockServer struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	values   map[string]map[string]any // by environment; "" is the fallback
+	tiers    map[string]config.ConfigTier
+	fail     int
+	requests []Request
+}
+
+// NewMockServer starts a MockServer serving values to every environment.
+// The server is closed when the test ends.
+func NewMockServer(t testing.TB, values map[string]any) *MockServer {
+	t.Helper()
+	s := newMock(values)
+	s.server = httptest.NewServer(s.handler())
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func newMock(values map[string]any) *MockServer {
+	return &MockServer{values: map[string]map[string]any{"": maps.Clone(values)}}
+}
+
+// URL is the base URL of the mock; it also issues OAuth tokens.
+func (s *MockServer) URL() string { return s.server.URL }
+
+// SetValues replaces the values served to environment, or with "" the
+// values served to every environment without its own.
+func (s *MockServer) SetValues(environment string, values map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[environment] = maps.Clone(values)
+}
+
+// SetTiers sets the tier metadata sent with bulk reads.
+func (s *MockServer) SetTiers(tiers map[string]config.ConfigTier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tiers = maps.Clone(tiers)
+}
+
+// FailWith makes every config request fail with status, as an outage would;
+// 0 restores normal service. Token requests still succeed.
+func (s *MockServer) FailWith(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = status
+}
+
+// Requests returns the config requests received so far, oldest first.
+// Token requests are not included.
+func (s *MockServer) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Env returns the SMOOAI_CONFIG_* variables that point a client at the
+// mock.
+func (s *MockServer) Env() map[string]string {
+	return map[string]string{
+		"SMOOAI_CONFIG_API_URL":       s.URL(),
+		"SMOOAI_CONFIG_AUTH_URL":      s.URL(),
+		"SMOOAI_CONFIG_ORG_ID":        MockOrgID,
+		"SMOOAI_CONFIG_CLIENT_ID":     "configtest-client",
+		"SMOOAI_CONFIG_CLIENT_SECRET": "configtest-secret",
+	}
+}
+
+// ManagerOptions configures a ConfigManager to read its remote tier from
+// the mock for environment. env is added to the mock's Env and replaces the
+// process environment, as with config.WithCMEnvOverride.
+func (s *MockServer) ManagerOptions(environment string, env map[string]string) []config.ConfigManagerOption {
+	merged := s.Env()
+	maps.Copy(merged, env)
+	return []config.ConfigManagerOption{
+		config.WithAPIKey("configtest-secret"),
+		config.WithBaseURL(s.URL()),
+		config.WithOrgID(MockOrgID),
+		config.WithConfigEnvironment(environment),
+		config.WithCMEnvOverride(merged),
+	}
+}
+
+// NewClient returns a ConfigClient reading from the mock. It never routes
+// through a local config agent.
+func (s *MockServer) NewClient(opts ...config.ConfigClientOption) *config.ConfigClient {
+	return newMockClient(s.URL(), nil, opts)
+}
+
+// NewFakeClient returns a ConfigClient serving values to every
+// environment without a network listener: its requests go straight to an
+// in-process MockServer. Use it where the code under test takes a
+// *config.ConfigClient; SetValues writes are kept for later reads.
+func NewFakeClient(values map[string]any, opts ...config.ConfigClientOption) *config.ConfigClient {
+	s := newMock(values)
+	return newMockClient(fakeBaseURL, &http.Client{Transport: handlerTransport{s.handler()}}, opts)
+}
+
+func newMockClient(baseURL string, hc *http.Client, opts []config.ConfigClientOption) *config.ConfigClient {
+	base := []config.ConfigClientOption{
+		config.WithAuthURL(baseURL),
+		config.WithAgentAddr("off"),
+	}
+	if hc != nil {
+		base = append(base, config.WithHTTPClient(hc))
+	}
+	return config.NewConfigClient(baseURL, "configtest-client", "configtest-secret", MockOrgID, append(base, opts...)...)
+}
+
+// NewStaticManager returns a ConfigManager serving exactly values, with no
+// config files, env vars or remote API: a stand-in for code that takes a
+// *config.ConfigManager. opts are applied after, for example to add
+// config.WithCMKeyTiers so tier checks apply.
+func NewStaticManager(values map[string]any, opts ...config.ConfigManagerOption) *config.ConfigManager {
+	data, err := json.Marshal(values)
+	if err != nil {
+		panic("configtest: NewStaticManager: " + err.Error())
+	}
+	base := []config.ConfigManagerOption{
+		config.WithCMConfigFiles(map[string]string{"default.json": string(data)}),
+		config.WithCMEnvOverride(map[string]string{}),
+	}
+	return config.NewConfigManager(append(base, opts...)...)
+}
+
+// handlerTransport serves requests with an http.Handler in process.
+type handlerTransport struct{ h http.Handler }
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.h.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+func (s *MockServer) handler() http.Handler {
+	prefix := "/organizations/" + MockOrgID + "/config/"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		writeScriptedJSON(w, map[string]any{"access_token": mockToken, "expires_in": 3600, "token_type": "Bearer"})
+	})
+	mux.HandleFunc("GET "+prefix+"values", func(w http.ResponseWriter, r *http.Request) {
+		values, tiers := s.snapshot(r.URL.Query().Get("environment"))
+		etag := valuesETag(values)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		writeScriptedJSON(w, map[string]any{"values": values, "tiers": tiers})
+	})
+	mux.HandleFunc("GET "+prefix+"values/{key}", func(w http.ResponseWriter, r *http.Request) {
+		values, _ := s.snapshot(r.URL.Query().Get("environment"))
+		v, ok := values[r.PathValue("key")]
+		if !ok {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		writeScriptedJSON(w, map[string]any{"value": v})
+	})
+	mux.HandleFunc("PUT "+prefix+"values/batch", s.serveBatch)
+	mux.HandleFunc("POST "+prefix+"feature-flags/{key}/evaluate", func(w http.ResponseWriter, r *http.Request) {
+		values, _ := s.snapshot(r.URL.Query().Get("environment"))
+		v, ok := values[r.PathValue("key")]
+		if !ok {
+			http.Error(w, `{"error":"flag not found"}`, http.StatusNotFound)
+			return
+		}
+		writeScriptedJSON(w, map[string]any{"value": v, "source": "raw"})
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" {
+			s.mu.Lock()
+			s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query()})
+			fail := s.fail
+			s.mu.Unlock()
+			if r.Header.Get("Authorization") != "Bearer "+mockToken {
+				http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+				return
+			}
+			if fail != 0 {
+				http.Error(w, `{"error":"injected failure"}`, fail)
+				return
+			}
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// serveBatch applies a SetValues write to its environment, refusing it
+// with 412 when If-Match names another version.
+func (s *MockServer) serveBatch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Environment string         `json:"environment"`
+		Values      map[string]any `json:"values"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error":"invalid body"}`, http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := s.valuesLocked(body.Environment)
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != valuesETag(current) {
+		http.Error(w, `{"error":"version mismatch"}`, http.StatusPreconditionFailed)
+		return
+	}
+	updated := maps.Clone(current)
+	if updated == nil {
+		updated = make(map[string]any, len(body.Values))
+	}
+	maps.Copy(updated, body.Values)
+	s.values[body.Environment] = updated
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// snapshot returns the values and tiers served to environment.
+func (s *MockServer) snapshot(environment string) (map[string]any, map[string]config.ConfigTier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.valuesLocked(environment)), maps.Clone(s.tiers)
+}
+
+func (s *MockServer) valuesLocked(environment string) map[string]any {
+	if values, ok := s.values[environment]; ok {
+		return values
+	}
+	return s.values[""]
+}
+
+// valuesETag is a strong ETag over the JSON of values.
+func valuesETag(values map[string]any) string {
+	data, _ := json.Marshal(values)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
@@ -0,0 +1,108 @@
+package configtest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+func TestMockServer_Manager(t *testing.T) {
+	srv := NewMockServer(t, map[string]any{"API_URL": "https://default", "DB_PASSWORD": "hunter2"})
+	srv.SetValues("production", map[string]any{"API_URL": "https://prod", "DB_PASSWORD": "s3cret"})
+	srv.SetTiers(map[string]config.ConfigTier{"API_URL": config.TierPublic, "DB_PASSWORD": config.TierSecret})
+	mgr := config.NewConfigManager(srv.ManagerOptions("production", map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+	})...)
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://prod", v)
+	_, err = mgr.GetPublicConfig("DB_PASSWORD")
+	assert.ErrorIs(t, err, config.ErrWrongTier, "tiers are sent with the values")
+
+	requests := srv.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/organizations/"+MockOrgID+"/config/values", requests[0].Path)
+	assert.Equal(t, "production", requests[0].Query.Get("environment"))
+}
+
+func TestMockServer_Client(t *testing.T) {
+	srv := NewMockServer(t, map[string]any{"API_URL": "https://default"})
+	client := srv.NewClient()
+	defer client.Close()
+
+	values, err := client.GetAllValues("staging")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"API_URL": "https://default"}, values, "environments without values get the fallback")
+
+	client.InvalidateCacheMatching(config.CacheFilter{Key: "API_URL"})
+	_, err = client.GetAllValues("staging")
+	require.NoError(t, err)
+	assert.Len(t, srv.Requests(), 2, "the refresh goes back to the server")
+
+	_, err = client.GetValue("MISSING", "staging")
+	assert.ErrorIs(t, err, config.ErrNotFound)
+
+	srv.FailWith(http.StatusServiceUnavailable)
+	_, err = client.GetValue("OTHER", "staging")
+	assert.ErrorIs(t, err, config.ErrServerError)
+}
+
+func TestMockServer_SetValuesIfMatch(t *testing.T) {
+	srv := NewMockServer(t, map[string]any{"LIMIT": 1.0})
+	client := srv.NewClient()
+	defer client.Close()
+
+	_, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	etag := client.SnapshotETag("production")
+	require.NoError(t, client.SetValues("production", map[string]any{"LIMIT": 2.0}, config.WithIfMatch(etag)))
+	err = client.SetValues("production", map[string]any{"LIMIT": 3.0}, config.WithIfMatch(etag))
+	assert.ErrorIs(t, err, config.ErrWriteConflict, "the version moved on")
+
+	client.InvalidateCache()
+	values, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, values["LIMIT"])
+}
+
+func TestNewFakeClient(t *testing.T) {
+	client := NewFakeClient(map[string]any{"API_URL": "https://fake", "NEW_UI": true})
+	defer client.Close()
+
+	v, err := client.GetValue("API_URL", "production")
+	require.NoError(t, err)
+	assert.Equal(t, "https://fake", v)
+	flag, err := client.EvaluateFeatureFlag(context.Background(), "NEW_UI", nil, "production")
+	require.NoError(t, err)
+	assert.Equal(t, true, flag.Value)
+
+	require.NoError(t, client.SetValues("production", map[string]any{"API_URL": "https://written"}))
+	client.InvalidateCache()
+	v, err = client.GetValue("API_URL", "production")
+	require.NoError(t, err)
+	assert.Equal(t, "https://written", v)
+}
+
+func TestNewStaticManager(t *testing.T) {
+	t.Setenv("API_URL", "from-process-env")
+	mgr := NewStaticManager(
+		map[string]any{"API_URL": "https://static", "DB_PASSWORD": "hunter2"},
+		config.WithCMKeyTiers(map[string]config.ConfigTier{"DB_PASSWORD": config.TierSecret}),
+		config.WithCMSchemaKeys(map[string]bool{"API_URL": true}),
+	)
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://static", v, "the process environment is ignored")
+	v, err = mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+	_, err = mgr.GetPublicConfig("DB_PASSWORD")
+	assert.ErrorIs(t, err, config.ErrWrongTier)
+}
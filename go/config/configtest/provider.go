@@ -0,0 +1,88 @@
+package configtest
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+// FakeProvider is a config.Provider serving a map, for code that takes the
+// interface. Every tier reads the same values; Set changes them and
+// notifies watchers synchronously, so a test can assert on the effect right
+// after:
+//
+//	cfg := configtest.NewFakeProvider(map[string]any{"NEW_UI": false})
+//	svc := NewService(cfg)
+//	cfg.Set("NEW_UI", true)
+//
+// A key that isn't set reads as nil with no error, as from a manager.
+type FakeProvider struct {
+	mu       sync.Mutex
+	values   map[string]any
+	watchers map[int]func(config.ConfigChangeEvent)
+	nextID   int
+}
+
+var _ config.Provider = (*FakeProvider)(nil)
+
+// NewFakeProvider returns a FakeProvider serving a copy of values.
+func NewFakeProvider(values map[string]any) *FakeProvider {
+	p := &FakeProvider{values: maps.Clone(values), watchers: make(map[int]func(config.ConfigChangeEvent))}
+	if p.values == nil {
+		p.values = make(map[string]any)
+	}
+	return p
+}
+
+// GetPublicConfig returns the value of key.
+func (p *FakeProvider) GetPublicConfig(key string) (any, error) { return p.get(key), nil }
+
+// GetSecretConfig returns the value of key.
+func (p *FakeProvider) GetSecretConfig(key string) (any, error) { return p.get(key), nil }
+
+// GetFeatureFlag returns the value of key.
+func (p *FakeProvider) GetFeatureFlag(key string) (any, error) { return p.get(key), nil }
+
+func (p *FakeProvider) get(key string) any {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.values[key]
+}
+
+// Set sets key to value, or with a nil value removes it, and calls every
+// watcher before returning.
+func (p *FakeProvider) Set(key string, value any) {
+	p.mu.Lock()
+	evt := config.ConfigChangeEvent{Keys: []string{key}, Values: map[string]any{}}
+	if value == nil {
+		delete(p.values, key)
+	} else {
+		p.values[key] = value
+		evt.Values[key] = value
+	}
+	ids := slices.Sorted(maps.Keys(p.watchers))
+	fns := make([]func(config.ConfigChangeEvent), 0, len(ids))
+	for _, id := range ids {
+		fns = append(fns, p.watchers[id])
+	}
+	p.mu.Unlock()
+	for _, fn := range fns {
+		fn(evt)
+	}
+}
+
+// Watch calls fn from Set until stop is called.
+func (p *FakeProvider) Watch(fn func(config.ConfigChangeEvent)) (stop func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := p.nextID
+	p.nextID++
+	p.watchers[id] = fn
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.watchers, id)
+	}
+}
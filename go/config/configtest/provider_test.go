@@ -0,0 +1,38 @@
+package configtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+func TestFakeProvider(t *testing.T) {
+	p := NewFakeProvider(map[string]any{"PORT": 8080.0, "NEW_UI": false})
+	port, err := config.Get[int](p, "PORT")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	var events []config.ConfigChangeEvent
+	stop := p.Watch(func(evt config.ConfigChangeEvent) { events = append(events, evt) })
+	p.Set("NEW_UI", true)
+	require.Len(t, events, 1, "watchers run before Set returns")
+	assert.Equal(t, []string{"NEW_UI"}, events[0].Keys)
+	assert.Equal(t, true, events[0].Values["NEW_UI"])
+	v, err := p.GetFeatureFlag("NEW_UI")
+	require.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	p.Set("PORT", nil)
+	require.Len(t, events, 2)
+	assert.NotContains(t, events[1].Values, "PORT", "a removed key has no value")
+	v, err = p.GetPublicConfig("PORT")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	stop()
+	p.Set("NEW_UI", false)
+	assert.Len(t, events, 2)
+}
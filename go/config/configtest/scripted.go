@@ -0,0 +1,287 @@
+package configtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+// ScriptedOrgID is the organization ID a ScriptedSource serves.
+const ScriptedOrgID = "550e8400-e29b-41d4-a716-446655440000"
+
+// Step is one state in a ScriptedSource timeline: from At (measured from the
+// start of the script) the source serves Values.
+type Step struct {
+	At     time.Duration
+	Values map[string]any
+}
+
+// ScriptedSource is a fake config API that replays a timeline of config
+// states against a fake clock. Nothing changes until Advance moves the
+// clock past a step, so examples and integration tests can walk through
+// refreshes, subscriptions and rollouts deterministically:
+//
+//	src := configtest.NewScriptedSource(t,
+//	    configtest.Step{At: 0, Values: map[string]any{"ROLLOUT_PERCENT": 10}},
+//	    configtest.Step{At: 30 * time.Second, Values: map[string]any{"ROLLOUT_PERCENT": 50}},
+//	)
+//	mgr := config.NewConfigManager(src.ManagerOptions("production", nil)...)
+//	sub, _ := mgr.Subscribe(ctx)
+//	src.Advance(30 * time.Second) // sub receives {"keys": ["ROLLOUT_PERCENT"], ...}
+//
+// Every environment and ring sees the same timeline. Crossing a step pushes
+// one change event, listing the keys that differ from the previous step, to
+// open SSE streams and pending long polls; a fresh fetch (for example after
+// ConfigManager.Invalidate) returns the current step's values, and flag
+// evaluations return them as is (source "raw"). Before the first step the
+// source serves no values.
+type ScriptedSource struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	steps   []Step
+	now     time.Duration
+	values  map[string]any
+	events  []config.ConfigChangeEvent // event n has id n+1
+	changed chan struct{}              // closed and replaced when events grows
+}
+
+// NewScriptedSource starts a ScriptedSource serving steps, which are sorted
+// by At. The server is closed when the test ends.
+func NewScriptedSource(t testing.TB, steps ...Step) *ScriptedSource {
+	t.Helper()
+	s := StartScriptedSource(steps...)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// StartScriptedSource is NewScriptedSource for code without a testing.TB,
+// such as examples. Call Close when done.
+func StartScriptedSource(steps ...Step) *ScriptedSource {
+	s := &ScriptedSource{
+		steps:   slices.SortedStableFunc(slices.Values(steps), func(a, b Step) int { return int(a.At - b.At) }),
+		values:  map[string]any{},
+		changed: make(chan struct{}),
+	}
+	for _, step := range s.steps {
+		if step.At > 0 {
+			break
+		}
+		s.values = maps.Clone(step.Values)
+	}
+	s.server = httptest.NewServer(s.handler())
+	return s
+}
+
+// Close shuts the fake API down, ending open streams and long polls.
+func (s *ScriptedSource) Close() {
+	s.server.CloseClientConnections()
+	s.server.Close()
+}
+
+// URL is the base URL of the fake API; it also issues OAuth tokens.
+func (s *ScriptedSource) URL() string { return s.server.URL }
+
+// Now returns how far the fake clock has advanced.
+func (s *ScriptedSource) Now() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Values returns a copy of the values currently served.
+func (s *ScriptedSource) Values() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.values)
+}
+
+// Advance moves the fake clock forward by d, applying every step it
+// crosses in order and pushing a change event for each one that changed a
+// value. It returns the keys changed, sorted.
+func (s *ScriptedSource) Advance(d time.Duration) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	from := s.now
+	s.now += d
+	changed := map[string]bool{}
+	for _, step := range s.steps {
+		if step.At <= from || step.At > s.now {
+			continue
+		}
+		evt := config.ConfigChangeEvent{Values: map[string]any{}}
+		for _, key := range sortedKeys(s.values, step.Values) {
+			old, had := s.values[key]
+			v, ok := step.Values[key]
+			if had == ok && reflect.DeepEqual(old, v) {
+				continue
+			}
+			evt.Keys = append(evt.Keys, key)
+			if ok {
+				evt.Values[key] = v
+			}
+			changed[key] = true
+		}
+		s.values = maps.Clone(step.Values)
+		if len(evt.Keys) > 0 {
+			s.events = append(s.events, evt)
+		}
+	}
+	if len(changed) > 0 {
+		close(s.changed)
+		s.changed = make(chan struct{})
+	}
+	return slices.Sorted(maps.Keys(changed))
+}
+
+// Env returns the SMOOAI_CONFIG_* variables that point a client at the
+// source.
+func (s *ScriptedSource) Env() map[string]string {
+	return map[string]string{
+		"SMOOAI_CONFIG_API_URL":       s.URL(),
+		"SMOOAI_CONFIG_AUTH_URL":      s.URL(),
+		"SMOOAI_CONFIG_ORG_ID":        ScriptedOrgID,
+		"SMOOAI_CONFIG_CLIENT_ID":     "scripted-client",
+		"SMOOAI_CONFIG_CLIENT_SECRET": "scripted-secret",
+	}
+}
+
+// ManagerOptions configures a ConfigManager to read its remote tier from
+// the source for environment. env is added to the source's Env and replaces
+// the process environment, as with config.WithCMEnvOverride.
+func (s *ScriptedSource) ManagerOptions(environment string, env map[string]string) []config.ConfigManagerOption {
+	merged := s.Env()
+	maps.Copy(merged, env)
+	return []config.ConfigManagerOption{
+		config.WithAPIKey("scripted-secret"),
+		config.WithBaseURL(s.URL()),
+		config.WithOrgID(ScriptedOrgID),
+		config.WithConfigEnvironment(environment),
+		config.WithCMEnvOverride(merged),
+	}
+}
+
+// NewClient returns a ConfigClient reading from the source. It never
+// routes through a local config agent.
+func (s *ScriptedSource) NewClient(opts ...config.ConfigClientOption) *config.ConfigClient {
+	base := []config.ConfigClientOption{
+		config.WithAuthURL(s.URL()),
+		config.WithAgentAddr("off"),
+	}
+	return config.NewConfigClient(s.URL(), "scripted-client", "scripted-secret", ScriptedOrgID, append(base, opts...)...)
+}
+
+func (s *ScriptedSource) handler() http.Handler {
+	prefix := "/organizations/" + ScriptedOrgID + "/config/"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		writeScriptedJSON(w, map[string]any{"access_token": "scripted-token", "expires_in": 3600})
+	})
+	mux.HandleFunc(prefix+"values", func(w http.ResponseWriter, r *http.Request) {
+		writeScriptedJSON(w, map[string]any{"values": s.Values()})
+	})
+	mux.HandleFunc(prefix+"values/stream", s.serveStream)
+	mux.HandleFunc(prefix+"values/", func(w http.ResponseWriter, r *http.Request) {
+		v, ok := s.Values()[strings.TrimPrefix(r.URL.Path, prefix+"values/")]
+		if !ok {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		writeScriptedJSON(w, map[string]any{"value": v})
+	})
+	mux.HandleFunc(prefix+"changes", s.serveChanges)
+	mux.HandleFunc("POST "+prefix+"feature-flags/{key}/evaluate", func(w http.ResponseWriter, r *http.Request) {
+		v, ok := s.Values()[r.PathValue("key")]
+		if !ok {
+			http.Error(w, `{"error":"flag not found"}`, http.StatusNotFound)
+			return
+		}
+		writeScriptedJSON(w, map[string]any{"value": v, "source": "raw"})
+	})
+	return mux
+}
+
+// pending returns the events after cursor (clamped to the events pushed so
+// far) with the clamped cursor, and a channel closed when more arrive.
+func (s *ScriptedSource) pending(cursor int) ([]config.ConfigChangeEvent, int, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursor = min(max(cursor, 0), len(s.events))
+	return s.events[cursor:], cursor, s.changed
+}
+
+func (s *ScriptedSource) serveStream(w http.ResponseWriter, r *http.Request) {
+	cursor := math.MaxInt // only events from now on
+	if id, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil {
+		cursor = id
+	}
+	_, cursor, _ = s.pending(cursor)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+	for {
+		events, from, changed := s.pending(cursor)
+		for i, evt := range events {
+			data, _ := json.Marshal(evt)
+			fmt.Fprintf(w, "id: %d\nevent: change\ndata: %s\n\n", from+i+1, data)
+		}
+		w.(http.Flusher).Flush()
+		cursor = from + len(events)
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		}
+	}
+}
+
+// serveChanges answers a long poll: without since, at once with the current
+// cursor; with since, once there are events after it.
+func (s *ScriptedSource) serveChanges(w http.ResponseWriter, r *http.Request) {
+	since, err := strconv.Atoi(r.URL.Query().Get("since"))
+	if err != nil {
+		since = math.MaxInt
+	}
+	events, from, changed := s.pending(since)
+	if err == nil && len(events) == 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		}
+		events, from, _ = s.pending(from)
+	}
+	changes := make([]map[string]any, len(events))
+	for i, evt := range events {
+		changes[i] = map[string]any{"id": strconv.Itoa(from + i + 1), "keys": evt.Keys, "values": evt.Values}
+	}
+	writeScriptedJSON(w, map[string]any{"cursor": strconv.Itoa(from + len(events)), "changes": changes})
+}
+
+func writeScriptedJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func sortedKeys(a, b map[string]any) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,92 @@
+package configtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+func rolloutScript(t *testing.T) *ScriptedSource {
+	return NewScriptedSource(t,
+		Step{At: 30 * time.Second, Values: map[string]any{"API_URL": "https://b", "ROLLOUT_PERCENT": 50.0}},
+		Step{At: 0, Values: map[string]any{"API_URL": "https://a", "ROLLOUT_PERCENT": 10.0, "LEGACY": true}},
+		Step{At: time.Minute, Values: map[string]any{"API_URL": "https://b", "ROLLOUT_PERCENT": 100.0}},
+	)
+}
+
+func nextEvent(t *testing.T, sub *config.Subscription) config.ConfigChangeEvent {
+	t.Helper()
+	select {
+	case evt, ok := <-sub.Events():
+		require.True(t, ok, "subscription closed")
+		return evt
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a change event")
+		return config.ConfigChangeEvent{}
+	}
+}
+
+func TestScriptedSource_RefreshSeesCurrentStep(t *testing.T) {
+	src := rolloutScript(t)
+	mgr := config.NewConfigManager(src.ManagerOptions("production", map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+	})...)
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://a", v)
+
+	assert.Empty(t, src.Advance(10*time.Second), "no step crossed")
+	assert.Equal(t, []string{"API_URL", "LEGACY", "ROLLOUT_PERCENT"}, src.Advance(20*time.Second))
+	assert.Equal(t, 30*time.Second, src.Now())
+
+	v, _ = mgr.GetPublicConfig("API_URL")
+	assert.Equal(t, "https://a", v, "cached until the next refresh")
+	mgr.Invalidate()
+	v, _ = mgr.GetPublicConfig("API_URL")
+	assert.Equal(t, "https://b", v)
+	v, _ = mgr.GetPublicConfig("LEGACY")
+	assert.Nil(t, v)
+}
+
+func TestScriptedSource_SubscribeReceivesSteps(t *testing.T) {
+	src := rolloutScript(t)
+	mgr := config.NewConfigManager(src.ManagerOptions("production", map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+	})...)
+	sub, err := mgr.Subscribe(context.Background())
+	require.NoError(t, err)
+	defer sub.Close()
+
+	src.Advance(30 * time.Second)
+	evt := nextEvent(t, sub)
+	assert.Equal(t, []string{"API_URL", "LEGACY", "ROLLOUT_PERCENT"}, evt.Keys)
+	assert.Equal(t, "1", evt.ID)
+	v, _ := mgr.GetFeatureFlag("ROLLOUT_PERCENT")
+	assert.Equal(t, 50.0, v)
+}
+
+func TestScriptedSource_LongPollDeliversEachStepInOrder(t *testing.T) {
+	src := rolloutScript(t)
+	client := src.NewClient(config.WithWatchTransport(config.WatchLongPoll))
+	defer client.Close()
+	sub, err := client.Subscribe(context.Background(), "production")
+	require.NoError(t, err)
+	defer sub.Close()
+
+	assert.Equal(t, []string{"API_URL", "LEGACY", "ROLLOUT_PERCENT"}, src.Advance(time.Minute))
+	first := nextEvent(t, sub)
+	assert.Equal(t, map[string]any{"API_URL": "https://b", "ROLLOUT_PERCENT": 50.0}, first.Values)
+	second := nextEvent(t, sub)
+	assert.Equal(t, []string{"ROLLOUT_PERCENT"}, second.Keys)
+	assert.Equal(t, "2", second.ID)
+
+	v, err := client.GetValue("ROLLOUT_PERCENT", "production")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, v)
+}
@@ -0,0 +1,132 @@
+package config
+
+// Runner for the cross-language conformance suite in
+// test-fixtures/conformance. Every SDK runs the same fixture files; a failure
+// here means the Go SDK disagrees with the shared contract, not just with
+// itself.
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadConformanceFixture(t *testing.T, name string, into any) {
+	t.Helper()
+	_, filename, _, _ := runtime.Caller(0)
+	path := filepath.Join(filepath.Dir(filename), "../../test-fixtures/conformance", name)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "Failed to read conformance fixture %s", name)
+	require.NoError(t, json.Unmarshal(data, into), "Failed to parse conformance fixture %s", name)
+}
+
+// normalizeJSON round-trips v through JSON so Go-specific types (int vs
+// float64, typed maps) compare equal to the decoded fixture values.
+func normalizeJSON(t *testing.T, v any) any {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var out any
+	require.NoError(t, json.Unmarshal(data, &out))
+	return out
+}
+
+func TestConformance_Merge(t *testing.T) {
+	var fixture struct {
+		Cases []struct {
+			Name       string `json:"name"`
+			NullPolicy string `json:"nullPolicy"`
+			Target     any    `json:"target"`
+			Source     any    `json:"source"`
+			Expected   any    `json:"expected"`
+		} `json:"cases"`
+	}
+	loadConformanceFixture(t, "merge.json", &fixture)
+	require.NotEmpty(t, fixture.Cases)
+
+	for _, tc := range fixture.Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			before := normalizeJSON(t, tc.Target)
+			got := MergeWithNullPolicy(tc.Target, tc.Source, NullPolicy(tc.NullPolicy))
+			assert.Equal(t, tc.Expected, normalizeJSON(t, got))
+			assert.Equal(t, before, normalizeJSON(t, tc.Target), "target must not be mutated")
+		})
+	}
+}
+
+func TestConformance_Coercion(t *testing.T) {
+	var fixture struct {
+		Cases []struct {
+			Name     string `json:"name"`
+			Type     string `json:"type"`
+			Input    string `json:"input"`
+			Expected any    `json:"expected"`
+		} `json:"cases"`
+	}
+	loadConformanceFixture(t, "coercion.json", &fixture)
+	require.NotEmpty(t, fixture.Cases)
+
+	for _, tc := range fixture.Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			types := map[string]string{}
+			if tc.Type != "" {
+				types["KEY"] = tc.Type
+			}
+			got := findAndProcessEnvConfigWithEnv(map[string]bool{"KEY": true}, "", types, map[string]string{"KEY": tc.Input})
+			assert.Equal(t, tc.Expected, normalizeJSON(t, got["KEY"]))
+		})
+	}
+}
+
+func TestConformance_Precedence(t *testing.T) {
+	var fixture struct {
+		Cases []struct {
+			Name        string            `json:"name"`
+			Environment string            `json:"environment"`
+			Files       map[string]any    `json:"files"`
+			Remote      map[string]any    `json:"remote"`
+			Env         map[string]string `json:"env"`
+			SchemaKeys  []string          `json:"schemaKeys"`
+			SchemaTypes map[string]string `json:"schemaTypes"`
+			Expected    map[string]any    `json:"expected"`
+		} `json:"cases"`
+	}
+	loadConformanceFixture(t, "precedence.json", &fixture)
+	require.NotEmpty(t, fixture.Cases)
+
+	for _, tc := range fixture.Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			env := map[string]string{
+				"SMOOAI_CONFIG_ENV":     tc.Environment,
+				"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, tc.Files),
+			}
+			opts := []ConfigManagerOption{WithConfigEnvironment(tc.Environment), WithCMSchemaTypes(tc.SchemaTypes)}
+			if tc.Remote != nil {
+				mock := newMockCMServer("key", "org", tc.Remote)
+				t.Cleanup(mock.close)
+				env["SMOOAI_CONFIG_AUTH_URL"] = mock.server.URL
+				opts = append(opts, WithAPIKey("key"), WithBaseURL(mock.server.URL), WithOrgID(testOrgID))
+			}
+			for k, v := range tc.Env {
+				env[k] = v
+			}
+			schemaKeys := make(map[string]bool, len(tc.SchemaKeys))
+			for _, k := range tc.SchemaKeys {
+				schemaKeys[k] = true
+			}
+			opts = append(opts, WithCMSchemaKeys(schemaKeys), WithCMEnvOverride(env))
+
+			mgr := NewConfigManager(opts...)
+			for key, want := range tc.Expected {
+				got, err := mgr.GetPublicConfig(key)
+				require.NoError(t, err)
+				assert.Equal(t, want, normalizeJSON(t, got), "key %s", key)
+			}
+		})
+	}
+}
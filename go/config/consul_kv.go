@@ -0,0 +1,173 @@
+package config
+
+// Consul KV store, through Consul's HTTP API. Watches use blocking queries,
+// so a change is seen as soon as Consul commits it.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// consulWait is how long a blocking query waits for a change.
+const consulWait = 5 * time.Minute
+
+// ConsulOption configures ConsulKV.
+type ConsulOption func(*ConsulKV)
+
+// WithConsulAddr sets the agent address instead of CONSUL_HTTP_ADDR, e.g.
+// "https://consul.internal:8501". A bare host:port means http.
+func WithConsulAddr(addr string) ConsulOption {
+	return func(c *ConsulKV) { c.addr = addr }
+}
+
+// WithConsulToken sets the ACL token instead of CONSUL_HTTP_TOKEN.
+func WithConsulToken(token string) ConsulOption {
+	return func(c *ConsulKV) { c.token = token }
+}
+
+// WithConsulEnv reads CONSUL_* variables through lookup instead of the
+// process environment.
+func WithConsulEnv(lookup EnvFunc) ConsulOption {
+	return func(c *ConsulKV) { c.lookupEnv = lookup }
+}
+
+// WithConsulHTTPClient sets the HTTP client. Its timeout, if any, must
+// exceed the five minute wait of a blocking query.
+func WithConsulHTTPClient(client *http.Client) ConsulOption {
+	return func(c *ConsulKV) { c.client = client }
+}
+
+// ConsulKV reads config from Consul KV. Use it as a KVStore with
+// WithCMKVStore, and as a RemoteProvider for references to single keys:
+//
+//	smooai-secret://consul/myapp/prod/DB_PASSWORD
+type ConsulKV struct {
+	addr      string
+	token     string
+	client    *http.Client
+	lookupEnv EnvFunc
+}
+
+// NewConsulKV creates a Consul KV store. The address defaults to
+// CONSUL_HTTP_ADDR, then the local agent at 127.0.0.1:8500, and the token to
+// CONSUL_HTTP_TOKEN.
+func NewConsulKV(opts ...ConsulOption) *ConsulKV {
+	c := &ConsulKV{client: http.DefaultClient, lookupEnv: os.LookupEnv}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.addr == "" {
+		c.addr, _ = c.lookupEnv("CONSUL_HTTP_ADDR")
+	}
+	if c.addr == "" {
+		c.addr = "127.0.0.1:8500"
+	}
+	if !strings.Contains(c.addr, "://") {
+		c.addr = "http://" + c.addr
+	}
+	c.addr = strings.TrimRight(c.addr, "/")
+	if c.token == "" {
+		c.token, _ = c.lookupEnv("CONSUL_HTTP_TOKEN")
+	}
+	return c
+}
+
+type consulPair struct {
+	Key   string `json:"Key"`
+	Value []byte `json:"Value"` // base64 in JSON; null for folders
+}
+
+// List implements KVStore.
+func (c *ConsulKV) List(ctx context.Context, prefix string) (map[string]string, uint64, error) {
+	return c.list(ctx, prefix, url.Values{"recurse": {"true"}})
+}
+
+// Wait implements KVStore with a blocking query.
+func (c *ConsulKV) Wait(ctx context.Context, prefix string, index uint64) (uint64, error) {
+	query := url.Values{"recurse": {"true"}, "keys": {"true"}}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", consulWait.String())
+	}
+	_, next, err := c.list(ctx, prefix, query)
+	if err == nil && next < index {
+		// The index went backwards (a snapshot restore); start over so the
+		// next change is seen.
+		next = 0
+	}
+	return next, err
+}
+
+func (c *ConsulKV) list(ctx context.Context, prefix string, query url.Values) (map[string]string, uint64, error) {
+	resp, data, err := c.get(ctx, prefix, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	values := make(map[string]string)
+	if resp.StatusCode == http.StatusNotFound || query.Has("keys") {
+		return values, index, nil
+	}
+	var pairs []consulPair
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, 0, fmt.Errorf("consul: decoding response: %w", err)
+	}
+	for _, p := range pairs {
+		if strings.HasSuffix(p.Key, "/") {
+			continue
+		}
+		values[strings.TrimPrefix(p.Key, prefix)] = string(p.Value)
+	}
+	return values, index, nil
+}
+
+// Handles reports whether ref names a Consul key.
+func (c *ConsulKV) Handles(ref string) bool {
+	return strings.HasPrefix(ref, "consul/")
+}
+
+// Resolve returns the value of the key ref names.
+func (c *ConsulKV) Resolve(ctx context.Context, ref string) (string, error) {
+	key := strings.TrimPrefix(ref, "consul/")
+	resp, data, err := c.get(ctx, key, url.Values{"raw": {"true"}})
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("consul: key %s not found", key)
+	}
+	return string(data), nil
+}
+
+// get reads /v1/kv/key. A 404 is returned as a response, not an error.
+func (c *ConsulKV) get(ctx context.Context, key string, query url.Values) (*http.Response, []byte, error) {
+	u := c.addr + (&url.URL{Path: "/v1/kv/" + key}).EscapedPath() + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return nil, nil, fmt.Errorf("consul: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return resp, data, nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsulKV(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tok", r.Header.Get("X-Consul-Token"))
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/v1/kv/myapp/prod/DB_PASSWORD" && q.Has("raw"):
+			w.Write([]byte("hunter2"))
+		case r.URL.Path == "/v1/kv/myapp/prod/" && q.Has("keys"):
+			if q.Get("index") == "7" {
+				assert.Equal(t, "5m0s", q.Get("wait"))
+				w.Header().Set("X-Consul-Index", "9")
+			} else {
+				w.Header().Set("X-Consul-Index", "7")
+			}
+			json.NewEncoder(w).Encode([]string{"myapp/prod/API_URL"})
+		case r.URL.Path == "/v1/kv/myapp/prod/":
+			w.Header().Set("X-Consul-Index", "7")
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"Key": "myapp/prod/", "Value": nil},
+				{"Key": "myapp/prod/API_URL", "Value": []byte("https://consul")},
+				{"Key": "myapp/prod/db/host", "Value": []byte("db.internal")},
+			})
+		default:
+			w.Header().Set("X-Consul-Index", "3")
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	env := func(key string) (string, bool) {
+		v, ok := map[string]string{"CONSUL_HTTP_ADDR": server.URL, "CONSUL_HTTP_TOKEN": "tok"}[key]
+		return v, ok
+	}
+	kv := NewConsulKV(WithConsulEnv(env))
+	ctx := context.Background()
+
+	values, index, err := kv.List(ctx, "myapp/prod/")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"API_URL": "https://consul", "db/host": "db.internal"}, values)
+	assert.Equal(t, uint64(7), index)
+
+	index, err = kv.Wait(ctx, "myapp/prod/", 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), index)
+	index, err = kv.Wait(ctx, "myapp/prod/", index)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), index)
+
+	values, _, err = kv.List(ctx, "other/")
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	assert.True(t, kv.Handles("consul/myapp/prod/DB_PASSWORD"))
+	assert.False(t, kv.Handles("etcd/myapp/prod/DB_PASSWORD"))
+	secret, err := kv.Resolve(ctx, "consul/myapp/prod/DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+	_, err = kv.Resolve(ctx, "consul/missing")
+	assert.ErrorContains(t, err, "consul: key missing not found")
+}
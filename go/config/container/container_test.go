@@ -111,7 +111,7 @@ func TestInitContainerConfig_BlankEnvCountsAsMissing(t *testing.T) {
 			"SMOOAI_CONFIG_API_URL":       "  ", // blank
 			"SMOOAI_CONFIG_CLIENT_ID":     "id",
 			"SMOOAI_CONFIG_CLIENT_SECRET": "sk",
-			"SMOOAI_CONFIG_ORG_ID":        "org",
+			"SMOOAI_CONFIG_ORG_ID":        "550e8400-e29b-41d4-a716-446655440000",
 			"SMOOAI_CONFIG_ENV":           "production",
 		},
 	})
@@ -320,7 +320,7 @@ func Test401_Refresh_Retry(t *testing.T) {
 		AuthURL:      auth.URL,
 		ClientID:     "cid",
 		ClientSecret: "csecret",
-		OrgID:        "org",
+		OrgID:        "550e8400-e29b-41d4-a716-446655440000",
 		Environment:  "production",
 		CacheTTL:     1 * time.Nanosecond, // force the GetValue read to bypass cache
 	})
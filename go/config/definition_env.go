@@ -0,0 +1,95 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// schemaKeyAliases returns the config keys a schema property may appear
+// under: its own name and its UPPER_SNAKE_CASE env var form ("apiUrl" and
+// "api_url" both become API_URL).
+func schemaKeyAliases(name string) []string {
+	upper := strings.ToUpper(name)
+	if !strings.Contains(name, "_") {
+		upper = CamelToUpperSnake(name)
+	}
+	if upper == name {
+		return []string{name}
+	}
+	return []string{name, upper}
+}
+
+// EnvSchema derives WithCMSchemaKeys / WithCMSchemaTypes maps from the tier
+// schemas: every declared property is picked up from env vars under its own
+// name and its UPPER_SNAKE_CASE form, and coerced by its JSON Schema type —
+// integer and number to "number", boolean to "boolean", arrays of strings,
+// numbers or booleans to "string[]", "number[]" or "boolean[]", and other
+// objects and arrays to "json". Properties typed string, or with no single type, get no hint and
+// are read as strings.
+func (d *ConfigDefinition) EnvSchema() (keys map[string]bool, types map[string]string) {
+	keys = make(map[string]bool)
+	types = make(map[string]string)
+	for _, schema := range []map[string]any{d.PublicSchema, d.SecretSchema, d.FeatureFlagSchema} {
+		v := &schemaValidator{root: schema}
+		props, _ := v.resolve(schema, 0)["properties"].(map[string]any)
+		for name, raw := range props {
+			typ := ""
+			if prop, ok := raw.(map[string]any); ok {
+				typ = envCoercionType(v.resolve(prop, 0))
+			}
+			for _, key := range schemaKeyAliases(name) {
+				keys[key] = true
+				if typ != "" {
+					types[key] = typ
+				}
+			}
+		}
+	}
+	return keys, types
+}
+
+// envCoercionType maps a property schema to the env coercion vocabulary.
+func envCoercionType(prop map[string]any) string {
+	var types []string
+	for _, t := range schemaTypeList(prop["type"]) {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	if len(types) != 1 {
+		return ""
+	}
+	switch types[0] {
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		// Arrays of scalars also read comma-separated lists and KEY_0,
+		// KEY_1 env vars.
+		items, _ := prop["items"].(map[string]any)
+		switch elem := envCoercionType(items); {
+		case elem == "number" || elem == "boolean":
+			return elem + "[]"
+		case slices.Equal(schemaTypeList(items["type"]), []string{"string"}):
+			return "string[]"
+		}
+		return "json"
+	case "object":
+		return "json"
+	}
+	return ""
+}
+
+// mergeEnvSchema adds def's derived keys and types to keys and types,
+// keeping any explicitly configured type.
+func mergeEnvSchema(def *ConfigDefinition, keys map[string]bool, types map[string]string) (map[string]bool, map[string]string) {
+	derivedKeys, derivedTypes := def.EnvSchema()
+	for key := range keys {
+		derivedKeys[key] = true
+	}
+	for key, typ := range types {
+		derivedTypes[key] = typ
+	}
+	return derivedKeys, derivedTypes
+}
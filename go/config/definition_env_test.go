@@ -0,0 +1,120 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type envSchemaPublic struct {
+	APIUrl     string            `json:"apiUrl"`
+	MaxRetries int               `json:"max_retries"`
+	Ratio      float64           `json:"ratio"`
+	Tags       []string          `json:"tags"`
+	Limits     map[string]int    `json:"limits"`
+	Nested     envSchemaNested   `json:"nested"`
+	Labels     map[string]string `json:"LABELS"`
+}
+
+type envSchemaNested struct {
+	Host string `json:"host"`
+}
+
+type envSchemaFlags struct {
+	NewUI bool `json:"newUi"`
+}
+
+func TestConfigDefinition_EnvSchema(t *testing.T) {
+	def, err := DefineConfigTyped(&envSchemaPublic{}, nil, &envSchemaFlags{})
+	require.NoError(t, err)
+
+	keys, types := def.EnvSchema()
+	for _, key := range []string{"apiUrl", "API_URL", "max_retries", "MAX_RETRIES", "ratio", "RATIO", "NEW_UI", "LABELS"} {
+		assert.True(t, keys[key], key)
+	}
+	assert.Equal(t, map[string]string{
+		"max_retries": "number", "MAX_RETRIES": "number",
+		"ratio": "number", "RATIO": "number",
+		"tags": "string[]", "TAGS": "string[]",
+		"limits": "json", "LIMITS": "json",
+		"nested": "json", "NESTED": "json",
+		"LABELS": "json",
+		"newUi":  "boolean", "NEW_UI": "boolean",
+	}, types)
+}
+
+func TestEnvCoercionType(t *testing.T) {
+	assert.Equal(t, "number", envCoercionType(map[string]any{"type": []any{"integer", "null"}}))
+	assert.Equal(t, "", envCoercionType(map[string]any{"type": []any{"integer", "string"}}))
+	assert.Equal(t, "", envCoercionType(map[string]any{"type": "string"}))
+	assert.Equal(t, "", envCoercionType(map[string]any{}))
+	assert.Equal(t, "number[]", envCoercionType(map[string]any{"type": "array", "items": map[string]any{"type": "integer"}}))
+	assert.Equal(t, "string[]", envCoercionType(map[string]any{"type": "array", "items": map[string]any{"type": "string"}}))
+	assert.Equal(t, "json", envCoercionType(map[string]any{"type": "array", "items": map[string]any{"type": "object"}}))
+	assert.Equal(t, "json", envCoercionType(map[string]any{"type": "array"}))
+}
+
+func TestConfigManager_WithCMDefinition(t *testing.T) {
+	def, err := DefineConfigTyped(&envSchemaPublic{}, nil, &envSchemaFlags{})
+	require.NoError(t, err)
+
+	newManager := func(env map[string]string) *ConfigManager {
+		env["SMOOAI_ENV_CONFIG_DIR"] = makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+			"API_URL": "https://api", "LIMITS": map[string]any{}, "NESTED": map[string]any{"host": "h"}, "LABELS": map[string]any{},
+		}})
+		return NewConfigManager(
+			WithCMDefinition(def),
+			WithCMSchemaKeys(map[string]bool{"EXTRA": true}),
+			WithCMSchemaTypes(map[string]string{"EXTRA": "number"}),
+			WithCMEnvOverride(env),
+		)
+	}
+	mgr := newManager(map[string]string{
+		"MAX_RETRIES": "5",
+		"RATIO":       "0.5",
+		"TAGS":        `["a","b"]`,
+		"NEW_UI":      "true",
+		"EXTRA":       "7",
+		"UNDECLARED":  "x",
+	})
+
+	// Env vars are read and coerced per the definition, alongside the
+	// explicit schema keys and types.
+	for key, want := range map[string]any{"MAX_RETRIES": 5, "RATIO": 0.5, "TAGS": []any{"a", "b"}, "EXTRA": 7, "UNDECLARED": nil} {
+		v, err := mgr.GetPublicConfig(key)
+		require.NoError(t, err)
+		assert.Equal(t, want, v, key)
+	}
+	flag, err := mgr.GetFeatureFlag("NEW_UI")
+	require.NoError(t, err)
+	assert.Equal(t, true, flag)
+
+	// Tiers come from the definition.
+	_, err = mgr.GetPublicConfig("NEW_UI")
+	assert.ErrorIs(t, err, ErrWrongTier)
+
+	// The merged config is validated against it.
+	_, err = newManager(map[string]string{"TAGS": `[1]`}).GetPublicConfig("API_URL")
+	assert.ErrorIs(t, err, ErrSchemaViolation)
+}
+
+func TestLocalConfigManager_WithDefinition(t *testing.T) {
+	def, err := DefineConfigTyped(&envSchemaPublic{}, nil, nil)
+	require.NoError(t, err)
+
+	mgr := NewLocalConfigManager(
+		WithDefinition(def),
+		WithEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+			"API_URL":               "https://api",
+			"MAX_RETRIES":           "3",
+		}),
+	)
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api", v)
+	v, err = mgr.GetPublicConfig("MAX_RETRIES")
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// diskCacheVersion is bumped when the on-disk layout changes; files with
+// another version are ignored.
+const diskCacheVersion = 1
+
+// diskCacheFile is the JSON document persisted by WithDiskCache. With an
+// encryption key the whole document is sealed with AES-256-GCM using the
+// baked-blob layout: nonce (12 bytes) || ciphertext || authTag (16 bytes).
+type diskCacheFile struct {
+	Version     int                   `json:"version"`
+	Environment string                `json:"environment"`
+	FetchedAt   time.Time             `json:"fetchedAt"`
+	Values      map[string]any        `json:"values"`
+	Tiers       map[string]ConfigTier `json:"tiers,omitempty"`
+}
+
+// WithDiskCache persists every successful remote fetch to path and, when a
+// later process cannot reach the API and has nothing fetched yet, serves the
+// persisted values as last-known-good. The file records when the values were
+// fetched; the staleness clock (see WithMaxStaleness) starts from that time,
+// so the budget holds across restarts. The file is written with 0600
+// permissions; add WithDiskCacheEncryptionKey when it holds secrets.
+func WithDiskCache(path string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.diskCachePath = path }
+}
+
+// WithDiskCacheEncryptionKey encrypts the WithDiskCache file with AES-256-GCM.
+// key must be 32 bytes. A file that fails to decrypt (e.g. after a key
+// rotation) is ignored and overwritten by the next successful fetch.
+func WithDiskCacheEncryptionKey(key []byte) ConfigManagerOption {
+	return func(m *ConfigManager) { m.diskCacheKey = key }
+}
+
+// saveDiskCacheLocked writes values to the disk cache. Failures are logged,
+// not returned: the cache is best-effort and must not break a good fetch.
+func (m *ConfigManager) saveDiskCacheLocked(environment string, values map[string]any, tiers map[string]ConfigTier) {
+	if m.diskCachePath == "" {
+		return
+	}
+	if err := writeDiskCache(m.diskCachePath, m.diskCacheKey, diskCacheFile{
+		Version:     diskCacheVersion,
+		Environment: environment,
+		FetchedAt:   time.Now().UTC(),
+		Values:      values,
+		Tiers:       tiers,
+	}); err != nil {
+		m.log().Warn("failed to write disk cache", "error", err)
+	}
+}
+
+// loadDiskCacheLocked returns the persisted values for environment, or nil
+// when there is no usable cache file.
+func (m *ConfigManager) loadDiskCacheLocked(environment string) *diskCacheFile {
+	if m.diskCachePath == "" {
+		return nil
+	}
+	cached, err := readDiskCache(m.diskCachePath, m.diskCacheKey)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			m.log().Warn("ignoring disk cache", "error", err)
+		}
+		return nil
+	}
+	if cached.Version != diskCacheVersion || cached.Environment != environment || cached.Values == nil {
+		return nil
+	}
+	return cached
+}
+
+func writeDiskCache(path string, key []byte, file diskCacheFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		gcm, err := diskCacheGCM(key)
+		if err != nil {
+			return err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return err
+		}
+		data = gcm.Seal(nonce, nonce, data, nil)
+	}
+
+	// Write-then-rename so a crash never leaves a torn file behind.
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func readDiskCache(path string, key []byte) (*diskCacheFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		gcm, err := diskCacheGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < gcm.NonceSize()+gcm.Overhead() {
+			return nil, fmt.Errorf("disk cache too short (%d bytes)", len(data))
+		}
+		data, err = gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+		if err != nil {
+			return nil, fmt.Errorf("disk cache decrypt: %w", err)
+		}
+	}
+	var file diskCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("disk cache unmarshal: %w", err)
+	}
+	return &file, nil
+}
+
+func diskCacheGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("disk cache key must be 32 bytes (got %d)", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigManager_DiskCacheServesAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "config.json")
+	server, failing := flakyRemote(t, map[string]any{"API_URL": "https://remote"})
+
+	first := newStalenessManager(t, server.URL, WithDiskCache(path))
+	v, err := first.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote", v)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A new process that cannot reach the API starts from the disk cache.
+	failing.Store(true)
+	second := newStalenessManager(t, server.URL, WithDiskCache(path), WithMaxStaleness(time.Hour))
+	v, err = second.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote", v)
+	assert.Greater(t, second.Staleness(), time.Duration(0))
+	assert.True(t, second.Health().IsHealthy())
+}
+
+func TestConfigManager_DiskCacheStalenessStartsAtFetchTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, writeDiskCache(path, nil, diskCacheFile{
+		Version:     diskCacheVersion,
+		Environment: "production",
+		FetchedAt:   time.Now().Add(-2 * time.Hour),
+		Values:      map[string]any{"API_URL": "https://cached"},
+	}))
+	server, failing := flakyRemote(t, nil)
+	failing.Store(true)
+
+	mgr := newStalenessManager(t, server.URL, WithDiskCache(path), WithMaxStaleness(time.Hour))
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://cached", v)
+	assert.False(t, mgr.Health().IsHealthy(), "two-hour-old cache exceeds a one-hour budget")
+}
+
+func TestConfigManager_DiskCacheIgnoresOtherEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, writeDiskCache(path, nil, diskCacheFile{
+		Version:     diskCacheVersion,
+		Environment: "staging",
+		FetchedAt:   time.Now(),
+		Values:      map[string]any{"API_URL": "https://staging"},
+	}))
+	server, failing := flakyRemote(t, nil)
+	failing.Store(true)
+
+	mgr := newStalenessManager(t, server.URL, WithDiskCache(path))
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestConfigManager_DiskCacheEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.bin")
+	key := bytes.Repeat([]byte{7}, 32)
+	server, failing := flakyRemote(t, map[string]any{"DB_PASSWORD": "hunter2"})
+
+	first := newStalenessManager(t, server.URL, WithDiskCache(path), WithDiskCacheEncryptionKey(key))
+	_, err := first.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "hunter2")
+
+	failing.Store(true)
+	second := newStalenessManager(t, server.URL, WithDiskCache(path), WithDiskCacheEncryptionKey(key))
+	v, err := second.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	// The wrong key ignores the file rather than failing the read.
+	third := newStalenessManager(t, server.URL, WithDiskCache(path), WithDiskCacheEncryptionKey(bytes.Repeat([]byte{8}, 32)))
+	v, err = third.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DoctorReport is what Doctor found out about where a manager's config
+// comes from. String formats it for a terminal.
+type DoctorReport struct {
+	// Environment is the config environment the files and remote fetch
+	// are for.
+	Environment string
+	// ConfigDir is the config directory, and ConfigDirVia how it was found
+	// (e.g. "SMOOAI_ENV_CONFIG_DIR"). ConfigDirErr says why none was.
+	ConfigDir    string
+	ConfigDirVia string
+	ConfigDirErr error
+	// Files are the config files for Environment in merge order, lowest
+	// precedence first, whether or not they exist.
+	Files []DoctorFile
+	// EnvVars are the env vars that set a schema key, or the key path of an
+	// alias, sorted by name.
+	EnvVars []DoctorEnvVar
+	// Remote is the remote API's credentials and connectivity.
+	Remote DoctorRemote
+}
+
+// DoctorFile is one config file of a DoctorReport.
+type DoctorFile struct {
+	Path string
+	// Loaded reports whether the file exists and parses. Err says why a
+	// file that exists didn't load.
+	Loaded bool
+	Err    error
+}
+
+// DoctorEnvVar is an env var that sets Key.
+type DoctorEnvVar struct {
+	Name string
+	Key  string
+}
+
+// DoctorRemote reports on the remote API.
+type DoctorRemote struct {
+	// Missing lists the settings the remote API needs but doesn't have, by
+	// their env var names. Empty means the remote layer is used.
+	Missing []string
+	BaseURL string
+	OrgID   string
+	// Probed reports whether a fetch was tried. It took Latency and
+	// returned Keys values, or failed with ProbeErr.
+	Probed   bool
+	Latency  time.Duration
+	Keys     int
+	ProbeErr error
+}
+
+// OK reports whether every source Doctor checked works: the config
+// directory was found with a default.json, every config file parses, and the
+// remote API, when configured, answered.
+func (r *DoctorReport) OK() bool {
+	if r.ConfigDirErr != nil {
+		return false
+	}
+	if len(r.Files) > 0 && !r.Files[0].Loaded {
+		return false // default.json is required
+	}
+	for _, f := range r.Files {
+		if f.Err != nil {
+			return false
+		}
+	}
+	return r.Remote.ProbeErr == nil
+}
+
+// Doctor reports how the manager finds its config: the config directory
+// and how it was found, which config files load and in what order, which
+// env vars match the schema, and whether the remote API is configured and
+// answers a fetch within ctx. It reads the sources afresh and leaves the
+// manager's merged config alone, so it can run on a manager that failed to
+// load.
+func (m *ConfigManager) Doctor(ctx context.Context) *DoctorReport {
+	env := m.envMap()
+	r := &DoctorReport{Environment: m.configEnvironment()}
+
+	if m.configFiles.fsys != nil {
+		r.ConfigDir, r.ConfigDirVia = m.configFiles.root, "WithCMConfigFS"
+	} else {
+		r.ConfigDir, r.ConfigDirVia, r.ConfigDirErr = locateConfigDir(true, env)
+	}
+	if r.ConfigDirErr == nil {
+		fsys, dir, shownDir, err := m.configFiles.resolve(env)
+		if err != nil {
+			r.ConfigDirErr = err
+		} else {
+			fileNames, namesErr := configFileNames(env)
+			for _, fileName := range fileNames {
+				name, data, err := readConfigFile(fsys, dir, fileName)
+				r.Files = append(r.Files, doctorFile(filepath.Join(shownDir, name), data, err))
+			}
+			if namesErr != nil {
+				r.Files = append(r.Files, DoctorFile{Path: shownDir, Err: namesErr})
+			}
+		}
+	}
+	if path := userOverridesPath(env); path != "" {
+		data, err := os.ReadFile(path)
+		r.Files = append(r.Files, doctorFile(path, data, err))
+	}
+
+	m.mu.Lock()
+	schemaKeys, _ := m.envSchemaLocked()
+	m.mu.Unlock()
+	for name := range env {
+		if key, _, ok := m.envNames.keyOf(name, func(key string) bool { return schemaKeys[key] }); ok {
+			r.EnvVars = append(r.EnvVars, DoctorEnvVar{Name: name, Key: key})
+		} else if path, ok := m.envNames.aliases[name]; ok {
+			r.EnvVars = append(r.EnvVars, DoctorEnvVar{Name: name, Key: path})
+		}
+	}
+	sort.Slice(r.EnvVars, func(i, j int) bool { return r.EnvVars[i].Name < r.EnvVars[j].Name })
+
+	m.doctorRemote(ctx, env, &r.Remote)
+	return r
+}
+
+func doctorFile(path string, data []byte, err error) DoctorFile {
+	if errors.Is(err, fs.ErrNotExist) {
+		return DoctorFile{Path: path}
+	}
+	if err == nil {
+		var parsed map[string]any
+		err = unmarshalJSONC(data, &parsed)
+	}
+	return DoctorFile{Path: path, Loaded: err == nil, Err: err}
+}
+
+// doctorRemote fills in r and, when the credentials are complete, probes
+// the API with a fetch of every value.
+func (m *ConfigManager) doctorRemote(ctx context.Context, env map[string]string, r *DoctorRemote) {
+	settings := []struct{ value, name string }{
+		{m.apiKey, "SMOOAI_CONFIG_API_KEY"},
+		{m.baseURL, "SMOOAI_CONFIG_API_URL"},
+		{m.orgID, "SMOOAI_CONFIG_ORG_ID"},
+	}
+	for _, s := range settings {
+		if s.value == "" && m.getEnvVal(s.name) == "" {
+			r.Missing = append(r.Missing, s.name)
+		}
+	}
+	client, configEnv, ok := m.newRemoteClient(env)
+	if !ok {
+		return
+	}
+	r.BaseURL, r.OrgID = client.baseURL, client.orgID
+
+	type result struct {
+		keys int
+		err  error
+	}
+	done := make(chan result, 1)
+	start := time.Now()
+	go func() {
+		defer client.Close()
+		values, err := client.GetAllValues(configEnv)
+		done <- result{len(values), err}
+	}()
+	r.Probed = true
+	select {
+	case res := <-done:
+		r.Keys, r.ProbeErr = res.keys, res.err
+	case <-ctx.Done():
+		r.ProbeErr = fmt.Errorf("no answer: %w", ctx.Err())
+	}
+	r.Latency = time.Since(start)
+}
+
+// String formats the report for a terminal.
+func (r *DoctorReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Environment: %s\n", r.Environment)
+	if r.ConfigDirErr != nil {
+		fmt.Fprintf(&b, "Config directory: not found: %v\n", r.ConfigDirErr)
+	} else {
+		fmt.Fprintf(&b, "Config directory: %s (from %s)\n", r.ConfigDir, r.ConfigDirVia)
+	}
+	if len(r.Files) > 0 {
+		b.WriteString("Config files, lowest precedence first:\n")
+		for _, f := range r.Files {
+			switch {
+			case f.Loaded:
+				fmt.Fprintf(&b, "  loaded   %s\n", f.Path)
+			case f.Err != nil:
+				fmt.Fprintf(&b, "  ERROR    %s: %v\n", f.Path, f.Err)
+			default:
+				fmt.Fprintf(&b, "  missing  %s\n", f.Path)
+			}
+		}
+	}
+	if len(r.EnvVars) == 0 {
+		b.WriteString("Env vars: none match the schema\n")
+	} else {
+		b.WriteString("Env vars that set schema keys:\n")
+		for _, v := range r.EnvVars {
+			if v.Name == v.Key {
+				fmt.Fprintf(&b, "  %s\n", v.Name)
+			} else {
+				fmt.Fprintf(&b, "  %s -> %s\n", v.Name, v.Key)
+			}
+		}
+	}
+	switch {
+	case len(r.Remote.Missing) > 0:
+		fmt.Fprintf(&b, "Remote API: not used, missing %s\n", strings.Join(r.Remote.Missing, ", "))
+	case r.Remote.ProbeErr != nil:
+		fmt.Fprintf(&b, "Remote API: %s (org %s) FAILED after %s: %v\n", r.Remote.BaseURL, r.Remote.OrgID, r.Remote.Latency.Round(time.Millisecond), r.Remote.ProbeErr)
+	case r.Remote.Probed:
+		fmt.Fprintf(&b, "Remote API: %s (org %s) returned %d values in %s\n", r.Remote.BaseURL, r.Remote.OrgID, r.Remote.Keys, r.Remote.Latency.Round(time.Millisecond))
+	}
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctor(t *testing.T) {
+	configDir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"BASE_KEY": "base"}})
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "test.json"), []byte("{broken"), 0o644))
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{"A": 1, "B": 2})
+	defer mock.close()
+
+	mgr := NewConfigManager(
+		WithCMSchemaKeys(map[string]bool{"API_URL": true}),
+		WithCMEnvPrefix("NEXT_PUBLIC_"),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR":        configDir,
+			"SMOOAI_CONFIG_ENV":            "test",
+			"SMOOAI_CONFIG_USER_OVERRIDES": "off",
+			"NEXT_PUBLIC_API_URL":          "https://env",
+			"UNRELATED":                    "x",
+			"SMOOAI_CONFIG_API_KEY":        "test-key",
+			"SMOOAI_CONFIG_API_URL":        mock.server.URL,
+			"SMOOAI_CONFIG_AUTH_URL":       mock.server.URL,
+			"SMOOAI_CONFIG_ORG_ID":         testOrgID,
+		}),
+	)
+	defer mgr.Close(context.Background())
+
+	r := mgr.Doctor(context.Background())
+	assert.Equal(t, "test", r.Environment)
+	assert.Equal(t, configDir, r.ConfigDir)
+	assert.Equal(t, "SMOOAI_ENV_CONFIG_DIR", r.ConfigDirVia)
+	require.Len(t, r.Files, 3)
+	assert.Equal(t, DoctorFile{Path: filepath.Join(configDir, "default.json"), Loaded: true}, r.Files[0])
+	assert.Equal(t, filepath.Join(configDir, "test.json"), r.Files[1].Path)
+	assert.Error(t, r.Files[1].Err)
+	assert.Equal(t, DoctorFile{Path: filepath.Join(configDir, "test.local.json")}, r.Files[2])
+	assert.Equal(t, []DoctorEnvVar{{Name: "NEXT_PUBLIC_API_URL", Key: "API_URL"}}, r.EnvVars)
+	assert.Empty(t, r.Remote.Missing)
+	assert.True(t, r.Remote.Probed)
+	assert.NoError(t, r.Remote.ProbeErr)
+	assert.Equal(t, 2, r.Remote.Keys)
+	assert.False(t, r.OK(), "test.json doesn't parse")
+	assert.Contains(t, r.String(), "ERROR    "+filepath.Join(configDir, "test.json"))
+	assert.Contains(t, r.String(), "NEXT_PUBLIC_API_URL -> API_URL")
+}
+
+func TestDoctor_MissingCredentials(t *testing.T) {
+	mgr := NewConfigManager(WithCMConfigFiles(map[string]string{"default.json": `{}`}), WithCMEnvOverride(map[string]string{
+		"SMOOAI_CONFIG_USER_OVERRIDES": "off",
+		"SMOOAI_CONFIG_ORG_ID":         testOrgID,
+	}))
+	defer mgr.Close(context.Background())
+
+	r := mgr.Doctor(context.Background())
+	assert.Equal(t, "WithCMConfigFS", r.ConfigDirVia)
+	assert.Equal(t, []string{"SMOOAI_CONFIG_API_KEY", "SMOOAI_CONFIG_API_URL"}, r.Remote.Missing)
+	assert.False(t, r.Remote.Probed)
+	assert.True(t, r.OK())
+	assert.Contains(t, r.String(), "Remote API: not used, missing SMOOAI_CONFIG_API_KEY, SMOOAI_CONFIG_API_URL")
+}
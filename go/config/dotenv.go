@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"strings"
+)
+
+// loadDotEnvFiles reads each .env file in order, later files overriding
+// earlier ones. Missing files are skipped, since .env.local and friends are
+// usually optional; unreadable or malformed files are logged and skipped.
+func loadDotEnvFiles(paths []string, log *slog.Logger) map[string]string {
+	env := make(map[string]string)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			log.Warn("failed to read .env file", "path", path, "error", err)
+			continue
+		}
+		vars, err := parseDotEnv(f)
+		f.Close()
+		if err != nil {
+			log.Warn("skipping .env file", "path", path, "error", err)
+			continue
+		}
+		maps.Copy(env, vars)
+	}
+	return env
+}
+
+// parseDotEnv parses KEY=VALUE lines. Blank lines and # comments are
+// ignored, an "export " prefix is allowed, single-quoted values are literal,
+// double-quoted values expand \n, \t, \" and \\, and unquoted values end at
+// " #". Variable references (${VAR}) are not expanded.
+func parseDotEnv(r io.Reader) (map[string]string, error) {
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNo)
+		}
+		value, err := dotEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		env[key] = value
+	}
+	return env, scanner.Err()
+}
+
+func dotEnvValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, "'"):
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", errors.New("unterminated ' quote")
+		}
+		return raw[1 : end+1], nil
+	case strings.HasPrefix(raw, `"`):
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			switch c := raw[i]; {
+			case c == '"':
+				return b.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case '"', '\\':
+					b.WriteByte(raw[i])
+				default:
+					b.WriteByte('\\')
+					b.WriteByte(raw[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", errors.New(`unterminated " quote`)
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// overlayDotEnv layers env over the .env values, so real env vars win.
+func overlayDotEnv(dotEnv, env map[string]string) map[string]string {
+	if len(dotEnv) == 0 {
+		return env
+	}
+	merged := maps.Clone(dotEnv)
+	maps.Copy(merged, env)
+	return merged
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	env, err := parseDotEnv(strings.NewReader(`
+# comment
+PLAIN=value
+export EXPORTED=yes
+SPACED = padded value   # trailing comment
+EMPTY=
+SINGLE='literal \n #not a comment'
+DOUBLE="line1\nline2 \"quoted\" \\ \d"
+URL=https://example.com/#anchor
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"PLAIN":    "value",
+		"EXPORTED": "yes",
+		"SPACED":   "padded value",
+		"EMPTY":    "",
+		"SINGLE":   `literal \n #not a comment`,
+		"DOUBLE":   "line1\nline2 \"quoted\" \\ \\d",
+		"URL":      "https://example.com/#anchor",
+	}, env)
+
+	for _, bad := range []string{"NO_EQUALS", "=value", `OPEN="unterminated`, "BAD KEY=x"} {
+		_, err := parseDotEnv(strings.NewReader(bad))
+		assert.ErrorContains(t, err, "line 1", bad)
+	}
+}
+
+func writeDotEnv(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadDotEnvFiles_LaterFilesWin(t *testing.T) {
+	dir := t.TempDir()
+	base := writeDotEnv(t, dir, ".env", "A=base\nB=base\n")
+	local := writeDotEnv(t, dir, ".env.local", "B=local\n")
+	broken := writeDotEnv(t, dir, ".env.broken", "C=ok\nnot a pair\n")
+
+	env := loadDotEnvFiles([]string{base, local, filepath.Join(dir, ".env.missing"), broken}, defaultLogger())
+	assert.Equal(t, map[string]string{"A": "base", "B": "local"}, env)
+}
+
+func TestConfigManager_WithCMDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	dotEnv := writeDotEnv(t, dir, ".env", "MAX_RETRIES=3\nAPI_URL=http://from-dotenv\nAPP_NAME=dotenv\n")
+
+	mgr := NewConfigManager(
+		WithCMDotEnv(dotEnv),
+		WithCMSchemaKeys(map[string]bool{"MAX_RETRIES": true, "API_URL": true, "APP_NAME": true}),
+		WithCMSchemaTypes(map[string]string{"MAX_RETRIES": "number"}),
+		WithCMEnvPrefix("NEXT_PUBLIC_"),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+			"API_URL":               "http://from-env",
+		}),
+	)
+
+	v, err := mgr.GetPublicConfig("MAX_RETRIES")
+	require.NoError(t, err)
+	assert.Equal(t, 3, v, "coerced like an env var")
+
+	v, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "http://from-env", v, "real env vars win")
+
+	v, err = mgr.GetPublicConfig("APP_NAME")
+	require.NoError(t, err)
+	assert.Equal(t, "dotenv", v)
+}
+
+func TestConfigManager_WithCMDotEnv_PrefixAndSettings(t *testing.T) {
+	dir := t.TempDir()
+	configDir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}})
+	dotEnv := writeDotEnv(t, dir, ".env", "NEXT_PUBLIC_API_URL=http://prefixed\nSMOOAI_ENV_CONFIG_DIR="+configDir+"\n")
+
+	mgr := NewConfigManager(
+		WithCMDotEnv(dotEnv),
+		WithCMSchemaKeys(map[string]bool{"API_URL": true}),
+		WithCMEnvPrefix("NEXT_PUBLIC_"),
+		WithCMEnvOverride(map[string]string{}),
+	)
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "http://prefixed", v)
+	assert.Equal(t, configDir, mgr.getEnvVal("SMOOAI_ENV_CONFIG_DIR"), "SDK settings can come from .env too")
+}
+
+func TestLocalConfigManager_WithDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	dotEnv := writeDotEnv(t, dir, ".env", "API_URL=http://from-dotenv\n")
+
+	mgr := NewLocalConfigManager(
+		WithDotEnv(dotEnv),
+		WithSchemaKeys(map[string]bool{"API_URL": true}),
+		WithEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+		}),
+	)
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "http://from-dotenv", v)
+}
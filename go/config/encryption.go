@@ -0,0 +1,223 @@
+package config
+
+// Client-side encryption for secret-tier values. The server stores an
+// envelope; only processes holding the key-encryption key can read the
+// value, so plaintext never reaches the API.
+//
+// An encrypted value is the string
+//
+//	smooenc:v1:<base64url of {"kid": "...", "dk": "<wrapped data key>", "ct": "<nonce || ciphertext>"}>
+//
+// where the data key is a fresh AES-256 key per value, wrapped by the
+// key-encryption key named kid, and ct is the JSON of the plain value
+// sealed with AES-256-GCM under the data key, with the config key's name as
+// additional data so an envelope can't be copied to another key.
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedValuePrefix marks a value as an envelope.
+const encryptedValuePrefix = "smooenc:v1:"
+
+// ErrDecryption matches any *DecryptionError via errors.Is.
+var ErrDecryption = errors.New("config value cannot be decrypted")
+
+// DecryptionError reports an encrypted secret that could not be decrypted:
+// no WithDecryptionKey, a key that doesn't unwrap it, or a damaged envelope.
+type DecryptionError struct {
+	Key string
+	Err error
+}
+
+func (e *DecryptionError) Error() string {
+	return fmt.Sprintf("[Smooai Config] Cannot decrypt %s: %v", e.Key, e.Err)
+}
+
+// Is supports errors.Is(err, ErrDecryption).
+func (e *DecryptionError) Is(target error) bool { return target == ErrDecryption }
+
+func (e *DecryptionError) Unwrap() error { return e.Err }
+
+// DecryptionKey unwraps the data keys of encrypted values. Implement it
+// over a KMS or age identity; LocalKey holds the key in process.
+type DecryptionKey interface {
+	// UnwrapKey returns the data key wrapped under the key named keyID.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// KeyWrapper wraps the data keys of values EncryptValue seals.
+type KeyWrapper interface {
+	// WrapKey wraps dataKey and returns the ID of the key it used.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+}
+
+// LocalKey is an AES-256 key-encryption key held in process. It both wraps
+// and unwraps data keys.
+type LocalKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+var (
+	_ DecryptionKey = (*LocalKey)(nil)
+	_ KeyWrapper    = (*LocalKey)(nil)
+)
+
+// NewLocalKey returns a LocalKey named id. key must be 32 bytes.
+func NewLocalKey(id string, key []byte) (*LocalKey, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, NewConfigError(fmt.Sprintf("local key %s: %v", id, err))
+	}
+	return &LocalKey{id: id, aead: aead}, nil
+}
+
+// WrapKey seals dataKey under k.
+func (k *LocalKey) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := sealGCM(k.aead, dataKey, []byte(k.id))
+	return k.id, wrapped, err
+}
+
+// UnwrapKey opens a data key k wrapped.
+func (k *LocalKey) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	if keyID != k.id {
+		return nil, fmt.Errorf("value is encrypted under key %q, not %q", keyID, k.id)
+	}
+	return openGCM(k.aead, wrapped, []byte(k.id))
+}
+
+// WithDecryptionKey decrypts encrypted secret-tier values with key when
+// they are read through GetSecretConfig (and Get). The merged config, the
+// disk cache and Snapshot keep the envelope; only the secret cache holds
+// the plain value. Without it, reading an encrypted secret fails with a
+// *DecryptionError.
+func WithDecryptionKey(key DecryptionKey) ConfigManagerOption {
+	return func(m *ConfigManager) { m.decryptionKey = key }
+}
+
+// decryptSecretLocked opens value, the merged value of secret key, when it
+// is encrypted. Must be called under m.mu.
+func (m *ConfigManager) decryptSecretLocked(key string, value any) (any, error) {
+	return decryptValue(context.Background(), key, value, m.decryptionKey)
+}
+
+// envelope is the JSON inside an encrypted value.
+type envelope struct {
+	KeyID      string `json:"kid"`
+	DataKey    []byte `json:"dk"`
+	Ciphertext []byte `json:"ct"`
+}
+
+// EncryptValue seals value, stored under key, for a secret-tier write such
+// as SetValues: the returned string is what the server stores.
+func EncryptValue(ctx context.Context, key string, value any, w KeyWrapper) (string, error) {
+	plain, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("config encrypt %s: %w", key, err)
+	}
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("config encrypt %s: %w", key, err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("config encrypt %s: %w", key, err)
+	}
+	ct, err := sealGCM(aead, plain, []byte(key))
+	if err != nil {
+		return "", fmt.Errorf("config encrypt %s: %w", key, err)
+	}
+	keyID, wrapped, err := w.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("config encrypt %s: wrap data key: %w", key, err)
+	}
+	data, err := json.Marshal(envelope{KeyID: keyID, DataKey: wrapped, Ciphertext: ct})
+	if err != nil {
+		return "", fmt.Errorf("config encrypt %s: %w", key, err)
+	}
+	return encryptedValuePrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// IsEncryptedValue reports whether value is an envelope from EncryptValue.
+func IsEncryptedValue(value any) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, encryptedValuePrefix)
+}
+
+// decryptValue opens value, stored under key, with dk. A value that isn't
+// an envelope is returned as is.
+func decryptValue(ctx context.Context, key string, value any, dk DecryptionKey) (any, error) {
+	if !IsEncryptedValue(value) {
+		return value, nil
+	}
+	fail := func(err error) (any, error) { return nil, &DecryptionError{Key: key, Err: err} }
+	if dk == nil {
+		return fail(errors.New("no decryption key (WithDecryptionKey)"))
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value.(string), encryptedValuePrefix))
+	if err != nil {
+		return fail(fmt.Errorf("malformed envelope: %w", err))
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return fail(fmt.Errorf("malformed envelope: %w", err))
+	}
+	dataKey, err := dk.UnwrapKey(ctx, env.KeyID, env.DataKey)
+	if err != nil {
+		return fail(fmt.Errorf("unwrap data key: %w", err))
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return fail(err)
+	}
+	plain, err := openGCM(aead, env.Ciphertext, []byte(key))
+	if err != nil {
+		return fail(err)
+	}
+	var out any
+	if err := json.Unmarshal(plain, &out); err != nil {
+		return fail(err)
+	}
+	return out, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes (got %d)", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealGCM encrypts plain as nonce || ciphertext.
+func sealGCM(aead cipher.AEAD, plain, ad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, ad), nil
+}
+
+// openGCM decrypts what sealGCM produced.
+func openGCM(aead cipher.AEAD, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("ciphertext too short")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], ad)
+	if err != nil {
+		return nil, errors.New("ciphertext does not decrypt under this key")
+	}
+	return plain, nil
+}
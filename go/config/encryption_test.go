@@ -0,0 +1,79 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocalKey(t *testing.T, id string, b byte) *LocalKey {
+	t.Helper()
+	key, err := NewLocalKey(id, bytes.Repeat([]byte{b}, 32))
+	require.NoError(t, err)
+	return key
+}
+
+func TestEncryptedSecrets(t *testing.T) {
+	ctx := context.Background()
+	key := newTestLocalKey(t, "kek-1", 1)
+	password, err := EncryptValue(ctx, "DB_PASSWORD", "hunter2", key)
+	require.NoError(t, err)
+	assert.True(t, IsEncryptedValue(password))
+	assert.NotContains(t, password, "hunter2")
+	creds, err := EncryptValue(ctx, "DB_CREDS", map[string]any{"user": "app", "port": 5432}, key)
+	require.NoError(t, err)
+	secrets := map[string]any{"DB_PASSWORD": password, "DB_CREDS": creds, "PLAIN": "not encrypted"}
+
+	mgr := NewStaticConfigManager(nil, secrets, nil, WithDecryptionKey(key))
+	v, err := mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+	v, err = mgr.GetSecretConfig("DB_CREDS")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"user": "app", "port": 5432.0}, v, "values round-trip through JSON")
+	v, err = mgr.GetSecretConfig("PLAIN")
+	require.NoError(t, err)
+	assert.Equal(t, "not encrypted", v)
+	plain, err := Get[string](mgr, "DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", plain)
+
+	snap, err := mgr.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, password, snap.Values["DB_PASSWORD"].Value, "the merged config keeps the envelope")
+
+	t.Run("no key", func(t *testing.T) {
+		_, err := NewStaticConfigManager(nil, secrets, nil).GetSecretConfig("DB_PASSWORD")
+		assert.ErrorIs(t, err, ErrDecryption)
+		var de *DecryptionError
+		require.ErrorAs(t, err, &de)
+		assert.Equal(t, "DB_PASSWORD", de.Key)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		for _, other := range []*LocalKey{newTestLocalKey(t, "kek-2", 1), newTestLocalKey(t, "kek-1", 2)} {
+			_, err := NewStaticConfigManager(nil, secrets, nil, WithDecryptionKey(other)).GetSecretConfig("DB_PASSWORD")
+			assert.ErrorIs(t, err, ErrDecryption)
+		}
+	})
+
+	t.Run("an envelope copied to another key fails", func(t *testing.T) {
+		moved := NewStaticConfigManager(nil, map[string]any{"API_TOKEN": password}, nil, WithDecryptionKey(key))
+		_, err := moved.GetSecretConfig("API_TOKEN")
+		assert.ErrorIs(t, err, ErrDecryption)
+	})
+
+	t.Run("a damaged envelope fails", func(t *testing.T) {
+		damaged := NewStaticConfigManager(nil, map[string]any{"DB_PASSWORD": encryptedValuePrefix + "!!"}, nil, WithDecryptionKey(key))
+		_, err := damaged.GetSecretConfig("DB_PASSWORD")
+		assert.ErrorIs(t, err, ErrDecryption)
+	})
+}
+
+func TestNewLocalKey_Length(t *testing.T) {
+	_, err := NewLocalKey("short", []byte("too short"))
+	assert.ErrorContains(t, err, "32 bytes")
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -12,61 +13,140 @@ func FindAndProcessEnvConfig(schemaKeys map[string]bool, prefix string, schemaTy
 }
 
 func findAndProcessEnvConfigWithEnv(schemaKeys map[string]bool, prefix string, schemaTypes map[string]string, env map[string]string) map[string]any {
-	result := make(map[string]any)
-	cloudRegion := GetCloudRegionFromEnv(env)
+	return findAndProcessEnvConfigWithBuiltins(schemaKeys, newEnvNames(prefix), schemaTypes, env, BuiltinPolicy{})
+}
 
-	envName := env["SMOOAI_CONFIG_ENV"]
-	if envName == "" {
-		envName = "development"
+// findAndProcessEnvConfigWithBuiltins is findAndProcessEnvConfigWithEnv with
+// env var names mapped per names and the built-in keys injected per policy.
+// When several names set one key, the rank envNames.keyOf gives decides.
+func findAndProcessEnvConfigWithBuiltins(schemaKeys map[string]bool, names envNames, schemaTypes map[string]string, env map[string]string, builtins BuiltinPolicy) map[string]any {
+	result := make(map[string]any)
+	ranks := make(map[string]int)
+	type element struct {
+		value string
+		rank  int
+	}
+	indexed := make(map[string]map[int]element)
+	isKey := func(key string) bool { return schemaKeys[key] }
+	isElement := func(key string) bool {
+		_, _, ok := listElementKey(key, schemaKeys, schemaTypes)
+		return ok
 	}
-	isLocal := CoerceBoolean(env["IS_LOCAL"])
-
-	for key, value := range env {
-		keyToUse := key
-		if prefix != "" && strings.HasPrefix(key, prefix) {
-			keyToUse = key[len(prefix):]
-		}
 
-		if !schemaKeys[keyToUse] {
+	for name, value := range env {
+		if key, rank, ok := names.keyOf(name, isKey); ok {
+			if r, set := ranks[key]; !set || rank < r {
+				result[key] = coerceEnvValue(schemaTypes[key], value)
+				ranks[key] = rank
+			}
 			continue
 		}
-
-		// Type coercion
-		if schemaTypes != nil {
-			if typ, ok := schemaTypes[keyToUse]; ok {
-				switch typ {
-				case "boolean":
-					result[keyToUse] = CoerceBoolean(value)
-					continue
-				case "number":
-					if strings.Contains(value, ".") {
-						if f, err := strconv.ParseFloat(value, 64); err == nil {
-							result[keyToUse] = f
-							continue
-						}
-					} else {
-						if n, err := strconv.Atoi(value); err == nil {
-							result[keyToUse] = n
-							continue
-						}
-					}
-				case "json", "object":
-					var parsed any
-					if err := json.Unmarshal([]byte(value), &parsed); err == nil {
-						result[keyToUse] = parsed
-						continue
-					}
-				}
+		// SERVERS_0, SERVERS_1... set the elements of a list key.
+		if key, rank, ok := names.keyOf(name, isElement); ok {
+			base, index, _ := listElementKey(key, schemaKeys, schemaTypes)
+			if indexed[base] == nil {
+				indexed[base] = make(map[int]element)
+			}
+			if e, set := indexed[base][index]; !set || rank < e.rank {
+				indexed[base][index] = element{value, rank}
 			}
 		}
-		result[keyToUse] = value
 	}
 
-	// Set built-in keys
-	result["ENV"] = envName
-	result["IS_LOCAL"] = isLocal
-	result["REGION"] = cloudRegion.Region
-	result["CLOUD_PROVIDER"] = cloudRegion.Provider
+	for key, elems := range indexed {
+		if _, ok := result[key]; ok {
+			continue // the whole list, set directly, wins
+		}
+		indices := make([]int, 0, len(elems))
+		for index := range elems {
+			indices = append(indices, index)
+		}
+		sort.Ints(indices)
+		elemType := strings.TrimSuffix(schemaTypes[key], "[]")
+		list := make([]any, len(indices))
+		for i, index := range indices {
+			list[i] = coerceEnvValue(elemType, elems[index].value)
+		}
+		result[key] = list
+	}
+
+	applyBuiltins(result, builtinValuesFromEnv(env), builtins, false)
 
 	return result
 }
+
+// listElementKey splits an env key such as SERVERS_0 into a schema key with
+// a list type ("string[]", "number[]" or "boolean[]") and an element index.
+func listElementKey(key string, schemaKeys map[string]bool, schemaTypes map[string]string) (string, int, bool) {
+	i := strings.LastIndexByte(key, '_')
+	if i <= 0 {
+		return "", 0, false
+	}
+	base := key[:i]
+	if !schemaKeys[base] || !isListType(schemaTypes[base]) {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(key[i+1:])
+	if err != nil || index < 0 || strconv.Itoa(index) != key[i+1:] {
+		return "", 0, false
+	}
+	return base, index, true
+}
+
+func isListType(typ string) bool {
+	switch typ {
+	case "string[]", "number[]", "boolean[]":
+		return true
+	}
+	return false
+}
+
+// coerceEnvValue converts a string from an env var (or another string-only
+// source) to its schema type: "boolean", "number", "json"/"object", or a
+// list type. A list is a JSON array or comma-separated elements ("a, b"),
+// each coerced to the element type. Values of other types, or that don't
+// parse, stay strings.
+func coerceEnvValue(typ, value string) any {
+	if isListType(typ) {
+		return coerceEnvList(strings.TrimSuffix(typ, "[]"), value)
+	}
+	switch typ {
+	case "boolean":
+		return CoerceBoolean(value)
+	case "number":
+		if strings.Contains(value, ".") {
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return f
+			}
+		} else if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	case "json", "object":
+		var parsed any
+		if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+			return parsed
+		}
+	}
+	return value
+}
+
+func coerceEnvList(elemType, value string) []any {
+	value = strings.TrimSpace(value)
+	var parsed []any
+	if strings.HasPrefix(value, "[") && json.Unmarshal([]byte(value), &parsed) == nil {
+		for i, elem := range parsed {
+			if s, ok := elem.(string); ok {
+				parsed[i] = coerceEnvValue(elemType, s)
+			}
+		}
+		return parsed
+	}
+	list := []any{}
+	if value == "" {
+		return list
+	}
+	for _, elem := range strings.Split(value, ",") {
+		list = append(list, coerceEnvValue(elemType, strings.TrimSpace(elem)))
+	}
+	return list
+}
@@ -58,6 +58,43 @@ func TestEnvConfig_CoercesJSON(t *testing.T) {
 	assert.Equal(t, 5432.0, db["port"])
 }
 
+func TestEnvConfig_CoercesLists(t *testing.T) {
+	schemaKeys := map[string]bool{"HOSTS": true, "PORTS": true, "FLAGS": true, "EMPTY": true, "TAGS": true}
+	schemaTypes := map[string]string{"HOSTS": "string[]", "PORTS": "number[]", "FLAGS": "boolean[]", "EMPTY": "string[]", "TAGS": "string[]"}
+	env := map[string]string{
+		"HOSTS": "a.example.com, b.example.com",
+		"PORTS": "[80, \"443\"]",
+		"FLAGS": "true,false",
+		"EMPTY": "",
+		"TAGS":  `["x,y", "z"]`,
+	}
+	result := findAndProcessEnvConfigWithEnv(schemaKeys, "", schemaTypes, env)
+	assert.Equal(t, []any{"a.example.com", "b.example.com"}, result["HOSTS"])
+	assert.Equal(t, []any{float64(80), 443}, result["PORTS"])
+	assert.Equal(t, []any{true, false}, result["FLAGS"])
+	assert.Equal(t, []any{}, result["EMPTY"])
+	assert.Equal(t, []any{"x,y", "z"}, result["TAGS"])
+}
+
+func TestEnvConfig_ListIndexSuffix(t *testing.T) {
+	schemaKeys := map[string]bool{"SERVERS": true, "PORTS": true, "NAME": true}
+	schemaTypes := map[string]string{"SERVERS": "string[]", "PORTS": "number[]"}
+	env := map[string]string{
+		"APP_SERVERS_1":  "b",
+		"APP_SERVERS_0":  "a",
+		"APP_SERVERS_10": "c",
+		"APP_SERVERS_x":  "ignored",
+		"APP_SERVERS_01": "ignored",
+		"PORTS":          "1,2",
+		"PORTS_0":        "9",
+		"NAME_0":         "not a list",
+	}
+	result := findAndProcessEnvConfigWithEnv(schemaKeys, "APP_", schemaTypes, env)
+	assert.Equal(t, []any{"a", "b", "c"}, result["SERVERS"], "elements in index order, gaps closed")
+	assert.Equal(t, []any{1, 2}, result["PORTS"], "the whole list wins over elements")
+	assert.NotContains(t, result, "NAME")
+}
+
 func TestEnvConfig_SetsBuiltinKeys(t *testing.T) {
 	env := map[string]string{"SMOOAI_CONFIG_ENV": "production", "AWS_REGION": "us-east-1"}
 	result := findAndProcessEnvConfigWithEnv(map[string]bool{}, "", nil, env)
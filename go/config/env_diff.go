@@ -0,0 +1,102 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+// EnvDiff is what differs between two environments' config, or two
+// snapshots. Each list is sorted by key.
+type EnvDiff struct {
+	// Added are keys only the second side sets.
+	Added []KeyDiff
+	// Removed are keys only the first side sets.
+	Removed []KeyDiff
+	// Changed are keys both sides set to different values.
+	Changed []KeyDiff
+}
+
+// KeyDiff is one key of an EnvDiff. Values are only shown for keys known to
+// be public or feature flags; others are masked with MaskSecret, so a
+// changed secret shows as changed without revealing either value.
+type KeyDiff struct {
+	Key  string
+	Tier ConfigTier
+	// From is the first side's value, nil when Added.
+	From any
+	// To is the second side's value, nil when Removed.
+	To any
+}
+
+// Empty reports whether the two sides are the same.
+func (d EnvDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffEnvironments fetches every value of envA and envB and returns what
+// changes going from envA to envB, e.g. DiffEnvironments("staging",
+// "production") to check for drift before a release.
+func (c *ConfigClient) DiffEnvironments(envA, envB string) (EnvDiff, error) {
+	a, err := c.GetAllValues(envA)
+	if err != nil {
+		return EnvDiff{}, err
+	}
+	b, err := c.GetAllValues(envB)
+	if err != nil {
+		return EnvDiff{}, err
+	}
+	tiers := c.KeyTiers(envA)
+	for key, tier := range c.KeyTiers(envB) {
+		if tiers == nil {
+			tiers = make(map[string]ConfigTier)
+		}
+		tiers[key] = tier
+	}
+	return diffValues(a, b, tiers), nil
+}
+
+// DiffSnapshots returns what changes going from snapshot a to snapshot b,
+// such as two managers' Snapshot for different environments.
+func DiffSnapshots(a, b *Snapshot) EnvDiff {
+	tiers := make(map[string]ConfigTier)
+	values := func(s *Snapshot) map[string]any {
+		out := make(map[string]any, len(s.Values))
+		for key, v := range s.Values {
+			out[key] = v.Value
+			if v.Tier.IsValid() {
+				tiers[key] = v.Tier
+			}
+		}
+		return out
+	}
+	return diffValues(values(a), values(b), tiers)
+}
+
+func diffValues(a, b map[string]any, tiers map[string]ConfigTier) EnvDiff {
+	show := func(key string, v any) any {
+		switch tiers[key] {
+		case TierPublic, TierFeatureFlag:
+			return v
+		}
+		return MaskSecret(v)
+	}
+	var d EnvDiff
+	for key, from := range a {
+		to, ok := b[key]
+		switch {
+		case !ok:
+			d.Removed = append(d.Removed, KeyDiff{Key: key, Tier: tiers[key], From: show(key, from)})
+		case !reflect.DeepEqual(from, to):
+			d.Changed = append(d.Changed, KeyDiff{Key: key, Tier: tiers[key], From: show(key, from), To: show(key, to)})
+		}
+	}
+	for key, to := range b {
+		if _, ok := a[key]; !ok {
+			d.Added = append(d.Added, KeyDiff{Key: key, Tier: tiers[key], To: show(key, to)})
+		}
+	}
+	for _, list := range [][]KeyDiff{d.Added, d.Removed, d.Changed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	}
+	return d
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigClient_DiffEnvironments(t *testing.T) {
+	envs := map[string]map[string]any{
+		"staging":    {"API_URL": "https://staging", "DB_PASSWORD": "staging-password-1", "NEW_KEY": "x", "SAME": 1.0},
+		"production": {"API_URL": "https://prod", "DB_PASSWORD": "production-password", "OLD_KEY": "y", "SAME": 1.0},
+	}
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"values": envs[r.URL.Query().Get("environment")],
+			"tiers":  map[string]string{"API_URL": "public", "DB_PASSWORD": "secret", "OLD_KEY": "public"},
+		})
+	})
+	defer server.Close()
+	client := newUnitClient(t, server.URL)
+
+	diff, err := client.DiffEnvironments("production", "staging")
+	require.NoError(t, err)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, []KeyDiff{{Key: "NEW_KEY", To: "****"}}, diff.Added)
+	assert.Equal(t, []KeyDiff{{Key: "OLD_KEY", Tier: TierPublic, From: "y"}}, diff.Removed)
+	assert.Equal(t, []KeyDiff{
+		{Key: "API_URL", Tier: TierPublic, From: "https://prod", To: "https://staging"},
+		{Key: "DB_PASSWORD", Tier: TierSecret, From: "pro****", To: "sta****"},
+	}, diff.Changed)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	a := &Snapshot{Values: map[string]SnapshotValue{"A": {Value: "1", Tier: TierPublic}, "B": {Value: []any{"x"}, Tier: TierPublic}}}
+	b := &Snapshot{Values: map[string]SnapshotValue{"A": {Value: "1", Tier: TierPublic}, "B": {Value: []any{"x"}, Tier: TierPublic}}}
+	assert.True(t, DiffSnapshots(a, b).Empty())
+
+	b.Values["B"] = SnapshotValue{Value: []any{"x", "y"}, Tier: TierPublic}
+	diff := DiffSnapshots(a, b)
+	assert.Equal(t, []KeyDiff{{Key: "B", Tier: TierPublic, From: []any{"x"}, To: []any{"x", "y"}}}, diff.Changed)
+}
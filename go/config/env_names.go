@@ -0,0 +1,111 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// envNames is how env var names map onto config keys: the prefixes
+// stripped from them (WithCMEnvPrefix) and the legacy names read in place
+// of a key (WithCMEnvAliases).
+type envNames struct {
+	prefixes []string
+	// aliases maps env var names to dotted key paths.
+	aliases map[string]string
+}
+
+// newEnvNames keeps the non-empty prefixes.
+func newEnvNames(prefixes ...string) envNames {
+	var n envNames
+	for _, p := range prefixes {
+		if p != "" {
+			n.prefixes = append(n.prefixes, p)
+		}
+	}
+	return n
+}
+
+// WithCMEnvAliases reads each env var in aliases as the key path it maps
+// to, so legacy names keep working during a migration without renaming them
+// in every deployment manifest:
+//
+//	config.WithCMEnvAliases(map[string]string{"PGHOST": "DATABASE.host", "REDIS_URL": "CACHE_URL"})
+//
+// A dotted path sets a key inside an object, matched and coerced like a
+// DATABASE__HOST env var (see WithCMEnvNestedSeparator). An alias loses to
+// an env var that sets the same key path under its own name.
+func WithCMEnvAliases(aliases map[string]string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.envNames.aliases = aliases }
+}
+
+// WithEnvAliases reads each env var in aliases as the key path it maps to.
+// See WithCMEnvAliases.
+func WithEnvAliases(aliases map[string]string) LocalConfigOption {
+	return func(m *LocalConfigManager) { m.envNames.aliases = aliases }
+}
+
+// keyOf returns the key env var name sets, stripping the first prefix that
+// leaves a key isKey accepts, and rank, which orders names that set the
+// same key: the first prefix listed wins and the bare name comes last.
+func (n envNames) keyOf(name string, isKey func(string) bool) (key string, rank int, ok bool) {
+	for i, p := range n.prefixes {
+		if strings.HasPrefix(name, p) && isKey(name[len(p):]) {
+			return name[len(p):], i, true
+		}
+	}
+	if isKey(name) {
+		return name, len(n.prefixes), true
+	}
+	return "", 0, false
+}
+
+// names lists the env var names that can set key.
+func (n envNames) names(key string) []string {
+	names := []string{key}
+	for _, p := range n.prefixes {
+		names = append(names, p+key)
+	}
+	return names
+}
+
+// applyEnvAliases sets the key path of each alias set in env in envConfig,
+// unless envConfig already has a value there. lower holds the layers below
+// env, highest first, to match nested keys and types against.
+func applyEnvAliases(envConfig map[string]any, env map[string]string, n envNames, schemaTypes map[string]string, lower []map[string]any) {
+	names := make([]string, 0, len(n.aliases))
+	for name := range n.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		raw, ok := env[name]
+		if !ok {
+			continue
+		}
+		path := strings.Split(n.aliases[name], ".")
+		if len(path) == 1 {
+			if _, set := envConfig[path[0]]; !set {
+				envConfig[path[0]] = coerceEnvValue(schemaTypes[path[0]], raw)
+			}
+			continue
+		}
+		if _, set := lookupPath(envConfig, path); !set {
+			setNestedEnv(envConfig, path, raw, lower)
+		}
+	}
+}
+
+// lookupPath returns the value at path in values.
+func lookupPath(values map[string]any, path []string) (any, bool) {
+	var v any = values
+	for _, part := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[part]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvConfig_MultiplePrefixes(t *testing.T) {
+	schemaKeys := map[string]bool{"API_URL": true, "MODE": true, "PORT": true}
+	env := map[string]string{
+		"NEXT_PUBLIC_API_URL": "next",
+		"VITE_API_URL":        "vite",
+		"API_URL":             "bare",
+		"VITE_MODE":           "vite",
+		"MODE":                "bare",
+		"PORT":                "80",
+	}
+	result := findAndProcessEnvConfigWithBuiltins(schemaKeys, newEnvNames("NEXT_PUBLIC_", "VITE_", ""), nil, env, BuiltinPolicy{})
+	assert.Equal(t, "next", result["API_URL"], "the first prefix listed wins")
+	assert.Equal(t, "vite", result["MODE"], "a prefixed name wins over the bare one")
+	assert.Equal(t, "80", result["PORT"])
+}
+
+func TestConfigManager_EnvAliases(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMConfigFiles(map[string]string{"default.json": `{"DATABASE": {"host": "localhost", "port": 5432}}`}),
+		WithCMSchemaKeys(map[string]bool{"DATABASE": true, "CACHE_URL": true, "API_URL": true}),
+		WithCMEnvPrefix("APP_", "NEXT_PUBLIC_"),
+		WithCMEnvAliases(map[string]string{
+			"PGHOST":    "DATABASE.host",
+			"PGPORT":    "DATABASE.port",
+			"REDIS_URL": "CACHE_URL",
+			"LEGACY":    "API_URL",
+		}),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_USER_OVERRIDES": "off",
+			"PGHOST":                       "pg.internal",
+			"PGPORT":                       "6543",
+			"REDIS_URL":                    "redis://cache",
+			"LEGACY":                       "old",
+			"NEXT_PUBLIC_API_URL":          "new",
+		}),
+	)
+	defer mgr.Close(context.Background())
+
+	db, err := mgr.GetPublicConfig("DATABASE")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "pg.internal", "port": 6543}, db)
+	cache, err := mgr.GetPublicConfig("CACHE_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "redis://cache", cache)
+	api, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "new", api, "the key's own name wins over an alias")
+}
+
+func TestConfigManager_EnvAliasesLoseToNestedNames(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMSchemaKeys(map[string]bool{"DATABASE": true}),
+		WithCMEnvAliases(map[string]string{"PGHOST": "DATABASE.host"}),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_USER_OVERRIDES": "off",
+			"PGHOST":                       "alias",
+			"DATABASE__HOST":               "nested",
+		}),
+	)
+	defer mgr.Close(context.Background())
+
+	db, err := mgr.GetPublicConfig("DATABASE")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "nested"}, db)
+}
+
+func TestConfigManager_EnvAliasesThroughEnvFunc(t *testing.T) {
+	env := map[string]string{"PGHOST": "pg.internal", "VITE_NAME": "svc"}
+	mgr := NewConfigManager(
+		WithCMSchemaKeys(map[string]bool{"DATABASE": true, "NAME": true}),
+		WithCMEnvPrefix("APP_", "VITE_"),
+		WithCMEnvAliases(map[string]string{"PGHOST": "DATABASE.host"}),
+		WithCMEnvFunc(func(key string) (string, bool) { v, ok := env[key]; return v, ok }),
+	)
+	defer mgr.Close(context.Background())
+
+	db, err := mgr.GetPublicConfig("DATABASE")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "pg.internal"}, db)
+	name, err := mgr.GetPublicConfig("NAME")
+	require.NoError(t, err)
+	assert.Equal(t, "svc", name)
+}
+
+func TestLocalConfigManager_EnvAliases(t *testing.T) {
+	mgr := NewLocalConfigManager(
+		WithConfigFS(fstest.MapFS{"default.json": {Data: []byte(`{}`)}}, "."),
+		WithSchemaKeys(map[string]bool{"CACHE_URL": true}),
+		WithEnvAliases(map[string]string{"REDIS_URL": "CACHE_URL"}),
+		WithEnvOverride(map[string]string{"SMOOAI_CONFIG_USER_OVERRIDES": "off", "REDIS_URL": "redis://cache"}),
+	)
+	v, err := mgr.GetPublicConfig("CACHE_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "redis://cache", v)
+}
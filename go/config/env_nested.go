@@ -0,0 +1,144 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// defaultEnvNestedSeparator splits env var names into a path of keys.
+const defaultEnvNestedSeparator = "__"
+
+// WithCMEnvNestedSeparator sets the separator that lets an env var set a
+// key inside an object, so DATABASE__HOST=db sets "host" in the DATABASE
+// object while the rest of DATABASE still comes from lower layers. The
+// first part must be a schema key; the inner parts match the existing keys
+// case-insensitively and ignoring _ and -, so POOL__MAX_SIZE sets
+// "maxSize", and are lowercased when no layer has them. A value is coerced
+// to the type of the value it replaces. Nested vars win over a var holding
+// the whole object, and a key a lower layer sets to something other than an
+// object can't be nested into. Defaults to "__"; "" turns nesting off. Not
+// available with WithCMEnvFunc, which can't list env vars.
+func WithCMEnvNestedSeparator(sep string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.envNestedSep = sep }
+}
+
+// applyNestedEnvLocked sets the keys env vars such as DATABASE__HOST name
+// in envConfig, then the aliases' keys (WithCMEnvAliases), matching the
+// keys of the layers below env. Must be called under m.mu.
+func (m *ConfigManager) applyNestedEnvLocked(env map[string]string, envConfig map[string]any, layers []configLayer) {
+	schemaKeys, schemaTypes := m.envSchemaLocked()
+	var lower []map[string]any // highest precedence first
+	for i := len(layers) - 1; i >= 0; i-- {
+		if layers[i].priority < PriorityEnv && layers[i].values != nil {
+			lower = append(lower, layers[i].values)
+		}
+	}
+	if m.envNestedSep != "" {
+		isNested := func(key string) bool {
+			parts := strings.Split(key, m.envNestedSep)
+			return len(parts) > 1 && !schemaKeys[key] && schemaKeys[parts[0]]
+		}
+		type nestedVar struct {
+			name, key string
+			rank      int
+		}
+		var vars []nestedVar
+		for name := range env {
+			if key, rank, ok := m.envNames.keyOf(name, isNested); ok {
+				vars = append(vars, nestedVar{name, key, rank})
+			}
+		}
+		// Set the lowest-ranked names first, so the names that win are
+		// set last.
+		sort.Slice(vars, func(i, j int) bool {
+			if vars[i].rank != vars[j].rank {
+				return vars[i].rank > vars[j].rank
+			}
+			return vars[i].name < vars[j].name
+		})
+		for _, v := range vars {
+			setNestedEnv(envConfig, strings.Split(v.key, m.envNestedSep), env[v.name], lower)
+		}
+	}
+	applyEnvAliases(envConfig, env, m.envNames, schemaTypes, lower)
+}
+
+// setNestedEnv sets the value at parts in target, creating objects on the
+// way. lower holds the lower layers' objects at the same path.
+func setNestedEnv(target map[string]any, parts []string, raw string, lower []map[string]any) {
+	for i, part := range parts {
+		if part == "" {
+			return
+		}
+		key := part
+		if i > 0 {
+			key = matchNestedKey(part, lower)
+		}
+		var (
+			existing any
+			found    bool
+			next     []map[string]any
+		)
+		for _, l := range lower {
+			v, ok := l[key]
+			if !ok {
+				continue
+			}
+			if !found {
+				existing, found = v, true
+			}
+			if obj, ok := v.(map[string]any); ok {
+				next = append(next, obj)
+			}
+		}
+		if i == len(parts)-1 {
+			target[key] = coerceEnvValue(envTypeOf(existing), raw)
+			return
+		}
+		if _, isObj := existing.(map[string]any); found && !isObj {
+			return // a lower layer sets the key to something other than an object
+		}
+		obj, ok := target[key].(map[string]any)
+		if !ok {
+			if _, set := target[key]; set {
+				return // an env var sets it to something other than an object
+			}
+			obj = make(map[string]any)
+			target[key] = obj
+		}
+		target, lower = obj, next
+	}
+}
+
+// matchNestedKey finds the key in lower that part names, such as "maxSize"
+// for MAX_SIZE, or lowercases part when none does.
+func matchNestedKey(part string, lower []map[string]any) string {
+	normalize := strings.NewReplacer("_", "", "-", "")
+	want := strings.ToLower(normalize.Replace(part))
+	for _, l := range lower {
+		keys := make([]string, 0, len(l))
+		for k := range l {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if strings.ToLower(normalize.Replace(k)) == want {
+				return k
+			}
+		}
+	}
+	return strings.ToLower(part)
+}
+
+// envTypeOf returns the coerceEnvValue type that keeps v's type.
+func envTypeOf(v any) string {
+	switch v.(type) {
+	case bool:
+		return "boolean"
+	case float64, float32, int, int64, int32:
+		return "number"
+	case map[string]any, []any:
+		return "json"
+	}
+	return ""
+}
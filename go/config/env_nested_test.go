@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigManager_NestedEnvVars(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMConfigFiles(map[string]string{"default.json": `{
+			"DATABASE": {"host": "localhost", "port": 5432, "ssl": false, "pool": {"maxSize": 10}},
+			"NAME": "svc"
+		}`}),
+		WithCMSchemaKeys(map[string]bool{"DATABASE": true, "CACHE": true, "NAME": true}),
+		WithCMEnvPrefix("APP_"),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_USER_OVERRIDES": "off",
+			"APP_DATABASE__HOST":           "db.internal",
+			"DATABASE__PORT":               "6543",
+			"DATABASE__SSL":                "true",
+			"DATABASE__POOL__MAX_SIZE":     "50",
+			"CACHE__TTL":                   "60",
+			"NAME__X":                      "ignored",
+			"UNKNOWN__X":                   "ignored",
+			"DATABASE____HOST":             "ignored",
+		}),
+	)
+	defer mgr.Close(context.Background())
+
+	db, err := mgr.GetPublicConfig("DATABASE")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"host": "db.internal",
+		"port": 6543,
+		"ssl":  true,
+		"pool": map[string]any{"maxSize": 50},
+	}, db)
+
+	cache, err := mgr.GetPublicConfig("CACHE")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"ttl": "60"}, cache, "keys no layer has are lowercased, values stay strings")
+
+	name, err := mgr.GetPublicConfig("NAME")
+	require.NoError(t, err)
+	assert.Equal(t, "svc", name, "a non-object key isn't replaced")
+	ok, err := mgr.Has("UNKNOWN")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestConfigManager_NestedEnvVarsOverWholeObject(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMSchemaKeys(map[string]bool{"DATABASE": true}),
+		WithCMSchemaTypes(map[string]string{"DATABASE": "json"}),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_USER_OVERRIDES": "off",
+			"DATABASE":                     `{"host": "a", "port": 1}`,
+			"DATABASE__HOST":               "b",
+		}),
+	)
+	defer mgr.Close(context.Background())
+
+	db, err := mgr.GetPublicConfig("DATABASE")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "b", "port": float64(1)}, db)
+}
+
+func TestConfigManager_NestedEnvVarsSeparator(t *testing.T) {
+	env := map[string]string{"SMOOAI_CONFIG_USER_OVERRIDES": "off", "DATABASE__HOST": "a", "DATABASE_DOT_HOST": "b"}
+	for _, tc := range []struct {
+		sep  string
+		want any
+	}{
+		{"", nil},
+		{"_DOT_", map[string]any{"host": "b"}},
+	} {
+		mgr := NewConfigManager(
+			WithCMSchemaKeys(map[string]bool{"DATABASE": true}),
+			WithCMEnvNestedSeparator(tc.sep),
+			WithCMEnvOverride(env),
+		)
+		db, err := mgr.GetPublicConfig("DATABASE")
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, db, "separator %q", tc.sep)
+		mgr.Close(context.Background())
+	}
+}
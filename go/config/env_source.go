@@ -0,0 +1,62 @@
+package config
+
+import "maps"
+
+// EnvFunc looks up an environment variable, like os.LookupEnv. Pass one to
+// WithCMEnvFunc or WithEnvFunc to source env vars from somewhere other than
+// the process environment.
+type EnvFunc func(key string) (string, bool)
+
+// sdkEnvVars lists every variable the loaders read by name. An EnvFunc
+// can't be enumerated, so envFromFunc asks it for exactly these plus the
+// schema keys. Keep in sync when a loader starts reading a new variable.
+var sdkEnvVars = []string{
+	"SMOOAI_CONFIG_API_URL", "SMOOAI_CONFIG_API_KEY", "SMOOAI_CONFIG_CLIENT_ID", "SMOOAI_CONFIG_CLIENT_SECRET",
+	"SMOOAI_CONFIG_ORG_ID", "SMOOAI_CONFIG_AUTH_URL", "SMOOAI_AUTH_URL",
+	"SMOOAI_CONFIG_ENV", "SMOOAI_CONFIG_ENV_INFERENCE", "SMOOAI_ENV_CONFIG_DIR", "SMOOAI_CONFIG_LEVELS_UP_LIMIT",
+	"IS_LOCAL",
+	"SMOOAI_CONFIG_RING", "RING", "DEPLOYMENT_STAGE",
+	"SMOOAI_CONFIG_CLOUD_PROVIDER", "SMOOAI_CONFIG_CLOUD_REGION",
+	"AWS_REGION", "AWS_DEFAULT_REGION", "AZURE_REGION", "AZURE_LOCATION", "GOOGLE_CLOUD_REGION", "CLOUDSDK_COMPUTE_REGION",
+	"SMOOAI_CONFIG_RUNTIME", "AWS_LAMBDA_FUNCTION_NAME", "ECS_CONTAINER_METADATA_URI", "ECS_CONTAINER_METADATA_URI_V4",
+	"K_SERVICE", "FUNCTIONS_WORKER_RUNTIME", "KUBERNETES_SERVICE_HOST",
+	"SMOOAI_CONFIG_AZ", "AVAILABILITY_ZONE",
+	"SMOO_CONFIG_KEY", "SMOO_CONFIG_KEY_FILE",
+	"SMOOAI_CONFIG_USER_OVERRIDES", "XDG_CONFIG_HOME", "HOME",
+}
+
+// envFromFunc snapshots the variables the loaders can read from fn: the
+// SDK's own variables, the platform variables environment inference reads,
+// every schema key both bare and with each prefix, and every alias.
+func envFromFunc(fn EnvFunc, schemaKeys map[string]bool, names envNames) map[string]string {
+	env := make(map[string]string)
+	lookup := func(key string) {
+		if v, ok := fn(key); ok {
+			env[key] = v
+		}
+	}
+	for _, key := range sdkEnvVars {
+		lookup(key)
+	}
+	for _, key := range inferredEnvVars {
+		lookup(key)
+	}
+	for key := range schemaKeys {
+		for _, name := range names.names(key) {
+			lookup(name)
+		}
+	}
+	for name := range names.aliases {
+		lookup(name)
+	}
+	return env
+}
+
+// cloneEnv copies an env override map so later writes by the caller can't
+// race with reads by the manager.
+func cloneEnv(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	return maps.Clone(env)
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCMEnvOverride_CopiesMap(t *testing.T) {
+	env := map[string]string{"API_URL": "from-env"}
+	mgr := NewConfigManager(WithCMEnvOverride(env), WithCMSchemaKeys(map[string]bool{"API_URL": true}))
+	env["API_URL"] = "mutated"
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", v)
+}
+
+func TestWithCMEnvFunc(t *testing.T) {
+	var mu sync.Mutex
+	values := map[string]string{
+		"SMOOAI_CONFIG_ENV": "staging",
+		"APP_API_URL":       "https://staging",
+		"UNDECLARED":        "ignored",
+	}
+	lookup := func(key string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		v, ok := values[key]
+		return v, ok
+	}
+	mgr := NewConfigManager(
+		WithCMEnvFunc(lookup),
+		WithCMEnvOverride(map[string]string{"APP_API_URL": "loses"}),
+		WithCMSchemaKeys(map[string]bool{"API_URL": true}),
+		WithCMEnvPrefix("APP_"),
+	)
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging", v)
+	assert.Equal(t, "staging", mgr.configEnvironment())
+	has, err := mgr.Has("UNDECLARED")
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	// The func is re-read on every load.
+	mu.Lock()
+	values["APP_API_URL"] = "https://rotated"
+	mu.Unlock()
+	mgr.Invalidate()
+	v, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://rotated", v)
+}
+
+func TestWithEnvFunc_LocalConfigManager(t *testing.T) {
+	env := map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+		"MAX_RETRIES":           "7",
+	}
+	mgr := NewLocalConfigManager(
+		WithEnvFunc(func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		}),
+		WithSchemaKeys(map[string]bool{"MAX_RETRIES": true}),
+		WithSchemaTypes(map[string]string{"MAX_RETRIES": "number"}),
+	)
+	v, err := mgr.GetPublicConfig("MAX_RETRIES")
+	require.NoError(t, err)
+	assert.Equal(t, 7, v)
+}
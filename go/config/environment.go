@@ -0,0 +1,47 @@
+package config
+
+import "strings"
+
+// defaultEnvironment is used when neither SMOOAI_CONFIG_ENV nor any inferred
+// platform variable names an environment.
+const defaultEnvironment = "development"
+
+// inferredEnvVars are the platform variables GetConfigEnvironmentFromEnv
+// falls back to, highest precedence first. NODE_ENV comes last: Node builds
+// routinely set it to "production" for optimizations even when deploying to
+// staging, so any more specific variable wins over it.
+var inferredEnvVars = []string{"APP_ENV", "RAILS_ENV", "DD_ENV", "ENVIRONMENT", "NODE_ENV"}
+
+// GetConfigEnvironment resolves the config environment from os environment
+// variables. See GetConfigEnvironmentFromEnv.
+func GetConfigEnvironment() string {
+	return GetConfigEnvironmentFromEnv(osEnvMap())
+}
+
+// GetConfigEnvironmentFromEnv resolves the config environment from a
+// provided env map, so services that already name their environment for
+// another tool don't need to repeat it for this SDK.
+//
+// Detection order:
+//  1. SMOOAI_CONFIG_ENV
+//  2. APP_ENV
+//  3. RAILS_ENV
+//  4. DD_ENV
+//  5. ENVIRONMENT
+//  6. NODE_ENV
+//  7. Default: "development"
+//
+// Setting SMOOAI_CONFIG_ENV_INFERENCE=false skips steps 2-6.
+func GetConfigEnvironmentFromEnv(env map[string]string) string {
+	if v := strings.TrimSpace(env["SMOOAI_CONFIG_ENV"]); v != "" {
+		return v
+	}
+	if v := strings.TrimSpace(env["SMOOAI_CONFIG_ENV_INFERENCE"]); v == "" || CoerceBoolean(v) {
+		for _, name := range inferredEnvVars {
+			if v := strings.TrimSpace(env[name]); v != "" {
+				return v
+			}
+		}
+	}
+	return defaultEnvironment
+}
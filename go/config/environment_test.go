@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConfigEnvironmentFromEnv(t *testing.T) {
+	cases := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"default", map[string]string{}, "development"},
+		{"explicit wins", map[string]string{"SMOOAI_CONFIG_ENV": "staging", "APP_ENV": "production"}, "staging"},
+		{"explicit trimmed", map[string]string{"SMOOAI_CONFIG_ENV": " staging\n"}, "staging"},
+		{"APP_ENV", map[string]string{"APP_ENV": "qa", "RAILS_ENV": "production", "NODE_ENV": "production"}, "qa"},
+		{"RAILS_ENV", map[string]string{"RAILS_ENV": "staging", "DD_ENV": "prod"}, "staging"},
+		{"DD_ENV", map[string]string{"DD_ENV": "prod", "ENVIRONMENT": "x"}, "prod"},
+		{"ENVIRONMENT", map[string]string{"ENVIRONMENT": "sandbox", "NODE_ENV": "production"}, "sandbox"},
+		{"NODE_ENV last", map[string]string{"NODE_ENV": "test"}, "test"},
+		{"blank skipped", map[string]string{"APP_ENV": "  ", "NODE_ENV": "production"}, "production"},
+		{"opt-out", map[string]string{"SMOOAI_CONFIG_ENV_INFERENCE": "false", "APP_ENV": "qa"}, "development"},
+		{"opt-out keeps explicit", map[string]string{"SMOOAI_CONFIG_ENV_INFERENCE": "0", "SMOOAI_CONFIG_ENV": "qa"}, "qa"},
+		{"opt-in explicit", map[string]string{"SMOOAI_CONFIG_ENV_INFERENCE": "true", "APP_ENV": "qa"}, "qa"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, GetConfigEnvironmentFromEnv(tc.env))
+		})
+	}
+}
+
+func TestConfigManager_InfersEnvironment(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{
+			"default.json": map[string]any{"API_URL": "default"},
+			"qa.json":      map[string]any{"API_URL": "qa"},
+		}),
+		"APP_ENV": "qa",
+	}))
+	v, err := mgr.GetPublicConfig("API_URL")
+	assert.NoError(t, err)
+	assert.Equal(t, "qa", v)
+	assert.Equal(t, "qa", mgr.configEnvironment())
+}
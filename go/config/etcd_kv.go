@@ -0,0 +1,296 @@
+package config
+
+// etcd v3 store, through etcd's JSON gateway (/v3/kv/range, /v3/watch), so
+// it needs no gRPC client. Watches stream from the revision last seen, so no
+// change between two loads is missed.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// EtcdOption configures EtcdKV.
+type EtcdOption func(*EtcdKV)
+
+// WithEtcdEndpoint sets the endpoint instead of ETCD_ENDPOINTS, e.g.
+// "https://etcd.internal:2379". A bare host:port means http.
+func WithEtcdEndpoint(endpoint string) EtcdOption {
+	return func(e *EtcdKV) { e.endpoint = endpoint }
+}
+
+// WithEtcdAuth authenticates as user, for clusters with auth enabled.
+func WithEtcdAuth(user, password string) EtcdOption {
+	return func(e *EtcdKV) { e.user, e.password = user, password }
+}
+
+// WithEtcdEnv reads ETCD_* variables through lookup instead of the process
+// environment.
+func WithEtcdEnv(lookup EnvFunc) EtcdOption {
+	return func(e *EtcdKV) { e.lookupEnv = lookup }
+}
+
+// WithEtcdHTTPClient sets the HTTP client. It must not have a timeout,
+// since a watch stays open until a change.
+func WithEtcdHTTPClient(client *http.Client) EtcdOption {
+	return func(e *EtcdKV) { e.client = client }
+}
+
+// EtcdKV reads config from etcd v3. Use it as a KVStore with WithCMKVStore,
+// and as a RemoteProvider for references to single keys:
+//
+//	smooai-secret://etcd/myapp/prod/DB_PASSWORD
+type EtcdKV struct {
+	endpoint  string
+	user      string
+	password  string
+	client    *http.Client
+	lookupEnv EnvFunc
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewEtcdKV creates an etcd store. The endpoint defaults to the first of
+// ETCD_ENDPOINTS, then 127.0.0.1:2379.
+func NewEtcdKV(opts ...EtcdOption) *EtcdKV {
+	e := &EtcdKV{client: http.DefaultClient, lookupEnv: os.LookupEnv}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.endpoint == "" {
+		endpoints, _ := e.lookupEnv("ETCD_ENDPOINTS")
+		e.endpoint, _, _ = strings.Cut(endpoints, ",")
+	}
+	if e.endpoint == "" {
+		e.endpoint = "127.0.0.1:2379"
+	}
+	if !strings.Contains(e.endpoint, "://") {
+		e.endpoint = "http://" + e.endpoint
+	}
+	e.endpoint = strings.TrimRight(e.endpoint, "/")
+	return e
+}
+
+type etcdHeader struct {
+	Revision uint64 `json:"revision,string"`
+}
+
+type etcdRangeResponse struct {
+	Header etcdHeader `json:"header"`
+	KVs    []struct {
+		Key   []byte `json:"key"`   // base64 in JSON
+		Value []byte `json:"value"` // base64 in JSON
+	} `json:"kvs"`
+}
+
+// List implements KVStore.
+func (e *EtcdKV) List(ctx context.Context, prefix string) (map[string]string, uint64, error) {
+	var out etcdRangeResponse
+	if err := e.call(ctx, "/v3/kv/range", etcdPrefixRange(prefix), &out); err != nil {
+		return nil, 0, err
+	}
+	values := make(map[string]string, len(out.KVs))
+	for _, kv := range out.KVs {
+		values[strings.TrimPrefix(string(kv.Key), prefix)] = string(kv.Value)
+	}
+	return values, out.Header.Revision, nil
+}
+
+// Wait implements KVStore with a watch from the revision after index.
+func (e *EtcdKV) Wait(ctx context.Context, prefix string, index uint64) (uint64, error) {
+	if index == 0 {
+		var out etcdRangeResponse
+		req := etcdPrefixRange(prefix)
+		req["count_only"] = true
+		if err := e.call(ctx, "/v3/kv/range", req, &out); err != nil {
+			return 0, err
+		}
+		return out.Header.Revision, nil
+	}
+
+	watch := etcdPrefixRange(prefix)
+	watch["start_revision"] = index + 1
+	resp, err := e.send(ctx, "/v3/watch", map[string]any{"create_request": watch})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Result struct {
+				Header   etcdHeader        `json:"header"`
+				Events   []json.RawMessage `json:"events"`
+				Canceled bool              `json:"canceled"`
+				Reason   string            `json:"cancel_reason"`
+			} `json:"result"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, fmt.Errorf("etcd: watch: %w", err)
+		}
+		switch {
+		case len(msg.Result.Events) > 0:
+			return msg.Result.Header.Revision, nil
+		case msg.Result.Canceled:
+			// Usually the revision was compacted away. Reporting the
+			// current one reloads the keys and watches on from there.
+			if msg.Result.Header.Revision > index {
+				return msg.Result.Header.Revision, nil
+			}
+			return 0, fmt.Errorf("etcd: watch canceled: %s", msg.Result.Reason)
+		}
+	}
+}
+
+// Handles reports whether ref names an etcd key.
+func (e *EtcdKV) Handles(ref string) bool {
+	return strings.HasPrefix(ref, "etcd/")
+}
+
+// Resolve returns the value of the key ref names.
+func (e *EtcdKV) Resolve(ctx context.Context, ref string) (string, error) {
+	key := strings.TrimPrefix(ref, "etcd/")
+	var out etcdRangeResponse
+	if err := e.call(ctx, "/v3/kv/range", map[string]any{"key": []byte(key)}, &out); err != nil {
+		return "", err
+	}
+	if len(out.KVs) == 0 {
+		return "", fmt.Errorf("etcd: key %s not found", key)
+	}
+	return string(out.KVs[0].Value), nil
+}
+
+// etcdPrefixRange is the key range covering every key under prefix.
+func etcdPrefixRange(prefix string) map[string]any {
+	if prefix == "" {
+		return map[string]any{"key": []byte{0}, "range_end": []byte{0}}
+	}
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return map[string]any{"key": []byte(prefix), "range_end": end[:i+1]}
+		}
+	}
+	// All 0xff: there is no key after the prefix, so range to the end.
+	return map[string]any{"key": []byte(prefix), "range_end": []byte{0}}
+}
+
+// call posts in to path and decodes the response into out.
+func (e *EtcdKV) call(ctx context.Context, path string, in, out any) error {
+	resp, err := e.send(ctx, path, in)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("etcd: decoding response: %w", err)
+	}
+	return nil
+}
+
+// send posts in to path, authenticating first when WithEtcdAuth is set and
+// again once if the token has expired. The caller closes the body of the
+// 200 response.
+func (e *EtcdKV) send(ctx context.Context, path string, in any) (*http.Response, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		token, err := e.authToken(ctx, attempt > 0)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := e.post(ctx, path, body, token)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusUnauthorized && e.user != "" && attempt == 0 {
+			continue
+		}
+		return nil, etcdError(resp.StatusCode, data)
+	}
+}
+
+func (e *EtcdKV) post(ctx context.Context, path string, body []byte, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return e.client.Do(req)
+}
+
+// authToken returns the auth token, fetching one when there is none yet or
+// refresh is set. Empty without WithEtcdAuth.
+func (e *EtcdKV) authToken(ctx context.Context, refresh bool) (string, error) {
+	if e.user == "" {
+		return "", nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.token != "" && !refresh {
+		return e.token, nil
+	}
+	body, err := json.Marshal(map[string]string{"name": e.user, "password": e.password})
+	if err != nil {
+		return "", err
+	}
+	resp, err := e.post(ctx, "/v3/auth/authenticate", body, "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", etcdError(resp.StatusCode, data)
+	}
+	var out struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("etcd: decoding auth response: %w", err)
+	}
+	e.token = out.Token
+	return e.token, nil
+}
+
+// etcdError formats a gateway error response, {"error": ..., "code": N}.
+func etcdError(status int, body []byte) error {
+	var e struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	_ = json.Unmarshal(body, &e)
+	msg := e.Message
+	if msg == "" {
+		msg = e.Error
+	}
+	if msg == "" {
+		msg = strings.TrimSpace(string(body))
+	}
+	return fmt.Errorf("etcd: HTTP %d: %s", status, msg)
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEtcdPrefixRange(t *testing.T) {
+	assert.Equal(t, map[string]any{"key": []byte("app/"), "range_end": []byte("app0")}, etcdPrefixRange("app/"))
+	assert.Equal(t, map[string]any{"key": []byte("a\xff"), "range_end": []byte("b")}, etcdPrefixRange("a\xff"))
+	assert.Equal(t, map[string]any{"key": []byte{0}, "range_end": []byte{0}}, etcdPrefixRange(""))
+}
+
+func TestEtcdKV(t *testing.T) {
+	var auths atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v3/auth/authenticate" {
+			n := auths.Add(1)
+			json.NewEncoder(w).Encode(map[string]string{"token": fmt.Sprintf("tok%d", n)})
+			return
+		}
+		// The first token has expired by the time it is used.
+		if r.Header.Get("Authorization") != "tok2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]any{"error": "invalid auth token", "code": 16})
+			return
+		}
+		var in map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		switch r.URL.Path {
+		case "/v3/kv/range":
+			if in["count_only"] == true {
+				json.NewEncoder(w).Encode(map[string]any{"header": map[string]string{"revision": "40"}})
+				return
+			}
+			kvs := []map[string][]byte{}
+			switch in["key"] {
+			case "bXlhcHAvcHJvZC8=": // myapp/prod/
+				assert.Equal(t, "bXlhcHAvcHJvZDA=", in["range_end"]) // myapp/prod0
+				kvs = append(kvs,
+					map[string][]byte{"key": []byte("myapp/prod/API_URL"), "value": []byte("https://etcd")},
+					map[string][]byte{"key": []byte("myapp/prod/MAX_RETRIES"), "value": []byte("5")})
+			case "bXlhcHAvcHJvZC9EQl9QQVNTV09SRA==": // myapp/prod/DB_PASSWORD
+				kvs = append(kvs, map[string][]byte{"key": []byte("myapp/prod/DB_PASSWORD"), "value": []byte("hunter2")})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"header": map[string]string{"revision": "40"}, "kvs": kvs})
+		case "/v3/watch":
+			create := in["create_request"].(map[string]any)
+			assert.Equal(t, 41.0, create["start_revision"])
+			w.Write([]byte(`{"result":{"header":{"revision":"40"},"created":true}}` + "\n"))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(`{"result":{"header":{"revision":"42"},"events":[{"kv":{}}]}}` + "\n"))
+		}
+	}))
+	defer server.Close()
+
+	kv := NewEtcdKV(WithEtcdEndpoint(server.URL), WithEtcdAuth("root", "pw"))
+	ctx := context.Background()
+
+	values, index, err := kv.List(ctx, "myapp/prod/")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"API_URL": "https://etcd", "MAX_RETRIES": "5"}, values)
+	assert.Equal(t, uint64(40), index)
+	assert.Equal(t, int32(2), auths.Load())
+
+	index, err = kv.Wait(ctx, "myapp/prod/", 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(40), index)
+	index, err = kv.Wait(ctx, "myapp/prod/", index)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), index)
+
+	assert.True(t, kv.Handles("etcd/myapp/prod/DB_PASSWORD"))
+	secret, err := kv.Resolve(ctx, "etcd/myapp/prod/DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+	_, err = kv.Resolve(ctx, "etcd/missing")
+	assert.ErrorContains(t, err, "etcd: key missing not found")
+}
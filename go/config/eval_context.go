@@ -0,0 +1,51 @@
+package config
+
+import "context"
+
+// EvalContext is a set of targeting attributes for segment-aware evaluation
+// (e.g. {"userId": ..., "tenantId": ..., "plan": ...}).
+type EvalContext map[string]any
+
+type evalContextKey struct{}
+
+// WithEvalContext returns a copy of ctx carrying attrs, layered over any
+// EvalContext already on ctx (attrs win per key). Set it once in request
+// middleware and EvaluateFeatureFlag / EvaluateLimit calls anywhere
+// downstream pick the attributes up without threading them through.
+func WithEvalContext(ctx context.Context, attrs EvalContext) context.Context {
+	merged := make(EvalContext)
+	for k, v := range EvalContextFrom(ctx) {
+		merged[k] = v
+	}
+	for k, v := range attrs {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, evalContextKey{}, merged)
+}
+
+// EvalContextFrom returns the EvalContext carried by ctx, or nil. The map is
+// shared; do not modify it.
+func EvalContextFrom(ctx context.Context) EvalContext {
+	attrs, _ := ctx.Value(evalContextKey{}).(EvalContext)
+	return attrs
+}
+
+// resolveEvalContext layers the explicit evalContext over the one carried by
+// ctx, so per-call attributes win over middleware-set ones. Never nil.
+func resolveEvalContext(ctx context.Context, evalContext map[string]any) map[string]any {
+	fromCtx := EvalContextFrom(ctx)
+	if len(fromCtx) == 0 {
+		if evalContext == nil {
+			return map[string]any{}
+		}
+		return evalContext
+	}
+	merged := make(map[string]any, len(fromCtx)+len(evalContext))
+	for k, v := range fromCtx {
+		merged[k] = v
+	}
+	for k, v := range evalContext {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEvalContext_LayersOverParent(t *testing.T) {
+	ctx := WithEvalContext(context.Background(), EvalContext{"tenantId": "t1", "plan": "free"})
+	ctx = WithEvalContext(ctx, EvalContext{"userId": "u1", "plan": "pro"})
+
+	assert.Equal(t, EvalContext{"tenantId": "t1", "userId": "u1", "plan": "pro"}, EvalContextFrom(ctx))
+	assert.Nil(t, EvalContextFrom(context.Background()))
+}
+
+func TestEvaluateFeatureFlag_ReadsEvalContextFromCtx(t *testing.T) {
+	var sent map[string]any
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Context map[string]any `json:"context"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		sent = body.Context
+		json.NewEncoder(w).Encode(map[string]any{"value": 1, "source": "rule"})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	ctx := WithEvalContext(context.Background(), EvalContext{"userId": "u1", "plan": "free"})
+	_, err := client.EvaluateFeatureFlag(ctx, "NEW_UI", map[string]any{"plan": "pro"}, "production")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"userId": "u1", "plan": "pro"}, sent, "explicit attributes win over ctx")
+
+	_, err = client.EvaluateLimit(ctx, "MAX_SEATS", nil, "production")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"userId": "u1", "plan": "free"}, sent)
+}
+
+func TestResolveEvalContext_EmptyIsNonNil(t *testing.T) {
+	assert.Equal(t, map[string]any{}, resolveEvalContext(context.Background(), nil))
+}
@@ -0,0 +1,138 @@
+package config_test
+
+// Runnable examples. They are compiled and run with the package tests, and
+// pkg.go.dev shows each next to the API it demonstrates. The remote API is a
+// configtest.ScriptedSource, so every example runs offline with fixed output.
+
+import (
+	"context"
+	"fmt"
+	"testing/fstest"
+	"time"
+
+	config "github.com/SmooAI/config/go/config"
+	"github.com/SmooAI/config/go/config/configtest"
+)
+
+// exampleFiles stands in for a .smooai-config directory.
+var exampleFiles = fstest.MapFS{
+	"default.json": {Data: []byte(`{
+		"API_HOST": "api.example.com",
+		"API_URL": "https://${API_HOST}/v1",
+		"API_RATE_LIMIT": {"requestsPerSecond": 50}
+	}`)},
+}
+
+// Refreshing picks up whatever the API serves now.
+func ExampleConfigManager_refresh() {
+	src := configtest.StartScriptedSource(
+		configtest.Step{At: 0, Values: map[string]any{"TIMEOUT_MS": 500.0}},
+		configtest.Step{At: time.Minute, Values: map[string]any{"TIMEOUT_MS": 250.0}},
+	)
+	defer src.Close()
+
+	mgr := config.NewConfigManager(append(src.ManagerOptions("production", nil),
+		config.WithCMConfigFS(exampleFiles, "."))...)
+	defer mgr.Close(context.Background())
+
+	timeout, _ := mgr.GetPublicConfig("TIMEOUT_MS")
+	fmt.Println("before:", timeout)
+
+	src.Advance(time.Minute)
+	mgr.Invalidate()
+	timeout, _ = mgr.GetPublicConfig("TIMEOUT_MS")
+	fmt.Println("after refresh:", timeout)
+	// Output:
+	// before: 500
+	// after refresh: 250
+}
+
+// A subscription applies pushed changes as they arrive, without a refresh.
+func ExampleConfigManager_Subscribe() {
+	src := configtest.StartScriptedSource(
+		configtest.Step{At: 0, Values: map[string]any{"CHECKOUT_ROLLOUT": 10.0, "BANNER": "Welcome"}},
+		configtest.Step{At: 30 * time.Second, Values: map[string]any{"CHECKOUT_ROLLOUT": 50.0, "BANNER": "Welcome"}},
+	)
+	defer src.Close()
+
+	mgr := config.NewConfigManager(append(src.ManagerOptions("production", nil),
+		config.WithCMConfigFS(exampleFiles, "."))...)
+	defer mgr.Close(context.Background())
+
+	sub, err := mgr.Subscribe(context.Background())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	src.Advance(30 * time.Second)
+
+	evt := <-sub.Events()
+	rollout, _ := mgr.GetFeatureFlag("CHECKOUT_ROLLOUT")
+	fmt.Println("changed:", evt.Keys)
+	fmt.Println("rollout:", rollout)
+	// Output:
+	// changed: [CHECKOUT_ROLLOUT]
+	// rollout: 50
+}
+
+func ExampleConfigClient_EvaluateFeatureFlag() {
+	src := configtest.StartScriptedSource(configtest.Step{Values: map[string]any{"NEW_CHECKOUT": true}})
+	defer src.Close()
+
+	client := src.NewClient()
+	defer client.Close()
+
+	res, err := client.EvaluateFeatureFlag(context.Background(), "NEW_CHECKOUT", map[string]any{"userId": "user-42"}, "production")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(res.Value, res.Source)
+	// Output: true raw
+}
+
+// Typed getters decode, default and validate structured values.
+func ExampleConfigManager_GetRateLimit() {
+	mgr := config.NewConfigManager(
+		config.WithCMConfigFS(exampleFiles, "."),
+		config.WithCMEnvOverride(map[string]string{}),
+	)
+	limit, err := mgr.GetRateLimit("API_RATE_LIMIT")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%.0f req/s, burst %d\n", limit.RequestsPerSecond, limit.Burst)
+	// Output: 50 req/s, burst 50
+}
+
+func ExampleWithCMInterpolation() {
+	mgr := config.NewConfigManager(
+		config.WithCMConfigFS(exampleFiles, "."),
+		config.WithCMEnvOverride(map[string]string{"API_HOST": "staging.example.com"}),
+		config.WithCMSchemaKeys(map[string]bool{"API_HOST": true}),
+		config.WithCMInterpolation(),
+	)
+	url, _ := mgr.GetPublicConfig("API_URL")
+	fmt.Println(url)
+	// Output: https://staging.example.com/v1
+}
+
+func ExampleInterpolate() {
+	values := map[string]any{
+		"DB_HOST": "db.internal",
+		"DB_URL":  "postgres://${DB_HOST}/${env:DB_NAME}",
+	}
+	env := func(name string) (string, bool) {
+		if name == "DB_NAME" {
+			return "orders", true
+		}
+		return "", false
+	}
+	if err := config.Interpolate(values, env); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(values["DB_URL"])
+	// Output: postgres://db.internal/orders
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Explanation is where a key's value came from. String formats it for a
+// terminal, masking values not known to be public or feature flags.
+type Explanation struct {
+	Key string
+	// Value is the key's merged value, and Found whether it has one.
+	Value any
+	Found bool
+	// Tier is the key's tier, empty when the manager doesn't know it.
+	Tier ConfigTier
+	// Source names the layer that supplied Value: a config file
+	// ("production.aws.json"), "built-in", "ssm", "kv", "remote API",
+	// "baked config", "snapshot", "env NAME", a WithSource layer, "override"
+	// or "deferred". A value resolved from a smooai-secret:// reference or a
+	// ${...} placeholder is marked "(secret reference)" or "(interpolated)".
+	Source string
+	// Candidates are the values each layer set for the key, lowest
+	// precedence first, so the last is the one merged unless Source is
+	// "deferred".
+	Candidates []Candidate
+}
+
+// Candidate is one layer's value for an Explanation's key.
+type Candidate struct {
+	Layer string
+	Value any
+}
+
+// Explain reports the value of key and which layer supplied it, with every
+// layer's candidate value, loading the config on first use like the Get*
+// methods. The file layer is split into its config files, which are read
+// afresh, and the env layer into the env vars that set the key. For a key
+// not known to be public or a feature flag, the WithSecretAccessPolicy
+// policy must allow the read, as for GetSecretConfig.
+func (m *ConfigManager) Explain(key string) (Explanation, error) {
+	e, revealable, err := m.explain(key)
+	if err != nil {
+		return Explanation{}, err
+	}
+	if !revealable {
+		if err := m.checkSecretAccess(key); err != nil {
+			return Explanation{}, err
+		}
+	}
+	return e, nil
+}
+
+// explain builds Explain's report, and reports whether key's value may be
+// shown without the secret access policy.
+func (m *ConfigManager) explain(key string) (Explanation, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.initialize(); err != nil {
+		return Explanation{}, false, err
+	}
+	env := m.envMap()
+	e := Explanation{Key: key, Tier: m.keyTierLocked(key)}
+	e.Value, e.Found = m.config[key]
+
+	for _, layer := range m.layersLocked(m.fileConfig, m.ssmConfig, m.kvConfig, m.remoteConfig, m.envConfig) {
+		var split []Candidate
+		switch layer.name {
+		case "file":
+			split = m.fileCandidates(env, key)
+		case "env":
+			split = m.envCandidates(env, key, layer.values[key])
+		case "remote":
+			switch {
+			case m.snapshot != nil:
+				layer.name = "snapshot"
+			case m.bakedConfig != nil:
+				layer.name = "baked config"
+			default:
+				layer.name = "remote API"
+			}
+		}
+		e.Candidates = append(e.Candidates, split...)
+		value, ok := layer.values[key]
+		if !ok {
+			continue
+		}
+		if len(split) == 0 || !reflect.DeepEqual(split[len(split)-1].Value, value) {
+			// Set by the layer itself rather than a file or env var it read:
+			// a built-in key, or a value reshaped by the merge.
+			if slices.Contains(BuiltinKeys, key) && (layer.name == "file" || layer.name == "env") {
+				layer.name = "built-in"
+			}
+			e.Candidates = append(e.Candidates, Candidate{Layer: layer.name, Value: value})
+		}
+	}
+	if value, ok := m.overrides[key]; ok {
+		e.Candidates = append(e.Candidates, Candidate{Layer: "override", Value: value})
+	}
+
+	switch _, deferred := m.deferred[key]; {
+	case len(e.Candidates) > 0 && e.Candidates[len(e.Candidates)-1].Layer == "override":
+		e.Source = "override"
+	case deferred:
+		e.Source = "deferred"
+	case len(e.Candidates) > 0:
+		top := e.Candidates[len(e.Candidates)-1]
+		e.Source = top.Layer
+		if s, ok := top.Value.(string); ok && e.Found && !reflect.DeepEqual(top.Value, e.Value) {
+			if strings.HasPrefix(s, SecretRefPrefix) {
+				e.Source += " (secret reference)"
+			} else if m.interpolate {
+				e.Source += " (interpolated)"
+			}
+		}
+	}
+	return e, m.revealableLocked(key), nil
+}
+
+// fileCandidates reads key from each config file in merge order. Files that
+// are missing or don't parse are skipped; Doctor reports them.
+func (m *ConfigManager) fileCandidates(env map[string]string, key string) []Candidate {
+	fsys, dir, _, err := m.configFiles.resolve(env)
+	if err != nil {
+		return nil
+	}
+	var out []Candidate
+	add := func(name string, data []byte) {
+		var parsed map[string]any
+		if unmarshalJSONC(data, &parsed) != nil {
+			return
+		}
+		if value, ok := parsed[key]; ok {
+			out = append(out, Candidate{Layer: name, Value: value})
+		}
+	}
+	fileNames, _ := configFileNames(env)
+	for _, fileName := range fileNames {
+		if name, data, err := readConfigFile(fsys, dir, fileName); err == nil {
+			add(name, data)
+		}
+	}
+	if path := userOverridesPath(env); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			add(filepath.Base(path), data)
+		}
+	}
+	return out
+}
+
+// envCandidates returns each env var that sets key, sorted by name but with
+// the one whose value was merged last.
+func (m *ConfigManager) envCandidates(env map[string]string, key string, merged any) []Candidate {
+	schemaKeys, schemaTypes := m.envSchemaLocked()
+	if !schemaKeys[key] {
+		return nil
+	}
+	var out []Candidate
+	for _, name := range m.envNames.names(key) {
+		if raw, ok := env[name]; ok {
+			out = append(out, Candidate{Layer: "env " + name, Value: coerceEnvValue(schemaTypes[key], raw)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		iWon, jWon := reflect.DeepEqual(out[i].Value, merged), reflect.DeepEqual(out[j].Value, merged)
+		if iWon != jWon {
+			return jWon
+		}
+		return out[i].Layer < out[j].Layer
+	})
+	return out
+}
+
+// String formats the explanation for a terminal.
+func (e Explanation) String() string {
+	show := func(v any) string {
+		switch e.Tier {
+		case TierPublic, TierFeatureFlag:
+		default:
+			v = MaskSecret(v)
+		}
+		return fmt.Sprintf("%#v", v)
+	}
+	var b strings.Builder
+	if e.Found {
+		fmt.Fprintf(&b, "%s = %s (from %s)\n", e.Key, show(e.Value), e.Source)
+	} else {
+		fmt.Fprintf(&b, "%s is not set\n", e.Key)
+	}
+	if len(e.Candidates) > 0 {
+		b.WriteString("Candidates, lowest precedence first:\n")
+		for _, c := range e.Candidates {
+			fmt.Fprintf(&b, "  %-24s %s\n", c.Layer, show(c.Value))
+		}
+	}
+	return b.String()
+}
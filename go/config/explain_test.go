@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	configDir := makeCMConfigDir(t, map[string]any{
+		"default.json":    map[string]any{"API_URL": "http://localhost", "DB_URL": "postgres://default", "PORT": 80},
+		"production.json": map[string]any{"API_URL": "https://prod", "DB_URL": "postgres://prod-db-host"},
+	})
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{"API_URL": "https://remote", "DB_URL": "postgres://remote-db-host"})
+	defer mock.close()
+
+	mgr := NewConfigManager(
+		WithCMSchemaKeys(map[string]bool{"API_URL": true, "DB_URL": true}),
+		WithCMEnvPrefix("NEXT_PUBLIC_"),
+		WithCMKeyTiers(map[string]ConfigTier{"API_URL": TierPublic, "DB_URL": TierSecret}),
+		WithDeferred("FULL_URL", func(config map[string]any) any { return config["API_URL"] }),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR":        configDir,
+			"SMOOAI_CONFIG_ENV":            "production",
+			"SMOOAI_CONFIG_USER_OVERRIDES": "off",
+			"NEXT_PUBLIC_API_URL":          "https://env",
+			"SMOOAI_CONFIG_API_KEY":        "test-key",
+			"SMOOAI_CONFIG_API_URL":        mock.server.URL,
+			"SMOOAI_CONFIG_AUTH_URL":       mock.server.URL,
+			"SMOOAI_CONFIG_ORG_ID":         testOrgID,
+		}),
+	)
+	defer mgr.Close(context.Background())
+
+	e, err := mgr.Explain("API_URL")
+	require.NoError(t, err)
+	assert.True(t, e.Found)
+	assert.Equal(t, "https://env", e.Value)
+	assert.Equal(t, "env NEXT_PUBLIC_API_URL", e.Source)
+	assert.Equal(t, []Candidate{
+		{"default.json", "http://localhost"},
+		{"production.json", "https://prod"},
+		{"remote API", "https://remote"},
+		{"env NEXT_PUBLIC_API_URL", "https://env"},
+	}, e.Candidates)
+	assert.Contains(t, e.String(), `API_URL = "https://env" (from env NEXT_PUBLIC_API_URL)`)
+
+	e, err = mgr.Explain("DB_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "remote API", e.Source)
+	assert.Len(t, e.Candidates, 3)
+	assert.NotContains(t, e.String(), "remote-db-host", "secrets are masked")
+	assert.Contains(t, e.String(), `"pos****"`)
+
+	e, err = mgr.Explain("PORT")
+	require.NoError(t, err)
+	assert.Equal(t, "default.json", e.Source)
+
+	e, err = mgr.Explain("FULL_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "deferred", e.Source)
+	assert.Equal(t, "https://env", e.Value)
+
+	mgr.SetOverride("PORT", 8080, 0)
+	e, err = mgr.Explain("PORT")
+	require.NoError(t, err)
+	assert.Equal(t, "override", e.Source)
+	assert.Equal(t, 8080, e.Value)
+
+	e, err = mgr.Explain("MISSING")
+	require.NoError(t, err)
+	assert.False(t, e.Found)
+	assert.Empty(t, e.Candidates)
+	assert.Equal(t, "MISSING is not set\n", e.String())
+}
+
+func TestExplain_BuiltinAndSecretReference(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMConfigFiles(map[string]string{"default.json": `{"TOKEN": "smooai-secret://vault:token"}`}),
+		WithCMRemoteProvider(&vaultProvider{secrets: map[string]string{"vault:token": "tok-123"}}),
+		WithCMEnvOverride(map[string]string{"SMOOAI_CONFIG_USER_OVERRIDES": "off", "SMOOAI_CONFIG_ENV": "staging"}),
+	)
+	defer mgr.Close(context.Background())
+
+	e, err := mgr.Explain("TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "tok-123", e.Value)
+	assert.Equal(t, "default.json (secret reference)", e.Source)
+
+	e, err = mgr.Explain("ENV")
+	require.NoError(t, err)
+	assert.Equal(t, "staging", e.Value)
+	assert.Equal(t, "built-in", e.Source)
+}
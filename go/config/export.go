@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ExportFormat is an output format of Export.
+type ExportFormat string
+
+// Export formats.
+const (
+	// ExportDotEnv writes KEY="value" lines, as docker compose env_file
+	// and .env loaders (WithCMDotEnv included) read them. Values that
+	// aren't strings are written as JSON.
+	ExportDotEnv ExportFormat = "dotenv"
+	// ExportJSON writes one indented JSON object.
+	ExportJSON ExportFormat = "json"
+	// ExportYAML writes a YAML mapping, e.g. for Helm values.
+	ExportYAML ExportFormat = "yaml"
+)
+
+// Export formats the merged config, every layer resolved, for other tooling
+// such as a docker compose env file or terraform vars, loading it on first
+// use like the Get* methods. Keys are sorted. Unless includeSecrets is set,
+// only keys known to be public or feature flags are written, as with
+// Redacted; the output then holds no secret a caller didn't ask for. With
+// includeSecrets, the WithSecretAccessPolicy policy must allow a read of
+// every other key.
+func (m *ConfigManager) Export(format ExportFormat, includeSecrets bool) ([]byte, error) {
+	m.mu.Lock()
+	if err := m.initialize(); err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	values := make(map[string]any, len(m.config))
+	var secrets []string
+	for key, value := range m.config {
+		switch {
+		case m.revealableLocked(key):
+			values[key] = cloneValue(value)
+		case includeSecrets:
+			values[key] = cloneValue(value)
+			secrets = append(secrets, key)
+		}
+	}
+	m.mu.Unlock()
+	if err := m.checkSecretAccessAll(secrets); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case ExportDotEnv:
+		return exportDotEnv(values), nil
+	case ExportJSON:
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(values); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	case ExportYAML:
+		var b strings.Builder
+		writeYAMLMap(&b, values, 0)
+		return []byte(b.String()), nil
+	}
+	return nil, NewConfigError(fmt.Sprintf("unknown export format %q", format))
+}
+
+// exportDotEnv quotes each value the way parseDotEnv unquotes it.
+func exportDotEnv(values map[string]any) []byte {
+	var b strings.Builder
+	for _, key := range sortedKeys(values) {
+		s, ok := values[key].(string)
+		if !ok {
+			s = jsonScalar(values[key])
+		}
+		s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s)
+		fmt.Fprintf(&b, "%s=\"%s\"\n", key, s)
+	}
+	return []byte(b.String())
+}
+
+// yamlPlainKey matches the mapping keys that YAML reads back as the same
+// string without quotes.
+var yamlPlainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// writeYAMLMap writes m in block style at indent spaces. Scalars are written
+// as JSON, which YAML reads as flow scalars of the same value.
+func writeYAMLMap(b *strings.Builder, m map[string]any, indent int) {
+	if len(m) == 0 && indent == 0 {
+		b.WriteString("{}\n")
+		return
+	}
+	for _, key := range sortedKeys(m) {
+		b.WriteString(strings.Repeat(" ", indent))
+		if yamlPlainKey.MatchString(key) && !yamlReserved(key) {
+			b.WriteString(key)
+		} else {
+			b.WriteString(jsonScalar(key))
+		}
+		b.WriteByte(':')
+		writeYAMLValue(b, m[key], indent+2)
+	}
+}
+
+// writeYAMLValue writes v after a "key:" or "-", nesting collections at
+// indent spaces.
+func writeYAMLValue(b *strings.Builder, v any, indent int) {
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) > 0 {
+			b.WriteByte('\n')
+			writeYAMLMap(b, v, indent)
+			return
+		}
+	case []any:
+		if len(v) > 0 {
+			b.WriteByte('\n')
+			for _, item := range v {
+				// Write the item as if nested under the dash, then put the
+				// dash in its first line's indentation: "- a: 1\n  b: 2".
+				var nested strings.Builder
+				writeYAMLValue(&nested, item, indent+2)
+				first := strings.TrimPrefix(nested.String(), "\n")
+				b.WriteString(strings.Repeat(" ", indent))
+				b.WriteString("-")
+				if first != nested.String() {
+					b.WriteByte(' ')
+					first = strings.TrimPrefix(first, strings.Repeat(" ", indent+2))
+				}
+				b.WriteString(first)
+			}
+			return
+		}
+	}
+	b.WriteByte(' ')
+	b.WriteString(jsonScalar(v))
+	b.WriteByte('\n')
+}
+
+// yamlReserved reports whether YAML would read s unquoted as something other
+// than a string.
+func yamlReserved(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "false", "null", "yes", "no", "on", "off", "y", "n", "~":
+		return true
+	}
+	return false
+}
+
+// jsonScalar formats v as JSON without escaping HTML characters.
+func jsonScalar(v any) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
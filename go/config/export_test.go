@@ -0,0 +1,84 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportManager(t *testing.T) *ConfigManager {
+	t.Helper()
+	mgr := NewConfigManager(
+		WithCMConfigFiles(map[string]string{"default.json": `{
+			"API_URL": "https://api.example.com/?a=1&b=<2>",
+			"GREETING": "say \"hi\"\n",
+			"PORT": 8080,
+			"DEBUG": true,
+			"DB": {"host": "db", "replicas": [{"host": "r1", "port": 1}, "r2"]},
+			"EMPTY": [],
+			"DB_PASSWORD": "hunter2"
+		}`}),
+		WithCMKeyTiers(map[string]ConfigTier{
+			"API_URL": TierPublic, "GREETING": TierPublic, "PORT": TierPublic, "DEBUG": TierFeatureFlag,
+			"DB": TierPublic, "EMPTY": TierPublic, "DB_PASSWORD": TierSecret,
+		}),
+		WithCMBuiltins(BuiltinPolicy{Default: BuiltinSuppress}),
+		WithCMEnvOverride(map[string]string{"SMOOAI_CONFIG_USER_OVERRIDES": "off"}),
+	)
+	t.Cleanup(func() { mgr.Close(context.Background()) })
+	return mgr
+}
+
+func TestExport_DotEnvRoundTrips(t *testing.T) {
+	out, err := newExportManager(t).Export(ExportDotEnv, true)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `GREETING="say \"hi\"\n"`+"\n")
+
+	parsed, err := parseDotEnv(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"API_URL":     "https://api.example.com/?a=1&b=<2>",
+		"GREETING":    "say \"hi\"\n",
+		"PORT":        "8080",
+		"DEBUG":       "true",
+		"DB":          `{"host":"db","replicas":[{"host":"r1","port":1},"r2"]}`,
+		"EMPTY":       "[]",
+		"DB_PASSWORD": "hunter2",
+	}, parsed)
+}
+
+func TestExport_JSON(t *testing.T) {
+	out, err := newExportManager(t).Export(ExportJSON, false)
+	require.NoError(t, err)
+	var parsed map[string]any
+	require.NoError(t, json.Unmarshal(out, &parsed))
+	assert.NotContains(t, parsed, "DB_PASSWORD", "secrets are left out without includeSecrets")
+	assert.Equal(t, "https://api.example.com/?a=1&b=<2>", parsed["API_URL"])
+	assert.Equal(t, float64(8080), parsed["PORT"])
+}
+
+func TestExport_YAML(t *testing.T) {
+	out, err := newExportManager(t).Export(ExportYAML, false)
+	require.NoError(t, err)
+	assert.Equal(t, `API_URL: "https://api.example.com/?a=1&b=<2>"
+DB:
+  host: "db"
+  replicas:
+    - host: "r1"
+      port: 1
+    - "r2"
+DEBUG: true
+EMPTY: []
+GREETING: "say \"hi\"\n"
+PORT: 8080
+`, string(out))
+}
+
+func TestExport_UnknownFormat(t *testing.T) {
+	_, err := newExportManager(t).Export("toml", true)
+	assert.ErrorContains(t, err, `unknown export format "toml"`)
+}
@@ -58,7 +58,7 @@ func TestEvaluateFeatureFlag_PostsExpectedBodyAndHeaders(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org-abc", "secret-key")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "secret-key")
 	defer client.Close()
 
 	ctx := context.Background()
@@ -70,7 +70,7 @@ func TestEvaluateFeatureFlag_PostsExpectedBodyAndHeaders(t *testing.T) {
 	require.NotNil(t, resp)
 
 	assert.Equal(t, http.MethodPost, gotMethod)
-	assert.Equal(t, "/organizations/org-abc/config/feature-flags/new_dashboard/evaluate", gotPath)
+	assert.Equal(t, "/organizations/"+testOrgID+"/config/feature-flags/new_dashboard/evaluate", gotPath)
 	assert.Equal(t, "Bearer secret-key", gotAuth)
 	assert.Equal(t, "application/json", gotContentType)
 
@@ -96,7 +96,7 @@ func TestEvaluateFeatureFlag_NilContextSerializesAsEmptyObject(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	_, err := client.EvaluateFeatureFlag(context.Background(), "flag", nil, "production")
@@ -120,7 +120,7 @@ func TestEvaluateFeatureFlag_UsesDefaultEnvironmentWhenEmpty(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	_, err := client.EvaluateFeatureFlag(context.Background(), "flag", map[string]any{}, "")
@@ -141,7 +141,7 @@ func TestEvaluateFeatureFlag_EnvironmentOverrideWins(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	_, err := client.EvaluateFeatureFlag(context.Background(), "flag", nil, "production")
@@ -162,7 +162,7 @@ func TestEvaluateFeatureFlag_URLEncodesKey(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org-abc", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	_, err := client.EvaluateFeatureFlag(context.Background(), "weird key/with:chars", nil, "production")
@@ -193,7 +193,7 @@ func TestEvaluateFeatureFlag_DecodesFullResponseShape(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	resp, err := client.EvaluateFeatureFlag(context.Background(), "flag", map[string]any{"userId": "u"}, "production")
@@ -216,7 +216,7 @@ func TestEvaluateFeatureFlag_404ReturnsNotFoundError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	_, err := client.EvaluateFeatureFlag(context.Background(), "missing", nil, "production")
@@ -238,7 +238,7 @@ func TestEvaluateFeatureFlag_400ReturnsContextError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	_, err := client.EvaluateFeatureFlag(context.Background(), "flag", map[string]any{}, "production")
@@ -260,7 +260,7 @@ func TestEvaluateFeatureFlag_500ReturnsServerError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	_, err := client.EvaluateFeatureFlag(context.Background(), "flag", nil, "production")
@@ -281,7 +281,7 @@ func TestEvaluateFeatureFlag_CanceledContextReturnsError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -0,0 +1,115 @@
+//go:build js && wasm
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"syscall/js"
+)
+
+// defaultHTTPClient sends requests with the browser or runtime's fetch API,
+// so the client works under TinyGo too, whose net/http has no transport on
+// js/wasm.
+var defaultHTTPClient = &http.Client{Transport: FetchTransport{}}
+
+// FetchTransport is an http.RoundTripper that sends requests with the
+// JavaScript fetch API. It is the default transport in js/wasm builds.
+//
+// Response bodies are read in full before RoundTrip returns, so Subscribe's
+// event stream can't be used over it; poll with Refresh instead, or set
+// SMOOAI_CONFIG_WATCH_TRANSPORT=longpoll.
+type FetchTransport struct{}
+
+// RoundTrip implements http.RoundTripper.
+func (FetchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	opts := js.Global().Get("Object").New()
+	opts.Set("method", req.Method)
+	headers := js.Global().Get("Headers").New()
+	for name, values := range req.Header {
+		for _, v := range values {
+			headers.Call("append", name, v)
+		}
+	}
+	opts.Set("headers", headers)
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		buf := js.Global().Get("Uint8Array").New(len(body))
+		js.CopyBytesToJS(buf, body)
+		opts.Set("body", buf)
+	}
+	if ctrl := js.Global().Get("AbortController"); !ctrl.IsUndefined() {
+		abort := ctrl.New()
+		opts.Set("signal", abort.Get("signal"))
+		stop := context.AfterFunc(req.Context(), func() { abort.Call("abort") })
+		defer stop()
+	}
+
+	res, err := awaitPromise(js.Global().Call("fetch", req.URL.String(), opts))
+	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	header := http.Header{}
+	addHeader := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		header.Add(args[1].String(), args[0].String())
+		return nil
+	})
+	res.Get("headers").Call("forEach", addHeader)
+	addHeader.Release()
+
+	data, err := awaitPromise(res.Call("arrayBuffer"))
+	if err != nil {
+		return nil, err
+	}
+	arr := js.Global().Get("Uint8Array").New(data)
+	body := make([]byte, arr.Get("length").Int())
+	js.CopyBytesToGo(body, arr)
+
+	code := res.Get("status").Int()
+	return &http.Response{
+		Status:        strconv.Itoa(code) + " " + res.Get("statusText").String(),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// awaitPromise blocks until p settles and returns its value or rejection.
+func awaitPromise(p js.Value) (js.Value, error) {
+	var (
+		value js.Value
+		err   error
+	)
+	done := make(chan struct{})
+	onResolve := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		value = args[0]
+		close(done)
+		return nil
+	})
+	defer onResolve.Release()
+	onReject := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		err = errors.New(args[0].Call("toString").String())
+		close(done)
+		return nil
+	})
+	defer onReject.Release()
+	p.Call("then", onResolve, onReject)
+	<-done
+	return value, err
+}
@@ -0,0 +1,9 @@
+//go:build !(js && wasm)
+
+package config
+
+import "net/http"
+
+// defaultHTTPClient is the client API and token requests use unless
+// WithHTTPClient sets another.
+var defaultHTTPClient = http.DefaultClient
@@ -1,11 +1,14 @@
 package config
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -35,12 +38,19 @@ func FindConfigDirectory(ignoreCache bool) (string, error) {
 }
 
 func findConfigDirectoryWithEnv(ignoreCache bool, env map[string]string) (string, error) {
+	dir, _, err := locateConfigDir(ignoreCache, env)
+	return dir, err
+}
+
+// locateConfigDir is findConfigDirectoryWithEnv, also saying how the
+// directory was found, for Doctor.
+func locateConfigDir(ignoreCache bool, env map[string]string) (dir, via string, err error) {
 	// 1. SMOOAI_ENV_CONFIG_DIR
 	if dir := env["SMOOAI_ENV_CONFIG_DIR"]; dir != "" {
 		if info, err := os.Stat(dir); err == nil && info.IsDir() {
-			return dir, nil
+			return dir, "SMOOAI_ENV_CONFIG_DIR", nil
 		}
-		return "", NewConfigError(fmt.Sprintf("directory in SMOOAI_ENV_CONFIG_DIR does not exist: %s", dir))
+		return "", "", NewConfigError(fmt.Sprintf("directory in SMOOAI_ENV_CONFIG_DIR does not exist: %s", dir))
 	}
 
 	// 2. Check cache
@@ -50,7 +60,7 @@ func findConfigDirectoryWithEnv(ignoreCache bool, env map[string]string) (string
 			dir := configDirCache
 			configDirCacheMu.Unlock()
 			if info, err := os.Stat(dir); err == nil && info.IsDir() {
-				return dir, nil
+				return dir, "cached lookup", nil
 			}
 			configDirCacheMu.Lock()
 			configDirCache = ""
@@ -63,7 +73,7 @@ func findConfigDirectoryWithEnv(ignoreCache bool, env map[string]string) (string
 	// 3. CWD candidates
 	cwd, err := os.Getwd()
 	if err != nil {
-		return "", NewConfigError(fmt.Sprintf("failed to get working directory: %v", err))
+		return "", "", NewConfigError(fmt.Sprintf("failed to get working directory: %v", err))
 	}
 
 	candidates := []string{".smooai-config", "smooai-config"}
@@ -75,7 +85,7 @@ func findConfigDirectoryWithEnv(ignoreCache bool, env map[string]string) (string
 			configDirCache = dir
 			configDirCacheAt = time.Now()
 			configDirCacheMu.Unlock()
-			return dir, nil
+			return dir, "working directory", nil
 		}
 	}
 
@@ -88,7 +98,7 @@ func findConfigDirectoryWithEnv(ignoreCache bool, env map[string]string) (string
 	}
 
 	searchDir := cwd
-	for range levelsUp {
+	for level := 1; level <= levelsUp; level++ {
 		parent := filepath.Dir(searchDir)
 		if parent == searchDir {
 			break // reached root
@@ -101,12 +111,16 @@ func findConfigDirectoryWithEnv(ignoreCache bool, env map[string]string) (string
 				configDirCache = dir
 				configDirCacheAt = time.Now()
 				configDirCacheMu.Unlock()
-				return dir, nil
+				via := "the working directory's parent"
+				if level > 1 {
+					via = fmt.Sprintf("%d levels up from the working directory", level)
+				}
+				return dir, via, nil
 			}
 		}
 	}
 
-	return "", NewConfigError(fmt.Sprintf("could not find config directory, searched %d levels up from %s", levelsUp, cwd))
+	return "", "", NewConfigError(fmt.Sprintf("could not find config directory, searched %d levels up from %s", levelsUp, cwd))
 }
 
 // FindAndProcessFileConfig loads and merges JSON config files.
@@ -117,25 +131,113 @@ func findConfigDirectoryWithEnv(ignoreCache bool, env map[string]string) (string
 //  3. {env}.json
 //  4. {env}.{provider}.json
 //  5. {env}.{provider}.{region}.json
+//  6. {env}.ring.{ring}.json (if a deployment ring is set)
+//  7. {env}.local.json (untracked per-checkout tweaks)
+//  8. the user override file (see userOverridesPath)
+//
+// Each file may instead be named .jsonc, and either form may contain
+// comments and trailing commas.
 func FindAndProcessFileConfig() (map[string]any, error) {
 	return findAndProcessFileConfigWithEnv(osEnvMap())
 }
 
 func findAndProcessFileConfigWithEnv(env map[string]string) (map[string]any, error) {
+	return findAndProcessFileConfigWithPolicy(env, NullSet, BuiltinPolicy{}, configSource{})
+}
+
+// configSource is where the config files are read from: root within fsys
+// (WithConfigFS), or the config directory found on disk when fsys is nil.
+type configSource struct {
+	fsys fs.FS
+	root string
+}
+
+// resolve returns the file system to read the config files from, the
+// directory holding them within it, and that directory as shown in errors.
+func (src configSource) resolve(env map[string]string) (fs.FS, string, string, error) {
+	if src.fsys != nil {
+		root := path.Clean(src.root)
+		return src.fsys, root, root, nil
+	}
 	configDir, err := findConfigDirectoryWithEnv(false, env)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return os.DirFS(configDir), ".", configDir, nil
+}
+
+// findAndProcessFileConfigWithPolicy layers the config files from src with
+// the given null policy, so a null in {env}.json can delete or defer to
+// default.json, and injects the built-in keys per builtins.
+func findAndProcessFileConfigWithPolicy(env map[string]string, nullPolicy NullPolicy, builtins BuiltinPolicy, src configSource) (map[string]any, error) {
+	fsys, dir, configDir, err := src.resolve(env)
 	if err != nil {
 		return nil, err
 	}
 
-	isLocal := CoerceBoolean(env["IS_LOCAL"])
-	envName := env["SMOOAI_CONFIG_ENV"]
-	if envName == "" {
-		envName = "development"
+	finalConfig := make(map[string]any)
+	layer := func(filePath string, data []byte) error {
+		var fileConfig map[string]any
+		if err := unmarshalJSONC(data, &fileConfig); err != nil {
+			return NewConfigError(fmt.Sprintf("error parsing %s: %v", filePath, err))
+		}
+		merged := MergeWithNullPolicy(finalConfig, fileConfig, nullPolicy)
+		if m, ok := merged.(map[string]any); ok {
+			finalConfig = m
+		}
+		return nil
+	}
+
+	fileNames, err := configFileNames(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, fileName := range fileNames {
+		name, data, err := readConfigFile(fsys, dir, fileName)
+		filePath := filepath.Join(configDir, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				if fileName == "default.json" {
+					return nil, NewConfigError(fmt.Sprintf("required default.json not found in %s", configDir))
+				}
+				continue // optional file
+			}
+			return nil, NewConfigError(fmt.Sprintf("error reading %s: %v", filePath, err))
+		}
+		if err := layer(filePath, data); err != nil {
+			return nil, err
+		}
 	}
+
+	if overridesPath := userOverridesPath(env); overridesPath != "" {
+		data, err := os.ReadFile(overridesPath)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, NewConfigError(fmt.Sprintf("error reading %s: %v", overridesPath, err))
+		default:
+			if err := layer(overridesPath, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	applyBuiltins(finalConfig, builtinValuesFromEnv(env), builtins, true)
+
+	return finalConfig, nil
+}
+
+// configFileNames lists the config files to layer for env, lowest
+// precedence first, by their .json names. With an invalid ring it returns
+// the other files and the ring's error.
+func configFileNames(env map[string]string) ([]string, error) {
+	isLocal := CoerceBoolean(env["IS_LOCAL"])
+	envName := GetConfigEnvironmentFromEnv(env)
 	cloudRegion := GetCloudRegionFromEnv(env)
+	ring := GetDeploymentRingFromEnv(env)
 
-	// Build file list
 	files := []string{"default.json"}
+	var ringErr error
 	if isLocal {
 		files = append(files, "local.json")
 	}
@@ -147,39 +249,58 @@ func findAndProcessFileConfigWithEnv(env map[string]string) (map[string]any, err
 				files = append(files, fmt.Sprintf("%s.%s.%s.json", envName, cloudRegion.Provider, cloudRegion.Region))
 			}
 		}
-	}
-
-	finalConfig := make(map[string]any)
-
-	for _, fileName := range files {
-		filePath := filepath.Join(configDir, fileName)
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				if fileName == "default.json" {
-					return nil, NewConfigError(fmt.Sprintf("required default.json not found in %s", configDir))
-				}
-				continue // optional file
+		// Ring overlays apply last so early rings can diverge from the
+		// broad rollout without a separate environment.
+		if ring != "" {
+			name, err := ringFileName(envName, ring)
+			if err != nil {
+				ringErr = err
+			} else {
+				files = append(files, name)
 			}
-			return nil, NewConfigError(fmt.Sprintf("error reading %s: %v", filePath, err))
 		}
+		// Developer overrides come last so they win over every tracked
+		// file; {env}.local.json is meant to be gitignored.
+		files = append(files, envName+".local.json")
+	}
 
-		var fileConfig map[string]any
-		if err := json.Unmarshal(data, &fileConfig); err != nil {
-			return nil, NewConfigError(fmt.Sprintf("error parsing %s: %v", filePath, err))
-		}
+	return files, ringErr
+}
 
-		merged := MergeReplaceArrays(finalConfig, fileConfig)
-		if m, ok := merged.(map[string]any); ok {
-			finalConfig = m
+// userOverridesPath returns the developer's own override file, which is
+// layered over every config file of every project:
+// $SMOOAI_CONFIG_USER_OVERRIDES, or smooai/overrides.json under
+// $XDG_CONFIG_HOME (default ~/.config). Empty when the variable is "off" or
+// there is no home directory.
+func userOverridesPath(env map[string]string) string {
+	if p := env["SMOOAI_CONFIG_USER_OVERRIDES"]; p != "" {
+		if strings.EqualFold(p, "off") {
+			return ""
 		}
+		return p
 	}
+	configHome := env["XDG_CONFIG_HOME"]
+	if configHome == "" {
+		home := env["HOME"]
+		if home == "" {
+			return ""
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "smooai", "overrides.json")
+}
 
-	// Set built-in keys
-	finalConfig["ENV"] = envName
-	finalConfig["IS_LOCAL"] = isLocal
-	finalConfig["REGION"] = cloudRegion.Region
-	finalConfig["CLOUD_PROVIDER"] = cloudRegion.Provider
-
-	return finalConfig, nil
+// readConfigFile reads fileName from dir in fsys, falling back to its
+// .jsonc spelling, and returns the name it read. The returned error is the
+// .json one when neither exists.
+func readConfigFile(fsys fs.FS, dir, fileName string) (string, []byte, error) {
+	data, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+	if !errors.Is(err, fs.ErrNotExist) {
+		return fileName, data, err
+	}
+	jsoncName := fileName + "c"
+	if data, jsoncErr := fs.ReadFile(fsys, path.Join(dir, jsoncName)); !errors.Is(jsoncErr, fs.ErrNotExist) {
+		return jsoncName, data, jsoncErr
+	}
+	return fileName, nil, err
 }
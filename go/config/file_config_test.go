@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -103,3 +104,136 @@ func TestFindAndProcessFileConfigWithEnv_SkipsOptionalFiles(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "test", result["API_URL"])
 }
+
+func TestFindAndProcessFileConfigWithEnv_OverrideLayers(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".smooai-config")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	writeJSON(t, configDir, "default.json", map[string]any{"API_URL": "default", "TIMEOUT": 10, "REGION_NAME": "us"})
+	writeJSON(t, configDir, "production.json", map[string]any{"API_URL": "prod", "TIMEOUT": 20})
+	writeJSON(t, configDir, "production.local.json", map[string]any{"API_URL": "local", "TIMEOUT": 30})
+	configHome := filepath.Join(dir, "xdg")
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "smooai"), 0o755))
+	writeJSON(t, filepath.Join(configHome, "smooai"), "overrides.json", map[string]any{"TIMEOUT": 40})
+
+	env := map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": configDir,
+		"SMOOAI_CONFIG_ENV":     "production",
+		"XDG_CONFIG_HOME":       configHome,
+	}
+	result, err := findAndProcessFileConfigWithEnv(env)
+	require.NoError(t, err)
+	assert.Equal(t, "local", result["API_URL"])
+	assert.Equal(t, 40.0, result["TIMEOUT"])
+	assert.Equal(t, "us", result["REGION_NAME"])
+
+	env["SMOOAI_CONFIG_USER_OVERRIDES"] = "off"
+	result, err = findAndProcessFileConfigWithEnv(env)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, result["TIMEOUT"])
+}
+
+func TestFindAndProcessFileConfigWithEnv_BadUserOverrides(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".smooai-config")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	writeJSON(t, configDir, "default.json", map[string]any{"API_URL": "test"})
+	overrides := filepath.Join(dir, "overrides.json")
+	require.NoError(t, os.WriteFile(overrides, []byte(`{"API_URL":`), 0o644))
+
+	env := map[string]string{"SMOOAI_ENV_CONFIG_DIR": configDir, "SMOOAI_CONFIG_USER_OVERRIDES": overrides}
+	_, err := findAndProcessFileConfigWithEnv(env)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), overrides)
+
+	env["SMOOAI_CONFIG_USER_OVERRIDES"] = filepath.Join(dir, "missing.json")
+	result, err := findAndProcessFileConfigWithEnv(env)
+	require.NoError(t, err)
+	assert.Equal(t, "test", result["API_URL"])
+}
+
+func TestUserOverridesPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("/home/dev", ".config", "smooai", "overrides.json"), userOverridesPath(map[string]string{"HOME": "/home/dev"}))
+	assert.Equal(t, filepath.Join("/xdg", "smooai", "overrides.json"), userOverridesPath(map[string]string{"HOME": "/home/dev", "XDG_CONFIG_HOME": "/xdg"}))
+	assert.Equal(t, "/tmp/o.json", userOverridesPath(map[string]string{"SMOOAI_CONFIG_USER_OVERRIDES": "/tmp/o.json"}))
+	assert.Empty(t, userOverridesPath(map[string]string{"HOME": "/home/dev", "SMOOAI_CONFIG_USER_OVERRIDES": "OFF"}))
+	assert.Empty(t, userOverridesPath(map[string]string{}))
+}
+
+func TestFindAndProcessFileConfigWithEnv_AcceptsComments(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".smooai-config")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "default.json"), []byte(`{
+	// Local dev server.
+	"API_URL": "http://localhost:3000",
+	"MAX_RETRIES": 3,
+}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "production.jsonc"), []byte(`{
+	/* Raised for the launch. */
+	"MAX_RETRIES": 10,
+}`), 0o644))
+
+	env := map[string]string{"SMOOAI_ENV_CONFIG_DIR": configDir, "SMOOAI_CONFIG_ENV": "production"}
+	result, err := findAndProcessFileConfigWithEnv(env)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:3000", result["API_URL"])
+	assert.Equal(t, 10.0, result["MAX_RETRIES"])
+}
+
+func TestFindAndProcessFileConfigWithEnv_JSONCDefault(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".smooai-config")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "default.jsonc"), []byte(`{"API_URL": "x"} // ok`), 0o644))
+
+	env := map[string]string{"SMOOAI_ENV_CONFIG_DIR": configDir, "SMOOAI_CONFIG_ENV": "test"}
+	result, err := findAndProcessFileConfigWithEnv(env)
+	require.NoError(t, err)
+	assert.Equal(t, "x", result["API_URL"])
+}
+
+func TestFindAndProcessFileConfigWithPolicy_FromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/default.json":     {Data: []byte(`{"API_URL": "http://localhost:3000", "MAX_RETRIES": 3}`)},
+		"config/production.jsonc": {Data: []byte(`{"MAX_RETRIES": 10, /* launch */}`)},
+	}
+	// The directory search isn't consulted, so a bad SMOOAI_ENV_CONFIG_DIR is ignored.
+	env := map[string]string{"SMOOAI_ENV_CONFIG_DIR": "/nonexistent", "SMOOAI_CONFIG_ENV": "production"}
+	result, err := findAndProcessFileConfigWithPolicy(env, NullSet, BuiltinPolicy{}, configSource{fsys: fsys, root: "config"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:3000", result["API_URL"])
+	assert.Equal(t, 10.0, result["MAX_RETRIES"])
+
+	_, err = findAndProcessFileConfigWithPolicy(env, NullSet, BuiltinPolicy{}, configSource{fsys: fsys, root: "missing"})
+	assert.ErrorContains(t, err, "required default.json not found in missing")
+}
+
+func TestConfigFSOptions(t *testing.T) {
+	fsys := fstest.MapFS{"default.json": {Data: []byte(`{"API_URL": "https://embedded.example"}`)}}
+	env := map[string]string{"SMOOAI_CONFIG_ENV": "production"}
+
+	mgr := NewConfigManager(WithCMConfigFS(fsys, "."), WithCMEnvOverride(env))
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://embedded.example", v)
+
+	local := NewLocalConfigManager(WithConfigFS(fsys, ""), WithEnvOverride(env))
+	v, err = local.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://embedded.example", v)
+}
+
+func TestWithCMConfigFiles(t *testing.T) {
+	files := map[string]string{
+		"default.json":    `{"API_URL": "https://default.example", "MAX_RETRIES": 3}`,
+		"production.json": `{"MAX_RETRIES": 5}`,
+	}
+	mgr := NewConfigManager(WithCMConfigFiles(files), WithCMEnvOverride(map[string]string{"SMOOAI_CONFIG_ENV": "production"}))
+	files["production.json"] = `{"MAX_RETRIES": 9}`
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://default.example", v)
+	v, err = mgr.GetPublicConfig("MAX_RETRIES")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, v)
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileWatchInterval is how often a watched config directory is checked.
+// Polling the directory's modification times keeps the SDK free of a
+// file-notification dependency, and a second is quick enough for edits made
+// by hand.
+var fileWatchInterval = time.Second
+
+// fileStamp identifies one version of a config file.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// fileWatcher polls a config directory and calls onChange whenever a .json
+// or .jsonc file in it is added, removed or rewritten.
+type fileWatcher struct {
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func startFileWatch(dir string, onChange func()) *fileWatcher {
+	w := &fileWatcher{stop: make(chan struct{}), done: make(chan struct{})}
+	last := configDirStamps(dir)
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(fileWatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				current := configDirStamps(dir)
+				if reflect.DeepEqual(current, last) {
+					continue
+				}
+				last = current
+				onChange()
+			}
+		}
+	}()
+	return w
+}
+
+// Stop ends the watch and waits for an in-flight reload to finish.
+// Idempotent; a nil watcher is a no-op.
+func (w *fileWatcher) Stop() {
+	if w == nil {
+		return
+	}
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// configDirStamps stamps every config file in dir. An unreadable directory
+// has no files, so its reappearance counts as a change.
+func configDirStamps(dir string) map[string]fileStamp {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	stamps := make(map[string]fileStamp, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".jsonc")) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		stamps[name] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+	return stamps
+}
+
+// watchConfigDir starts watching the config directory src resolves to, or
+// returns nil when there is nothing on disk to watch: the files come from an
+// fs.FS, or no directory was found.
+func watchConfigDir(src configSource, env map[string]string, log *slog.Logger, onChange func()) *fileWatcher {
+	if src.fsys != nil {
+		return nil
+	}
+	dir, err := findConfigDirectoryWithEnv(true, env)
+	if err != nil {
+		log.Warn("not watching config files", "error", err)
+		return nil
+	}
+	return startFileWatch(dir, onChange)
+}
+
+// WithCMFileWatch watches the config directory and, when a config file
+// changes, rebuilds the merged config in one step and notifies change
+// listeners of the keys whose values changed. A change that leaves the
+// files unparseable (mid-save, or a typo) is logged and the current config
+// is kept. Files read through WithCMConfigFS are not watched. Call
+// StopFileWatch to end the watch.
+func WithCMFileWatch() ConfigManagerOption {
+	return func(m *ConfigManager) { m.fileWatch = true }
+}
+
+// StopFileWatch ends the watch started by WithCMFileWatch, waiting for an
+// in-flight reload. Safe to call without one.
+func (m *ConfigManager) StopFileWatch() { m.watcher.Stop() }
+
+// reloadConfigFiles rebuilds the merged config after a config file change.
+func (m *ConfigManager) reloadConfigFiles() {
+	env := m.envMap()
+	if _, err := findAndProcessFileConfigWithPolicy(env, m.nullPolicy, m.builtins, m.configFiles); err != nil {
+		m.log().Warn("ignoring config file change", "error", err)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.initialized {
+		return // the next read loads the new files anyway
+	}
+	m.resetLocked()
+	if err := m.initialize(); err != nil {
+		m.log().Warn("reload after config file change failed", "error", err)
+	}
+}
+
+// WithFileWatch watches the config directory and rebuilds the config in one
+// step when a config file changes. See WithCMFileWatch.
+func WithFileWatch() LocalConfigOption {
+	return func(m *LocalConfigManager) { m.fileWatch = true }
+}
+
+// StopFileWatch ends the watch started by WithFileWatch. Safe to call
+// without one.
+func (m *LocalConfigManager) StopFileWatch() { m.watcher.Stop() }
+
+func (m *LocalConfigManager) reloadConfigFiles() {
+	env := m.getEnv()
+	if _, err := findAndProcessFileConfigWithPolicy(env, NullSet, m.builtins.withoutFallback(), m.configFiles); err != nil {
+		m.log().Warn("ignoring config file change", "error", err)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.initialized {
+		return
+	}
+	before := m.effectiveLocked()
+	m.invalidateLocked()
+	if err := m.initialize(); err != nil {
+		m.log().Warn("reload after config file change failed", "error", err)
+		return
+	}
+	m.notifyWatchersLocked(before)
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fastFileWatch(t *testing.T) {
+	t.Helper()
+	orig := fileWatchInterval
+	fileWatchInterval = 10 * time.Millisecond
+	t.Cleanup(func() { fileWatchInterval = orig })
+}
+
+func TestConfigManager_WithCMFileWatch(t *testing.T) {
+	fastFileWatch(t)
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_URL": "http://old", "TIMEOUT": 5}})
+	mgr := NewConfigManager(
+		WithCMFileWatch(),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}),
+	)
+	defer mgr.StopFileWatch()
+	events := make(chan ConfigChangeEvent, 4)
+	mgr.addChangeListener(func(evt ConfigChangeEvent) { events <- evt })
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "http://old", v)
+
+	// A half-written file is ignored and the current config kept.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "default.json"), []byte(`{"API_URL": `), 0o644))
+	time.Sleep(50 * time.Millisecond)
+	v, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "http://old", v)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "default.json"), []byte(`{"API_URL": "http://new", "TIMEOUT": 5}`), 0o644))
+	select {
+	case evt := <-events:
+		assert.Equal(t, []string{"API_URL"}, evt.Keys)
+		assert.Equal(t, "http://new", evt.Values["API_URL"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change event after editing default.json")
+	}
+	v, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "http://new", v)
+}
+
+func TestLocalConfigManager_WithFileWatch(t *testing.T) {
+	fastFileWatch(t)
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_URL": "http://old"}})
+	mgr := NewLocalConfigManager(
+		WithFileWatch(),
+		WithEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir, "SMOOAI_CONFIG_ENV": "test"}),
+	)
+	defer mgr.StopFileWatch()
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "http://old", v)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.json"), []byte(`{"API_URL": "http://test"}`), 0o644))
+	assert.Eventually(t, func() bool {
+		v, err := mgr.GetPublicConfig("API_URL")
+		return err == nil && v == "http://test"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestStopFileWatch_WithoutWatch(t *testing.T) {
+	NewConfigManager(WithCMEnvOverride(map[string]string{})).StopFileWatch()
+	NewLocalConfigManager(WithEnvOverride(map[string]string{})).StopFileWatch()
+}
@@ -0,0 +1,69 @@
+package config
+
+// Feature-flag evaluation stats — the server aggregates EvaluateFeatureFlag
+// traffic per flag so flag-hygiene tooling (see the flagreport package) can
+// spot flags nobody evaluates any more or that have sat at 100% rollout.
+//
+// Server contract:
+//
+//	GET {BaseURL}/organizations/{orgId}/config/feature-flags/stats?environment=...
+//	{"flags": [{"key": "...", "evaluations": 123, "lastEvaluatedAt": "RFC3339",
+//	            "rolloutPercentage": 100, "rolloutPercentageSince": "RFC3339"}]}
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// FeatureFlagStats is the server's evaluation summary for one flag.
+type FeatureFlagStats struct {
+	Key string `json:"key"`
+	// Evaluations is the total evaluation count in the server's retention
+	// window.
+	Evaluations int64 `json:"evaluations"`
+	// LastEvaluatedAt is the most recent evaluation; zero if never evaluated
+	// within the retention window.
+	LastEvaluatedAt time.Time `json:"lastEvaluatedAt"`
+	// RolloutPercentage is the flag's current rollout (0-100).
+	RolloutPercentage float64 `json:"rolloutPercentage"`
+	// RolloutPercentageSince is when RolloutPercentage last changed.
+	RolloutPercentageSince time.Time `json:"rolloutPercentageSince"`
+}
+
+// FeatureFlagStats fetches evaluation stats for every flag in environment.
+// Pass an empty environment to use the client's default.
+func (c *ConfigClient) FeatureFlagStats(ctx context.Context, environment string) ([]FeatureFlagStats, error) {
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/config/feature-flags/stats?environment=%s",
+		c.orgURL(), url.QueryEscape(env))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("config feature flag stats: %w", err)
+	}
+
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("config feature flag stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError("feature flag stats", resp)
+	}
+
+	var result struct {
+		Flags []FeatureFlagStats `json:"flags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("config feature flag stats decode: %w", err)
+	}
+	return result.Flags, nil
+}
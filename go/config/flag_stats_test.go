@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlagStats_DecodesFlags(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/organizations/"+testOrgID+"/config/feature-flags/stats", r.URL.Path)
+		assert.Equal(t, "production", r.URL.Query().Get("environment"))
+		json.NewEncoder(w).Encode(map[string]any{"flags": []map[string]any{
+			{"key": "NEW_UI", "evaluations": 42, "lastEvaluatedAt": "2026-01-02T03:04:05Z",
+				"rolloutPercentage": 100, "rolloutPercentageSince": "2025-10-01T00:00:00Z"},
+		}})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	stats, err := client.FeatureFlagStats(context.Background(), "production")
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "NEW_UI", stats[0].Key)
+	assert.Equal(t, int64(42), stats[0].Evaluations)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), stats[0].LastEvaluatedAt)
+	assert.Equal(t, 100.0, stats[0].RolloutPercentage)
+}
+
+func TestFeatureFlagStats_HTTPError(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.FeatureFlagStats(context.Background(), "production")
+	assert.ErrorIs(t, err, ErrServerError)
+}
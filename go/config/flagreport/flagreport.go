@@ -0,0 +1,257 @@
+// Package flagreport produces a "stale flags" report for feature-flag
+// hygiene. It cross-references the flags a service declares (schema and/or
+// remote tier metadata) with where code reads them (keyusage.ScanDir) and
+// how they are evaluated in production (ConfigClient.FeatureFlagStats).
+//
+// A flag is reported when it is never evaluated within the evaluation
+// window, has sat at 100% rollout past the rollout window (the flag can be
+// deleted and the code path made permanent), is not referenced in code, or
+// is archived on the server. Archived keys of any tier that code still
+// reads are listed too.
+//
+// Build is pure; gathering the inputs is up to the caller:
+//
+//	usages, _ := keyusage.ScanDir(".")
+//	stats, _ := client.FeatureFlagStats(ctx, "production")
+//	report := flagreport.Build(flagreport.DeclaredFlags(def, nil), usages, stats, flagreport.Options{})
+//	report.WriteText(os.Stdout)
+package flagreport
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	config "github.com/SmooAI/config/go/config"
+	"github.com/SmooAI/config/go/config/keyusage"
+)
+
+// Default windows used when the corresponding Options field is zero.
+const (
+	DefaultEvaluationWindow = 90 * 24 * time.Hour
+	DefaultRolloutWindow    = 60 * 24 * time.Hour
+)
+
+// Reason explains why a flag is considered stale.
+type Reason string
+
+const (
+	// ReasonNotEvaluated: no evaluation within the evaluation window.
+	ReasonNotEvaluated Reason = "not_evaluated"
+	// ReasonFullRollout: rolled out to 100% for longer than the rollout window.
+	ReasonFullRollout Reason = "full_rollout"
+	// ReasonUnreferenced: declared but never read in the scanned code.
+	ReasonUnreferenced Reason = "unreferenced"
+	// ReasonArchived: archived (soft-deleted) on the server.
+	ReasonArchived Reason = "archived"
+)
+
+// Options tunes Build.
+type Options struct {
+	// Now is the reference time. Defaults to time.Now().
+	Now time.Time
+	// EvaluationWindow flags declared flags with no evaluation inside it.
+	// Defaults to DefaultEvaluationWindow (90 days).
+	EvaluationWindow time.Duration
+	// RolloutWindow flags flags that have been at 100% for longer than it.
+	// Defaults to DefaultRolloutWindow (60 days).
+	RolloutWindow time.Duration
+	// Archived are the keys archived on the server, of any tier, with when
+	// (ConfigClient.ArchivedKeys or ConfigManager.ArchivedKeys). Declared
+	// flags among them are reported with ReasonArchived, and code still
+	// reading any of them is listed in Report.ArchivedReads.
+	Archived map[string]time.Time
+}
+
+// StaleFlag is one reported flag.
+type StaleFlag struct {
+	Key     string
+	Reasons []Reason
+	// Usages are the code locations that read the flag; remove them along
+	// with the flag.
+	Usages []keyusage.Usage
+	// Stats is the flag's evaluation summary, or nil when the server had none.
+	Stats *config.FeatureFlagStats
+}
+
+// Report is the result of Build.
+type Report struct {
+	// GeneratedAt is Options.Now.
+	GeneratedAt time.Time
+	// Declared is the number of flags considered.
+	Declared int
+	// Stale are the reported flags, sorted by key.
+	Stale []StaleFlag
+	// ArchivedReads are the archived keys code still reads, sorted by key.
+	ArchivedReads []ArchivedRead
+}
+
+// ArchivedRead is an archived key the scanned code still reads. The reads
+// return nothing from the server any more and break once the key is purged.
+type ArchivedRead struct {
+	Key string
+	// ArchivedAt is when the key was archived; zero if unknown.
+	ArchivedAt time.Time
+	Usages     []keyusage.Usage
+}
+
+// DeclaredFlags returns the sorted union of feature-flag keys declared in def
+// and in remote tier metadata (ConfigClient.KeyTiers). Either may be nil.
+func DeclaredFlags(def *config.ConfigDefinition, remoteTiers map[string]config.ConfigTier) []string {
+	seen := make(map[string]bool)
+	if def != nil {
+		for _, key := range def.Keys(config.TierFeatureFlag) {
+			seen[key] = true
+		}
+	}
+	for key, tier := range remoteTiers {
+		if tier == config.TierFeatureFlag {
+			seen[key] = true
+		}
+	}
+	flags := make([]string, 0, len(seen))
+	for key := range seen {
+		flags = append(flags, key)
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// Build reports the stale flags among declared. Code usages match a flag
+// verbatim or by its UPPER_SNAKE_CASE form, as keyusage.Check does; only
+// feature-flag-tier usages count. When stats is nil, the evaluation-based
+// checks are skipped (no data is not the same as no evaluations); a flag
+// missing from a non-nil stats slice counts as never evaluated.
+func Build(declared []string, usages []keyusage.Usage, stats []config.FeatureFlagStats, opts Options) *Report {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	evalWindow := opts.EvaluationWindow
+	if evalWindow == 0 {
+		evalWindow = DefaultEvaluationWindow
+	}
+	rolloutWindow := opts.RolloutWindow
+	if rolloutWindow == 0 {
+		rolloutWindow = DefaultRolloutWindow
+	}
+
+	usagesByKey := make(map[string][]keyusage.Usage)
+	allUsagesByKey := make(map[string][]keyusage.Usage)
+	for _, u := range usages {
+		if u.Tier == config.TierFeatureFlag {
+			usagesByKey[u.Key] = append(usagesByKey[u.Key], u)
+		}
+		allUsagesByKey[u.Key] = append(allUsagesByKey[u.Key], u)
+	}
+	statsByKey := make(map[string]*config.FeatureFlagStats, len(stats))
+	for i := range stats {
+		statsByKey[stats[i].Key] = &stats[i]
+	}
+
+	report := &Report{GeneratedAt: now, Declared: len(declared)}
+	for _, key := range declared {
+		flag := StaleFlag{Key: key, Usages: usagesByKey[key]}
+		if snake := config.CamelToUpperSnake(key); snake != key {
+			flag.Usages = append(flag.Usages, usagesByKey[snake]...)
+		}
+		flag.Stats = statsByKey[key]
+
+		if stats != nil {
+			if flag.Stats == nil || flag.Stats.LastEvaluatedAt.IsZero() || now.Sub(flag.Stats.LastEvaluatedAt) > evalWindow {
+				flag.Reasons = append(flag.Reasons, ReasonNotEvaluated)
+			}
+			if flag.Stats != nil && flag.Stats.RolloutPercentage >= 100 &&
+				!flag.Stats.RolloutPercentageSince.IsZero() && now.Sub(flag.Stats.RolloutPercentageSince) > rolloutWindow {
+				flag.Reasons = append(flag.Reasons, ReasonFullRollout)
+			}
+		}
+		if len(flag.Usages) == 0 {
+			flag.Reasons = append(flag.Reasons, ReasonUnreferenced)
+		}
+		if _, ok := opts.Archived[key]; ok {
+			flag.Reasons = append(flag.Reasons, ReasonArchived)
+		}
+		if len(flag.Reasons) > 0 {
+			report.Stale = append(report.Stale, flag)
+		}
+	}
+	sort.Slice(report.Stale, func(i, j int) bool { return report.Stale[i].Key < report.Stale[j].Key })
+
+	for key, archivedAt := range opts.Archived {
+		read := ArchivedRead{Key: key, ArchivedAt: archivedAt, Usages: allUsagesByKey[key]}
+		if snake := config.CamelToUpperSnake(key); snake != key {
+			read.Usages = append(read.Usages, allUsagesByKey[snake]...)
+		}
+		if len(read.Usages) > 0 {
+			report.ArchivedReads = append(report.ArchivedReads, read)
+		}
+	}
+	sort.Slice(report.ArchivedReads, func(i, j int) bool { return report.ArchivedReads[i].Key < report.ArchivedReads[j].Key })
+	return report
+}
+
+// WriteText writes a human-readable report, one flag per block with its
+// reasons and the code locations to clean up.
+func (r *Report) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%d of %d feature flags look stale\n", len(r.Stale), r.Declared); err != nil {
+		return err
+	}
+	for _, flag := range r.Stale {
+		reasons := make([]string, len(flag.Reasons))
+		for i, reason := range flag.Reasons {
+			reasons[i] = describe(reason, flag.Stats, r.GeneratedAt)
+		}
+		if _, err := fmt.Fprintf(w, "\n%s: %s\n", flag.Key, strings.Join(reasons, "; ")); err != nil {
+			return err
+		}
+		for _, u := range flag.Usages {
+			if _, err := fmt.Fprintf(w, "  %s\n", u.Pos); err != nil {
+				return err
+			}
+		}
+	}
+	if len(r.ArchivedReads) > 0 {
+		if _, err := fmt.Fprintf(w, "\n%d archived keys are still read in code\n", len(r.ArchivedReads)); err != nil {
+			return err
+		}
+	}
+	for _, read := range r.ArchivedReads {
+		since := ""
+		if !read.ArchivedAt.IsZero() {
+			since = fmt.Sprintf(" %d days ago", daysSince(r.GeneratedAt, read.ArchivedAt))
+		}
+		if _, err := fmt.Fprintf(w, "\n%s: archived%s\n", read.Key, since); err != nil {
+			return err
+		}
+		for _, u := range read.Usages {
+			if _, err := fmt.Fprintf(w, "  %s\n", u.Pos); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func describe(reason Reason, stats *config.FeatureFlagStats, now time.Time) string {
+	switch reason {
+	case ReasonNotEvaluated:
+		if stats == nil || stats.LastEvaluatedAt.IsZero() {
+			return "never evaluated"
+		}
+		return fmt.Sprintf("last evaluated %d days ago", daysSince(now, stats.LastEvaluatedAt))
+	case ReasonFullRollout:
+		return fmt.Sprintf("at 100%% rollout for %d days", daysSince(now, stats.RolloutPercentageSince))
+	case ReasonUnreferenced:
+		return "not referenced in code"
+	case ReasonArchived:
+		return "archived on the server"
+	}
+	return string(reason)
+}
+
+func daysSince(now, t time.Time) int {
+	return int(now.Sub(t) / (24 * time.Hour))
+}
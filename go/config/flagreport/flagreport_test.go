@@ -0,0 +1,125 @@
+package flagreport
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/SmooAI/config/go/config"
+	"github.com/SmooAI/config/go/config/keyusage"
+)
+
+var now = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func daysAgo(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+
+func flagUsage(key string) keyusage.Usage {
+	return keyusage.Usage{
+		Pos:    token.Position{Filename: "main.go", Line: 10, Column: 5},
+		Method: "GetFeatureFlag",
+		Tier:   config.TierFeatureFlag,
+		Key:    key,
+	}
+}
+
+func TestDeclaredFlags_UnionOfSchemaAndRemote(t *testing.T) {
+	def := config.DefineConfig(nil, nil, map[string]any{"properties": map[string]any{
+		"newUi": map[string]any{"type": "boolean"},
+	}})
+	flags := DeclaredFlags(def, map[string]config.ConfigTier{
+		"BETA":    config.TierFeatureFlag,
+		"API_URL": config.TierPublic,
+		"newUi":   config.TierFeatureFlag,
+	})
+	assert.Equal(t, []string{"BETA", "newUi"}, flags)
+}
+
+func TestBuild_ClassifiesStaleFlags(t *testing.T) {
+	declared := []string{"ACTIVE", "DORMANT", "NEVER", "ROLLED_OUT", "DEAD_CODE", "newUi"}
+	usages := []keyusage.Usage{
+		flagUsage("ACTIVE"), flagUsage("DORMANT"), flagUsage("NEVER"), flagUsage("ROLLED_OUT"),
+		flagUsage("NEW_UI"),
+		{Tier: config.TierPublic, Key: "DEAD_CODE"},
+	}
+	stats := []config.FeatureFlagStats{
+		{Key: "ACTIVE", LastEvaluatedAt: daysAgo(1), RolloutPercentage: 50, RolloutPercentageSince: daysAgo(200)},
+		{Key: "DORMANT", LastEvaluatedAt: daysAgo(120)},
+		{Key: "ROLLED_OUT", LastEvaluatedAt: daysAgo(1), RolloutPercentage: 100, RolloutPercentageSince: daysAgo(61)},
+		{Key: "DEAD_CODE", LastEvaluatedAt: daysAgo(1)},
+		{Key: "newUi", LastEvaluatedAt: daysAgo(1), RolloutPercentage: 100, RolloutPercentageSince: daysAgo(10)},
+	}
+
+	report := Build(declared, usages, stats, Options{Now: now})
+	assert.Equal(t, 6, report.Declared)
+
+	got := map[string][]Reason{}
+	for _, f := range report.Stale {
+		got[f.Key] = f.Reasons
+	}
+	assert.Equal(t, map[string][]Reason{
+		"DEAD_CODE":  {ReasonUnreferenced},
+		"DORMANT":    {ReasonNotEvaluated},
+		"NEVER":      {ReasonNotEvaluated},
+		"ROLLED_OUT": {ReasonFullRollout},
+	}, got)
+	assert.Equal(t, "DEAD_CODE", report.Stale[0].Key)
+}
+
+func TestBuild_WithoutStatsOnlyChecksUsage(t *testing.T) {
+	report := Build([]string{"USED", "UNUSED"}, []keyusage.Usage{flagUsage("USED")}, nil, Options{Now: now})
+	require.Len(t, report.Stale, 1)
+	assert.Equal(t, "UNUSED", report.Stale[0].Key)
+	assert.Equal(t, []Reason{ReasonUnreferenced}, report.Stale[0].Reasons)
+}
+
+func TestBuild_CustomWindows(t *testing.T) {
+	stats := []config.FeatureFlagStats{{Key: "F", LastEvaluatedAt: daysAgo(10)}}
+	report := Build([]string{"F"}, []keyusage.Usage{flagUsage("F")}, stats,
+		Options{Now: now, EvaluationWindow: 7 * 24 * time.Hour})
+	require.Len(t, report.Stale, 1)
+	assert.Equal(t, []Reason{ReasonNotEvaluated}, report.Stale[0].Reasons)
+}
+
+func TestReport_WriteText(t *testing.T) {
+	stats := []config.FeatureFlagStats{
+		{Key: "ROLLED_OUT", LastEvaluatedAt: daysAgo(1), RolloutPercentage: 100, RolloutPercentageSince: daysAgo(75)},
+	}
+	report := Build([]string{"ROLLED_OUT", "NEVER"}, []keyusage.Usage{flagUsage("ROLLED_OUT")}, stats, Options{Now: now})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	assert.Equal(t, "2 of 2 feature flags look stale\n"+
+		"\nNEVER: never evaluated; not referenced in code\n"+
+		"\nROLLED_OUT: at 100% rollout for 75 days\n"+
+		"  main.go:10:5\n", buf.String())
+}
+
+func TestBuild_ArchivedKeys(t *testing.T) {
+	usages := []keyusage.Usage{
+		flagUsage("OLD_FLAG"),
+		{Pos: token.Position{Filename: "db.go", Line: 3, Column: 1}, Tier: config.TierSecret, Key: "OLD_DSN"},
+	}
+	archived := map[string]time.Time{"OLD_FLAG": daysAgo(14), "OLD_DSN": {}, "GONE": daysAgo(2)}
+	report := Build([]string{"OLD_FLAG"}, usages, nil, Options{Now: now, Archived: archived})
+
+	require.Len(t, report.Stale, 1)
+	assert.Equal(t, []Reason{ReasonArchived}, report.Stale[0].Reasons)
+	require.Len(t, report.ArchivedReads, 2, "archived keys nothing reads are not listed")
+	assert.Equal(t, "OLD_DSN", report.ArchivedReads[0].Key)
+	assert.Equal(t, "OLD_FLAG", report.ArchivedReads[1].Key)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	assert.Equal(t, "1 of 1 feature flags look stale\n"+
+		"\nOLD_FLAG: archived on the server\n"+
+		"  main.go:10:5\n"+
+		"\n2 archived keys are still read in code\n"+
+		"\nOLD_DSN: archived\n"+
+		"  db.go:3:1\n"+
+		"\nOLD_FLAG: archived 14 days ago\n"+
+		"  main.go:10:5\n", buf.String())
+}
@@ -0,0 +1,201 @@
+package config
+
+// Fleet consistency — each instance can publish a fingerprint of the values
+// snapshot it is serving, and FleetConsistency compares the fingerprints the
+// server has collected for an environment. A replica whose fingerprint
+// differs from the majority is usually a stuck cache (missed invalidation,
+// wedged refresh loop) that would otherwise only show up as "works on some
+// pods".
+//
+// Server contract:
+//
+//	POST {BaseURL}/organizations/{orgId}/config/fingerprints
+//	{"environment": "...", "instanceId": "...", "fingerprint": "sha256:..."}
+//
+//	GET  {BaseURL}/organizations/{orgId}/config/fingerprints?environment=...
+//	{"instances": [{"instanceId": "...", "fingerprint": "...", "reportedAt": "RFC3339"}]}
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// Fingerprint returns a stable digest of a values map: "sha256:" followed by
+// the hex SHA-256 of its canonical JSON encoding (encoding/json sorts map
+// keys, so equal maps always hash equal). Unencodable values hash as the
+// empty map.
+func Fingerprint(values map[string]any) string {
+	data, err := json.Marshal(values)
+	if err != nil || values == nil {
+		data = []byte("{}")
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// InstanceFingerprint is one replica's last reported snapshot fingerprint.
+type InstanceFingerprint struct {
+	InstanceID  string    `json:"instanceId"`
+	Fingerprint string    `json:"fingerprint"`
+	ReportedAt  time.Time `json:"reportedAt"`
+}
+
+// FleetConsistencyReport summarizes the fingerprints reported for an
+// environment.
+type FleetConsistencyReport struct {
+	// Environment is the environment the report covers.
+	Environment string
+	// Majority is the most commonly reported fingerprint. Ties break on the
+	// lexically smallest fingerprint so the report is deterministic.
+	Majority string
+	// Instances are all reporting instances, sorted by InstanceID.
+	Instances []InstanceFingerprint
+	// Divergent are the instances whose fingerprint differs from Majority.
+	Divergent []InstanceFingerprint
+}
+
+// Consistent reports whether every instance serves the same snapshot.
+func (r *FleetConsistencyReport) Consistent() bool {
+	return len(r.Divergent) == 0
+}
+
+// WithFingerprintReporting turns on report mode: after every successful
+// GetAllValues (including 304 refreshes) the client publishes the snapshot
+// fingerprint for instanceID in the background. Reporting is best-effort —
+// failures never affect the fetch.
+func WithFingerprintReporting(instanceID string) ConfigClientOption {
+	return func(c *ConfigClient) {
+		c.fingerprintInstanceID = instanceID
+	}
+}
+
+// SnapshotFingerprint returns the fingerprint of the last GetAllValues
+// snapshot for environment, or "" when none has been fetched. Pass an empty
+// environment to use the client's default.
+func (c *ConfigClient) SnapshotFingerprint(environment string) string {
+	env := c.resolveEnv(environment)
+	c.mu.RLock()
+	snap, ok := c.snapshots[env]
+	c.mu.RUnlock()
+	if !ok {
+		return ""
+	}
+	return Fingerprint(snap.values)
+}
+
+// ReportFingerprint publishes instanceID's fingerprint for environment.
+// Pass an empty environment to use the client's default.
+func (c *ConfigClient) ReportFingerprint(ctx context.Context, environment, instanceID, fingerprint string) error {
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(struct {
+		Environment string `json:"environment"`
+		InstanceID  string `json:"instanceId"`
+		Fingerprint string `json:"fingerprint"`
+	}{env, instanceID, fingerprint})
+	if err != nil {
+		return fmt.Errorf("config report fingerprint: marshal body: %w", err)
+	}
+
+	u := fmt.Sprintf("%s/config/fingerprints", c.orgURL())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("config report fingerprint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		return fmt.Errorf("config report fingerprint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newHTTPError("report fingerprint", resp)
+	}
+	return nil
+}
+
+// FleetConsistency fetches every fingerprint reported for environment and
+// reports which instances diverge from the majority. Pass an empty
+// environment to use the client's default.
+func (c *ConfigClient) FleetConsistency(ctx context.Context, environment string) (*FleetConsistencyReport, error) {
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/config/fingerprints?environment=%s",
+		c.orgURL(), url.QueryEscape(env))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("config fleet consistency: %w", err)
+	}
+
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("config fleet consistency: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError("fleet consistency", resp)
+	}
+
+	var result struct {
+		Instances []InstanceFingerprint `json:"instances"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("config fleet consistency decode: %w", err)
+	}
+
+	return buildFleetReport(env, result.Instances), nil
+}
+
+func buildFleetReport(env string, instances []InstanceFingerprint) *FleetConsistencyReport {
+	sort.Slice(instances, func(i, j int) bool { return instances[i].InstanceID < instances[j].InstanceID })
+
+	counts := make(map[string]int)
+	for _, inst := range instances {
+		counts[inst.Fingerprint]++
+	}
+	majority := ""
+	for fp, n := range counts {
+		if n > counts[majority] || (n == counts[majority] && fp < majority) {
+			majority = fp
+		}
+	}
+
+	report := &FleetConsistencyReport{Environment: env, Majority: majority, Instances: instances}
+	for _, inst := range instances {
+		if inst.Fingerprint != majority {
+			report.Divergent = append(report.Divergent, inst)
+		}
+	}
+	return report
+}
+
+// reportSnapshotAsync publishes the snapshot fingerprint when report mode is
+// on. Runs detached from the caller so a slow report never delays a fetch.
+func (c *ConfigClient) reportSnapshotAsync(env string, values map[string]any) {
+	if c.fingerprintInstanceID == "" {
+		return
+	}
+	fp := Fingerprint(values)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = c.ReportFingerprint(ctx, env, c.fingerprintInstanceID, fp)
+	}()
+}
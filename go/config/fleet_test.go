@@ -0,0 +1,105 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint_StableAcrossMapOrder(t *testing.T) {
+	a := map[string]any{"A": "1", "B": map[string]any{"x": 1.0, "y": 2.0}}
+	b := map[string]any{"B": map[string]any{"y": 2.0, "x": 1.0}, "A": "1"}
+	assert.Equal(t, Fingerprint(a), Fingerprint(b))
+	assert.True(t, strings.HasPrefix(Fingerprint(a), "sha256:"))
+	assert.NotEqual(t, Fingerprint(a), Fingerprint(map[string]any{"A": "2"}))
+	assert.Equal(t, Fingerprint(nil), Fingerprint(map[string]any{}))
+}
+
+func TestFleetConsistency_ReportsDivergentInstances(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/organizations/"+testOrgID+"/config/fingerprints", r.URL.Path)
+		assert.Equal(t, "production", r.URL.Query().Get("environment"))
+		json.NewEncoder(w).Encode(map[string]any{"instances": []map[string]any{
+			{"instanceId": "pod-c", "fingerprint": "sha256:old", "reportedAt": "2026-01-01T00:00:00Z"},
+			{"instanceId": "pod-a", "fingerprint": "sha256:new", "reportedAt": "2026-01-01T00:00:00Z"},
+			{"instanceId": "pod-b", "fingerprint": "sha256:new", "reportedAt": "2026-01-01T00:00:00Z"},
+		}})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	report, err := client.FleetConsistency(context.Background(), "production")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:new", report.Majority)
+	assert.False(t, report.Consistent())
+	require.Len(t, report.Divergent, 1)
+	assert.Equal(t, "pod-c", report.Divergent[0].InstanceID)
+	assert.Equal(t, "pod-a", report.Instances[0].InstanceID)
+}
+
+func TestFleetConsistency_ConsistentFleet(t *testing.T) {
+	report := buildFleetReport("production", []InstanceFingerprint{
+		{InstanceID: "a", Fingerprint: "sha256:x"},
+		{InstanceID: "b", Fingerprint: "sha256:x"},
+	})
+	assert.True(t, report.Consistent())
+	assert.Equal(t, "sha256:x", report.Majority)
+}
+
+func TestFleetConsistency_HTTPError(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.FleetConsistency(context.Background(), "production")
+	assert.ErrorIs(t, err, ErrServerError)
+}
+
+func TestWithFingerprintReporting_PublishesAfterFetch(t *testing.T) {
+	reported := make(chan map[string]string, 1)
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			reported <- body
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"A": "1"}})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL, WithFingerprintReporting("pod-1"))
+	defer client.Close()
+
+	values, err := client.GetAllValues("production")
+	require.NoError(t, err)
+
+	select {
+	case body := <-reported:
+		assert.Equal(t, "pod-1", body["instanceId"])
+		assert.Equal(t, "production", body["environment"])
+		assert.Equal(t, Fingerprint(values), body["fingerprint"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("fingerprint was not reported")
+	}
+	assert.Equal(t, Fingerprint(values), client.SnapshotFingerprint("production"))
+}
+
+func TestSnapshotFingerprint_EmptyBeforeFetch(t *testing.T) {
+	client := NewConfigClient("https://api.example.com", "cid", "sec", testOrgID)
+	defer client.Close()
+	assert.Equal(t, "", client.SnapshotFingerprint("production"))
+}
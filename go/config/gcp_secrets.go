@@ -0,0 +1,325 @@
+package config
+
+// GCP Secret Manager provider for smooai-secret:// references. It calls the
+// REST API's versions.access method directly, so it needs no Google SDK.
+// Tokens come from the default chain (see DefaultGCPTokenSource), which
+// covers service account keys and workload identity on GCE, GKE and Cloud
+// Run.
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	gcpSecretManagerEndpoint = "https://secretmanager.googleapis.com"
+	gcpCloudPlatformScope    = "https://www.googleapis.com/auth/cloud-platform"
+	gcpOAuthTokenURL         = "https://oauth2.googleapis.com/token"
+)
+
+// GCPOption configures GCPSecretManager.
+type GCPOption func(*GCPSecretManager)
+
+// WithGCPTokenSource sets the token source instead of the default chain.
+func WithGCPTokenSource(s CloudTokenSource) GCPOption {
+	return func(p *GCPSecretManager) { p.tokens = s }
+}
+
+// WithGCPEndpoint sends requests to url instead of
+// https://secretmanager.googleapis.com, e.g. a regional endpoint.
+func WithGCPEndpoint(url string) GCPOption {
+	return func(p *GCPSecretManager) { p.endpoint = strings.TrimRight(url, "/") }
+}
+
+// WithGCPEnv reads GOOGLE_* variables through lookup instead of the process
+// environment.
+func WithGCPEnv(lookup EnvFunc) GCPOption {
+	return func(p *GCPSecretManager) { p.lookupEnv = lookup }
+}
+
+// WithGCPHTTPClient sets the HTTP client for Secret Manager and token
+// requests.
+func WithGCPHTTPClient(c *http.Client) GCPOption {
+	return func(p *GCPSecretManager) { p.client = c }
+}
+
+// GCPSecretManager resolves references that are Secret Manager resource
+// names, with or without a version (latest by default):
+//
+//	smooai-secret://projects/my-project/secrets/db-password
+//	smooai-secret://projects/my-project/secrets/db-password/versions/3
+type GCPSecretManager struct {
+	tokens    CloudTokenSource
+	client    *http.Client
+	lookupEnv EnvFunc
+	endpoint  string
+}
+
+// NewGCPSecretManager creates a Secret Manager provider.
+func NewGCPSecretManager(opts ...GCPOption) *GCPSecretManager {
+	p := &GCPSecretManager{client: http.DefaultClient, lookupEnv: os.LookupEnv, endpoint: gcpSecretManagerEndpoint}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.tokens == nil {
+		p.tokens = DefaultGCPTokenSource(p.lookupEnv, p.client)
+	}
+	return p
+}
+
+// Handles reports whether ref is a Secret Manager resource name.
+func (p *GCPSecretManager) Handles(ref string) bool {
+	_, ok := gcpSecretVersion(ref)
+	return ok
+}
+
+// Resolve fetches the secret version's payload.
+func (p *GCPSecretManager) Resolve(ctx context.Context, ref string) (string, error) {
+	name, ok := gcpSecretVersion(ref)
+	if !ok {
+		return "", fmt.Errorf("not a Secret Manager resource name: %s", ref)
+	}
+	token, err := p.tokens.AccessToken(ctx, gcpCloudPlatformScope)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+"/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", cloudJSONError("secretmanager", resp.StatusCode, data)
+	}
+	var out struct {
+		Payload struct {
+			Data []byte `json:"data"` // base64 in JSON
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("secretmanager: decoding response: %w", err)
+	}
+	return string(out.Payload.Data), nil
+}
+
+// gcpSecretVersion returns the secret version ref names:
+// projects/P/secrets/S, which means the latest version, or
+// projects/P/secrets/S/versions/V.
+func gcpSecretVersion(ref string) (string, bool) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 4 && len(parts) != 6 {
+		return "", false
+	}
+	for _, part := range parts {
+		if part == "" {
+			return "", false
+		}
+	}
+	if parts[0] != "projects" || parts[2] != "secrets" {
+		return "", false
+	}
+	if len(parts) == 4 {
+		return ref + "/versions/latest", true
+	}
+	return ref, parts[4] == "versions"
+}
+
+var errNoGCPCredentials = errors.New("no GCP credentials found (checked GOOGLE_APPLICATION_CREDENTIALS, the gcloud application default credentials and the metadata server)")
+
+// DefaultGCPTokenSource returns the application default credentials chain,
+// reading variables through lookupEnv (nil for the process environment):
+//
+//  1. The key file at GOOGLE_APPLICATION_CREDENTIALS (service account or
+//     authorized user)
+//  2. gcloud's application_default_credentials.json
+//  3. The metadata server (GCE, GKE workload identity, Cloud Run), at
+//     GCE_METADATA_HOST or metadata.google.internal
+//
+// Tokens are cached until five minutes before they expire.
+func DefaultGCPTokenSource(lookupEnv EnvFunc, client *http.Client) CloudTokenSource {
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &gcpTokenChain{lookupEnv: lookupEnv, client: client, cache: cloudTokenCache{now: time.Now}}
+}
+
+type gcpTokenChain struct {
+	lookupEnv EnvFunc
+	client    *http.Client
+	cache     cloudTokenCache
+}
+
+// gcpKeyFile is the subset of a credentials JSON file the chain uses.
+type gcpKeyFile struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (c *gcpTokenChain) AccessToken(ctx context.Context, _ string) (string, error) {
+	return c.cache.get(ctx, gcpCloudPlatformScope, c.fetch)
+}
+
+func (c *gcpTokenChain) env(name string) string {
+	v, _ := c.lookupEnv(name)
+	return v
+}
+
+func (c *gcpTokenChain) fetch(ctx context.Context) (cloudToken, error) {
+	if path := c.env("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		return c.keyFile(ctx, path)
+	}
+	if dir := c.gcloudConfigDir(); dir != "" {
+		path := filepath.Join(dir, "application_default_credentials.json")
+		if _, err := os.Stat(path); err == nil {
+			return c.keyFile(ctx, path)
+		}
+	}
+	t, err := c.metadata(ctx)
+	if err != nil {
+		return cloudToken{}, fmt.Errorf("%w: metadata server: %v", errNoGCPCredentials, err)
+	}
+	return t, nil
+}
+
+// gcloudConfigDir is CLOUDSDK_CONFIG, or ~/.config/gcloud.
+func (c *gcpTokenChain) gcloudConfigDir() string {
+	if dir := c.env("CLOUDSDK_CONFIG"); dir != "" {
+		return dir
+	}
+	if home := c.env("HOME"); home != "" {
+		return filepath.Join(home, ".config", "gcloud")
+	}
+	return ""
+}
+
+// keyFile exchanges a service account key or a gcloud user login for a
+// token.
+func (c *gcpTokenChain) keyFile(ctx context.Context, path string) (cloudToken, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cloudToken{}, fmt.Errorf("gcp credentials: %w", err)
+	}
+	var key gcpKeyFile
+	if err := json.Unmarshal(data, &key); err != nil {
+		return cloudToken{}, fmt.Errorf("gcp credentials %s: %w", path, err)
+	}
+	var form url.Values
+	tokenURL := coalesceStr(key.TokenURI, gcpOAuthTokenURL)
+	switch key.Type {
+	case "service_account":
+		assertion, err := gcpServiceAccountJWT(key, tokenURL, time.Now())
+		if err != nil {
+			return cloudToken{}, fmt.Errorf("gcp credentials %s: %w", path, err)
+		}
+		form = url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"}, "assertion": {assertion}}
+	case "authorized_user":
+		form = url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {key.ClientID},
+			"client_secret": {key.ClientSecret},
+			"refresh_token": {key.RefreshToken},
+		}
+	default:
+		return cloudToken{}, fmt.Errorf("gcp credentials %s: unsupported type %q", path, key.Type)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return cloudToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	now := time.Now()
+	body, err := doCloudRequest(c.client, req)
+	if err != nil {
+		return cloudToken{}, fmt.Errorf("gcp token: %w", err)
+	}
+	return decodeOAuthToken(body, now, "gcp token")
+}
+
+// metadata fetches the attached service account's token.
+func (c *gcpTokenChain) metadata(ctx context.Context) (cloudToken, error) {
+	host := coalesceStr(c.env("GCE_METADATA_HOST"), "metadata.google.internal")
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	// Off GCP the metadata host doesn't resolve or hangs; don't let it eat
+	// the whole resolution timeout.
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimRight(host, "/")+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return cloudToken{}, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	now := time.Now()
+	body, err := doCloudRequest(c.client, req)
+	if err != nil {
+		return cloudToken{}, err
+	}
+	return decodeOAuthToken(body, now, "metadata server")
+}
+
+// gcpServiceAccountJWT signs the RS256 assertion a service account key
+// exchanges for a token.
+func gcpServiceAccountJWT(key gcpKeyFile, audience string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", errors.New("private_key is not PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("private_key: %w", err)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("private_key is not an RSA key")
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   key.ClientEmail,
+		"scope": gcpCloudPlatformScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCPSecretVersion(t *testing.T) {
+	name, ok := gcpSecretVersion("projects/p/secrets/db")
+	require.True(t, ok)
+	assert.Equal(t, "projects/p/secrets/db/versions/latest", name)
+	name, ok = gcpSecretVersion("projects/p/secrets/db/versions/3")
+	require.True(t, ok)
+	assert.Equal(t, "projects/p/secrets/db/versions/3", name)
+
+	for _, ref := range []string{
+		"projects/p/secrets",
+		"projects/p/secrets/",
+		"projects/p/topics/db",
+		"projects/p/secrets/db/aliases/3",
+		testSecretARN,
+	} {
+		_, ok := gcpSecretVersion(ref)
+		assert.False(t, ok, ref)
+	}
+}
+
+func TestGCPSecretManager_Resolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer ya29.token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/v1/projects/p/secrets/db/versions/latest:access":
+			json.NewEncoder(w).Encode(map[string]any{"payload": map[string]any{"data": []byte(`{"password":"hunter2"}`)}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":{"code":404,"message":"Secret [projects/p/secrets/gone] not found.","status":"NOT_FOUND"}}`)
+		}
+	}))
+	defer server.Close()
+
+	p := NewGCPSecretManager(WithGCPEndpoint(server.URL), WithGCPTokenSource(StaticCloudToken("ya29.token")))
+	assert.True(t, p.Handles("projects/p/secrets/db"))
+	assert.False(t, p.Handles(testSecretARN))
+
+	payload, err := p.Resolve(context.Background(), "projects/p/secrets/db")
+	require.NoError(t, err)
+	assert.Equal(t, `{"password":"hunter2"}`, payload)
+
+	_, err = p.Resolve(context.Background(), "projects/p/secrets/gone")
+	assert.EqualError(t, err, "secretmanager: NOT_FOUND: Secret [projects/p/secrets/gone] not found. (HTTP 404)")
+}
+
+func TestDefaultGCPTokenSource_Sources(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		switch r.Form.Get("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:jwt-bearer":
+			parts := strings.Split(r.Form.Get("assertion"), ".")
+			require.Len(t, parts, 3)
+			sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+			require.NoError(t, err)
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig))
+			claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			assert.Contains(t, string(claims), `"iss":"app@p.iam.gserviceaccount.com"`)
+			io.WriteString(w, `{"access_token":"SA","expires_in":3599}`)
+		case "refresh_token":
+			assert.Equal(t, "refresh", r.Form.Get("refresh_token"))
+			io.WriteString(w, `{"access_token":"USER","expires_in":3599}`)
+		}
+	})
+	mux.HandleFunc("GET /computeMetadata/v1/instance/service-accounts/default/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		io.WriteString(w, `{"access_token":"GCE","expires_in":3599,"token_type":"Bearer"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	writeKey := func(v map[string]any) string {
+		path := filepath.Join(t.TempDir(), "key.json")
+		data, _ := json.Marshal(v)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+	serviceAccount := writeKey(map[string]any{
+		"type":         "service_account",
+		"client_email": "app@p.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    server.URL + "/token",
+	})
+	user := writeKey(map[string]any{
+		"type": "authorized_user", "client_id": "id", "client_secret": "s", "refresh_token": "refresh", "token_uri": server.URL + "/token",
+	})
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"service account", map[string]string{"GOOGLE_APPLICATION_CREDENTIALS": serviceAccount}, "SA"},
+		{"authorized user", map[string]string{"GOOGLE_APPLICATION_CREDENTIALS": user}, "USER"},
+		{"metadata server", map[string]string{"GCE_METADATA_HOST": strings.TrimPrefix(server.URL, "http://"), "HOME": t.TempDir()}, "GCE"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := DefaultGCPTokenSource(envLookup(tc.env), nil).AccessToken(context.Background(), "")
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, token)
+		})
+	}
+}
+
+func TestDefaultGCPTokenSource_CachesUntilExpiry(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `{"access_token":"GCE","expires_in":3599}`)
+	}))
+	defer server.Close()
+
+	source := DefaultGCPTokenSource(envLookup(map[string]string{"GCE_METADATA_HOST": server.URL}), nil)
+	for range 2 {
+		_, err := source.AccessToken(context.Background(), "")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Get reads key through its own tier (GetSecretConfig for a known secret,
+// GetFeatureFlag for a flag, GetPublicConfig otherwise) and converts it to
+// T, so callers don't type-assert:
+//
+//	port, err := config.Get[int](mgr, "PORT")
+//	timeout, err := config.Get[time.Duration](mgr, "TIMEOUT")
+//	db, err := config.Get[DBSettings](mgr, "DB")
+//
+// JSON numbers convert to any numeric type they fit without loss, objects
+// decode into structs and maps, and a string holding JSON ("8080", "true",
+// `{"host": "db"}`) converts like the value it holds, as env vars do. A
+// time.Duration is read from a Go duration string such as "1m30s" or a
+// number of seconds. A key that isn't set returns T's zero value. Any
+// Provider works; one that doesn't know tiers is read with GetPublicConfig.
+func Get[T any](m Provider, key string) (T, error) {
+	var zero T
+	value, err := getOwnTier(m, key)
+	if err != nil || value == nil {
+		return zero, err
+	}
+	return convertValue[T](key, value)
+}
+
+// GetRequired is Get for a key that must be set: one that isn't, or is
+// null, returns a *KeyNotFoundError.
+func GetRequired[T any](m Provider, key string) (T, error) {
+	var zero T
+	value, err := getOwnTier(m, key)
+	if err != nil {
+		return zero, err
+	}
+	if value == nil {
+		return zero, &KeyNotFoundError{Key: key, Environment: providerEnvironment(m)}
+	}
+	return convertValue[T](key, value)
+}
+
+// getOwnTier reads key through the getter of its tier, loading the config
+// first so the remote tiers are known.
+func (m *ConfigManager) getOwnTier(key string) (any, error) {
+	tier, err := m.ownTier(key)
+	if err != nil {
+		return nil, err
+	}
+	switch tier {
+	case TierSecret:
+		return m.GetSecretConfig(key)
+	case TierFeatureFlag:
+		return m.GetFeatureFlag(key)
+	}
+	return m.GetPublicConfig(key)
+}
+
+// ownTier loads the config, so the remote tiers are known, and returns
+// key's tier.
+func (m *ConfigManager) ownTier(key string) (ConfigTier, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err := m.initialize()
+	return m.keyTierLocked(key), err
+}
+
+// convertValue converts a config value to T; see Get.
+func convertValue[T any](key string, value any) (T, error) {
+	var out T
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+	if _, ok := value.(*SecretString); ok {
+		// Converting would copy the secret out of its protected buffer.
+		return out, NewConfigError(fmt.Sprintf("config key %q is a protected secret: read it as *config.SecretString", key))
+	}
+	invalid := func(err error) (T, error) {
+		var zero T
+		return zero, NewConfigError(fmt.Sprintf("config key %q is not a %v: %s", key, reflect.TypeFor[T](), strings.TrimPrefix(err.Error(), "json: ")))
+	}
+	if d, ok := any(&out).(*time.Duration); ok {
+		var sd settingsDuration
+		if err := convertJSON(value, &sd); err != nil {
+			return invalid(err)
+		}
+		*d = time.Duration(sd)
+		return out, nil
+	}
+	if err := convertJSON(value, &out); err != nil {
+		return invalid(err)
+	}
+	return out, nil
+}
+
+// convertJSON decodes value into dst through its JSON form, falling back to
+// the JSON a string value holds.
+func convertJSON(value, dst any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, dst)
+	if s, ok := value.(string); ok && err != nil {
+		if json.Unmarshal([]byte(strings.TrimSpace(s)), dst) == nil {
+			return nil
+		}
+	}
+	return err
+}
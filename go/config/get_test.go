@@ -0,0 +1,107 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	type dbSettings struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	mgr := NewConfigManager(
+		WithCMConfigFiles(map[string]string{"default.json": `{
+			"PORT": 8080,
+			"RATIO": 0.5,
+			"TIMEOUT": "1m30s",
+			"RETRY_AFTER": 2,
+			"DEBUG": true,
+			"DB": {"host": "db", "port": 5432},
+			"DB_JSON": "{\"host\": \"db2\", \"port\": 1}",
+			"PORT_STRING": "9090",
+			"HOSTS": ["a", "b"],
+			"NAME": "svc",
+			"NOTHING": null,
+			"DB_PASSWORD": "hunter2"
+		}`}),
+		WithCMKeyTiers(map[string]ConfigTier{"DB_PASSWORD": TierSecret, "DEBUG": TierFeatureFlag}),
+		WithCMEnvOverride(map[string]string{"SMOOAI_CONFIG_USER_OVERRIDES": "off"}),
+	)
+	defer mgr.Close(context.Background())
+
+	port, err := Get[int](mgr, "PORT")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	ratio, err := Get[float32](mgr, "RATIO")
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.5), ratio)
+
+	timeout, err := Get[time.Duration](mgr, "TIMEOUT")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, timeout)
+
+	retry, err := Get[time.Duration](mgr, "RETRY_AFTER")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, retry)
+
+	debug, err := Get[bool](mgr, "DEBUG")
+	require.NoError(t, err)
+	assert.True(t, debug)
+
+	db, err := Get[dbSettings](mgr, "DB")
+	require.NoError(t, err)
+	assert.Equal(t, dbSettings{Host: "db", Port: 5432}, db)
+
+	db, err = Get[dbSettings](mgr, "DB_JSON")
+	require.NoError(t, err)
+	assert.Equal(t, dbSettings{Host: "db2", Port: 1}, db)
+
+	port, err = Get[int](mgr, "PORT_STRING")
+	require.NoError(t, err)
+	assert.Equal(t, 9090, port)
+
+	hosts, err := Get[[]string](mgr, "HOSTS")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, hosts)
+
+	password, err := Get[string](mgr, "DB_PASSWORD")
+	require.NoError(t, err, "secrets are read through the secret tier")
+	assert.Equal(t, "hunter2", password)
+
+	missing, err := Get[int](mgr, "MISSING")
+	require.NoError(t, err)
+	assert.Zero(t, missing)
+
+	_, err = Get[int](mgr, "RATIO")
+	assert.ErrorContains(t, err, `config key "RATIO" is not a int`)
+
+	_, err = Get[time.Duration](mgr, "NAME")
+	assert.ErrorContains(t, err, `invalid duration "svc"`)
+}
+
+func TestGetRequired(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMConfigFiles(map[string]string{"default.json": `{"PORT": 8080, "NOTHING": null}`}),
+		WithCMEnvOverride(map[string]string{"SMOOAI_CONFIG_USER_OVERRIDES": "off", "SMOOAI_CONFIG_ENV": "test"}),
+	)
+	defer mgr.Close(context.Background())
+
+	port, err := GetRequired[int](mgr, "PORT")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	for _, key := range []string{"MISSING", "NOTHING"} {
+		_, err = GetRequired[int](mgr, key)
+		assert.True(t, errors.Is(err, ErrKeyNotFound), key)
+		var notFound *KeyNotFoundError
+		require.ErrorAs(t, err, &notFound)
+		assert.Equal(t, "test", notFound.Environment)
+	}
+}
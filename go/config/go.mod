@@ -2,13 +2,15 @@ module github.com/SmooAI/config/go/config
 
 go 1.23
 
-require github.com/stretchr/testify v1.10.0
+require (
+	github.com/invopop/jsonschema v0.13.0
+	github.com/stretchr/testify v1.10.0
+)
 
 require (
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
 	github.com/buger/jsonparser v1.1.1 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/invopop/jsonschema v0.13.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for ConfigClient HTTP failures. Match them with errors.Is;
+// use errors.As with *HTTPError for the status code and response body.
+var (
+	// ErrUnauthorized matches an HTTPError with status 401 (after the
+	// client's one token-refresh retry).
+	ErrUnauthorized = errors.New("config unauthorized")
+	// ErrForbidden matches an HTTPError with status 403.
+	ErrForbidden = errors.New("config forbidden")
+	// ErrNotFound matches an HTTPError with status 404.
+	ErrNotFound = errors.New("config not found")
+	// ErrServerError matches an HTTPError with any 5xx status.
+	ErrServerError = errors.New("config server error")
+)
+
+// HTTPError is returned by ConfigClient when the API answers with an
+// unexpected status.
+type HTTPError struct {
+	// Op names the client operation, e.g. "get value".
+	Op string
+	// StatusCode is the HTTP status returned by the server.
+	StatusCode int
+	// Body is the response body text, trimmed of surrounding whitespace.
+	Body string
+}
+
+// Error implements the error interface.
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("config %s: HTTP %d", e.Op, e.StatusCode)
+	}
+	return fmt.Sprintf("config %s: HTTP %d: %s", e.Op, e.StatusCode, e.Body)
+}
+
+// Is supports errors.Is matching against ErrUnauthorized, ErrForbidden,
+// ErrNotFound and ErrServerError, and ErrWriteConflict for 409 Conflict and
+// 412 Precondition Failed.
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrServerError:
+		return e.StatusCode >= 500 && e.StatusCode <= 599
+	case ErrWriteConflict:
+		return e.StatusCode == http.StatusConflict || e.StatusCode == http.StatusPreconditionFailed
+	}
+	return false
+}
+
+// newHTTPError reads resp's body into an HTTPError for op. The caller still
+// owns closing the body.
+func newHTTPError(op string, resp *http.Response) *HTTPError {
+	body, _ := io.ReadAll(resp.Body)
+	return &HTTPError{Op: op, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPError_IsSentinels(t *testing.T) {
+	cases := []struct {
+		status int
+		match  error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusInternalServerError, ErrServerError},
+		{http.StatusServiceUnavailable, ErrServerError},
+		{http.StatusConflict, ErrWriteConflict},
+		{http.StatusPreconditionFailed, ErrWriteConflict},
+	}
+	sentinels := []error{ErrUnauthorized, ErrForbidden, ErrNotFound, ErrServerError, ErrWriteConflict}
+	for _, tc := range cases {
+		err := &HTTPError{Op: "get value", StatusCode: tc.status}
+		for _, sentinel := range sentinels {
+			assert.Equal(t, sentinel == tc.match, errors.Is(err, sentinel), "status %d vs %v", tc.status, sentinel)
+		}
+	}
+}
+
+func TestHTTPError_Message(t *testing.T) {
+	assert.Equal(t, "config get value: HTTP 404: no such key",
+		(&HTTPError{Op: "get value", StatusCode: 404, Body: "no such key"}).Error())
+	assert.Equal(t, "config get value: HTTP 500", (&HTTPError{Op: "get value", StatusCode: 500}).Error())
+}
+
+func TestGetValue_HTTPErrorCarriesStatusAndBody(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("  {\"error\": \"Not found\"}\n"))
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.GetValue("missing", "prod")
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, "get value", httpErr.Op)
+	assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+	assert.Equal(t, `{"error": "Not found"}`, httpErr.Body)
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// ErrHubClosed is returned by ConfigHub.Manager after Close.
+var ErrHubClosed = errors.New("config hub is closed")
+
+// ConfigHub holds one ConfigManager per org for multi-tenant backends that
+// serve many customer orgs from one process. Managers are created on first
+// use and share one HTTP client, so every org's API requests draw on the
+// same connection pool. The zero value is not usable; call NewConfigHub.
+type ConfigHub struct {
+	httpClient *http.Client
+	opts       []ConfigManagerOption
+	orgOpts    func(orgID string) []ConfigManagerOption
+
+	mu       sync.Mutex
+	managers map[string]*ConfigManager
+	closed   bool
+}
+
+// ConfigHubOption is a functional option for ConfigHub.
+type ConfigHubOption func(*ConfigHub)
+
+// WithHubManagerOptions applies opts to every org's manager. Pass
+// WithCMRateLimiter here to cap the API requests of all orgs together.
+func WithHubManagerOptions(opts ...ConfigManagerOption) ConfigHubOption {
+	return func(h *ConfigHub) { h.opts = append(h.opts, opts...) }
+}
+
+// WithHubOrgOptions calls fn for the options of each org's manager, after
+// the WithHubManagerOptions ones, such as the org's credentials.
+func WithHubOrgOptions(fn func(orgID string) []ConfigManagerOption) ConfigHubOption {
+	return func(h *ConfigHub) { h.orgOpts = fn }
+}
+
+// WithHubHTTPClient sets the HTTP client every org's manager sends API and
+// token requests with. Defaults to the one NewConfigClient uses.
+func WithHubHTTPClient(hc *http.Client) ConfigHubOption {
+	return func(h *ConfigHub) { h.httpClient = hc }
+}
+
+// NewConfigHub returns a hub with no managers yet.
+func NewConfigHub(opts ...ConfigHubOption) *ConfigHub {
+	h := &ConfigHub{httpClient: defaultHTTPClient, managers: make(map[string]*ConfigManager)}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Manager returns the manager for orgID, creating it on first use. The
+// org ID is validated (ValidateOrgID) and normalized, so "ABC…" and "abc…"
+// share a manager. Creating one does no I/O: the manager loads on its first
+// read like any other.
+func (h *ConfigHub) Manager(orgID string) (*ConfigManager, error) {
+	if err := ValidateOrgID(orgID); err != nil {
+		return nil, err
+	}
+	orgID = normalizeOrgID(orgID)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+	if m, ok := h.managers[orgID]; ok {
+		return m, nil
+	}
+	opts := slices.Clone(h.opts)
+	if h.httpClient != nil {
+		opts = append(opts, WithCMHTTPClient(h.httpClient))
+	}
+	if h.orgOpts != nil {
+		opts = append(opts, h.orgOpts(orgID)...)
+	}
+	m := NewConfigManager(append(opts, WithOrgID(orgID))...)
+	h.managers[orgID] = m
+	return m, nil
+}
+
+// Orgs lists the orgs that have a manager, sorted.
+func (h *ConfigHub) Orgs() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	orgs := make([]string, 0, len(h.managers))
+	for org := range h.managers {
+		orgs = append(orgs, org)
+	}
+	slices.Sort(orgs)
+	return orgs
+}
+
+// Remove closes and drops orgID's manager, for an org that churned. A later
+// Manager call creates a fresh one.
+func (h *ConfigHub) Remove(ctx context.Context, orgID string) error {
+	orgID = normalizeOrgID(orgID)
+	h.mu.Lock()
+	m, ok := h.managers[orgID]
+	delete(h.managers, orgID)
+	h.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return m.Close(ctx)
+}
+
+// Close closes every org's manager and stops Manager from creating more.
+// It returns the Close errors joined.
+func (h *ConfigHub) Close(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	managers := h.managers
+	h.managers = make(map[string]*ConfigManager)
+	h.mu.Unlock()
+	var errs []error
+	for _, m := range managers {
+		errs = append(errs, m.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
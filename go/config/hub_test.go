@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const otherOrgID = "660e8400-e29b-41d4-a716-446655440000"
+
+func TestConfigHub(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		org := strings.Split(r.URL.Path, "/")[2]
+		json.NewEncoder(w).Encode(map[string]any{"values": map[string]any{"ORG": org}})
+	}))
+	t.Cleanup(server.Close)
+	configDir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}})
+	transport := &countingTransport{}
+	var created []string
+	hub := NewConfigHub(
+		WithHubHTTPClient(&http.Client{Transport: transport}),
+		WithHubManagerOptions(
+			WithBaseURL(server.URL),
+			WithConfigEnvironment("production"),
+			WithCMEnvOverride(map[string]string{"SMOOAI_CONFIG_AUTH_URL": server.URL, "SMOOAI_ENV_CONFIG_DIR": configDir}),
+		),
+		WithHubOrgOptions(func(orgID string) []ConfigManagerOption {
+			created = append(created, orgID)
+			return []ConfigManagerOption{WithAPIKey("key-for-" + orgID)}
+		}),
+	)
+
+	a, err := hub.Manager(testOrgID)
+	require.NoError(t, err)
+	b, err := hub.Manager(otherOrgID)
+	require.NoError(t, err)
+	again, err := hub.Manager(" " + strings.ToUpper(testOrgID))
+	require.NoError(t, err)
+	assert.Same(t, a, again, "org IDs are normalized")
+	assert.Equal(t, []string{testOrgID, otherOrgID}, created)
+	assert.Equal(t, []string{testOrgID, otherOrgID}, hub.Orgs())
+
+	v, err := a.GetPublicConfig("ORG")
+	require.NoError(t, err)
+	assert.Equal(t, testOrgID, v)
+	v, err = b.GetPublicConfig("ORG")
+	require.NoError(t, err)
+	assert.Equal(t, otherOrgID, v)
+	assert.EqualValues(t, 4, transport.calls.Load(), "both orgs use the shared client")
+
+	_, err = hub.Manager("not-an-org")
+	assert.ErrorIs(t, err, ErrInvalidOrgID)
+
+	require.NoError(t, hub.Remove(context.Background(), otherOrgID))
+	assert.Equal(t, []string{testOrgID}, hub.Orgs())
+
+	require.NoError(t, hub.Close(context.Background()))
+	_, err = hub.Manager(testOrgID)
+	assert.ErrorIs(t, err, ErrHubClosed)
+}
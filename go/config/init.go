@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+)
+
+// ErrInit matches any *InitError via errors.Is.
+var ErrInit = errors.New("config failed to initialize")
+
+// InitError reports the config sources Init could not load. The manager
+// still serves what did load, falling back as it does on a lazy load.
+type InitError struct {
+	// Failed maps each layer that failed ("file", "ssm", "kv", "remote",
+	// "secrets" for unresolved smooai-secret:// references, "interpolation"
+	// for ${...} placeholders that didn't resolve, or "source <type>" for
+	// a WithSource layer) to why.
+	Failed map[string]error
+	// Cause is set when loading itself failed or ctx ended first.
+	Cause error
+}
+
+func (e *InitError) Error() string {
+	var parts []string
+	if e.Cause != nil {
+		parts = append(parts, e.Cause.Error())
+	}
+	layers := make([]string, 0, len(e.Failed))
+	for layer := range e.Failed {
+		layers = append(layers, layer)
+	}
+	sort.Strings(layers)
+	for _, layer := range layers {
+		parts = append(parts, fmt.Sprintf("%s: %v", layer, e.Failed[layer]))
+	}
+	return "[Smooai Config] config failed to initialize: " + strings.Join(parts, "; ")
+}
+
+// Is supports errors.Is(err, ErrInit).
+func (e *InitError) Is(target error) bool { return target == ErrInit }
+
+func (e *InitError) Unwrap() []error {
+	var errs []error
+	if e.Cause != nil {
+		errs = append(errs, e.Cause)
+	}
+	for _, err := range e.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Init loads every config source now instead of on the first Get, and
+// returns an *InitError naming each source that failed: config files that
+// exist but don't load, an SSM, KV, remote API or WithSource fetch that
+// failed, secret references that didn't resolve, or placeholders that
+// didn't interpolate. A lazy load only logs those and falls back. Call it
+// at startup, or from a readiness probe, to fail when the config can't be
+// loaded. When the config is already loaded, Init reports on that load.
+// When ctx ends first, Init returns and the load carries on in the
+// background.
+func (m *ConfigManager) Init(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if err := m.initialize(); err != nil {
+			done <- &InitError{Failed: maps.Clone(m.loadErrs), Cause: err}
+			return
+		}
+		if len(m.loadErrs) > 0 {
+			done <- &InitError{Failed: maps.Clone(m.loadErrs)}
+			return
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return &InitError{Cause: fmt.Errorf("config did not load: %w", ctx.Err())}
+	}
+}
+
+// recordLoadErrLocked notes that layer failed to load. Must be called
+// under m.mu.
+func (m *ConfigManager) recordLoadErrLocked(layer string, err error) {
+	if m.loadErrs == nil {
+		m.loadErrs = make(map[string]error)
+	}
+	m.loadErrs[layer] = err
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit_ReportsFailedLayers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+	}))
+	defer server.Close()
+
+	mgr := NewConfigManager(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithOrgID(testOrgID),
+		WithCMConfigFiles(map[string]string{"default.json": `{"API_URL": `}),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_AUTH_URL":       server.URL,
+			"SMOOAI_CONFIG_USER_OVERRIDES": "off",
+			"API_URL":                      "http://env",
+		}),
+		WithCMSchemaKeys(map[string]bool{"API_URL": true}),
+		WithSource(PriorityKV, &memSource{err: errors.New("unreachable")}),
+	)
+	defer mgr.Close(context.Background())
+
+	err := mgr.Init(context.Background())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInit))
+	var initErr *InitError
+	require.ErrorAs(t, err, &initErr)
+	assert.ElementsMatch(t, []string{"file", "remote", "source *config.memSource"}, keysOf(initErr.Failed))
+	assert.NoError(t, initErr.Cause)
+	assert.Contains(t, err.Error(), "file: [Smooai Config] error parsing")
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "http://env", v, "what did load is still served")
+}
+
+func TestInit_OK(t *testing.T) {
+	mock := newMockCMServer("test-key", testOrgID, map[string]any{"API_URL": "https://remote"})
+	defer mock.close()
+
+	mgr := NewConfigManager(
+		WithAPIKey("test-key"),
+		WithBaseURL(mock.server.URL),
+		WithOrgID(testOrgID),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_AUTH_URL":       mock.server.URL,
+			"SMOOAI_CONFIG_USER_OVERRIDES": "off",
+			"SMOOAI_ENV_CONFIG_DIR":        t.TempDir() + "/missing",
+		}),
+	)
+	defer mgr.Close(context.Background())
+
+	require.NoError(t, mgr.Init(context.Background()), "no config directory is not a failure")
+	requests := mock.count()
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote", v)
+	assert.Equal(t, requests, mock.count(), "Get uses the config Init loaded")
+}
+
+func TestInit_ContextEnds(t *testing.T) {
+	release := make(chan struct{})
+	mgr := NewConfigManager(
+		WithCMConfigFiles(map[string]string{"default.json": `{}`}),
+		WithSource(PriorityRemote, blockingSource(release)),
+		WithCMEnvOverride(map[string]string{"SMOOAI_CONFIG_USER_OVERRIDES": "off"}),
+	)
+	defer mgr.Close(context.Background())
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := mgr.Init(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, ErrInit)
+}
+
+// blockingSource is a Source whose Load waits until the channel closes.
+type blockingSource chan struct{}
+
+func (s blockingSource) Load(context.Context) (map[string]any, error) {
+	<-s
+	return nil, nil
+}
+
+func keysOf(m map[string]error) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
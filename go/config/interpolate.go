@@ -0,0 +1,231 @@
+package config
+
+// Interpolation of ${...} placeholders in string config values
+// (WithCMInterpolation / WithInterpolation). ${KEY} is replaced with the
+// merged value of KEY, ${env:NAME} with the environment variable NAME, and
+// $${ is a literal ${. A string that is exactly one ${KEY} placeholder takes
+// KEY's value as is, so "${PORT}" stays a number.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ErrInterpolation matches any *InterpolationError via errors.Is.
+var ErrInterpolation = errors.New("config placeholder cannot be interpolated")
+
+// InterpolationError reports a placeholder that could not be resolved: an
+// unset key or environment variable, a reference cycle, or a missing "}".
+type InterpolationError struct {
+	// Key holds the value the placeholder appears in.
+	Key string
+	// Placeholder is the placeholder as written, e.g. "${DB_HOST}".
+	Placeholder string
+	// Reason says why it could not be resolved.
+	Reason string
+}
+
+func (e *InterpolationError) Error() string {
+	return fmt.Sprintf("[Smooai Config] Cannot interpolate %s in %s: %s", e.Placeholder, e.Key, e.Reason)
+}
+
+// Is supports errors.Is(err, ErrInterpolation).
+func (e *InterpolationError) Is(target error) bool { return target == ErrInterpolation }
+
+// WithCMInterpolation expands ${KEY} and ${env:NAME} placeholders in string
+// values, including strings nested in objects and arrays, as the last step
+// of every load, after deferred values and overrides. A placeholder that
+// can't be resolved fails reads of the key it appears in, and of keys that
+// reference that key, with an *InterpolationError until the next reload;
+// other keys load as usual. Since an
+// interpolated value depends on other keys, pushed changes and overrides
+// reload the whole config, as with WithDeferred.
+func WithCMInterpolation() ConfigManagerOption {
+	return func(m *ConfigManager) { m.interpolate = true }
+}
+
+// WithInterpolation expands ${KEY} and ${env:NAME} placeholders in string
+// values when the config loads. See WithCMInterpolation.
+func WithInterpolation() LocalConfigOption {
+	return func(m *LocalConfigManager) { m.interpolate = true }
+}
+
+// dependentValuesLocked reports whether a key's value may depend on other
+// keys (deferred values or interpolation), so that a change to one key
+// needs a full reload rather than a single-key re-merge.
+func (m *ConfigManager) dependentValuesLocked() bool {
+	return len(m.deferred) > 0 || m.interpolate
+}
+
+// Interpolate expands the placeholders in every value of config in place.
+// lookupEnv resolves ${env:NAME}; nil leaves every environment variable
+// unset. Values are left untouched if any placeholder fails, and the
+// returned error joins one *InterpolationError per failing key.
+func Interpolate(config map[string]any, lookupEnv EnvFunc) error {
+	resolved, failed := interpolate(config, lookupEnv)
+	if len(failed) > 0 {
+		return joinInterpolationErrors(failed)
+	}
+	maps.Copy(config, resolved)
+	return nil
+}
+
+// interpolate expands every value of config without changing it. It
+// returns the expanded value of each key that resolved and the error of
+// each key that didn't.
+func interpolate(config map[string]any, lookupEnv EnvFunc) (map[string]any, map[string]error) {
+	in := &interpolator{config: config, lookupEnv: lookupEnv, resolved: map[string]any{}, failed: map[string]error{}, active: map[string]bool{}}
+	for _, key := range slices.Sorted(maps.Keys(config)) {
+		in.resolve(key)
+	}
+	return in.resolved, in.failed
+}
+
+// joinInterpolationErrors joins the errors of failed in key order.
+func joinInterpolationErrors(failed map[string]error) error {
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(failed)) {
+		// A key that fails because a key it references failed reports the
+		// same error; list it once.
+		if err := failed[key]; !slices.Contains(errs, err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type interpolator struct {
+	config    map[string]any
+	lookupEnv EnvFunc
+	resolved  map[string]any
+	failed    map[string]error
+	active    map[string]bool // keys being resolved, for cycle detection
+	path      []string
+}
+
+// resolve returns key's value with its placeholders expanded.
+func (in *interpolator) resolve(key string) (any, error) {
+	if v, ok := in.resolved[key]; ok {
+		return v, nil
+	}
+	if err, ok := in.failed[key]; ok {
+		return nil, err
+	}
+	in.active[key] = true
+	in.path = append(in.path, key)
+	defer func() {
+		delete(in.active, key)
+		in.path = in.path[:len(in.path)-1]
+	}()
+	v, err := in.expand(key, in.config[key])
+	if err != nil {
+		in.failed[key] = err
+		return nil, err
+	}
+	in.resolved[key] = v
+	return v, nil
+}
+
+// expand expands the strings in v, which belongs to key.
+func (in *interpolator) expand(key string, v any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return in.expandString(key, v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			expanded, err := in.expand(key, item)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = expanded
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			expanded, err := in.expand(key, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = expanded
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func (in *interpolator) expandString(key, s string) (any, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return nil, &InterpolationError{Key: key, Placeholder: s[i:], Reason: `missing "}"`}
+		}
+		placeholder := s[i : i+end+1]
+		value, err := in.lookup(key, placeholder)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 && end+1 == len(s) && b.Len() == 0 {
+			return value, nil // the whole string: keep the value's type
+		}
+		b.WriteString(s[:i])
+		b.WriteString(placeholderString(value))
+		s = s[i+end+1:]
+	}
+}
+
+// lookup resolves one placeholder found in key's value.
+func (in *interpolator) lookup(key, placeholder string) (any, error) {
+	ref := strings.TrimSpace(placeholder[2 : len(placeholder)-1])
+	if name, ok := strings.CutPrefix(ref, "env:"); ok {
+		if in.lookupEnv != nil {
+			if v, ok := in.lookupEnv(name); ok {
+				return v, nil
+			}
+		}
+		return nil, &InterpolationError{Key: key, Placeholder: placeholder, Reason: "environment variable " + name + " is not set"}
+	}
+	if in.active[ref] {
+		cycle := slices.Concat(in.path[slices.Index(in.path, ref):], []string{ref})
+		return nil, &InterpolationError{Key: key, Placeholder: placeholder, Reason: "reference cycle " + strings.Join(cycle, " -> ")}
+	}
+	raw, ok := in.config[ref]
+	if !ok || raw == nil {
+		return nil, &InterpolationError{Key: key, Placeholder: placeholder, Reason: "key " + ref + " is not set"}
+	}
+	return in.resolve(ref)
+}
+
+// placeholderString formats a value spliced into a longer string. Objects
+// and arrays are written as JSON.
+func placeholderString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any, []any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate_ExpandsKeysAndEnv(t *testing.T) {
+	cfg := map[string]any{
+		"DB_HOST":   "db.internal",
+		"DB_PORT":   5432.0,
+		"DB_URL":    "postgres://${DB_HOST}:${DB_PORT}/app",
+		"PORT":      "${DB_PORT}",
+		"CACHE":     "${env:HOME}/.cache",
+		"NESTED":    map[string]any{"urls": []any{"${DB_URL}?sslmode=require", 1.0}},
+		"ESCAPED":   "literal $${DB_HOST}",
+		"CHAINED":   "${DB_URL}#${ESCAPED}",
+		"UNTOUCHED": true,
+	}
+	env := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/dev", true
+		}
+		return "", false
+	}
+	require.NoError(t, Interpolate(cfg, env))
+
+	assert.Equal(t, "postgres://db.internal:5432/app", cfg["DB_URL"])
+	assert.Equal(t, 5432.0, cfg["PORT"], "a lone placeholder keeps the value's type")
+	assert.Equal(t, "/home/dev/.cache", cfg["CACHE"])
+	assert.Equal(t, map[string]any{"urls": []any{"postgres://db.internal:5432/app?sslmode=require", 1.0}}, cfg["NESTED"])
+	assert.Equal(t, "literal ${DB_HOST}", cfg["ESCAPED"])
+	assert.Equal(t, "postgres://db.internal:5432/app#literal ${DB_HOST}", cfg["CHAINED"])
+	assert.Equal(t, true, cfg["UNTOUCHED"])
+}
+
+func TestInterpolate_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    map[string]any
+		reason string
+	}{
+		{"missing key", map[string]any{"A": "${B}"}, "key B is not set"},
+		{"null key", map[string]any{"A": "${B}", "B": nil}, "key B is not set"},
+		{"missing env", map[string]any{"A": "${env:NOPE}"}, "environment variable NOPE is not set"},
+		{"unterminated", map[string]any{"A": "x ${B"}, `missing "}"`},
+		{"self reference", map[string]any{"A": "${A}"}, "reference cycle A -> A"},
+		{"cycle", map[string]any{"A": "${B}", "B": "x${C}", "C": "${A}"}, "reference cycle A -> B -> C -> A"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			original := map[string]any{}
+			for k, v := range tc.cfg {
+				original[k] = v
+			}
+			err := Interpolate(tc.cfg, nil)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInterpolation))
+			var interpErr *InterpolationError
+			require.True(t, errors.As(err, &interpErr))
+			assert.Contains(t, interpErr.Reason, tc.reason)
+			assert.Equal(t, original, tc.cfg, "config untouched on error")
+		})
+	}
+}
+
+func TestInterpolate_ReportsEachFailureOnce(t *testing.T) {
+	err := Interpolate(map[string]any{"A": "${B}", "B": "${MISSING}", "C": "${env:NOPE}"}, nil)
+	require.Error(t, err)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 2)
+}
+
+func TestConfigManager_Interpolation(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{
+		"default.json": map[string]any{
+			"API_HOST": "api.example.com",
+			"API_URL":  "https://${API_HOST}/v1",
+			"TEMPLATE": "$${NOT_EXPANDED}",
+		},
+	})
+	mgr := NewConfigManager(
+		WithCMInterpolation(),
+		WithCMSchemaKeys(map[string]bool{"API_HOST": true}),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir, "API_HOST": "env.example.com"}),
+	)
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://env.example.com/v1", v, "placeholders see the merged value")
+	v, _ = mgr.GetPublicConfig("TEMPLATE")
+	assert.Equal(t, "${NOT_EXPANDED}", v)
+
+	mgr.SetOverride("API_HOST", "override.example.com", 0)
+	v, _ = mgr.GetPublicConfig("API_URL")
+	assert.Equal(t, "https://override.example.com/v1", v, "an override re-expands dependents")
+}
+
+func TestConfigManager_InterpolationErrorFailsItsKeyOnly(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"values": map[string]any{
+			"API_URL":  "https://${API_HOST}",
+			"LINK_URL": "${API_URL}/docs",
+			"NAME":     "orders",
+		}})
+	}))
+	t.Cleanup(server.Close)
+	mgr := newStalenessManager(t, server.URL, WithCMInterpolation())
+
+	for range 3 {
+		_, err := mgr.GetPublicConfig("API_URL")
+		assert.ErrorIs(t, err, ErrInterpolation)
+		_, err = mgr.GetPublicConfig("LINK_URL")
+		assert.ErrorIs(t, err, ErrInterpolation, "a key referencing a failing key fails too")
+		v, err := mgr.GetPublicConfig("NAME")
+		require.NoError(t, err)
+		assert.Equal(t, "orders", v)
+	}
+	assert.EqualValues(t, 1, fetches.Load(), "a failed placeholder doesn't reload on every read")
+
+	var initErr *InitError
+	require.ErrorAs(t, mgr.Init(context.Background()), &initErr)
+	assert.ErrorIs(t, initErr.Failed["interpolation"], ErrInterpolation)
+
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_URL": "https://${API_HOST}"}})
+	plain := NewConfigManager(WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}))
+	v, err := plain.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://${API_HOST}", v, "off by default")
+}
+
+func TestLocalConfigManager_Interpolation(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"DB_URL": "postgres://${DB_USER}@db/${env:DB_NAME}"}})
+	mgr := NewLocalConfigManager(
+		WithInterpolation(),
+		WithSchemaKeys(map[string]bool{"DB_USER": true}),
+		WithEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir, "DB_USER": "app", "DB_NAME": "orders"}),
+	)
+	v, err := mgr.GetPublicConfig("DB_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://app@db/orders", v)
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// unmarshalJSONC decodes JSON with comments (JSONC) into v. Line comments
+// (//), block comments (/* */) and trailing commas before } or ] are
+// accepted, so config files can say why a value is there; everything else
+// must be strict JSON.
+func unmarshalJSONC(data []byte, v any) error {
+	stripped, err := stripJSONC(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(stripped, v)
+}
+
+// stripJSONC blanks out comments and trailing commas. Removed bytes become
+// spaces (newlines are kept), so offsets in json's syntax errors still point
+// at the right place in the original file.
+func stripJSONC(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	copy(out, data)
+	blank := func(from, to int) {
+		for i := from; i < to; i++ {
+			if out[i] != '\n' && out[i] != '\r' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	pendingComma := -1
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			pendingComma = -1
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			start := i
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			blank(start, i)
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			start := i
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			if i+1 >= len(data) {
+				return nil, errors.New("unterminated /* comment")
+			}
+			i++
+			blank(start, i+1)
+		case c == ',':
+			pendingComma = i
+		case c == '}' || c == ']':
+			if pendingComma >= 0 {
+				out[pendingComma] = ' '
+			}
+			pendingComma = -1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			pendingComma = -1
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalJSONC(t *testing.T) {
+	data := []byte(`{
+	// Raised after the March outage; see INC-42.
+	"MAX_RETRIES": 5,
+	/* block
+	   comment */
+	"API_URL": "https://api.example.com/v1", // URL slashes are not a comment
+	"QUOTE": "a \"// not a comment\" b",
+	"TAGS": ["a", "b",],
+}
+`)
+	var got map[string]any
+	require.NoError(t, unmarshalJSONC(data, &got))
+	assert.Equal(t, map[string]any{
+		"MAX_RETRIES": 5.0,
+		"API_URL":     "https://api.example.com/v1",
+		"QUOTE":       `a "// not a comment" b`,
+		"TAGS":        []any{"a", "b"},
+	}, got)
+}
+
+func TestUnmarshalJSONC_StillStrict(t *testing.T) {
+	var got map[string]any
+	assert.Error(t, unmarshalJSONC([]byte(`{"A": 1,, }`), &got))
+	assert.Error(t, unmarshalJSONC([]byte(`{"A": 1 /* open`), &got))
+	assert.Error(t, unmarshalJSONC([]byte(`{A: 1}`), &got))
+}
+
+func TestStripJSONC_KeepsOffsets(t *testing.T) {
+	data := []byte("{\n// note\n\"A\": 1,\n}")
+	stripped, err := stripJSONC(data)
+	require.NoError(t, err)
+	assert.Len(t, stripped, len(data))
+	assert.Equal(t, "{\n       \n\"A\": 1 \n}", string(stripped))
+}
@@ -0,0 +1,63 @@
+// Package analysis provides keyusage as a golang.org/x/tools/go/analysis
+// Analyzer, so the config key check runs under go vet, gopls and
+// multichecker drivers. It is a separate module so the config module does
+// not depend on golang.org/x/tools.
+//
+// The schema comes from a bundle file named by the -schema flag. Command
+// smooai-config-keys runs the Analyzer on its own or under go vet:
+//
+//	go vet -vettool=$(which smooai-config-keys) -schema schema.bundle.json ./...
+package analysis
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	config "github.com/SmooAI/config/go/config"
+	"github.com/SmooAI/config/go/config/keyusage"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer reports config key reads whose key isn't declared in the schema,
+// or is declared in a different tier than the getter reads.
+var Analyzer = &analysis.Analyzer{
+	Name: "smooaiconfigkeys",
+	Doc:  "check config keys read through the SmooAI config getters against the schema",
+	URL:  "https://pkg.go.dev/github.com/SmooAI/config/go/config/keyusage/analysis",
+	Run:  run,
+}
+
+var schemaPath string
+
+func init() {
+	Analyzer.Flags.StringVar(&schemaPath, "schema", "", "schema bundle file to check keys against")
+}
+
+// schema loads the bundle once per process; drivers call run once per
+// package.
+var schema = sync.OnceValues(func() (*config.ConfigDefinition, error) {
+	if schemaPath == "" {
+		return nil, errors.New("smooaiconfigkeys: -schema is required")
+	}
+	f, err := os.Open(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return config.ReadSchemaBundle(f)
+})
+
+func run(pass *analysis.Pass) (any, error) {
+	def, err := schema()
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range pass.Files {
+		tf := pass.Fset.File(file.Pos())
+		for _, d := range keyusage.Check(def, keyusage.Inspect(pass.Fset, file, pass.TypesInfo)) {
+			pass.Reportf(tf.Pos(d.Pos.Offset), "%s", d.Message)
+		}
+	}
+	return nil, nil
+}
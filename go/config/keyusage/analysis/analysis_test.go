@@ -0,0 +1,28 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+func TestAnalyzer(t *testing.T) {
+	def := config.DefineConfig(
+		map[string]any{"type": "object", "properties": map[string]any{"apiUrl": map[string]any{"type": "string"}}},
+		map[string]any{"type": "object", "properties": map[string]any{"DB_PASSWORD": map[string]any{"type": "string"}}},
+		map[string]any{"type": "object", "properties": map[string]any{"newUi": map[string]any{"type": "boolean"}}},
+	)
+	path := filepath.Join(t.TempDir(), "schema.bundle.json")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, config.WriteSchemaBundle(f, def))
+	require.NoError(t, f.Close())
+	require.NoError(t, Analyzer.Flags.Set("schema", path))
+
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "sample")
+}
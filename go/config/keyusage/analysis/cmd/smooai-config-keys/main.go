@@ -0,0 +1,13 @@
+// Command smooai-config-keys runs the keyusage Analyzer on its own or as a
+// go vet tool:
+//
+//	smooai-config-keys -schema schema.bundle.json ./...
+//	go vet -vettool=$(which smooai-config-keys) -schema schema.bundle.json ./...
+package main
+
+import (
+	"github.com/SmooAI/config/go/config/keyusage/analysis"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() { singlechecker.Main(analysis.Analyzer) }
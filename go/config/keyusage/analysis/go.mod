@@ -0,0 +1,24 @@
+module github.com/SmooAI/config/go/config/keyusage/analysis
+
+go 1.23
+
+require (
+	github.com/SmooAI/config/go/config v0.0.0
+	github.com/stretchr/testify v1.10.0
+	golang.org/x/tools v0.30.0
+)
+
+require (
+	github.com/bahlo/generic-list-go v0.2.0 // indirect
+	github.com/buger/jsonparser v1.1.1 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/invopop/jsonschema v0.13.0 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/SmooAI/config/go/config => ../..
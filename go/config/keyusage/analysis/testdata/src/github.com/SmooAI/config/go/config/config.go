@@ -0,0 +1,8 @@
+// Package config stubs the getters of the real config package.
+package config
+
+type ConfigManager struct{}
+
+func (*ConfigManager) GetPublicConfig(string) (any, error) { return nil, nil }
+func (*ConfigManager) GetSecretConfig(string) (any, error) { return nil, nil }
+func (*ConfigManager) GetFeatureFlag(string) (any, error)  { return nil, nil }
@@ -0,0 +1,20 @@
+package sample
+
+import config "github.com/SmooAI/config/go/config"
+
+// cache has getters of the same names but isn't a config type.
+type cache struct{}
+
+func (cache) GetPublicConfig(string) (any, error) { return nil, nil }
+
+const flagKey = "NEW_UI"
+
+func use(m *config.ConfigManager, c cache, dynamic string) {
+	m.GetPublicConfig("API_URL")
+	m.GetSecretConfig("DB_PASSWORD")
+	m.GetPublicConfig("DB_PASSWORD") // want `config key "DB_PASSWORD" is read via GetPublicConfig but declared in the secret tier`
+	m.GetFeatureFlag(flagKey)
+	m.GetPublicConfig("MISSING_KEY") // want `config key "MISSING_KEY" is not declared in the schema`
+	m.GetPublicConfig(dynamic)
+	c.GetPublicConfig("NOT_CONFIG")
+}
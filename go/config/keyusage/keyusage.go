@@ -0,0 +1,334 @@
+// Package keyusage statically finds config key reads in Go source and
+// cross-checks them against a ConfigDefinition, so references to undeclared
+// keys fail at build/CI time instead of surfacing as a nil value (or an
+// UndefinedKeyError) at runtime.
+//
+// It recognizes calls to the ConfigManager / LocalConfigManager getters —
+// GetPublicConfig, GetSecretConfig, GetFeatureFlag — and to
+// ConfigClient.EvaluateFeatureFlag whose key argument is a string literal or
+// a constant that folds to one. Keys built at runtime are
+// skipped; they cannot be checked statically.
+//
+// The package uses only go/ast, go/parser, and go/types from the standard
+// library so the config module does not take a dependency on
+// golang.org/x/tools. The go/analysis Analyzer lives in its own module,
+// github.com/SmooAI/config/go/config/keyusage/analysis, and the
+// "smooai-config keys" command runs CheckDir.
+package keyusage
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+// getter describes a recognized key-reading method: the tier it reads, and
+// which argument carries the key.
+type getter struct {
+	tier   config.ConfigTier
+	keyArg int
+	nargs  int
+}
+
+// getters maps the recognized method names to how they read keys.
+var getters = map[string]getter{
+	"GetPublicConfig":     {tier: config.TierPublic, keyArg: 0, nargs: 1},
+	"GetSecretConfig":     {tier: config.TierSecret, keyArg: 0, nargs: 1},
+	"GetFeatureFlag":      {tier: config.TierFeatureFlag, keyArg: 0, nargs: 1},
+	"EvaluateFeatureFlag": {tier: config.TierFeatureFlag, keyArg: 1, nargs: 4},
+}
+
+// builtinKeys are set by the file/env loaders on every merged config and are
+// never declared in a schema.
+var builtinKeys = func() map[string]bool {
+	keys := make(map[string]bool, len(config.BuiltinKeys))
+	for _, key := range config.BuiltinKeys {
+		keys[key] = true
+	}
+	return keys
+}()
+
+// Usage is a single statically-resolved key read.
+type Usage struct {
+	// Pos is the source position of the key argument.
+	Pos token.Position
+	// Method is the getter called, e.g. "GetSecretConfig".
+	Method string
+	// Tier is the tier the getter reads.
+	Tier config.ConfigTier
+	// Key is the literal key string.
+	Key string
+}
+
+// Diagnostic is a key usage that does not match the definition.
+type Diagnostic struct {
+	Usage
+	// Message describes the mismatch.
+	Message string
+}
+
+// String formats the diagnostic as "file:line:col: message", the shape
+// editors and CI annotators expect.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Message)
+}
+
+// configReceivers are the config package types whose getters Inspect
+// recognizes once it knows the receiver's type.
+var configReceivers = map[string]bool{
+	"ConfigManager":      true,
+	"LocalConfigManager": true,
+	"Provider":           true,
+	"ConfigView":         true,
+	"ConfigClient":       true,
+}
+
+// configPackage is the config package's import path.
+var configPackage = reflect.TypeFor[config.ConfigManager]().PkgPath()
+
+// Inspect returns every recognized getter call in file whose key argument is
+// a compile-time string. info may be nil; when set, named constants (e.g.
+// generated key constants) are resolved through it as well as literals, and
+// when it records Selections, calls on types other than the config
+// package's ConfigManager, LocalConfigManager, Provider, ConfigView and
+// ConfigClient are skipped. Calls whose receiver didn't type-check are
+// matched by method name alone.
+func Inspect(fset *token.FileSet, file *ast.File, info *types.Info) []Usage {
+	var usages []Usage
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		g, ok := getters[sel.Sel.Name]
+		if !ok || len(call.Args) != g.nargs || !configMethod(sel, info) {
+			return true
+		}
+		arg := call.Args[g.keyArg]
+		key, ok := stringValue(arg, info)
+		if !ok {
+			return true
+		}
+		usages = append(usages, Usage{
+			Pos:    fset.Position(arg.Pos()),
+			Method: sel.Sel.Name,
+			Tier:   g.tier,
+			Key:    key,
+		})
+		return true
+	})
+	return usages
+}
+
+// configMethod reports whether sel may call a config package getter: its
+// method is declared on one of configReceivers, or info can't tell.
+func configMethod(sel *ast.SelectorExpr, info *types.Info) bool {
+	if info == nil || info.Selections == nil {
+		return true
+	}
+	selection, ok := info.Selections[sel]
+	if !ok {
+		return true
+	}
+	fn, ok := selection.Obj().(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != configPackage {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && configReceivers[named.Obj().Name()]
+}
+
+func stringValue(expr ast.Expr, info *types.Info) (string, bool) {
+	if info != nil {
+		if tv, ok := info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			return constant.StringVal(tv.Value), true
+		}
+	}
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// Check cross-checks usages against def. A usage is reported when its key is
+// not declared in any tier, or is declared only in a different tier than the
+// getter reads (e.g. GetPublicConfig on a secret key). Schema property names
+// match either verbatim or via their UPPER_SNAKE_CASE form, since env-derived
+// keys are conventionally upper-snake. Built-in keys (config.BuiltinKeys)
+// are always accepted.
+func Check(def *config.ConfigDefinition, usages []Usage) []Diagnostic {
+	declared := map[config.ConfigTier]map[string]bool{}
+	for _, tier := range []config.ConfigTier{config.TierPublic, config.TierSecret, config.TierFeatureFlag} {
+		names := map[string]bool{}
+		for _, k := range def.Keys(tier) {
+			names[k] = true
+			names[config.CamelToUpperSnake(k)] = true
+		}
+		declared[tier] = names
+	}
+
+	var diags []Diagnostic
+	for _, u := range usages {
+		if builtinKeys[u.Key] || declared[u.Tier][u.Key] {
+			continue
+		}
+		var elsewhere []string
+		for _, tier := range []config.ConfigTier{config.TierPublic, config.TierSecret, config.TierFeatureFlag} {
+			if tier != u.Tier && declared[tier][u.Key] {
+				elsewhere = append(elsewhere, string(tier))
+			}
+		}
+		msg := fmt.Sprintf("config key %q is not declared in the schema", u.Key)
+		if len(elsewhere) > 0 {
+			msg = fmt.Sprintf("config key %q is read via %s but declared in the %s tier",
+				u.Key, u.Method, strings.Join(elsewhere, ", "))
+		}
+		diags = append(diags, Diagnostic{Usage: u, Message: msg})
+	}
+	return diags
+}
+
+// CheckDir runs ScanDir over dir and checks the usages against def.
+// Diagnostics are sorted by position.
+func CheckDir(def *config.ConfigDefinition, dir string) ([]Diagnostic, error) {
+	usages, err := ScanDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	diags := Check(def, usages)
+	sort.Slice(diags, func(i, j int) bool { return positionLess(diags[i].Pos, diags[j].Pos) })
+	return diags, nil
+}
+
+// ScanDir parses every non-test .go file under dir (recursively, skipping
+// vendor, testdata, and dot-directories) and returns the key usages found,
+// sorted by position. Each package is type-checked against its
+// dependencies' export data, as go vet does, so keys named by constants
+// resolve, including generated key constants from another package. That
+// needs the go command and a module around dir; without them, and in
+// packages with type errors, ScanDir still finds literal keys and constants
+// declared in the same package.
+func ScanDir(dir string) ([]Usage, error) {
+	fset := token.NewFileSet()
+	type pkg struct {
+		dir, name string
+	}
+	var order []pkg
+	packages := map[pkg][]*ast.File{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("keyusage: parse %s: %w", path, err)
+		}
+		p := pkg{dir: filepath.Dir(path), name: file.Name.Name}
+		if _, ok := packages[p]; !ok {
+			order = append(order, p)
+		}
+		packages[p] = append(packages[p], file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	imp := exportImporter(fset, dir)
+	var usages []Usage
+	for _, p := range order {
+		files := packages[p]
+		info := &types.Info{
+			Types:      map[ast.Expr]types.TypeAndValue{},
+			Selections: map[*ast.SelectorExpr]*types.Selection{},
+		}
+		// With an Error func, Check keeps going past type errors and fills
+		// info for everything it could check.
+		conf := types.Config{Importer: imp, Error: func(error) {}}
+		_, _ = conf.Check(p.name, fset, files, info)
+		for _, file := range files {
+			usages = append(usages, Inspect(fset, file, info)...)
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool { return positionLess(usages[i].Pos, usages[j].Pos) })
+	return usages, nil
+}
+
+// exportImporter imports packages from the compiler export data that
+// "go list -export" reports for the packages under dir and their
+// dependencies, building them if needed.
+func exportImporter(fset *token.FileSet, dir string) types.Importer {
+	exports := map[string]string{}
+	cmd := exec.Command("go", "list", "-e", "-export", "-deps", "-f", "{{if .Export}}{{.ImportPath}}={{.Export}}{{end}}", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			if path, file, ok := strings.Cut(line, "="); ok {
+				exports[path] = file
+			}
+		}
+	}
+	return importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		file, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("keyusage: no export data for %s", path)
+		}
+		return os.Open(file)
+	})
+}
+
+func positionLess(a, b token.Position) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
@@ -0,0 +1,213 @@
+package keyusage
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+func testDefinition() *config.ConfigDefinition {
+	return config.DefineConfig(
+		map[string]any{"type": "object", "properties": map[string]any{"apiUrl": map[string]any{"type": "string"}}},
+		map[string]any{"type": "object", "properties": map[string]any{"DB_PASSWORD": map[string]any{"type": "string"}}},
+		map[string]any{"type": "object", "properties": map[string]any{"newUi": map[string]any{"type": "boolean"}}},
+	)
+}
+
+const sampleSrc = `package sample
+
+import config "github.com/SmooAI/config/go/config"
+
+const flagKey = "NEW_UI"
+
+func use(m *config.ConfigManager, dynamic string) {
+	m.GetPublicConfig("API_URL")
+	m.GetPublicConfig("apiUrl")
+	m.GetSecretConfig("DB_PASSWORD")
+	m.GetPublicConfig("DB_PASSWORD")
+	m.GetFeatureFlag(flagKey)
+	m.GetPublicConfig("MISSING_KEY")
+	m.GetPublicConfig("ENV")
+	m.GetPublicConfig(dynamic)
+}
+`
+
+func parseSample(t *testing.T) (*token.FileSet, *ast.File) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "sample.go", sampleSrc, 0)
+	require.NoError(t, err)
+	return fset, file
+}
+
+func TestInspect_LiteralKeysOnly(t *testing.T) {
+	fset, file := parseSample(t)
+	usages := Inspect(fset, file, nil)
+
+	var keys []string
+	for _, u := range usages {
+		keys = append(keys, u.Key)
+	}
+	assert.Equal(t, []string{"API_URL", "apiUrl", "DB_PASSWORD", "DB_PASSWORD", "MISSING_KEY", "ENV"}, keys)
+	assert.Equal(t, config.TierSecret, usages[2].Tier)
+	assert.Equal(t, 8, usages[0].Pos.Line)
+}
+
+func TestInspect_ResolvesConstantsWithTypeInfo(t *testing.T) {
+	fset, file := parseSample(t)
+	info := &types.Info{Types: map[ast.Expr]types.TypeAndValue{}}
+	// The config import does not resolve outside a module; constants still do.
+	conf := &types.Config{Importer: importer.Default(), Error: func(error) {}}
+	_, _ = conf.Check("sample", fset, []*ast.File{file}, info)
+
+	usages := Inspect(fset, file, info)
+	var flagKeys []string
+	for _, u := range usages {
+		if u.Tier == config.TierFeatureFlag {
+			flagKeys = append(flagKeys, u.Key)
+		}
+	}
+	assert.Equal(t, []string{"NEW_UI"}, flagKeys)
+}
+
+func TestCheck_ReportsUndeclaredAndWrongTier(t *testing.T) {
+	fset, file := parseSample(t)
+	diags := Check(testDefinition(), Inspect(fset, file, nil))
+
+	require.Len(t, diags, 2)
+	assert.Equal(t, "DB_PASSWORD", diags[0].Key)
+	assert.Contains(t, diags[0].Message, "declared in the secret tier")
+	assert.Equal(t, "MISSING_KEY", diags[1].Key)
+	assert.Contains(t, diags[1].Message, "not declared in the schema")
+	assert.Contains(t, diags[1].String(), "sample.go:13:")
+}
+
+func TestCheckDir_SkipsTestFilesAndTestdata(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sample.go"), []byte(sampleSrc), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sample_test.go"),
+		[]byte("package sample\n\nfunc x(m mgr) { m.GetPublicConfig(\"IN_TEST\") }\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "testdata"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "testdata", "x.go"),
+		[]byte("package x\n\nfunc x(m any) { m.GetPublicConfig(\"IN_TESTDATA\") }\n"), 0o644))
+
+	diags, err := CheckDir(testDefinition(), dir)
+	require.NoError(t, err)
+	require.Len(t, diags, 2)
+	assert.Equal(t, "DB_PASSWORD", diags[0].Key)
+	assert.Equal(t, "MISSING_KEY", diags[1].Key)
+}
+
+func TestScanDir_RecognizesEvaluateFeatureFlag(t *testing.T) {
+	dir := t.TempDir()
+	src := `package sample
+
+func use(c client, ctx any) {
+	c.EvaluateFeatureFlag(ctx, "NEW_CHECKOUT", nil, "")
+	c.EvaluateFeatureFlag(ctx, "WRONG_ARITY")
+	c.GetFeatureFlag("NEW_UI")
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "flags.go"), []byte(src), 0o644))
+
+	usages, err := ScanDir(dir)
+	require.NoError(t, err)
+	require.Len(t, usages, 2)
+	assert.Equal(t, "NEW_CHECKOUT", usages[0].Key)
+	assert.Equal(t, "EvaluateFeatureFlag", usages[0].Method)
+	assert.Equal(t, config.TierFeatureFlag, usages[0].Tier)
+	assert.Equal(t, "NEW_UI", usages[1].Key)
+}
+
+func TestCheck_AcceptsEveryBuiltinKey(t *testing.T) {
+	var usages []Usage
+	for _, key := range config.BuiltinKeys {
+		usages = append(usages, Usage{Method: "GetPublicConfig", Tier: config.TierPublic, Key: key})
+	}
+	assert.Empty(t, Check(testDefinition(), usages))
+}
+
+// writeConfigModule writes a module that requires this config module from
+// disk, with files under dir.
+func writeConfigModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root, err := filepath.Abs("..")
+	require.NoError(t, err)
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/app\n\ngo 1.23\n\n" +
+		"require github.com/SmooAI/config/go/config v0.0.0\n\n" +
+		"replace github.com/SmooAI/config/go/config => " + root + "\n"
+	files["go.sum"] = string(sum)
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+	}
+	return dir
+}
+
+func TestScanDir_ResolvesGeneratedKeyConstants(t *testing.T) {
+	dir := writeConfigModule(t, map[string]string{
+		"keys/keys.go": "package keys\n\nconst NewUI = \"NEW_UI\"\n",
+		"app/app.go": `package app
+
+import (
+	config "github.com/SmooAI/config/go/config"
+
+	"example.com/app/keys"
+)
+
+const local = "LOCAL_FLAG"
+
+func use(m *config.ConfigManager, p config.Provider) {
+	m.GetFeatureFlag(keys.NewUI)
+	p.GetFeatureFlag(local)
+}
+`,
+	})
+
+	usages, err := ScanDir(dir)
+	require.NoError(t, err)
+	var got []string
+	for _, u := range usages {
+		got = append(got, u.Key)
+	}
+	assert.Equal(t, []string{"NEW_UI", "LOCAL_FLAG"}, got)
+}
+
+func TestScanDir_SkipsOtherReceivers(t *testing.T) {
+	dir := writeConfigModule(t, map[string]string{
+		"app/app.go": `package app
+
+import config "github.com/SmooAI/config/go/config"
+
+type cache struct{}
+
+func (cache) GetPublicConfig(string) (any, error) { return nil, nil }
+
+type wrapped struct{ *config.ConfigManager }
+
+func use(c cache, w wrapped) {
+	c.GetPublicConfig("NOT_CONFIG")
+	w.GetPublicConfig("EMBEDDED")
+}
+`,
+	})
+
+	usages, err := ScanDir(dir)
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	assert.Equal(t, "EMBEDDED", usages[0].Key, "a promoted config method still counts")
+}
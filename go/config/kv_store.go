@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// kvLoadTimeout bounds one load of a KV prefix.
+const kvLoadTimeout = 10 * time.Second
+
+// kvWatchBackoff bounds the retry delay after a failed KV watch.
+var kvWatchBackoff = struct{ initial, max time.Duration }{time.Second, 30 * time.Second}
+
+// KVStore is a key-value store that holds config under a key prefix, such
+// as Consul KV (NewConsulKV) or etcd (NewEtcdKV).
+type KVStore interface {
+	// List returns every key under prefix, with the prefix removed, and the
+	// store's index: a number that changes whenever a key under prefix
+	// does.
+	List(ctx context.Context, prefix string) (map[string]string, uint64, error)
+	// Wait blocks until a key under prefix changes after index and returns
+	// the new index. It may also return the same index when the store's
+	// own wait time passes. An index of zero returns the current index
+	// without blocking.
+	Wait(ctx context.Context, prefix string, index uint64) (uint64, error)
+}
+
+// WithCMKVStore loads every key under prefix (e.g. "myapp/prod/") from
+// store on each load, and merges them between the SSM parameters and the
+// remote API: file < SSM < KV < remote < env. Without SmooAI API
+// credentials, the KV layer takes the remote API's place. Keys are named
+// like SSM parameters: myapp/prod/DB_HOST is DB_HOST and deeper names become
+// nested objects. Values are coerced by the schema types like env vars.
+// When a load fails, the last keys loaded are kept, with a warning.
+func WithCMKVStore(store KVStore, prefix string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.kv = &kvSource{store: store, prefix: prefix} }
+}
+
+// WithCMKVWatch watches the WithCMKVStore prefix and, when a key under it
+// changes, rebuilds the merged config in one step and notifies change
+// listeners of the keys whose values changed. Close stops the watch.
+func WithCMKVWatch() ConfigManagerOption {
+	return func(m *ConfigManager) { m.kvWatch = true }
+}
+
+// kvSource is the KV store layer.
+type kvSource struct {
+	store  KVStore
+	prefix string
+	// last is the last successful load, served when a load fails, and
+	// index the store's index at that load.
+	last  map[string]any
+	index uint64
+}
+
+// load fetches the prefix.
+func (s *kvSource) load(schemaTypes map[string]string) (map[string]any, uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), kvLoadTimeout)
+	defer cancel()
+	pairs, index, err := s.store.List(ctx, s.prefix)
+	if err != nil {
+		return nil, 0, err
+	}
+	values := make(map[string]any, len(pairs))
+	for name, raw := range pairs {
+		parts := strings.Split(strings.Trim(name, "/"), "/")
+		var value any = raw
+		if typ, ok := schemaTypes[parts[0]]; ok && len(parts) == 1 {
+			value = coerceEnvValue(typ, raw)
+		}
+		setNested(values, parts, value)
+	}
+	return values, index, nil
+}
+
+// loadKVLocked returns the KV layer, or the last good one when the load
+// fails. Must be called under m.mu.
+func (m *ConfigManager) loadKVLocked(schemaTypes map[string]string) map[string]any {
+	if m.kv == nil {
+		return nil
+	}
+	values, index, err := m.kv.load(schemaTypes)
+	if err != nil {
+		m.log().Warn("failed to load KV keys", "prefix", m.kv.prefix, "error", err)
+		m.recordLoadErrLocked("kv", err)
+		return m.kv.last
+	}
+	m.kv.last, m.kv.index = values, index
+	return values
+}
+
+// reloadKV rebuilds the merged config when the store's index is no longer
+// the one loaded.
+func (m *ConfigManager) reloadKV(index uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.initialized || m.kv.index == index {
+		return // the next read loads the new keys anyway, or already has
+	}
+	m.resetLocked()
+	if err := m.initialize(); err != nil {
+		m.log().Warn("reload after KV change failed", "error", err)
+	}
+}
+
+// kvWatcher calls onChange with the store's index whenever it changes, and
+// once with the index it starts at, so a change between the first load and
+// the start of the watch isn't lost.
+type kvWatcher struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func startKVWatch(store KVStore, prefix string, log *slog.Logger, onChange func(index uint64)) *kvWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &kvWatcher{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		var index uint64
+		backoff := kvWatchBackoff.initial
+		for {
+			next, err := store.Wait(ctx, prefix, index)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Warn("KV watch failed, retrying", "prefix", prefix, "backoff", backoff, "error", err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				backoff = min(backoff*2, kvWatchBackoff.max)
+				continue
+			}
+			backoff = kvWatchBackoff.initial
+			if next != index {
+				onChange(next)
+			}
+			index = next
+		}
+	}()
+	return w
+}
+
+// Stop ends the watch and waits for an in-flight reload to finish.
+func (w *kvWatcher) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// setNested stores value in values under the path parts, creating nested
+// objects for all but the last part. An empty last part is skipped.
+func setNested(values map[string]any, parts []string, value any) {
+	key := parts[len(parts)-1]
+	if key == "" {
+		return
+	}
+	target := values
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := target[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			target[part] = next
+		}
+		target = next
+	}
+	target[key] = value
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memKV is an in-memory KVStore whose index counts writes.
+type memKV struct {
+	mu      sync.Mutex
+	values  map[string]string
+	index   uint64
+	changed chan struct{}
+}
+
+func newMemKV(values map[string]string) *memKV {
+	return &memKV{values: values, index: 1, changed: make(chan struct{})}
+}
+
+func (s *memKV) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	s.index++
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *memKV) List(_ context.Context, prefix string) (map[string]string, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.values), s.index, nil
+}
+
+func (s *memKV) Wait(ctx context.Context, _ string, index uint64) (uint64, error) {
+	for {
+		s.mu.Lock()
+		current, changed := s.index, s.changed
+		s.mu.Unlock()
+		if index == 0 || current != index {
+			return current, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+func TestConfigManager_KVStore(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+		"API_URL": "https://file",
+		"ONLY_IN": "file",
+	}})
+	store := newMemKV(map[string]string{
+		"API_URL":     "https://kv",
+		"MAX_RETRIES": "5",
+		"db/host":     "db.internal",
+		"FROM_ENV":    "kv",
+	})
+	mgr := NewConfigManager(
+		WithCMKVStore(store, "myapp/prod/"),
+		WithCMSchemaKeys(map[string]bool{"FROM_ENV": true}),
+		WithCMSchemaTypes(map[string]string{"MAX_RETRIES": "number"}),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir, "FROM_ENV": "env"}),
+	)
+
+	for key, want := range map[string]any{
+		"API_URL":     "https://kv",
+		"ONLY_IN":     "file",
+		"MAX_RETRIES": 5,
+		"db":          map[string]any{"host": "db.internal"},
+		"FROM_ENV":    "env",
+	} {
+		v, err := mgr.GetPublicConfig(key)
+		require.NoError(t, err)
+		assert.Equal(t, want, v, key)
+	}
+}
+
+func TestConfigManager_KVWatch(t *testing.T) {
+	store := newMemKV(map[string]string{"API_URL": "https://v1"})
+	mgr := NewConfigManager(WithCMKVStore(store, ""), WithCMKVWatch(), WithCMEnvOverride(map[string]string{}))
+	defer mgr.Close(context.Background())
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://v1", v)
+
+	store.set("API_URL", "https://v2")
+	assert.Eventually(t, func() bool {
+		v, _ := mgr.GetPublicConfig("API_URL")
+		return v == "https://v2"
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"maps"
+	"slices"
+)
+
+// WithLazyRemoteKeys fetches remote values one key at a time, the first
+// time each is read, instead of fetching every value of the environment on
+// load. It suits a service that reads a handful of keys from an org with
+// thousands. Reloads (Invalidate, refreshes) fetch again only the keys read
+// so far.
+//
+// Methods that look at the whole config (Has, Redacted, Export, schema
+// validation) see only the remote keys read so far, and the remote tier
+// and archive metadata of the bulk fetch is not available.
+func WithLazyRemoteKeys() ConfigManagerOption {
+	return func(m *ConfigManager) {
+		m.lazyRemote = true
+		m.lazyKeys = make(map[string]bool)
+	}
+}
+
+// fetchRemoteLocked fetches the remote values for configEnv: all of them,
+// or under WithLazyRemoteKeys the keys read so far. Must be called under
+// m.mu.
+func (m *ConfigManager) fetchRemoteLocked(client *ConfigClient, configEnv string) (map[string]any, error) {
+	if !m.lazyRemote {
+		return client.GetAllValues(configEnv)
+	}
+	values := make(map[string]any, len(m.lazyKeys))
+	for _, key := range slices.Sorted(maps.Keys(m.lazyKeys)) {
+		v, err := client.GetValue(key, configEnv)
+		if errors.Is(err, ErrNotFound) || (err == nil && v == nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[key] = v
+	}
+	return values, nil
+}
+
+// fetchLazyKeyLocked fetches key from the remote the first time it is read
+// under WithLazyRemoteKeys and merges it into config. Must be called under
+// m.mu on an initialized manager.
+func (m *ConfigManager) fetchLazyKeyLocked(key string) error {
+	if !m.lazyRemote || m.lazyKeys[key] || m.bakedConfig != nil || m.snapshot != nil {
+		return nil
+	}
+	m.lazyKeys[key] = true
+	if m.dependentValuesLocked() {
+		// Deferred and interpolated values may read key: reload, which
+		// fetches it with the other keys read so far, to recompute them.
+		m.resetLocked()
+		m.previousConfig = nil // the key's first read is not a change
+		return m.initialize()
+	}
+	client, configEnv, ok := m.newRemoteClient(m.envMap())
+	if !ok {
+		return nil
+	}
+	defer client.Close()
+	v, err := client.GetValue(key, configEnv)
+	switch {
+	case errors.Is(err, ErrNotFound) || (err == nil && v == nil):
+		return nil
+	case err != nil:
+		// The next load tries again, as it does for every key read.
+		m.log().Warn("failed to fetch remote config key", "key", key, "error", err)
+		return nil
+	}
+	m.remoteConfig[key] = v
+	if m.lastRemote != nil {
+		m.lastRemote[key] = v
+	}
+	m.mergeKeyLocked(key)
+	m.validateLocked()
+	return nil
+}
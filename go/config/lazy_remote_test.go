@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyedRemote serves values in bulk and one key at a time, and records the
+// config paths requested.
+func keyedRemote(t *testing.T, values map[string]any) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		_, rest, _ := strings.Cut(r.URL.Path, "/config/")
+		mu.Lock()
+		paths = append(paths, rest)
+		mu.Unlock()
+		key, single := strings.CutPrefix(rest, "values/")
+		if !single {
+			json.NewEncoder(w).Encode(map[string]any{"values": values})
+			return
+		}
+		v, ok := values[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"value": v})
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		out := paths
+		paths = nil
+		return out
+	}
+}
+
+func TestConfigManager_LazyRemoteKeys(t *testing.T) {
+	server, requested := keyedRemote(t, map[string]any{"API_URL": "https://remote", "OTHER": "x"})
+	mgr := newStalenessManager(t, server.URL, WithLazyRemoteKeys())
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote", v)
+	v, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote", v)
+	v, err = mgr.GetPublicConfig("MISSING")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+	assert.Equal(t, []string{"values/API_URL", "values/MISSING"}, requested(), "each key is fetched once, never in bulk")
+
+	has, err := mgr.Has("OTHER")
+	require.NoError(t, err)
+	assert.False(t, has, "keys not read yet are not fetched")
+
+	t.Run("a reload fetches only the keys read", func(t *testing.T) {
+		mgr.Invalidate()
+		v, err := mgr.GetPublicConfig("API_URL")
+		require.NoError(t, err)
+		assert.Equal(t, "https://remote", v)
+		assert.Equal(t, []string{"values/API_URL", "values/MISSING"}, requested())
+	})
+}
+
+func TestConfigManager_LazyRemoteKeysFailure(t *testing.T) {
+	server, failing := flakyRemote(t, map[string]any{"API_URL": "https://remote"})
+	mgr := newStalenessManager(t, server.URL, WithLazyRemoteKeys(), WithMaxStaleness(time.Hour))
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Nil(t, v, "flakyRemote has no per-key values")
+
+	failing.Store(true)
+	mgr.Invalidate()
+	_, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.ErrorIs(t, mgr.Health().LastError, ErrServerError, "a failed reload counts as a failed fetch")
+}
+
+func TestConfigManager_LazyRemoteKeysInterpolation(t *testing.T) {
+	server, requested := keyedRemote(t, map[string]any{"HOST": "db.internal", "DSN": "postgres://${HOST}/app"})
+	mgr := newStalenessManager(t, server.URL, WithLazyRemoteKeys(), WithCMInterpolation())
+
+	// DSN's placeholder sees HOST only once HOST has been read.
+	_, err := mgr.GetPublicConfig("HOST")
+	require.NoError(t, err)
+	v, err := mgr.GetPublicConfig("DSN")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://db.internal/app", v)
+	assert.Equal(t, []string{"values/HOST", "values/DSN", "values/HOST"}, requested())
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrManagerClosed is returned by ConfigManager methods that would start
+// background work after Close.
+var ErrManagerClosed = errors.New("config manager is closed")
+
+// component is one piece of background work a manager started: a file
+// watch or a live subscription (with its stream and API client). stop must
+// be idempotent and return once the component's goroutines have exited.
+type component struct {
+	id   int
+	name string
+	stop func()
+}
+
+// components tracks everything a ConfigManager runs in the background so
+// Close can stop it in order. Components stop in reverse start order, like
+// deferred calls; callbacks (change listeners, the restart handler) are
+// drained last, since a component still stopping may fire one. The zero
+// value is ready to use.
+type components struct {
+	mu        sync.Mutex
+	nextID    int
+	running   []component
+	closing   bool // no new components
+	drained   bool // no new callbacks
+	stopping  string
+	callbacks sync.WaitGroup
+	done      chan struct{}
+}
+
+// register adds a started component. The returned func removes it again
+// when the component ends on its own (a Subscription closed by its owner).
+// After Close has begun it returns ErrManagerClosed and the caller must stop
+// the component itself.
+func (cs *components) register(name string, stop func()) (func(), error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.closing {
+		return nil, ErrManagerClosed
+	}
+	cs.nextID++
+	id := cs.nextID
+	cs.running = append(cs.running, component{id: id, name: name, stop: stop})
+	return func() {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+		cs.running = slices.DeleteFunc(cs.running, func(c component) bool { return c.id == id })
+	}, nil
+}
+
+// goCallback runs fn on its own goroutine, tracked so Close can wait for
+// it. Once Close has drained callbacks, fn is dropped.
+func (cs *components) goCallback(fn func()) {
+	cs.mu.Lock()
+	if cs.drained {
+		cs.mu.Unlock()
+		return
+	}
+	cs.callbacks.Add(1)
+	cs.mu.Unlock()
+	go func() {
+		defer cs.callbacks.Done()
+		fn()
+	}()
+}
+
+// shutdown starts the ordered stop once and returns a channel closed when
+// it has finished.
+func (cs *components) shutdown() <-chan struct{} {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.done != nil {
+		return cs.done
+	}
+	cs.closing = true
+	cs.done = make(chan struct{})
+	running := slices.Clone(cs.running)
+	go func() {
+		defer close(cs.done)
+		for _, c := range slices.Backward(running) {
+			cs.setStopping(c.name)
+			c.stop()
+		}
+		cs.setStopping("callbacks")
+		cs.mu.Lock()
+		cs.drained = true
+		cs.mu.Unlock()
+		cs.callbacks.Wait()
+		cs.setStopping("")
+	}()
+	return cs.done
+}
+
+func (cs *components) setStopping(name string) {
+	cs.mu.Lock()
+	cs.stopping = name
+	cs.mu.Unlock()
+}
+
+// Close stops the manager's background work in order — the file watch and
+// live subscriptions (closing their streams and API clients) in reverse
+// start order, then in-flight change listener and restart handler calls —
+// and waits for it to finish. If ctx ends first, Close returns an error
+// naming what was still stopping; the shutdown carries on in the
+// background, and a later Close waits for the same one.
+//
+// Reads keep working after Close from the config already loaded, but no
+// further changes are applied or delivered, and Subscribe returns
+// ErrManagerClosed. Don't call Close from a change listener or restart
+// handler: it waits for that call to return.
+//
+// Close first scrubs the secret cache (see WithSecretMemoryProtection) and
+// stops SetOverride ttl timers; active overrides stay in place.
+func (m *ConfigManager) Close(ctx context.Context) error {
+	m.scrubSecrets()
+	m.stopOverrideTimers()
+	done := m.components.shutdown()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+		m.components.mu.Lock()
+		stopping := m.components.stopping
+		m.components.mu.Unlock()
+		return fmt.Errorf("[Smooai Config] Close: still stopping %s: %w", stopping, ctx.Err())
+	}
+}
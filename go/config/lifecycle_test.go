@@ -0,0 +1,105 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLifecycleManager(t *testing.T, opts ...ConfigManagerOption) (*ConfigManager, chan string) {
+	t.Helper()
+	server, frames := sseServer(t, map[string]any{"A": "remote-a"}, nil)
+	configDir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"C": "file-c"}})
+	mgr := NewConfigManager(append([]ConfigManagerOption{
+		WithAPIKey("secret"),
+		WithBaseURL(server.URL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_AUTH_URL": server.URL,
+			"SMOOAI_ENV_CONFIG_DIR":  configDir,
+		}),
+	}, opts...)...)
+	return mgr, frames
+}
+
+func TestConfigManager_CloseStopsBackgroundWork(t *testing.T) {
+	mgr, frames := newLifecycleManager(t, WithCMFileWatch())
+	require.NotNil(t, mgr.watcher)
+
+	sub, err := mgr.Subscribe(context.Background())
+	require.NoError(t, err)
+	frames <- `{"keys":["A"],"values":{"A":"pushed-a"}}`
+	receiveEvent(t, sub)
+
+	require.NoError(t, mgr.Close(context.Background()))
+
+	select {
+	case _, ok := <-sub.Events():
+		assert.False(t, ok, "subscription events closed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription still open after Close")
+	}
+	select {
+	case <-mgr.watcher.done:
+	default:
+		t.Fatal("file watch still running after Close")
+	}
+
+	_, err = mgr.Subscribe(context.Background())
+	assert.ErrorIs(t, err, ErrManagerClosed)
+	v, err := mgr.GetPublicConfig("A")
+	require.NoError(t, err)
+	assert.Equal(t, "pushed-a", v, "reads still served after Close")
+	assert.NoError(t, mgr.Close(context.Background()), "Close is idempotent")
+}
+
+func TestConfigManager_CloseDrainsCallbacks(t *testing.T) {
+	mgr, _ := newLifecycleManager(t)
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	mgr.addChangeListener(func(ConfigChangeEvent) {
+		started <- struct{}{}
+		<-release
+	})
+	_, err := mgr.GetPublicConfig("A")
+	require.NoError(t, err)
+	mgr.SetOverride("A", "override", 0)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = mgr.Close(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), "callbacks")
+
+	close(release)
+	require.NoError(t, mgr.Close(context.Background()))
+
+	mgr.ClearOverride("A")
+	select {
+	case <-started:
+		t.Fatal("listener called after Close")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestConfigManager_ClosedSubscriptionUnregisters(t *testing.T) {
+	mgr, _ := newLifecycleManager(t)
+	sub, err := mgr.Subscribe(context.Background())
+	require.NoError(t, err)
+	mgr.components.mu.Lock()
+	assert.Len(t, mgr.components.running, 1)
+	mgr.components.mu.Unlock()
+
+	sub.Close()
+	mgr.components.mu.Lock()
+	assert.Empty(t, mgr.components.running)
+	mgr.components.mu.Unlock()
+	require.NoError(t, mgr.Close(context.Background()))
+}
@@ -38,7 +38,7 @@ func TestEvaluateLimit_PostsExpectedBodyAndReturnsNumericValue(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org-abc", "secret-key")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "secret-key")
 	defer client.Close()
 
 	resp, err := client.EvaluateLimit(context.Background(), "agentMaxIterations", map[string]any{
@@ -49,7 +49,7 @@ func TestEvaluateLimit_PostsExpectedBodyAndReturnsNumericValue(t *testing.T) {
 	require.NotNil(t, resp)
 
 	assert.Equal(t, http.MethodPost, gotMethod)
-	assert.Equal(t, "/organizations/org-abc/config/limits/agentMaxIterations/evaluate", gotPath)
+	assert.Equal(t, "/organizations/"+testOrgID+"/config/limits/agentMaxIterations/evaluate", gotPath)
 	assert.Equal(t, "production", gotBody["environment"])
 	assert.Equal(t, float64(20), resp.Value)
 	assert.Equal(t, "rule", resp.Source)
@@ -69,7 +69,7 @@ func TestEvaluateLimit_NilContextSerializesAsEmptyObject(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	_, err := client.EvaluateLimit(context.Background(), "agentMaxIterations", nil, "production")
@@ -87,7 +87,7 @@ func TestEvaluateLimit_Maps404ToNotFound(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newFeatureFlagTestClient(t, server.URL, "org", "")
+	client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 	defer client.Close()
 
 	_, err := client.EvaluateLimit(context.Background(), "unknown", nil, "production")
@@ -111,7 +111,7 @@ func TestEvaluateLimit_Maps400And5xx(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(tc.status)
 		}))
-		client := newFeatureFlagTestClient(t, server.URL, "org", "")
+		client := newFeatureFlagTestClient(t, server.URL, testOrgID, "")
 
 		_, err := client.EvaluateLimit(context.Background(), "agentMaxIterations", nil, "production")
 		require.Error(t, err)
@@ -0,0 +1,102 @@
+package config
+
+// Key listing — names and metadata without values, so operators can audit
+// what exists without pulling secrets into memory.
+//
+// Server contract:
+//
+//	GET {BaseURL}/organizations/{orgId}/config/keys?environment=...[&pageToken=...][&limit=N]
+//	{"keys": [{"key": "...", "tier": "secret", "type": "string",
+//	           "updatedAt": "RFC3339", "updatedBy": "..."}],
+//	 "nextPageToken": "..."}
+//
+// An empty or missing nextPageToken marks the last page.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxListKeysPages bounds ListKeys so a server that keeps returning the same
+// page token cannot loop forever.
+const maxListKeysPages = 1000
+
+// KeyMetadata describes a config key without its value.
+type KeyMetadata struct {
+	Key       string     `json:"key"`
+	Tier      ConfigTier `json:"tier"`
+	Type      string     `json:"type,omitempty"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	UpdatedBy string     `json:"updatedBy,omitempty"`
+}
+
+// KeyPage is one page of ListKeysPage results.
+type KeyPage struct {
+	Keys []KeyMetadata `json:"keys"`
+	// NextPageToken fetches the following page; "" on the last page.
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// ListKeys returns metadata for every key in environment, following
+// pagination to the end. Values are never fetched. Pass an empty environment
+// to use the client's default.
+func (c *ConfigClient) ListKeys(environment string) ([]KeyMetadata, error) {
+	var keys []KeyMetadata
+	token := ""
+	for i := 0; i < maxListKeysPages; i++ {
+		page, err := c.ListKeysPage(environment, token, 0)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, page.Keys...)
+		if page.NextPageToken == "" {
+			return keys, nil
+		}
+		token = page.NextPageToken
+	}
+	return nil, fmt.Errorf("config list keys: more than %d pages", maxListKeysPages)
+}
+
+// ListKeysPage fetches a single page of key metadata. pageToken is "" for the
+// first page; limit <= 0 uses the server default.
+func (c *ConfigClient) ListKeysPage(environment, pageToken string, limit int) (*KeyPage, error) {
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{"environment": {env}}
+	if pageToken != "" {
+		q.Set("pageToken", pageToken)
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	u := fmt.Sprintf("%s/config/keys?%s", c.orgURL(), q.Encode())
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("config list keys: %w", err)
+	}
+
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("config list keys: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError("list keys", resp)
+	}
+
+	var page KeyPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("config list keys decode: %w", err)
+	}
+	return &page, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListKeys_FollowsPagination(t *testing.T) {
+	var tokens []string
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/organizations/"+testOrgID+"/config/keys", r.URL.Path)
+		assert.Equal(t, "production", r.URL.Query().Get("environment"))
+		token := r.URL.Query().Get("pageToken")
+		tokens = append(tokens, token)
+		switch token {
+		case "":
+			json.NewEncoder(w).Encode(map[string]any{
+				"keys": []map[string]any{
+					{"key": "API_URL", "tier": "public", "type": "string", "updatedAt": "2026-03-01T10:00:00Z", "updatedBy": "alice"},
+				},
+				"nextPageToken": "p2",
+			})
+		case "p2":
+			json.NewEncoder(w).Encode(map[string]any{
+				"keys": []map[string]any{{"key": "DB_PASSWORD", "tier": "secret", "type": "string"}},
+			})
+		}
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	keys, err := client.ListKeys("production")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "p2"}, tokens)
+	require.Len(t, keys, 2)
+	assert.Equal(t, KeyMetadata{
+		Key: "API_URL", Tier: TierPublic, Type: "string",
+		UpdatedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), UpdatedBy: "alice",
+	}, keys[0])
+	assert.Equal(t, TierSecret, keys[1].Tier)
+}
+
+func TestListKeysPage_SendsLimitAndToken(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "50", r.URL.Query().Get("limit"))
+		assert.Equal(t, "abc", r.URL.Query().Get("pageToken"))
+		json.NewEncoder(w).Encode(map[string]any{"keys": []any{}, "nextPageToken": "def"})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	page, err := client.ListKeysPage("production", "abc", 50)
+	require.NoError(t, err)
+	assert.Empty(t, page.Keys)
+	assert.Equal(t, "def", page.NextPageToken)
+}
+
+func TestListKeys_HTTPError(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.ListKeys("production")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -2,6 +2,10 @@ package config
 
 import (
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
 	"sync"
 	"time"
 )
@@ -10,7 +14,7 @@ const defaultLocalCacheTTL = 24 * time.Hour
 
 type localCacheEntry struct {
 	value     any
-	expiresAt time.Time
+	expiresAt deadline
 }
 
 // LocalConfigManager provides lazy-initialized, cached config access.
@@ -27,10 +31,21 @@ type LocalConfigManager struct {
 	secretCache map[string]localCacheEntry
 	ffCache     map[string]localCacheEntry
 	schemaKeys  map[string]bool
-	envPrefix   string
+	envNames    envNames
 	schemaTypes map[string]string
 	cacheTTL    time.Duration
 	envOverride map[string]string
+	envFunc     EnvFunc
+	dotEnvFiles []string
+	dotEnv      map[string]string
+	definition  *ConfigDefinition
+	builtins    BuiltinPolicy
+	configFiles configSource
+	fileWatch   bool
+	watcher     *fileWatcher
+	interpolate bool
+	logger      *slog.Logger
+	watchers    []*watcher
 }
 
 // LocalConfigOption is a functional option for LocalConfigManager.
@@ -47,6 +62,15 @@ func NewLocalConfigManager(opts ...LocalConfigOption) *LocalConfigManager {
 	for _, opt := range opts {
 		opt(m)
 	}
+	if m.definition != nil {
+		m.schemaKeys, m.schemaTypes = mergeEnvSchema(m.definition, m.schemaKeys, m.schemaTypes)
+	}
+	if len(m.dotEnvFiles) > 0 {
+		m.dotEnv = loadDotEnvFiles(m.dotEnvFiles, m.log())
+	}
+	if m.fileWatch {
+		m.watcher = watchConfigDir(m.configFiles, m.getEnv(), m.log(), m.reloadConfigFiles)
+	}
 	return m
 }
 
@@ -55,9 +79,9 @@ func WithSchemaKeys(keys map[string]bool) LocalConfigOption {
 	return func(m *LocalConfigManager) { m.schemaKeys = keys }
 }
 
-// WithEnvPrefix sets env var prefix for stripping.
-func WithEnvPrefix(prefix string) LocalConfigOption {
-	return func(m *LocalConfigManager) { m.envPrefix = prefix }
+// WithEnvPrefix sets the env var prefixes to strip. See WithCMEnvPrefix.
+func WithEnvPrefix(prefixes ...string) LocalConfigOption {
+	return func(m *LocalConfigManager) { m.envNames.prefixes = newEnvNames(prefixes...).prefixes }
 }
 
 // WithSchemaTypes sets schema type hints for coercion.
@@ -65,21 +89,55 @@ func WithSchemaTypes(types map[string]string) LocalConfigOption {
 	return func(m *LocalConfigManager) { m.schemaTypes = types }
 }
 
+// WithDefinition derives the schema keys and type hints from def, as
+// WithCMDefinition does for a ConfigManager.
+func WithDefinition(def *ConfigDefinition) LocalConfigOption {
+	return func(m *LocalConfigManager) { m.definition = def }
+}
+
 // WithLocalCacheTTL sets the cache TTL.
 func WithLocalCacheTTL(ttl time.Duration) LocalConfigOption {
 	return func(m *LocalConfigManager) { m.cacheTTL = ttl }
 }
 
-// WithEnvOverride overrides environment variables (for testing).
+// WithEnvOverride overrides environment variables (for testing). The map is
+// copied, so changing it afterwards has no effect on the manager.
 func WithEnvOverride(env map[string]string) LocalConfigOption {
-	return func(m *LocalConfigManager) { m.envOverride = env }
+	return func(m *LocalConfigManager) { m.envOverride = cloneEnv(env) }
+}
+
+// WithEnvFunc sources environment variables from fn instead of the process
+// environment, taking precedence over WithEnvOverride. See WithCMEnvFunc.
+func WithEnvFunc(fn EnvFunc) LocalConfigOption {
+	return func(m *LocalConfigManager) { m.envFunc = fn }
+}
+
+// WithDotEnv loads .env files below real env vars. See WithCMDotEnv.
+func WithDotEnv(paths ...string) LocalConfigOption {
+	return func(m *LocalConfigManager) { m.dotEnvFiles = append(m.dotEnvFiles, paths...) }
+}
+
+// WithBuiltins sets how the built-in keys (ENV, IS_LOCAL, REGION,
+// CLOUD_PROVIDER, RING, RUNTIME, AZ) interact with config-file and env-var
+// keys of the same name. By default they overwrite them; see BuiltinPolicy.
+func WithBuiltins(policy BuiltinPolicy) LocalConfigOption {
+	return func(m *LocalConfigManager) { m.builtins = policy }
+}
+
+// WithConfigFS reads the config files from the root directory of fsys,
+// such as an embed.FS. See WithCMConfigFS.
+func WithConfigFS(fsys fs.FS, root string) LocalConfigOption {
+	return func(m *LocalConfigManager) { m.configFiles = configSource{fsys: fsys, root: root} }
 }
 
 func (m *LocalConfigManager) getEnv() map[string]string {
+	if m.envFunc != nil {
+		return overlayDotEnv(m.dotEnv, envFromFunc(m.envFunc, m.schemaKeys, m.envNames))
+	}
 	if m.envOverride != nil {
-		return m.envOverride
+		return overlayDotEnv(m.dotEnv, m.envOverride)
 	}
-	return osEnvMap()
+	return overlayDotEnv(m.dotEnv, osEnvMap())
 }
 
 func (m *LocalConfigManager) initialize() error {
@@ -89,7 +147,10 @@ func (m *LocalConfigManager) initialize() error {
 
 	env := m.getEnv()
 
-	fileConfig, err := findAndProcessFileConfigWithEnv(env)
+	// Files take precedence over env vars here, so fallback built-ins are
+	// applied below, once both layers are known.
+	layerPolicy := m.builtins.withoutFallback()
+	fileConfig, err := findAndProcessFileConfigWithPolicy(env, NullSet, layerPolicy, m.configFiles)
 	if err != nil {
 		return err
 	}
@@ -99,11 +160,52 @@ func (m *LocalConfigManager) initialize() error {
 	if schemaKeys == nil {
 		schemaKeys = make(map[string]bool)
 	}
-	m.envConfig = findAndProcessEnvConfigWithEnv(schemaKeys, m.envPrefix, m.schemaTypes, env)
+	m.envConfig = findAndProcessEnvConfigWithBuiltins(schemaKeys, m.envNames, m.schemaTypes, env, layerPolicy)
+	applyEnvAliases(m.envConfig, env, m.envNames, m.schemaTypes, []map[string]any{m.fileConfig})
+	for key, value := range builtinValuesFromEnv(env) {
+		if m.builtins.Mode(key) != BuiltinFallback {
+			continue
+		}
+		_, inFile := m.fileConfig[key]
+		if _, inEnv := m.envConfig[key]; !inFile && !inEnv {
+			m.envConfig[key] = value
+		}
+	}
+	if m.interpolate {
+		// Placeholders resolve against the effective config (file over
+		// env), so flatten the two layers into fileConfig first.
+		effective := make(map[string]any, len(m.fileConfig)+len(m.envConfig))
+		maps.Copy(effective, m.envConfig)
+		maps.Copy(effective, m.fileConfig)
+		if err := Interpolate(effective, m.lookupEnvVal); err != nil {
+			return err
+		}
+		m.fileConfig, m.envConfig = effective, map[string]any{}
+	}
 	m.initialized = true
 	return nil
 }
 
+// lookupEnvVal looks up one env var where getEnv would find it.
+func (m *LocalConfigManager) lookupEnvVal(key string) (string, bool) {
+	var (
+		v  string
+		ok bool
+	)
+	switch {
+	case m.envFunc != nil:
+		v, ok = m.envFunc(key)
+	case m.envOverride != nil:
+		v, ok = m.envOverride[key]
+	default:
+		v, ok = os.LookupEnv(key)
+	}
+	if !ok {
+		v, ok = m.dotEnv[key]
+	}
+	return v, ok
+}
+
 func (m *LocalConfigManager) getValue(key string, cache map[string]localCacheEntry) (any, error) {
 	// SMOODEV-847 — guard against empty keys (matches ConfigManager.getFromTier).
 	if key == "" {
@@ -116,7 +218,7 @@ func (m *LocalConfigManager) getValue(key string, cache map[string]localCacheEnt
 
 	// Check cache
 	if entry, ok := cache[key]; ok {
-		if time.Now().Before(entry.expiresAt) {
+		if !entry.expiresAt.passed() {
 			return entry.value, nil
 		}
 		delete(cache, key)
@@ -130,7 +232,7 @@ func (m *LocalConfigManager) getValue(key string, cache map[string]localCacheEnt
 	// File config takes precedence
 	if m.fileConfig != nil {
 		if v, ok := m.fileConfig[key]; ok {
-			cache[key] = localCacheEntry{value: v, expiresAt: time.Now().Add(m.cacheTTL)}
+			cache[key] = localCacheEntry{value: v, expiresAt: expireAfter(m.cacheTTL)}
 			return v, nil
 		}
 	}
@@ -138,7 +240,7 @@ func (m *LocalConfigManager) getValue(key string, cache map[string]localCacheEnt
 	// Env config fallback
 	if m.envConfig != nil {
 		if v, ok := m.envConfig[key]; ok {
-			cache[key] = localCacheEntry{value: v, expiresAt: time.Now().Add(m.cacheTTL)}
+			cache[key] = localCacheEntry{value: v, expiresAt: expireAfter(m.cacheTTL)}
 			return v, nil
 		}
 	}
@@ -165,10 +267,17 @@ func (m *LocalConfigManager) GetFeatureFlag(key string) (any, error) {
 func (m *LocalConfigManager) Invalidate() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.invalidateLocked()
+}
+
+// invalidateLocked drops all loaded state and caches. Must be called under
+// m.mu.
+func (m *LocalConfigManager) invalidateLocked() {
 	m.initialized = false
 	m.fileConfig = nil
 	m.envConfig = nil
-	m.publicCache = make(map[string]localCacheEntry)
-	m.secretCache = make(map[string]localCacheEntry)
-	m.ffCache = make(map[string]localCacheEntry)
+	// Cleared in place: the getters pick their cache map before locking.
+	clear(m.publicCache)
+	clear(m.secretCache)
+	clear(m.ffCache)
 }
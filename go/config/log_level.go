@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// BindLogLevel sets level from the public config key (e.g. LOG_LEVEL) and
+// keeps it in step as the value changes through a reload, pushed change or
+// override, so a fleet's log level can be changed live from the platform.
+//
+// Values are slog level names, case-insensitive and optionally offset
+// ("debug", "INFO", "warn", "warning", "error", "ERROR+2"), or a number. An
+// unset key leaves level as it is. A value that doesn't parse is an error
+// here and is logged and ignored when it arrives as a change.
+func BindLogLevel(mgr *ConfigManager, key string, level *slog.LevelVar) error {
+	value, err := mgr.GetPublicConfig(key)
+	if err != nil {
+		return err
+	}
+	if value != nil {
+		parsed, err := parseLogLevel(key, value)
+		if err != nil {
+			return err
+		}
+		level.Set(parsed)
+	}
+	// Listeners run on their own goroutines, so two quick changes can be
+	// delivered out of order. Re-reading the key under mu instead of using
+	// the event's value means the last listener to run applies the latest.
+	var mu sync.Mutex
+	mgr.addChangeListener(func(evt ConfigChangeEvent) {
+		if !slices.Contains(evt.Keys, key) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		value, err := mgr.GetPublicConfig(key)
+		if err == nil && value == nil {
+			return
+		}
+		var parsed slog.Level
+		if err == nil {
+			parsed, err = parseLogLevel(key, value)
+		}
+		if err != nil {
+			mgr.log().Warn("ignoring log level", "error", err)
+			return
+		}
+		level.Set(parsed)
+	})
+	return nil
+}
+
+// parseLogLevel reads a slog level from a config value.
+func parseLogLevel(key string, value any) (slog.Level, error) {
+	if n, ok := jsonNumber(value); ok && n == float64(int(n)) {
+		return slog.Level(int(n)), nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return 0, NewConfigError(fmt.Sprintf("config key %q is not a log level: unsupported type %T", key, value))
+	}
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "warning") {
+		s = "warn"
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, NewConfigError(fmt.Sprintf("config key %q is not a log level: %q", key, s))
+	}
+	return level, nil
+}
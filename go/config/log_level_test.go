@@ -0,0 +1,63 @@
+package config
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	cases := map[any]slog.Level{
+		"debug":     slog.LevelDebug,
+		" INFO ":    slog.LevelInfo,
+		"warn":      slog.LevelWarn,
+		"Warning":   slog.LevelWarn,
+		"error":     slog.LevelError,
+		"ERROR+2":   slog.LevelError + 2,
+		float64(-4): slog.LevelDebug,
+		8:           slog.LevelError,
+	}
+	for in, want := range cases {
+		got, err := parseLogLevel("LOG_LEVEL", in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, bad := range []any{"verbose", true, 1.5} {
+		_, err := parseLogLevel("LOG_LEVEL", bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestBindLogLevel(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"LOG_LEVEL": "warn"}}),
+	}))
+
+	var level slog.LevelVar
+	require.NoError(t, BindLogLevel(mgr, "LOG_LEVEL", &level))
+	assert.Equal(t, slog.LevelWarn, level.Level())
+
+	mgr.SetOverride("LOG_LEVEL", "debug", 0)
+	assert.Eventually(t, func() bool { return level.Level() == slog.LevelDebug }, 2*time.Second, 5*time.Millisecond)
+
+	// An unparseable change is ignored.
+	mgr.SetOverride("LOG_LEVEL", "chatty", 0)
+	mgr.SetOverride("LOG_LEVEL", "error", 0)
+	assert.Eventually(t, func() bool { return level.Level() == slog.LevelError }, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestBindLogLevel_UnsetAndInvalid(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"BAD_LEVEL": "loud"}}),
+	}))
+
+	var level slog.LevelVar
+	level.Set(slog.LevelWarn)
+	require.NoError(t, BindLogLevel(mgr, "LOG_LEVEL", &level))
+	assert.Equal(t, slog.LevelWarn, level.Level(), "unset key leaves the level alone")
+
+	assert.ErrorContains(t, BindLogLevel(mgr, "BAD_LEVEL", &level), "not a log level")
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+)
+
+// defaultLogger is where warnings go without WithCMLogger, WithLocalLogger
+// or WithLogger: slog.Default at the time of logging, so an application's
+// slog.SetDefault applies even to managers created before it.
+func defaultLogger() *slog.Logger {
+	return slog.Default().With(slog.String("component", "smooai-config"))
+}
+
+// WithCMLogger sends the manager's warnings (failed fetches, ignored
+// changes, unreadable files) to logger, and at debug level which layer
+// each key's value came from. Values are only logged for keys known to be
+// public or feature flags; others are "[redacted]". The remote client the
+// manager creates logs to logger too. Defaults to slog.Default.
+func WithCMLogger(logger *slog.Logger) ConfigManagerOption {
+	return func(m *ConfigManager) { m.logger = logger }
+}
+
+// WithLocalLogger sends the manager's warnings to logger. Defaults to
+// slog.Default.
+func WithLocalLogger(logger *slog.Logger) LocalConfigOption {
+	return func(m *LocalConfigManager) { m.logger = logger }
+}
+
+// WithLogger logs the client's requests at debug level to logger, and its
+// retries at info. Values are never logged. Defaults to slog.Default.
+func WithLogger(logger *slog.Logger) ConfigClientOption {
+	return func(c *ConfigClient) { c.logger = logger }
+}
+
+func (m *ConfigManager) log() *slog.Logger {
+	if m.logger != nil {
+		return m.logger
+	}
+	return defaultLogger()
+}
+
+func (m *LocalConfigManager) log() *slog.Logger {
+	if m.logger != nil {
+		return m.logger
+	}
+	return defaultLogger()
+}
+
+func (c *ConfigClient) log() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return defaultLogger()
+}
+
+// logMergeLocked logs at debug level which layer set each key of merged.
+// layers are the merged layers, lowest first. Must be called under m.mu.
+func (m *ConfigManager) logMergeLocked(merged map[string]any, layers []configLayer) {
+	log := m.log()
+	if !log.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	for key, value := range merged {
+		from := "computed"
+		if _, ok := m.overrides[key]; ok {
+			from = "override"
+		} else {
+			for i := len(layers) - 1; i >= 0; i-- {
+				if _, ok := layers[i].values[key]; ok {
+					from = layers[i].name
+					break
+				}
+			}
+		}
+		if !m.revealableLocked(key) {
+			value = redactedValue
+		}
+		log.Debug("merged config key", "key", key, "layer", from, "value", value)
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCMLogger_Warnings(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	src := newMemSource(map[string]any{})
+	src.err = errors.New("database down")
+	mgr := NewConfigManager(WithSource(PriorityRemote, src), WithCMLogger(logger), WithCMEnvOverride(map[string]string{}))
+	defer mgr.Close(context.Background())
+
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `level=WARN msg="failed to load config source"`)
+	assert.Contains(t, buf.String(), `error="database down"`)
+}
+
+func TestWithCMLogger_DebugMergeRedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+		"API_URL":     "https://file",
+		"DB_PASSWORD": "hunter2",
+	}})
+	mgr := NewConfigManager(
+		WithCMLogger(logger),
+		WithCMKeyTiers(map[string]ConfigTier{"API_URL": TierPublic, "DB_PASSWORD": TierSecret}),
+		WithCMSchemaKeys(map[string]bool{"API_URL": true}),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir, "API_URL": "https://env"}),
+	)
+	defer mgr.Close(context.Background())
+
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	out := buf.String()
+	assert.Contains(t, out, `msg="merged config key" key=API_URL layer=env value=https://env`)
+	assert.Contains(t, out, `msg="merged config key" key=DB_PASSWORD layer=file value=[redacted]`)
+	assert.NotContains(t, out, "hunter2")
+}
+
+func TestWithLogger_Client(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"value":"secret-value"}`))
+	}))
+	defer srv.Close()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := newUnitClient(t, srv.URL, WithLogger(logger))
+
+	v, err := client.GetValue("DB_PASSWORD", "production")
+	require.NoError(t, err)
+	assert.Equal(t, "secret-value", v)
+	assert.Contains(t, buf.String(), `msg="config API request" method=GET`)
+	assert.Contains(t, buf.String(), "status=200")
+	assert.NotContains(t, buf.String(), "secret-value")
+}
@@ -0,0 +1,129 @@
+package config
+
+// Long-poll change detection, the fallback for networks that strip or
+// buffer Server-Sent Events. Subscribe switches to it on its own when the
+// stream endpoint is unreachable or answers with something other than an
+// event stream, or always with WithWatchTransport(WatchLongPoll); events are
+// delivered exactly as over SSE.
+//
+// Server contract:
+//
+//	GET {BaseURL}/organizations/{orgId}/config/changes?environment=...[&ring=...][&since=cursor]
+//	{"cursor": "43", "changes": [{"id": "43", "keys": ["API_URL"], "values": {"API_URL": "https://..."}}]}
+//
+// Without since the server answers at once with the current cursor and no
+// changes. With since it holds the request until there are changes after
+// the cursor, or answers with an empty list after its wait (about 30s).
+// Cursors are SSE event ids, so either transport can resume from the other.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// errStreamUnavailable marks an SSE attempt that a long poll may succeed
+// where the stream didn't.
+var errStreamUnavailable = errors.New("event stream unavailable")
+
+// longPollTimeout bounds one poll, well past the server's wait, so a
+// connection silently dropped by a proxy is noticed. An empty poll answered
+// sooner than longPollMinInterval (a proxy cutting requests short) waits out
+// the rest before polling again.
+var (
+	longPollTimeout     = 2 * time.Minute
+	longPollMinInterval = time.Second
+)
+
+// changeEvent carries the id field that ConfigChangeEvent takes from SSE
+// framing rather than JSON.
+type changeEvent struct {
+	ConfigChangeEvent
+	ID string `json:"id"`
+}
+
+// pollChanges makes one long-poll request for changes after since and
+// returns them with the cursor to poll from next.
+func (c *ConfigClient) pollChanges(ctx context.Context, env, since string) (string, []ConfigChangeEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, longPollTimeout)
+	defer cancel()
+
+	u := fmt.Sprintf("%s/config/changes?%s", c.orgURL(), c.valuesQuery(env))
+	if since != "" {
+		u += "&since=" + url.QueryEscape(since)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("config poll changes: %w", err)
+	}
+
+	resp, err := c.doRequestWithRetryUsing(c.streamClient, req)
+	if err != nil {
+		return "", nil, fmt.Errorf("config poll changes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, newHTTPError("poll changes", resp)
+	}
+	var raw struct {
+		Cursor  string        `json:"cursor"`
+		Changes []changeEvent `json:"changes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return "", nil, fmt.Errorf("config poll changes decode: %w", err)
+	}
+	changes := make([]ConfigChangeEvent, len(raw.Changes))
+	for i, change := range raw.Changes {
+		changes[i] = change.ConfigChangeEvent
+		changes[i].ID = change.ID
+	}
+	return raw.Cursor, changes, nil
+}
+
+// runLongPoll polls for changes after cursor until ctx is done, applying
+// and delivering each one the way runSubscription does. Failed polls are
+// recorded on sub and retried with backoff.
+func (c *ConfigClient) runLongPoll(ctx context.Context, env string, sub *Subscription, cursor string) {
+	backoff := subscribeBackoff.initial
+	for {
+		started := time.Now()
+		next, changes, err := c.pollChanges(ctx, env, cursor)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			sub.setErr(err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, subscribeBackoff.max)
+			continue
+		}
+		backoff = subscribeBackoff.initial
+		for _, evt := range changes {
+			evt.Environment = env
+			if evt.ID != "" {
+				cursor = evt.ID
+			}
+			c.applyChangeEvent(evt)
+			sub.deliver(evt)
+		}
+		if next != "" {
+			cursor = next
+		}
+		if wait := longPollMinInterval - time.Since(started); len(changes) == 0 && wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+	}
+}
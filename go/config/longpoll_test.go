@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// longPollServer serves /token, a stream endpoint answering with
+// streamStatus and streamType, and a changes endpoint fed from the returned
+// channel. Each batch sent is one poll response; the cursor counts batches.
+func longPollServer(t *testing.T, streamStatus int, streamType string) (*httptest.Server, chan []map[string]any, chan string) {
+	t.Helper()
+	batches := make(chan []map[string]any, 4)
+	sinces := make(chan string, 16)
+	cursor := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "jwt", "expires_in": 3600})
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", streamType)
+		w.WriteHeader(streamStatus)
+		w.Write([]byte("<html>blocked</html>"))
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/changes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "production", r.URL.Query().Get("environment"))
+		since := r.URL.Query().Get("since")
+		sinces <- since
+		var changes []map[string]any
+		if since != "" {
+			select {
+			case changes = <-batches:
+				cursor++
+			case <-r.Context().Done():
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"cursor": string(rune('0' + cursor)), "changes": changes})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, batches, sinces
+}
+
+func TestConfigClient_SubscribeFallsBackToLongPoll(t *testing.T) {
+	for name, stream := range map[string]struct {
+		status      int
+		contentType string
+	}{
+		"stream not found":    {http.StatusNotFound, "text/plain"},
+		"stream stripped":     {http.StatusOK, "text/html; charset=utf-8"},
+		"stream not accepted": {http.StatusNotAcceptable, "text/plain"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server, batches, sinces := longPollServer(t, stream.status, stream.contentType)
+			client := NewConfigClient(server.URL, "cid", "secret", testOrgID, WithAuthURL(server.URL), WithRing(""))
+			defer client.Close()
+
+			sub, err := client.Subscribe(context.Background(), "production")
+			require.NoError(t, err)
+			defer sub.Close()
+			assert.Equal(t, "", <-sinces, "the first poll fetches the current cursor")
+			assert.Equal(t, "0", <-sinces)
+
+			batches <- []map[string]any{{"id": "1", "keys": []string{"A"}, "values": map[string]any{"A": "new"}}}
+			evt := receiveEvent(t, sub)
+			assert.Equal(t, "1", evt.ID)
+			assert.Equal(t, "production", evt.Environment)
+			assert.Equal(t, []string{"A"}, evt.Keys)
+			v, ok := client.GetCachedValue("A", "production")
+			assert.True(t, ok)
+			assert.Equal(t, "new", v)
+			assert.Equal(t, "1", <-sinces, "polling resumes from the new cursor")
+		})
+	}
+}
+
+func TestConfigClient_SubscribeLongPollUnavailable(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.Subscribe(context.Background(), "production")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "poll changes")
+}
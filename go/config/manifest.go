@@ -0,0 +1,158 @@
+package config
+
+// Build manifests record which schema, bundled defaults and SDK version a
+// binary was built with, for provenance audits. Generate one at build time
+// with GenerateBuildManifest and embed it either through the linker:
+//
+//	go build -ldflags "$(go run ./tools/manifest)" ./cmd/service
+//
+// where the tool prints manifest.LDFlag(), or as a file:
+//
+//	//go:embed smooai-config-manifest.json
+//	var manifestJSON []byte
+//	func init() { config.RegisterBuildManifest(manifestJSON) }
+//
+// At runtime, BuildManifest returns it.
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// embeddedManifest is base64-encoded manifest JSON, set with
+// -ldflags "-X github.com/SmooAI/config/go/config.embeddedManifest=...".
+var embeddedManifest string
+
+var (
+	registeredManifestMu sync.RWMutex
+	registeredManifest   *ConfigManifest
+)
+
+// ConfigManifest describes the config inputs a binary was built with.
+type ConfigManifest struct {
+	// SchemaVersion labels the schema, e.g. a release tag. Defaults to
+	// SchemaFingerprint.
+	SchemaVersion string `json:"schemaVersion"`
+	// SchemaFingerprint is the Fingerprint of the schema's JSON Schema;
+	// empty when no definition was given.
+	SchemaFingerprint string `json:"schemaFingerprint,omitempty"`
+	// DefaultsFingerprint is the Fingerprint of the bundled config files,
+	// keyed by file name.
+	DefaultsFingerprint string `json:"defaultsFingerprint"`
+	// DefaultsFiles lists the fingerprinted files, sorted.
+	DefaultsFiles []string `json:"defaultsFiles"`
+	// SDKVersion is the smooai-config Go package Version.
+	SDKVersion string `json:"sdkVersion"`
+	// GeneratedAt is when the manifest was generated.
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+// BuildManifestOptions configures GenerateBuildManifest.
+type BuildManifestOptions struct {
+	// Definition is the service's schema. Optional.
+	Definition *ConfigDefinition
+	// SchemaVersion overrides the derived schema version.
+	SchemaVersion string
+	// ConfigDir holds the bundled *.json and *.jsonc config files. Empty
+	// searches for .smooai-config the way the file loader does.
+	ConfigDir string
+}
+
+// GenerateBuildManifest fingerprints the schema and bundled config files.
+// Run it at build time, from the directory the service is built in.
+func GenerateBuildManifest(opts BuildManifestOptions) (*ConfigManifest, error) {
+	dir := opts.ConfigDir
+	if dir == "" {
+		found, err := findConfigDirectoryWithEnv(true, osEnvMap())
+		if err != nil {
+			return nil, err
+		}
+		dir = found
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, NewConfigError(fmt.Sprintf("error listing %s: %v", dir, err))
+	}
+	jsoncPaths, _ := filepath.Glob(filepath.Join(dir, "*.jsonc"))
+	paths = append(paths, jsoncPaths...)
+	files := make(map[string]any, len(paths))
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, NewConfigError(fmt.Sprintf("error reading %s: %v", path, err))
+		}
+		var content any
+		if err := unmarshalJSONC(data, &content); err != nil {
+			return nil, NewConfigError(fmt.Sprintf("error parsing %s: %v", path, err))
+		}
+		name := filepath.Base(path)
+		files[name] = content
+		names = append(names, name)
+	}
+
+	manifest := &ConfigManifest{
+		SchemaVersion:       opts.SchemaVersion,
+		DefaultsFingerprint: Fingerprint(files),
+		DefaultsFiles:       names,
+		SDKVersion:          Version,
+		GeneratedAt:         time.Now().UTC(),
+	}
+	if opts.Definition != nil {
+		manifest.SchemaFingerprint = Fingerprint(opts.Definition.JSONSchema)
+	}
+	if manifest.SchemaVersion == "" {
+		manifest.SchemaVersion = manifest.SchemaFingerprint
+	}
+	return manifest, nil
+}
+
+// LDFlag returns the -X linker flag that embeds m, for use in
+// go build -ldflags.
+func (m *ConfigManifest) LDFlag() (string, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return "-X github.com/SmooAI/config/go/config.embeddedManifest=" + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// RegisterBuildManifest installs a manifest embedded as a file (the JSON
+// GenerateBuildManifest produces). It takes precedence over a linker-embedded
+// one.
+func RegisterBuildManifest(data []byte) error {
+	var m ConfigManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return NewConfigError(fmt.Sprintf("invalid build manifest: %v", err))
+	}
+	registeredManifestMu.Lock()
+	registeredManifest = &m
+	registeredManifestMu.Unlock()
+	return nil
+}
+
+// BuildManifest returns the manifest embedded at build time. ok is false
+// when none was embedded (or the linker value is malformed); the returned
+// manifest then carries only SDKVersion.
+func BuildManifest() (manifest ConfigManifest, ok bool) {
+	registeredManifestMu.RLock()
+	registered := registeredManifest
+	registeredManifestMu.RUnlock()
+	if registered != nil {
+		return *registered, true
+	}
+	if embeddedManifest != "" {
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(embeddedManifest))
+		if err == nil && json.Unmarshal(data, &manifest) == nil {
+			return manifest, true
+		}
+	}
+	return ConfigManifest{SDKVersion: Version}, false
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetBuildManifest(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		registeredManifestMu.Lock()
+		registeredManifest = nil
+		registeredManifestMu.Unlock()
+		embeddedManifest = ""
+	})
+}
+
+func TestGenerateBuildManifest(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{
+		"default.json":    map[string]any{"API_URL": "https://a"},
+		"production.json": map[string]any{"API_URL": "https://b"},
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644))
+	def := DefineConfig(map[string]any{"type": "object", "properties": map[string]any{"API_URL": map[string]any{"type": "string"}}}, nil, nil)
+
+	m, err := GenerateBuildManifest(BuildManifestOptions{Definition: def, ConfigDir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default.json", "production.json"}, m.DefaultsFiles)
+	assert.True(t, strings.HasPrefix(m.DefaultsFingerprint, "sha256:"))
+	assert.Equal(t, Fingerprint(def.JSONSchema), m.SchemaFingerprint)
+	assert.Equal(t, m.SchemaFingerprint, m.SchemaVersion)
+	assert.Equal(t, Version, m.SDKVersion)
+
+	again, err := GenerateBuildManifest(BuildManifestOptions{ConfigDir: dir, SchemaVersion: "v42"})
+	require.NoError(t, err)
+	assert.Equal(t, m.DefaultsFingerprint, again.DefaultsFingerprint)
+	assert.Equal(t, "v42", again.SchemaVersion)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "production.json"), []byte(`{"API_URL":"https://c"}`), 0o644))
+	changed, err := GenerateBuildManifest(BuildManifestOptions{ConfigDir: dir})
+	require.NoError(t, err)
+	assert.NotEqual(t, m.DefaultsFingerprint, changed.DefaultsFingerprint)
+}
+
+func TestBuildManifest_NotEmbedded(t *testing.T) {
+	resetBuildManifest(t)
+	m, ok := BuildManifest()
+	assert.False(t, ok)
+	assert.Equal(t, ConfigManifest{SDKVersion: Version}, m)
+}
+
+func TestBuildManifest_FromLDFlag(t *testing.T) {
+	resetBuildManifest(t)
+	want := ConfigManifest{SchemaVersion: "v1", DefaultsFingerprint: "sha256:abc", SDKVersion: Version}
+	flag, err := want.LDFlag()
+	require.NoError(t, err)
+	name, value, found := strings.Cut(flag, "=")
+	require.True(t, found)
+	assert.Equal(t, "-X github.com/SmooAI/config/go/config.embeddedManifest", name)
+
+	embeddedManifest = value
+	got, ok := BuildManifest()
+	assert.True(t, ok)
+	assert.Equal(t, want.SchemaVersion, got.SchemaVersion)
+	assert.Equal(t, want.DefaultsFingerprint, got.DefaultsFingerprint)
+}
+
+func TestBuildManifest_RegisteredFileWins(t *testing.T) {
+	resetBuildManifest(t)
+	embeddedManifest = "bm90LWpzb24=" // "not-json": ignored
+	data, err := json.Marshal(ConfigManifest{SchemaVersion: "from-file"})
+	require.NoError(t, err)
+	require.NoError(t, RegisterBuildManifest(data))
+
+	got, ok := BuildManifest()
+	assert.True(t, ok)
+	assert.Equal(t, "from-file", got.SchemaVersion)
+	assert.Error(t, RegisterBuildManifest([]byte("{")))
+}
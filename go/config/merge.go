@@ -1,10 +1,42 @@
 package config
 
+// NullPolicy controls how an explicit JSON null in a higher-precedence layer
+// is merged over a lower one.
+type NullPolicy string
+
+const (
+	// NullSet (default) overwrites the lower value with nil, leaving the key
+	// present. Matches the TypeScript mergeReplaceArrays.
+	NullSet NullPolicy = "set"
+	// NullDelete removes the key from the merged object, so the lower
+	// layer's value disappears too.
+	NullDelete NullPolicy = "delete"
+	// NullKeepLower ignores the null and keeps the lower layer's value.
+	NullKeepLower NullPolicy = "keep"
+)
+
 // MergeReplaceArrays performs a deep merge where:
 //   - Slices (arrays) from source replace target entirely
 //   - Maps (objects) merge recursively
 //   - Other values (primitives) from source overwrite target
+//
+// Explicit nil values overwrite (NullSet); see MergeWithNullPolicy for the
+// alternatives.
 func MergeReplaceArrays(target, source any) any {
+	return MergeWithNullPolicy(target, source, NullSet)
+}
+
+// MergeWithNullPolicy is MergeReplaceArrays with a configurable treatment of
+// nil source values. The policy applies at every depth: under NullDelete a
+// nil map entry removes the key, under NullKeepLower it keeps the target's
+// entry. A nil top-level source has no key to delete, so NullDelete returns
+// nil there, while NullKeepLower returns target. An empty policy means
+// NullSet.
+func MergeWithNullPolicy(target, source any, policy NullPolicy) any {
+	if source == nil && policy == NullKeepLower {
+		return target
+	}
+
 	// If source is a slice, replace entirely
 	if sourceSlice, ok := source.([]any); ok {
 		result := make([]any, len(sourceSlice))
@@ -24,11 +56,17 @@ func MergeReplaceArrays(target, source any) any {
 			targetMap = make(map[string]any)
 		}
 		for key, value := range sourceMap {
-			if existing, exists := targetMap[key]; exists {
-				targetMap[key] = MergeReplaceArrays(existing, value)
-			} else {
-				targetMap[key] = value
+			if value == nil {
+				switch policy {
+				case NullDelete:
+					delete(targetMap, key)
+				case NullKeepLower:
+				default:
+					targetMap[key] = nil
+				}
+				continue
 			}
+			targetMap[key] = MergeWithNullPolicy(targetMap[key], value, policy)
 		}
 		return targetMap
 	}
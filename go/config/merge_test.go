@@ -144,3 +144,22 @@ func TestMerge_DoesNotMutateTarget(t *testing.T) {
 	// Original target's inner map should not be mutated
 	assert.Equal(t, map[string]any{"x": 1.0}, inner)
 }
+
+func TestMergeWithNullPolicy_Set(t *testing.T) {
+	got := MergeWithNullPolicy(map[string]any{"a": 1.0}, map[string]any{"a": nil}, NullSet)
+	assert.Equal(t, map[string]any{"a": nil}, got)
+	assert.Equal(t, MergeReplaceArrays(map[string]any{"a": 1.0}, map[string]any{"a": nil}), got)
+}
+
+func TestMergeWithNullPolicy_Delete(t *testing.T) {
+	target := map[string]any{"db": map[string]any{"host": "h", "password": "p"}, "keep": true}
+	got := MergeWithNullPolicy(target, map[string]any{"db": map[string]any{"password": nil}}, NullDelete)
+	assert.Equal(t, map[string]any{"db": map[string]any{"host": "h"}, "keep": true}, got)
+	assert.Equal(t, "p", target["db"].(map[string]any)["password"], "target must not be mutated")
+}
+
+func TestMergeWithNullPolicy_KeepLower(t *testing.T) {
+	got := MergeWithNullPolicy(map[string]any{"a": 1.0, "b": 2.0}, map[string]any{"a": nil, "b": 3.0, "c": nil}, NullKeepLower)
+	assert.Equal(t, map[string]any{"a": 1.0, "b": 3.0}, got)
+	assert.Equal(t, "lower", MergeWithNullPolicy("lower", nil, NullKeepLower))
+}
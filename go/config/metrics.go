@@ -0,0 +1,122 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metricName is the gauge exported by WriteMetrics.
+const metricName = "smooai_config_value"
+
+// WithCMMetricKeys opts keys in to WriteMetrics / MetricsHandler, which
+// export their current values as a Prometheus gauge so dashboards can line
+// behavior changes up with config changes. Only numeric and boolean values
+// are exported (true = 1); secret-tier keys never are.
+func WithCMMetricKeys(keys ...string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.metricKeys = append(m.metricKeys, keys...) }
+}
+
+// WriteMetrics writes the WithCMMetricKeys values in the Prometheus text
+// exposition format, one sample per key:
+//
+//	smooai_config_value{key="MAX_RETRIES"} 3
+//
+// Keys that are unset or hold a non-numeric value are omitted. It loads the
+// config on first use, like the Get* methods.
+func (m *ConfigManager) WriteMetrics(w io.Writer) error {
+	m.mu.Lock()
+	if err := m.initialize(); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	samples := make(map[string]float64, len(m.metricKeys))
+	for _, key := range m.metricKeys {
+		if m.keyTierLocked(key) == TierSecret {
+			continue
+		}
+		if v, ok := metricValue(m.config[key]); ok {
+			samples[key] = v
+		}
+	}
+	m.mu.Unlock()
+
+	keys := make([]string, 0, len(samples))
+	for key := range samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# HELP %s Current value of a numeric or boolean config key.\n", metricName)
+	fmt.Fprintf(&buf, "# TYPE %s gauge\n", metricName)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "%s{key=\"%s\"} %s\n", metricName, escapeLabelValue(key), formatMetricValue(samples[key]))
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// MetricsHandler serves WriteMetrics, followed by WriteAccessMetrics, for a
+// Prometheus scrape endpoint.
+func (m *ConfigManager) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := m.WriteMetrics(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = m.WriteAccessMetrics(&buf)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(buf.Bytes())
+	})
+}
+
+// metricValue converts a config value to a gauge sample.
+func metricValue(v any) (float64, bool) {
+	switch x := v.(type) {
+	case bool:
+		if x {
+			return 1, true
+		}
+		return 0, true
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func formatMetricValue(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigManager_WriteMetrics(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMMetricKeys("MAX_RETRIES", "ENABLE_CACHE", "API_URL", "MISSING", "DB_PASSWORD", "RATE"),
+		WithCMKeyTiers(map[string]ConfigTier{"DB_PASSWORD": TierSecret}),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{
+				"default.json": map[string]any{
+					"MAX_RETRIES":  3,
+					"ENABLE_CACHE": true,
+					"API_URL":      "https://api",
+					"DB_PASSWORD":  1234,
+					"RATE":         0.25,
+				},
+			}),
+		}),
+	)
+
+	var sb strings.Builder
+	require.NoError(t, mgr.WriteMetrics(&sb))
+	assert.Equal(t, `# HELP smooai_config_value Current value of a numeric or boolean config key.
+# TYPE smooai_config_value gauge
+smooai_config_value{key="ENABLE_CACHE"} 1
+smooai_config_value{key="MAX_RETRIES"} 3
+smooai_config_value{key="RATE"} 0.25
+`, sb.String())
+}
+
+func TestConfigManager_MetricsHandler(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMMetricKeys("MAX_RETRIES"),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"MAX_RETRIES": 5}}),
+		}),
+	)
+	mgr.SetOverride("MAX_RETRIES", 7, 0)
+
+	rec := httptest.NewRecorder()
+	mgr.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "version=0.0.4")
+	assert.Contains(t, rec.Body.String(), `smooai_config_value{key="MAX_RETRIES"} 7`)
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabelValue("a\"b\\c\nd"))
+}
@@ -0,0 +1,36 @@
+package config
+
+import "time"
+
+// monoEpoch anchors deadlines to the monotonic clock reading taken at
+// startup. time.Since(monoEpoch) only ever moves forward at a steady rate,
+// whatever happens to the wall clock.
+var monoEpoch = time.Now()
+
+// monoNow is the time elapsed since monoEpoch on the monotonic clock.
+// Tests replace it to move time without sleeping.
+var monoNow = func() time.Duration { return time.Since(monoEpoch) }
+
+// deadline is an expiry on the monotonic clock. Unlike a time.Time, it can't
+// lose its monotonic reading through Round, UTC or serialization, so a wall
+// clock step (an NTP correction, a VM resumed from suspend) can neither
+// expire every cache entry at once nor keep entries alive indefinitely.
+//
+// The zero deadline never passes.
+type deadline time.Duration
+
+// expireAfter returns the deadline d from now. A d of zero or less has
+// already passed.
+func expireAfter(d time.Duration) deadline {
+	at := monoNow() + d
+	if at <= 0 {
+		// Keep it distinct from the zero deadline, which never passes.
+		at = -1
+	}
+	return deadline(at)
+}
+
+// passed reports whether the deadline has been reached.
+func (dl deadline) passed() bool {
+	return dl != 0 && monoNow() >= time.Duration(dl)
+}
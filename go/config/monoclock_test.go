@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMonoClock replaces monoNow for the duration of the test and returns a
+// function that advances it.
+func fakeMonoClock(t *testing.T) func(time.Duration) {
+	t.Helper()
+	now := time.Hour
+	orig := monoNow
+	monoNow = func() time.Duration { return now }
+	t.Cleanup(func() { monoNow = orig })
+	return func(d time.Duration) { now += d }
+}
+
+func TestDeadline(t *testing.T) {
+	advance := fakeMonoClock(t)
+
+	var never deadline
+	assert.False(t, never.passed())
+	assert.True(t, expireAfter(0).passed())
+	assert.True(t, expireAfter(-time.Minute).passed())
+
+	dl := expireAfter(time.Minute)
+	advance(59 * time.Second)
+	assert.False(t, dl.passed())
+	advance(time.Second)
+	assert.True(t, dl.passed())
+
+	advance(24 * time.Hour)
+	assert.False(t, never.passed())
+}
+
+func TestClientCache_ExpiresOnMonotonicClock(t *testing.T) {
+	advance := fakeMonoClock(t)
+	client := NewConfigClient("https://api.example.com", "cid", "sec", testOrgID, WithCacheTTL(time.Minute))
+	defer client.Close()
+
+	k := CacheKey{Environment: "prod", Key: "API_URL"}
+	client.SeedCacheKey(k, "https://api")
+
+	// Only monotonic time counts; the wall clock is never consulted.
+	advance(30 * time.Second)
+	v, ok := client.CachedValue(k)
+	require.True(t, ok)
+	assert.Equal(t, "https://api", v)
+
+	advance(30 * time.Second)
+	_, ok = client.CachedValue(k)
+	assert.False(t, ok)
+}
+
+func TestDecodeOAuthToken_PrefersRelativeLifetime(t *testing.T) {
+	now := time.Now()
+
+	// expires_on says the token expired long ago (the host clock is ahead of
+	// the issuer's), but expires_in says it has an hour left.
+	past := now.Add(-24 * time.Hour).Unix()
+	body := []byte(fmt.Sprintf(`{"access_token":"T","expires_in":3600,"expires_on":%d}`, past))
+	tok, err := decodeOAuthToken(body, now, "test")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), tok.expires)
+
+	tok, err = decodeOAuthToken([]byte(`{"access_token":"T","expires_on":"4102444800"}`), now, "test")
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(4102444800, 0).Unix(), tok.expires.Unix())
+	// The converted expiry keeps now's monotonic reading.
+	assert.NotEqual(t, tok.expires.Round(0), tok.expires)
+}
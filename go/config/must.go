@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MustInit loads every config source and panics with a *ConfigError when
+// loading fails or any of required is unset. Meant for main(), where a
+// service should refuse to start without its config.
+func (m *ConfigManager) MustInit(required ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.initialize(); err != nil {
+		panic(NewConfigError(fmt.Sprintf("config failed to load: %v", err)))
+	}
+	var missing []string
+	for _, key := range required {
+		if m.config[key] == nil {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		panic(NewConfigError(fmt.Sprintf("required config keys are not set: %v", missing)))
+	}
+}
+
+// MustGetPublicConfig is GetPublicConfig for required keys: it panics with a
+// *ConfigError when the read fails, the key is unset, or the value does not
+// match the key's WithCMSchemaTypes type.
+func (m *ConfigManager) MustGetPublicConfig(key string) any {
+	return m.mustGet(key, m.GetPublicConfig)
+}
+
+// MustGetSecretConfig is GetSecretConfig for required keys; see
+// MustGetPublicConfig.
+func (m *ConfigManager) MustGetSecretConfig(key string) any {
+	return m.mustGet(key, m.GetSecretConfig)
+}
+
+// MustGetFeatureFlag is GetFeatureFlag for required keys; see
+// MustGetPublicConfig.
+func (m *ConfigManager) MustGetFeatureFlag(key string) any {
+	return m.mustGet(key, m.GetFeatureFlag)
+}
+
+func (m *ConfigManager) mustGet(key string, get func(string) (any, error)) any {
+	value, err := get(key)
+	if err != nil {
+		panic(NewConfigError(fmt.Sprintf("required config key %q: %v", key, err)))
+	}
+	if value == nil {
+		panic(NewConfigError(fmt.Sprintf("required config key %q is not set", key)))
+	}
+	if typ, ok := m.schemaTypes[key]; ok && !matchesSchemaType(value, typ) {
+		panic(NewConfigError(fmt.Sprintf("required config key %q is %T, want %s", key, value, typ)))
+	}
+	return value
+}
+
+// matchesSchemaType reports whether value fits a schema type hint as used by
+// WithCMSchemaTypes ("string", "number", "boolean", "json", "object", or a
+// list type such as "string[]"). Unknown hints match anything.
+func matchesSchemaType(value any, typ string) bool {
+	if isListType(typ) {
+		list, ok := value.([]any)
+		elemType := strings.TrimSuffix(typ, "[]")
+		for i := 0; ok && i < len(list); i++ {
+			ok = matchesSchemaType(list[i], elemType)
+		}
+		return ok
+	}
+	switch typ {
+	case "string":
+		switch value.(type) {
+		case string, *SecretString:
+			return true
+		}
+		return false
+	case "number":
+		switch value.(type) {
+		case float64, float32, int, int64, int32, json.Number:
+			return true
+		}
+		return false
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "json", "object":
+		switch value.(type) {
+		case map[string]any, []any:
+			return true
+		}
+		return false
+	}
+	return true
+}
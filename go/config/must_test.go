@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMustManager(t *testing.T, opts ...ConfigManagerOption) *ConfigManager {
+	t.Helper()
+	base := []ConfigManagerOption{
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{
+				"default.json": map[string]any{"API_URL": "https://api", "MAX_RETRIES": 3, "NEW_UI": true},
+			}),
+		}),
+	}
+	return NewConfigManager(append(base, opts...)...)
+}
+
+// panicMessage runs fn and returns the *ConfigError message it panicked with.
+func panicMessage(t *testing.T, fn func()) (msg string) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		require.NotNil(t, r, "expected a panic")
+		cerr, ok := r.(*ConfigError)
+		require.True(t, ok, "panic value is %T, want *ConfigError", r)
+		msg = cerr.Error()
+	}()
+	fn()
+	return ""
+}
+
+func TestConfigManager_MustGet(t *testing.T) {
+	mgr := newMustManager(t, WithCMSchemaTypes(map[string]string{"API_URL": "string", "MAX_RETRIES": "number", "NEW_UI": "boolean"}))
+	assert.Equal(t, "https://api", mgr.MustGetPublicConfig("API_URL"))
+	assert.Equal(t, 3.0, mgr.MustGetSecretConfig("MAX_RETRIES"))
+	assert.Equal(t, true, mgr.MustGetFeatureFlag("NEW_UI"))
+
+	msg := panicMessage(t, func() { mgr.MustGetPublicConfig("MISSING") })
+	assert.Equal(t, `[Smooai Config] required config key "MISSING" is not set`, msg)
+}
+
+func TestConfigManager_MustGetMistyped(t *testing.T) {
+	mgr := newMustManager(t, WithCMSchemaTypes(map[string]string{"API_URL": "number"}))
+	msg := panicMessage(t, func() { mgr.MustGetPublicConfig("API_URL") })
+	assert.Equal(t, `[Smooai Config] required config key "API_URL" is string, want number`, msg)
+}
+
+func TestConfigManager_MustGetReadError(t *testing.T) {
+	mgr := newMustManager(t, WithCMKeyTiers(map[string]ConfigTier{"API_URL": TierPublic}))
+	msg := panicMessage(t, func() { mgr.MustGetSecretConfig("API_URL") })
+	assert.Contains(t, msg, `required config key "API_URL"`)
+}
+
+func TestConfigManager_MustInit(t *testing.T) {
+	mgr := newMustManager(t)
+	assert.NotPanics(t, func() { mgr.MustInit("API_URL", "MAX_RETRIES") })
+
+	msg := panicMessage(t, func() { newMustManager(t).MustInit("API_URL", "DB_URL", "REDIS_URL") })
+	assert.Equal(t, "[Smooai Config] required config keys are not set: [DB_URL REDIS_URL]", msg)
+}
+
+func TestMatchesSchemaType(t *testing.T) {
+	assert.True(t, matchesSchemaType("x", "string"))
+	assert.False(t, matchesSchemaType(1.0, "string"))
+	assert.True(t, matchesSchemaType(1, "number"))
+	assert.True(t, matchesSchemaType(false, "boolean"))
+	assert.True(t, matchesSchemaType([]any{1.0}, "json"))
+	assert.False(t, matchesSchemaType("{}", "object"))
+	assert.True(t, matchesSchemaType("anything", "custom"))
+}
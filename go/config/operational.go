@@ -0,0 +1,279 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Typed loaders for the operational settings most services tune at runtime.
+// Each setting is one config object, so every service shares the same shape
+// and a change to related fields (a rate and its burst) applies together.
+// Unknown fields are rejected, so a misspelt field fails loudly instead of
+// silently keeping its default. Durations are seconds, or Go duration strings
+// such as "1m30s".
+
+// RateLimit is a token-bucket rate limit:
+//
+//	{"requestsPerSecond": 100, "burst": 20}
+type RateLimit struct {
+	// RequestsPerSecond is the sustained rate. Must be positive.
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	// Burst is the bucket size. Defaults to RequestsPerSecond rounded up.
+	Burst int `json:"burst"`
+}
+
+// CircuitBreaker holds circuit breaker thresholds:
+//
+//	{"failureThreshold": 5, "successThreshold": 2, "openTimeout": "30s", "halfOpenMaxRequests": 1}
+type CircuitBreaker struct {
+	// FailureThreshold is the consecutive failures that open the circuit.
+	// Must be at least 1.
+	FailureThreshold int `json:"failureThreshold"`
+	// SuccessThreshold is the consecutive half-open successes that close it
+	// again. Defaults to 1.
+	SuccessThreshold int `json:"successThreshold"`
+	// OpenTimeout is how long the circuit stays open before trying again.
+	// Must be positive.
+	OpenTimeout time.Duration `json:"-"`
+	// HalfOpenMaxRequests bounds concurrent trial requests while half-open.
+	// Defaults to 1.
+	HalfOpenMaxRequests int `json:"halfOpenMaxRequests"`
+}
+
+// PoolSize holds connection pool limits, in the shape of the sql.DB
+// setters:
+//
+//	{"maxOpen": 20, "maxIdle": 5, "maxLifetime": "30m", "maxIdleTime": 300}
+type PoolSize struct {
+	// MaxOpen caps open connections; 0 means unlimited.
+	MaxOpen int `json:"maxOpen"`
+	// MaxIdle caps idle connections. Must not exceed a non-zero MaxOpen.
+	MaxIdle int `json:"maxIdle"`
+	// MaxLifetime retires connections after this long; 0 means never.
+	MaxLifetime time.Duration `json:"-"`
+	// MaxIdleTime closes connections idle this long; 0 means never.
+	MaxIdleTime time.Duration `json:"-"`
+}
+
+// GetRateLimit reads key through the public tier as a RateLimit.
+func (m *ConfigManager) GetRateLimit(key string) (RateLimit, error) {
+	value, err := m.GetPublicConfig(key)
+	if err != nil {
+		return RateLimit{}, err
+	}
+	return parseRateLimit(key, value)
+}
+
+// GetCircuitBreaker reads key through the public tier as a CircuitBreaker.
+func (m *ConfigManager) GetCircuitBreaker(key string) (CircuitBreaker, error) {
+	value, err := m.GetPublicConfig(key)
+	if err != nil {
+		return CircuitBreaker{}, err
+	}
+	return parseCircuitBreaker(key, value)
+}
+
+// GetPoolSize reads key through the public tier as a PoolSize.
+func (m *ConfigManager) GetPoolSize(key string) (PoolSize, error) {
+	value, err := m.GetPublicConfig(key)
+	if err != nil {
+		return PoolSize{}, err
+	}
+	return parsePoolSize(key, value)
+}
+
+// WithRateLimitHandler calls fn with the new settings whenever key's value
+// changes after the first load. fn runs on its own goroutine; invalid values
+// are logged and skipped.
+func WithRateLimitHandler(key string, fn func(RateLimit)) ConfigManagerOption {
+	return settingsHandler(key, "rate limit", func(value any) error {
+		rl, err := parseRateLimit(key, value)
+		if err == nil {
+			fn(rl)
+		}
+		return err
+	})
+}
+
+// WithCircuitBreakerHandler calls fn with the new settings whenever key's
+// value changes after the first load. fn runs on its own goroutine; invalid
+// values are logged and skipped.
+func WithCircuitBreakerHandler(key string, fn func(CircuitBreaker)) ConfigManagerOption {
+	return settingsHandler(key, "circuit breaker", func(value any) error {
+		cb, err := parseCircuitBreaker(key, value)
+		if err == nil {
+			fn(cb)
+		}
+		return err
+	})
+}
+
+// WithPoolSizeHandler calls fn with the new settings whenever key's value
+// changes after the first load. fn runs on its own goroutine; invalid values
+// are logged and skipped.
+func WithPoolSizeHandler(key string, fn func(PoolSize)) ConfigManagerOption {
+	return settingsHandler(key, "pool size", func(value any) error {
+		ps, err := parsePoolSize(key, value)
+		if err == nil {
+			fn(ps)
+		}
+		return err
+	})
+}
+
+// settingsHandler registers a change listener that hands key's current
+// value to apply, logging apply's error.
+func settingsHandler(key, kind string, apply func(value any) error) ConfigManagerOption {
+	return func(m *ConfigManager) {
+		// Listeners for successive changes run concurrently and can finish
+		// out of order, so apply the value read back under mu rather than
+		// the event's, which may already be stale.
+		var mu sync.Mutex
+		m.changeListeners = append(m.changeListeners, func(evt ConfigChangeEvent) {
+			if !slices.Contains(evt.Keys, key) {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			value, err := m.GetPublicConfig(key)
+			if err == nil && value == nil {
+				return
+			}
+			if err == nil {
+				err = apply(value)
+			}
+			if err != nil {
+				m.log().Warn("ignoring "+kind+" settings", "error", err)
+			}
+		})
+	}
+}
+
+func parseRateLimit(key string, value any) (RateLimit, error) {
+	var rl RateLimit
+	if err := decodeSettings(key, "rate limit", value, &rl); err != nil {
+		return RateLimit{}, err
+	}
+	invalid := settingsError(key, "rate limit")
+	switch {
+	case rl.RequestsPerSecond <= 0:
+		return RateLimit{}, invalid("requestsPerSecond must be positive")
+	case rl.Burst < 0:
+		return RateLimit{}, invalid("burst must not be negative")
+	}
+	if rl.Burst == 0 {
+		rl.Burst = int(rl.RequestsPerSecond)
+		if float64(rl.Burst) < rl.RequestsPerSecond {
+			rl.Burst++
+		}
+	}
+	return rl, nil
+}
+
+func parseCircuitBreaker(key string, value any) (CircuitBreaker, error) {
+	var raw struct {
+		CircuitBreaker
+		OpenTimeout settingsDuration `json:"openTimeout"`
+	}
+	if err := decodeSettings(key, "circuit breaker", value, &raw); err != nil {
+		return CircuitBreaker{}, err
+	}
+	cb := raw.CircuitBreaker
+	cb.OpenTimeout = time.Duration(raw.OpenTimeout)
+	invalid := settingsError(key, "circuit breaker")
+	switch {
+	case cb.FailureThreshold < 1:
+		return CircuitBreaker{}, invalid("failureThreshold must be at least 1")
+	case cb.SuccessThreshold < 0 || cb.HalfOpenMaxRequests < 0:
+		return CircuitBreaker{}, invalid("thresholds must not be negative")
+	case cb.OpenTimeout <= 0:
+		return CircuitBreaker{}, invalid("openTimeout must be positive")
+	}
+	if cb.SuccessThreshold == 0 {
+		cb.SuccessThreshold = 1
+	}
+	if cb.HalfOpenMaxRequests == 0 {
+		cb.HalfOpenMaxRequests = 1
+	}
+	return cb, nil
+}
+
+func parsePoolSize(key string, value any) (PoolSize, error) {
+	var raw struct {
+		PoolSize
+		MaxLifetime settingsDuration `json:"maxLifetime"`
+		MaxIdleTime settingsDuration `json:"maxIdleTime"`
+	}
+	if err := decodeSettings(key, "pool size", value, &raw); err != nil {
+		return PoolSize{}, err
+	}
+	ps := raw.PoolSize
+	ps.MaxLifetime = time.Duration(raw.MaxLifetime)
+	ps.MaxIdleTime = time.Duration(raw.MaxIdleTime)
+	invalid := settingsError(key, "pool size")
+	switch {
+	case ps.MaxOpen < 0 || ps.MaxIdle < 0:
+		return PoolSize{}, invalid("maxOpen and maxIdle must not be negative")
+	case ps.MaxOpen > 0 && ps.MaxIdle > ps.MaxOpen:
+		return PoolSize{}, invalid("maxIdle must not exceed maxOpen")
+	case ps.MaxLifetime < 0 || ps.MaxIdleTime < 0:
+		return PoolSize{}, invalid("durations must not be negative")
+	}
+	return ps, nil
+}
+
+func settingsError(key, kind string) func(reason string) error {
+	return func(reason string) error {
+		return NewConfigError(fmt.Sprintf("config key %q is not valid %s settings: %s", key, kind, reason))
+	}
+}
+
+// decodeSettings decodes a config object (or its JSON string form) into
+// dst, rejecting unknown fields.
+func decodeSettings(key, kind string, value any, dst any) error {
+	invalid := settingsError(key, kind)
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return invalid("not set")
+	case string:
+		data = []byte(strings.TrimSpace(v))
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return invalid(err.Error())
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return invalid(strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
+
+// settingsDuration decodes a number of seconds or a Go duration string.
+type settingsDuration time.Duration
+
+func (d *settingsDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		*d = settingsDuration(parsed)
+		return nil
+	}
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	*d = settingsDuration(seconds * float64(time.Second))
+	return nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationalSettings_Get(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+			"API_RATE_LIMIT":   map[string]any{"requestsPerSecond": 12.5},
+			"PAYMENTS_BREAKER": map[string]any{"failureThreshold": 5, "openTimeout": "1m30s"},
+			"DB_POOL":          `{"maxOpen": 20, "maxIdle": 5, "maxLifetime": 1800, "maxIdleTime": "5m"}`,
+		}}),
+	}))
+
+	rl, err := mgr.GetRateLimit("API_RATE_LIMIT")
+	require.NoError(t, err)
+	assert.Equal(t, RateLimit{RequestsPerSecond: 12.5, Burst: 13}, rl)
+
+	cb, err := mgr.GetCircuitBreaker("PAYMENTS_BREAKER")
+	require.NoError(t, err)
+	assert.Equal(t, CircuitBreaker{FailureThreshold: 5, SuccessThreshold: 1, OpenTimeout: 90 * time.Second, HalfOpenMaxRequests: 1}, cb)
+
+	ps, err := mgr.GetPoolSize("DB_POOL")
+	require.NoError(t, err)
+	assert.Equal(t, PoolSize{MaxOpen: 20, MaxIdle: 5, MaxLifetime: 30 * time.Minute, MaxIdleTime: 5 * time.Minute}, ps)
+
+	_, err = mgr.GetRateLimit("MISSING")
+	assert.ErrorContains(t, err, "not set")
+}
+
+func TestOperationalSettings_Validation(t *testing.T) {
+	cases := []struct {
+		name  string
+		parse func() error
+		want  string
+	}{
+		{"zero rate", func() error { _, err := parseRateLimit("K", map[string]any{"requestsPerSecond": 0}); return err }, "requestsPerSecond must be positive"},
+		{"typo", func() error { _, err := parseRateLimit("K", map[string]any{"requestPerSecond": 5}); return err }, "unknown field"},
+		{"no failures", func() error {
+			_, err := parseCircuitBreaker("K", map[string]any{"openTimeout": 5})
+			return err
+		}, "failureThreshold"},
+		{"no timeout", func() error {
+			_, err := parseCircuitBreaker("K", map[string]any{"failureThreshold": 3})
+			return err
+		}, "openTimeout must be positive"},
+		{"bad duration", func() error {
+			_, err := parseCircuitBreaker("K", map[string]any{"failureThreshold": 3, "openTimeout": "soon"})
+			return err
+		}, "invalid duration"},
+		{"idle above open", func() error { _, err := parsePoolSize("K", map[string]any{"maxOpen": 2, "maxIdle": 5}); return err }, "maxIdle must not exceed maxOpen"},
+		{"wrong type", func() error { _, err := parsePoolSize("K", map[string]any{"maxOpen": "ten"}); return err }, "K"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorContains(t, tc.parse(), tc.want)
+		})
+	}
+}
+
+func TestOperationalSettings_Handlers(t *testing.T) {
+	rateLimits := make(chan RateLimit, 1)
+	breakers := make(chan CircuitBreaker, 1)
+	pools := make(chan PoolSize, 1)
+	mgr := NewConfigManager(
+		WithRateLimitHandler("RATE", func(rl RateLimit) { rateLimits <- rl }),
+		WithCircuitBreakerHandler("BREAKER", func(cb CircuitBreaker) { breakers <- cb }),
+		WithPoolSizeHandler("POOL", func(ps PoolSize) { pools <- ps }),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+				"RATE": map[string]any{"requestsPerSecond": 10, "burst": 10},
+			}}),
+		}),
+	)
+	_, err := mgr.GetRateLimit("RATE")
+	require.NoError(t, err)
+
+	mgr.SetOverride("RATE", map[string]any{"requestsPerSecond": -1}, 0)
+	mgr.SetOverride("RATE", map[string]any{"requestsPerSecond": 50, "burst": 100}, 0)
+	mgr.SetOverride("BREAKER", map[string]any{"failureThreshold": 2, "openTimeout": 10}, 0)
+	mgr.SetOverride("POOL", map[string]any{"maxOpen": 4}, 0)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case rl := <-rateLimits:
+			assert.Equal(t, RateLimit{RequestsPerSecond: 50, Burst: 100}, rl, "invalid values are skipped")
+		case cb := <-breakers:
+			assert.Equal(t, 10*time.Second, cb.OpenTimeout)
+		case ps := <-pools:
+			assert.Equal(t, 4, ps.MaxOpen)
+		case <-time.After(2 * time.Second):
+			t.Fatal("handler not called")
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"sort"
+	"time"
+)
+
+// redactedValue replaces secret-tier override values in Status.
+const redactedValue = "[redacted]"
+
+type configOverride struct {
+	value any
+	setAt time.Time
+	// expiresAt is when a SetOverride ttl ends (zero = never); timer
+	// clears the override then, if seq still matches.
+	expiresAt time.Time
+	timer     *time.Timer
+	seq       uint64
+}
+
+// ConfigOverride is one active in-memory override, as reported by Status.
+type ConfigOverride struct {
+	Key string
+	// Value is the override value. Unless the key is known to be public or
+	// a feature flag (per WithCMKeyTiers, the schema or the API's tier
+	// metadata), it is reported as "[redacted]".
+	Value any
+	SetAt time.Time
+	// ExpiresAt is when the override clears itself; zero means never.
+	ExpiresAt time.Time
+}
+
+// ManagerStatus is a point-in-time view of a ConfigManager for admin and
+// debug endpoints.
+type ManagerStatus struct {
+	// Initialized reports whether the sources have been loaded.
+	Initialized bool
+	// Health is the same value Health returns.
+	Health ManagerHealth
+	// Overrides are the active SetOverride entries, sorted by key.
+	Overrides []ConfigOverride
+	// RestartPending are the restart-required keys that changed since
+	// startup (see WithRestartRequiredKeys).
+	RestartPending []string
+}
+
+// SetOverride pins key to value in a top-precedence in-memory layer, above
+// env vars and remote values. Overrides survive Invalidate, refreshes and
+// pushed changes until ClearOverride is called or ttl runs out — intended
+// for break-glass operational changes driven by an admin endpoint, where a
+// ttl keeps a forgotten hot-patch from outliving the incident. A ttl of
+// zero or less never expires. Setting a key again replaces its override
+// and ttl. The value replaces the merged value outright (objects are not
+// deep-merged). After Close, overrides stay as they are: ttls no longer run
+// out.
+func (m *ConfigManager) SetOverride(key string, value any, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.overrides == nil {
+		m.overrides = make(map[string]configOverride)
+	}
+	if old, ok := m.overrides[key]; ok && old.timer != nil {
+		old.timer.Stop()
+	}
+	m.overrideSeq++
+	o := configOverride{value: value, setAt: time.Now(), seq: m.overrideSeq}
+	if ttl > 0 && !m.overrideTimersStopped {
+		o.expiresAt = o.setAt.Add(ttl)
+		seq := o.seq
+		o.timer = time.AfterFunc(ttl, func() { m.expireOverride(key, seq) })
+	}
+	m.overrides[key] = o
+	m.refreshKeyLocked(key)
+}
+
+// ClearOverride removes the override for key, restoring the value from the
+// underlying sources. Clearing a key without an override is a no-op.
+func (m *ConfigManager) ClearOverride(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.overrides[key]
+	if !ok {
+		return
+	}
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+	delete(m.overrides, key)
+	m.refreshKeyLocked(key)
+}
+
+// expireOverride clears key's override when its ttl ends, unless it has
+// since been replaced or cleared.
+func (m *ConfigManager) expireOverride(key string, seq uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if o, ok := m.overrides[key]; !ok || o.seq != seq || m.overrideTimersStopped {
+		return
+	}
+	delete(m.overrides, key)
+	m.log().Info("config override expired", "key", key)
+	m.refreshKeyLocked(key)
+}
+
+// stopOverrideTimers stops every ttl timer for Close. A timer already
+// firing finds overrideTimersStopped set and does nothing.
+func (m *ConfigManager) stopOverrideTimers() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.overrideTimersStopped = true
+	for key, o := range m.overrides {
+		if o.timer != nil {
+			o.timer.Stop()
+			o.timer = nil
+			o.expiresAt = time.Time{}
+			m.overrides[key] = o
+		}
+	}
+}
+
+// Status reports the manager's initialization state, health and active
+// overrides. It never triggers a load.
+func (m *ConfigManager) Status() ManagerStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := ManagerStatus{Initialized: m.initialized, Health: m.healthLocked(), RestartPending: m.restartPendingLocked()}
+	for key, o := range m.overrides {
+		value := o.value
+		if !m.revealableLocked(key) {
+			value = redactedValue
+		}
+		status.Overrides = append(status.Overrides, ConfigOverride{Key: key, Value: value, SetAt: o.setAt, ExpiresAt: o.expiresAt})
+	}
+	sort.Slice(status.Overrides, func(i, j int) bool { return status.Overrides[i].Key < status.Overrides[j].Key })
+	return status
+}
+
+// refreshKeyLocked brings config[key] in line after an override change.
+// Deferred and interpolated values may depend on any key, so those managers
+// reload fully.
+func (m *ConfigManager) refreshKeyLocked(key string) {
+	if !m.initialized {
+		return
+	}
+	if m.dependentValuesLocked() {
+		m.resetLocked()
+		return
+	}
+	m.remergeKeyLocked(key)
+}
+
+// applyOverridesLocked writes every override into merged.
+func (m *ConfigManager) applyOverridesLocked(merged map[string]any) {
+	for key, o := range m.overrides {
+		merged[key] = o.value
+	}
+}
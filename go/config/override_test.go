@@ -0,0 +1,164 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigManager_OverrideWinsOverAllSources(t *testing.T) {
+	mock := newMockCMServer("key", "org", map[string]any{"API_URL": "remote", "RATE": 10.0})
+	defer mock.close()
+	configDir := makeCMConfigDir(t, map[string]any{
+		"default.json": map[string]any{"API_URL": "file", "RATE": 1.0},
+	})
+
+	mgr := NewConfigManager(
+		WithAPIKey("key"),
+		WithBaseURL(mock.server.URL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMSchemaKeys(map[string]bool{"API_URL": true}),
+		WithCMEnvOverride(mock.envOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": configDir,
+			"API_URL":               "env",
+		})),
+	)
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "env", v)
+
+	mgr.SetOverride("API_URL", "override", 0)
+	mgr.SetOverride("RATE", 0.0, 0)
+	v, _ = mgr.GetPublicConfig("API_URL")
+	assert.Equal(t, "override", v)
+	v, _ = mgr.GetPublicConfig("RATE")
+	assert.Equal(t, 0.0, v)
+
+	mgr.Invalidate()
+	v, _ = mgr.GetPublicConfig("API_URL")
+	assert.Equal(t, "override", v, "overrides survive a refresh")
+
+	mgr.ClearOverride("API_URL")
+	mgr.ClearOverride("RATE")
+	v, _ = mgr.GetPublicConfig("API_URL")
+	assert.Equal(t, "env", v)
+	v, _ = mgr.GetPublicConfig("RATE")
+	assert.Equal(t, 10.0, v)
+	assert.Equal(t, 2, mock.count(), "clearing re-merges without a refetch")
+}
+
+func TestConfigManager_OverrideBeforeInitialize(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{}))
+	mgr.SetOverride("KILL_SWITCH", true, 0)
+
+	v, err := mgr.GetFeatureFlag("KILL_SWITCH")
+	require.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	mgr.ClearOverride("KILL_SWITCH")
+	v, err = mgr.GetFeatureFlag("KILL_SWITCH")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestConfigManager_OverrideWinsOverDeferred(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMEnvOverride(map[string]string{}),
+		WithDeferred("DERIVED", func(cfg map[string]any) any { return "computed:" + toStringOr(cfg["BASE"]) }),
+	)
+	v, _ := mgr.GetPublicConfig("DERIVED")
+	assert.Equal(t, "computed:", v)
+
+	mgr.SetOverride("BASE", "b", 0)
+	v, _ = mgr.GetPublicConfig("DERIVED")
+	assert.Equal(t, "computed:b", v, "deferred values see overrides")
+
+	mgr.SetOverride("DERIVED", "pinned", 0)
+	v, _ = mgr.GetPublicConfig("DERIVED")
+	assert.Equal(t, "pinned", v)
+}
+
+func TestConfigManager_StatusListsOverrides(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMEnvOverride(map[string]string{}),
+		WithCMKeyTiers(map[string]ConfigTier{"DB_PASSWORD": TierSecret, "MAINTENANCE": TierFeatureFlag}),
+	)
+	status := mgr.Status()
+	assert.False(t, status.Initialized)
+	assert.Empty(t, status.Overrides)
+
+	mgr.SetOverride("MAINTENANCE", true, 0)
+	mgr.SetOverride("DB_PASSWORD", "rotated", 0)
+	mgr.SetOverride("SIGNING_SALT", "pepper", 0)
+	_, _ = mgr.GetFeatureFlag("MAINTENANCE")
+
+	status = mgr.Status()
+	assert.True(t, status.Initialized)
+	assert.True(t, status.Health.IsHealthy())
+	require.Len(t, status.Overrides, 3)
+	assert.Equal(t, "DB_PASSWORD", status.Overrides[0].Key)
+	assert.Equal(t, "[redacted]", status.Overrides[0].Value)
+	assert.Equal(t, "MAINTENANCE", status.Overrides[1].Key)
+	assert.Equal(t, true, status.Overrides[1].Value)
+	assert.False(t, status.Overrides[1].SetAt.IsZero())
+	assert.Equal(t, "SIGNING_SALT", status.Overrides[2].Key)
+	assert.Equal(t, "[redacted]", status.Overrides[2].Value, "a key of unknown tier is redacted")
+}
+
+func toStringOr(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func TestConfigManager_OverrideTTL(t *testing.T) {
+	configDir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_URL": "file"}})
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": configDir}))
+
+	mgr.SetOverride("API_URL", "patched", 50*time.Millisecond)
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "patched", v)
+	overrides := mgr.Status().Overrides
+	require.Len(t, overrides, 1)
+	assert.WithinDuration(t, overrides[0].SetAt.Add(50*time.Millisecond), overrides[0].ExpiresAt, 0)
+
+	assert.Eventually(t, func() bool {
+		v, _ := mgr.GetPublicConfig("API_URL")
+		return v == "file"
+	}, time.Second, 10*time.Millisecond, "the override clears itself")
+	assert.Empty(t, mgr.Status().Overrides)
+
+	t.Run("setting again replaces the ttl", func(t *testing.T) {
+		mgr.SetOverride("API_URL", "short", 20*time.Millisecond)
+		mgr.SetOverride("API_URL", "pinned", 0)
+		time.Sleep(60 * time.Millisecond)
+		v, err := mgr.GetPublicConfig("API_URL")
+		require.NoError(t, err)
+		assert.Equal(t, "pinned", v)
+		assert.True(t, mgr.Status().Overrides[0].ExpiresAt.IsZero())
+		mgr.ClearOverride("API_URL")
+	})
+}
+
+func TestConfigManager_CloseStopsOverrideTTL(t *testing.T) {
+	configDir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_URL": "file"}})
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": configDir}))
+
+	mgr.SetOverride("API_URL", "patched", 30*time.Millisecond)
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	require.NoError(t, mgr.Close(context.Background()))
+
+	time.Sleep(80 * time.Millisecond)
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "patched", v, "the ttl no longer runs after Close")
+	overrides := mgr.Status().Overrides
+	require.Len(t, overrides, 1)
+	assert.True(t, overrides[0].ExpiresAt.IsZero())
+}
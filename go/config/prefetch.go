@@ -0,0 +1,49 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// prefetchConcurrency bounds how many environments PrefetchEnvironments
+// fetches at once, so a tool sweeping dozens of environments doesn't open a
+// connection per environment against the API.
+const prefetchConcurrency = 4
+
+// PrefetchEnvironments fetches every listed environment in parallel, at most
+// four at a time, and populates the cache so later GetValue / GetAllValues
+// calls for them are served locally. An empty name means the client's
+// default environment; duplicates are fetched once.
+//
+// Every environment is attempted. The returned error joins one error per
+// environment that failed, each naming its environment; environments that
+// succeeded stay cached either way.
+func (c *ConfigClient) PrefetchEnvironments(envs ...string) error {
+	seen := make(map[string]bool, len(envs))
+	unique := make([]string, 0, len(envs))
+	for _, env := range envs {
+		env = c.resolveEnv(env)
+		if !seen[env] {
+			seen[env] = true
+			unique = append(unique, env)
+		}
+	}
+
+	errs := make([]error, len(unique))
+	sem := make(chan struct{}, prefetchConcurrency)
+	var wg sync.WaitGroup
+	for i, env := range unique {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if _, err := c.GetAllValues(env); err != nil {
+				errs[i] = fmt.Errorf("prefetch %s: %w", env, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefetchEnvironments_PopulatesCacheWithBoundedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	var mu sync.Mutex
+	fetched := map[string]int{}
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		env := r.URL.Query().Get("environment")
+		mu.Lock()
+		fetched[env]++
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"values": {"ENV_NAME": "` + env + `"}}`))
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	envs := []string{"dev", "qa", "staging", "production", "eu-1", "eu-2", "us-1", "us-2", "dev"}
+	require.NoError(t, client.PrefetchEnvironments(envs...))
+
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(prefetchConcurrency))
+	assert.Greater(t, maxInFlight.Load(), int32(1))
+	assert.Len(t, fetched, 8)
+	assert.Equal(t, 1, fetched["dev"])
+
+	for _, env := range envs {
+		v, ok := client.GetCachedValue("ENV_NAME", env)
+		assert.True(t, ok, env)
+		assert.Equal(t, env, v)
+	}
+}
+
+func TestPrefetchEnvironments_JoinsErrors(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("environment") == "broken" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"values": {"A": 1}}`))
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	err := client.PrefetchEnvironments("production", "broken", "bad env")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, err, ErrInvalidEnvironment)
+	assert.Contains(t, err.Error(), "prefetch broken:")
+
+	_, ok := client.GetCachedValue("A", "production")
+	assert.True(t, ok)
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"sync/atomic"
+)
+
+// Provider is the read side of a config manager, for code that should
+// depend on an interface rather than on *ConfigManager:
+//
+//	type Server struct{ cfg config.Provider }
+//
+//	port, err := config.Get[int](s.cfg, "PORT")
+//
+// ConfigManager, LocalConfigManager and ConfigView implement it, as does
+// configtest.FakeProvider for tests.
+type Provider interface {
+	GetPublicConfig(key string) (any, error)
+	GetSecretConfig(key string) (any, error)
+	GetFeatureFlag(key string) (any, error)
+	// Watch calls fn with the keys whose effective value changed until
+	// stop is called. Calling stop more than once is safe; no call to fn
+	// starts after it returns.
+	Watch(fn func(ConfigChangeEvent)) (stop func())
+}
+
+var (
+	_ Provider = (*ConfigManager)(nil)
+	_ Provider = (*LocalConfigManager)(nil)
+	_ Provider = (*ConfigView)(nil)
+)
+
+// watcher is one Watch registration; stopped keeps fn from starting once
+// stop has returned, even for an event already on its way.
+type watcher struct {
+	fn      func(ConfigChangeEvent)
+	stopped atomic.Bool
+}
+
+// notify runs w.fn with evt through run unless w is stopped.
+func (w *watcher) notify(evt ConfigChangeEvent, run func(func())) {
+	run(func() {
+		if !w.stopped.Load() {
+			w.fn(evt)
+		}
+	})
+}
+
+// Watch calls fn, on its own goroutine, with every change to the merged
+// config: a reload, a pushed change, a file change picked up by
+// WithCMFileWatch, or an override. See Provider.
+func (m *ConfigManager) Watch(fn func(ConfigChangeEvent)) (stop func()) {
+	w := &watcher{fn: fn}
+	m.mu.Lock()
+	m.watchers = append(m.watchers, w)
+	m.mu.Unlock()
+	return func() {
+		w.stopped.Store(true)
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.watchers = slices.DeleteFunc(m.watchers, func(o *watcher) bool { return o == w })
+	}
+}
+
+// Watch calls fn with the keys a config file change altered once the
+// config has been read. Only WithFileWatch detects changes; without it fn
+// is never called.
+func (m *LocalConfigManager) Watch(fn func(ConfigChangeEvent)) (stop func()) {
+	w := &watcher{fn: fn}
+	m.mu.Lock()
+	m.watchers = append(m.watchers, w)
+	m.mu.Unlock()
+	return func() {
+		w.stopped.Store(true)
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.watchers = slices.DeleteFunc(m.watchers, func(o *watcher) bool { return o == w })
+	}
+}
+
+// effectiveLocked is the merged config the getters serve: files over env
+// vars. Must be called under m.mu.
+func (m *LocalConfigManager) effectiveLocked() map[string]any {
+	effective := make(map[string]any, len(m.fileConfig)+len(m.envConfig))
+	maps.Copy(effective, m.envConfig)
+	maps.Copy(effective, m.fileConfig)
+	return effective
+}
+
+// notifyWatchersLocked hands the keys that differ between before and the
+// current config to every watcher. Must be called under m.mu.
+func (m *LocalConfigManager) notifyWatchersLocked(before map[string]any) {
+	if len(m.watchers) == 0 {
+		return
+	}
+	after := m.effectiveLocked()
+	keys := changedKeys(before, after)
+	if len(keys) == 0 {
+		return
+	}
+	evt := ConfigChangeEvent{Keys: keys, Values: make(map[string]any, len(keys))}
+	for _, key := range keys {
+		if v, ok := after[key]; ok {
+			evt.Values[key] = v
+		}
+	}
+	for _, w := range m.watchers {
+		w.notify(evt, func(fn func()) { go fn() })
+	}
+}
+
+// Watch is ConfigManager.Watch for the keys the view doesn't override:
+// a change to an overridden key isn't visible through the view.
+func (v *ConfigView) Watch(fn func(ConfigChangeEvent)) (stop func()) {
+	return v.m.Watch(func(evt ConfigChangeEvent) {
+		keys := slices.DeleteFunc(slices.Clone(evt.Keys), func(key string) bool {
+			_, overridden := v.overrides[key]
+			return overridden
+		})
+		if len(keys) == 0 {
+			return
+		}
+		values := make(map[string]any, len(keys))
+		for _, key := range keys {
+			if value, ok := evt.Values[key]; ok {
+				values[key] = value
+			}
+		}
+		fn(ConfigChangeEvent{ID: evt.ID, Environment: evt.Environment, Keys: keys, Values: values})
+	})
+}
+
+// tieredProvider is a Provider that knows each key's tier, so Get reads a
+// key through the getter of its tier.
+type tieredProvider interface {
+	getOwnTier(key string) (any, error)
+	configEnvironment() string
+}
+
+// getOwnTier reads key from p through the getter of its tier when p knows
+// tiers, and as a public key otherwise.
+func getOwnTier(p Provider, key string) (any, error) {
+	if tp, ok := p.(tieredProvider); ok {
+		return tp.getOwnTier(key)
+	}
+	return p.GetPublicConfig(key)
+}
+
+// providerEnvironment is the environment p reads, when it knows one.
+func providerEnvironment(p Provider) string {
+	if tp, ok := p.(tieredProvider); ok {
+		return tp.configEnvironment()
+	}
+	return ""
+}
+
+// getOwnTier is ConfigManager.getOwnTier with the view's overrides.
+func (v *ConfigView) getOwnTier(key string) (any, error) {
+	switch tier, err := v.m.ownTier(key); {
+	case err != nil:
+		return nil, err
+	case tier == TierSecret:
+		return v.GetSecretConfig(key)
+	case tier == TierFeatureFlag:
+		return v.GetFeatureFlag(key)
+	}
+	return v.GetPublicConfig(key)
+}
+
+func (v *ConfigView) configEnvironment() string { return v.m.configEnvironment() }
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigManager_Watch(t *testing.T) {
+	mgr := newViewManager(t)
+	events := make(chan ConfigChangeEvent, 4)
+	stop := mgr.Watch(func(evt ConfigChangeEvent) { events <- evt })
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+
+	mgr.SetOverride("API_URL", "override", 0)
+	select {
+	case evt := <-events:
+		assert.Equal(t, []string{"API_URL"}, evt.Keys)
+		assert.Equal(t, "override", evt.Values["API_URL"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change event after SetOverride")
+	}
+
+	stop()
+	stop()
+	mgr.ClearOverride("API_URL")
+	select {
+	case evt := <-events:
+		t.Fatalf("event after stop: %+v", evt)
+	case <-time.After(50 * time.Millisecond):
+	}
+	mgr.mu.Lock()
+	assert.Empty(t, mgr.watchers, "stop removes the registration")
+	mgr.mu.Unlock()
+}
+
+func TestConfigView_Watch(t *testing.T) {
+	mgr := newViewManager(t)
+	view := mgr.WithOverrides(map[string]any{"NEW_CHECKOUT": true})
+	events := make(chan ConfigChangeEvent, 4)
+	defer view.Watch(func(evt ConfigChangeEvent) { events <- evt })()
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+
+	mgr.SetOverride("NEW_CHECKOUT", false, 0)
+	mgr.SetOverride("API_URL", "override", 0)
+	select {
+	case evt := <-events:
+		assert.Equal(t, []string{"API_URL"}, evt.Keys, "the view's own overrides hide manager changes")
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change event after SetOverride")
+	}
+}
+
+func TestLocalConfigManager_Watch(t *testing.T) {
+	fastFileWatch(t)
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_URL": "http://old", "TIMEOUT": 5}})
+	mgr := NewLocalConfigManager(
+		WithFileWatch(),
+		WithEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}),
+	)
+	defer mgr.StopFileWatch()
+	events := make(chan ConfigChangeEvent, 4)
+	defer mgr.Watch(func(evt ConfigChangeEvent) { events <- evt })()
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "default.json"), []byte(`{"API_URL": "http://new", "TIMEOUT": 5}`), 0o644))
+	select {
+	case evt := <-events:
+		assert.Equal(t, []string{"API_URL"}, evt.Keys)
+		assert.Equal(t, "http://new", evt.Values["API_URL"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change event after editing default.json")
+	}
+}
+
+func TestGet_Provider(t *testing.T) {
+	local := NewLocalConfigManager(WithEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"PORT": 8080}}),
+	}))
+	port, err := Get[int](local, "PORT")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+	_, err = GetRequired[string](local, "MISSING")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	view := newViewManager(t).WithOverrides(map[string]any{"NEW_CHECKOUT": true})
+	on, err := Get[bool](view, "NEW_CHECKOUT")
+	require.NoError(t, err, "a flag is read through GetFeatureFlag")
+	assert.True(t, on)
+	password, err := Get[string](view, "DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", password)
+}
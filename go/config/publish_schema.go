@@ -0,0 +1,183 @@
+package config
+
+// Schema publishing. PublishSchema uploads a ConfigDefinition's JSON Schema
+// so the dashboard and the other SDKs validate against the same contract,
+// the way the CLI's push command does.
+//
+// Server contract:
+//
+//	GET  {BaseURL}/organizations/{orgId}/config/schemas
+//	[{"id": "...", "name": "...", "currentVersion": 3, "jsonSchema": {...}}, ...]
+//
+//	POST {BaseURL}/organizations/{orgId}/config/schemas
+//	{"name": "...", "jsonSchema": {...}, "description": "...", "environment": "..."}
+//	→ the created schema
+//
+//	POST {BaseURL}/organizations/{orgId}/config/schemas/{schemaId}/push
+//	{"jsonSchema": {...}, "changeDescription": "...", "environment": "..."}
+//	→ {"schema": {...}, "version": {"version": 4, ...}}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// PublishSchemaOption configures a PublishSchema call.
+type PublishSchemaOption func(*publishSchemaOptions)
+
+type publishSchemaOptions struct {
+	name        string
+	description string
+}
+
+// WithSchemaName names the schema on the server. Defaults to the
+// definition's "$smooaiName", as set in a schema.json for the CLI.
+func WithSchemaName(name string) PublishSchemaOption {
+	return func(o *publishSchemaOptions) { o.name = name }
+}
+
+// WithSchemaDescription describes the schema when it is created, or the
+// change when a new version is pushed.
+func WithSchemaDescription(description string) PublishSchemaOption {
+	return func(o *publishSchemaOptions) { o.description = description }
+}
+
+// PublishedSchema is the server's record of a published schema.
+type PublishedSchema struct {
+	ID   string
+	Name string
+	// Version is the schema's current version number.
+	Version int
+	// Created is true when the schema did not exist and was created.
+	Created bool
+	// Changed is false when the server already had an identical schema and
+	// nothing was uploaded.
+	Changed bool
+}
+
+// PublishSchema uploads def's JSON Schema for environment, creating the
+// named schema or pushing a new version of it. Pushing a schema identical to
+// the server's current version is a no-op. The schema is checked with
+// ValidateSmooaiSchema first, so a schema the other SDKs can't evaluate is
+// rejected before anything is sent. Pass an empty environment to use the
+// client's default.
+func (c *ConfigClient) PublishSchema(def *ConfigDefinition, environment string, opts ...PublishSchemaOption) (*PublishedSchema, error) {
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
+	}
+	var o publishSchemaOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	name := o.name
+	if name == "" {
+		name, _ = def.JSONSchema["$smooaiName"].(string)
+	}
+	if name == "" {
+		return nil, NewConfigError("schema name is required: pass WithSchemaName or set \"$smooaiName\" in the schema")
+	}
+	if result := ValidateSmooaiSchema(def.JSONSchema); !result.Valid {
+		msgs := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			msgs[i] = fmt.Sprintf("%s: %s", e.Path, e.Message)
+		}
+		return nil, NewConfigError("schema uses unsupported JSON Schema features: " + strings.Join(msgs, "; "))
+	}
+
+	var existing []remoteSchema
+	if err := c.schemaRequest(http.MethodGet, "/config/schemas", nil, &existing); err != nil {
+		return nil, err
+	}
+	for _, s := range existing {
+		if s.Name != name {
+			continue
+		}
+		published := &PublishedSchema{ID: s.ID, Name: s.Name, Version: s.CurrentVersion}
+		if reflect.DeepEqual(jsonRoundTrip(def.JSONSchema), s.JSONSchema) {
+			return published, nil
+		}
+		var pushed struct {
+			Schema  remoteSchema `json:"schema"`
+			Version struct {
+				Version int `json:"version"`
+			} `json:"version"`
+		}
+		body := map[string]any{"jsonSchema": def.JSONSchema, "environment": env}
+		if o.description != "" {
+			body["changeDescription"] = o.description
+		}
+		if err := c.schemaRequest(http.MethodPost, "/config/schemas/"+url.PathEscape(s.ID)+"/push", body, &pushed); err != nil {
+			return nil, err
+		}
+		published.Version = pushed.Version.Version
+		if published.Version == 0 {
+			published.Version = pushed.Schema.CurrentVersion
+		}
+		published.Changed = true
+		return published, nil
+	}
+
+	var created remoteSchema
+	body := map[string]any{"name": name, "jsonSchema": def.JSONSchema, "environment": env}
+	if o.description != "" {
+		body["description"] = o.description
+	}
+	if err := c.schemaRequest(http.MethodPost, "/config/schemas", body, &created); err != nil {
+		return nil, err
+	}
+	return &PublishedSchema{ID: created.ID, Name: name, Version: created.CurrentVersion, Created: true, Changed: true}, nil
+}
+
+// schemaRequest sends a JSON request to the org's schema endpoints and
+// decodes the response into out.
+func (c *ConfigClient) schemaRequest(method, path string, body, out any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("config publish schema: marshal body: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method, c.orgURL()+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("config publish schema: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		return fmt.Errorf("config publish schema: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newHTTPError("publish schema", resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("config publish schema decode: %w", err)
+	}
+	return nil
+}
+
+// jsonRoundTrip round-trips v through JSON so it compares equal to a decoded
+// server response (numbers as float64, typed slices as []any).
+func jsonRoundTrip(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
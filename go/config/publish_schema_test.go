@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publishDefinition() *ConfigDefinition {
+	return DefineConfig(map[string]any{"type": "object", "properties": map[string]any{
+		"API_URL": map[string]any{"type": "string"},
+	}}, nil, nil)
+}
+
+func TestPublishSchema_CreatesNewSchema(t *testing.T) {
+	var created map[string]any
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/organizations/"+testOrgID+"/config/schemas":
+			json.NewEncoder(w).Encode([]map[string]any{{"id": "other", "name": "billing", "currentVersion": 2}})
+		case r.Method == http.MethodPost && r.URL.Path == "/organizations/"+testOrgID+"/config/schemas":
+			json.NewDecoder(r.Body).Decode(&created)
+			json.NewEncoder(w).Encode(map[string]any{"id": "s-1", "name": "orders", "currentVersion": 1})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	published, err := client.PublishSchema(publishDefinition(), "production", WithSchemaName("orders"), WithSchemaDescription("orders service"))
+	require.NoError(t, err)
+	assert.Equal(t, &PublishedSchema{ID: "s-1", Name: "orders", Version: 1, Created: true, Changed: true}, published)
+	assert.Equal(t, "orders", created["name"])
+	assert.Equal(t, "production", created["environment"])
+	assert.Equal(t, "orders service", created["description"])
+	assert.Equal(t, jsonRoundTrip(publishDefinition().JSONSchema), created["jsonSchema"])
+}
+
+func TestPublishSchema_PushesNewVersion(t *testing.T) {
+	var pushed map[string]any
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode([]map[string]any{{"id": "s-1", "name": "orders", "currentVersion": 3, "jsonSchema": map[string]any{"type": "object"}}})
+		case r.URL.Path == "/organizations/"+testOrgID+"/config/schemas/s-1/push":
+			json.NewDecoder(r.Body).Decode(&pushed)
+			json.NewEncoder(w).Encode(map[string]any{"schema": map[string]any{"id": "s-1", "currentVersion": 4}, "version": map[string]any{"version": 4}})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	def := publishDefinition()
+	def.JSONSchema["$smooaiName"] = "orders"
+	published, err := client.PublishSchema(def, "staging", WithSchemaDescription("add API_URL"))
+	require.NoError(t, err)
+	assert.Equal(t, &PublishedSchema{ID: "s-1", Name: "orders", Version: 4, Changed: true}, published)
+	assert.Equal(t, "add API_URL", pushed["changeDescription"])
+	assert.Equal(t, "staging", pushed["environment"])
+}
+
+func TestPublishSchema_UnchangedIsNoop(t *testing.T) {
+	def := publishDefinition()
+	posts := 0
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts++
+		}
+		json.NewEncoder(w).Encode([]map[string]any{{"id": "s-1", "name": "orders", "currentVersion": 3, "jsonSchema": def.JSONSchema}})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	published, err := client.PublishSchema(def, "production", WithSchemaName("orders"))
+	require.NoError(t, err)
+	assert.Equal(t, &PublishedSchema{ID: "s-1", Name: "orders", Version: 3}, published)
+	assert.Zero(t, posts)
+}
+
+func TestPublishSchema_RejectsBeforeSending(t *testing.T) {
+	requests := 0
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) { requests++ })
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.PublishSchema(publishDefinition(), "production")
+	assert.ErrorContains(t, err, "schema name is required")
+
+	unsupported := DefineConfig(map[string]any{"type": "object", "properties": map[string]any{
+		"A": map[string]any{"type": "string", "if": map[string]any{}},
+	}}, nil, nil)
+	_, err = client.PublishSchema(unsupported, "production", WithSchemaName("orders"))
+	assert.ErrorContains(t, err, "unsupported JSON Schema features")
+
+	_, err = client.PublishSchema(publishDefinition(), "bad env", WithSchemaName("orders"))
+	assert.True(t, errors.Is(err, ErrInvalidEnvironment))
+	assert.Zero(t, requests)
+}
+
+func TestPublishSchema_ServerError(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.PublishSchema(publishDefinition(), "production", WithSchemaName("orders"))
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+}
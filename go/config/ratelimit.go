@@ -0,0 +1,125 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited matches the *RateLimitedError a ConfigClient returns when
+// a RateLimitReject limiter has no request to spare.
+var ErrRateLimited = errors.New("config client rate limited")
+
+// RateLimitedError is returned without a request being sent when a
+// RateLimitReject limiter is out of requests.
+type RateLimitedError struct {
+	// RetryIn is how long until the limiter allows another request.
+	RetryIn time.Duration
+}
+
+// Error implements the error interface.
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("config API request rate limited, retry in %s", e.RetryIn)
+}
+
+// Is matches ErrRateLimited.
+func (e *RateLimitedError) Is(target error) bool { return target == ErrRateLimited }
+
+// RateLimitMode is what a RateLimiter does with a request over the limit.
+type RateLimitMode int
+
+const (
+	// RateLimitWait queues the request until the limit allows it or its
+	// context is done.
+	RateLimitWait RateLimitMode = iota
+	// RateLimitReject fails the request at once with a *RateLimitedError.
+	RateLimitReject
+)
+
+// RateLimiter is a token bucket that caps the requests ConfigClients send
+// to the API. Share one between clients (WithRateLimiter) or managers
+// (WithCMRateLimiter) to cap a whole process. A nil *RateLimiter allows
+// every request.
+type RateLimiter struct {
+	rps   float64
+	burst float64
+	mode  RateLimitMode
+
+	mu     sync.Mutex
+	tokens float64
+	// last is when tokens was last topped up, on the monotonic clock.
+	last time.Duration
+}
+
+// NewRateLimiter allows rps requests a second on average and bursts of up
+// to burst requests, handling the requests over the limit as mode says. An
+// rps of zero or less returns nil, which doesn't limit; a burst below 1 is
+// 1.
+func NewRateLimiter(rps float64, burst int, mode RateLimitMode) *RateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	b := float64(max(burst, 1))
+	return &RateLimiter{rps: rps, burst: b, mode: mode, tokens: b, last: monoNow()}
+}
+
+// WithRateLimit caps the client at rps requests a second with bursts of up
+// to burst, queuing the requests over the limit. For a limit shared with
+// other clients or one that rejects, use WithRateLimiter.
+func WithRateLimit(rps float64, burst int) ConfigClientOption {
+	return WithRateLimiter(NewRateLimiter(rps, burst, RateLimitWait))
+}
+
+// WithRateLimiter caps the client's requests with l, which other clients
+// may share.
+func WithRateLimiter(l *RateLimiter) ConfigClientOption {
+	return func(c *ConfigClient) { c.limiter = l }
+}
+
+// WithCMRateLimiter caps the manager's config API requests with l. Pass
+// the same limiter to every manager in a process so aggressive refresh
+// intervals or many managers can't hammer the API together.
+func WithCMRateLimiter(l *RateLimiter) ConfigManagerOption {
+	return func(m *ConfigManager) { m.limiter = l }
+}
+
+// wait takes a request from the bucket, waiting for one to become free or
+// rejecting the request as the mode says.
+func (l *RateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	delay, err := l.reserve()
+	if err != nil || delay <= 0 {
+		return err
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++ // give back the request that was never sent
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a request from the bucket and returns how long to wait
+// before sending it.
+func (l *RateLimiter) reserve() (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := monoNow()
+	l.tokens = min(l.burst, l.tokens+(now-l.last).Seconds()*l.rps)
+	l.last = now
+	short := 1 - l.tokens
+	if short > 0 && l.mode == RateLimitReject {
+		return 0, &RateLimitedError{RetryIn: time.Duration(short / l.rps * float64(time.Second))}
+	}
+	l.tokens--
+	return time.Duration(short / l.rps * float64(time.Second)), nil
+}
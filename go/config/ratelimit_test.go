@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Reject(t *testing.T) {
+	advance := fakeMonoClock(t)
+	l := NewRateLimiter(2, 2, RateLimitReject)
+	ctx := context.Background()
+
+	require.NoError(t, l.wait(ctx))
+	require.NoError(t, l.wait(ctx), "a burst of 2 goes through")
+	err := l.wait(ctx)
+	require.ErrorIs(t, err, ErrRateLimited)
+	var limited *RateLimitedError
+	require.ErrorAs(t, err, &limited)
+	assert.Equal(t, 500*time.Millisecond, limited.RetryIn)
+
+	advance(500 * time.Millisecond)
+	require.NoError(t, l.wait(ctx))
+	require.ErrorIs(t, l.wait(ctx), ErrRateLimited)
+
+	advance(time.Hour)
+	require.NoError(t, l.wait(ctx))
+	require.NoError(t, l.wait(ctx))
+	require.ErrorIs(t, l.wait(ctx), ErrRateLimited, "idle time refills no more than the burst")
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	l := NewRateLimiter(50, 1, RateLimitWait)
+	start := time.Now()
+	for range 3 {
+		require.NoError(t, l.wait(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond, "requests over the burst are queued")
+
+	t.Run("a canceled wait gives its request back", func(t *testing.T) {
+		advance := fakeMonoClock(t)
+		l := NewRateLimiter(1, 1, RateLimitWait)
+		require.NoError(t, l.wait(context.Background()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, l.wait(ctx), context.Canceled)
+
+		advance(time.Second)
+		delay, err := l.reserve()
+		require.NoError(t, err)
+		assert.Zero(t, delay)
+	})
+}
+
+func TestRateLimiter_Off(t *testing.T) {
+	assert.Nil(t, NewRateLimiter(0, 10, RateLimitReject))
+	var l *RateLimiter
+	assert.NoError(t, l.wait(context.Background()))
+}
+
+func TestConfigClient_RateLimiterSharedAcrossClients(t *testing.T) {
+	fakeMonoClock(t)
+	server, _, calls := countingRemote(t, map[string]any{"K": "v"})
+	limiter := NewRateLimiter(1, 2, RateLimitReject)
+	a := newUnitClient(t, server.URL, WithRateLimiter(limiter))
+	b := newUnitClient(t, server.URL, WithRateLimiter(limiter))
+
+	_, err := a.GetAllValues("production")
+	require.NoError(t, err)
+	_, err = b.GetAllValues("production")
+	require.NoError(t, err)
+	_, err = a.GetValue("K", "staging")
+	require.ErrorIs(t, err, ErrRateLimited)
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestConfigManager_RateLimiter(t *testing.T) {
+	fakeMonoClock(t)
+	server, _, calls := countingRemote(t, map[string]any{"API_URL": "https://remote"})
+	limiter := NewRateLimiter(1, 1, RateLimitReject)
+	mgr := newStalenessManager(t, server.URL, WithMaxStaleness(time.Hour), WithCMRateLimiter(limiter))
+
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	mgr.Invalidate()
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote", v, "a rejected refresh serves the last good values")
+	assert.EqualValues(t, 1, calls.Load())
+	assert.ErrorIs(t, mgr.Health().LastError, ErrRateLimited)
+}
+
+func TestConfigClient_RateLimiterCountsAuthRetry(t *testing.T) {
+	fakeMonoClock(t)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"values": map[string]any{"K": "v"}})
+	}))
+	t.Cleanup(server.Close)
+	client := newUnitClient(t, server.URL, WithRateLimiter(NewRateLimiter(1, 1, RateLimitReject)))
+
+	_, err := client.GetAllValues("production")
+	require.ErrorIs(t, err, ErrRateLimited, "the retry after a 401 needs a token of its own")
+	assert.EqualValues(t, 1, calls.Load())
+}
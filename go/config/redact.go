@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// secretMask replaces all but MaskSecret's short prefix of a secret.
+const secretMask = "****"
+
+// MaskSecret returns a printable stand-in for a secret value: strings of 12
+// or more characters keep their first three, so "sk-live-9f8e7d6c" becomes
+// "sk-****", and everything else becomes "****".
+func MaskSecret(v any) string {
+	if s, ok := v.(string); ok {
+		if r := []rune(s); len(r) >= 12 {
+			return string(r[:3]) + secretMask
+		}
+	}
+	return secretMask
+}
+
+// Redacted returns a copy of the merged config that is safe to log: secret
+// values are masked with MaskSecret. Only keys known to be public or feature
+// flags (from WithCMKeyTiers, the schema or the API's tier metadata) keep
+// their values, so a key of unknown tier is masked too. Loads the config on
+// first use like the Get* methods; a schema violation doesn't stop it.
+func (m *ConfigManager) Redacted() (map[string]any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.initialize(); err != nil && m.config == nil {
+		return nil, err
+	}
+	out := make(map[string]any, len(m.config))
+	for key, value := range m.config {
+		if m.revealableLocked(key) {
+			out[key] = value
+		} else {
+			out[key] = MaskSecret(value)
+		}
+	}
+	return out, nil
+}
+
+// SafeDump returns Redacted as indented JSON, keys sorted, for debug output.
+func (m *ConfigManager) SafeDump() (string, error) {
+	values, err := m.Redacted()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// revealableLocked reports whether key's value may be printed: it is known
+// to be public or a feature flag. Must be called under m.mu.
+func (m *ConfigManager) revealableLocked(key string) bool {
+	switch m.keyTierLocked(key) {
+	case TierPublic, TierFeatureFlag:
+		return true
+	}
+	return false
+}
+
+// String implements fmt.Stringer without printing any value, so logging a
+// manager by mistake can't leak a secret. Use SafeDump to see the values.
+func (m *ConfigManager) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.initialized {
+		return "config.ConfigManager{not loaded}"
+	}
+	return fmt.Sprintf("config.ConfigManager{environment: %s, keys: %s}", m.configEnvironment(), keyList(m.config))
+}
+
+// GoString keeps %#v from printing the manager's fields.
+func (m *ConfigManager) GoString() string { return m.String() }
+
+// String implements fmt.Stringer without printing any value.
+func (m *LocalConfigManager) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.initialized {
+		return "config.LocalConfigManager{not loaded}"
+	}
+	keys := make(map[string]any, len(m.fileConfig)+len(m.envConfig))
+	for k := range m.fileConfig {
+		keys[k] = nil
+	}
+	for k := range m.envConfig {
+		keys[k] = nil
+	}
+	return fmt.Sprintf("config.LocalConfigManager{keys: %s}", keyList(keys))
+}
+
+// GoString keeps %#v from printing the manager's fields.
+func (m *LocalConfigManager) GoString() string { return m.String() }
+
+// String implements fmt.Stringer without printing the credentials or cached
+// values.
+func (c *ConfigClient) String() string {
+	return fmt.Sprintf("config.ConfigClient{baseURL: %s, org: %s, environment: %s}", c.baseURL, c.orgID, c.defaultEnvironment)
+}
+
+// GoString keeps %#v from printing the client's fields.
+func (c *ConfigClient) GoString() string { return c.String() }
+
+// keyList formats the keys of values, sorted, as [A B C].
+func keyList(values map[string]any) string {
+	return "[" + strings.Join(sortedKeys(values), " ") + "]"
+}
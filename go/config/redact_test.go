@@ -0,0 +1,68 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskSecret(t *testing.T) {
+	assert.Equal(t, "sk-****", MaskSecret("sk-live-9f8e7d6c"))
+	assert.Equal(t, "****", MaskSecret("hunter2"))
+	assert.Equal(t, "****", MaskSecret(42))
+	assert.Equal(t, "****", MaskSecret(map[string]any{"password": "x"}))
+	assert.Equal(t, "pås****", MaskSecret("påssword-long"))
+}
+
+func TestConfigManager_Redacted(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+		"API_URL":       "https://api.example.com",
+		"STRIPE_KEY":    "sk-live-9f8e7d6c5b4a",
+		"ENABLE_NEW_UI": true,
+		"UNDECLARED":    "maybe-a-secret-value",
+	}})
+	mgr := NewConfigManager(
+		WithCMKeyTiers(map[string]ConfigTier{"API_URL": TierPublic, "STRIPE_KEY": TierSecret, "ENABLE_NEW_UI": TierFeatureFlag}),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}),
+	)
+	defer mgr.Close(context.Background())
+
+	values, err := mgr.Redacted()
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com", values["API_URL"])
+	assert.Equal(t, true, values["ENABLE_NEW_UI"])
+	assert.Equal(t, "sk-****", values["STRIPE_KEY"])
+	assert.Equal(t, "may****", values["UNDECLARED"])
+
+	dump, err := mgr.SafeDump()
+	require.NoError(t, err)
+	assert.Contains(t, dump, `"STRIPE_KEY": "sk-****"`)
+	assert.NotContains(t, dump, "9f8e7d6c")
+}
+
+func TestStringers_DoNotPrintValues(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"DB_PASSWORD": "hunter2"}})
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}))
+	defer mgr.Close(context.Background())
+	assert.Equal(t, "config.ConfigManager{not loaded}", fmt.Sprint(mgr))
+
+	_, err := mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		out := fmt.Sprintf(format, mgr)
+		assert.Contains(t, out, "DB_PASSWORD", format)
+		assert.NotContains(t, out, "hunter2", format)
+	}
+
+	local := NewLocalConfigManager(WithEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}))
+	_, err = local.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Contains(t, fmt.Sprintf("%#v", local), "DB_PASSWORD")
+	assert.NotContains(t, fmt.Sprintf("%#v", local), "hunter2")
+
+	client := NewConfigClient("https://config.example.com", "client-id", "client-secret", testOrgID)
+	assert.NotContains(t, fmt.Sprintf("%+v %#v", client, client), "client-secret")
+}
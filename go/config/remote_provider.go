@@ -0,0 +1,320 @@
+package config
+
+// Secret references resolved through a RemoteProvider. A string value of the
+// form smooai-secret://<ref> is replaced, when the config loads, with the
+// payload returned by the first provider that handles ref. A #field suffix
+// picks one field of a JSON payload:
+//
+//	"DB_PASSWORD": "smooai-secret://arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf#password"
+//
+// The manager has a built-in provider for the cloud it detects from
+// CLOUD_PROVIDER: GCPSecretManager on gcp, AzureKeyVault on azure and
+// AWSSecretsManager otherwise. WithCMRemoteProvider adds others ahead of it.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretRefPrefix marks a string value as a reference to a secret held by a
+// RemoteProvider.
+const SecretRefPrefix = "smooai-secret://"
+
+// Defaults for secret reference resolution.
+const (
+	defaultSecretRefTTL     = 5 * time.Minute
+	defaultSecretRefTimeout = 10 * time.Second
+)
+
+// secretRefBackoff bounds how long a secret that failed to fetch is left
+// alone before the next fetch; reloads and reads in between get the same
+// error without asking the provider.
+var secretRefBackoff = struct{ initial, max time.Duration }{5 * time.Second, 5 * time.Minute}
+
+// ErrSecretRef matches any *SecretRefError via errors.Is.
+var ErrSecretRef = errors.New("config secret reference cannot be resolved")
+
+// SecretRefError reports a smooai-secret:// value that could not be
+// resolved: no provider handles it, the provider failed with nothing cached,
+// or the #field is missing from the payload. The rest of the config still
+// loads; reads of Key fail with this error until the reference resolves.
+type SecretRefError struct {
+	// Key holds the value the reference appears in.
+	Key string
+	// Ref is the reference without the smooai-secret:// prefix.
+	Ref string
+	Err error
+}
+
+func (e *SecretRefError) Error() string {
+	return fmt.Sprintf("[Smooai Config] Cannot resolve secret reference %s in %s: %v", e.Ref, e.Key, e.Err)
+}
+
+// Is supports errors.Is(err, ErrSecretRef).
+func (e *SecretRefError) Is(target error) bool { return target == ErrSecretRef }
+
+func (e *SecretRefError) Unwrap() error { return e.Err }
+
+// RemoteProvider resolves secret references against an external secret
+// store, so hybrid setups can keep real secrets there while the rest of the
+// config comes from the SmooAI API.
+type RemoteProvider interface {
+	// Handles reports whether the provider resolves ref, the part of the
+	// value after smooai-secret:// with any #field removed.
+	Handles(ref string) bool
+	// Resolve returns the secret payload ref points at.
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// WithCMRemoteProvider adds a provider for smooai-secret:// values. Providers
+// are asked in the order added, before the built-in one for the detected
+// cloud. Add NewAWSSecretsManager, NewGCPSecretManager or NewAzureKeyVault
+// here to resolve another cloud's references.
+func WithCMRemoteProvider(p RemoteProvider) ConfigManagerOption {
+	return func(m *ConfigManager) { m.remoteProviders = append(m.remoteProviders, p) }
+}
+
+// WithCMSecretRefTTL sets how long a resolved secret payload is reused
+// across reloads (default 5m). A reload after it expires fetches the secret
+// again; if that fails, the expired payload keeps being served with a
+// warning.
+func WithCMSecretRefTTL(ttl time.Duration) ConfigManagerOption {
+	return func(m *ConfigManager) { m.secretRefTTL = ttl }
+}
+
+// builtinRemoteProvider returns the provider for cloud, as detected by
+// GetCloudRegionFromEnv. AWS is the fallback, since Secrets Manager ARNs
+// were resolved everywhere before the other clouds had providers.
+func builtinRemoteProvider(cloud string, lookupEnv EnvFunc) RemoteProvider {
+	switch cloud {
+	case "gcp":
+		return NewGCPSecretManager(WithGCPEnv(lookupEnv))
+	case "azure":
+		return NewAzureKeyVault(WithAzureEnv(lookupEnv))
+	default:
+		return NewAWSSecretsManager(WithAWSEnv(lookupEnv))
+	}
+}
+
+// secretResolver resolves smooai-secret:// values, caching payloads by ref.
+type secretResolver struct {
+	providers []RemoteProvider
+	ttl       time.Duration
+	timeout   time.Duration
+	now       func() time.Time
+	log       *slog.Logger
+
+	mu     sync.Mutex
+	cache  map[string]cachedSecret
+	failed map[string]failedSecret
+}
+
+type cachedSecret struct {
+	payload string
+	fetched time.Time
+}
+
+// failedSecret is a fetch that failed with nothing cached, retried after
+// retryAt.
+type failedSecret struct {
+	err     error
+	retryAt time.Time
+	backoff time.Duration
+}
+
+func newSecretResolver(providers []RemoteProvider, ttl time.Duration, log *slog.Logger) *secretResolver {
+	if ttl <= 0 {
+		ttl = defaultSecretRefTTL
+	}
+	return &secretResolver{
+		providers: providers,
+		ttl:       ttl,
+		timeout:   defaultSecretRefTimeout,
+		now:       time.Now,
+		log:       log,
+		cache:     map[string]cachedSecret{},
+		failed:    map[string]failedSecret{},
+	}
+}
+
+// resolveAll replaces every secret reference in config, including strings
+// nested in objects and arrays. A key with a reference that fails is
+// removed from config, and returned with its *SecretRefError; the other
+// keys resolve regardless.
+func (r *secretResolver) resolveAll(config map[string]any) map[string]error {
+	keys := make([]string, 0, len(config))
+	for key, value := range config {
+		if hasSecretRef(value) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	var errs map[string]error
+	for _, key := range keys {
+		v, err := r.resolveValue(ctx, key, config[key])
+		if err != nil {
+			if errs == nil {
+				errs = make(map[string]error)
+			}
+			errs[key] = err
+			delete(config, key)
+			continue
+		}
+		config[key] = v
+	}
+	return errs
+}
+
+// resolveValue resolves the references in v, which belongs to key.
+func (r *secretResolver) resolveValue(ctx context.Context, key string, v any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		if !strings.HasPrefix(v, SecretRefPrefix) {
+			return v, nil
+		}
+		return r.resolveRef(ctx, key, strings.TrimPrefix(v, SecretRefPrefix))
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			resolved, err := r.resolveValue(ctx, key, item)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			resolved, err := r.resolveValue(ctx, key, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// resolveRef resolves one reference: the whole payload, or with a #field
+// suffix that field of the JSON payload.
+func (r *secretResolver) resolveRef(ctx context.Context, key, ref string) (any, error) {
+	id, field, hasField := strings.Cut(ref, "#")
+	payload, err := r.payload(ctx, id)
+	if err != nil {
+		return nil, &SecretRefError{Key: key, Ref: ref, Err: err}
+	}
+	if !hasField {
+		return payload, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+		return nil, &SecretRefError{Key: key, Ref: ref, Err: errors.New("the secret is not a JSON object")}
+	}
+	v, ok := fields[field]
+	if !ok {
+		return nil, &SecretRefError{Key: key, Ref: ref, Err: fmt.Errorf("field %q is not in the secret", field)}
+	}
+	return v, nil
+}
+
+// payload returns the secret for id, from the cache while fresh. After a
+// fetch fails with nothing cached, it returns that error without fetching
+// again until the backoff (secretRefBackoff) runs out.
+func (r *secretResolver) payload(ctx context.Context, id string) (string, error) {
+	r.mu.Lock()
+	cached, ok := r.cache[id]
+	failed, hasFailed := r.failed[id]
+	r.mu.Unlock()
+	if ok && r.now().Sub(cached.fetched) < r.ttl {
+		return cached.payload, nil
+	}
+	if !ok && hasFailed && r.now().Before(failed.retryAt) {
+		return "", failed.err
+	}
+
+	provider := r.provider(id)
+	if provider == nil {
+		return "", errors.New("no remote provider handles it")
+	}
+	payload, err := provider.Resolve(ctx, id)
+	if err != nil {
+		if ok {
+			r.log.Warn("failed to refresh secret, serving the cached value", "secret", id, "error", err)
+			return cached.payload, nil
+		}
+		backoff := secretRefBackoff.initial
+		if hasFailed {
+			backoff = min(failed.backoff*2, secretRefBackoff.max)
+		}
+		r.mu.Lock()
+		r.failed[id] = failedSecret{err: err, retryAt: r.now().Add(backoff), backoff: backoff}
+		r.mu.Unlock()
+		return "", err
+	}
+	r.mu.Lock()
+	r.cache[id] = cachedSecret{payload: payload, fetched: r.now()}
+	delete(r.failed, id)
+	r.mu.Unlock()
+	return payload, nil
+}
+
+func (r *secretResolver) provider(id string) RemoteProvider {
+	for _, p := range r.providers {
+		if p.Handles(id) {
+			return p
+		}
+	}
+	return nil
+}
+
+// secretRefErrLocked returns the error that kept key's secret reference
+// from resolving, or nil. It first tries the reference again; the
+// resolver's backoff keeps that from reaching the provider on every read.
+// Managers with deferred or interpolated values retry on the next reload
+// instead, since those may depend on key.
+func (m *ConfigManager) secretRefErrLocked(key string) error {
+	if _, ok := m.secretRefErrs[key]; !ok {
+		return nil
+	}
+	if !m.dependentValuesLocked() {
+		m.remergeKeyLocked(key)
+	}
+	return m.secretRefErrs[key]
+}
+
+// hasSecretRef reports whether v holds a smooai-secret:// string.
+func hasSecretRef(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return strings.HasPrefix(v, SecretRefPrefix)
+	case map[string]any:
+		for _, item := range v {
+			if hasSecretRef(item) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if hasSecretRef(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
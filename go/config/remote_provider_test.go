@@ -0,0 +1,180 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vaultProvider is a RemoteProvider for "vault:" refs backed by a map.
+type vaultProvider struct {
+	secrets map[string]string
+	err     error
+	calls   int
+}
+
+func (p *vaultProvider) Handles(ref string) bool { return strings.HasPrefix(ref, "vault:") }
+
+func (p *vaultProvider) Resolve(_ context.Context, ref string) (string, error) {
+	p.calls++
+	if p.err != nil {
+		return "", p.err
+	}
+	v, ok := p.secrets[ref]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func TestConfigManager_ResolvesSecretRefs(t *testing.T) {
+	vault := &vaultProvider{secrets: map[string]string{
+		"vault:db":    `{"user":"app","password":"hunter2","port":5432}`,
+		"vault:token": "tok-123",
+	}}
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+		"DB_PASSWORD": "smooai-secret://vault:db#password",
+		"DB_PORT":     "smooai-secret://vault:db#port",
+		"API_TOKEN":   "smooai-secret://vault:token",
+		"NESTED":      map[string]any{"users": []any{"smooai-secret://vault:db#user"}},
+		"PLAIN":       "value",
+	}})
+	mgr := NewConfigManager(WithCMRemoteProvider(vault), WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}))
+
+	v, err := mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+	v, _ = mgr.GetSecretConfig("DB_PORT")
+	assert.Equal(t, 5432.0, v, "a JSON field keeps its type")
+	v, _ = mgr.GetSecretConfig("API_TOKEN")
+	assert.Equal(t, "tok-123", v)
+	v, _ = mgr.GetPublicConfig("NESTED")
+	assert.Equal(t, map[string]any{"users": []any{"app"}}, v)
+	v, _ = mgr.GetPublicConfig("PLAIN")
+	assert.Equal(t, "value", v)
+	assert.Equal(t, 2, vault.calls, "one fetch per secret, however many fields are read")
+}
+
+func TestConfigManager_SecretRefCacheAndStaleFallback(t *testing.T) {
+	vault := &vaultProvider{secrets: map[string]string{"vault:token": "v1"}}
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_TOKEN": "smooai-secret://vault:token"}})
+	mgr := NewConfigManager(
+		WithCMRemoteProvider(vault),
+		WithCMSecretRefTTL(time.Minute),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}),
+	)
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	mgr.secrets.now = func() time.Time { return clock }
+
+	v, _ := mgr.GetSecretConfig("API_TOKEN")
+	assert.Equal(t, "v1", v)
+
+	vault.secrets["vault:token"] = "v2"
+	mgr.Invalidate()
+	v, _ = mgr.GetSecretConfig("API_TOKEN")
+	assert.Equal(t, "v1", v, "reused within the TTL")
+	assert.Equal(t, 1, vault.calls)
+
+	clock = clock.Add(time.Minute)
+	mgr.Invalidate()
+	v, _ = mgr.GetSecretConfig("API_TOKEN")
+	assert.Equal(t, "v2", v, "fetched again once expired")
+
+	vault.err = errors.New("vault sealed")
+	clock = clock.Add(time.Minute)
+	mgr.Invalidate()
+	v, err := mgr.GetSecretConfig("API_TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", v, "the expired payload is served when a refresh fails")
+}
+
+func TestConfigManager_SecretRefErrors(t *testing.T) {
+	vault := &vaultProvider{secrets: map[string]string{"vault:plain": "not json", "vault:db": `{"user":"app"}`}}
+	tests := []struct {
+		name string
+		ref  string
+		msg  string
+	}{
+		{"no provider", "smooai-secret://gcp:projects/p/secrets/s", "no remote provider handles it"},
+		{"provider fails", "smooai-secret://vault:missing", "not found"},
+		{"not json", "smooai-secret://vault:plain#field", "the secret is not a JSON object"},
+		{"missing field", "smooai-secret://vault:db#nope", `field "nope" is not in the secret`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"SECRET": tc.ref, "PLAIN": "x"}})
+			mgr := NewConfigManager(WithCMRemoteProvider(vault), WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}))
+			v, err := mgr.GetPublicConfig("PLAIN")
+			require.NoError(t, err, "other keys still load")
+			assert.Equal(t, "x", v)
+
+			_, err = mgr.GetSecretConfig("SECRET")
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrSecretRef)
+			var refErr *SecretRefError
+			require.True(t, errors.As(err, &refErr))
+			assert.Equal(t, "SECRET", refErr.Key)
+			assert.Contains(t, err.Error(), tc.msg)
+		})
+	}
+}
+
+func TestConfigManager_SecretRefFailureBacksOff(t *testing.T) {
+	vault := &vaultProvider{err: errors.New("vault sealed")}
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_TOKEN": "smooai-secret://vault:token", "PLAIN": "x"}})
+	mgr := NewConfigManager(WithCMRemoteProvider(vault), WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}))
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	mgr.secrets.now = func() time.Time { return clock }
+
+	err := mgr.Init(context.Background())
+	var initErr *InitError
+	require.True(t, errors.As(err, &initErr))
+	assert.ErrorIs(t, initErr.Failed["secrets"], ErrSecretRef)
+
+	for range 3 {
+		_, err := mgr.GetSecretConfig("API_TOKEN")
+		assert.ErrorContains(t, err, "vault sealed")
+		v, err := mgr.GetPublicConfig("PLAIN")
+		require.NoError(t, err)
+		assert.Equal(t, "x", v)
+	}
+	mgr.Invalidate()
+	_, err = mgr.GetSecretConfig("API_TOKEN")
+	assert.ErrorIs(t, err, ErrSecretRef)
+	assert.Equal(t, 1, vault.calls, "reads and reloads within the backoff don't refetch")
+
+	vault.err = nil
+	vault.secrets = map[string]string{"vault:token": "tok"}
+	clock = clock.Add(secretRefBackoff.initial)
+	v, err := mgr.GetSecretConfig("API_TOKEN")
+	require.NoError(t, err, "a read after the backoff retries")
+	assert.Equal(t, "tok", v)
+	assert.Equal(t, 2, vault.calls)
+}
+
+func TestConfigManager_ResolvesAWSSecretRefs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Authorization"), "Credential=AKID/")
+		json.NewEncoder(w).Encode(map[string]any{"SecretString": `{"password":"from-aws"}`})
+	}))
+	defer server.Close()
+
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"DB_PASSWORD": "smooai-secret://" + testSecretARN + "#password"}})
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR":            dir,
+		"AWS_ACCESS_KEY_ID":                "AKID",
+		"AWS_SECRET_ACCESS_KEY":            "secret",
+		"AWS_ENDPOINT_URL_SECRETS_MANAGER": server.URL,
+	}))
+	v, err := mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "from-aws", v)
+}
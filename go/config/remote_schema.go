@@ -0,0 +1,110 @@
+package config
+
+// Schema fetching. GetSchema pulls the schema published for an environment
+// (see PublishSchema), so a service can coerce env vars, enforce tiers and
+// validate values without compiling the schema into its binary.
+//
+// Server contract:
+//
+//	GET {BaseURL}/organizations/{orgId}/config/schema?environment=...
+//	{"id": "...", "name": "...", "currentVersion": 3, "jsonSchema": {...}}
+//
+// 404 means no schema has been published for the environment.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// remoteSchema is a schema as listed or returned by the server.
+type remoteSchema struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	CurrentVersion int            `json:"currentVersion"`
+	JSONSchema     map[string]any `json:"jsonSchema"`
+}
+
+// GetSchema fetches the schema published for environment and returns it as
+// a ConfigDefinition. Pass an empty environment to use the client's default.
+// A missing schema is an *HTTPError matching ErrNotFound.
+func (c *ConfigClient) GetSchema(environment string) (*ConfigDefinition, error) {
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/config/schema?%s", c.orgURL(), url.Values{"environment": {env}}.Encode())
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("config get schema: %w", err)
+	}
+
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("config get schema: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPError("get schema", resp)
+	}
+
+	var schema remoteSchema
+	if err := json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+		return nil, fmt.Errorf("config get schema decode: %w", err)
+	}
+	return definitionFromJSONSchema(schema.JSONSchema)
+}
+
+// definitionFromJSONSchema splits the combined JSON Schema DefineConfig
+// produces back into its tiers.
+func definitionFromJSONSchema(schema map[string]any) (*ConfigDefinition, error) {
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return nil, NewConfigError("schema has no tier properties (public, secret, feature_flags)")
+	}
+	tier := func(name string) map[string]any {
+		s, _ := props[name].(map[string]any)
+		return s
+	}
+	return DefineConfig(tier("public"), tier("secret"), tier("feature_flags")), nil
+}
+
+// WithCMRemoteSchema fetches the environment's published schema (GetSchema)
+// on the first load that reaches the API, and uses it as if it had been
+// compiled in with WithCMDefinition: its keys are read from env vars and
+// coerced by type, keys are refused through the wrong tier, and values are
+// validated. Explicitly configured schema keys, types, tiers and
+// definitions win over the fetched schema. If the fetch
+// fails the manager warns and loads without it, retrying on the next load.
+func WithCMRemoteSchema() ConfigManagerOption {
+	return func(m *ConfigManager) { m.remoteSchema = true }
+}
+
+// loadRemoteSchemaLocked fetches the published schema once, replacing a
+// schema bundle. Must be called under m.mu.
+func (m *ConfigManager) loadRemoteSchemaLocked(client *ConfigClient, configEnv string) {
+	if !m.remoteSchema || (m.remoteDefinition != nil && !m.schemaBundle) {
+		return
+	}
+	def, err := client.GetSchema(configEnv)
+	if err != nil {
+		m.log().Warn("failed to fetch remote schema", "error", err)
+		return
+	}
+	m.remoteDefinition = def
+	m.remoteDefinitionTiers = definitionTiers(def)
+	m.schemaBundle = false
+}
+
+// envSchemaLocked returns the env-var keys and coercion types: the
+// configured ones plus those derived from the remote schema, if loaded.
+func (m *ConfigManager) envSchemaLocked() (map[string]bool, map[string]string) {
+	if m.remoteDefinition == nil {
+		return m.schemaKeys, m.schemaTypes
+	}
+	return mergeEnvSchema(m.remoteDefinition, m.schemaKeys, m.schemaTypes)
+}
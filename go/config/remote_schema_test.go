@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func remoteSchemaJSON() map[string]any {
+	return DefineConfig(
+		map[string]any{"type": "object", "properties": map[string]any{
+			"MAX_RETRIES": map[string]any{"type": "integer", "minimum": 0},
+		}},
+		map[string]any{"type": "object", "properties": map[string]any{
+			"DB_PASSWORD": map[string]any{"type": "string"},
+		}},
+		nil,
+	).JSONSchema
+}
+
+func TestGetSchema(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/organizations/"+testOrgID+"/config/schema", r.URL.Path)
+		assert.Equal(t, "production", r.URL.Query().Get("environment"))
+		json.NewEncoder(w).Encode(map[string]any{"id": "s-1", "name": "orders", "currentVersion": 2, "jsonSchema": remoteSchemaJSON()})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	def, err := client.GetSchema("production")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MAX_RETRIES"}, def.Keys(TierPublic))
+	assert.Equal(t, []string{"DB_PASSWORD"}, def.Keys(TierSecret))
+	assert.Empty(t, def.Keys(TierFeatureFlag))
+}
+
+func TestGetSchema_Errors(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("environment") == "staging" {
+			json.NewEncoder(w).Encode(map[string]any{"jsonSchema": map[string]any{"type": "object"}})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.GetSchema("production")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = client.GetSchema("staging")
+	assert.ErrorContains(t, err, "no tier properties")
+}
+
+// newRemoteSchemaServer serves values and a published schema behind the
+// mock OAuth endpoint; schemaStatus != 200 fails the schema fetch.
+func newRemoteSchemaServer(t *testing.T, values map[string]any, schemaStatus int, schemaFetches *atomic.Int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": mockJWT, "expires_in": 3600, "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/schema", func(w http.ResponseWriter, r *http.Request) {
+		schemaFetches.Add(1)
+		if schemaStatus != http.StatusOK {
+			w.WriteHeader(schemaStatus)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"jsonSchema": remoteSchemaJSON()})
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"values": values})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func remoteSchemaManager(t *testing.T, server *httptest.Server, env map[string]string) *ConfigManager {
+	t.Helper()
+	override := map[string]string{
+		"SMOOAI_CONFIG_AUTH_URL": server.URL,
+		"SMOOAI_ENV_CONFIG_DIR":  makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+	}
+	for k, v := range env {
+		override[k] = v
+	}
+	return NewConfigManager(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMRemoteSchema(),
+		WithCMEnvOverride(override),
+	)
+}
+
+func TestConfigManager_WithCMRemoteSchema(t *testing.T) {
+	var fetches atomic.Int32
+	server := newRemoteSchemaServer(t, map[string]any{"DB_PASSWORD": "s3cret"}, http.StatusOK, &fetches)
+	mgr := remoteSchemaManager(t, server, map[string]string{"MAX_RETRIES": "3"})
+
+	// The env var is coerced by the fetched schema's integer type.
+	v, err := mgr.GetPublicConfig("MAX_RETRIES")
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+
+	// And the secret is refused through the public tier.
+	_, err = mgr.GetPublicConfig("DB_PASSWORD")
+	assert.ErrorIs(t, err, ErrWrongTier)
+
+	mgr.Invalidate()
+	_, err = mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load(), "schema is fetched once")
+}
+
+func TestConfigManager_WithCMRemoteSchema_Validates(t *testing.T) {
+	var fetches atomic.Int32
+	server := newRemoteSchemaServer(t, map[string]any{}, http.StatusOK, &fetches)
+	mgr := remoteSchemaManager(t, server, map[string]string{"MAX_RETRIES": "-1"})
+
+	_, err := mgr.GetPublicConfig("MAX_RETRIES")
+	assert.ErrorIs(t, err, ErrSchemaViolation)
+}
+
+func TestConfigManager_WithCMRemoteSchema_FetchFailureIsNotFatal(t *testing.T) {
+	var fetches atomic.Int32
+	server := newRemoteSchemaServer(t, map[string]any{"DB_PASSWORD": "s3cret"}, http.StatusNotFound, &fetches)
+	mgr := remoteSchemaManager(t, server, map[string]string{"MAX_RETRIES": "3"})
+
+	v, err := mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", v)
+	v, err = mgr.GetPublicConfig("MAX_RETRIES")
+	require.NoError(t, err)
+	assert.Nil(t, v, "without a schema the env var isn't a known key")
+
+	mgr.Invalidate()
+	_, err = mgr.GetPublicConfig("MAX_RETRIES")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load(), "a failed fetch is retried on the next load")
+}
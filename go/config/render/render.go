@@ -0,0 +1,324 @@
+// Package render writes merged config to files, for init containers that
+// prepare config so the main container can start without the SDK:
+//
+//	smooai-config render --schema schema.bundle.json --env-file /config/env \
+//	    --files-dir /config/keys --keys API_URL,DB_PASSWORD
+//
+// Render loads the config the way a ConfigManager does (files, remote API,
+// env vars), validates it against the schema when one is given, and writes
+// the selected keys. Every failure has its own exit code, and nothing is
+// written unless every key resolves, so a failed init container leaves no
+// partial config behind.
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	config "github.com/SmooAI/config/go/config"
+)
+
+// Exit codes returned by Main.
+const (
+	ExitOK      = 0
+	ExitUsage   = 2 // bad flags or options
+	ExitLoad    = 3 // the config failed to load
+	ExitTimeout = 4 // loading took longer than the timeout
+	ExitInvalid = 5 // schema violations, or selected keys that aren't set
+	ExitWrite   = 6 // an output couldn't be written
+)
+
+// DefaultTimeout bounds loading when Options.Timeout is zero.
+const DefaultTimeout = 30 * time.Second
+
+// Options selects what Render loads and where it writes.
+type Options struct {
+	// Keys are the keys to write, each of which must be set. Empty means
+	// every key in Schema that is set, under its UPPER_SNAKE_CASE name.
+	Keys []string
+	// Schema, when set, validates the merged config and declares key tiers
+	// and env var types, as config.WithCMSchemaBundle does.
+	Schema *config.ConfigDefinition
+	// Environment is the config environment; empty means the one detected
+	// from the environment (SMOOAI_CONFIG_ENV, ...).
+	Environment string
+	// EnvFile, when set, receives a shell snippet of export KEY='value'
+	// lines. "-" writes it to the Stdout passed to Main.
+	EnvFile string
+	// JSONFile, when set, receives the keys as one JSON object.
+	JSONFile string
+	// FilesDir, when set, receives one file per key, named after the key.
+	FilesDir string
+	// Timeout bounds loading. Defaults to DefaultTimeout.
+	Timeout time.Duration
+	// ManagerOptions are appended to the ConfigManager's options.
+	ManagerOptions []config.ConfigManagerOption
+}
+
+// Error is a Render failure with its exit code.
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Render loads the config and writes the selected keys. stdout receives an
+// EnvFile of "-". Errors are *Error.
+func Render(ctx context.Context, opts Options, stdout io.Writer) error {
+	if opts.EnvFile == "" && opts.JSONFile == "" && opts.FilesDir == "" {
+		return &Error{ExitUsage, errors.New("nothing to write: set an env file, JSON file or files directory")}
+	}
+	keys, required := opts.Keys, true
+	if len(keys) == 0 && opts.Schema != nil {
+		keys, required = schemaKeys(opts.Schema), false
+	}
+	if len(keys) == 0 {
+		return &Error{ExitUsage, errors.New("no keys selected: pass keys or a schema")}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	var mopts []config.ConfigManagerOption
+	if opts.Environment != "" {
+		mopts = append(mopts, config.WithConfigEnvironment(opts.Environment))
+	}
+	if opts.Schema != nil {
+		mopts = append(mopts, config.WithCMDefinition(opts.Schema))
+	}
+	mgr := config.NewConfigManager(append(mopts, opts.ManagerOptions...)...)
+	defer mgr.Close(context.Background())
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	type result struct {
+		values map[string]any
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		values, err := readKeys(mgr, keys, required)
+		done <- result{values, err}
+	}()
+	var values map[string]any
+	select {
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return &Error{ExitTimeout, fmt.Errorf("config did not load within %s", timeout)}
+		}
+		return &Error{ExitLoad, ctx.Err()}
+	case r := <-done:
+		if r.err != nil {
+			return r.err
+		}
+		values = r.values
+	}
+
+	if opts.EnvFile != "" {
+		if err := writeOutput(opts.EnvFile, stdout, envSnippet(keys, values)); err != nil {
+			return &Error{ExitWrite, err}
+		}
+	}
+	if opts.JSONFile != "" {
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return &Error{ExitWrite, err}
+		}
+		if err := writeOutput(opts.JSONFile, stdout, append(data, '\n')); err != nil {
+			return &Error{ExitWrite, err}
+		}
+	}
+	if opts.FilesDir != "" {
+		if err := os.MkdirAll(opts.FilesDir, 0o755); err != nil {
+			return &Error{ExitWrite, err}
+		}
+		for _, key := range keys {
+			if _, ok := values[key]; !ok {
+				continue
+			}
+			if err := writeFileAtomic(filepath.Join(opts.FilesDir, key), []byte(formatValue(values[key]))); err != nil {
+				return &Error{ExitWrite, err}
+			}
+		}
+	}
+	return nil
+}
+
+// schemaKeys lists def's keys by their config key names.
+func schemaKeys(def *config.ConfigDefinition) []string {
+	var keys []string
+	for _, tier := range []config.ConfigTier{config.TierPublic, config.TierSecret, config.TierFeatureFlag} {
+		for _, name := range def.Keys(tier) {
+			if strings.Contains(name, "_") {
+				keys = append(keys, strings.ToUpper(name))
+			} else {
+				keys = append(keys, config.CamelToUpperSnake(name))
+			}
+		}
+	}
+	return keys
+}
+
+// readKeys reads each key through the getter for its tier, leaving out
+// unset keys. When required, it instead fails with every unset key at once,
+// so one run shows everything that's missing.
+func readKeys(mgr *config.ConfigManager, keys []string, required bool) (map[string]any, error) {
+	values := make(map[string]any, len(keys))
+	var missing []string
+	for _, key := range keys {
+		v, err := mgr.GetPublicConfig(key)
+		var wrongTier *config.WrongTierError
+		if errors.As(err, &wrongTier) {
+			switch wrongTier.Actual {
+			case config.TierSecret:
+				v, err = mgr.GetSecretConfig(key)
+			case config.TierFeatureFlag:
+				v, err = mgr.GetFeatureFlag(key)
+			}
+		}
+		switch {
+		case errors.Is(err, config.ErrSchemaViolation):
+			return nil, &Error{ExitInvalid, err}
+		case err != nil:
+			return nil, &Error{ExitLoad, err}
+		case v == nil:
+			missing = append(missing, key)
+		default:
+			values[key] = v
+		}
+	}
+	if len(missing) > 0 && required {
+		return nil, &Error{ExitInvalid, fmt.Errorf("config keys are not set: %s", strings.Join(missing, ", "))}
+	}
+	return values, nil
+}
+
+// envSnippet formats values as export lines for a POSIX shell, in key order.
+func envSnippet(keys []string, values map[string]any) []byte {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	var b strings.Builder
+	for _, key := range sorted {
+		if _, ok := values[key]; !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "export %s='%s'\n", key, strings.ReplaceAll(formatValue(values[key]), "'", `'\''`))
+	}
+	return []byte(b.String())
+}
+
+// formatValue renders strings as they are and everything else as JSON.
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func writeOutput(path string, stdout io.Writer, data []byte) error {
+	if path == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames it
+// into place, so readers never see a partly written file. Files are only
+// readable by their owner, since they may hold secrets.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Main runs the render command with args (without the command name) and
+// returns its exit code. Errors go to stderr.
+func Main(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		opts       Options
+		keys       string
+		schemaPath string
+	)
+	fs.StringVar(&keys, "keys", "", "comma-separated keys to write (default: every key in --schema)")
+	fs.StringVar(&schemaPath, "schema", "", "schema bundle to validate against (see config.WriteSchemaBundle)")
+	fs.StringVar(&opts.Environment, "environment", "", "config environment (default: detected)")
+	fs.StringVar(&opts.EnvFile, "env-file", "", "write export KEY='value' lines here (- for stdout)")
+	fs.StringVar(&opts.JSONFile, "json-file", "", "write the keys here as a JSON object (- for stdout)")
+	fs.StringVar(&opts.FilesDir, "files-dir", "", "write one file per key into this directory")
+	fs.DurationVar(&opts.Timeout, "timeout", DefaultTimeout, "give up loading after this long")
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "render: unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return ExitUsage
+	}
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			opts.Keys = append(opts.Keys, key)
+		}
+	}
+	if schemaPath != "" {
+		f, err := os.Open(schemaPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "render: %v\n", err)
+			return ExitUsage
+		}
+		opts.Schema, err = config.ReadSchemaBundle(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(stderr, "render: %v\n", err)
+			return ExitUsage
+		}
+	}
+
+	if err := Render(ctx, opts, stdout); err != nil {
+		fmt.Fprintf(stderr, "render: %v\n", err)
+		var rerr *Error
+		if errors.As(err, &rerr) {
+			return rerr.Code
+		}
+		return ExitLoad
+	}
+	return ExitOK
+}
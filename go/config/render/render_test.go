@@ -0,0 +1,127 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	config "github.com/SmooAI/config/go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testManagerOptions() []config.ConfigManagerOption {
+	return []config.ConfigManagerOption{
+		config.WithCMConfigFiles(map[string]string{
+			"default.json": `{"API_URL": "https://api.example.com", "MAX_RETRIES": 3, "DB_PASSWORD": "it's secret"}`,
+		}),
+		config.WithCMEnvOverride(map[string]string{"SMOOAI_CONFIG_ENV": "test"}),
+		config.WithCMKeyTiers(map[string]config.ConfigTier{"DB_PASSWORD": config.TierSecret}),
+	}
+}
+
+func TestRender_WritesSelectedKeys(t *testing.T) {
+	dir := t.TempDir()
+	var stdout bytes.Buffer
+	err := Render(context.Background(), Options{
+		Keys:           []string{"MAX_RETRIES", "DB_PASSWORD"},
+		EnvFile:        "-",
+		JSONFile:       filepath.Join(dir, "config.json"),
+		FilesDir:       filepath.Join(dir, "keys"),
+		ManagerOptions: testManagerOptions(),
+	}, &stdout)
+	require.NoError(t, err)
+
+	assert.Equal(t, "export DB_PASSWORD='it'\\''s secret'\nexport MAX_RETRIES='3'\n", stdout.String())
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	require.NoError(t, err)
+	var values map[string]any
+	require.NoError(t, json.Unmarshal(data, &values))
+	assert.Equal(t, map[string]any{"MAX_RETRIES": 3.0, "DB_PASSWORD": "it's secret"}, values)
+
+	data, err = os.ReadFile(filepath.Join(dir, "keys", "DB_PASSWORD"))
+	require.NoError(t, err)
+	assert.Equal(t, "it's secret", string(data))
+	entries, err := os.ReadDir(filepath.Join(dir, "keys"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestRender_SchemaKeys(t *testing.T) {
+	def := config.DefineConfig(
+		map[string]any{"type": "object", "properties": map[string]any{
+			"apiUrl":     map[string]any{"type": "string"},
+			"maxRetries": map[string]any{"type": "integer"},
+			"unsetKey":   map[string]any{"type": "string"},
+		}},
+		nil, nil,
+	)
+	var stdout bytes.Buffer
+	err := Render(context.Background(), Options{Schema: def, EnvFile: "-", ManagerOptions: testManagerOptions()}, &stdout)
+	require.NoError(t, err)
+	assert.Equal(t, "export API_URL='https://api.example.com'\nexport MAX_RETRIES='3'\n", stdout.String())
+}
+
+func TestRender_ExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	render := func(opts Options) int {
+		opts.ManagerOptions = append(testManagerOptions(), opts.ManagerOptions...)
+		err := Render(context.Background(), opts, &bytes.Buffer{})
+		if err == nil {
+			return ExitOK
+		}
+		var rerr *Error
+		require.ErrorAs(t, err, &rerr)
+		return rerr.Code
+	}
+
+	assert.Equal(t, ExitUsage, render(Options{Keys: []string{"API_URL"}}))
+	assert.Equal(t, ExitUsage, render(Options{EnvFile: "-"}))
+
+	out := filepath.Join(dir, "env")
+	assert.Equal(t, ExitInvalid, render(Options{Keys: []string{"API_URL", "MISSING", "ALSO_MISSING"}, EnvFile: out}))
+	assert.NoFileExists(t, out)
+
+	strict := config.DefineConfig(
+		map[string]any{"type": "object", "properties": map[string]any{"maxRetries": map[string]any{"type": "string"}}},
+		nil, nil,
+	)
+	assert.Equal(t, ExitInvalid, render(Options{Schema: strict, EnvFile: out}))
+
+	assert.Equal(t, ExitTimeout, render(Options{
+		Keys:           []string{"API_URL"},
+		EnvFile:        out,
+		Timeout:        20 * time.Millisecond,
+		ManagerOptions: []config.ConfigManagerOption{config.WithCMKVStore(blockingKV{}, "")},
+	}))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), nil, 0o600))
+	assert.Equal(t, ExitWrite, render(Options{Keys: []string{"API_URL"}, FilesDir: filepath.Join(dir, "file")}))
+}
+
+func TestMain_Flags(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, ExitUsage, Main(context.Background(), []string{"--bogus"}, &stdout, &stderr))
+	assert.Equal(t, ExitUsage, Main(context.Background(), []string{"--env-file", "-", "extra"}, &stdout, &stderr))
+	assert.Equal(t, ExitUsage, Main(context.Background(), []string{"--env-file", "-", "--schema", "/nonexistent.json"}, &stdout, &stderr))
+	assert.Equal(t, ExitUsage, Main(context.Background(), []string{"--env-file", "-"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "no keys selected")
+}
+
+// blockingKV is a KV store that never answers.
+type blockingKV struct{}
+
+func (blockingKV) List(ctx context.Context, _ string) (map[string]string, uint64, error) {
+	<-ctx.Done()
+	return nil, 0, ctx.Err()
+}
+
+func (blockingKV) Wait(ctx context.Context, _ string, _ uint64) (uint64, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
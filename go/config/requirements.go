@@ -0,0 +1,155 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// waitReadyBackoff bounds the delay between WaitReady's reloads.
+var waitReadyBackoff = struct{ initial, max time.Duration }{250 * time.Millisecond, 5 * time.Second}
+
+// Requirements declares the config an application needs before it can
+// start. Build it with Require and check it with WithCMRequirements and
+// WaitReady:
+//
+//	mgr := config.NewConfigManager(config.WithCMRequirements(
+//		config.Require().PublicKeys("API_URL").SecretKeys("DB_PASSWORD").Flags("ENABLE_NEW_UI"),
+//	))
+//	if err := mgr.WaitReady(ctx); err != nil {
+//		log.Fatal(err)
+//	}
+type Requirements struct {
+	keys []requiredKey
+}
+
+type requiredKey struct {
+	key  string
+	tier ConfigTier
+}
+
+// Require starts an empty set of requirements.
+func Require() *Requirements { return &Requirements{} }
+
+// PublicKeys requires keys to be set and readable as public values.
+func (r *Requirements) PublicKeys(keys ...string) *Requirements { return r.add(TierPublic, keys) }
+
+// SecretKeys requires keys to be set and readable as secrets.
+func (r *Requirements) SecretKeys(keys ...string) *Requirements { return r.add(TierSecret, keys) }
+
+// Flags requires feature flags to be set and readable as feature flags.
+func (r *Requirements) Flags(keys ...string) *Requirements { return r.add(TierFeatureFlag, keys) }
+
+func (r *Requirements) add(tier ConfigTier, keys []string) *Requirements {
+	for _, key := range keys {
+		r.keys = append(r.keys, requiredKey{key: key, tier: tier})
+	}
+	return r
+}
+
+// WithCMRequirements has WaitReady check the merged config against r.
+// Requirements from several calls add up.
+func WithCMRequirements(r *Requirements) ConfigManagerOption {
+	return func(m *ConfigManager) { m.requirements = append(m.requirements, r.keys...) }
+}
+
+// ErrNotReady matches any *NotReadyError via errors.Is.
+var ErrNotReady = errors.New("config does not meet its requirements")
+
+// UnmetRequirement is one requirement the merged config fails.
+type UnmetRequirement struct {
+	Key string
+	// Tier is the tier the key was required in.
+	Tier ConfigTier
+	// Reason says what is wrong, e.g. "is not set".
+	Reason string
+}
+
+// NotReadyError is returned by WaitReady with everything that stops the
+// config from being ready, so one startup failure shows all of it.
+type NotReadyError struct {
+	// Unmet lists the requirements the merged config fails, in the order
+	// they were declared.
+	Unmet []UnmetRequirement
+	// Cause is the error loading or validating the config, if any.
+	Cause error
+}
+
+// Error implements the error interface.
+func (e *NotReadyError) Error() string {
+	var parts []string
+	if e.Cause != nil {
+		parts = append(parts, e.Cause.Error())
+	}
+	for _, u := range e.Unmet {
+		parts = append(parts, fmt.Sprintf("%s key %s %s", u.Tier, u.Key, u.Reason))
+	}
+	msg := "[Smooai Config] config is not ready: " + strings.Join(parts, "; ")
+	if len(e.Unmet) > 0 {
+		msg += ". Set the missing keys in the config files, the SmooAI config API or env vars"
+	}
+	return msg
+}
+
+// Is supports errors.Is(err, ErrNotReady).
+func (e *NotReadyError) Is(target error) bool { return target == ErrNotReady }
+
+func (e *NotReadyError) Unwrap() error { return e.Cause }
+
+// WaitReady loads the config and checks it against the WithCMRequirements
+// requirements. While the config fails to load or doesn't meet them, it
+// reloads with a growing delay, in case a remote fetch or an env change is
+// all that's missing. When ctx ends first, it returns a *NotReadyError
+// listing every unmet requirement and the last load error.
+func (m *ConfigManager) WaitReady(ctx context.Context) error {
+	backoff := waitReadyBackoff.initial
+	for {
+		done := make(chan error, 1)
+		go func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			done <- m.checkReadyLocked()
+		}()
+		var err error
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			return &NotReadyError{Cause: fmt.Errorf("config did not load: %w", ctx.Err())}
+		}
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, waitReadyBackoff.max)
+		m.Invalidate()
+	}
+}
+
+// checkReadyLocked loads the config and returns a *NotReadyError when it
+// fails to load or to meet the requirements. Must be called under m.mu.
+func (m *ConfigManager) checkReadyLocked() error {
+	loadErr := m.initialize()
+	if m.config == nil {
+		// Nothing loaded to check the requirements against.
+		return &NotReadyError{Cause: loadErr}
+	}
+	var unmet []UnmetRequirement
+	for _, req := range m.requirements {
+		if v, ok := m.config[req.key]; !ok || v == nil {
+			unmet = append(unmet, UnmetRequirement{Key: req.key, Tier: req.tier, Reason: "is not set"})
+		} else if err := m.checkTierLocked(req.key, req.tier); err != nil {
+			actual := m.keyTierLocked(req.key)
+			unmet = append(unmet, UnmetRequirement{Key: req.key, Tier: req.tier, Reason: fmt.Sprintf("is declared as a %s value", actual)})
+		}
+	}
+	if loadErr == nil && len(unmet) == 0 {
+		return nil
+	}
+	return &NotReadyError{Unmet: unmet, Cause: loadErr}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitReady_Satisfied(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMConfigFiles(map[string]string{"default.json": `{"API_URL": "https://api", "DB_PASSWORD": "pw", "ENABLE_NEW_UI": false}`}),
+		WithCMEnvOverride(map[string]string{}),
+		WithCMKeyTiers(map[string]ConfigTier{"DB_PASSWORD": TierSecret, "ENABLE_NEW_UI": TierFeatureFlag}),
+		WithCMRequirements(Require().PublicKeys("API_URL").SecretKeys("DB_PASSWORD").Flags("ENABLE_NEW_UI")),
+	)
+	require.NoError(t, mgr.WaitReady(context.Background()))
+}
+
+func TestWaitReady_AggregatesUnmet(t *testing.T) {
+	mgr := NewConfigManager(
+		WithCMConfigFiles(map[string]string{"default.json": `{"API_URL": "https://api", "DB_PASSWORD": "pw", "NULLED": null}`}),
+		WithCMEnvOverride(map[string]string{}),
+		WithCMKeyTiers(map[string]ConfigTier{"DB_PASSWORD": TierSecret}),
+		WithCMRequirements(Require().PublicKeys("API_URL", "DB_PASSWORD", "NULLED")),
+		WithCMRequirements(Require().SecretKeys("STRIPE_KEY").Flags("ENABLE_NEW_UI")),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := mgr.WaitReady(ctx)
+	require.ErrorIs(t, err, ErrNotReady)
+	var notReady *NotReadyError
+	require.ErrorAs(t, err, &notReady)
+	assert.Equal(t, []UnmetRequirement{
+		{Key: "DB_PASSWORD", Tier: TierPublic, Reason: "is declared as a secret value"},
+		{Key: "NULLED", Tier: TierPublic, Reason: "is not set"},
+		{Key: "STRIPE_KEY", Tier: TierSecret, Reason: "is not set"},
+		{Key: "ENABLE_NEW_UI", Tier: TierFeatureFlag, Reason: "is not set"},
+	}, notReady.Unmet)
+	assert.Contains(t, err.Error(), "secret key STRIPE_KEY is not set; feature_flag key ENABLE_NEW_UI is not set")
+}
+
+func TestWaitReady_RetriesUntilSatisfied(t *testing.T) {
+	var set atomic.Bool
+	mgr := NewConfigManager(
+		WithCMSchemaKeys(map[string]bool{"API_URL": true}),
+		WithCMEnvFunc(func(key string) (string, bool) {
+			if key == "API_URL" && set.Load() {
+				return "https://api", true
+			}
+			return "", false
+		}),
+		WithCMRequirements(Require().PublicKeys("API_URL")),
+	)
+	time.AfterFunc(50*time.Millisecond, func() { set.Store(true) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, mgr.WaitReady(ctx))
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api", v)
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// HealthStatusRestartPending is reported by Health once a restart-required
+// key (WithRestartRequiredKeys) has changed in a running process.
+const HealthStatusRestartPending = "restart_pending"
+
+// WithRestartRequiredKeys marks keys the service only reads at startup
+// (pool sizes, listen addresses, ...). Hot-reloading them would silently do
+// nothing, so when a reload, pushed change or override changes one, the
+// manager records a pending restart: Health reports
+// HealthStatusRestartPending and any WithRestartHandler callback runs.
+func WithRestartRequiredKeys(keys ...string) ConfigManagerOption {
+	return func(m *ConfigManager) {
+		if m.restartKeys == nil {
+			m.restartKeys = make(map[string]bool, len(keys))
+		}
+		for _, key := range keys {
+			m.restartKeys[key] = true
+		}
+	}
+}
+
+// WithRestartHandler registers a callback for when restart-required keys
+// change, e.g. to drain and exit so the orchestrator starts a fresh process.
+// It runs on its own goroutine with the changed keys, once per change.
+func WithRestartHandler(fn func(keys []string)) ConfigManagerOption {
+	return func(m *ConfigManager) { m.restartHandler = fn }
+}
+
+// RestartPending returns the restart-required keys that changed since the
+// manager started, sorted; nil when no restart is needed.
+func (m *ConfigManager) RestartPending() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.restartPendingLocked()
+}
+
+// restartPendingLocked must be called under m.mu.
+func (m *ConfigManager) restartPendingLocked() []string {
+	if len(m.restartPending) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m.restartPending))
+	for key := range m.restartPending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// markRestartLocked records changed restart-required keys and fires the
+// handler. Must be called under m.mu.
+func (m *ConfigManager) markRestartLocked(changed []string) {
+	var keys []string
+	for _, key := range changed {
+		if m.restartKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	if m.restartPending == nil {
+		m.restartPending = make(map[string]bool)
+	}
+	for _, key := range keys {
+		m.restartPending[key] = true
+	}
+	if m.restartHandler != nil {
+		handler := m.restartHandler
+		m.components.goCallback(func() { handler(keys) })
+	}
+}
+
+// restartReason describes the pending restart for Health.
+func restartReason(keys []string) string {
+	return fmt.Sprintf("restart required: %s changed", strings.Join(keys, ", "))
+}
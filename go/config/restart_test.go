@@ -0,0 +1,82 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigManager_RestartRequiredKeys(t *testing.T) {
+	var mu sync.Mutex
+	values := map[string]any{"POOL_SIZE": 10.0, "API_URL": "https://v1"}
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"values": values})
+	}))
+	defer remote.Close()
+
+	restarts := make(chan []string, 4)
+	mgr := newStalenessManager(t, remote.URL,
+		WithRestartRequiredKeys("POOL_SIZE", "LISTEN_ADDR"),
+		WithRestartHandler(func(keys []string) { restarts <- keys }),
+	)
+	_, err := mgr.GetPublicConfig("POOL_SIZE")
+	require.NoError(t, err)
+	assert.True(t, mgr.Health().IsHealthy())
+
+	// A hot-reloadable change does not need a restart.
+	mu.Lock()
+	values = map[string]any{"POOL_SIZE": 10.0, "API_URL": "https://v2"}
+	mu.Unlock()
+	mgr.Invalidate()
+	_, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Nil(t, mgr.RestartPending())
+	assert.True(t, mgr.Health().IsHealthy())
+
+	mu.Lock()
+	values = map[string]any{"POOL_SIZE": 20.0, "API_URL": "https://v2"}
+	mu.Unlock()
+	mgr.Invalidate()
+	v, err := mgr.GetPublicConfig("POOL_SIZE")
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, v, "the new value is still served")
+
+	assert.Equal(t, []string{"POOL_SIZE"}, mgr.RestartPending())
+	h := mgr.Health()
+	assert.Equal(t, HealthStatusRestartPending, h.Status)
+	assert.Equal(t, "restart required: POOL_SIZE changed", h.Reason)
+	assert.Equal(t, []string{"POOL_SIZE"}, mgr.Status().RestartPending)
+
+	select {
+	case keys := <-restarts:
+		assert.Equal(t, []string{"POOL_SIZE"}, keys)
+	case <-time.After(2 * time.Second):
+		t.Fatal("restart handler not called")
+	}
+}
+
+func TestConfigManager_RestartRequiredOverride(t *testing.T) {
+	mgr := NewConfigManager(
+		WithRestartRequiredKeys("LISTEN_ADDR"),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"LISTEN_ADDR": ":8080"}}),
+		}),
+	)
+	_, err := mgr.GetPublicConfig("LISTEN_ADDR")
+	require.NoError(t, err)
+
+	mgr.SetOverride("LISTEN_ADDR", ":9090", 0)
+	assert.Equal(t, []string{"LISTEN_ADDR"}, mgr.RestartPending())
+}
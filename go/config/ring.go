@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetDeploymentRing detects the deployment ring from os environment variables.
+func GetDeploymentRing() string {
+	return GetDeploymentRingFromEnv(osEnvMap())
+}
+
+// GetDeploymentRingFromEnv detects the deployment ring (canary, early, broad,
+// ...) from a provided env map. Rings let early-ring instances receive
+// different values than broad rings within the same environment.
+//
+// Detection order:
+//  1. SMOOAI_CONFIG_RING (custom override)
+//  2. RING
+//  3. DEPLOYMENT_STAGE
+//  4. Default: "" (no ring — ring overlays and the ring query param are skipped)
+func GetDeploymentRingFromEnv(env map[string]string) string {
+	return strings.TrimSpace(coalesceStr(env["SMOOAI_CONFIG_RING"], env["RING"], env["DEPLOYMENT_STAGE"]))
+}
+
+// ringFileName returns the overlay file name for a ring within an environment,
+// e.g. "production.ring.canary.json". The ring comes from the process env, so
+// it must be a valid environment name; anything else, such as a path
+// separator or "..", is rejected rather than read from outside the config
+// directory.
+func ringFileName(envName, ring string) (string, error) {
+	if len(ring) > maxEnvironmentLength || !environmentPattern.MatchString(ring) {
+		return "", NewConfigError(fmt.Sprintf("invalid deployment ring %q: may only contain letters, digits, '.', '_' and '-', and must start with a letter or digit", ring))
+	}
+	return envName + ".ring." + ring + ".json", nil
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDeploymentRingFromEnv_CustomOverrideWins(t *testing.T) {
+	env := map[string]string{
+		"SMOOAI_CONFIG_RING": "canary",
+		"RING":               "early",
+		"DEPLOYMENT_STAGE":   "broad",
+	}
+	assert.Equal(t, "canary", GetDeploymentRingFromEnv(env))
+}
+
+func TestGetDeploymentRingFromEnv_RingBeforeDeploymentStage(t *testing.T) {
+	env := map[string]string{"RING": "early", "DEPLOYMENT_STAGE": "broad"}
+	assert.Equal(t, "early", GetDeploymentRingFromEnv(env))
+}
+
+func TestGetDeploymentRingFromEnv_DeploymentStage(t *testing.T) {
+	assert.Equal(t, "broad", GetDeploymentRingFromEnv(map[string]string{"DEPLOYMENT_STAGE": "broad"}))
+}
+
+func TestGetDeploymentRingFromEnv_DefaultEmpty(t *testing.T) {
+	assert.Equal(t, "", GetDeploymentRingFromEnv(map[string]string{}))
+}
+
+func TestFindAndProcessFileConfigWithEnv_RingOverlay(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".smooai-config")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	writeJSON(t, configDir, "default.json", map[string]any{"API_URL": "http://localhost", "MAX_RETRIES": 3})
+	writeJSON(t, configDir, "production.json", map[string]any{"API_URL": "https://api.example.com"})
+	writeJSON(t, configDir, "production.ring.canary.json", map[string]any{"API_URL": "https://canary.example.com"})
+
+	env := map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": configDir,
+		"SMOOAI_CONFIG_ENV":     "production",
+		"RING":                  "canary",
+	}
+	result, err := findAndProcessFileConfigWithEnv(env)
+	require.NoError(t, err)
+	assert.Equal(t, "https://canary.example.com", result["API_URL"])
+	assert.Equal(t, 3.0, result["MAX_RETRIES"])
+	assert.Equal(t, "canary", result["RING"])
+}
+
+func TestFindAndProcessFileConfigWithEnv_RingOverlaySkippedWithoutRing(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".smooai-config")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	writeJSON(t, configDir, "default.json", map[string]any{"API_URL": "http://localhost"})
+	writeJSON(t, configDir, "production.ring.canary.json", map[string]any{"API_URL": "https://canary.example.com"})
+
+	env := map[string]string{"SMOOAI_ENV_CONFIG_DIR": configDir, "SMOOAI_CONFIG_ENV": "production"}
+	result, err := findAndProcessFileConfigWithEnv(env)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost", result["API_URL"])
+	assert.Equal(t, "", result["RING"])
+}
+
+func TestFindAndProcessFileConfigWithEnv_RejectsInvalidRing(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".smooai-config")
+	require.NoError(t, os.MkdirAll(configDir, 0o755))
+	writeJSON(t, configDir, "default.json", map[string]any{"API_URL": "http://localhost"})
+
+	for _, ring := range []string{"../../x", "canary/early", ".hidden"} {
+		env := map[string]string{"SMOOAI_ENV_CONFIG_DIR": configDir, "SMOOAI_CONFIG_ENV": "production", "RING": ring}
+		_, err := findAndProcessFileConfigWithEnv(env)
+		var cfgErr *ConfigError
+		require.ErrorAs(t, err, &cfgErr, ring)
+		assert.Contains(t, err.Error(), "invalid deployment ring", ring)
+	}
+}
+
+func TestEnvConfig_SetsRingBuiltin(t *testing.T) {
+	result := findAndProcessEnvConfigWithEnv(map[string]bool{}, "", nil, map[string]string{"DEPLOYMENT_STAGE": "early"})
+	assert.Equal(t, "early", result["RING"])
+}
+
+func TestGetAllValues_SendsRingQueryParam(t *testing.T) {
+	var gotRing string
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		gotRing = r.URL.Query().Get("ring")
+		json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"A": "1"}})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL, WithRing("canary"))
+	defer client.Close()
+
+	_, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	assert.Equal(t, "canary", gotRing)
+}
+
+func TestGetValue_OmitsRingQueryParamWhenUnset(t *testing.T) {
+	hasRing := true
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		hasRing = r.URL.Query().Has("ring")
+		json.NewEncoder(w).Encode(valueResponse{Value: "v"})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL, WithRing(""))
+	defer client.Close()
+
+	_, err := client.GetValue("KEY", "production")
+	require.NoError(t, err)
+	assert.False(t, hasRing)
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetValueAtVersion(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/organizations/"+testOrgID+"/config/values/API_URL", r.URL.Path)
+		assert.Equal(t, "production", r.URL.Query().Get("environment"))
+		if r.URL.Query().Get("version") != "3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"value": "https://v3", "version": 3})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	v, err := client.GetValueAtVersion("API_URL", "production", 3)
+	require.NoError(t, err)
+	assert.Equal(t, "https://v3", v)
+	_, ok := client.GetCachedValue("API_URL", "production")
+	assert.False(t, ok, "old versions are not cached")
+
+	_, err = client.GetValueAtVersion("API_URL", "production", 99)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRollback(t *testing.T) {
+	var body map[string]any
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("ETag", `"v7"`)
+			json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"API_URL": "https://bad"}})
+			return
+		}
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/organizations/"+testOrgID+"/config/values/API_URL/rollback", r.URL.Path)
+		assert.Equal(t, `"v7"`, r.Header.Get("If-Match"))
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(map[string]any{"value": "https://good", "version": 8})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	version, err := client.Rollback("API_URL", "production", 6,
+		WithIfMatch(client.SnapshotETag("production")), WithChangeReason("bad deploy"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), version)
+	assert.Equal(t, map[string]any{"environment": "production", "toVersion": 6.0, "reason": "bad deploy"}, body)
+
+	v, ok := client.GetCachedValue("API_URL", "production")
+	assert.True(t, ok)
+	assert.Equal(t, "https://good", v)
+	assert.Equal(t, "", client.SnapshotETag("production"), "snapshot is dropped after a rollback")
+}
+
+func TestRollback_Conflict(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.Rollback("API_URL", "production", 6)
+	assert.ErrorIs(t, err, ErrWriteConflict)
+	_, ok := client.GetCachedValue("API_URL", "production")
+	assert.False(t, ok)
+}
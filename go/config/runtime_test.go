@@ -169,7 +169,7 @@ func TestNewRuntimeConfigManager_NoEnvFallsBackToLiveClient(t *testing.T) {
 		// cleanly to a live-fetching ConfigManager.
 		APIKey:      "test-key",
 		BaseURL:     srv.URL,
-		OrgID:       "org-1",
+		OrgID:       testOrgID,
 		Environment: "production",
 		EnvOverride: map[string]string{
 			// Ensure no stray blob env vars leak in from the test runner.
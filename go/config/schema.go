@@ -5,7 +5,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"sort"
 
 	"github.com/invopop/jsonschema"
 )
@@ -48,8 +48,8 @@ func DefineConfig(publicSchema, secretSchema, featureFlagSchema map[string]any)
 			result := ValidateSmooaiSchema(tier.schema)
 			if !result.Valid {
 				for _, e := range result.Errors {
-					fmt.Fprintf(os.Stderr, "[Smooai Config] Warning: [%s] %s: %s Suggestion: %s\n",
-						tier.name, e.Path, e.Message, e.Suggestion)
+					defaultLogger().Warn("schema is not portable across languages: "+e.Message,
+						"tier", tier.name, "path", e.Path, "suggestion", e.Suggestion)
 				}
 			}
 		}
@@ -101,6 +101,43 @@ func DefineConfig(publicSchema, secretSchema, featureFlagSchema map[string]any)
 	}
 }
 
+// Keys returns the property names declared for a tier, sorted. Unknown tiers
+// and tiers without a "properties" object return nil.
+func (d *ConfigDefinition) Keys(tier ConfigTier) []string {
+	var schema map[string]any
+	switch tier {
+	case TierPublic:
+		schema = d.PublicSchema
+	case TierSecret:
+		schema = d.SecretSchema
+	case TierFeatureFlag:
+		schema = d.FeatureFlagSchema
+	}
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// KeyTiers maps every key declared in the definition to its tier, for use
+// with WithCMKeyTiers. A key declared in more than one tier maps to the last
+// of public, secret, feature_flag.
+func (d *ConfigDefinition) KeyTiers() map[string]ConfigTier {
+	tiers := make(map[string]ConfigTier)
+	for _, tier := range []ConfigTier{TierPublic, TierSecret, TierFeatureFlag} {
+		for _, key := range d.Keys(tier) {
+			tiers[key] = tier
+		}
+	}
+	return tiers
+}
+
 // MarshalJSON implements custom JSON marshaling for ConfigTier.
 func (t ConfigTier) MarshalJSON() ([]byte, error) {
 	return json.Marshal(string(t))
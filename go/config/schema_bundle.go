@@ -0,0 +1,58 @@
+package config
+
+// Schema bundles. A bundle is a schema exported to a file, so air-gapped
+// and offline services get the same env coercion, tier enforcement and value
+// validation as WithCMRemoteSchema without reaching the API:
+//
+//	def, _ := client.GetSchema("production")
+//	config.WriteSchemaBundle(f, def)
+//
+//	//go:embed schema.bundle.json
+//	var bundle []byte
+//	def, err := config.ReadSchemaBundle(bytes.NewReader(bundle))
+//	mgr := config.NewConfigManager(config.WithCMSchemaBundle(def))
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteSchemaBundle writes def's JSON Schema to w as a schema bundle.
+func WriteSchemaBundle(w io.Writer, def *ConfigDefinition) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(def.JSONSchema); err != nil {
+		return NewConfigError(fmt.Sprintf("error writing schema bundle: %v", err))
+	}
+	return nil
+}
+
+// ReadSchemaBundle reads a schema bundle written by WriteSchemaBundle. The
+// server's GET /config/schema response ({"jsonSchema": {...}}) is accepted
+// too, so a saved API response works as a bundle.
+func ReadSchemaBundle(r io.Reader) (*ConfigDefinition, error) {
+	var schema map[string]any
+	if err := json.NewDecoder(r).Decode(&schema); err != nil {
+		return nil, NewConfigError(fmt.Sprintf("error parsing schema bundle: %v", err))
+	}
+	if inner, ok := schema["jsonSchema"].(map[string]any); ok {
+		schema = inner
+	}
+	return definitionFromJSONSchema(schema)
+}
+
+// WithCMSchemaBundle uses def, typically read with ReadSchemaBundle, as a
+// stand-in for the published schema: it is used as WithCMRemoteSchema uses
+// a fetched one. Explicitly configured schema keys, types, tiers and
+// WithCMDefinition still win. With WithCMRemoteSchema as well, a
+// successfully fetched schema replaces the bundle, which then only covers
+// fetch failures. For a definition that should always apply, use
+// WithCMDefinition instead.
+func WithCMSchemaBundle(def *ConfigDefinition) ConfigManagerOption {
+	return func(m *ConfigManager) {
+		m.remoteDefinition = def
+		m.remoteDefinitionTiers = definitionTiers(def)
+		m.schemaBundle = true
+	}
+}
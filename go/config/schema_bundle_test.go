@@ -0,0 +1,92 @@
+package config
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaBundle_RoundTrip(t *testing.T) {
+	def, err := definitionFromJSONSchema(remoteSchemaJSON())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSchemaBundle(&buf, def))
+	got, err := ReadSchemaBundle(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, jsonRoundTrip(def.JSONSchema), jsonRoundTrip(got.JSONSchema))
+	assert.Equal(t, TierSecret, got.KeyTiers()["DB_PASSWORD"])
+}
+
+func TestReadSchemaBundle_ServerResponse(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSchemaBundle(&buf, &ConfigDefinition{JSONSchema: remoteSchemaJSON()}))
+	got, err := ReadSchemaBundle(strings.NewReader(`{"id": "s1", "currentVersion": 2, "jsonSchema": ` + buf.String() + `}`))
+	require.NoError(t, err)
+	assert.Equal(t, TierPublic, got.KeyTiers()["MAX_RETRIES"])
+}
+
+func TestReadSchemaBundle_Invalid(t *testing.T) {
+	_, err := ReadSchemaBundle(strings.NewReader("not json"))
+	assert.ErrorContains(t, err, "error parsing schema bundle")
+	_, err = ReadSchemaBundle(strings.NewReader(`{"type": "object"}`))
+	assert.ErrorContains(t, err, "no tier properties")
+}
+
+func TestConfigManager_WithCMSchemaBundle_Offline(t *testing.T) {
+	def, err := definitionFromJSONSchema(remoteSchemaJSON())
+	require.NoError(t, err)
+	mgr := NewConfigManager(
+		WithCMSchemaBundle(def),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"DB_PASSWORD": "s3cret"}}),
+			"MAX_RETRIES":           "3",
+		}),
+	)
+
+	v, err := mgr.GetPublicConfig("MAX_RETRIES")
+	require.NoError(t, err)
+	assert.Equal(t, 3, v)
+	_, err = mgr.GetPublicConfig("DB_PASSWORD")
+	assert.ErrorIs(t, err, ErrWrongTier)
+
+	invalid := NewConfigManager(
+		WithCMSchemaBundle(def),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+			"MAX_RETRIES":           "-1",
+		}),
+	)
+	_, err = invalid.GetPublicConfig("MAX_RETRIES")
+	assert.ErrorIs(t, err, ErrSchemaViolation)
+}
+
+func TestConfigManager_WithCMSchemaBundle_FetchedSchemaWins(t *testing.T) {
+	var fetches atomic.Int32
+	server := newRemoteSchemaServer(t, map[string]any{}, http.StatusOK, &fetches)
+	// The bundle predates DB_PASSWORD moving to the secret tier.
+	stale := DefineConfig(map[string]any{"type": "object", "properties": map[string]any{
+		"DB_PASSWORD": map[string]any{"type": "string"},
+	}}, nil, nil)
+	mgr := NewConfigManager(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMRemoteSchema(),
+		WithCMSchemaBundle(stale),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_AUTH_URL": server.URL,
+			"SMOOAI_ENV_CONFIG_DIR":  makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+		}),
+	)
+
+	_, err := mgr.GetPublicConfig("DB_PASSWORD")
+	assert.ErrorIs(t, err, ErrWrongTier)
+	assert.Equal(t, int32(1), fetches.Load())
+}
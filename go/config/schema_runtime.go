@@ -0,0 +1,497 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSchemaRefDepth bounds $ref resolution so a self-referencing schema
+// can't recurse forever.
+const maxSchemaRefDepth = 32
+
+// ErrSchemaViolation matches any *SchemaViolationError via errors.Is.
+var ErrSchemaViolation = errors.New("config does not match its schema")
+
+// SchemaViolation is one way the merged config fails its ConfigDefinition.
+type SchemaViolation struct {
+	// Path locates the value, e.g. "/public/DATABASE/port".
+	Path string `json:"path"`
+	// Expected describes the constraint, e.g. "integer", "minimum 1" or
+	// "required".
+	Expected string `json:"expected"`
+	// Actual is the offending value: nil for a missing key, "[redacted]" in
+	// the secret tier.
+	Actual any `json:"actual"`
+}
+
+// SchemaViolationError is returned by the ConfigManager's Get* methods when
+// WithCMDefinition is set and the merged config violates the schema.
+type SchemaViolationError struct {
+	Violations []SchemaViolation
+}
+
+// Error implements the error interface.
+func (e *SchemaViolationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		if v.Expected == "required" {
+			parts[i] = v.Path + ": required key is not set"
+			continue
+		}
+		actual, err := json.Marshal(v.Actual)
+		if err != nil {
+			actual = []byte(fmt.Sprint(v.Actual))
+		}
+		parts[i] = fmt.Sprintf("%s: expected %s, got %s", v.Path, v.Expected, actual)
+	}
+	return "[Smooai Config] config does not match its schema: " + strings.Join(parts, "; ")
+}
+
+// Is supports errors.Is(err, ErrSchemaViolation).
+func (e *SchemaViolationError) Is(target error) bool { return target == ErrSchemaViolation }
+
+// WithConfigDefinition validates the merged config against def's public,
+// secret and feature flag schemas each time the manager loads it. While the
+// config violates the schema, every Get* returns a *SchemaViolationError
+// listing each violation. Pushed changes (Subscribe) and overrides are
+// validated as they apply, so a fix clears the error. Keys are matched by
+// their schema name or its UPPER_SNAKE_CASE form, so "apiUrl" in the schema
+// checks API_URL in the config.
+//
+// Deprecated: Use WithCMDefinition, which validates the same way and also
+// derives env keys and tiers from def.
+func WithConfigDefinition(def *ConfigDefinition) ConfigManagerOption {
+	return func(m *ConfigManager) { m.definition = def }
+}
+
+// validateLocked re-checks m.config against the definition and records the
+// result in m.schemaErr. Must be called under m.mu.
+func (m *ConfigManager) validateLocked() {
+	m.schemaErr = nil
+	def := m.definition
+	if def == nil {
+		def = m.remoteDefinition
+	}
+	if def == nil || m.config == nil {
+		return
+	}
+	if violations := validateConfig(def, m.config); len(violations) > 0 {
+		m.schemaErr = &SchemaViolationError{Violations: violations}
+	}
+}
+
+// validateConfig checks config against every tier schema in def.
+func validateConfig(def *ConfigDefinition, config map[string]any) []SchemaViolation {
+	var violations []SchemaViolation
+	for _, tier := range []struct {
+		name   ConfigTier
+		schema map[string]any
+	}{
+		{TierPublic, def.PublicSchema},
+		{TierSecret, def.SecretSchema},
+		{TierFeatureFlag, def.FeatureFlagSchema},
+	} {
+		if len(tier.schema) == 0 {
+			continue
+		}
+		v := &schemaValidator{root: tier.schema, redact: tier.name == TierSecret}
+		v.validateTier(config, "/"+string(tier.name))
+		violations = append(violations, v.violations...)
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Path < violations[j].Path })
+	return violations
+}
+
+// validateValues checks each value against the schema its key is declared
+// with in def, under any of its schemaKeyAliases. Undeclared keys are not
+// checked.
+func validateValues(def *ConfigDefinition, values map[string]any) []SchemaViolation {
+	var violations []SchemaViolation
+	for _, tier := range []struct {
+		name   ConfigTier
+		schema map[string]any
+	}{
+		{TierPublic, def.PublicSchema},
+		{TierSecret, def.SecretSchema},
+		{TierFeatureFlag, def.FeatureFlagSchema},
+	} {
+		if len(tier.schema) == 0 {
+			continue
+		}
+		v := &schemaValidator{root: tier.schema, redact: tier.name == TierSecret}
+		props, _ := v.resolve(tier.schema, 0)["properties"].(map[string]any)
+		for name, sub := range props {
+			subSchema, isMap := sub.(map[string]any)
+			if !isMap {
+				continue
+			}
+			if key, value, ok := lookupSchemaKey(values, name); ok {
+				v.validate(subSchema, value, "/"+string(tier.name)+"/"+key, 0)
+			}
+		}
+		violations = append(violations, v.violations...)
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Path < violations[j].Path })
+	return violations
+}
+
+type schemaValidator struct {
+	root       map[string]any
+	redact     bool
+	violations []SchemaViolation
+}
+
+func (v *schemaValidator) fail(path, expected string, actual any) {
+	if v.redact && actual != nil {
+		actual = redactedValue
+	}
+	v.violations = append(v.violations, SchemaViolation{Path: path, Expected: expected, Actual: actual})
+}
+
+// validateTier checks the tier's declared keys against the flat merged
+// config. Unlike a nested object, the merged config holds every tier's keys
+// plus the built-ins, so additionalProperties is not enforced here.
+func (v *schemaValidator) validateTier(config map[string]any, path string) {
+	schema := v.resolve(v.root, 0)
+	props, _ := schema["properties"].(map[string]any)
+	required := stringSet(schema["required"])
+	for name, sub := range props {
+		key, value, ok := lookupSchemaKey(config, name)
+		if !ok {
+			if required[name] {
+				v.fail(path+"/"+name, "required", nil)
+			}
+			continue
+		}
+		if subSchema, isMap := sub.(map[string]any); isMap {
+			v.validate(subSchema, value, path+"/"+key, 0)
+		}
+	}
+}
+
+// lookupSchemaKey finds a schema property in config under any of its
+// schemaKeyAliases.
+func lookupSchemaKey(config map[string]any, name string) (string, any, bool) {
+	for _, key := range schemaKeyAliases(name) {
+		if value, ok := config[key]; ok {
+			return key, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// resolve follows a local "$ref" ("#/$defs/X" or "#/definitions/X").
+func (v *schemaValidator) resolve(schema map[string]any, depth int) map[string]any {
+	for depth < maxSchemaRefDepth {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		var target map[string]any
+		for _, defsKey := range []string{"$defs", "definitions"} {
+			if name, found := strings.CutPrefix(ref, "#/"+defsKey+"/"); found {
+				defs, _ := v.root[defsKey].(map[string]any)
+				target, _ = defs[name].(map[string]any)
+			}
+		}
+		if target == nil {
+			return schema
+		}
+		schema = target
+		depth++
+	}
+	return schema
+}
+
+func (v *schemaValidator) validate(schema map[string]any, value any, path string, depth int) {
+	if depth > maxSchemaRefDepth {
+		return
+	}
+	schema = v.resolve(schema, depth)
+
+	if types := schemaTypeList(schema["type"]); len(types) > 0 && !matchesAnyJSONType(value, types) {
+		v.fail(path, strings.Join(types, " or "), value)
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok && !containsJSON(enum, value) {
+		v.fail(path, "one of "+jsonString(enum), value)
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, value) {
+		v.fail(path, "const "+jsonString(c), value)
+	}
+
+	switch x := value.(type) {
+	case string:
+		v.validateString(schema, x, path)
+	case map[string]any:
+		v.validateObject(schema, x, path, depth)
+	case []any:
+		v.validateArray(schema, x, path, depth)
+	default:
+		if n, ok := jsonNumber(value); ok {
+			v.validateNumber(schema, n, value, path)
+		}
+	}
+
+	for _, sub := range schemaList(schema["allOf"]) {
+		v.validate(sub, value, path, depth+1)
+	}
+	if anyOf := schemaList(schema["anyOf"]); len(anyOf) > 0 && v.countMatches(anyOf, value, path, depth) == 0 {
+		v.fail(path, "a match for anyOf", value)
+	}
+	if oneOf := schemaList(schema["oneOf"]); len(oneOf) > 0 {
+		if n := v.countMatches(oneOf, value, path, depth); n != 1 {
+			v.fail(path, fmt.Sprintf("exactly one match for oneOf (matched %d)", n), value)
+		}
+	}
+}
+
+// countMatches reports how many of schemas value satisfies, without
+// recording their violations.
+func (v *schemaValidator) countMatches(schemas []map[string]any, value any, path string, depth int) int {
+	matches := 0
+	for _, sub := range schemas {
+		probe := &schemaValidator{root: v.root}
+		probe.validate(sub, value, path, depth+1)
+		if len(probe.violations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+func (v *schemaValidator) validateString(schema map[string]any, s, path string) {
+	length := utf8.RuneCountInString(s)
+	if n, ok := jsonNumber(schema["minLength"]); ok && float64(length) < n {
+		v.fail(path, fmt.Sprintf("minLength %g", n), s)
+	}
+	if n, ok := jsonNumber(schema["maxLength"]); ok && float64(length) > n {
+		v.fail(path, fmt.Sprintf("maxLength %g", n), s)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		// Patterns RE2 can't compile are skipped rather than failing the
+		// whole config; ValidateSmooaiSchema is the place to catch them.
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(s) {
+			v.fail(path, "pattern "+pattern, s)
+		}
+	}
+	if format, ok := schema["format"].(string); ok && !matchesFormat(format, s) {
+		v.fail(path, "format "+format, s)
+	}
+}
+
+func (v *schemaValidator) validateNumber(schema map[string]any, n float64, value any, path string) {
+	if min, ok := jsonNumber(schema["minimum"]); ok && n < min {
+		v.fail(path, fmt.Sprintf("minimum %g", min), value)
+	}
+	if max, ok := jsonNumber(schema["maximum"]); ok && n > max {
+		v.fail(path, fmt.Sprintf("maximum %g", max), value)
+	}
+	if min, ok := jsonNumber(schema["exclusiveMinimum"]); ok && n <= min {
+		v.fail(path, fmt.Sprintf("exclusiveMinimum %g", min), value)
+	}
+	if max, ok := jsonNumber(schema["exclusiveMaximum"]); ok && n >= max {
+		v.fail(path, fmt.Sprintf("exclusiveMaximum %g", max), value)
+	}
+	if step, ok := jsonNumber(schema["multipleOf"]); ok && step > 0 {
+		if q := n / step; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(path, fmt.Sprintf("multipleOf %g", step), value)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema map[string]any, obj map[string]any, path string, depth int) {
+	props, _ := schema["properties"].(map[string]any)
+	for name := range stringSet(schema["required"]) {
+		if _, ok := obj[name]; !ok {
+			v.fail(path+"/"+name, "required", nil)
+		}
+	}
+	for name, value := range obj {
+		if sub, ok := props[name].(map[string]any); ok {
+			v.validate(sub, value, path+"/"+name, depth+1)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.fail(path+"/"+name, "no additional properties", value)
+			}
+		case map[string]any:
+			v.validate(extra, value, path+"/"+name, depth+1)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(schema map[string]any, arr []any, path string, depth int) {
+	if n, ok := jsonNumber(schema["minItems"]); ok && float64(len(arr)) < n {
+		v.fail(path, fmt.Sprintf("minItems %g", n), arr)
+	}
+	if n, ok := jsonNumber(schema["maxItems"]); ok && float64(len(arr)) > n {
+		v.fail(path, fmt.Sprintf("maxItems %g", n), arr)
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		seen := make(map[string]bool, len(arr))
+		for _, item := range arr {
+			key := jsonString(item)
+			if seen[key] {
+				v.fail(path, "uniqueItems", arr)
+				break
+			}
+			seen[key] = true
+		}
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		for i, item := range arr {
+			v.validate(items, item, fmt.Sprintf("%s/%d", path, i), depth+1)
+		}
+	}
+}
+
+// schemaTypeList normalizes "type" (a string or a list of strings).
+func schemaTypeList(t any) []string {
+	switch x := t.(type) {
+	case string:
+		return []string{x}
+	case []any:
+		types := make([]string, 0, len(x))
+		for _, item := range x {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	case []string:
+		return x
+	}
+	return nil
+}
+
+func matchesAnyJSONType(value any, types []string) bool {
+	for _, t := range types {
+		if matchesJSONType(value, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesJSONType(value any, t string) bool {
+	switch t {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := jsonNumber(value)
+		return ok
+	case "integer":
+		n, ok := jsonNumber(value)
+		return ok && n == math.Trunc(n)
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	}
+	return true
+}
+
+// jsonNumber converts a numeric value (never a bool) to float64.
+func jsonNumber(v any) (float64, bool) {
+	if _, isBool := v.(bool); isBool {
+		return 0, false
+	}
+	return metricValue(v)
+}
+
+func schemaList(v any) []map[string]any {
+	list, _ := v.([]any)
+	schemas := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(map[string]any); ok {
+			schemas = append(schemas, s)
+		}
+	}
+	return schemas
+}
+
+func stringSet(v any) map[string]bool {
+	set := make(map[string]bool)
+	switch x := v.(type) {
+	case []any:
+		for _, item := range x {
+			if s, ok := item.(string); ok {
+				set[s] = true
+			}
+		}
+	case []string:
+		for _, s := range x {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+// jsonString renders v as JSON; equal JSON values render identically since
+// encoding/json sorts map keys.
+func jsonString(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func jsonEqual(a, b any) bool {
+	return jsonString(a) == jsonString(b)
+}
+
+func containsJSON(list []any, value any) bool {
+	for _, item := range list {
+		if jsonEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFormat checks the formats ValidateSmooaiSchema accepts; unknown
+// formats pass.
+func matchesFormat(format, s string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	case "uuid":
+		return uuidPattern.MatchString(strings.ToLower(s))
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	case "ipv6":
+		ip := net.ParseIP(s)
+		return ip != nil && strings.Contains(s, ":")
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runtimeTestDefinition() *ConfigDefinition {
+	return DefineConfig(
+		map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"apiUrl":     map[string]any{"type": "string", "format": "uri"},
+				"maxRetries": map[string]any{"type": "integer", "minimum": 0, "maximum": 10},
+				"logLevel":   map[string]any{"type": "string", "enum": []any{"debug", "info", "warn"}},
+				"database": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"host": map[string]any{"type": "string", "minLength": 1},
+						"port": map[string]any{"type": "integer"},
+					},
+					"required":             []any{"host"},
+					"additionalProperties": false,
+				},
+				"regions": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "uniqueItems": true},
+			},
+			"required": []any{"apiUrl"},
+		},
+		map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"dbPassword": map[string]any{"type": "string", "minLength": 12}},
+		},
+		map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"newUi": map[string]any{"type": "boolean"}},
+		},
+	)
+}
+
+func TestValidateConfig_Valid(t *testing.T) {
+	violations := validateConfig(runtimeTestDefinition(), map[string]any{
+		"API_URL":     "https://api.example.com",
+		"MAX_RETRIES": 3.0,
+		"LOG_LEVEL":   "info",
+		"DATABASE":    map[string]any{"host": "db", "port": 5432.0},
+		"REGIONS":     []any{"us-east-1", "eu-west-1"},
+		"DB_PASSWORD": "correct-horse-battery",
+		"NEW_UI":      true,
+		"ENV":         "production", // undeclared keys are fine at the top level
+	})
+	assert.Empty(t, violations)
+}
+
+func TestValidateConfig_Violations(t *testing.T) {
+	violations := validateConfig(runtimeTestDefinition(), map[string]any{
+		"MAX_RETRIES": 2.5,
+		"LOG_LEVEL":   "trace",
+		"DATABASE":    map[string]any{"port": "5432", "user": "x"},
+		"REGIONS":     []any{"a", "a"},
+		"DB_PASSWORD": "short",
+		"NEW_UI":      "yes",
+	})
+	assert.Equal(t, []SchemaViolation{
+		{Path: "/feature_flag/NEW_UI", Expected: "boolean", Actual: "yes"},
+		{Path: "/public/DATABASE/host", Expected: "required", Actual: nil},
+		{Path: "/public/DATABASE/port", Expected: "integer", Actual: "5432"},
+		{Path: "/public/DATABASE/user", Expected: "no additional properties", Actual: "x"},
+		{Path: "/public/LOG_LEVEL", Expected: `one of ["debug","info","warn"]`, Actual: "trace"},
+		{Path: "/public/MAX_RETRIES", Expected: "integer", Actual: 2.5},
+		{Path: "/public/REGIONS", Expected: "uniqueItems", Actual: []any{"a", "a"}},
+		{Path: "/public/apiUrl", Expected: "required", Actual: nil},
+		{Path: "/secret/DB_PASSWORD", Expected: "minLength 12", Actual: "[redacted]"},
+	}, violations)
+}
+
+func TestValidateConfig_CompositionAndRefs(t *testing.T) {
+	def := DefineConfig(map[string]any{
+		"type": "object",
+		"$defs": map[string]any{
+			"Port": map[string]any{"type": "integer", "exclusiveMinimum": 0, "maximum": 65535},
+		},
+		"properties": map[string]any{
+			"port":    map[string]any{"$ref": "#/$defs/Port"},
+			"timeout": map[string]any{"anyOf": []any{map[string]any{"type": "integer"}, map[string]any{"type": "string", "pattern": "^[0-9]+s$"}}},
+			"mode":    map[string]any{"oneOf": []any{map[string]any{"const": "a"}, map[string]any{"type": "string", "maxLength": 1}}},
+		},
+	}, nil, nil)
+
+	assert.Empty(t, validateConfig(def, map[string]any{"PORT": 8080, "TIMEOUT": "30s", "MODE": "b"}))
+
+	violations := validateConfig(def, map[string]any{"PORT": 0, "TIMEOUT": "soon", "MODE": "a"})
+	require.Len(t, violations, 3)
+	assert.Equal(t, "exactly one match for oneOf (matched 2)", violations[0].Expected)
+	assert.Equal(t, "exclusiveMinimum 0", violations[1].Expected)
+	assert.Equal(t, "a match for anyOf", violations[2].Expected)
+}
+
+func TestConfigManager_WithConfigDefinition(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{
+		"default.json": map[string]any{"API_URL": "https://api.example.com", "MAX_RETRIES": 20},
+	})
+	mgr := NewConfigManager(
+		WithConfigDefinition(runtimeTestDefinition()),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}),
+	)
+
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.ErrorIs(t, err, ErrSchemaViolation)
+	var schemaErr *SchemaViolationError
+	require.ErrorAs(t, err, &schemaErr)
+	assert.Equal(t, []SchemaViolation{{Path: "/public/MAX_RETRIES", Expected: "maximum 10", Actual: 20.0}}, schemaErr.Violations)
+	assert.Equal(t, "[Smooai Config] config does not match its schema: /public/MAX_RETRIES: expected maximum 10, got 20", err.Error())
+
+	// Fixing the value live clears the error.
+	mgr.SetOverride("MAX_RETRIES", 5, 0)
+	v, err := mgr.GetPublicConfig("MAX_RETRIES")
+	require.NoError(t, err)
+	assert.Equal(t, 5, v)
+
+	// And breaking it again is caught even for cached keys.
+	_, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	mgr.SetOverride("API_URL", "not a uri", 0)
+	_, err = mgr.GetPublicConfig("MAX_RETRIES")
+	assert.ErrorIs(t, err, ErrSchemaViolation)
+}
@@ -229,3 +229,16 @@ func TestDefineConfigTyped_NilAll(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "object", result.JSONSchema["type"])
 }
+
+func TestConfigDefinition_KeysPerTier(t *testing.T) {
+	def := DefineConfig(
+		map[string]any{"type": "object", "properties": map[string]any{"b": map[string]any{}, "a": map[string]any{}}},
+		map[string]any{"type": "object", "properties": map[string]any{"secret": map[string]any{}}},
+		nil,
+	)
+
+	assert.Equal(t, []string{"a", "b"}, def.Keys(TierPublic))
+	assert.Equal(t, []string{"secret"}, def.Keys(TierSecret))
+	assert.Nil(t, def.Keys(TierFeatureFlag))
+	assert.Nil(t, def.Keys(ConfigTier("bogus")))
+}
@@ -0,0 +1,83 @@
+package config
+
+import "strings"
+
+// WithClientKeyPrefix scopes the client's value fetches to the keys that
+// start with one of prefixes. The prefixes are sent as ?prefix= so the
+// server can leave the other keys out of the response, and any other key
+// it returns is dropped. GetValue for a key outside the scope returns nil
+// without a request.
+func WithClientKeyPrefix(prefixes ...string) ConfigClientOption {
+	return func(c *ConfigClient) { c.keyPrefixes = nonEmpty(prefixes) }
+}
+
+// WithClientNamespace scopes the client's value fetches to the keys of
+// namespace ns, sent as ?namespace=. A key the server tags with another
+// namespace is dropped, and cached values are stored under ns
+// (CacheKey.Namespace).
+func WithClientNamespace(ns string) ConfigClientOption {
+	return func(c *ConfigClient) { c.namespace = ns }
+}
+
+// WithKeyPrefixFilter keeps only the remote keys that start with one of
+// prefixes, so a service that reads PAYMENTS_* doesn't hold (or leak in a
+// dump) the rest of the org's values. The filter is sent to the server and
+// enforced again on every fetch and pushed change. Keys from files, env
+// vars and the other layers are not filtered.
+func WithKeyPrefixFilter(prefixes ...string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.keyPrefixes = nonEmpty(prefixes) }
+}
+
+// WithNamespace keeps only the remote keys of namespace ns. See
+// WithClientNamespace and WithKeyPrefixFilter.
+func WithNamespace(ns string) ConfigManagerOption {
+	return func(m *ConfigManager) { m.namespace = ns }
+}
+
+// inScope reports whether key, tagged with namespace ns ("" if untagged),
+// is within the client's key prefixes and namespace.
+func (c *ConfigClient) inScope(key, ns string) bool {
+	if c.namespace != "" && ns != "" && ns != c.namespace {
+		return false
+	}
+	return hasKeyPrefix(key, c.keyPrefixes)
+}
+
+// dropOutOfScope removes the keys outside the client's scope from result.
+func (c *ConfigClient) dropOutOfScope(result *valuesResponse) {
+	if c.namespace == "" && len(c.keyPrefixes) == 0 {
+		return
+	}
+	for key := range result.Values {
+		if !c.inScope(key, result.Metadata[key].Namespace) {
+			delete(result.Values, key)
+			delete(result.Tiers, key)
+			delete(result.Metadata, key)
+		}
+	}
+}
+
+// hasKeyPrefix reports whether key starts with one of prefixes, or
+// prefixes is empty.
+func hasKeyPrefix(key string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// nonEmpty drops the empty strings from values.
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
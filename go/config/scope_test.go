@@ -0,0 +1,101 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scopedRemote serves response for every values request, ignoring any
+// scope, and records the query of each.
+func scopedRemote(t *testing.T, response map[string]any) (*httptest.Server, func() []url.Values) {
+	t.Helper()
+	var mu sync.Mutex
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		mu.Lock()
+		queries = append(queries, r.URL.Query())
+		mu.Unlock()
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+		return queries
+	}
+}
+
+func TestConfigClient_KeyPrefix(t *testing.T) {
+	server, queries := scopedRemote(t, map[string]any{
+		"values": map[string]any{"PAYMENTS_URL": "https://pay", "BILLING_URL": "https://bill", "STRIPE_KEY": "sk"},
+	})
+	client := newUnitClient(t, server.URL, WithClientKeyPrefix("PAYMENTS_", "STRIPE_"))
+
+	values, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"PAYMENTS_URL": "https://pay", "STRIPE_KEY": "sk"}, values, "keys the server returns anyway are dropped")
+	require.Len(t, queries(), 1)
+	assert.Equal(t, []string{"PAYMENTS_", "STRIPE_"}, queries()[0]["prefix"])
+
+	v, err := client.GetValue("BILLING_URL", "production")
+	require.NoError(t, err)
+	assert.Nil(t, v)
+	assert.Len(t, queries(), 1, "a key outside the prefixes is not fetched")
+}
+
+func TestConfigClient_Namespace(t *testing.T) {
+	server, queries := scopedRemote(t, map[string]any{
+		"values":   map[string]any{"URL": "https://pay", "LIMIT": 5, "INVOICE_URL": "https://bill"},
+		"metadata": map[string]any{"URL": map[string]any{"namespace": "payments"}, "INVOICE_URL": map[string]any{"namespace": "billing"}},
+	})
+	client := newUnitClient(t, server.URL, WithClientNamespace("payments"))
+
+	values, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"URL": "https://pay", "LIMIT": float64(5)}, values, "keys tagged with another namespace are dropped")
+	assert.Equal(t, "payments", queries()[0].Get("namespace"))
+
+	v, ok := client.CachedValue(CacheKey{Environment: "production", Namespace: "payments", Key: "URL"})
+	assert.True(t, ok)
+	assert.Equal(t, "https://pay", v)
+	_, ok = client.CachedValue(CacheKey{Environment: "production", Key: "URL"})
+	assert.False(t, ok, "namespaced values don't share the environment-wide entries")
+}
+
+func TestConfigManager_KeyPrefixFilter(t *testing.T) {
+	server, queries := scopedRemote(t, map[string]any{
+		"values": map[string]any{"PAYMENTS_URL": "https://pay", "BILLING_URL": "https://bill"},
+	})
+	mgr := newStalenessManager(t, server.URL, WithKeyPrefixFilter("PAYMENTS_"))
+
+	v, err := mgr.GetPublicConfig("PAYMENTS_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://pay", v)
+	has, err := mgr.Has("BILLING_URL")
+	require.NoError(t, err)
+	assert.False(t, has)
+	assert.Equal(t, "PAYMENTS_", queries()[0].Get("prefix"))
+
+	mgr.applyRemoteChange(nil, ConfigChangeEvent{
+		Environment: "production",
+		Keys:        []string{"PAYMENTS_URL", "BILLING_URL"},
+		Values:      map[string]any{"PAYMENTS_URL": "https://pay2", "BILLING_URL": "https://bill2"},
+	})
+	v, err = mgr.GetPublicConfig("PAYMENTS_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://pay2", v)
+	has, err = mgr.Has("BILLING_URL")
+	require.NoError(t, err)
+	assert.False(t, has, "pushed changes are filtered too")
+}
@@ -0,0 +1,7 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package config
+
+// allocSecret returns an n-byte heap buffer: this platform has no mlock.
+// SecretString still zeroes it on Destroy.
+func allocSecret(n int) ([]byte, func()) { return allocSecretHeap(n) }
@@ -0,0 +1,25 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package config
+
+import "syscall"
+
+// allocSecret returns an n-byte buffer mapped outside the Go heap and,
+// when RLIMIT_MEMLOCK allows, locked into RAM, with the func that releases
+// it.
+func allocSecret(n int) ([]byte, func()) {
+	if n == 0 {
+		return []byte{}, func() {}
+	}
+	buf, err := syscall.Mmap(-1, 0, n, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return allocSecretHeap(n)
+	}
+	locked := syscall.Mlock(buf) == nil
+	return buf, func() {
+		if locked {
+			_ = syscall.Munlock(buf)
+		}
+		_ = syscall.Munmap(buf)
+	}
+}
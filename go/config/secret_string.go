@@ -0,0 +1,157 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// SecretString holds a secret in a buffer outside the Go heap, locked into
+// RAM where the platform allows (mlock on Unix) so it is never swapped, and
+// zeroed by Destroy. Printing, logging or marshalling it shows
+// "[redacted]"; Expose is the only way to read it. The zero value is an
+// empty, destroyed secret.
+type SecretString struct {
+	mu   sync.Mutex
+	buf  []byte
+	free func()
+}
+
+// NewSecretString copies s into a new SecretString. s itself is not
+// scrubbed; Go strings are immutable.
+func NewSecretString(s string) *SecretString {
+	buf, free := allocSecret(len(s))
+	copy(buf, s)
+	secret := &SecretString{buf: buf, free: free}
+	// A SecretString the caller forgets to Destroy is still scrubbed.
+	runtime.SetFinalizer(secret, (*SecretString).Destroy)
+	return secret
+}
+
+// Expose calls fn with the secret. The string aliases the protected buffer:
+// fn must not keep it, or anything sliced from it, after returning. Destroy
+// waits for fn to return. A destroyed secret exposes "".
+func (s *SecretString) Expose(fn func(string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buf) == 0 {
+		fn("")
+		return
+	}
+	fn(unsafe.String(&s.buf[0], len(s.buf)))
+}
+
+// Len returns the length of the secret in bytes.
+func (s *SecretString) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buf)
+}
+
+// Destroy zeroes and releases the buffer. Safe to call more than once.
+func (s *SecretString) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.free != nil {
+		clear(s.buf)
+		s.free()
+	}
+	s.buf, s.free = nil, nil
+}
+
+// allocSecretHeap is allocSecret's fallback: an ordinary buffer that
+// Destroy still zeroes.
+func allocSecretHeap(n int) ([]byte, func()) { return make([]byte, n), func() {} }
+
+// clone returns a new SecretString holding the same secret.
+func (s *SecretString) clone() *SecretString {
+	var c *SecretString
+	s.Expose(func(v string) { c = NewSecretString(v) })
+	return c
+}
+
+// reveal copies the secret onto the heap, for parsers whose results hold
+// ordinary strings anyway, such as a Credential.
+func (s *SecretString) reveal() string {
+	var v string
+	s.Expose(func(secret string) { v = strings.Clone(secret) })
+	return v
+}
+
+// destroySecret scrubs value when it is a *SecretString.
+func destroySecret(value any) {
+	if s, ok := value.(*SecretString); ok {
+		s.Destroy()
+	}
+}
+
+// String returns "[redacted]".
+func (s *SecretString) String() string { return redactedValue }
+
+// GoString returns "[redacted]".
+func (s *SecretString) GoString() string { return redactedValue }
+
+// MarshalJSON encodes the secret as "[redacted]".
+func (s *SecretString) MarshalJSON() ([]byte, error) { return json.Marshal(redactedValue) }
+
+// LogValue logs the secret as "[redacted]".
+func (s *SecretString) LogValue() slog.Value { return slog.StringValue(redactedValue) }
+
+// WithSecretMemoryProtection makes GetSecretConfig return string secrets
+// as a *SecretString instead of a string: read them with Expose, and
+// Destroy each one when done. Every call returns a new SecretString the
+// caller owns. The manager's secret cache holds its own SecretStrings and
+// scrubs them when a key changes or expires, on Invalidate and on Close.
+// Secrets that aren't strings are returned as usual.
+//
+// Only those SecretStrings are protected. The merged config, the fetched
+// remote values, the last good remote values and the client's value cache
+// keep every secret as a plain Go string on the heap for the life of the
+// process, and neither Invalidate nor Close scrubs them. Pair this option
+// with WithDecryptionKey so those copies hold only ciphertext.
+func WithSecretMemoryProtection() ConfigManagerOption {
+	return func(m *ConfigManager) { m.protectSecrets = true }
+}
+
+// protectSecret wraps a string secret in a SecretString for the cache.
+func (m *ConfigManager) protectSecret(value any) any {
+	if s, ok := value.(string); ok && m.protectSecrets {
+		return NewSecretString(s)
+	}
+	return value
+}
+
+// handOut returns the value a getter returns for a cached value: its own
+// copy of a SecretString, otherwise the value itself.
+func handOut(value any) any {
+	if s, ok := value.(*SecretString); ok {
+		return s.clone()
+	}
+	return value
+}
+
+// dropCacheEntry removes key from cache, scrubbing a SecretString.
+func dropCacheEntry(cache map[string]localCacheEntry, key string) {
+	if s, ok := cache[key].value.(*SecretString); ok {
+		s.Destroy()
+	}
+	delete(cache, key)
+}
+
+// scrubCache empties cache, scrubbing every SecretString in it.
+func scrubCache(cache map[string]localCacheEntry) {
+	for key := range cache {
+		dropCacheEntry(cache, key)
+	}
+}
+
+// scrubSecrets empties the secret cache. Reads afterwards fill it again
+// from the merged config.
+func (m *ConfigManager) scrubSecrets() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	scrubCache(m.secretCache)
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exposed(s *SecretString) string {
+	var out string
+	s.Expose(func(v string) { out = string([]byte(v)) })
+	return out
+}
+
+func TestSecretString(t *testing.T) {
+	s := NewSecretString("hunter2")
+	assert.Equal(t, "hunter2", exposed(s))
+	assert.Equal(t, 7, s.Len())
+	assert.Equal(t, redactedValue, s.String())
+	assert.NotContains(t, fmt.Sprintf("%v %+v %#v", s, s, s), "hunter2")
+	data, err := json.Marshal(map[string]any{"password": s})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password": "[redacted]"}`, string(data))
+
+	s.Destroy()
+	s.Destroy()
+	assert.Equal(t, "", exposed(s))
+	assert.Zero(t, s.Len())
+
+	assert.Equal(t, "", exposed(NewSecretString("")))
+	assert.Equal(t, "", exposed(&SecretString{}), "the zero value is empty")
+}
+
+func TestConfigManager_WithSecretMemoryProtection(t *testing.T) {
+	mgr := NewStaticConfigManager(
+		map[string]any{"API_URL": "https://static"},
+		map[string]any{"DB_PASSWORD": "hunter2", "DB_PORT": 5432.0},
+		nil,
+		WithSecretMemoryProtection(),
+	)
+
+	v, err := mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	first, ok := v.(*SecretString)
+	require.True(t, ok, "string secrets come back as *SecretString, got %T", v)
+	assert.Equal(t, "hunter2", exposed(first))
+
+	v, err = mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	second := v.(*SecretString)
+	assert.NotSame(t, first, second, "each read returns the caller's own copy")
+	first.Destroy()
+	assert.Equal(t, "hunter2", exposed(second), "destroying one copy leaves the others")
+
+	v, err = mgr.GetSecretConfig("DB_PORT")
+	require.NoError(t, err)
+	assert.Equal(t, 5432.0, v, "secrets that aren't strings are returned as usual")
+	v, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://static", v)
+
+	secret, err := Get[*SecretString](mgr, "DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", exposed(secret))
+	_, err = Get[string](mgr, "DB_PASSWORD")
+	assert.ErrorContains(t, err, "protected secret")
+	assert.NotPanics(t, func() { mgr.MustGetSecretConfig("DB_PASSWORD") })
+
+	mgr.mu.Lock()
+	cached := mgr.secretCache["DB_PASSWORD"].value.(*SecretString)
+	mgr.mu.Unlock()
+	mgr.Invalidate()
+	assert.Zero(t, cached.Len(), "Invalidate scrubs the cache")
+
+	_, err = mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	mgr.mu.Lock()
+	cached = mgr.secretCache["DB_PASSWORD"].value.(*SecretString)
+	mgr.mu.Unlock()
+	require.NoError(t, mgr.Close(context.Background()))
+	assert.Zero(t, cached.Len(), "Close scrubs the cache")
+	assert.Equal(t, "hunter2", exposed(second), "copies handed out are the caller's to destroy")
+}
@@ -0,0 +1,127 @@
+package config
+
+// Bulk writes. SetValues sends every key in one transactional request so
+// related keys (a database host and its password, say) are never observed
+// half-updated by other readers.
+//
+// Server contract:
+//
+//	PUT {BaseURL}/organizations/{orgId}/config/values/batch
+//	If-Match: "<etag>"            (optional, WithIfMatch)
+//	{"environment": "...", "values": {"K": v, ...}, "reason": "..."}
+//
+// The server applies all values or none. 409 / 412 mean the write lost a
+// race (or the If-Match precondition failed) and nothing was written.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrWriteConflict matches the HTTPError SetValues returns when the server
+// rejects the write with 409 Conflict or 412 Precondition Failed. Nothing was
+// written.
+var ErrWriteConflict = errors.New("config write conflict")
+
+// WriteOption configures a SetValues call.
+type WriteOption func(*writeOptions)
+
+type writeOptions struct {
+	ifMatch string
+	reason  string
+}
+
+// WithIfMatch makes the write conditional on the environment's values still
+// matching etag (see SnapshotETag). A stale etag fails with ErrWriteConflict
+// instead of overwriting someone else's change.
+func WithIfMatch(etag string) WriteOption {
+	return func(o *writeOptions) { o.ifMatch = etag }
+}
+
+// WithChangeReason records a human-readable reason in the server's audit log.
+func WithChangeReason(reason string) WriteOption {
+	return func(o *writeOptions) { o.reason = reason }
+}
+
+// WithClientDefinition validates every SetValues write against the schema
+// of the key's tier in def before anything is sent, so a bad value fails
+// at once with a *SchemaViolationError listing each problem instead of a
+// server 400. Keys def doesn't declare are sent unchecked.
+func WithClientDefinition(def *ConfigDefinition) ConfigClientOption {
+	return func(c *ConfigClient) { c.definition = def }
+}
+
+// SnapshotETag returns the ETag of the last GetAllValues response for
+// environment, or "" when none is known. Pass it to WithIfMatch for a
+// read-modify-write that fails instead of clobbering a concurrent change.
+func (c *ConfigClient) SnapshotETag(environment string) string {
+	env := c.resolveEnv(environment)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.snapshots[env].etag
+}
+
+// SetValues writes values to environment in a single transactional PUT —
+// the server applies every key or none. Pass an empty environment to use the
+// client's default. On success the written values replace their cache
+// entries together, and the environment's ETag snapshot is dropped so the
+// next GetAllValues is a full fetch.
+func (c *ConfigClient) SetValues(environment string, values map[string]any, opts ...WriteOption) error {
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if c.definition != nil {
+		if violations := validateValues(c.definition, values); len(violations) > 0 {
+			return &SchemaViolationError{Violations: violations}
+		}
+	}
+	var o writeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	body, err := json.Marshal(struct {
+		Environment string         `json:"environment"`
+		Values      map[string]any `json:"values"`
+		Reason      string         `json:"reason,omitempty"`
+	}{env, values, o.reason})
+	if err != nil {
+		return fmt.Errorf("config set values: marshal body: %w", err)
+	}
+
+	u := fmt.Sprintf("%s/config/values/batch", c.orgURL())
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("config set values: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.ifMatch != "" {
+		req.Header.Set("If-Match", o.ifMatch)
+	}
+
+	resp, err := c.doRequestWithRetry(req)
+	if err != nil {
+		return fmt.Errorf("config set values: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newHTTPError("set values", resp)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.snapshots, env)
+	for key, value := range values {
+		c.setCacheLocked(c.valueCacheKey(env, key), value)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetValues_SingleTransactionalPut(t *testing.T) {
+	var puts int
+	var body map[string]any
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("ETag", `"v1"`)
+			json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"DATABASE_HOST": "old-host", "DATABASE_PASSWORD": "old"}})
+			return
+		}
+		puts++
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/organizations/"+testOrgID+"/config/values/batch", r.URL.Path)
+		assert.Equal(t, `"v1"`, r.Header.Get("If-Match"))
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	etag := client.SnapshotETag("production")
+	assert.Equal(t, `"v1"`, etag)
+
+	err = client.SetValues("production", map[string]any{
+		"DATABASE_HOST":     "new-host",
+		"DATABASE_PASSWORD": "rotated",
+	}, WithIfMatch(etag), WithChangeReason("rotate db credentials"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, puts)
+	assert.Equal(t, "production", body["environment"])
+	assert.Equal(t, "rotate db credentials", body["reason"])
+	assert.Equal(t, map[string]any{"DATABASE_HOST": "new-host", "DATABASE_PASSWORD": "rotated"}, body["values"])
+
+	v, ok := client.GetCachedValue("DATABASE_PASSWORD", "production")
+	assert.True(t, ok)
+	assert.Equal(t, "rotated", v)
+	assert.Equal(t, "", client.SnapshotETag("production"), "snapshot is dropped after a write")
+}
+
+func TestSetValues_ConflictWritesNothing(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		w.Write([]byte("etag mismatch"))
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	err := client.SetValues("production", map[string]any{"A": "1"}, WithIfMatch(`"stale"`))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrWriteConflict))
+	assert.ErrorContains(t, err, "etag mismatch")
+	_, ok := client.GetCachedValue("A", "production")
+	assert.False(t, ok)
+}
+
+func TestSetValues_ServerError(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	err := client.SetValues("production", map[string]any{"A": "1"})
+	assert.ErrorIs(t, err, ErrServerError)
+	assert.False(t, errors.Is(err, ErrWriteConflict))
+}
+
+func TestSetValues_EmptyIsNoop(t *testing.T) {
+	client := NewConfigClient("http://127.0.0.1:0", "cid", "sec", testOrgID)
+	defer client.Close()
+	assert.NoError(t, client.SetValues("production", nil))
+}
+
+func TestSetValues_ValidatesAgainstDefinition(t *testing.T) {
+	requests := 0
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	def := DefineConfig(
+		map[string]any{"type": "object", "properties": map[string]any{
+			"maxRetries": map[string]any{"type": "integer", "minimum": 0},
+		}},
+		map[string]any{"type": "object", "properties": map[string]any{
+			"DB_PASSWORD": map[string]any{"type": "string", "minLength": 12},
+		}},
+		nil,
+	)
+	client := newUnitClient(t, server.URL, WithClientDefinition(def))
+	defer client.Close()
+
+	err := client.SetValues("production", map[string]any{
+		"MAX_RETRIES": -1,
+		"DB_PASSWORD": "short",
+		"UNDECLARED":  []any{"anything"},
+	})
+	var schemaErr *SchemaViolationError
+	require.ErrorAs(t, err, &schemaErr)
+	assert.True(t, errors.Is(err, ErrSchemaViolation))
+	assert.Equal(t, []SchemaViolation{
+		{Path: "/public/MAX_RETRIES", Expected: "minimum 0", Actual: -1},
+		{Path: "/secret/DB_PASSWORD", Expected: "minLength 12", Actual: redactedValue},
+	}, schemaErr.Violations)
+	assert.Zero(t, requests, "nothing is sent")
+
+	require.NoError(t, client.SetValues("production", map[string]any{"MAX_RETRIES": 3, "UNDECLARED": 1}))
+	assert.Equal(t, 1, requests)
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// flightGroup coalesces concurrent calls that share a key into a single
+// execution — a minimal, dependency-free take on
+// golang.org/x/sync/singleflight. ConfigClient uses it so a cold cache under
+// high concurrency issues one HTTP request per (environment, key) instead of
+// a thundering herd of identical fetches.
+//
+// The zero value is ready to use.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg  sync.WaitGroup
+	val any
+	err error
+	// panicked is set when fn panicked; every caller re-panics with it.
+	panicked *flightPanic
+	// dups counts callers that joined while the call was in flight.
+	dups int
+}
+
+// Do executes fn once for all concurrent callers with the same key. Callers
+// that arrive while fn is running block and receive the same result; shared
+// reports whether the result was handed to more than one caller. If fn
+// panics, every caller panics with a *flightPanic carrying the value and
+// fn's stack; if fn calls runtime.Goexit, the waiters get errFlightAborted.
+// A nil error therefore always comes with the value fn returned.
+func (g *flightGroup) Do(key string, fn func() (any, error)) (val any, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		if c.panicked != nil {
+			panic(c.panicked)
+		}
+		return c.val, c.err, true
+	}
+	c := &flightCall{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	// Release waiters and clear the slot even if fn panics, so later
+	// callers don't deadlock on a call that will never finish.
+	returned := false
+	defer func() {
+		if !returned {
+			if r := recover(); r != nil {
+				c.panicked = &flightPanic{value: r, stack: debug.Stack()}
+			} else {
+				c.err = errFlightAborted
+			}
+		}
+		g.mu.Lock()
+		shared = c.dups > 0
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+		if c.panicked != nil {
+			panic(c.panicked)
+		}
+	}()
+	c.val, c.err = fn()
+	returned = true
+	return c.val, c.err, false
+}
+
+// errFlightAborted is what waiters get when the call they joined exited
+// through runtime.Goexit.
+var errFlightAborted = errors.New("config: coalesced call exited without returning")
+
+// flightPanic is the panic value Do re-raises when fn panicked.
+type flightPanic struct {
+	value any
+	stack []byte
+}
+
+func (p *flightPanic) Error() string {
+	return fmt.Sprintf("%v\n\n%s", p.value, p.stack)
+}
+
+func (p *flightPanic) Unwrap() error {
+	err, _ := p.value.(error)
+	return err
+}
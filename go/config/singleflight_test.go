@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlightGroup_CoalescesConcurrentCalls(t *testing.T) {
+	var g flightGroup
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	const goroutines = 10
+	var wg sync.WaitGroup
+	results := make([]any, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err, _ := g.Do("k", func() (any, error) {
+				calls.Add(1)
+				<-release
+				return "v", nil
+			})
+			assert.NoError(t, err)
+			results[i] = v
+		}(i)
+	}
+	// Give the goroutines time to pile onto the in-flight call.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, r := range results {
+		assert.Equal(t, "v", r)
+	}
+}
+
+func TestFlightGroup_SequentialCallsRunAgain(t *testing.T) {
+	var g flightGroup
+	calls := 0
+	for i := 0; i < 3; i++ {
+		_, _, shared := g.Do("k", func() (any, error) {
+			calls++
+			return nil, nil
+		})
+		assert.False(t, shared)
+	}
+	assert.Equal(t, 3, calls)
+}
+
+func TestFlightGroup_PropagatesError(t *testing.T) {
+	var g flightGroup
+	boom := errors.New("boom")
+	_, err, _ := g.Do("k", func() (any, error) { return nil, boom })
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestFlightGroup_PanicReachesEveryCaller(t *testing.T) {
+	var g flightGroup
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	go func() {
+		defer func() { recover() }()
+		g.Do("k", func() (any, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+
+	waiter := make(chan any)
+	go func() {
+		defer func() { waiter <- recover() }()
+		g.Do("k", func() (any, error) { return "unused", nil })
+	}()
+	// Give the waiter time to join the in-flight call.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	p, ok := (<-waiter).(*flightPanic)
+	if assert.True(t, ok, "waiter should re-panic") {
+		assert.Equal(t, "boom", p.value)
+		assert.Contains(t, p.Error(), "boom")
+	}
+
+	// The slot is cleared, so the next call runs fn again.
+	v, err, _ := g.Do("k", func() (any, error) { return "v", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "v", v)
+}
+
+func TestGetAllValues_ConcurrentColdCacheSingleRequest(t *testing.T) {
+	var requests atomic.Int32
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"A": "1"}})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values, err := client.GetAllValues("production")
+			assert.NoError(t, err)
+			assert.Equal(t, "1", values["A"])
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestGetValue_ConcurrentColdCacheSingleRequest(t *testing.T) {
+	var requests atomic.Int32
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		json.NewEncoder(w).Encode(valueResponse{Value: "v"})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := client.GetValue("KEY", "production")
+			assert.NoError(t, err)
+			assert.Equal(t, "v", v)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load())
+}
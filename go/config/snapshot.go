@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// snapshotVersion is the Snapshot format written by Snapshot.
+const snapshotVersion = 1
+
+// Snapshot is a manager's resolved config at one moment, with each key's
+// tier, for replaying later with LoadSnapshot: to see what config an incident
+// ran with, or to run where no source is reachable. It marshals to JSON.
+// Secrets are held in the clear, so store snapshots like secrets.
+type Snapshot struct {
+	Version     int                      `json:"version"`
+	Environment string                   `json:"environment"`
+	TakenAt     time.Time                `json:"takenAt"`
+	Values      map[string]SnapshotValue `json:"values"`
+}
+
+// SnapshotValue is one key of a Snapshot.
+type SnapshotValue struct {
+	Value any `json:"value"`
+	// Tier is the key's tier, empty when the manager didn't know it.
+	Tier ConfigTier `json:"tier,omitempty"`
+}
+
+// Snapshot returns the merged config, every layer resolved, loading it on
+// first use like the Get* methods. The WithSecretAccessPolicy policy must
+// allow a read of every key not known to be public or a feature flag.
+func (m *ConfigManager) Snapshot() (*Snapshot, error) {
+	m.mu.Lock()
+	if err := m.initialize(); err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	snap := &Snapshot{
+		Version:     snapshotVersion,
+		Environment: m.configEnvironment(),
+		TakenAt:     time.Now().UTC(),
+		Values:      make(map[string]SnapshotValue, len(m.config)),
+	}
+	var secrets []string
+	for key, value := range m.config {
+		snap.Values[key] = SnapshotValue{Value: cloneValue(value), Tier: m.keyTierLocked(key)}
+		if !m.revealableLocked(key) {
+			secrets = append(secrets, key)
+		}
+	}
+	m.mu.Unlock()
+	if err := m.checkSecretAccessAll(secrets); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// LoadSnapshot replaces every source with the Snapshot JSON in data, so the
+// manager serves exactly the config it was taken from. Only overrides
+// (SetOverride) still apply on top. The snapshot's tiers are enforced like
+// the API's tier metadata. Change listeners are told about the keys that
+// differ from the config served before.
+func (m *ConfigManager) LoadSnapshot(data []byte) error {
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return NewConfigError(fmt.Sprintf("invalid config snapshot: %v", err))
+	}
+	if snap.Version != snapshotVersion {
+		return NewConfigError(fmt.Sprintf("unsupported config snapshot version %d", snap.Version))
+	}
+	if snap.Values == nil {
+		snap.Values = map[string]SnapshotValue{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshot = &snap
+	wasInitialized := m.initialized
+	m.resetLocked()
+	if !wasInitialized {
+		return nil
+	}
+	return m.initialize()
+}
+
+// initializeFromSnapshotLocked is initialize for a LoadSnapshot manager.
+// The values stand in for the remote layer, so overrides re-merge as usual.
+// Must be called under m.mu.
+func (m *ConfigManager) initializeFromSnapshotLocked() error {
+	values := make(map[string]any, len(m.snapshot.Values))
+	tiers := make(map[string]ConfigTier)
+	for key, v := range m.snapshot.Values {
+		values[key] = v.Value
+		if v.Tier.IsValid() {
+			tiers[key] = v.Tier
+		}
+	}
+	merged := make(map[string]any, len(values))
+	for key, value := range values {
+		merged[key] = cloneValue(value)
+	}
+	m.applyOverridesLocked(merged)
+
+	if m.previousConfig != nil {
+		m.notifyChangeLocked(changedKeys(m.previousConfig, merged), merged)
+		m.previousConfig = nil
+	}
+	m.config = merged
+	m.fileConfig, m.ssmConfig, m.kvConfig, m.envConfig = nil, nil, nil, nil
+	m.remoteConfig = values
+	m.remoteTiers = tiers
+	m.loadErrs = nil
+	m.initialized = true
+	m.validateLocked()
+	return m.schemaErr
+}
+
+// cloneValue deep-copies the objects and arrays in v.
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = cloneValue(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = cloneValue(e)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+		"API_URL":     "https://file",
+		"DB_PASSWORD": "hunter2",
+		"LIMITS":      map[string]any{"rps": 10},
+	}})
+	live := NewConfigManager(
+		WithCMKeyTiers(map[string]ConfigTier{"API_URL": TierPublic, "DB_PASSWORD": TierSecret}),
+		WithCMSchemaKeys(map[string]bool{"API_URL": true}),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir, "API_URL": "https://env"}),
+	)
+	snap, err := live.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, SnapshotValue{Value: "https://env", Tier: TierPublic}, snap.Values["API_URL"])
+	assert.Equal(t, SnapshotValue{Value: "hunter2", Tier: TierSecret}, snap.Values["DB_PASSWORD"])
+	data, err := json.Marshal(snap)
+	require.NoError(t, err)
+
+	// Replayed with none of the original sources.
+	replay := NewConfigManager(WithCMEnvOverride(map[string]string{"API_URL": "https://ignored"}))
+	defer replay.Close(context.Background())
+	require.NoError(t, replay.LoadSnapshot(data))
+	v, err := replay.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://env", v)
+	v, err = replay.GetPublicConfig("LIMITS")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"rps": float64(10)}, v)
+
+	_, err = replay.GetPublicConfig("DB_PASSWORD")
+	var wrongTier *WrongTierError
+	require.True(t, errors.As(err, &wrongTier))
+	v, err = replay.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	replay.SetOverride("API_URL", "https://override", 0)
+	v, err = replay.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://override", v)
+}
+
+func TestLoadSnapshot_NotifiesChanges(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{}))
+	defer mgr.Close(context.Background())
+	require.NoError(t, mgr.LoadSnapshot([]byte(`{"version":1,"values":{"A":{"value":"1"},"B":{"value":"1"}}}`)))
+	_, err := mgr.Has("A")
+	require.NoError(t, err)
+
+	changed := make(chan []string, 1)
+	mgr.addChangeListener(func(evt ConfigChangeEvent) { changed <- evt.Keys })
+	require.NoError(t, mgr.LoadSnapshot([]byte(`{"version":1,"values":{"A":{"value":"2"},"B":{"value":"1"}}}`)))
+	assert.Equal(t, []string{"A"}, <-changed)
+}
+
+func TestLoadSnapshot_Invalid(t *testing.T) {
+	mgr := NewConfigManager()
+	assert.Error(t, mgr.LoadSnapshot([]byte(`not json`)))
+	assert.ErrorContains(t, mgr.LoadSnapshot([]byte(`{"version":2,"values":{}}`)), "unsupported config snapshot version 2")
+}
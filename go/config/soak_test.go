@@ -0,0 +1,218 @@
+//go:build soak
+
+package config
+
+// Soak test for the background subsystems (subscriptions and their
+// reconnects, file watching, change listeners, reloads). It runs for
+// SMOOAI_SOAK_DURATION (default 10m) and fails if goroutine counts or heap
+// size grow after warm-up:
+//
+//	go test -tags soak -run Soak -timeout 0 -v ./...
+//	SMOOAI_SOAK_DURATION=4h go test -tags soak -run Soak -timeout 0 -v ./...
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// soakGoroutineSlack and soakHeapSlack bound growth over the warm-up sample.
+const (
+	soakGoroutineSlack = 25
+	soakHeapSlack      = 32 << 20
+)
+
+// soakServer serves tokens, values and an SSE stream that pushes a change
+// every few milliseconds and drops the connection after a handful, so
+// subscribers reconnect constantly.
+func soakServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var version atomic.Int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": mockJWT, "expires_in": 3600})
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		for i := range 5 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+			v := version.Add(1)
+			fmt.Fprintf(w, "id: %d\nevent: change\ndata: {\"keys\":[\"API_URL\",\"TIMEOUT\"],\"values\":{\"API_URL\":\"v%d\"}}\n\n", v, i)
+			w.(http.Flusher).Flush()
+		}
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values/", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(valueResponse{Value: version.Load()})
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{
+			"API_URL": fmt.Sprintf("v%d", version.Load()),
+			"TIMEOUT": 30,
+		}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func soakDuration(t *testing.T) time.Duration {
+	t.Helper()
+	if v := os.Getenv("SMOOAI_SOAK_DURATION"); v != "" {
+		d, err := time.ParseDuration(v)
+		require.NoError(t, err, "SMOOAI_SOAK_DURATION")
+		return d
+	}
+	return 10 * time.Minute
+}
+
+type soakSample struct {
+	goroutines int
+	heap       uint64
+}
+
+func takeSoakSample() soakSample {
+	runtime.GC()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return soakSample{goroutines: runtime.NumGoroutine(), heap: mem.HeapAlloc}
+}
+
+func TestSoak_BackgroundSubsystems(t *testing.T) {
+	duration := soakDuration(t)
+	origBackoff, origWatch := subscribeBackoff, fileWatchInterval
+	subscribeBackoff.initial, subscribeBackoff.max = time.Millisecond, 20*time.Millisecond
+	fileWatchInterval = 20 * time.Millisecond
+	t.Cleanup(func() { subscribeBackoff, fileWatchInterval = origBackoff, origWatch })
+
+	server := soakServer(t)
+	configDir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"TIMEOUT": 10, "REGION": "us"}})
+	env := map[string]string{
+		"SMOOAI_CONFIG_AUTH_URL": server.URL,
+		"SMOOAI_ENV_CONFIG_DIR":  configDir,
+	}
+	newManager := func(opts ...ConfigManagerOption) *ConfigManager {
+		return NewConfigManager(append([]ConfigManagerOption{
+			WithAPIKey("test-key"),
+			WithBaseURL(server.URL),
+			WithOrgID(testOrgID),
+			WithConfigEnvironment("production"),
+			WithCMEnvOverride(env),
+		}, opts...)...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	var wg sync.WaitGroup
+	var ops atomic.Int64
+	worker := func(name string, step func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ctx.Err() == nil; i++ {
+				step(i)
+				ops.Add(1)
+			}
+			t.Logf("%s: done", name)
+		}()
+	}
+
+	// A long-lived manager with a live subscription, a file watch and
+	// listeners, read, overridden and invalidated continuously.
+	var changes atomic.Int64
+	mgr := newManager(WithCMFileWatch())
+	defer mgr.Close(context.Background())
+	mgr.addChangeListener(func(ConfigChangeEvent) { changes.Add(1) })
+	sub, err := mgr.Subscribe(ctx)
+	require.NoError(t, err)
+	defer sub.Close()
+	go func() {
+		for range sub.Events() {
+		}
+	}()
+
+	worker("reads", func(i int) {
+		_, err := mgr.GetPublicConfig("API_URL")
+		assert.NoError(t, err)
+		if i%50 == 0 {
+			mgr.Invalidate()
+		}
+	})
+	worker("overrides", func(i int) {
+		if i%2 == 0 {
+			mgr.SetOverride("TIMEOUT", i, 0)
+		} else {
+			mgr.ClearOverride("TIMEOUT")
+		}
+		time.Sleep(time.Millisecond)
+	})
+	worker("file edits", func(i int) {
+		data := fmt.Sprintf(`{"TIMEOUT": 10, "REGION": "us", "EDIT": %d}`, i)
+		assert.NoError(t, os.WriteFile(filepath.Join(configDir, "default.json"), []byte(data), 0o644))
+		time.Sleep(50 * time.Millisecond)
+	})
+	// Short-lived clients, subscriptions and watchers, each torn down.
+	worker("subscription churn", func(int) {
+		client := NewConfigClient(server.URL, "cid", "secret", testOrgID, WithAuthURL(server.URL), WithRing(""))
+		sub, err := client.Subscribe(ctx, "production")
+		if err == nil {
+			select {
+			case <-sub.Events():
+			case <-ctx.Done():
+			}
+			sub.Close()
+		}
+		client.Close()
+	})
+	worker("manager churn", func(int) {
+		m := newManager(WithCMFileWatch())
+		_, _ = m.GetPublicConfig("TIMEOUT")
+		assert.NoError(t, m.Close(context.Background()))
+	})
+
+	sampleEvery := max(duration/20, time.Second)
+	var baseline soakSample
+	ticker := time.NewTicker(sampleEvery)
+	defer ticker.Stop()
+	for n := 1; ; n++ {
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+			s := takeSoakSample()
+			t.Logf("sample %d: %d goroutines, %d KiB heap, %d ops, %d changes",
+				n, s.goroutines, s.heap>>10, ops.Load(), changes.Load())
+			if n == 2 {
+				// The first interval includes start-up; measure from the second.
+				baseline = s
+			}
+			continue
+		}
+		break
+	}
+	wg.Wait()
+
+	final := takeSoakSample()
+	t.Logf("final: %d goroutines, %d KiB heap, %d ops", final.goroutines, final.heap>>10, ops.Load())
+	if baseline.goroutines == 0 {
+		t.Skip("too short to take a baseline; raise SMOOAI_SOAK_DURATION")
+	}
+	assert.Positive(t, changes.Load(), "change listeners ran")
+	assert.LessOrEqual(t, final.goroutines, baseline.goroutines+soakGoroutineSlack, "goroutines leaked")
+	assert.LessOrEqual(t, final.heap, 2*baseline.heap+soakHeapSlack, "heap grew")
+}
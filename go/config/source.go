@@ -0,0 +1,193 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// sourceLoadTimeout bounds one Load of a WithSource layer.
+const sourceLoadTimeout = 10 * time.Second
+
+// Priorities of the built-in layers. A WithSource layer merges above every
+// built-in layer whose priority is at most its own, so a source at
+// PriorityRemote+1 sits between the remote API and env vars. Overrides
+// (SetOverride) always win.
+const (
+	PriorityFile   = 100
+	PrioritySSM    = 200
+	PriorityKV     = 300
+	PriorityRemote = 400
+	PriorityEnv    = 500
+)
+
+// Source is a custom config layer, such as a database table or an internal
+// API, registered with WithSource.
+type Source interface {
+	// Load returns the source's values by config key. Nested objects merge
+	// like file config.
+	Load(ctx context.Context) (map[string]any, error)
+}
+
+// WatchableSource is a Source that can report its own changes. The manager
+// watches it until Close, which cancels ctx.
+type WatchableSource interface {
+	Source
+	// Watch returns a channel that receives a SourceChange whenever the
+	// source's values change. Closing the channel ends the watch.
+	Watch(ctx context.Context) (<-chan SourceChange, error)
+}
+
+// SourceChange reports a change to a WatchableSource.
+type SourceChange struct {
+	// Keys are the keys that changed. With keys, only the source is loaded
+	// again and only those keys are re-merged; without, the whole config is
+	// reloaded.
+	Keys []string
+}
+
+// WithSource merges src as a layer at priority, relative to the built-in
+// layers' Priority* constants. Sources at the same priority merge in the
+// order they were added, above the built-in layer sharing it. When a load
+// fails, the last values loaded are kept, with a warning. A WatchableSource
+// is watched, and each change rebuilds the merged config and notifies change
+// listeners, as with WithCMKVWatch.
+func WithSource(priority int, src Source) ConfigManagerOption {
+	return func(m *ConfigManager) { m.sources = append(m.sources, &sourceLayer{src: src, priority: priority}) }
+}
+
+// sourceLayer is a WithSource layer.
+type sourceLayer struct {
+	src      Source
+	priority int
+	// last is the last successful load, served when a load fails.
+	last map[string]any
+}
+
+func (s *sourceLayer) load() (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sourceLoadTimeout)
+	defer cancel()
+	values, err := s.src.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = map[string]any{}
+	}
+	return values, nil
+}
+
+// loadSourcesLocked loads every WithSource layer, keeping the last good
+// values of one that fails. Must be called under m.mu.
+func (m *ConfigManager) loadSourcesLocked() {
+	for _, s := range m.sources {
+		values, err := s.load()
+		if err != nil {
+			m.log().Warn("failed to load config source", "source", fmt.Sprintf("%T", s.src), "error", err)
+			m.recordLoadErrLocked(fmt.Sprintf("source %T", s.src), err)
+			continue
+		}
+		s.last = values
+	}
+}
+
+// configLayer is one layer of the merged config.
+type configLayer struct {
+	name     string
+	priority int
+	values   map[string]any
+}
+
+// layersLocked returns the layers to merge, lowest priority first: the
+// built-in ones with the WithSource layers placed among them. Must be called
+// under m.mu.
+func (m *ConfigManager) layersLocked(file, ssm, kv, remote, env map[string]any) []configLayer {
+	layers := []configLayer{
+		{"file", PriorityFile, file},
+		{"ssm", PrioritySSM, ssm},
+		{"kv", PriorityKV, kv},
+		{"remote", PriorityRemote, remote},
+		{"env", PriorityEnv, env},
+	}
+	for _, s := range m.sources {
+		if m.snapshot != nil {
+			break // a snapshot replaces every source
+		}
+		layers = append(layers, configLayer{fmt.Sprintf("source %T", s.src), s.priority, s.last})
+	}
+	// Stable, so built-in layers stay below sources at their priority and
+	// sources keep the order they were added in.
+	slices.SortStableFunc(layers, func(a, b configLayer) int { return cmp.Compare(a.priority, b.priority) })
+	return layers
+}
+
+// startSourceWatches watches every WatchableSource until Close.
+func (m *ConfigManager) startSourceWatches() {
+	for _, s := range m.sources {
+		ws, ok := s.src.(WatchableSource)
+		if !ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		changes, err := ws.Watch(ctx)
+		if err != nil {
+			cancel()
+			m.log().Warn("failed to watch config source", "source", fmt.Sprintf("%T", s.src), "error", err)
+			continue
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case change, ok := <-changes:
+					if !ok {
+						return
+					}
+					m.applySourceChange(s, change)
+				}
+			}
+		}()
+		_, _ = m.components.register("source watch", func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// applySourceChange brings the merged config up to date with a change to s.
+func (m *ConfigManager) applySourceChange(s *sourceLayer, change SourceChange) {
+	var (
+		values map[string]any
+		err    error
+	)
+	if len(change.Keys) > 0 {
+		// Load before taking the lock, so reads aren't held up by it.
+		values, err = s.load()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.initialized {
+		return // the next read loads the source anyway
+	}
+	if len(change.Keys) == 0 || m.dependentValuesLocked() {
+		m.resetLocked()
+		if err := m.initialize(); err != nil {
+			m.log().Warn("reload after config source change failed", "error", err)
+		}
+		return
+	}
+	if err != nil {
+		m.log().Warn("failed to load config source", "source", fmt.Sprintf("%T", s.src), "error", err)
+		return
+	}
+	s.last = values
+	for _, key := range change.Keys {
+		m.remergeKeyLocked(key)
+	}
+}
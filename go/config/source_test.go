@@ -0,0 +1,125 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memSource is a WatchableSource backed by a map.
+type memSource struct {
+	mu      sync.Mutex
+	values  map[string]any
+	err     error
+	changes chan SourceChange
+}
+
+func newMemSource(values map[string]any) *memSource {
+	return &memSource{values: values, changes: make(chan SourceChange, 1)}
+}
+
+func (s *memSource) Load(context.Context) (map[string]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return maps.Clone(s.values), nil
+}
+
+func (s *memSource) Watch(context.Context) (<-chan SourceChange, error) { return s.changes, nil }
+
+func (s *memSource) set(key string, value any, notify SourceChange) {
+	s.mu.Lock()
+	s.values[key] = value
+	s.mu.Unlock()
+	s.changes <- notify
+}
+
+func TestWithSource_Priority(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+		"BELOW_FILE": "file",
+		"ABOVE_FILE": "file",
+	}})
+	low := newMemSource(map[string]any{"BELOW_FILE": "low", "ONLY_LOW": "low"})
+	mid := newMemSource(map[string]any{"ABOVE_FILE": "mid", "FROM_ENV": "mid"})
+	mid2 := newMemSource(map[string]any{"ABOVE_FILE": "mid2"})
+	top := newMemSource(map[string]any{"FROM_ENV": "top"})
+	mgr := NewConfigManager(
+		WithSource(PriorityFile-1, low),
+		WithSource(PriorityFile, mid),
+		WithSource(PriorityFile, mid2),
+		WithSource(PriorityEnv, top),
+		WithCMSchemaKeys(map[string]bool{"FROM_ENV": true}),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir, "FROM_ENV": "env"}),
+	)
+	defer mgr.Close(context.Background())
+
+	for key, want := range map[string]any{
+		"BELOW_FILE": "file",
+		"ONLY_LOW":   "low",
+		"ABOVE_FILE": "mid2",
+		"FROM_ENV":   "top",
+	} {
+		v, err := mgr.GetPublicConfig(key)
+		require.NoError(t, err)
+		assert.Equal(t, want, v, key)
+	}
+}
+
+func TestWithSource_KeepsLastGoodLoad(t *testing.T) {
+	src := newMemSource(map[string]any{"API_URL": "https://v1"})
+	mgr := NewConfigManager(WithSource(PriorityRemote, src), WithCMEnvOverride(map[string]string{}))
+	defer mgr.Close(context.Background())
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://v1", v)
+
+	src.mu.Lock()
+	src.err = errors.New("database down")
+	src.mu.Unlock()
+	mgr.Invalidate()
+	v, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://v1", v)
+}
+
+func TestWithSource_Watch(t *testing.T) {
+	src := newMemSource(map[string]any{"API_URL": "https://v1", "MODE": "a"})
+	mgr := NewConfigManager(WithSource(PriorityRemote, src), WithCMEnvOverride(map[string]string{}))
+	defer mgr.Close(context.Background())
+	changed := make(chan []string, 2)
+	mgr.addChangeListener(func(evt ConfigChangeEvent) { changed <- evt.Keys })
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://v1", v)
+
+	// With keys, only those are re-merged.
+	src.set("API_URL", "https://v2", SourceChange{Keys: []string{"API_URL"}})
+	select {
+	case keys := <-changed:
+		assert.Equal(t, []string{"API_URL"}, keys)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change for a keyed source change")
+	}
+	v, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://v2", v)
+
+	// Without keys, the whole config is reloaded.
+	src.set("MODE", "b", SourceChange{})
+	select {
+	case keys := <-changed:
+		assert.Equal(t, []string{"MODE"}, keys)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change for a full source change")
+	}
+}
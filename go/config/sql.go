@@ -0,0 +1,358 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// SQLOption configures OpenSQL.
+type SQLOption func(*sqlOptions)
+
+type sqlOptions struct {
+	driver      string
+	dsn         func(driverName string, cred Credential) (string, error)
+	onReconnect func()
+}
+
+// WithSQLDriver sets the database/sql driver name, overriding the config
+// object's "driver" field and a connection URL's scheme.
+func WithSQLDriver(name string) SQLOption {
+	return func(o *sqlOptions) { o.driver = name }
+}
+
+// WithSQLDSN replaces the built-in DSN formats, for drivers that take
+// neither a connection URL nor the MySQL form.
+func WithSQLDSN(fn func(driverName string, cred Credential) (string, error)) SQLOption {
+	return func(o *sqlOptions) { o.dsn = fn }
+}
+
+// WithSQLReconnectHandler calls fn, on its own goroutine, after a config
+// change switched the pool to new connection settings.
+func WithSQLReconnectHandler(fn func()) SQLOption {
+	return func(o *sqlOptions) { o.onReconnect = fn }
+}
+
+// OpenSQL opens a *sql.DB from the connection settings stored under key,
+// read through the secret tier. The value is a credential bundle (see
+// Credential) with optional "driver" and "sslmode" (or boolean "ssl")
+// fields, or a connection URL whose scheme names the driver:
+//
+//	{"driver": "postgres", "host": "db-1.internal", "port": 5432, "username": "app",
+//	 "password": "s3cret", "database": "orders", "sslmode": "require"}
+//
+// The driver must already be registered (imported) by the caller. When key
+// changes, new connections use the new settings and pooled connections opened
+// with the old ones are closed as they go idle, so a rotated password or
+// failed-over host takes effect without reopening the pool.
+func OpenSQL(mgr *ConfigManager, key string, opts ...SQLOption) (*sql.DB, error) {
+	var o sqlOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	value, err := mgr.GetSecretConfig(key)
+	if err != nil {
+		return nil, err
+	}
+	driverName, dsn, err := sqlSettings(key, value, o)
+	destroySecret(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// sql.Open doesn't connect; it resolves the registered driver.
+	probe, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, NewConfigError(fmt.Sprintf("open SQL for config key %q: %v", key, err))
+	}
+	drv := probe.Driver()
+	_ = probe.Close()
+
+	c := &sqlConnector{drv: drv, dsn: dsn}
+	db := sql.OpenDB(c)
+	// Changes are delivered on separate goroutines and may run out of
+	// order; reading the key back under mu makes the last one to run set
+	// the latest DSN.
+	var mu sync.Mutex
+	mgr.addChangeListener(func(evt ConfigChangeEvent) {
+		if c.closed.Load() || !slices.Contains(evt.Keys, key) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		// Read the key back rather than using evt.Values, which holds the
+		// value before decryption (WithDecryptionKey).
+		value, err := mgr.GetSecretConfig(key)
+		if err == nil && value == nil {
+			mgr.log().Warn("config key was removed; keeping current SQL connection settings", "key", key)
+			return
+		}
+		var newDriver, newDSN string
+		if err == nil {
+			newDriver, newDSN, err = sqlSettings(key, value, o)
+			destroySecret(value)
+		}
+		if err != nil {
+			mgr.log().Warn("ignoring SQL connection settings", "error", err)
+			return
+		}
+		if newDriver != driverName {
+			mgr.log().Warn("config key changed the SQL driver; reopen the pool to switch drivers", "key", key, "driver", driverName, "new_driver", newDriver)
+			return
+		}
+		if c.setDSN(newDSN) && o.onReconnect != nil {
+			o.onReconnect()
+		}
+	})
+	return db, nil
+}
+
+// sqlSettings derives the driver name and DSN from a config value.
+func sqlSettings(key string, value any, o sqlOptions) (driverName, dsn string, err error) {
+	if s, ok := value.(*SecretString); ok { // WithSecretMemoryProtection
+		value = s.reveal()
+	}
+	if s, ok := value.(string); ok && strings.Contains(s, "://") && !strings.HasPrefix(strings.TrimSpace(s), "{") {
+		s = strings.TrimSpace(s)
+		u, perr := url.Parse(s)
+		if perr != nil {
+			return "", "", NewConfigError(fmt.Sprintf("config key %q is not a valid connection URL", key))
+		}
+		driverName = o.driver
+		if driverName == "" {
+			driverName = u.Scheme
+		}
+		if o.dsn == nil {
+			return driverName, s, nil
+		}
+	}
+
+	cred, err := parseCredential(key, value)
+	if err != nil {
+		return "", "", err
+	}
+	if driverName == "" {
+		driverName = o.driver
+	}
+	if driverName == "" {
+		driverName, _ = cred.Extra["driver"].(string)
+	}
+	if driverName == "" {
+		return "", "", NewConfigError(fmt.Sprintf("config key %q names no SQL driver; add a \"driver\" field or use WithSQLDriver", key))
+	}
+	if o.dsn != nil {
+		dsn, err = o.dsn(driverName, cred)
+		return driverName, dsn, err
+	}
+	return driverName, buildDSN(driverName, cred), nil
+}
+
+// buildDSN formats cred for driverName: the MySQL driver's
+// user:pass@tcp(host:port)/db form, and a connection URL for everything
+// else (lib/pq, pgx and most other drivers accept one).
+func buildDSN(driverName string, cred Credential) string {
+	host := cred.Host
+	if cred.Port != 0 {
+		host = net.JoinHostPort(host, strconv.Itoa(cred.Port))
+	}
+	ssl, hasSSL := sqlSSLMode(cred.Extra)
+
+	if driverName == "mysql" {
+		dsn := cred.Username
+		if cred.Password != "" {
+			dsn += ":" + cred.Password
+		}
+		dsn += "@tcp(" + host + ")/" + cred.Database
+		if hasSSL {
+			tls := "true"
+			if ssl == "disable" {
+				tls = "false"
+			}
+			dsn += "?tls=" + tls
+		}
+		return dsn
+	}
+
+	scheme := driverName
+	if scheme == "pgx" {
+		scheme = "postgres"
+	}
+	u := &url.URL{Scheme: scheme, User: url.UserPassword(cred.Username, cred.Password), Host: host, Path: "/" + cred.Database}
+	if hasSSL {
+		u.RawQuery = url.Values{"sslmode": {ssl}}.Encode()
+	}
+	return u.String()
+}
+
+// sqlSSLMode reads "sslmode", or maps a boolean "ssl" to require/disable.
+func sqlSSLMode(extra map[string]any) (string, bool) {
+	if mode, ok := extra["sslmode"].(string); ok && mode != "" {
+		return mode, true
+	}
+	switch ssl := extra["ssl"].(type) {
+	case bool:
+		if ssl {
+			return "require", true
+		}
+		return "disable", true
+	case string:
+		if on, err := strconv.ParseBool(ssl); err == nil {
+			return sqlSSLMode(map[string]any{"ssl": on})
+		}
+	}
+	return "", false
+}
+
+// sqlConnector opens connections with the current DSN. Each connection
+// remembers the generation it was opened in; once the DSN changes, older
+// connections report themselves invalid and the pool closes them instead of
+// reusing them.
+type sqlConnector struct {
+	drv driver.Driver
+
+	mu         sync.Mutex
+	dsn        string
+	generation uint64
+
+	closed atomic.Bool
+}
+
+func (c *sqlConnector) current() (string, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dsn, c.generation
+}
+
+// setDSN switches to dsn, reporting whether it differs from the current one.
+func (c *sqlConnector) setDSN(dsn string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if dsn == c.dsn {
+		return false
+	}
+	c.dsn = dsn
+	c.generation++
+	return true
+}
+
+func (c *sqlConnector) stale(generation uint64) bool {
+	_, current := c.current()
+	return generation != current
+}
+
+// Connect implements driver.Connector.
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn, generation := c.current()
+	var (
+		conn driver.Conn
+		err  error
+	)
+	if dc, ok := c.drv.(driver.DriverContext); ok {
+		var connector driver.Connector
+		if connector, err = dc.OpenConnector(dsn); err == nil {
+			conn, err = connector.Connect(ctx)
+		}
+	} else {
+		conn, err = c.drv.Open(dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{Conn: conn, stale: func() bool { return c.stale(generation) }}, nil
+}
+
+// Driver implements driver.Connector.
+func (c *sqlConnector) Driver() driver.Driver { return c.drv }
+
+// Close is called by sql.DB.Close and stops following config changes.
+func (c *sqlConnector) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+// sqlConn wraps a driver connection so the pool can retire it once the
+// connection settings change. The optional driver interfaces are forwarded
+// when the underlying connection implements them; otherwise each method
+// falls back the way database/sql would without them.
+type sqlConn struct {
+	driver.Conn
+	stale func() bool
+}
+
+// IsValid implements driver.Validator.
+func (c *sqlConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok && !v.IsValid() {
+		return false
+	}
+	return !c.stale()
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if c.stale() {
+		return driver.ErrBadConn
+	}
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// Ping implements driver.Pinger.
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts.ReadOnly || opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, NewConfigError("SQL driver does not support transaction options")
+	}
+	return c.Conn.Begin()
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
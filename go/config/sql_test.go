@@ -0,0 +1,166 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSQLDriver records the DSN of every connection it opens.
+type fakeSQLDriver struct {
+	mu   sync.Mutex
+	dsns []string
+}
+
+func (d *fakeSQLDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dsns = append(d.dsns, dsn)
+	return fakeSQLConn{}, nil
+}
+
+func (d *fakeSQLDriver) opened() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.dsns...)
+}
+
+type fakeSQLConn struct{}
+
+func (fakeSQLConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeSQLConn) Close() error                        { return nil }
+func (fakeSQLConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+var testSQLDriver = &fakeSQLDriver{}
+
+func init() { sql.Register("smooai-fake-sql", testSQLDriver) }
+
+func TestOpenSQL_SwapsSettingsOnChange(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{
+			"default.json": map[string]any{"DATABASE": map[string]any{
+				"driver": "smooai-fake-sql", "host": "db-1", "port": 5432, "username": "app",
+				"password": "v1", "database": "orders", "sslmode": "require",
+			}},
+		}),
+	}))
+
+	reconnected := make(chan struct{}, 1)
+	db, err := OpenSQL(mgr, "DATABASE", WithSQLReconnectHandler(func() { reconnected <- struct{}{} }))
+	require.NoError(t, err)
+	defer db.Close()
+
+	before := len(testSQLDriver.opened())
+	require.NoError(t, db.PingContext(context.Background()))
+	opened := testSQLDriver.opened()
+	require.Len(t, opened, before+1)
+	assert.Equal(t, "smooai-fake-sql://app:v1@db-1:5432/orders?sslmode=require", opened[before])
+
+	mgr.SetOverride("DATABASE", map[string]any{
+		"driver": "smooai-fake-sql", "host": "db-2", "port": 5432, "username": "app",
+		"password": "v2", "database": "orders",
+	}, 0)
+	select {
+	case <-reconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("reconnect handler not called")
+	}
+
+	// The idle connection from before the change is retired, not reused.
+	require.NoError(t, db.PingContext(context.Background()))
+	opened = testSQLDriver.opened()
+	assert.Equal(t, "smooai-fake-sql://app:v2@db-2:5432/orders", opened[len(opened)-1])
+}
+
+func TestSQLSettings(t *testing.T) {
+	cases := []struct {
+		name       string
+		value      any
+		opts       []SQLOption
+		wantDriver string
+		wantDSN    string
+	}{
+		{
+			name:       "connection URL",
+			value:      "postgres://app:s3cret@db:5432/orders?sslmode=disable",
+			wantDriver: "postgres",
+			wantDSN:    "postgres://app:s3cret@db:5432/orders?sslmode=disable",
+		},
+		{
+			name:       "driver option overrides scheme",
+			value:      "postgres://app:s3cret@db/orders",
+			opts:       []SQLOption{WithSQLDriver("pgx")},
+			wantDriver: "pgx",
+			wantDSN:    "postgres://app:s3cret@db/orders",
+		},
+		{
+			name:       "pgx uses the postgres scheme",
+			value:      map[string]any{"driver": "pgx", "host": "db", "username": "app", "password": "p@ss", "database": "orders", "ssl": true},
+			wantDriver: "pgx",
+			wantDSN:    "postgres://app:p%40ss@db/orders?sslmode=require",
+		},
+		{
+			name:       "mysql",
+			value:      `{"driver": "mysql", "host": "db", "port": 3306, "username": "app", "password": "s3cret", "database": "orders", "ssl": false}`,
+			wantDriver: "mysql",
+			wantDSN:    "app:s3cret@tcp(db:3306)/orders?tls=false",
+		},
+		{
+			name:  "custom DSN",
+			value: map[string]any{"driver": "sqlserver", "host": "db", "username": "sa", "password": "s3cret"},
+			opts: []SQLOption{WithSQLDSN(func(driverName string, cred Credential) (string, error) {
+				return "server=" + cred.Host + ";user id=" + cred.Username, nil
+			})},
+			wantDriver: "sqlserver",
+			wantDSN:    "server=db;user id=sa",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var o sqlOptions
+			for _, opt := range tc.opts {
+				opt(&o)
+			}
+			driverName, dsn, err := sqlSettings("DATABASE", tc.value, o)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantDriver, driverName)
+			assert.Equal(t, tc.wantDSN, dsn)
+
+			if s, ok := tc.value.(string); ok {
+				// WithSecretMemoryProtection hands out a *SecretString.
+				driverName, dsn, err = sqlSettings("DATABASE", NewSecretString(s), o)
+				require.NoError(t, err)
+				assert.Equal(t, tc.wantDriver, driverName)
+				assert.Equal(t, tc.wantDSN, dsn)
+			}
+		})
+	}
+}
+
+func TestOpenSQL_Errors(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{
+			"default.json": map[string]any{
+				"NO_DRIVER":  map[string]any{"host": "db", "username": "app", "password": "x"},
+				"UNKNOWN":    "nosuchdriver://app:x@db/orders",
+				"NOT_BUNDLE": "plain string",
+			},
+		}),
+	}))
+
+	_, err := OpenSQL(mgr, "NO_DRIVER")
+	assert.ErrorContains(t, err, "names no SQL driver")
+	_, err = OpenSQL(mgr, "UNKNOWN")
+	assert.ErrorContains(t, err, "nosuchdriver")
+	_, err = OpenSQL(mgr, "NOT_BUNDLE")
+	assert.ErrorContains(t, err, "not a credential bundle")
+	_, err = OpenSQL(mgr, "MISSING")
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ssmServer serves GetParametersByPath for /myapp/prod/ in two pages.
+func ssmServer(t *testing.T, fail *atomic.Bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail != nil && fail.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "AccessDeniedException", "message": "denied"})
+			return
+		}
+		assert.Equal(t, "AmazonSSM.GetParametersByPath", r.Header.Get("X-Amz-Target"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/ssm/aws4_request")
+		var in struct {
+			Path           string
+			Recursive      bool
+			WithDecryption bool
+			NextToken      string
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		assert.Equal(t, "/myapp/prod/", in.Path)
+		assert.True(t, in.Recursive)
+		assert.True(t, in.WithDecryption)
+
+		switch in.NextToken {
+		case "":
+			json.NewEncoder(w).Encode(map[string]any{
+				"Parameters": []map[string]string{
+					{"Name": "/myapp/prod/API_URL", "Type": "String", "Value": "https://ssm"},
+					{"Name": "/myapp/prod/DB_PASSWORD", "Type": "SecureString", "Value": "decrypted"},
+				},
+				"NextToken": "page2",
+			})
+		case "page2":
+			json.NewEncoder(w).Encode(map[string]any{
+				"Parameters": []map[string]string{
+					{"Name": "/myapp/prod/MAX_RETRIES", "Type": "String", "Value": "5"},
+					{"Name": "/myapp/prod/HOSTS", "Type": "StringList", "Value": "a,b"},
+					{"Name": "/myapp/prod/db/host", "Type": "String", "Value": "db.internal"},
+					{"Name": "/myapp/prod/FROM_ENV", "Type": "String", "Value": "ssm"},
+				},
+			})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestConfigManager_SSMParameters(t *testing.T) {
+	server := ssmServer(t, nil)
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+		"API_URL": "https://file",
+		"db":      map[string]any{"port": 5432},
+		"ONLY_IN": "file",
+	}})
+	mgr := NewConfigManager(
+		WithCMSSMParameters("/myapp/prod", WithAWSRegion("eu-west-1")),
+		WithCMSchemaKeys(map[string]bool{"FROM_ENV": true}),
+		WithCMSchemaTypes(map[string]string{"MAX_RETRIES": "number"}),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": dir,
+			"AWS_ACCESS_KEY_ID":     "AKID",
+			"AWS_SECRET_ACCESS_KEY": "secret",
+			"AWS_ENDPOINT_URL_SSM":  server.URL,
+			"FROM_ENV":              "env",
+		}),
+	)
+
+	for key, want := range map[string]any{
+		"API_URL":     "https://ssm",
+		"DB_PASSWORD": "decrypted",
+		"MAX_RETRIES": 5,
+		"HOSTS":       []any{"a", "b"},
+		"db":          map[string]any{"host": "db.internal", "port": 5432.0},
+		"ONLY_IN":     "file",
+		"FROM_ENV":    "env",
+	} {
+		v, err := mgr.GetPublicConfig(key)
+		require.NoError(t, err)
+		assert.Equal(t, want, v, key)
+	}
+}
+
+func TestConfigManager_SSMParametersBelowRemote(t *testing.T) {
+	server := ssmServer(t, nil)
+	remote := newMockCMServer("test-key", testOrgID, map[string]any{"API_URL": "https://remote"})
+	defer remote.close()
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}})
+	mgr := NewConfigManager(
+		WithAPIKey("test-key"),
+		WithBaseURL(remote.server.URL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMSSMParameters("/myapp/prod/"),
+		WithCMEnvOverride(remote.envOverride(map[string]string{
+			"SMOOAI_ENV_CONFIG_DIR": dir,
+			"SMOOAI_CONFIG_ENV":     "test",
+			"AWS_REGION":            "eu-west-1",
+			"AWS_ACCESS_KEY_ID":     "AKID",
+			"AWS_SECRET_ACCESS_KEY": "secret",
+			"AWS_ENDPOINT_URL_SSM":  server.URL,
+		})),
+	)
+	v, _ := mgr.GetPublicConfig("API_URL")
+	assert.Equal(t, "https://remote", v)
+	v, _ = mgr.GetPublicConfig("DB_PASSWORD")
+	assert.Equal(t, "decrypted", v)
+}
+
+func TestConfigManager_SSMParametersKeepLastGoodLoad(t *testing.T) {
+	var fail atomic.Bool
+	server := ssmServer(t, &fail)
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}})
+	mgr := NewConfigManager(
+		WithCMSSMParameters("/myapp/prod/", WithAWSRegion("eu-west-1"), WithAWSEndpoint(server.URL),
+			WithAWSCredentials(StaticAWSCredentials("AKID", "secret", ""))),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}),
+	)
+	v, _ := mgr.GetPublicConfig("API_URL")
+	assert.Equal(t, "https://ssm", v)
+
+	fail.Store(true)
+	mgr.Invalidate()
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://ssm", v)
+}
+
+func TestConfigManager_SSMParametersNeedRegion(t *testing.T) {
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"API_URL": "https://file"}})
+	mgr := NewConfigManager(
+		WithCMSSMParameters("/myapp/prod/"),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}),
+	)
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err, "a failed SSM load leaves the other layers")
+	assert.Equal(t, "https://file", v)
+}
+
+func TestRemergeKey_ResolvesSecretRefs(t *testing.T) {
+	vault := &vaultProvider{secrets: map[string]string{"vault:token": "tok"}}
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}})
+	mgr := NewConfigManager(WithCMRemoteProvider(vault), WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}))
+	_, err := mgr.GetPublicConfig("API_TOKEN")
+	require.NoError(t, err)
+
+	mgr.mu.Lock()
+	mgr.remoteConfig["API_TOKEN"] = "smooai-secret://vault:token"
+	mgr.remergeKeyLocked("API_TOKEN")
+	mgr.mu.Unlock()
+	v, _ := mgr.GetPublicConfig("API_TOKEN")
+	assert.Equal(t, "tok", v, "a pushed reference is resolved like a loaded one")
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Health statuses reported by ConfigManager.Health.
+const (
+	HealthStatusHealthy  = "healthy"
+	HealthStatusDegraded = "degraded"
+)
+
+// ErrStaleConfig matches any *StaleConfigError via errors.Is.
+var ErrStaleConfig = errors.New("config is staler than the configured budget")
+
+// StaleConfigError is returned from GetSecretConfig when WithFailStaleSecrets
+// is on and the manager has been serving non-fresh remote data for longer
+// than the WithMaxStaleness budget.
+type StaleConfigError struct {
+	// Key is the secret key that was read.
+	Key string
+	// Staleness is how long the manager has been serving non-fresh data.
+	Staleness time.Duration
+	// Budget is the configured maximum staleness.
+	Budget time.Duration
+	// Cause is the remote fetch error that started the staleness clock.
+	Cause error
+}
+
+// Error implements the error interface.
+func (e *StaleConfigError) Error() string {
+	msg := fmt.Sprintf("[Smooai Config] refusing to serve secret %q: remote config has been stale for %s (budget %s)",
+		e.Key, e.Staleness.Round(time.Second), e.Budget)
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// Is supports errors.Is(err, ErrStaleConfig).
+func (e *StaleConfigError) Is(target error) bool { return target == ErrStaleConfig }
+
+// Unwrap exposes the remote fetch error.
+func (e *StaleConfigError) Unwrap() error { return e.Cause }
+
+// ManagerHealth is the status returned by ConfigManager.Health. Status is
+// HealthStatusHealthy, HealthStatusDegraded or HealthStatusRestartPending;
+// Reason is set when not healthy.
+type ManagerHealth struct {
+	Status string
+	Reason string
+	// Staleness is how long the manager has been serving non-fresh remote
+	// data; zero when the last remote fetch succeeded.
+	Staleness time.Duration
+	// LastSync is when remote values last arrived, from a fetch or a
+	// pushed change; zero before the first.
+	LastSync time.Time
+	// LastError is why the last remote fetch failed while stale.
+	LastError error
+}
+
+// IsHealthy reports whether the status is healthy.
+func (h ManagerHealth) IsHealthy() bool { return h.Status == HealthStatusHealthy }
+
+// WithMaxStaleness sets a soft-fail budget for stale remote config. When a
+// remote fetch fails, the manager keeps serving the last good remote values
+// and starts a staleness clock; once it runs past d, Health reports degraded
+// (and, with WithFailStaleSecrets, secret reads start failing). A later
+// successful fetch resets the clock. Zero (default) disables the budget.
+func WithMaxStaleness(d time.Duration) ConfigManagerOption {
+	return func(m *ConfigManager) { m.maxStaleness = d }
+}
+
+// WithFailStaleSecrets makes GetSecretConfig return *StaleConfigError once
+// the WithMaxStaleness budget is exceeded, enforcing freshness for sensitive
+// values. Public and feature-flag reads keep serving last-good data.
+func WithFailStaleSecrets(fail bool) ConfigManagerOption {
+	return func(m *ConfigManager) { m.failStaleSecrets = fail }
+}
+
+// Staleness reports how long the manager has been serving non-fresh remote
+// data. Zero when the last remote fetch succeeded (or none has failed).
+func (m *ConfigManager) Staleness() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stalenessLocked()
+}
+
+// stalenessLocked must be called under m.mu.
+func (m *ConfigManager) stalenessLocked() time.Duration {
+	if m.staleSince.IsZero() {
+		return 0
+	}
+	return time.Since(m.staleSince)
+}
+
+// Health is a cheap, non-erroring status for readiness probes. It reports
+// degraded once the manager has served non-fresh remote data for longer than
+// the WithMaxStaleness budget, and otherwise restart_pending once a
+// WithRestartRequiredKeys key has changed.
+func (m *ConfigManager) Health() ManagerHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.healthLocked()
+}
+
+// healthLocked must be called under m.mu.
+func (m *ConfigManager) healthLocked() ManagerHealth {
+	h := ManagerHealth{Status: HealthStatusHealthy, Staleness: m.stalenessLocked(), LastSync: m.lastSync}
+	if h.Staleness > 0 {
+		h.LastError = m.lastRemoteErr
+	}
+	if m.maxStaleness > 0 && h.Staleness > m.maxStaleness {
+		h.Status = HealthStatusDegraded
+		h.Reason = fmt.Sprintf("remote config stale for %s (budget %s)", h.Staleness.Round(time.Second), m.maxStaleness)
+		if m.lastRemoteErr != nil {
+			h.Reason += ": " + m.lastRemoteErr.Error()
+		}
+	} else if pending := m.restartPendingLocked(); pending != nil {
+		h.Status = HealthStatusRestartPending
+		h.Reason = restartReason(pending)
+	}
+	return h
+}
+
+// HealthHandler serves Health as JSON, for mounting under a path such as
+// /healthz/config. It answers 503 Service Unavailable while degraded, so
+// an orchestrator can take a service running on stale config out of
+// rotation, and 200 OK otherwise:
+//
+//	{"status": "degraded", "reason": "...", "stalenessSeconds": 5400, "lastSync": "...", "lastError": "..."}
+func (m *ConfigManager) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := m.Health()
+		body := struct {
+			Status           string     `json:"status"`
+			Reason           string     `json:"reason,omitempty"`
+			StalenessSeconds float64    `json:"stalenessSeconds"`
+			LastSync         *time.Time `json:"lastSync,omitempty"`
+			LastError        string     `json:"lastError,omitempty"`
+		}{Status: h.Status, Reason: h.Reason, StalenessSeconds: h.Staleness.Seconds()}
+		if !h.LastSync.IsZero() {
+			body.LastSync = &h.LastSync
+		}
+		if h.LastError != nil {
+			body.LastError = h.LastError.Error()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if h.Status == HealthStatusDegraded {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(body)
+	})
+}
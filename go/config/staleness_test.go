@@ -0,0 +1,174 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyRemote serves values until failing is set, then returns 503.
+func flakyRemote(t *testing.T, values map[string]any) (*httptest.Server, *atomic.Bool) {
+	t.Helper()
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"values": values})
+	}))
+	t.Cleanup(server.Close)
+	return server, &failing
+}
+
+func newStalenessManager(t *testing.T, serverURL string, opts ...ConfigManagerOption) *ConfigManager {
+	t.Helper()
+	configDir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}})
+	base := []ConfigManagerOption{
+		WithAPIKey("key"),
+		WithBaseURL(serverURL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_AUTH_URL": serverURL,
+			"SMOOAI_ENV_CONFIG_DIR":  configDir,
+		}),
+	}
+	return NewConfigManager(append(base, opts...)...)
+}
+
+func TestConfigManager_ServesLastGoodRemoteOnFailure(t *testing.T) {
+	server, failing := flakyRemote(t, map[string]any{"API_URL": "https://remote"})
+	mgr := newStalenessManager(t, server.URL, WithMaxStaleness(time.Hour))
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote", v)
+	assert.Zero(t, mgr.Staleness())
+
+	failing.Store(true)
+	mgr.Invalidate()
+
+	v, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote", v, "last good remote value is served")
+	assert.Positive(t, mgr.Staleness())
+	assert.True(t, mgr.Health().IsHealthy(), "still within budget")
+
+	failing.Store(false)
+	mgr.Invalidate()
+	_, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Zero(t, mgr.Staleness(), "a successful fetch resets the clock")
+}
+
+func TestConfigManager_HealthDegradesPastBudget(t *testing.T) {
+	server, failing := flakyRemote(t, map[string]any{"API_URL": "https://remote"})
+	mgr := newStalenessManager(t, server.URL, WithMaxStaleness(10*time.Millisecond))
+
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.True(t, mgr.Health().IsHealthy())
+
+	failing.Store(true)
+	mgr.Invalidate()
+	_, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+
+	health := mgr.Health()
+	assert.Equal(t, HealthStatusDegraded, health.Status)
+	assert.Contains(t, health.Reason, "HTTP 503")
+	assert.Greater(t, health.Staleness, 10*time.Millisecond)
+
+	// Public reads keep serving last-good data past the budget.
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote", v)
+}
+
+func TestConfigManager_FailStaleSecrets(t *testing.T) {
+	server, failing := flakyRemote(t, map[string]any{"DB_PASSWORD": "hunter2"})
+	mgr := newStalenessManager(t, server.URL,
+		WithMaxStaleness(10*time.Millisecond),
+		WithFailStaleSecrets(true),
+	)
+
+	v, err := mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	failing.Store(true)
+	mgr.Invalidate()
+	_, err = mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err, "within budget the last good secret is served")
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = mgr.GetSecretConfig("DB_PASSWORD")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrStaleConfig))
+	var staleErr *StaleConfigError
+	require.True(t, errors.As(err, &staleErr))
+	assert.Equal(t, "DB_PASSWORD", staleErr.Key)
+	assert.Equal(t, 10*time.Millisecond, staleErr.Budget)
+}
+
+func TestConfigManager_HealthWithoutBudget(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{}))
+	assert.True(t, mgr.Health().IsHealthy())
+	assert.Zero(t, mgr.Staleness())
+}
+
+func TestConfigManager_HealthHandler(t *testing.T) {
+	server, failing := flakyRemote(t, map[string]any{"API_URL": "https://remote"})
+	mgr := newStalenessManager(t, server.URL, WithMaxStaleness(10*time.Millisecond))
+
+	before := time.Now()
+	_, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	health := mgr.Health()
+	assert.False(t, health.LastSync.Before(before))
+	assert.NoError(t, health.LastError)
+
+	serve := func() (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		mgr.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz/config", nil))
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		return rec.Code, body
+	}
+	code, body := serve()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "healthy", body["status"])
+	assert.NotContains(t, body, "lastError")
+	assert.Contains(t, body, "lastSync")
+
+	failing.Store(true)
+	mgr.Invalidate()
+	_, err = mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+
+	health = mgr.Health()
+	assert.Equal(t, HealthStatusDegraded, health.Status)
+	assert.ErrorContains(t, health.LastError, "HTTP 503")
+	assert.False(t, health.LastSync.Before(before), "the last good sync is kept")
+
+	code, body = serve()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "degraded", body["status"])
+	assert.Contains(t, body["lastError"], "HTTP 503")
+	assert.Greater(t, body["stalenessSeconds"], 0.01)
+}
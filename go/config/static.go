@@ -0,0 +1,33 @@
+package config
+
+// NewStaticConfigManager returns a ConfigManager that serves public, secret
+// and flags as its public, secret and feature flag tiers, and reads no
+// config files, env vars or remote API. It is a real *ConfigManager, so it
+// drops in wherever one is injected, for unit tests and demos without temp
+// directories or env juggling:
+//
+//	mgr := config.NewStaticConfigManager(
+//	    map[string]any{"API_URL": "https://api.example.com"},
+//	    map[string]any{"DB_PASSWORD": "hunter2"},
+//	    map[string]any{"NEW_UI": true},
+//	)
+//
+// Reading a key through the wrong tier fails as it would against the API.
+// A key in more than one map takes the last one's value and tier. opts
+// apply as usual, so overrides, change listeners and WithStrictMode work;
+// options that configure a source have nothing to read. The maps are
+// copied.
+func NewStaticConfigManager(public, secret, flags map[string]any, opts ...ConfigManagerOption) *ConfigManager {
+	snap := &Snapshot{Version: snapshotVersion, Values: make(map[string]SnapshotValue, len(public)+len(secret)+len(flags))}
+	for _, tier := range []struct {
+		tier   ConfigTier
+		values map[string]any
+	}{{TierPublic, public}, {TierSecret, secret}, {TierFeatureFlag, flags}} {
+		for key, value := range tier.values {
+			snap.Values[key] = SnapshotValue{Value: cloneValue(value), Tier: tier.tier}
+		}
+	}
+	m := NewConfigManager(opts...)
+	m.snapshot = snap
+	return m
+}
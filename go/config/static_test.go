@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStaticConfigManager(t *testing.T) {
+	t.Setenv("API_URL", "from-process-env")
+	public := map[string]any{"API_URL": "https://static", "LIMITS": map[string]any{"rps": 10.0}}
+	mgr := NewStaticConfigManager(
+		public,
+		map[string]any{"DB_PASSWORD": "hunter2"},
+		map[string]any{"NEW_UI": true},
+		WithCMSchemaKeys(map[string]bool{"API_URL": true}),
+	)
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://static", v, "the process environment is ignored")
+	v, err = mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+	v, err = mgr.GetFeatureFlag("NEW_UI")
+	require.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	_, err = mgr.GetPublicConfig("DB_PASSWORD")
+	assert.ErrorIs(t, err, ErrWrongTier)
+	_, err = mgr.GetFeatureFlag("API_URL")
+	assert.ErrorIs(t, err, ErrWrongTier)
+
+	public["LIMITS"].(map[string]any)["rps"] = 99.0
+	v, err = mgr.GetPublicConfig("LIMITS")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"rps": 10.0}, v, "the maps are copied")
+
+	mgr.SetOverride("NEW_UI", false, 0)
+	v, err = mgr.GetFeatureFlag("NEW_UI")
+	require.NoError(t, err)
+	assert.Equal(t, false, v)
+
+	port, err := Get[int](NewStaticConfigManager(map[string]any{"PORT": 8080.0}, nil, nil), "PORT")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+}
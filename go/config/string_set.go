@@ -0,0 +1,130 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// StringSet is a live, read-only view of a config key holding a list of
+// strings (allowed origins, blocked IPs, tenant IDs). It is safe for
+// concurrent use; lookups never block on an update.
+type StringSet struct {
+	members atomic.Pointer[map[string]struct{}]
+}
+
+// Contains reports whether member is in the set.
+func (s *StringSet) Contains(member string) bool {
+	_, ok := (*s.members.Load())[member]
+	return ok
+}
+
+// Len returns the number of members.
+func (s *StringSet) Len() int { return len(*s.members.Load()) }
+
+// Values returns the members, sorted.
+func (s *StringSet) Values() []string {
+	members := *s.members.Load()
+	values := make([]string, 0, len(members))
+	for member := range members {
+		values = append(values, member)
+	}
+	sort.Strings(values)
+	return values
+}
+
+func (s *StringSet) set(values []string) {
+	members := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		members[v] = struct{}{}
+	}
+	s.members.Store(&members)
+}
+
+// StringSetWatcher returns a StringSet holding the public config key's
+// members, updated whenever the value changes through a reload, pushed
+// change or override. The value is a JSON array of strings, that array as a
+// JSON string, or a comma-separated string ("https://a.example, https://b.example").
+// Members are trimmed and empty ones dropped. An unset key is an empty set;
+// an update that doesn't parse is logged and the previous members are kept.
+func StringSetWatcher(mgr *ConfigManager, key string) (*StringSet, error) {
+	value, err := mgr.GetPublicConfig(key)
+	if err != nil {
+		return nil, err
+	}
+	values, err := parseStringList(key, value)
+	if err != nil {
+		return nil, err
+	}
+	set := &StringSet{}
+	set.set(values)
+
+	// Re-read under mu rather than trusting the event's value, so changes
+	// delivered out of order still leave the latest value applied.
+	var mu sync.Mutex
+	mgr.addChangeListener(func(evt ConfigChangeEvent) {
+		if !slices.Contains(evt.Keys, key) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		value, err := mgr.GetPublicConfig(key)
+		var values []string
+		if err == nil {
+			values, err = parseStringList(key, value)
+		}
+		if err != nil {
+			mgr.log().Warn("ignoring string set update", "error", err)
+			return
+		}
+		set.set(values)
+	})
+	return set, nil
+}
+
+// parseStringList reads a list of strings from a config value.
+func parseStringList(key string, value any) ([]string, error) {
+	invalid := func(reason string) error {
+		return NewConfigError(fmt.Sprintf("config key %q is not a string list: %s", key, reason))
+	}
+	var items []any
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		items = v
+	case []string:
+		for _, s := range v {
+			items = append(items, s)
+		}
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if strings.HasPrefix(trimmed, "[") {
+			if err := json.Unmarshal([]byte(trimmed), &items); err != nil {
+				return nil, invalid("invalid JSON")
+			}
+			break
+		}
+		for _, part := range strings.Split(trimmed, ",") {
+			items = append(items, part)
+		}
+	default:
+		return nil, invalid(fmt.Sprintf("unsupported type %T", value))
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, invalid(fmt.Sprintf("member %v is not a string", item))
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values, nil
+}
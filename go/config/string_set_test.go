@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStringList(t *testing.T) {
+	cases := []struct {
+		in   any
+		want []string
+	}{
+		{[]any{"a", " b ", ""}, []string{"a", "b"}},
+		{`["a", "b"]`, []string{"a", "b"}},
+		{"https://a.example, https://b.example,,", []string{"https://a.example", "https://b.example"}},
+		{"", []string{}},
+		{nil, nil},
+	}
+	for _, tc := range cases {
+		got, err := parseStringList("K", tc.in)
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, got, tc.in)
+	}
+	for _, bad := range []any{[]any{"a", 1}, `["a",`, 42} {
+		_, err := parseStringList("K", bad)
+		assert.ErrorContains(t, err, "not a string list", bad)
+	}
+}
+
+func TestStringSetWatcher(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+			"ALLOWED_ORIGINS": []any{"https://app.example", "https://admin.example"},
+		}}),
+	}))
+
+	origins, err := StringSetWatcher(mgr, "ALLOWED_ORIGINS")
+	require.NoError(t, err)
+	assert.True(t, origins.Contains("https://app.example"))
+	assert.False(t, origins.Contains("https://evil.example"))
+	assert.Equal(t, 2, origins.Len())
+
+	mgr.SetOverride("ALLOWED_ORIGINS", "https://app.example, https://new.example", 0)
+	assert.Eventually(t, func() bool { return origins.Contains("https://new.example") }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"https://app.example", "https://new.example"}, origins.Values())
+
+	// A bad update keeps the previous members.
+	mgr.SetOverride("ALLOWED_ORIGINS", 42, 0)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, []string{"https://app.example", "https://new.example"}, origins.Values())
+
+	mgr.ClearOverride("ALLOWED_ORIGINS")
+	assert.Eventually(t, func() bool { return origins.Contains("https://admin.example") }, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestStringSetWatcher_UnsetIsEmpty(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{
+		"SMOOAI_ENV_CONFIG_DIR": makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"BAD": 1}}),
+	}))
+
+	set, err := StringSetWatcher(mgr, "BLOCKED_IPS")
+	require.NoError(t, err)
+	assert.Zero(t, set.Len())
+	assert.False(t, set.Contains(""))
+
+	_, err = StringSetWatcher(mgr, "BAD")
+	assert.Error(t, err)
+}
@@ -0,0 +1,596 @@
+package config
+
+// Live config updates over Server-Sent Events. Polling is too slow for
+// feature-flag kill switches; a subscription pushes value changes as they
+// happen and invalidates only the affected keys.
+//
+// Server contract:
+//
+//	GET {BaseURL}/organizations/{orgId}/config/values/stream?environment=...[&ring=...]
+//	Accept: text/event-stream
+//
+//	id: 42
+//	event: change
+//	data: {"keys": ["API_URL", "OLD_KEY"], "values": {"API_URL": "https://..."}}
+//
+// A key listed in "keys" but absent from "values" was changed without an
+// inline value (or deleted) — the cache entry is dropped and the next read
+// refetches it. Events other than "change" (and ":" comment heartbeats) are
+// ignored. Where the stream is unavailable, Subscribe long-polls instead
+// (see longpoll.go); WithWatchTransport can also select long polling or a
+// WebSocket (see websocket.go).
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigChangeEvent is one batch of value changes pushed by the server.
+type ConfigChangeEvent struct {
+	// ID is the SSE event id, resent as Last-Event-ID on reconnect.
+	ID string `json:"-"`
+	// Environment the change applies to.
+	Environment string `json:"-"`
+	// Keys are every key that changed.
+	Keys []string `json:"keys"`
+	// Values carries the new value for keys the server inlined. Keys without
+	// an entry must be refetched.
+	Values map[string]any `json:"values,omitempty"`
+}
+
+// Subscription is a live change stream opened by ConfigClient.Subscribe. The
+// stream reconnects with backoff until Close is called or the context passed
+// to Subscribe is cancelled.
+type Subscription struct {
+	events chan ConfigChangeEvent
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// source is the underlying client stream when this subscription
+	// re-delivers events (ConfigManager.Subscribe); Err consults it.
+	source *Subscription
+
+	mu      sync.Mutex
+	lastErr error
+}
+
+// Events delivers change events in arrival order. The channel is closed when
+// the subscription ends.
+//
+// Reading Events is optional: changes are applied whether or not anyone
+// reads them, and delivery never blocks the stream. Events are buffered; if
+// the buffer is full, everything still queued is coalesced with the new
+// event into one that lists every changed key with its latest value.
+func (s *Subscription) Events() <-chan ConfigChangeEvent { return s.events }
+
+// Err returns the most recent stream error (a dropped connection or failed
+// reconnect), or nil.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	err := s.lastErr
+	s.mu.Unlock()
+	if err == nil && s.source != nil {
+		return s.source.Err()
+	}
+	return err
+}
+
+// Close stops the stream and waits for the reader goroutine to exit.
+// Idempotent.
+func (s *Subscription) Close() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *Subscription) setErr(err error) {
+	s.mu.Lock()
+	s.lastErr = err
+	s.mu.Unlock()
+}
+
+// subscriptionBuffer is how many events Events holds before coalescing.
+const subscriptionBuffer = 16
+
+// deliver queues evt on Events without blocking. When the reader is behind
+// and the buffer is full, it folds every queued event and evt into one, so
+// the reader catches up on the latest value of each key. Each subscription
+// has one sending goroutine, so the buffer it drains stays drained.
+func (s *Subscription) deliver(evt ConfigChangeEvent) {
+	select {
+	case s.events <- evt:
+		return
+	default:
+	}
+	var merged *ConfigChangeEvent
+	for drained := false; !drained; {
+		select {
+		case queued := <-s.events:
+			if merged == nil {
+				merged = &queued
+			} else {
+				*merged = coalesceEvents(*merged, queued)
+			}
+		default:
+			drained = true
+		}
+	}
+	if merged != nil {
+		evt = coalesceEvents(*merged, evt)
+	}
+	select {
+	case s.events <- evt:
+	default: // unreachable: this goroutine just emptied the buffer
+	}
+}
+
+// coalesceEvents merges two consecutive events into one: the keys of both,
+// with newer's entry (inlined value or none) winning for keys in both.
+func coalesceEvents(older, newer ConfigChangeEvent) ConfigChangeEvent {
+	out := ConfigChangeEvent{ID: newer.ID, Environment: newer.Environment}
+	if out.ID == "" {
+		out.ID = older.ID
+	}
+	inNewer := make(map[string]bool, len(newer.Keys))
+	for _, key := range newer.Keys {
+		inNewer[key] = true
+	}
+	values := make(map[string]any)
+	for _, key := range older.Keys {
+		if inNewer[key] {
+			continue
+		}
+		out.Keys = append(out.Keys, key)
+		if v, ok := older.Values[key]; ok {
+			values[key] = v
+		}
+	}
+	out.Keys = append(out.Keys, newer.Keys...)
+	for _, key := range newer.Keys {
+		if v, ok := newer.Values[key]; ok {
+			values[key] = v
+		}
+	}
+	if len(values) > 0 {
+		out.Values = values
+	}
+	return out
+}
+
+// subscribeBackoff bounds the reconnect delay after a dropped stream.
+var subscribeBackoff = struct{ initial, max time.Duration }{time.Second, 30 * time.Second}
+
+// WatchTransport selects how Subscribe receives changes.
+type WatchTransport string
+
+const (
+	// WatchAuto uses SSE, falling back to long polling where the stream is
+	// unavailable. The default.
+	WatchAuto WatchTransport = ""
+	// WatchSSE uses SSE only.
+	WatchSSE WatchTransport = "sse"
+	// WatchLongPoll long-polls /config/changes.
+	WatchLongPoll WatchTransport = "longpoll"
+	// WatchWebSocket uses a WebSocket, for gateways that only permit
+	// WebSocket upgrades.
+	WatchWebSocket WatchTransport = "websocket"
+)
+
+// WithWatchTransport sets how Subscribe receives changes. Defaults to
+// $SMOOAI_CONFIG_WATCH_TRANSPORT ("sse", "longpoll" or "websocket"), or
+// WatchAuto.
+func WithWatchTransport(transport WatchTransport) ConfigClientOption {
+	return func(c *ConfigClient) {
+		c.watchTransport = transport
+	}
+}
+
+// Subscribe opens a stream of value changes for environment (empty uses the
+// client's default) over the client's watch transport (WithWatchTransport).
+// The first connection is made synchronously so a misconfiguration fails
+// loud; after that, dropped streams reconnect with exponential backoff,
+// resuming from the last event id.
+//
+// Every event is applied to the client's own cache before it is delivered:
+// inlined values overwrite their entries, other listed keys are evicted, and
+// the environment's ETag snapshot is dropped so the next GetAllValues is a
+// full fetch.
+func (c *ConfigClient) Subscribe(ctx context.Context, environment string) (*Subscription, error) {
+	env, err := c.requestEnv(environment)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	sub := &Subscription{
+		events: make(chan ConfigChangeEvent, subscriptionBuffer),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	run, err := c.startWatch(ctx, env, sub)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go func() {
+		defer close(sub.done)
+		defer close(sub.events)
+		run()
+	}()
+	return sub, nil
+}
+
+// startWatch makes the first connection over the client's watch transport
+// and returns the loop that delivers changes from it.
+func (c *ConfigClient) startWatch(ctx context.Context, env string, sub *Subscription) (func(), error) {
+	switch c.watchTransport {
+	case WatchAuto, WatchSSE:
+		body, err := c.openStream(ctx, env, "")
+		if c.watchTransport == WatchAuto && errors.Is(err, errStreamUnavailable) {
+			cursor, _, pollErr := c.pollChanges(ctx, env, "")
+			if pollErr != nil {
+				return nil, errors.Join(err, pollErr)
+			}
+			return func() { c.runLongPoll(ctx, env, sub, cursor) }, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func() { c.runSubscription(ctx, env, sub, body) }, nil
+	case WatchLongPoll:
+		cursor, _, err := c.pollChanges(ctx, env, "")
+		if err != nil {
+			return nil, err
+		}
+		return func() { c.runLongPoll(ctx, env, sub, cursor) }, nil
+	case WatchWebSocket:
+		ws, err := c.openWebSocket(ctx, env, "")
+		if err != nil {
+			return nil, err
+		}
+		return func() { c.runWebSocket(ctx, env, sub, ws) }, nil
+	default:
+		return nil, NewConfigError(fmt.Sprintf("unknown watch transport %q", c.watchTransport))
+	}
+}
+
+func (c *ConfigClient) streamURL(env string) string {
+	return fmt.Sprintf("%s/config/values/stream?%s", c.orgURL(), c.valuesQuery(env))
+}
+
+func (c *ConfigClient) openStream(ctx context.Context, env, lastEventID string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.streamURL(env), nil)
+	if err != nil {
+		return nil, fmt.Errorf("config subscribe: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := c.doRequestWithRetryUsing(c.streamClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("config subscribe: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotAcceptable, http.StatusNotImplemented:
+		httpErr := newHTTPError("subscribe", resp)
+		resp.Body.Close()
+		return nil, fmt.Errorf("config subscribe: %w: %w", errStreamUnavailable, httpErr)
+	default:
+		httpErr := newHTTPError("subscribe", resp)
+		resp.Body.Close()
+		return nil, httpErr
+	}
+	// A proxy that strips SSE typically answers 200 with its own body.
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		resp.Body.Close()
+		return nil, fmt.Errorf("config subscribe: %w: got Content-Type %q", errStreamUnavailable, resp.Header.Get("Content-Type"))
+	}
+	return resp.Body, nil
+}
+
+func (c *ConfigClient) runSubscription(ctx context.Context, env string, sub *Subscription, body io.ReadCloser) {
+	lastEventID := ""
+	backoff := subscribeBackoff.initial
+	for {
+		err := readEventStream(body, func(evt ConfigChangeEvent) bool {
+			backoff = subscribeBackoff.initial
+			evt.Environment = env
+			if evt.ID != "" {
+				lastEventID = evt.ID
+			}
+			c.applyChangeEvent(evt)
+			sub.deliver(evt)
+			return ctx.Err() == nil
+		})
+		body.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		sub.setErr(fmt.Errorf("config subscribe: stream dropped: %w", err))
+
+		// Reconnect until it sticks or the subscription is closed.
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, subscribeBackoff.max)
+			body, err = c.openStream(ctx, env, lastEventID)
+			if err == nil {
+				break
+			}
+			sub.setErr(err)
+			if c.watchTransport == WatchAuto && errors.Is(err, errStreamUnavailable) {
+				c.runLongPoll(ctx, env, sub, lastEventID)
+				return
+			}
+		}
+	}
+}
+
+// applyChangeEvent folds a change event into the client cache.
+func (c *ConfigClient) applyChangeEvent(evt ConfigChangeEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.snapshots, evt.Environment)
+	for _, key := range evt.Keys {
+		if v, ok := evt.Values[key]; ok {
+			c.setCacheLocked(c.valueCacheKey(evt.Environment, key), v)
+		} else {
+			delete(c.cache, c.valueCacheKey(evt.Environment, key))
+		}
+	}
+}
+
+// readEventStream parses text/event-stream frames from r and calls emit for
+// each "change" event until r is exhausted or emit returns false. Malformed
+// data payloads are skipped rather than tearing down the stream.
+func readEventStream(r io.Reader, emit func(ConfigChangeEvent) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var id, event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 && (event == "" || event == "change") {
+				var evt ConfigChangeEvent
+				if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &evt); err == nil {
+					evt.ID = id
+					if !emit(evt) {
+						return nil
+					}
+				}
+			}
+			event, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return scanner.Err()
+}
+
+// Subscribe opens a live change stream for the manager's remote environment
+// and applies each event as it arrives: only the listed keys are evicted
+// from the per-tier caches and re-merged (file < remote < env <
+// SetOverride, so an env-var override still wins over a pushed value). Keys
+// the server did not inline are refetched individually. Managers with
+// deferred or interpolated values reload fully on each event, since those
+// may depend on any key.
+//
+// The returned Subscription re-delivers every applied event; events apply
+// whether or not its Events channel is read (see Subscription.Events).
+// Requires remote API credentials; baked (NewRuntimeConfigManager) managers
+// have no stream.
+func (m *ConfigManager) Subscribe(ctx context.Context) (*Subscription, error) {
+	env := m.envMap()
+	if m.bakedConfig != nil {
+		return nil, NewConfigError("Subscribe is not available for baked config managers")
+	}
+	m.mu.Lock()
+	replaying := m.snapshot != nil
+	m.mu.Unlock()
+	if replaying {
+		return nil, NewConfigError("Subscribe is not available while replaying a snapshot")
+	}
+	client, configEnv, ok := m.newRemoteClient(env)
+	if !ok {
+		return nil, NewConfigError("Subscribe requires remote API credentials (API key, base URL, org ID)")
+	}
+
+	m.mu.Lock()
+	err := m.initialize()
+	m.mu.Unlock()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	inner, err := client.Subscribe(ctx, configEnv)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	outer := &Subscription{
+		events: make(chan ConfigChangeEvent, subscriptionBuffer),
+		cancel: cancel,
+		done:   make(chan struct{}),
+		source: inner,
+	}
+	unregister, err := m.components.register("subscription", outer.Close)
+	if err != nil {
+		cancel()
+		inner.Close()
+		client.Close()
+		return nil, fmt.Errorf("[Smooai Config] Subscribe: %w", err)
+	}
+	go func() {
+		defer unregister()
+		defer close(outer.done)
+		defer close(outer.events)
+		defer client.Close()
+		defer inner.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt, ok := <-inner.Events():
+				if !ok {
+					return
+				}
+				if err := m.applyRemoteChange(client, evt); err != nil {
+					outer.setErr(err)
+				}
+				outer.deliver(evt)
+			}
+		}
+	}()
+	return outer, nil
+}
+
+// applyRemoteChange patches the merged config for the keys in evt. A key
+// the server didn't inline is refetched; a 404 or a null value removes it.
+// When the refetch fails any other way the key keeps its previous value,
+// the error is returned, and the manager reloads fully on the next read.
+func (m *ConfigManager) applyRemoteChange(client *ConfigClient, evt ConfigChangeEvent) error {
+	// Resolve values the server didn't inline before taking the lock — the
+	// client cache was already evicted for them, so this hits the network.
+	var keys []string
+	for _, key := range evt.Keys {
+		if hasKeyPrefix(key, m.keyPrefixes) { // WithKeyPrefixFilter
+			keys = append(keys, key)
+		}
+	}
+	values := make(map[string]any, len(keys))
+	removed := make(map[string]bool)
+	var refetchErr error
+	for _, key := range keys {
+		if v, ok := evt.Values[key]; ok {
+			values[key] = v
+			continue
+		}
+		v, err := client.GetValue(key, evt.Environment)
+		switch {
+		case errors.Is(err, ErrNotFound) || (err == nil && v == nil):
+			removed[key] = true
+		case err != nil:
+			if refetchErr == nil {
+				refetchErr = fmt.Errorf("[Smooai Config] refetch %s after a pushed change: %w", key, err)
+			}
+		default:
+			values[key] = v
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.initialized || m.snapshot != nil {
+		return refetchErr // the next read initializes from scratch anyway; a snapshot takes no pushes
+	}
+	if refetchErr != nil {
+		// Keep the previous values and reload everything on the next read;
+		// if the API is still failing that serves the last good remote
+		// values and starts the staleness clock.
+		m.log().Warn("reloading config after a failed refetch", "error", refetchErr)
+		m.resetLocked()
+		return refetchErr
+	}
+	m.lastSync = time.Now()
+	if m.dependentValuesLocked() {
+		m.resetLocked()
+		return nil
+	}
+	for _, key := range keys {
+		if removed[key] {
+			delete(m.remoteConfig, key)
+		} else {
+			m.remoteConfig[key] = values[key]
+		}
+		m.remergeKeyLocked(key)
+	}
+	return nil
+}
+
+// remergeKeyLocked recomputes config[key] from the stored layers (file <
+// SSM < KV < remote < env < override, with WithSource layers among them)
+// and evicts it from the per-tier caches. Must be called under m.mu on an
+// initialized manager without dependent values.
+func (m *ConfigManager) remergeKeyLocked(key string) {
+	delete(m.publicCache, key)
+	dropCacheEntry(m.secretCache, key)
+	delete(m.ffCache, key)
+
+	before := map[string]any{}
+	if v, ok := m.config[key]; ok {
+		before[key] = v
+	}
+	m.mergeKeyLocked(key)
+	m.validateLocked()
+	m.notifyChangeLocked(changedKeys(before, m.config, key), m.config)
+}
+
+// mergeKeyLocked sets config[key] from the stored layers. Must be called
+// under m.mu.
+func (m *ConfigManager) mergeKeyLocked(key string) {
+	if o, ok := m.overrides[key]; ok {
+		m.config[key] = o.value
+		delete(m.secretRefErrs, key)
+		return
+	}
+	merged := map[string]any{}
+	for _, layer := range m.layersLocked(m.fileConfig, m.ssmConfig, m.kvConfig, m.remoteConfig, m.envConfig) {
+		if v, ok := layer.values[key]; ok {
+			merged = MergeWithNullPolicy(merged, map[string]any{key: v}, m.nullPolicy).(map[string]any)
+		}
+	}
+	if err := m.secrets.resolveAll(merged)[key]; err != nil {
+		_, failing := m.secretRefErrs[key]
+		if _, ok := m.config[key]; ok && !failing {
+			m.log().Warn("keeping the previous value", "key", key, "error", err)
+			return
+		}
+		if m.secretRefErrs == nil {
+			m.secretRefErrs = make(map[string]error)
+		}
+		m.secretRefErrs[key] = err
+		delete(m.config, key)
+		return
+	}
+	delete(m.secretRefErrs, key)
+	if value, ok := merged[key]; ok {
+		m.config[key] = value
+	} else {
+		delete(m.config, key)
+	}
+}
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEventStream_ParsesChangeEvents(t *testing.T) {
+	stream := strings.Join([]string{
+		": heartbeat",
+		"",
+		"id: 1",
+		"event: change",
+		`data: {"keys":["A"],"values":{"A":"1"}}`,
+		"",
+		"event: ping",
+		`data: {"keys":["IGNORED"]}`,
+		"",
+		"id: 2",
+		`data: {"keys":["B"]}`,
+		"",
+		"data: not-json",
+		"",
+	}, "\n")
+
+	var got []ConfigChangeEvent
+	err := readEventStream(strings.NewReader(stream), func(evt ConfigChangeEvent) bool {
+		got = append(got, evt)
+		return true
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "1", got[0].ID)
+	assert.Equal(t, map[string]any{"A": "1"}, got[0].Values)
+	assert.Equal(t, "2", got[1].ID)
+	assert.Equal(t, []string{"B"}, got[1].Keys)
+	assert.Nil(t, got[1].Values)
+}
+
+// sseServer serves /token, the values endpoints, and an SSE stream fed from
+// the returned channel. Each string sent is written as one "data:" frame.
+func sseServer(t *testing.T, values map[string]any, single map[string]any) (*httptest.Server, chan string) {
+	t.Helper()
+	frames := make(chan string, 8)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "jwt", "expires_in": 3600})
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values/stream", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case frame := <-frames:
+				fmt.Fprintf(w, "event: change\ndata: %s\n\n", frame)
+				w.(http.Flusher).Flush()
+			}
+		}
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values/", func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/organizations/"+testOrgID+"/config/values/")
+		v, ok := single[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(valueResponse{Value: v})
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(valuesResponse{Values: values})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, frames
+}
+
+func receiveEvent(t *testing.T, sub *Subscription) ConfigChangeEvent {
+	t.Helper()
+	select {
+	case evt, ok := <-sub.Events():
+		require.True(t, ok, "subscription closed")
+		return evt
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change event received")
+		return ConfigChangeEvent{}
+	}
+}
+
+func TestConfigClient_SubscribeUpdatesCache(t *testing.T) {
+	server, frames := sseServer(t, map[string]any{"A": "old", "B": "old"}, nil)
+	client := NewConfigClient(server.URL, "cid", "secret", testOrgID, WithAuthURL(server.URL), WithRing(""))
+	defer client.Close()
+
+	_, err := client.GetAllValues("production")
+	require.NoError(t, err)
+
+	sub, err := client.Subscribe(context.Background(), "production")
+	require.NoError(t, err)
+	defer sub.Close()
+
+	frames <- `{"keys":["A","B"],"values":{"A":"new"}}`
+	evt := receiveEvent(t, sub)
+	assert.Equal(t, "production", evt.Environment)
+
+	v, ok := client.GetCachedValue("A", "production")
+	assert.True(t, ok)
+	assert.Equal(t, "new", v)
+	_, ok = client.GetCachedValue("B", "production")
+	assert.False(t, ok, "keys without inline values are evicted")
+}
+
+func TestConfigClient_SubscribeFailsLoudOnHTTPError(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	_, err := client.Subscribe(context.Background(), "production")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestConfigManager_SubscribeInvalidatesOnlyChangedKeys(t *testing.T) {
+	server, frames := sseServer(t,
+		map[string]any{"A": "remote-a", "B": "remote-b", "PINNED": "remote-pinned"},
+		map[string]any{"B": "refetched-b"},
+	)
+	configDir := makeCMConfigDir(t, map[string]any{
+		"default.json": map[string]any{"C": "file-c"},
+	})
+
+	mgr := NewConfigManager(
+		WithAPIKey("secret"),
+		WithBaseURL(server.URL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMSchemaKeys(map[string]bool{"PINNED": true}),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_AUTH_URL": server.URL,
+			"SMOOAI_ENV_CONFIG_DIR":  configDir,
+			"PINNED":                 "env-pinned",
+		}),
+	)
+
+	v, err := mgr.GetPublicConfig("A")
+	require.NoError(t, err)
+	assert.Equal(t, "remote-a", v)
+
+	sub, err := mgr.Subscribe(context.Background())
+	require.NoError(t, err)
+	defer sub.Close()
+
+	frames <- `{"keys":["A","B","C","PINNED"],"values":{"A":"pushed-a","C":"pushed-c","PINNED":"pushed-pinned"}}`
+	receiveEvent(t, sub)
+
+	v, _ = mgr.GetPublicConfig("A")
+	assert.Equal(t, "pushed-a", v)
+	v, _ = mgr.GetSecretConfig("B")
+	assert.Equal(t, "refetched-b", v)
+	v, _ = mgr.GetPublicConfig("C")
+	assert.Equal(t, "pushed-c", v)
+	v, _ = mgr.GetPublicConfig("PINNED")
+	assert.Equal(t, "env-pinned", v, "env overrides still win over pushed values")
+}
+
+func TestConfigManager_SubscribeAppliesWithoutReader(t *testing.T) {
+	server, frames := sseServer(t, map[string]any{"N": 0.0}, nil)
+	mgr := NewConfigManager(
+		WithAPIKey("secret"),
+		WithBaseURL(server.URL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_AUTH_URL": server.URL,
+			"SMOOAI_ENV_CONFIG_DIR":  makeCMConfigDir(t, map[string]any{"default.json": map[string]any{}}),
+		}),
+	)
+	sub, err := mgr.Subscribe(context.Background())
+	require.NoError(t, err)
+	defer sub.Close()
+
+	// Nobody reads Events while three buffers' worth of changes arrive.
+	const pushes = 3 * subscriptionBuffer
+	for i := 1; i <= pushes; i++ {
+		frames <- fmt.Sprintf(`{"keys":["N","K%d"],"values":{"N":%d,"K%d":true}}`, i, i, i)
+	}
+	assert.Eventually(t, func() bool {
+		v, _ := mgr.GetPublicConfig("N")
+		return v == float64(pushes)
+	}, 2*time.Second, 10*time.Millisecond, "changes keep applying")
+
+	// The backlog is coalesced, not lost: every key arrives, with the
+	// latest value of N last.
+	seen := map[string]bool{}
+	for done := false; !done; {
+		evt := receiveEvent(t, sub)
+		for _, key := range evt.Keys {
+			seen[key] = true
+		}
+		done = evt.Values["N"] == float64(pushes)
+	}
+	assert.Len(t, seen, pushes+1)
+	assert.Empty(t, sub.Events(), "the latest value of N comes last")
+}
+
+func TestConfigManager_SubscribeKeepsValueWhenRefetchFails(t *testing.T) {
+	frames := make(chan string, 1)
+	var down atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "jwt", "expires_in": 3600})
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case frame := <-frames:
+			fmt.Fprintf(w, "event: change\ndata: %s\n\n", frame)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values", func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(valuesResponse{Values: map[string]any{"KILL": true}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mgr := NewConfigManager(
+		WithAPIKey("secret"),
+		WithBaseURL(server.URL),
+		WithOrgID(testOrgID),
+		WithConfigEnvironment("production"),
+		WithCMEnvOverride(map[string]string{
+			"SMOOAI_CONFIG_AUTH_URL": server.URL,
+			"SMOOAI_ENV_CONFIG_DIR":  makeCMConfigDir(t, map[string]any{"default.json": map[string]any{"KILL": false}}),
+		}),
+	)
+	v, err := mgr.GetFeatureFlag("KILL")
+	require.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	sub, err := mgr.Subscribe(context.Background())
+	require.NoError(t, err)
+	defer sub.Close()
+
+	down.Store(true)
+	frames <- `{"keys":["KILL"]}`
+	receiveEvent(t, sub)
+	assert.ErrorContains(t, sub.Err(), "502")
+
+	v, err = mgr.GetFeatureFlag("KILL")
+	require.NoError(t, err)
+	assert.Equal(t, true, v, "a failed refetch is not a deletion")
+}
+
+func TestCoalesceEvents(t *testing.T) {
+	older := ConfigChangeEvent{ID: "1", Environment: "production", Keys: []string{"A", "B", "C"}, Values: map[string]any{"A": "a1", "B": "b1"}}
+	newer := ConfigChangeEvent{ID: "2", Environment: "production", Keys: []string{"B", "D"}, Values: map[string]any{"D": "d2"}}
+
+	got := coalesceEvents(older, newer)
+	assert.Equal(t, "2", got.ID)
+	assert.Equal(t, []string{"A", "C", "B", "D"}, got.Keys)
+	// B's newer entry has no inline value, so it must be refetched.
+	assert.Equal(t, map[string]any{"A": "a1", "D": "d2"}, got.Values)
+
+	got = coalesceEvents(older, ConfigChangeEvent{Keys: []string{"A"}})
+	assert.Equal(t, "1", got.ID, "an event without an id keeps the last one")
+}
+
+func TestConfigManager_SubscribeRequiresCredentials(t *testing.T) {
+	mgr := NewConfigManager(WithCMEnvOverride(map[string]string{}))
+	_, err := mgr.Subscribe(context.Background())
+	assert.ErrorContains(t, err, "remote API credentials")
+}
+
+func TestConfigClient_SubscribeIgnoresRequestTimeout(t *testing.T) {
+	server, frames := sseServer(t, nil, nil)
+	client := NewConfigClient(server.URL, "cid", "secret", testOrgID,
+		WithAuthURL(server.URL), WithRing(""), WithRequestTimeout(50*time.Millisecond))
+	defer client.Close()
+
+	sub, err := client.Subscribe(context.Background(), "production")
+	require.NoError(t, err)
+	defer sub.Close()
+
+	time.Sleep(150 * time.Millisecond)
+	frames <- `{"keys":["A"],"values":{"A":"new"}}`
+	evt := receiveEvent(t, sub)
+	assert.Equal(t, []string{"A"}, evt.Keys)
+}
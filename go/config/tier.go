@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrWrongTier matches any *WrongTierError via errors.Is.
+var ErrWrongTier = errors.New("config key read through the wrong tier")
+
+// WrongTierError is returned from GetPublicConfig / GetSecretConfig /
+// GetFeatureFlag when the key is known to belong to a different tier — e.g.
+// reading a secret through GetPublicConfig, which would otherwise hand a
+// secret to code paths that treat public values as safe to expose.
+type WrongTierError struct {
+	// Key is the key that was read.
+	Key string
+	// Requested is the tier of the getter that was called.
+	Requested ConfigTier
+	// Actual is the tier the key is declared in.
+	Actual ConfigTier
+}
+
+// Error implements the error interface.
+func (e *WrongTierError) Error() string {
+	return fmt.Sprintf("[Smooai Config] config key %q is a %s value and cannot be read as %s",
+		e.Key, e.Actual, e.Requested)
+}
+
+// Is supports errors.Is(err, ErrWrongTier).
+func (e *WrongTierError) Is(target error) bool { return target == ErrWrongTier }
+
+// WithCMKeyTiers declares key tiers so Get* can enforce tier boundaries,
+// for services without a definition or to override the tiers
+// WithCMDefinition takes from one; its entries win. Without either, the
+// manager falls back to the tier metadata the remote API sends with the
+// values, if any. Keys with no known tier are readable through every
+// getter, as before.
+func WithCMKeyTiers(tiers map[string]ConfigTier) ConfigManagerOption {
+	return func(m *ConfigManager) { m.keyTiers = tiers }
+}
+
+// definitionTiers returns def's key tiers under both the schema name and
+// its UPPER_SNAKE_CASE form, the names env vars and validation match.
+func definitionTiers(def *ConfigDefinition) map[string]ConfigTier {
+	tiers := def.KeyTiers()
+	for key, tier := range def.KeyTiers() {
+		if snake := CamelToUpperSnake(key); snake != key {
+			if _, ok := tiers[snake]; !ok {
+				tiers[snake] = tier
+			}
+		}
+	}
+	return tiers
+}
+
+// checkTierLocked returns *WrongTierError when key is known to belong to a
+// tier other than requested. Must be called under m.mu after initialize.
+func (m *ConfigManager) checkTierLocked(key string, requested ConfigTier) error {
+	actual := m.keyTierLocked(key)
+	if !actual.IsValid() || actual == requested {
+		return nil
+	}
+	return &WrongTierError{Key: key, Requested: requested, Actual: actual}
+}
+
+// keyTierLocked returns key's known tier (WithCMKeyTiers first, then
+// WithCMDefinition, the remote schema or bundle, and remote metadata), or ""
+// when unknown. Must be called under m.mu.
+func (m *ConfigManager) keyTierLocked(key string) ConfigTier {
+	if tier, ok := m.keyTiers[key]; ok {
+		return tier
+	}
+	if tier, ok := m.definitionTiers[key]; ok {
+		return tier
+	}
+	if tier, ok := m.remoteDefinitionTiers[key]; ok {
+		return tier
+	}
+	return m.remoteTiers[key]
+}
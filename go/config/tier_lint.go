@@ -0,0 +1,127 @@
+package config
+
+// Misplaced tier values in config files. The JSON files in .smooai-config
+// are committed and shipped with the code, so they are public: a literal
+// value for a secret key there leaks the secret, and a smooai-secret://
+// reference on a public or feature flag key pulls a secret into a tier that
+// is treated as safe to expose. LintConfigFiles reports both; WithStrictMode
+// refuses to load them.
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// TierIssue is a config file value that does not belong in a config file
+// given its key's tier.
+type TierIssue struct {
+	// File is the file name within the config directory.
+	File string
+	Key  string
+	// Tier is the tier the key is declared in.
+	Tier   ConfigTier
+	Reason string
+}
+
+func (i TierIssue) String() string {
+	return fmt.Sprintf("%s: %s (%s): %s", i.File, i.Key, i.Tier, i.Reason)
+}
+
+// LintConfigFiles checks every *.json and *.jsonc file in root within fsys
+// (e.g. os.DirFS(".smooai-config"), ".") against tiers, typically
+// ConfigDefinition.KeyTiers, for use in CI. *.local.json files are skipped:
+// they are gitignored, so a developer's own secrets may live there. Issues
+// are sorted by file, then key.
+func LintConfigFiles(fsys fs.FS, root string, tiers map[string]ConfigTier) ([]TierIssue, error) {
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, NewConfigError(fmt.Sprintf("error reading %s: %v", root, err))
+	}
+	var issues []TierIssue
+	for _, entry := range entries {
+		name := entry.Name()
+		base := strings.TrimSuffix(strings.TrimSuffix(name, ".jsonc"), ".json")
+		if entry.IsDir() || base == name || base == "schema" || strings.HasSuffix(base, ".local") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(root, name))
+		if err != nil {
+			return nil, NewConfigError(fmt.Sprintf("error reading %s: %v", name, err))
+		}
+		var values map[string]any
+		if err := unmarshalJSONC(data, &values); err != nil {
+			return nil, NewConfigError(fmt.Sprintf("error parsing %s: %v", name, err))
+		}
+		for key, value := range values {
+			tier := tiers[key]
+			if reason := misplacedFileValue(tier, value); reason != "" {
+				issues = append(issues, TierIssue{File: name, Key: key, Tier: tier, Reason: reason})
+			}
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Key < issues[j].Key
+	})
+	return issues, nil
+}
+
+// misplacedFileValue says why value, for a key declared in tier, must not
+// come from a config file, or returns "" if it may.
+func misplacedFileValue(tier ConfigTier, value any) string {
+	switch tier {
+	case TierSecret:
+		if hasLiteralValue(value) {
+			return "secret value in a config file; use a smooai-secret:// reference, an env var or the config API"
+		}
+	case TierPublic, TierFeatureFlag:
+		if hasSecretRef(value) {
+			return "secret reference on a " + string(tier) + " key would expose the secret"
+		}
+	}
+	return ""
+}
+
+// hasLiteralValue reports whether v holds anything but nulls, empty strings
+// and secret references.
+func hasLiteralValue(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case string:
+		return v != "" && !strings.HasPrefix(v, SecretRefPrefix)
+	case map[string]any:
+		for _, item := range v {
+			if hasLiteralValue(item) {
+				return true
+			}
+		}
+		return false
+	case []any:
+		for _, item := range v {
+			if hasLiteralValue(item) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// dropMisplacedLocked removes the file values misplacedFileValue rejects,
+// warning about each. Must be called under m.mu.
+func (m *ConfigManager) dropMisplacedLocked(fileConfig map[string]any) {
+	for key, value := range fileConfig {
+		tier := m.keyTierLocked(key)
+		if reason := misplacedFileValue(tier, value); reason != "" {
+			delete(fileConfig, key)
+			m.log().Warn("ignoring key from the config files: "+reason, "key", key)
+		}
+	}
+}
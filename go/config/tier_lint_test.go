@@ -0,0 +1,87 @@
+package config
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var lintTiers = map[string]ConfigTier{
+	"API_URL":     TierPublic,
+	"NEW_UI":      TierFeatureFlag,
+	"DB_PASSWORD": TierSecret,
+	"DATABASE":    TierSecret,
+	"STRIPE_KEY":  TierSecret,
+}
+
+func TestLintConfigFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		".smooai-config/default.json": {Data: []byte(`{
+			"API_URL": "https://api.example.com",
+			"DB_PASSWORD": null,
+			"STRIPE_KEY": "",
+			"DATABASE": {"host": "db", "password": "smooai-secret://vault:db#password"}
+		}`)},
+		".smooai-config/production.jsonc": {Data: []byte(`{
+			// leaked
+			"DB_PASSWORD": "hunter2",
+			"API_URL": "smooai-secret://vault:api-url",
+			"NEW_UI": "smooai-secret://vault:flag",
+			"UNDECLARED": "smooai-secret://vault:x"
+		}`)},
+		".smooai-config/development.local.json": {Data: []byte(`{"DB_PASSWORD": "dev-only"}`)},
+		".smooai-config/schema.json":            {Data: []byte(`{"properties": {}}`)},
+		".smooai-config/README.md":              {Data: []byte("not config")},
+	}
+	issues, err := LintConfigFiles(fsys, ".smooai-config", lintTiers)
+	require.NoError(t, err)
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.File+" "+issue.Key)
+	}
+	assert.Equal(t, []string{
+		"default.json DATABASE",
+		"production.jsonc API_URL",
+		"production.jsonc DB_PASSWORD",
+		"production.jsonc NEW_UI",
+	}, got)
+	assert.Equal(t, "production.jsonc: DB_PASSWORD (secret): secret value in a config file; use a smooai-secret:// reference, an env var or the config API", issues[2].String())
+	assert.Equal(t, "secret reference on a public key would expose the secret", issues[1].Reason)
+}
+
+func TestLintConfigFiles_ParseError(t *testing.T) {
+	fsys := fstest.MapFS{"default.json": {Data: []byte(`{"API_URL":`)}}
+	_, err := LintConfigFiles(fsys, ".", lintTiers)
+	assert.ErrorContains(t, err, "error parsing default.json")
+}
+
+func TestConfigManager_StrictModeDropsMisplacedFileValues(t *testing.T) {
+	vault := &vaultProvider{secrets: map[string]string{"vault:stripe": "sk_live"}}
+	dir := makeCMConfigDir(t, map[string]any{"default.json": map[string]any{
+		"API_URL":     "smooai-secret://vault:stripe",
+		"DB_PASSWORD": "hunter2",
+		"STRIPE_KEY":  "smooai-secret://vault:stripe",
+	}})
+	opts := []ConfigManagerOption{
+		WithCMRemoteProvider(vault),
+		WithCMKeyTiers(lintTiers),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": dir}),
+	}
+
+	lenient := NewConfigManager(opts...)
+	v, err := lenient.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v, "only strict mode refuses misplaced values")
+
+	strict := NewConfigManager(append(opts, WithStrictMode())...)
+	_, err = strict.GetSecretConfig("DB_PASSWORD")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = strict.GetPublicConfig("API_URL")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	v, err = strict.GetSecretConfig("STRIPE_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "sk_live", v, "references on secret keys are the right place")
+}
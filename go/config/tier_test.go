@@ -0,0 +1,117 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigManager_KeyTiersFromSchema(t *testing.T) {
+	configDir := makeCMConfigDir(t, map[string]any{
+		"default.json": map[string]any{"API_URL": "https://api", "DB_PASSWORD": "hunter2", "NEW_UI": true, "UNTYPED": "x"},
+	})
+	def := DefineConfig(
+		map[string]any{"properties": map[string]any{"API_URL": map[string]any{"type": "string"}}},
+		map[string]any{"properties": map[string]any{"DB_PASSWORD": map[string]any{"type": "string"}}},
+		map[string]any{"properties": map[string]any{"NEW_UI": map[string]any{"type": "boolean"}}},
+	)
+	mgr := NewConfigManager(
+		WithCMKeyTiers(def.KeyTiers()),
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": configDir}),
+	)
+
+	v, err := mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	_, err = mgr.GetPublicConfig("DB_PASSWORD")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrWrongTier))
+	var tierErr *WrongTierError
+	require.True(t, errors.As(err, &tierErr))
+	assert.Equal(t, TierSecret, tierErr.Actual)
+	assert.Equal(t, TierPublic, tierErr.Requested)
+
+	_, err = mgr.GetFeatureFlag("API_URL")
+	assert.ErrorIs(t, err, ErrWrongTier)
+
+	v, err = mgr.GetFeatureFlag("NEW_UI")
+	require.NoError(t, err)
+	assert.Equal(t, true, v)
+
+	v, err = mgr.GetPublicConfig("UNTYPED")
+	require.NoError(t, err, "keys without tier metadata are readable from any tier")
+	assert.Equal(t, "x", v)
+}
+
+func TestConfigManager_KeyTiersFromRemote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"values": map[string]any{"API_URL": "https://api", "DB_PASSWORD": "hunter2"},
+			"tiers":  map[string]string{"API_URL": "public", "DB_PASSWORD": "secret"},
+		})
+	}))
+	defer server.Close()
+
+	mgr := newStalenessManager(t, server.URL)
+
+	v, err := mgr.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api", v)
+
+	_, err = mgr.GetPublicConfig("DB_PASSWORD")
+	assert.ErrorIs(t, err, ErrWrongTier)
+
+	v, err = mgr.GetSecretConfig("DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+}
+
+func TestConfigManager_SchemaTiersWinOverRemote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "stub", "expires_in": 3600})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"values": map[string]any{"TOKEN": "t"},
+			"tiers":  map[string]string{"TOKEN": "public"},
+		})
+	}))
+	defer server.Close()
+
+	mgr := newStalenessManager(t, server.URL, WithCMKeyTiers(map[string]ConfigTier{"TOKEN": TierSecret}))
+
+	_, err := mgr.GetPublicConfig("TOKEN")
+	assert.ErrorIs(t, err, ErrWrongTier)
+	v, err := mgr.GetSecretConfig("TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "t", v)
+}
+
+func TestConfigClient_KeyTiers(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"values": map[string]any{"A": "1"},
+			"tiers":  map[string]string{"A": "feature_flag"},
+		})
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+
+	assert.Nil(t, client.KeyTiers("production"))
+	_, err := client.GetAllValues("production")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ConfigTier{"A": TierFeatureFlag}, client.KeyTiers("production"))
+}
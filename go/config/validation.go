@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxEnvironmentLength bounds environment names; the API rejects longer ones.
+const maxEnvironmentLength = 64
+
+var (
+	// ErrInvalidEnvironment matches an *InvalidInputError for an environment
+	// name via errors.Is.
+	ErrInvalidEnvironment = errors.New("invalid config environment")
+	// ErrInvalidOrgID matches an *InvalidInputError for an org ID via
+	// errors.Is.
+	ErrInvalidOrgID = errors.New("invalid config org ID")
+)
+
+var (
+	environmentPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	uuidPattern        = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+// InvalidInputError is returned before any request is made when the
+// environment name or org ID cannot address anything on the server.
+type InvalidInputError struct {
+	// Field is "environment" or "org ID".
+	Field string
+	// Value is the rejected input, after normalization.
+	Value string
+	// Reason says what is wrong with Value.
+	Reason string
+
+	kind error
+}
+
+// Error implements the error interface.
+func (e *InvalidInputError) Error() string {
+	return fmt.Sprintf("[Smooai Config] invalid %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// Is supports errors.Is(err, ErrInvalidEnvironment) and
+// errors.Is(err, ErrInvalidOrgID).
+func (e *InvalidInputError) Is(target error) bool { return target == e.kind }
+
+// normalizeEnvironment strips the stray whitespace that creeps in from env
+// files and copy-paste.
+func normalizeEnvironment(env string) string {
+	return strings.TrimSpace(env)
+}
+
+// normalizeOrgID strips whitespace and lowercases, the canonical UUID form
+// the API routes on.
+func normalizeOrgID(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// ValidateEnvironment reports whether env is a usable environment name:
+// 1-64 letters, digits, '.', '_' or '-', starting with a letter or digit.
+// Surrounding whitespace is ignored, as it is everywhere the client takes an
+// environment.
+func ValidateEnvironment(env string) error {
+	env = normalizeEnvironment(env)
+	invalid := func(reason string) error {
+		return &InvalidInputError{Field: "environment", Value: env, Reason: reason, kind: ErrInvalidEnvironment}
+	}
+	switch {
+	case env == "":
+		return invalid("must not be empty")
+	case len(env) > maxEnvironmentLength:
+		return invalid(fmt.Sprintf("longer than %d characters", maxEnvironmentLength))
+	case !environmentPattern.MatchString(env):
+		return invalid("may only contain letters, digits, '.', '_' and '-', and must start with a letter or digit")
+	}
+	return nil
+}
+
+// ValidateOrgID reports whether id is an org ID: a UUID such as
+// 550e8400-e29b-41d4-a716-446655440000. Case and surrounding whitespace are
+// ignored, as they are by NewConfigClient.
+func ValidateOrgID(id string) error {
+	id = normalizeOrgID(id)
+	if !uuidPattern.MatchString(id) {
+		reason := "must be a UUID"
+		if id == "" {
+			reason = "must not be empty (set SMOOAI_CONFIG_ORG_ID)"
+		}
+		return &InvalidInputError{Field: "org ID", Value: id, Reason: reason, kind: ErrInvalidOrgID}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateEnvironment(t *testing.T) {
+	for _, env := range []string{"production", "dev-stage", "pr_123", "eu.west", "Staging", " production\n"} {
+		assert.NoError(t, ValidateEnvironment(env), env)
+	}
+	for _, env := range []string{"", "  ", "pre prod", "-prod", "prod/../x", "prod?x=1", strings.Repeat("a", 65)} {
+		err := ValidateEnvironment(env)
+		assert.ErrorIs(t, err, ErrInvalidEnvironment, env)
+		assert.NotErrorIs(t, err, ErrInvalidOrgID, env)
+	}
+}
+
+func TestValidateOrgID(t *testing.T) {
+	assert.NoError(t, ValidateOrgID(testOrgID))
+	assert.NoError(t, ValidateOrgID(" 550E8400-E29B-41D4-A716-446655440000 "))
+	for _, id := range []string{"", "org-id", "550e8400e29b41d4a716446655440000", "550e8400-e29b-41d4-a716-44665544000g"} {
+		assert.ErrorIs(t, ValidateOrgID(id), ErrInvalidOrgID, id)
+	}
+
+	var inputErr *InvalidInputError
+	require.ErrorAs(t, ValidateOrgID("my-org"), &inputErr)
+	assert.Equal(t, "org ID", inputErr.Field)
+	assert.Equal(t, `[Smooai Config] invalid org ID "my-org": must be a UUID`, inputErr.Error())
+}
+
+func TestNewConfigClient_NormalizesOrgIDAndEnvironment(t *testing.T) {
+	t.Setenv("SMOOAI_CONFIG_ENV", " staging\n")
+	var gotURL string
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		w.Write([]byte(`{"values": {}}`))
+	})
+	defer server.Close()
+
+	// An org ID pasted with whitespace and upper case still routes.
+	client := NewConfigClient(server.URL, "cid", "sec", "  550E8400-E29B-41D4-A716-446655440000 ",
+		WithTokenProvider(newFixedTokenProvider(t, unitTestJWT)), WithRing(""))
+	defer client.Close()
+
+	_, err := client.GetAllValues("")
+	require.NoError(t, err)
+	assert.Equal(t, "/organizations/"+testOrgID+"/config/values?environment=staging", gotURL)
+
+	_, err = client.GetAllValues(" production ")
+	require.NoError(t, err)
+	assert.Equal(t, "/organizations/"+testOrgID+"/config/values?environment=production", gotURL)
+}
+
+func TestConfigClient_RejectsInvalidInputBeforeRequest(t *testing.T) {
+	var requests atomic.Int32
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"values": {}}`))
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL)
+	defer client.Close()
+	_, err := client.GetValue("API_URL", "pre prod")
+	assert.ErrorIs(t, err, ErrInvalidEnvironment)
+	err = client.SetValues("prod/x", map[string]any{"A": 1})
+	assert.ErrorIs(t, err, ErrInvalidEnvironment)
+
+	badOrg := NewConfigClient(server.URL, "cid", "sec", "my-org", WithTokenProvider(newFixedTokenProvider(t, unitTestJWT)))
+	defer badOrg.Close()
+	_, err = badOrg.GetAllValues("production")
+	assert.ErrorIs(t, err, ErrInvalidOrgID)
+
+	assert.Zero(t, requests.Load())
+}
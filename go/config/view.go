@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"maps"
+)
+
+// ConfigView reads a ConfigManager through a layer of local overrides that
+// only the view sees, such as a tenant's feature flags for one request.
+// Views are cheap, safe for concurrent use and never change the manager;
+// create one with ConfigManager.WithOverrides.
+type ConfigView struct {
+	m         *ConfigManager
+	overrides map[string]any
+}
+
+// WithOverrides returns a view whose reads see overrides on top of every
+// other layer, including SetOverride. The manager itself is unchanged:
+//
+//	view := mgr.WithOverrides(map[string]any{"NEW_CHECKOUT": tenant.Beta})
+//	on, err := view.GetFeatureFlag("NEW_CHECKOUT")
+//
+// An overridden key is still checked like a read of the manager (its tier,
+// WithStrictSchemaKeys, schema violations) but may be a key no source
+// sets. Changing the map after the call doesn't affect the view.
+func (m *ConfigManager) WithOverrides(overrides map[string]any) *ConfigView {
+	return &ConfigView{m: m, overrides: maps.Clone(overrides)}
+}
+
+// WithOverrides returns a view with overrides on top of v's own.
+func (v *ConfigView) WithOverrides(overrides map[string]any) *ConfigView {
+	merged := maps.Clone(v.overrides)
+	if merged == nil {
+		merged = make(map[string]any, len(overrides))
+	}
+	maps.Copy(merged, overrides)
+	return &ConfigView{m: v.m, overrides: merged}
+}
+
+// Manager returns the manager the view reads.
+func (v *ConfigView) Manager() *ConfigManager { return v.m }
+
+// GetPublicConfig is ConfigManager.GetPublicConfig with the view's
+// overrides.
+func (v *ConfigView) GetPublicConfig(key string) (any, error) {
+	return v.get(key, v.m.GetPublicConfig)
+}
+
+// GetSecretConfig is ConfigManager.GetSecretConfig with the view's
+// overrides.
+func (v *ConfigView) GetSecretConfig(key string) (any, error) {
+	return v.get(key, v.m.GetSecretConfig)
+}
+
+// GetFeatureFlag is ConfigManager.GetFeatureFlag with the view's overrides.
+func (v *ConfigView) GetFeatureFlag(key string) (any, error) {
+	return v.get(key, v.m.GetFeatureFlag)
+}
+
+// Has is ConfigManager.Has with the view's overrides.
+func (v *ConfigView) Has(key string) (bool, error) {
+	if _, ok := v.overrides[key]; ok {
+		return true, nil
+	}
+	return v.m.Has(key)
+}
+
+// get reads key through the manager's getter, which checks it, and returns
+// the override instead when the view has one.
+func (v *ConfigView) get(key string, read func(string) (any, error)) (any, error) {
+	value, err := read(key)
+	override, ok := v.overrides[key]
+	if !ok {
+		return value, err
+	}
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+	return override, nil
+}
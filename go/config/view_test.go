@@ -0,0 +1,104 @@
+package config
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newViewManager(t *testing.T, opts ...ConfigManagerOption) *ConfigManager {
+	t.Helper()
+	configDir := makeCMConfigDir(t, map[string]any{
+		"default.json": map[string]any{"API_URL": "file", "NEW_CHECKOUT": false, "DB_PASSWORD": "hunter2"},
+	})
+	base := []ConfigManagerOption{
+		WithCMEnvOverride(map[string]string{"SMOOAI_ENV_CONFIG_DIR": configDir}),
+		WithCMKeyTiers(map[string]ConfigTier{"NEW_CHECKOUT": TierFeatureFlag, "DB_PASSWORD": TierSecret}),
+	}
+	return NewConfigManager(append(base, opts...)...)
+}
+
+func TestConfigView(t *testing.T) {
+	mgr := newViewManager(t)
+	view := mgr.WithOverrides(map[string]any{"NEW_CHECKOUT": true, "TENANT": "acme"})
+
+	v, err := view.GetFeatureFlag("NEW_CHECKOUT")
+	require.NoError(t, err)
+	assert.Equal(t, true, v)
+	v, err = view.GetPublicConfig("API_URL")
+	require.NoError(t, err)
+	assert.Equal(t, "file", v, "keys without an override read through")
+	v, err = view.GetPublicConfig("TENANT")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", v, "an override may set a key no source sets")
+	has, err := view.Has("TENANT")
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	v, err = mgr.GetFeatureFlag("NEW_CHECKOUT")
+	require.NoError(t, err)
+	assert.Equal(t, false, v, "the manager is unchanged")
+	has, err = mgr.Has("TENANT")
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	t.Run("overrides win over SetOverride", func(t *testing.T) {
+		mgr.SetOverride("API_URL", "break-glass", 0)
+		defer mgr.ClearOverride("API_URL")
+		v, err := mgr.WithOverrides(map[string]any{"API_URL": "tenant"}).GetPublicConfig("API_URL")
+		require.NoError(t, err)
+		assert.Equal(t, "tenant", v)
+	})
+
+	t.Run("nested views layer their overrides", func(t *testing.T) {
+		child := view.WithOverrides(map[string]any{"TENANT": "globex"})
+		v, err := child.GetPublicConfig("TENANT")
+		require.NoError(t, err)
+		assert.Equal(t, "globex", v)
+		v, err = child.GetFeatureFlag("NEW_CHECKOUT")
+		require.NoError(t, err)
+		assert.Equal(t, true, v)
+		v, err = view.GetPublicConfig("TENANT")
+		require.NoError(t, err)
+		assert.Equal(t, "acme", v, "the parent view is unchanged")
+	})
+
+	t.Run("the tier is still checked", func(t *testing.T) {
+		_, err := mgr.WithOverrides(map[string]any{"DB_PASSWORD": "x"}).GetPublicConfig("DB_PASSWORD")
+		assert.ErrorIs(t, err, ErrWrongTier)
+	})
+
+	t.Run("later changes to the map don't leak in", func(t *testing.T) {
+		overrides := map[string]any{"TENANT": "acme"}
+		view := mgr.WithOverrides(overrides)
+		overrides["TENANT"] = "changed"
+		v, err := view.GetPublicConfig("TENANT")
+		require.NoError(t, err)
+		assert.Equal(t, "acme", v)
+	})
+}
+
+func TestConfigView_StrictMode(t *testing.T) {
+	mgr := newViewManager(t, WithStrictMode())
+	v, err := mgr.WithOverrides(map[string]any{"TENANT": "acme"}).GetPublicConfig("TENANT")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", v, "an override counts as set")
+}
+
+func TestConfigView_Concurrent(t *testing.T) {
+	mgr := newViewManager(t)
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			view := mgr.WithOverrides(map[string]any{"TENANT": i})
+			v, err := view.GetPublicConfig("TENANT")
+			assert.NoError(t, err)
+			assert.Equal(t, i, v)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,284 @@
+package config
+
+// Live config updates over WebSocket, for gateways that allow WebSocket
+// upgrades but not Server-Sent Events or long requests. Selected with
+// WithWatchTransport(WatchWebSocket); events are delivered exactly as over
+// SSE.
+//
+// Server contract:
+//
+//	GET {BaseURL}/organizations/{orgId}/config/values/ws?environment=...[&ring=...][&since=cursor]
+//	Connection: Upgrade
+//	Upgrade: websocket
+//
+// After the upgrade the server sends one text message per change:
+//
+//	{"id": "42", "keys": ["API_URL", "OLD_KEY"], "values": {"API_URL": "https://..."}}
+//
+// with the same meaning as an SSE "change" event. since resumes after an
+// event id on reconnect. The client only answers pings and close frames; it
+// never sends data.
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WebSocket opcodes (RFC 6455 section 5.2).
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+)
+
+// wsAcceptGUID is appended to the handshake key to derive the accept value.
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsMaxMessage bounds one message, matching the SSE reader's line limit.
+const wsMaxMessage = 4 * 1024 * 1024
+
+// wsConn is an upgraded WebSocket connection.
+type wsConn struct {
+	rwc io.ReadWriteCloser
+	r   *bufio.Reader
+}
+
+func (c *ConfigClient) openWebSocket(ctx context.Context, env, since string) (*wsConn, error) {
+	u := fmt.Sprintf("%s/config/values/ws?%s", c.orgURL(), c.valuesQuery(env))
+	if since != "" {
+		u += "&since=" + url.QueryEscape(since)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("config subscribe: %w", err)
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("config subscribe: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	resp, err := c.doRequestWithRetryUsing(c.streamClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("config subscribe: %w", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		httpErr := newHTTPError("subscribe", resp)
+		resp.Body.Close()
+		return nil, httpErr
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != wsAccept(key) {
+		resp.Body.Close()
+		return nil, fmt.Errorf("config subscribe: invalid Sec-WebSocket-Accept %q", got)
+	}
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, errors.New("config subscribe: upgraded connection is not writable")
+	}
+	return &wsConn{rwc: rwc, r: bufio.NewReader(rwc)}, nil
+}
+
+func wsAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// readMessage returns the next text or binary message, answering pings and
+// close frames along the way. A close frame ends the stream with io.EOF.
+func (ws *wsConn) readMessage() ([]byte, error) {
+	var message []byte
+	inMessage := false
+	for {
+		fin, opcode, payload, err := wsReadFrame(ws.r)
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsPing:
+			if err := wsWriteFrame(ws.rwc, wsPong, payload, true); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			_ = wsWriteFrame(ws.rwc, wsClose, payload, true)
+			return nil, io.EOF
+		case wsText, wsBinary:
+			if inMessage {
+				return nil, errors.New("websocket: new message inside a fragmented one")
+			}
+			message, inMessage = payload, true
+		case wsContinuation:
+			if !inMessage {
+				return nil, errors.New("websocket: continuation without a message")
+			}
+			if len(message)+len(payload) > wsMaxMessage {
+				return nil, errors.New("websocket: message too large")
+			}
+			message = append(message, payload...)
+		default:
+			return nil, fmt.Errorf("websocket: unknown opcode %#x", opcode)
+		}
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+func (ws *wsConn) Close() error { return ws.rwc.Close() }
+
+// wsReadFrame reads one frame, unmasking its payload if masked.
+func wsReadFrame(r io.Reader) (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > wsMaxMessage {
+		return false, 0, nil, errors.New("websocket: frame too large")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// wsWriteFrame writes payload as one final frame. Clients must mask every
+// frame they send; servers must not.
+func wsWriteFrame(w io.Writer, opcode byte, payload []byte, mask bool) error {
+	frame := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n < 126:
+		frame[1] = byte(n)
+	case n <= 0xFFFF:
+		frame[1] = 126
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame[1] = 127
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	if !mask {
+		_, err := w.Write(append(frame, payload...))
+		return err
+	}
+	frame[1] |= 0x80
+	var key [4]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return err
+	}
+	frame = append(frame, key[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^key[i%4])
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// runWebSocket delivers changes from ws, reconnecting with backoff and
+// resuming from the last event id, until ctx is done.
+func (c *ConfigClient) runWebSocket(ctx context.Context, env string, sub *Subscription, ws *wsConn) {
+	lastEventID := ""
+	backoff := subscribeBackoff.initial
+	for {
+		stop := context.AfterFunc(ctx, func() { ws.Close() })
+		err := c.readWebSocket(ctx, env, sub, ws, func(id string) {
+			backoff = subscribeBackoff.initial
+			if id != "" {
+				lastEventID = id
+			}
+		})
+		stop()
+		ws.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		sub.setErr(fmt.Errorf("config subscribe: stream dropped: %w", err))
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, subscribeBackoff.max)
+			ws, err = c.openWebSocket(ctx, env, lastEventID)
+			if err == nil {
+				break
+			}
+			sub.setErr(err)
+		}
+	}
+}
+
+// readWebSocket applies and delivers each change message until the
+// connection fails or ctx is done. Malformed messages are skipped.
+func (c *ConfigClient) readWebSocket(ctx context.Context, env string, sub *Subscription, ws *wsConn, seen func(id string)) error {
+	for {
+		message, err := ws.readMessage()
+		if err != nil {
+			return err
+		}
+		var change changeEvent
+		if err := json.Unmarshal(message, &change); err != nil {
+			continue
+		}
+		evt := change.ConfigChangeEvent
+		evt.ID = change.ID
+		evt.Environment = env
+		seen(evt.ID)
+		c.applyChangeEvent(evt)
+		sub.deliver(evt)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
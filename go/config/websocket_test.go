@@ -0,0 +1,157 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWSFrames_RoundTrip(t *testing.T) {
+	for _, size := range []int{0, 5, 125, 126, 70000} {
+		payload := bytes.Repeat([]byte("x"), size)
+		for _, mask := range []bool{true, false} {
+			var buf bytes.Buffer
+			require.NoError(t, wsWriteFrame(&buf, wsText, payload, mask))
+			assert.Equal(t, mask, buf.Bytes()[1]&0x80 != 0)
+			fin, opcode, got, err := wsReadFrame(&buf)
+			require.NoError(t, err)
+			assert.True(t, fin)
+			assert.Equal(t, byte(wsText), opcode)
+			assert.Equal(t, payload, got)
+		}
+	}
+}
+
+func TestWSConn_ReadMessage(t *testing.T) {
+	var in, out bytes.Buffer
+	// A ping between the fragments of a message is answered with a pong.
+	in.Write([]byte{wsText, 3})
+	in.WriteString(`{"a`)
+	require.NoError(t, wsWriteFrame(&in, wsPing, []byte("hi"), false))
+	require.NoError(t, wsWriteFrame(&in, wsContinuation, []byte(`":1}`), false))
+	require.NoError(t, wsWriteFrame(&in, wsClose, nil, false))
+
+	ws := &wsConn{rwc: nopWriteCloser{&in, &out}, r: bufio.NewReader(&in)}
+	message, err := ws.readMessage()
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(message))
+	_, opcode, payload, err := wsReadFrame(&out)
+	require.NoError(t, err)
+	assert.Equal(t, byte(wsPong), opcode)
+	assert.Equal(t, "hi", string(payload))
+
+	_, err = ws.readMessage()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+type nopWriteCloser struct {
+	io.Reader
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// wsServer upgrades /config/values/ws and sends each string from the
+// returned channel as one text message.
+func wsServer(t *testing.T) (*httptest.Server, chan string, chan string) {
+	t.Helper()
+	messages := make(chan string, 8)
+	queries := make(chan string, 8)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "jwt", "expires_in": 3600})
+	})
+	mux.HandleFunc("/organizations/"+testOrgID+"/config/values/ws", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "websocket", r.Header.Get("Upgrade"))
+		assert.Equal(t, "Bearer jwt", r.Header.Get("Authorization"))
+		queries <- r.URL.RawQuery
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", wsAccept(r.Header.Get("Sec-WebSocket-Key")))
+		rw.Flush()
+		for message := range messages {
+			if message == "drop" {
+				return
+			}
+			require.NoError(t, wsWriteFrame(conn, wsText, []byte(message), false))
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		close(messages)
+		server.Close()
+	})
+	return server, messages, queries
+}
+
+func TestConfigClient_SubscribeWebSocket(t *testing.T) {
+	orig := subscribeBackoff
+	subscribeBackoff.initial = 0
+	t.Cleanup(func() { subscribeBackoff = orig })
+	server, messages, queries := wsServer(t)
+	client := NewConfigClient(server.URL, "cid", "secret", testOrgID,
+		WithAuthURL(server.URL), WithRing(""), WithWatchTransport(WatchWebSocket))
+	defer client.Close()
+
+	sub, err := client.Subscribe(context.Background(), "production")
+	require.NoError(t, err)
+	defer sub.Close()
+	assert.Equal(t, "environment=production", <-queries)
+
+	messages <- `not json`
+	messages <- `{"id":"7","keys":["A"],"values":{"A":"new"}}`
+	evt := receiveEvent(t, sub)
+	assert.Equal(t, "7", evt.ID)
+	assert.Equal(t, "production", evt.Environment)
+	v, ok := client.GetCachedValue("A", "production")
+	assert.True(t, ok)
+	assert.Equal(t, "new", v)
+
+	// A dropped connection reconnects and resumes after the last event.
+	messages <- "drop"
+	assert.Equal(t, "environment=production&since=7", <-queries)
+	messages <- `{"id":"8","keys":["B"]}`
+	evt = receiveEvent(t, sub)
+	assert.Equal(t, []string{"B"}, evt.Keys)
+}
+
+func TestConfigClient_SubscribeWebSocketRejected(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	defer server.Close()
+
+	client := newUnitClient(t, server.URL, WithWatchTransport(WatchWebSocket))
+	defer client.Close()
+	_, err := client.Subscribe(context.Background(), "production")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestConfigClient_SubscribeTransportSelection(t *testing.T) {
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	// SSE only: no long-poll fallback.
+	client := newUnitClient(t, server.URL, WithWatchTransport(WatchSSE))
+	defer client.Close()
+	_, err := client.Subscribe(context.Background(), "production")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotContains(t, err.Error(), "poll changes")
+
+	bogus := newUnitClient(t, server.URL, WithWatchTransport("carrier-pigeon"))
+	defer bogus.Close()
+	_, err = bogus.Subscribe(context.Background(), "production")
+	assert.ErrorContains(t, err, `unknown watch transport "carrier-pigeon"`)
+}